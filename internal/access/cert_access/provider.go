@@ -0,0 +1,109 @@
+// Package certaccess authenticates clients by their verified TLS client
+// certificate, mapping certificate names to inbound identities.
+package certaccess
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"sync"
+
+	internalaccess "github.com/nghyane/llm-mux/internal/access"
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+var doRegister = sync.OnceFunc(func() {
+	internalaccess.RegisterProvider(config.AccessProviderTypeClientCert, newProvider)
+})
+
+// Register ensures the client-certificate provider is available to the access manager.
+func Register() {
+	doRegister()
+}
+
+// provider maps certificate names to identities. The identity becomes the
+// request's principal, so the per-API-key settings (rate limits, model
+// allowlists, priorities, usage attribution) are keyed by identity.
+type provider struct {
+	name string
+	// identities maps URI, DNS and email SANs and subject common names.
+	identities map[string]string
+	// allowUnmapped uses the first URI SAN, or the common name, of
+	// certificates without a mapping as their identity.
+	allowUnmapped bool
+}
+
+func newProvider(cfg *config.AccessProvider, _ *config.SDKConfig) (internalaccess.Provider, error) {
+	p := &provider{name: cfg.Name, identities: make(map[string]string)}
+	if p.name == "" {
+		p.name = config.AccessProviderTypeClientCert
+	}
+	if raw, ok := cfg.Config["identities"]; ok {
+		m, ok := raw.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("identities must be a map of certificate name to identity")
+		}
+		for name, identity := range m {
+			s, ok := identity.(string)
+			if !ok || s == "" {
+				return nil, fmt.Errorf("identity for %q must be a non-empty string", name)
+			}
+			p.identities[name] = s
+		}
+	}
+	if v, ok := cfg.Config["allow-unmapped"].(bool); ok {
+		p.allowUnmapped = v
+	}
+	return p, nil
+}
+
+func (p *provider) Identifier() string { return p.name }
+
+func (p *provider) Authenticate(_ context.Context, r *http.Request) (*internalaccess.Result, error) {
+	// Only certificates verified against tls.client-ca count.
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, internalaccess.ErrNoCredentials
+	}
+	cert := r.TLS.VerifiedChains[0][0]
+	identity, ok := p.identityOf(cert)
+	if !ok {
+		return nil, internalaccess.ErrInvalidCredential
+	}
+	return &internalaccess.Result{
+		Provider:  p.Identifier(),
+		Principal: identity,
+		Metadata: map[string]string{
+			"source":  "client-certificate",
+			"subject": cert.Subject.String(),
+		},
+	}, nil
+}
+
+// identityOf returns the identity of the first certificate name with a
+// mapping, checking URI, DNS and email SANs before the common name.
+func (p *provider) identityOf(cert *x509.Certificate) (string, bool) {
+	names := make([]string, 0, len(cert.URIs)+len(cert.DNSNames)+len(cert.EmailAddresses)+1)
+	for _, u := range cert.URIs {
+		names = append(names, u.String())
+	}
+	names = append(names, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+	for _, name := range names {
+		if identity, ok := p.identities[name]; ok {
+			return identity, true
+		}
+	}
+	if p.allowUnmapped {
+		if len(cert.URIs) > 0 {
+			return cert.URIs[0].String(), true
+		}
+		if cert.Subject.CommonName != "" {
+			return cert.Subject.CommonName, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,53 @@
+package certaccess
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	internalaccess "github.com/nghyane/llm-mux/internal/access"
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+func TestAuthenticateMapsCertificateNames(t *testing.T) {
+	p, err := newProvider(&config.AccessProvider{Config: map[string]any{
+		"identities": map[string]any{
+			"spiffe://cluster.local/ns/apps/sa/chatbot": "chatbot",
+			"batch-worker": "batch",
+		},
+	}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spiffe, _ := url.Parse("spiffe://cluster.local/ns/apps/sa/chatbot")
+
+	for _, tc := range []struct {
+		name string
+		cert *x509.Certificate
+		want string
+		err  error
+	}{
+		{"uri san", &x509.Certificate{URIs: []*url.URL{spiffe}, Subject: pkix.Name{CommonName: "batch-worker"}}, "chatbot", nil},
+		{"common name", &x509.Certificate{Subject: pkix.Name{CommonName: "batch-worker"}}, "batch", nil},
+		{"unmapped", &x509.Certificate{Subject: pkix.Name{CommonName: "stranger"}}, "", internalaccess.ErrInvalidCredential},
+		{"no certificate", nil, "", internalaccess.ErrNoCredentials},
+	} {
+		r := httptest.NewRequest("GET", "/v1/models", nil)
+		r.TLS = &tls.ConnectionState{}
+		if tc.cert != nil {
+			r.TLS.VerifiedChains = [][]*x509.Certificate{{tc.cert}}
+		}
+		res, err := p.Authenticate(context.Background(), r)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("%s: err = %v, want %v", tc.name, err, tc.err)
+		}
+		if err == nil && res.Principal != tc.want {
+			t.Fatalf("%s: principal = %q, want %q", tc.name, res.Principal, tc.want)
+		}
+	}
+}
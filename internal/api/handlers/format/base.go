@@ -3,6 +3,7 @@ package format
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/interfaces"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"github.com/nghyane/llm-mux/internal/util"
 )
 
@@ -30,6 +32,10 @@ type BaseAPIHandler struct {
 	Cfg                   *config.SDKConfig
 	Routing               *config.RoutingConfig
 	OpenAICompatProviders []string
+
+	coalescer    requestCoalescer
+	idempotency  idempotencyStore
+	snapshotPins snapshotPinStore
 }
 
 func NewBaseAPIHandlers(cfg *config.SDKConfig, routing *config.RoutingConfig, authManager *provider.Manager, openAICompatProviders []string) *BaseAPIHandler {
@@ -72,6 +78,7 @@ func (h *BaseAPIHandler) GetContextWithCancel(ctx context.Context, handler inter
 	newCtx, cancel := context.WithCancel(ctx)
 	newCtx = context.WithValue(newCtx, ctxKeyGin, c)
 	newCtx = context.WithValue(newCtx, ctxKeyHandler, handler)
+	newCtx = provider.WithClientAPIKey(newCtx, c.GetString("apiKey"))
 	return newCtx, func(params ...any) {
 		if h.Cfg.RequestLog && len(params) == 1 {
 			switch data := params[0].(type) {
@@ -138,14 +145,10 @@ func buildRequestOpts(normalizedModel string, rawJSON []byte, metadata map[strin
 
 // extractErrorDetails extracts status code and headers from error interface
 func extractErrorDetails(err error) (int, http.Header) {
-	status := http.StatusInternalServerError
-	if se, ok := err.(interface{ StatusCode() int }); ok {
-		if code := se.StatusCode(); code > 0 {
-			status = code
-		}
-	}
+	status := ir.ErrorStatus(err)
 	var addon http.Header
-	if he, ok := err.(interface{ Headers() http.Header }); ok {
+	var he interface{ Headers() http.Header }
+	if errors.As(err, &he) {
 		if hdr := he.Headers(); hdr != nil {
 			addon = hdr.Clone()
 		}
@@ -154,10 +157,42 @@ func extractErrorDetails(err error) (int, http.Header) {
 }
 
 func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	ctx, rawJSON, overrides := h.withOverrides(ctx, handlerType, rawJSON)
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(ctx, modelName)
+	if errMsg == nil {
+		errMsg = h.checkUnknownParams(ctx, handlerType, rawJSON)
+	}
+	if errMsg == nil {
+		errMsg = shedOverload(ctx)
+	}
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	overrides = h.withAdaptiveThinking(ctx, normalizedModel, rawJSON, metadata, overrides)
+	providers, metadata = overrides.apply(providers, metadata)
+	if ctx, errMsg = h.withOfflineMode(ctx, normalizedModel, rawJSON); errMsg != nil {
+		return nil, errMsg
+	}
+	ctx, fingerprint := h.withSnapshotPin(ctx, rawJSON)
+	transcript := h.newTranscript(ctx, handlerType, modelName, rawJSON, false)
+	payload, errMsg, replayed := h.idempotent(ctx, handlerType, normalizedModel, alt, rawJSON, func() ([]byte, *interfaces.ErrorMessage) {
+		return h.coalesce(ctx, handlerType, normalizedModel, alt, rawJSON, func() ([]byte, *interfaces.ErrorMessage) {
+			return h.executeWithFallbacks(ctx, handlerType, normalizedModel, rawJSON, alt, providers, metadata, overrides)
+		})
+	})
 	if errMsg != nil {
 		return nil, errMsg
 	}
+	if !replayed {
+		transcript.complete(payload)
+		h.reportSnapshot(ctx, fingerprint)
+	}
+	return payload, nil
+}
+
+// executeWithFallbacks executes a non-streaming request, then each model of
+// the fallback chain in turn while it fails.
+func (h *BaseAPIHandler) executeWithFallbacks(ctx context.Context, handlerType, normalizedModel string, rawJSON []byte, alt string, providers []string, metadata map[string]any, overrides requestOverrides) ([]byte, *interfaces.ErrorMessage) {
 	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, alt, false)
 	resp, err := h.AuthManager.Execute(ctx, providers, req, opts)
 	if err == nil {
@@ -166,10 +201,11 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 
 	fallbacks := h.getFallbackChain(normalizedModel)
 	for _, fallbackModel := range fallbacks {
-		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(fallbackModel)
-		if len(fbProviders) == 0 {
+		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(ctx, fallbackModel)
+		if len(fbProviders) == 0 || (provider.IsOffline(ctx) && provider.IsSearchModel(fbNormalizedModel)) {
 			continue
 		}
+		fbMetadata = overrides.applyMetadata(fbMetadata)
 		fbReq, fbOpts := buildRequestOpts(fbNormalizedModel, rawJSON, fbMetadata, handlerType, alt, false)
 		fbResp, fbErr := h.AuthManager.Execute(ctx, fbProviders, fbReq, fbOpts)
 		if fbErr == nil {
@@ -182,10 +218,12 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 }
 
 func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	ctx, rawJSON, overrides := h.withOverrides(ctx, handlerType, rawJSON)
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(ctx, modelName)
 	if errMsg != nil {
 		return nil, errMsg
 	}
+	providers, metadata = overrides.apply(providers, metadata)
 	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, alt, false)
 	resp, err := h.AuthManager.ExecuteCount(ctx, providers, req, opts)
 	if err != nil {
@@ -196,29 +234,49 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 }
 
 func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	ctx, rawJSON, overrides := h.withOverrides(ctx, handlerType, rawJSON)
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(ctx, modelName)
+	if errMsg == nil {
+		errMsg = h.checkUnknownParams(ctx, handlerType, rawJSON)
+	}
+	if errMsg == nil {
+		errMsg = shedOverload(ctx)
+	}
 	if errMsg != nil {
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		errChan <- errMsg
 		close(errChan)
 		return nil, errChan
 	}
+	overrides = h.withAdaptiveThinking(ctx, normalizedModel, rawJSON, metadata, overrides)
+	providers, metadata = overrides.apply(providers, metadata)
+	if ctx, errMsg = h.withOfflineMode(ctx, normalizedModel, rawJSON); errMsg != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errMsg
+		close(errChan)
+		return nil, errChan
+	}
+	ctx, fingerprint := h.withSnapshotPin(ctx, rawJSON)
+	transcript := h.newTranscript(ctx, handlerType, modelName, rawJSON, true)
 	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, alt, true)
 	chunks, err := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
 	if err == nil {
-		return h.wrapStreamChannel(ctx, chunks)
+		h.reportSnapshot(ctx, fingerprint)
+		return h.wrapStreamChannel(ctx, chunks, transcript)
 	}
 
 	fallbacks := h.getFallbackChain(normalizedModel)
 	for _, fallbackModel := range fallbacks {
-		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(fallbackModel)
-		if len(fbProviders) == 0 {
+		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(ctx, fallbackModel)
+		if len(fbProviders) == 0 || (provider.IsOffline(ctx) && provider.IsSearchModel(fbNormalizedModel)) {
 			continue
 		}
+		fbMetadata = overrides.applyMetadata(fbMetadata)
 		fbReq, fbOpts := buildRequestOpts(fbNormalizedModel, rawJSON, fbMetadata, handlerType, alt, true)
 		fbChunks, fbErr := h.AuthManager.ExecuteStream(ctx, fbProviders, fbReq, fbOpts)
 		if fbErr == nil {
-			return h.wrapStreamChannel(ctx, fbChunks)
+			h.reportSnapshot(ctx, fingerprint)
+			return h.wrapStreamChannel(ctx, fbChunks, transcript)
 		}
 	}
 
@@ -229,9 +287,12 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	return nil, errChan
 }
 
-func (h *BaseAPIHandler) wrapStreamChannel(ctx context.Context, chunks <-chan provider.StreamChunk) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
+// wrapStreamChannel forwards chunks to the handler. A non-nil transcript
+// records every chunk and is saved when the stream ends without error.
+func (h *BaseAPIHandler) wrapStreamChannel(ctx context.Context, chunks <-chan provider.StreamChunk, transcript *transcriptRecorder) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
 	dataChan := make(chan []byte, 128)
 	errChan := make(chan *interfaces.ErrorMessage, 1)
+	pacer := h.newStreamPacer(ctx)
 	go func() {
 		defer close(dataChan)
 		defer close(errChan)
@@ -241,6 +302,7 @@ func (h *BaseAPIHandler) wrapStreamChannel(ctx context.Context, chunks <-chan pr
 				return
 			case chunk, ok := <-chunks:
 				if !ok {
+					transcript.finish()
 					return
 				}
 				if chunk.Err != nil {
@@ -252,6 +314,10 @@ func (h *BaseAPIHandler) wrapStreamChannel(ctx context.Context, chunks <-chan pr
 					return
 				}
 				if len(chunk.Payload) > 0 {
+					transcript.observe(chunk.Payload)
+					if pacer != nil && pacer.Wait(ctx, chunk.Payload) != nil {
+						return
+					}
 					select {
 					case dataChan <- chunk.Payload:
 					case <-ctx.Done():
@@ -264,7 +330,7 @@ func (h *BaseAPIHandler) wrapStreamChannel(ctx context.Context, chunks <-chan pr
 	return dataChan, errChan
 }
 
-func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string, normalizedModel string, metadata map[string]any, err *interfaces.ErrorMessage) {
+func (h *BaseAPIHandler) getRequestDetails(ctx context.Context, modelName string) (providers []string, normalizedModel string, metadata map[string]any, err *interfaces.ErrorMessage) {
 	resolvedModelName := util.ResolveAutoModel(modelName)
 	specifiedProvider := util.ExtractProviderFromPrefixedModelID(resolvedModelName)
 	cleanModelName := util.NormalizeIncomingModelID(resolvedModelName)
@@ -285,6 +351,12 @@ func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string
 		// GetProviderName uses canonical index for cross-provider routing
 		// Translation happens in executeWithProvider via GetModelIDForProvider
 		providers = util.GetProviderName(normalizedModel)
+		if len(providers) == 0 {
+			var errMsg *interfaces.ErrorMessage
+			if providers, normalizedModel, errMsg = h.replaceDeprecatedModel(ctx, normalizedModel); errMsg != nil {
+				return nil, "", nil, errMsg
+			}
+		}
 	}
 
 	if len(providers) == 0 {
@@ -322,11 +394,56 @@ func cloneMetadata(src map[string]any) map[string]any {
 	return dst
 }
 
+// WriteErrorResponse writes msg as an OpenAI-style error.
 func (h *BaseAPIHandler) WriteErrorResponse(c *gin.Context, msg *interfaces.ErrorMessage) {
-	status := http.StatusInternalServerError
-	if msg != nil && msg.StatusCode > 0 {
-		status = msg.StatusCode
+	status := writeErrorHeaders(c, msg)
+	c.JSON(status, ErrorResponse{
+		Error: ErrorDetail{
+			Message: StreamErrorMessage(msg),
+			Type:    ir.OpenAIErrorType(status),
+			Code:    errorCode(msg),
+		},
+	})
+}
+
+// WriteClaudeErrorResponse writes msg as an Anthropic Messages API error.
+func (h *BaseAPIHandler) WriteClaudeErrorResponse(c *gin.Context, msg *interfaces.ErrorMessage) {
+	status := writeErrorHeaders(c, msg)
+	c.JSON(status, ClaudeErrorBody(msg))
+}
+
+// WriteGeminiErrorResponse writes msg as a Google API error. A known retry
+// delay is included as RetryInfo, which Google SDKs honor.
+func (h *BaseAPIHandler) WriteGeminiErrorResponse(c *gin.Context, msg *interfaces.ErrorMessage) {
+	status := writeErrorHeaders(c, msg)
+	detail := gin.H{
+		"code":    status,
+		"message": StreamErrorMessage(msg),
+		"status":  ir.GeminiErrorStatus(status),
+	}
+	if retry := retryAfterSeconds(msg); retry != "" {
+		detail["details"] = []gin.H{{
+			"@type":      "type.googleapis.com/google.rpc.RetryInfo",
+			"retryDelay": retry + "s",
+		}}
+	}
+	c.JSON(status, gin.H{"error": detail})
+}
+
+// ClaudeErrorBody builds an Anthropic Messages API error body.
+func ClaudeErrorBody(msg *interfaces.ErrorMessage) gin.H {
+	return gin.H{
+		"type": "error",
+		"error": gin.H{
+			"type":    ir.ClaudeErrorType(StreamErrorStatus(msg)),
+			"message": StreamErrorMessage(msg),
+		},
 	}
+}
+
+// writeErrorHeaders copies the headers carried by msg, such as Retry-After,
+// and returns the response status.
+func writeErrorHeaders(c *gin.Context, msg *interfaces.ErrorMessage) int {
 	if msg != nil && msg.Addon != nil {
 		for key, values := range msg.Addon {
 			if len(values) == 0 {
@@ -338,23 +455,29 @@ func (h *BaseAPIHandler) WriteErrorResponse(c *gin.Context, msg *interfaces.Erro
 			}
 		}
 	}
-	c.Status(status)
-	if msg != nil && msg.Error != nil {
-		errResp := ErrorResponse{
-			Error: ErrorDetail{
-				Message: msg.Error.Error(),
-				Type:    "server_error",
-			},
-		}
-		c.JSON(status, errResp)
-	} else {
-		c.JSON(status, ErrorResponse{
-			Error: ErrorDetail{
-				Message: http.StatusText(status),
-				Type:    "server_error",
-			},
-		})
+	// Streaming handlers set text/event-stream up front; the error is plain JSON.
+	c.Writer.Header().Del("Content-Type")
+	return StreamErrorStatus(msg)
+}
+
+func retryAfterSeconds(msg *interfaces.ErrorMessage) string {
+	if msg == nil || msg.Addon == nil {
+		return ""
+	}
+	return msg.Addon.Get("Retry-After")
+}
+
+// errorCode returns the machine-readable code of errors raised by the auth
+// manager, such as quota_exhausted.
+func errorCode(msg *interfaces.ErrorMessage) string {
+	if msg == nil {
+		return ""
+	}
+	var pe *provider.Error
+	if errors.As(msg.Error, &pe) {
+		return pe.Code
 	}
+	return ""
 }
 
 func (h *BaseAPIHandler) LoggingAPIResponseError(ctx context.Context, err *interfaces.ErrorMessage) {
@@ -77,7 +77,7 @@ func (h *ClaudeCodeAPIHandler) ClaudeCountTokens(c *gin.Context) {
 
 	resp, errMsg := h.ExecuteCountWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, alt)
 	if errMsg != nil {
-		h.WriteErrorResponse(c, errMsg)
+		h.WriteClaudeErrorResponse(c, errMsg)
 		cliCancel(errMsg.Error)
 		return
 	}
@@ -100,7 +100,7 @@ func (h *ClaudeCodeAPIHandler) handleNonStreamingResponse(c *gin.Context, rawJSO
 
 	resp, errMsg := h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, alt)
 	if errMsg != nil {
-		h.WriteErrorResponse(c, errMsg)
+		h.WriteClaudeErrorResponse(c, errMsg)
 		cliCancel(errMsg.Error)
 		return
 	}
@@ -133,7 +133,6 @@ func (h *ClaudeCodeAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON [
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
-	c.Header("Access-Control-Allow-Origin", "*")
 
 	flusher, ok := c.Writer.(http.Flusher)
 	if !ok {
@@ -153,6 +152,16 @@ func (h *ClaudeCodeAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON [
 }
 
 func (h *ClaudeCodeAPIHandler) forwardClaudeStream(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
+	write := func(chunk []byte) bool {
+		if len(chunk) > 0 {
+			if _, err := c.Writer.Write(chunk); err != nil {
+				cancel(err)
+				return false
+			}
+			flusher.Flush()
+		}
+		return true
+	}
 	for {
 		select {
 		case <-c.Request.Context().Done():
@@ -161,56 +170,54 @@ func (h *ClaudeCodeAPIHandler) forwardClaudeStream(c *gin.Context, flusher http.
 
 		case chunk, ok := <-data:
 			if !ok {
+				if errMsg := format.PendingStreamError(errs); errMsg != nil {
+					h.writeStreamError(c, flusher, cancel, errMsg)
+					return
+				}
 				cancel(nil)
 				return
 			}
-			if len(chunk) > 0 {
-				if _, err := c.Writer.Write(chunk); err != nil {
-					cancel(err)
-					return
-				}
-				flusher.Flush()
+			if !write(chunk) {
+				return
 			}
 
 		case errMsg, ok := <-errs:
 			if !ok {
+				errs = nil
 				continue
 			}
-			if errMsg != nil {
-				// An error occurred: emit as a proper SSE error event
-				errorBytes, _ := json.Marshal(h.toClaudeError(errMsg))
-				_, _ = c.Writer.WriteString("event: error\n")
-				_, _ = c.Writer.WriteString("data: ")
-				_, _ = c.Writer.Write(errorBytes)
-				_, _ = c.Writer.WriteString("\n\n")
-				flusher.Flush()
-			}
-			var execErr error
-			if errMsg != nil {
-				execErr = errMsg.Error
+			// Events produced before the failure are still buffered.
+			for chunk := range data {
+				if !write(chunk) {
+					return
+				}
 			}
-			cancel(execErr)
+			h.writeStreamError(c, flusher, cancel, errMsg)
 			return
 		}
 	}
 }
 
-type claudeErrorDetail struct {
-	Type    string `json:"type"`
-	Message string `json:"message"`
-}
-
-type claudeErrorResponse struct {
-	Type  string            `json:"type"`
-	Error claudeErrorDetail `json:"error"`
-}
-
-func (h *ClaudeCodeAPIHandler) toClaudeError(msg *interfaces.ErrorMessage) claudeErrorResponse {
-	return claudeErrorResponse{
-		Type: "error",
-		Error: claudeErrorDetail{
-			Type:    "api_error",
-			Message: msg.Error.Error(),
-		},
+// writeStreamError ends a messages stream. Before anything was sent the client
+// gets a regular error response; afterwards an error event followed by
+// message_stop, so SDKs raise the typed error instead of a truncated stream.
+func (h *ClaudeCodeAPIHandler) writeStreamError(c *gin.Context, flusher http.Flusher, cancel func(error), errMsg *interfaces.ErrorMessage) {
+	if errMsg == nil {
+		cancel(nil)
+		return
+	}
+	if !c.Writer.Written() {
+		h.WriteClaudeErrorResponse(c, errMsg)
+		cancel(errMsg.Error)
+		return
 	}
+	errorBytes, _ := json.Marshal(format.ClaudeErrorBody(errMsg))
+	_, _ = c.Writer.WriteString("event: error\n")
+	_, _ = c.Writer.WriteString("data: ")
+	_, _ = c.Writer.Write(errorBytes)
+	_, _ = c.Writer.WriteString("\n\n")
+	_, _ = c.Writer.WriteString("event: message_stop\n")
+	_, _ = c.Writer.WriteString("data: {\"type\":\"message_stop\"}\n\n")
+	flusher.Flush()
+	cancel(errMsg.Error)
 }
@@ -0,0 +1,139 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+// requestCoalescer runs identical concurrent non-streaming requests once and
+// hands the result to every waiting caller. The zero value is ready to use.
+type requestCoalescer struct {
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+type coalescedCall struct {
+	done    chan struct{}
+	payload []byte
+	err     *interfaces.ErrorMessage
+	// waiters counts the callers sharing the call besides the first.
+	waiters int
+}
+
+// do runs fn, or waits for the call already running under key. shared is
+// true when the result came from another caller's call.
+func (g *requestCoalescer) do(ctx context.Context, key string, fn func() ([]byte, *interfaces.ErrorMessage)) (payload []byte, errMsg *interfaces.ErrorMessage, shared bool) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		call.waiters++
+		g.mu.Unlock()
+		select {
+		case <-call.done:
+			return bytes.Clone(call.payload), call.err, true
+		case <-ctx.Done():
+			status, addon := extractErrorDetails(ctx.Err())
+			return nil, &interfaces.ErrorMessage{StatusCode: status, Error: ctx.Err(), Addon: addon}, true
+		}
+	}
+	if g.calls == nil {
+		g.calls = make(map[string]*coalescedCall)
+	}
+	call := &coalescedCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+	call.payload, call.err = fn()
+	return call.payload, call.err, false
+}
+
+// coalesce runs execute once for identical concurrent requests of clients
+// with request coalescing enabled. A caller whose shared call was canceled
+// by the client that started it runs its own request instead.
+func (h *BaseAPIHandler) coalesce(ctx context.Context, handlerType, model, alt string, rawJSON []byte, execute func() ([]byte, *interfaces.ErrorMessage)) ([]byte, *interfaces.ErrorMessage) {
+	if h.Cfg == nil {
+		return execute()
+	}
+	apiKey := provider.ClientAPIKey(ctx)
+	if !h.Cfg.RequestCoalescing.AppliesTo(apiKey) {
+		return execute()
+	}
+
+	key := coalesceKey(apiKey, handlerType, model, alt, rawJSON)
+	payload, errMsg, shared := h.coalescer.do(ctx, key, execute)
+	if !shared {
+		return payload, errMsg
+	}
+	if errMsg != nil && ctx.Err() == nil && errors.Is(errMsg.Error, context.Canceled) {
+		return execute()
+	}
+	log.Debugf("coalesced identical %s request for model %s", handlerType, model)
+	return payload, errMsg
+}
+
+// coalesceKey hashes the request with JSON object keys sorted, so requests
+// differing only in key order or whitespace share a key.
+func coalesceKey(apiKey, handlerType, model, alt string, rawJSON []byte) string {
+	h := sha256.New()
+	for _, part := range []string{apiKey, handlerType, model, alt} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	writeCanonicalJSON(h, gjson.ParseBytes(rawJSON))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeCanonicalJSON hashes v with object keys sorted. Keys and strings are
+// quoted, so their content cannot forge the surrounding structure.
+func writeCanonicalJSON(h hash.Hash, v gjson.Result) {
+	switch {
+	case v.IsObject():
+		type field struct {
+			key   string
+			value gjson.Result
+		}
+		var fields []field
+		v.ForEach(func(k, val gjson.Result) bool {
+			fields = append(fields, field{k.String(), val})
+			return true
+		})
+		sort.Slice(fields, func(i, j int) bool { return fields[i].key < fields[j].key })
+		h.Write([]byte{'{'})
+		for _, f := range fields {
+			h.Write([]byte(strconv.Quote(f.key)))
+			h.Write([]byte{':'})
+			writeCanonicalJSON(h, f.value)
+			h.Write([]byte{','})
+		}
+		h.Write([]byte{'}'})
+	case v.IsArray():
+		h.Write([]byte{'['})
+		v.ForEach(func(_, val gjson.Result) bool {
+			writeCanonicalJSON(h, val)
+			h.Write([]byte{','})
+			return true
+		})
+		h.Write([]byte{']'})
+	case v.Type == gjson.String:
+		h.Write([]byte(strconv.Quote(v.String())))
+	default:
+		h.Write([]byte(v.Raw))
+	}
+}
@@ -0,0 +1,99 @@
+package format
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/interfaces"
+)
+
+func TestRequestCoalescerSharesConcurrentCalls(t *testing.T) {
+	var g requestCoalescer
+	var calls atomic.Int32
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	leaderDone := make(chan []byte)
+	go func() {
+		payload, _, _ := g.do(context.Background(), "k", func() ([]byte, *interfaces.ErrorMessage) {
+			calls.Add(1)
+			close(started)
+			<-release
+			return []byte("ok"), nil
+		})
+		leaderDone <- payload
+	}()
+	<-started
+
+	var wg sync.WaitGroup
+	results := make([][]byte, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			payload, _, shared := g.do(context.Background(), "k", func() ([]byte, *interfaces.ErrorMessage) {
+				calls.Add(1)
+				return []byte("other"), nil
+			})
+			if !shared {
+				t.Errorf("follower %d did not share the call", i)
+			}
+			results[i] = payload
+		}()
+	}
+	for {
+		g.mu.Lock()
+		waiters := g.calls["k"].waiters
+		g.mu.Unlock()
+		if waiters == len(results) {
+			break
+		}
+		runtime.Gosched()
+	}
+	close(release)
+	wg.Wait()
+
+	if got := string(<-leaderDone); got != "ok" {
+		t.Fatalf("leader payload = %q", got)
+	}
+	for i, p := range results {
+		if string(p) != "ok" {
+			t.Errorf("follower %d payload = %q", i, p)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("executions = %d, want 1", n)
+	}
+}
+
+func TestCoalesceKeyIgnoresKeyOrderAndWhitespace(t *testing.T) {
+	a := coalesceKey("k", "openai", "m", "", []byte(`{"model":"m","messages":[{"role":"user","content":"hi"}],"temperature":0.5}`))
+	b := coalesceKey("k", "openai", "m", "", []byte(`{ "temperature":0.5, "messages":[{"content":"hi","role":"user"}], "model":"m" }`))
+	if a != b {
+		t.Fatal("keys differ for equivalent bodies")
+	}
+	if a == coalesceKey("other", "openai", "m", "", []byte(`{"model":"m","messages":[{"role":"user","content":"hi"}],"temperature":0.5}`)) {
+		t.Fatal("keys match across API keys")
+	}
+	if a == coalesceKey("k", "openai", "m", "", []byte(`{"model":"m","messages":[{"role":"user","content":"hi!"}],"temperature":0.5}`)) {
+		t.Fatal("keys match for different bodies")
+	}
+}
+
+func TestCoalesceKeyQuotesKeysAndStrings(t *testing.T) {
+	for _, pair := range [][2]string{
+		{`{"a":"x\",\"b\":\"y"}`, `{"a":"x","b":"y"}`},
+		{`{"a\":\"x":"y"}`, `{"a":"x:y"}`},
+		{`["a\",\"b"]`, `["a","b"]`},
+	} {
+		if coalesceKey("k", "openai", "m", "", []byte(pair[0])) == coalesceKey("k", "openai", "m", "", []byte(pair[1])) {
+			t.Errorf("%s and %s share a key", pair[0], pair[1])
+		}
+	}
+	if coalesceKey("k", "openai", "m", "", []byte(`{"a":"\u0041"}`)) != coalesceKey("k", "openai", "m", "", []byte(`{"a":"A"}`)) {
+		t.Error("keys differ for equivalent escapes")
+	}
+}
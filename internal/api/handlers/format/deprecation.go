@@ -0,0 +1,55 @@
+package format
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/util"
+)
+
+// ModelSubstitutionHeader reports that a retired model was replaced, as
+// "<requested>=<replacement>".
+const ModelSubstitutionHeader = "X-LLMMUX-Model-Substitution"
+
+// replaceDeprecatedModel returns the providers of the replacement of model,
+// which no auth serves, and the replacement itself. Without a served
+// replacement it returns no providers and model unchanged. Under
+// routing.deprecations.hard-fail it returns an error naming the replacement.
+func (h *BaseAPIHandler) replaceDeprecatedModel(ctx context.Context, model string) ([]string, string, *interfaces.ErrorMessage) {
+	var deprecations config.DeprecationConfig
+	if h.Routing != nil {
+		deprecations = h.Routing.Deprecations
+	}
+	replacement, ok := deprecations.Replacement(model)
+	if !ok {
+		return nil, model, nil
+	}
+	if h.Routing != nil {
+		replacement = h.Routing.ResolveModelAlias(replacement)
+	}
+	providers := util.GetProviderName(replacement)
+	if len(providers) == 0 {
+		return nil, model, nil
+	}
+	if deprecations.HardFail {
+		return nil, model, &interfaces.ErrorMessage{
+			StatusCode: http.StatusGone,
+			Error: &provider.Error{
+				Code:       "model_deprecated",
+				Message:    fmt.Sprintf("model %s is retired; use %s", model, replacement),
+				HTTPStatus: http.StatusGone,
+			},
+		}
+	}
+	log.Debugf("replacing retired model %s with %s", model, replacement)
+	if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil {
+		c.Header(ModelSubstitutionHeader, model+"="+replacement)
+	}
+	return providers, replacement, nil
+}
@@ -0,0 +1,150 @@
+package format
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
+	"github.com/nghyane/llm-mux/internal/sseutil"
+	"github.com/nghyane/llm-mux/internal/translator"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/preprocess"
+	"github.com/nghyane/llm-mux/internal/util"
+)
+
+// ExplainHeader asks for a trace of routing and translation decisions instead
+// of executing the request. The explain query parameter does the same.
+const ExplainHeader = "X-LLMMUX-Explain"
+
+// ExplainRequested reports whether c asks for explain mode.
+func ExplainRequested(c *gin.Context) bool {
+	v := c.GetHeader(ExplainHeader)
+	if v == "" {
+		v = c.Query("explain")
+	}
+	on, err := strconv.ParseBool(v)
+	return err == nil && on
+}
+
+// Explain writes a JSON trace of how rawJSON, sent in handlerType's format for
+// modelName, would be routed and translated. Nothing is sent upstream. cfg
+// supplies the payload rules; it may be nil.
+func (h *BaseAPIHandler) Explain(c *gin.Context, handlerType, modelName string, rawJSON []byte, cfg *config.Config) {
+	ctx := provider.WithClientAPIKey(c.Request.Context(), c.GetString("apiKey"))
+	ctx = context.WithValue(ctx, ctxKeyGin, c)
+	ctx = preprocess.WithPreview(ctx)
+	ctx, rawJSON, overrides := h.withOverrides(ctx, handlerType, rawJSON)
+
+	trace := gin.H{
+		"object":        "llm-mux.explain",
+		"source_format": handlerType,
+		"priority":      provider.PriorityFrom(ctx).String(),
+	}
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(ctx, modelName)
+	providers, metadata = overrides.apply(providers, metadata)
+	modelTrace := gin.H{
+		"requested": modelName,
+		"resolved":  normalizedModel,
+	}
+	if len(metadata) > 0 {
+		modelTrace["suffix_metadata"] = metadata
+	}
+	if fallbacks := h.getFallbackChain(normalizedModel); len(fallbacks) > 0 {
+		modelTrace["fallbacks"] = fallbacks
+	}
+	trace["model"] = modelTrace
+	if errMsg != nil {
+		trace["error"] = StreamErrorMessage(errMsg)
+		c.JSON(http.StatusOK, trace)
+		return
+	}
+
+	routing := h.AuthManager.ExplainRouting(ctx, providers, normalizedModel)
+	trace["routing"] = explainRouting(routing)
+	trace["translation"] = explainTranslation(ctx, handlerType, normalizedModel, rawJSON, metadata)
+
+	rules := make([]gin.H, 0)
+	for _, r := range sseutil.MatchingPayloadRules(cfg, normalizedModel, provider.ClientAPIKey(ctx), "") {
+		rules = append(rules, gin.H{"kind": r.Kind, "params": r.Params})
+	}
+	trace["payload_rules"] = rules
+	c.JSON(http.StatusOK, trace)
+}
+
+func explainRouting(r provider.RoutingExplanation) gin.H {
+	providers := make([]gin.H, 0, len(r.Providers))
+	for _, p := range r.Providers {
+		auths := make([]gin.H, 0, len(p.Auths))
+		for _, a := range p.Auths {
+			entry := gin.H{"id": a.ID, "usable": a.Rejected == ""}
+			if a.Rejected != "" {
+				entry["rejected"] = a.Rejected
+			}
+			if !a.Until.IsZero() {
+				entry["until"] = a.Until.UTC().Format(time.RFC3339)
+			}
+			auths = append(auths, entry)
+		}
+		entry := gin.H{"provider": p.Provider, "circuit": p.Circuit, "auths": auths}
+		if p.Rank > 0 {
+			entry["rank"] = p.Rank
+		}
+		if p.Skipped != "" {
+			entry["skipped"] = p.Skipped
+		}
+		providers = append(providers, entry)
+	}
+	out := gin.H{"providers": providers}
+	if r.Policy != "" {
+		out["policy"] = r.Policy
+		out["baseline"] = r.Baseline
+	}
+	return out
+}
+
+// explainTranslation parses rawJSON as the request pipeline does and reports
+// the thinking and limit normalization applied plus the input token estimate.
+func explainTranslation(ctx context.Context, handlerType, model string, rawJSON []byte, metadata map[string]any) gin.H {
+	requested, err := translator.ParseRequest(handlerType, sseutil.SanitizeUndefinedValues(rawJSON))
+	if err != nil {
+		return gin.H{"error": err.Error()}
+	}
+	effective, err := stream.ConvertRequestToIR(ctx, provider.Format(handlerType), model, rawJSON, metadata)
+	if err != nil {
+		return gin.H{"error": err.Error()}
+	}
+	out := gin.H{
+		"messages":     len(effective.Messages),
+		"tools":        len(effective.Tools),
+		"input_tokens": util.CountTokensFromIR(effective.Model, effective),
+	}
+	if requested.MaxTokens != nil || effective.MaxTokens != nil {
+		out["max_tokens"] = gin.H{"requested": requested.MaxTokens, "effective": effective.MaxTokens}
+	}
+	if requested.Thinking != nil || effective.Thinking != nil {
+		out["thinking"] = gin.H{"requested": explainThinking(requested.Thinking), "effective": explainThinking(effective.Thinking)}
+	}
+	return out
+}
+
+func explainThinking(t *ir.ThinkingConfig) gin.H {
+	if t == nil {
+		return nil
+	}
+	out := gin.H{"include_thoughts": t.IncludeThoughts}
+	if t.ThinkingBudget != nil {
+		out["budget"] = *t.ThinkingBudget
+	}
+	if t.ThinkingLevel != "" {
+		out["level"] = t.ThinkingLevel
+	}
+	if t.Effort != "" {
+		out["effort"] = t.Effort
+	}
+	return out
+}
@@ -0,0 +1,174 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+const (
+	// IdempotencyKeyHeader names a non-streaming request so that retries of
+	// it return the stored response.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set on responses served from the store.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	defaultIdempotencyTTL        = 24 * time.Hour
+	defaultIdempotencyMaxEntries = 10000
+	idempotencySweepInterval     = time.Minute
+)
+
+// idempotencyStore keeps successful responses by client API key and
+// Idempotency-Key. The zero value is ready to use.
+type idempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+}
+
+type idempotencyEntry struct {
+	// fingerprint identifies the request body the key was first used with.
+	fingerprint string
+	// done is closed once the first request finished; entries of failed
+	// requests are removed before.
+	done    chan struct{}
+	payload []byte
+	expires time.Time
+}
+
+// idempotent runs execute once per Idempotency-Key of clients with
+// idempotency enabled and returns the stored response to later requests
+// with the same key until it expires. replayed is true for stored
+// responses. Failed requests are not stored, so their retries run again.
+func (h *BaseAPIHandler) idempotent(ctx context.Context, handlerType, model, alt string, rawJSON []byte, execute func() ([]byte, *interfaces.ErrorMessage)) (payload []byte, errMsg *interfaces.ErrorMessage, replayed bool) {
+	c, _ := ctx.Value(ctxKeyGin).(*gin.Context)
+	if h.Cfg == nil || !h.Cfg.Idempotency.Enabled || c == nil {
+		payload, errMsg = execute()
+		return payload, errMsg, false
+	}
+	idemKey := strings.TrimSpace(c.GetHeader(IdempotencyKeyHeader))
+	if idemKey == "" {
+		payload, errMsg = execute()
+		return payload, errMsg, false
+	}
+	apiKey := c.GetString("apiKey")
+	key := apiKey + "\x00" + idemKey
+	fingerprint := coalesceKey(apiKey, handlerType, model, alt, rawJSON)
+	cfg := h.Cfg.Idempotency
+
+	for {
+		entry, owner, errMsg := h.idempotency.acquire(key, fingerprint, cfg.MaxEntries)
+		if errMsg != nil {
+			return nil, errMsg, false
+		}
+		if entry == nil {
+			// Store full: run unprotected.
+			payload, errMsg = execute()
+			return payload, errMsg, false
+		}
+		if owner {
+			payload, errMsg = execute()
+			h.idempotency.finish(key, entry, payload, errMsg == nil, idempotencyTTL(cfg.TTL))
+			return payload, errMsg, false
+		}
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			status, addon := extractErrorDetails(ctx.Err())
+			return nil, &interfaces.ErrorMessage{StatusCode: status, Error: ctx.Err(), Addon: addon}, false
+		}
+		if entry.payload != nil {
+			log.Debugf("replaying stored %s response for idempotency key %q", handlerType, idemKey)
+			c.Header(IdempotentReplayedHeader, "true")
+			return bytes.Clone(entry.payload), nil, true
+		}
+		// The first request failed; this one takes over the key.
+	}
+}
+
+// acquire returns the live entry for key, creating it when there is none;
+// owner reports whether the caller created it and must run the request.
+// It returns a nil entry when the store is full.
+func (s *idempotencyStore) acquire(key, fingerprint string, maxEntries int) (entry *idempotencyEntry, owner bool, errMsg *interfaces.ErrorMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= idempotencySweepInterval {
+		s.sweep(now)
+	}
+	if e, ok := s.entries[key]; ok && (e.expires.IsZero() || now.Before(e.expires)) {
+		if e.fingerprint != fingerprint {
+			return nil, false, idempotencyKeyReused()
+		}
+		return e, false, nil
+	}
+	if maxEntries <= 0 {
+		maxEntries = defaultIdempotencyMaxEntries
+	}
+	if len(s.entries) >= maxEntries {
+		s.sweep(now)
+		if len(s.entries) >= maxEntries {
+			return nil, false, nil
+		}
+	}
+	if s.entries == nil {
+		s.entries = make(map[string]*idempotencyEntry)
+	}
+	e := &idempotencyEntry{fingerprint: fingerprint, done: make(chan struct{})}
+	s.entries[key] = e
+	return e, true, nil
+}
+
+// finish stores the response of a successful request, or forgets the key
+// of a failed one, and releases waiting duplicates.
+func (s *idempotencyStore) finish(key string, e *idempotencyEntry, payload []byte, ok bool, ttl time.Duration) {
+	s.mu.Lock()
+	if ok {
+		e.payload = bytes.Clone(payload)
+		if e.payload == nil {
+			e.payload = []byte{}
+		}
+		e.expires = time.Now().Add(ttl)
+	} else if s.entries[key] == e {
+		delete(s.entries, key)
+	}
+	s.mu.Unlock()
+	close(e.done)
+}
+
+// sweep removes expired entries. Requires s.mu.
+func (s *idempotencyStore) sweep(now time.Time) {
+	s.lastSweep = now
+	for k, e := range s.entries {
+		if !e.expires.IsZero() && !now.Before(e.expires) {
+			delete(s.entries, k)
+		}
+	}
+}
+
+func idempotencyTTL(raw string) time.Duration {
+	if d, err := time.ParseDuration(strings.TrimSpace(raw)); err == nil && d > 0 {
+		return d
+	}
+	return defaultIdempotencyTTL
+}
+
+func idempotencyKeyReused() *interfaces.ErrorMessage {
+	return &interfaces.ErrorMessage{
+		StatusCode: http.StatusUnprocessableEntity,
+		Error: &provider.Error{
+			Code:       "idempotency_key_reused",
+			Message:    "Idempotency-Key was already used with a different request",
+			HTTPStatus: http.StatusUnprocessableEntity,
+		},
+	}
+}
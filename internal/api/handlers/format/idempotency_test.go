@@ -0,0 +1,52 @@
+package format
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+)
+
+func TestIdempotentReplaysStoredResponse(t *testing.T) {
+	h := &BaseAPIHandler{Cfg: &config.SDKConfig{Idempotency: config.IdempotencyConfig{Enabled: true}}}
+	request := func(body string, execute func() ([]byte, *interfaces.ErrorMessage)) ([]byte, *interfaces.ErrorMessage, bool, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		c.Request.Header.Set(IdempotencyKeyHeader, "retry-1")
+		ctx := context.WithValue(context.Background(), ctxKeyGin, c)
+		payload, errMsg, replayed := h.idempotent(ctx, "openai", "m", "", []byte(body), execute)
+		return payload, errMsg, replayed, w
+	}
+
+	calls := 0
+	failing := func() ([]byte, *interfaces.ErrorMessage) {
+		calls++
+		return nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadGateway}
+	}
+	succeeding := func() ([]byte, *interfaces.ErrorMessage) {
+		calls++
+		return []byte(`{"id":"1"}`), nil
+	}
+
+	if _, errMsg, _, _ := request(`{"a":1}`, failing); errMsg == nil {
+		t.Fatal("failure not returned")
+	}
+	if payload, _, replayed, _ := request(`{"a":1}`, succeeding); replayed || string(payload) != `{"id":"1"}` {
+		t.Fatalf("retry after failure: replayed = %v, payload = %s", replayed, payload)
+	}
+	payload, _, replayed, w := request(`{ "a": 1 }`, succeeding)
+	if !replayed || string(payload) != `{"id":"1"}` || w.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("duplicate: replayed = %v, payload = %s, headers = %v", replayed, payload, w.Header())
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2", calls)
+	}
+	if _, errMsg, _, _ := request(`{"a":2}`, succeeding); errMsg == nil || errMsg.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("reused key with other body: %+v", errMsg)
+	}
+}
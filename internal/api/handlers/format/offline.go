@@ -0,0 +1,41 @@
+package format
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// withOfflineMode marks ctx offline when the client's API key is in offline
+// mode, and rejects requests that would need external retrieval instead of
+// silently dropping the feature.
+func (h *BaseAPIHandler) withOfflineMode(ctx context.Context, model string, rawJSON []byte) (context.Context, *interfaces.ErrorMessage) {
+	if h.Cfg == nil {
+		return ctx, nil
+	}
+	if !h.Cfg.OfflineMode.AppliesTo(provider.ClientAPIKey(ctx)) {
+		return ctx, nil
+	}
+	if provider.IsSearchModel(model) {
+		return ctx, offlineViolation(fmt.Sprintf("model %s searches the web", model))
+	}
+	if _, removed := provider.StripExternalRetrieval(rawJSON); len(removed) > 0 {
+		return ctx, offlineViolation("request uses " + strings.Join(removed, ", "))
+	}
+	return provider.WithOffline(ctx), nil
+}
+
+func offlineViolation(reason string) *interfaces.ErrorMessage {
+	return &interfaces.ErrorMessage{
+		StatusCode: http.StatusBadRequest,
+		Error: &provider.Error{
+			Code:       "offline_policy_violation",
+			Message:    reason + "; external retrieval is disabled for this API key",
+			HTTPStatus: http.StatusBadRequest,
+		},
+	}
+}
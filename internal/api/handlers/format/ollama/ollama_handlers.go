@@ -41,7 +41,6 @@ func (h *OllamaAPIHandler) Models() []map[string]any {
 
 func (h *OllamaAPIHandler) Version(c *gin.Context) {
 	c.Header("Content-Type", "application/json")
-	c.Header("Access-Control-Allow-Origin", "*")
 	c.Header("Server", fmt.Sprintf("ollama/%s", OllamaVersion))
 	c.JSON(http.StatusOK, gin.H{
 		"version": OllamaVersion,
@@ -50,7 +49,6 @@ func (h *OllamaAPIHandler) Version(c *gin.Context) {
 
 func (h *OllamaAPIHandler) Tags(c *gin.Context) {
 	c.Header("Content-Type", "application/json")
-	c.Header("Access-Control-Allow-Origin", "*")
 	c.Header("Server", fmt.Sprintf("ollama/%s", OllamaVersion))
 
 	modelRegistry := registry.GetGlobalRegistry()
@@ -96,7 +94,6 @@ func (h *OllamaAPIHandler) Tags(c *gin.Context) {
 
 func (h *OllamaAPIHandler) Show(c *gin.Context) {
 	c.Header("Content-Type", "application/json")
-	c.Header("Access-Control-Allow-Origin", "*")
 	c.Header("Server", fmt.Sprintf("ollama/%s", OllamaVersion))
 
 	var requestBody map[string]any
@@ -253,7 +250,6 @@ func (h *OllamaAPIHandler) Generate(c *gin.Context) {
 func (h *OllamaAPIHandler) handleOllamaChatStream(c *gin.Context, _ *openai.OpenAIAPIHandler, openaiRequest []byte, modelName string) {
 	c.Header("Content-Type", "application/json")
 	c.Header("Transfer-Encoding", "chunked")
-	c.Header("Access-Control-Allow-Origin", "*")
 	c.Header("Server", fmt.Sprintf("ollama/%s", OllamaVersion))
 
 	// Get the http.Flusher interface to manually flush the response
@@ -331,7 +327,6 @@ func (h *OllamaAPIHandler) handleOllamaChatStream(c *gin.Context, _ *openai.Open
 
 func (h *OllamaAPIHandler) handleOllamaChatNonStream(c *gin.Context, _ *openai.OpenAIAPIHandler, openaiRequest []byte, modelName string) {
 	c.Header("Content-Type", "application/json")
-	c.Header("Access-Control-Allow-Origin", "*")
 	c.Header("Server", fmt.Sprintf("ollama/%s", OllamaVersion))
 
 	// Get context with cancel
@@ -368,7 +363,6 @@ func (h *OllamaAPIHandler) handleOllamaChatNonStream(c *gin.Context, _ *openai.O
 func (h *OllamaAPIHandler) handleOllamaGenerateStream(c *gin.Context, _ *openai.OpenAIAPIHandler, openaiRequest []byte, modelName string) {
 	c.Header("Content-Type", "application/json")
 	c.Header("Transfer-Encoding", "chunked")
-	c.Header("Access-Control-Allow-Origin", "*")
 	c.Header("Server", fmt.Sprintf("ollama/%s", OllamaVersion))
 
 	// Get the http.Flusher interface to manually flush the response
@@ -446,7 +440,6 @@ func (h *OllamaAPIHandler) handleOllamaGenerateStream(c *gin.Context, _ *openai.
 
 func (h *OllamaAPIHandler) handleOllamaGenerateNonStream(c *gin.Context, _ *openai.OpenAIAPIHandler, openaiRequest []byte, modelName string) {
 	c.Header("Content-Type", "application/json")
-	c.Header("Access-Control-Allow-Origin", "*")
 	c.Header("Server", fmt.Sprintf("ollama/%s", OllamaVersion))
 
 	// Get context with cancel
@@ -0,0 +1,169 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/sseutil"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const defaultContinuePrompt = "Continue exactly where you stopped. Do not repeat any earlier text."
+
+// autoContinuations returns how many continuation requests the chat completion
+// in rawJSON may issue when cut off at max_tokens. Requests for several
+// choices (n>1) are never continued, as only the first choice would be.
+func autoContinuations(ac config.AutoContinueConfig, rawJSON []byte) int {
+	if ac.MaxContinuations <= 0 || gjson.GetBytes(rawJSON, "n").Int() > 1 {
+		return 0
+	}
+	model := gjson.GetBytes(rawJSON, "model").String()
+	if len(ac.Models) == 0 {
+		return ac.MaxContinuations
+	}
+	for _, pattern := range ac.Models {
+		if sseutil.MatchModelPattern(pattern, model) {
+			return ac.MaxContinuations
+		}
+	}
+	return 0
+}
+
+// continuationRequest appends the output generated so far and a request to
+// continue it to the conversation in rawJSON.
+func continuationRequest(rawJSON []byte, partial, prompt string) []byte {
+	if prompt == "" {
+		prompt = defaultContinuePrompt
+	}
+	out, _ := sjson.SetBytes(rawJSON, "messages.-1", map[string]any{"role": "assistant", "content": partial})
+	out, _ = sjson.SetBytes(out, "messages.-1", map[string]any{"role": "user", "content": prompt})
+	return out
+}
+
+// addUsage adds token counts from earlier segments to the usage object at
+// path in doc.
+func addUsage(doc []byte, path string, prompt, completion int64) []byte {
+	u := gjson.GetBytes(doc, path)
+	if !u.IsObject() || (prompt == 0 && completion == 0) {
+		return doc
+	}
+	doc, _ = sjson.SetBytes(doc, path+".prompt_tokens", u.Get("prompt_tokens").Int()+prompt)
+	doc, _ = sjson.SetBytes(doc, path+".completion_tokens", u.Get("completion_tokens").Int()+completion)
+	doc, _ = sjson.SetBytes(doc, path+".total_tokens", u.Get("total_tokens").Int()+prompt+completion)
+	return doc
+}
+
+// continueCompletion re-requests a non-streaming chat completion cut off at
+// max_tokens up to n times and merges the answers. A failed continuation
+// returns what was generated so far.
+func (h *OpenAIAPIHandler) continueCompletion(ctx context.Context, rawJSON, resp []byte, n int, prompt, alt string) []byte {
+	modelName := gjson.GetBytes(rawJSON, "model").String()
+	text := gjson.GetBytes(resp, "choices.0.message.content").String()
+	id := gjson.GetBytes(resp, "id").String()
+	for ; n > 0; n-- {
+		if gjson.GetBytes(resp, "choices.0.finish_reason").String() != "length" || gjson.GetBytes(resp, "choices.0.message.tool_calls").Exists() {
+			break
+		}
+		next, errMsg := h.ExecuteWithAuthManager(ctx, h.HandlerType(), modelName, continuationRequest(rawJSON, text, prompt), alt)
+		if errMsg != nil {
+			break
+		}
+		text += gjson.GetBytes(next, "choices.0.message.content").String()
+		next, _ = sjson.SetBytes(next, "choices.0.message.content", text)
+		next, _ = sjson.SetBytes(next, "id", id)
+		resp = addUsage(next, "usage", gjson.GetBytes(resp, "usage.prompt_tokens").Int(), gjson.GetBytes(resp, "usage.completion_tokens").Int())
+	}
+	return resp
+}
+
+// continueStream forwards a chat completion stream. When it finishes at
+// max_tokens without tool calls, the finish chunk is held back and a
+// continuation is streamed in its place, up to n times. Usage reported at the
+// end covers every segment.
+func (h *OpenAIAPIHandler) continueStream(ctx context.Context, rawJSON []byte, n int, prompt, alt string, data <-chan []byte, errs <-chan *interfaces.ErrorMessage) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
+	out := make(chan []byte, 128)
+	outErr := make(chan *interfaces.ErrorMessage, 1)
+	modelName := gjson.GetBytes(rawJSON, "model").String()
+	go func() {
+		defer close(out)
+		defer close(outErr)
+		var text []byte
+		var id string
+		var promptTokens, completionTokens int64
+		send := func(chunk []byte) bool {
+			select {
+			case out <- chunk:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for segment := 0; ; segment++ {
+			canContinue := segment < n
+			truncated, tools := false, false
+			for chunk := range data {
+				framed := bytes.HasPrefix(chunk, sseDataPrefix)
+				doc := bytes.TrimSpace(bytes.TrimPrefix(chunk, sseDataPrefix))
+				if !gjson.ValidBytes(doc) || !bytes.HasPrefix(doc, []byte("{")) {
+					if !truncated && !send(chunk) {
+						return
+					}
+					continue
+				}
+				if truncated {
+					// Usage sent after the finish chunk of a continued segment.
+					promptTokens += gjson.GetBytes(doc, "usage.prompt_tokens").Int()
+					completionTokens += gjson.GetBytes(doc, "usage.completion_tokens").Int()
+					continue
+				}
+				if id == "" {
+					id = gjson.GetBytes(doc, "id").String()
+				} else if segment > 0 {
+					doc, _ = sjson.SetBytes(doc, "id", id)
+				}
+				delta := gjson.GetBytes(doc, "choices.0.delta")
+				text = append(text, delta.Get("content").String()...)
+				if delta.Get("tool_calls").Exists() {
+					tools = true
+				}
+				if canContinue && !tools && gjson.GetBytes(doc, "choices.0.finish_reason").String() == "length" {
+					truncated = true
+					promptTokens += gjson.GetBytes(doc, "usage.prompt_tokens").Int()
+					completionTokens += gjson.GetBytes(doc, "usage.completion_tokens").Int()
+					if delta.Get("content").String() == "" {
+						continue
+					}
+					doc, _ = sjson.DeleteBytes(doc, "usage")
+					doc, _ = sjson.SetBytes(doc, "choices.0.finish_reason", nil)
+				} else {
+					doc = addUsage(doc, "usage", promptTokens, completionTokens)
+				}
+				if framed {
+					doc = append(append(append([]byte{}, sseDataPrefix...), doc...), sseNewline...)
+				}
+				if !send(doc) {
+					return
+				}
+			}
+			if errMsg := format.PendingStreamError(errs); errMsg != nil {
+				outErr <- errMsg
+				return
+			}
+			if !truncated {
+				return
+			}
+			data, errs = h.ExecuteStreamWithAuthManager(ctx, h.HandlerType(), modelName, continuationRequest(rawJSON, string(text), prompt), alt)
+			if data == nil {
+				if errMsg := <-errs; errMsg != nil {
+					outErr <- errMsg
+				}
+				return
+			}
+		}
+	}()
+	return out, outErr
+}
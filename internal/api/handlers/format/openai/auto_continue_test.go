@@ -0,0 +1,113 @@
+package openai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/tidwall/gjson"
+)
+
+func TestContinuationRequest(t *testing.T) {
+	raw := []byte(`{"model":"gpt-4o","max_tokens":16,"messages":[{"role":"user","content":"write a poem"}]}`)
+	out := continuationRequest(raw, "Roses are", "")
+	msgs := gjson.GetBytes(out, "messages").Array()
+	if len(msgs) != 3 {
+		t.Fatalf("messages = %s", gjson.GetBytes(out, "messages").Raw)
+	}
+	if msgs[1].Get("role").String() != "assistant" || msgs[1].Get("content").String() != "Roses are" {
+		t.Errorf("assistant message = %s", msgs[1].Raw)
+	}
+	if msgs[2].Get("role").String() != "user" || msgs[2].Get("content").String() != defaultContinuePrompt {
+		t.Errorf("prompt message = %s", msgs[2].Raw)
+	}
+
+	usage := addUsage([]byte(`{"usage":{"prompt_tokens":10,"completion_tokens":4,"total_tokens":14}}`), "usage", 7, 16)
+	if got := gjson.GetBytes(usage, "usage.total_tokens").Int(); got != 37 {
+		t.Errorf("total_tokens = %d", got)
+	}
+
+	ac := config.AutoContinueConfig{MaxContinuations: 2, Models: []string{"claude-*"}}
+	if autoContinuations(ac, []byte(`{"model":"gpt-4o"}`)) != 0 || autoContinuations(ac, []byte(`{"model":"claude-sonnet-4"}`)) != 2 {
+		t.Error("model patterns not applied")
+	}
+	if autoContinuations(ac, []byte(`{"model":"claude-sonnet-4","n":2}`)) != 0 {
+		t.Error("request for several choices is continued")
+	}
+}
+
+func TestContinueCompletion(t *testing.T) {
+	exec := &scriptedExecutor{responses: []string{
+		`{"id":"c2","choices":[{"index":0,"message":{"role":"assistant","content":"are red"},"finish_reason":"stop"}],"usage":{"prompt_tokens":15,"completion_tokens":3,"total_tokens":18}}`,
+	}}
+	h := newScriptedHandler(t, exec)
+	raw := []byte(`{"model":"fake-model","messages":[{"role":"user","content":"write a poem"}]}`)
+	first := []byte(`{"id":"c1","choices":[{"index":0,"message":{"role":"assistant","content":"Roses "},"finish_reason":"length"}],"usage":{"prompt_tokens":10,"completion_tokens":4,"total_tokens":14}}`)
+
+	resp := h.continueCompletion(context.Background(), raw, first, 2, "", "")
+	if got := gjson.GetBytes(resp, "choices.0.message.content").String(); got != "Roses are red" {
+		t.Errorf("content = %q", got)
+	}
+	if got := gjson.GetBytes(resp, "id").String(); got != "c1" {
+		t.Errorf("id = %q", got)
+	}
+	if got := gjson.GetBytes(resp, "choices.0.finish_reason").String(); got != "stop" {
+		t.Errorf("finish_reason = %q", got)
+	}
+	if got := gjson.GetBytes(resp, "usage").Raw; got != `{"prompt_tokens":25,"completion_tokens":7,"total_tokens":32}` {
+		t.Errorf("usage = %s", got)
+	}
+	if len(exec.requests) != 1 || gjson.GetBytes(exec.requests[0], "messages.1.content").String() != "Roses " {
+		t.Errorf("continuation requests = %q", exec.requests)
+	}
+}
+
+func TestContinueStream(t *testing.T) {
+	exec := &scriptedExecutor{responses: []string{
+		"data: {\"id\":\"c2\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"are red\"}}]}\n\n",
+		"data: {\"id\":\"c2\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}],\"usage\":{\"prompt_tokens\":15,\"completion_tokens\":3,\"total_tokens\":18}}\n\n",
+		"data: [DONE]\n\n",
+	}}
+	h := newScriptedHandler(t, exec)
+	raw := []byte(`{"model":"fake-model","stream":true,"messages":[{"role":"user","content":"write a poem"}]}`)
+	data := make(chan []byte, 4)
+	data <- []byte("data: {\"id\":\"c1\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":\"Roses \"}}]}\n\n")
+	data <- []byte("data: {\"id\":\"c1\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"length\"}],\"usage\":{\"prompt_tokens\":10,\"completion_tokens\":4,\"total_tokens\":14}}\n\n")
+	data <- []byte("data: [DONE]\n\n")
+	close(data)
+	errs := make(chan *interfaces.ErrorMessage)
+	close(errs)
+
+	out, outErr := h.continueStream(context.Background(), raw, 2, "", "", data, errs)
+	var chunks []string
+	for chunk := range out {
+		chunks = append(chunks, strings.TrimSpace(strings.TrimPrefix(string(chunk), "data: ")))
+	}
+	if errMsg := <-outErr; errMsg != nil {
+		t.Fatalf("stream error: %v", errMsg.Error)
+	}
+	if len(chunks) != 4 || chunks[3] != "[DONE]" {
+		t.Fatalf("chunks = %q", chunks)
+	}
+	var content strings.Builder
+	for _, c := range chunks[:3] {
+		if id := gjson.Get(c, "id").String(); id != "c1" {
+			t.Errorf("chunk id = %q in %s", id, c)
+		}
+		content.WriteString(gjson.Get(c, "choices.0.delta.content").String())
+	}
+	if content.String() != "Roses are red" {
+		t.Errorf("content = %q", content.String())
+	}
+	if got := gjson.Get(chunks[2], "choices.0.finish_reason").String(); got != "stop" {
+		t.Errorf("finish_reason = %q", got)
+	}
+	if got := gjson.Get(chunks[2], "usage").Raw; got != `{"prompt_tokens":25,"completion_tokens":7,"total_tokens":32}` {
+		t.Errorf("usage = %s", got)
+	}
+	if len(exec.requests) != 1 || gjson.GetBytes(exec.requests[0], "messages.1.content").String() != "Roses " {
+		t.Errorf("continuation requests = %q", exec.requests)
+	}
+}
@@ -0,0 +1,334 @@
+package openai
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// suffixInstruction and suffixSeparator frame the prompt of a request with a
+// suffix, asking the model for only the text that belongs between the prompt
+// and the suffix (fill-in-the-middle).
+const (
+	suffixInstruction = "Continue the text below. Your output is inserted verbatim directly after the text " +
+		"and must flow naturally into the suffix that follows it. Output only the inserted text, without " +
+		"repeating the text or the suffix.\n\nText:\n"
+	suffixSeparator = "\n\nSuffix:\n"
+)
+
+// completionsOptions carries legacy completions semantics that have no chat equivalent
+// and must be applied when rendering the response.
+type completionsOptions struct {
+	prompt string
+	echo   bool
+}
+
+// convertCompletionsRequestToChatCompletions converts a legacy completions request into a
+// chat completions request with a single user message. A suffix is added to that
+// message with fill-in-the-middle instructions, stop sequences and sampling
+// parameters are carried over unchanged.
+//
+// Parameters:
+//   - rawJSON: The raw JSON bytes of the completions request
+//
+// Returns:
+//   - []byte: The converted chat completions request
+//   - *completionsOptions: Echo/prompt state needed to render the response
+//   - error: A client error when the prompt cannot be represented
+func convertCompletionsRequestToChatCompletions(rawJSON []byte) ([]byte, *completionsOptions, error) {
+	root := gjson.ParseBytes(rawJSON)
+
+	prompt, err := completionsPrompt(root.Get("prompt"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if prompt == "" {
+		prompt = "Complete this:"
+	}
+
+	out := `{"model":"","messages":[]}`
+	out, _ = sjson.Set(out, "model", root.Get("model").String())
+
+	content := prompt
+	if suffix := root.Get("suffix").String(); suffix != "" {
+		content = suffixInstruction + prompt + suffixSeparator + suffix
+	}
+	out, _ = sjson.SetRaw(out, "messages.-1", `{"role":"user","content":""}`)
+	out, _ = sjson.Set(out, "messages.0.content", content)
+
+	for _, key := range []string{"max_tokens", "temperature", "top_p", "n", "seed", "user", "frequency_penalty", "presence_penalty", "logit_bias", "stream", "stream_options"} {
+		if v := root.Get(key); v.Exists() {
+			out, _ = sjson.SetRaw(out, key, v.Raw)
+		}
+	}
+
+	if stop := root.Get("stop"); stop.Exists() && stop.Type != gjson.Null {
+		out, _ = sjson.SetRaw(out, "stop", stop.Raw)
+	}
+
+	// Legacy logprobs is the number of top alternatives, chat uses a flag plus top_logprobs.
+	if logprobs := root.Get("logprobs"); logprobs.Type == gjson.Number && logprobs.Int() > 0 {
+		out, _ = sjson.Set(out, "logprobs", true)
+		out, _ = sjson.Set(out, "top_logprobs", logprobs.Int())
+	}
+
+	opts := &completionsOptions{prompt: prompt, echo: root.Get("echo").Bool()}
+	return []byte(out), opts, nil
+}
+
+// completionsPrompt extracts a single text prompt. Batched prompts and token-id
+// prompts cannot be represented as one chat message and are rejected.
+func completionsPrompt(prompt gjson.Result) (string, error) {
+	switch {
+	case !prompt.Exists() || prompt.Type == gjson.Null:
+		return "", nil
+	case prompt.Type == gjson.String:
+		return prompt.String(), nil
+	case prompt.IsArray():
+		items := prompt.Array()
+		if len(items) == 0 {
+			return "", nil
+		}
+		if len(items) > 1 {
+			return "", errors.New("batched prompts are not supported; send one prompt per request")
+		}
+		if items[0].Type != gjson.String {
+			return "", errors.New("token id prompts are not supported; send the prompt as text")
+		}
+		return items[0].String(), nil
+	default:
+		return "", errors.New("prompt must be a string")
+	}
+}
+
+// completionsFinishReason maps chat finish reasons onto the values allowed by the legacy API.
+func completionsFinishReason(reason string) string {
+	switch reason {
+	case "stop", "length", "content_filter":
+		return reason
+	case "", "null":
+		return ""
+	default:
+		return "stop"
+	}
+}
+
+// convertChatCompletionsResponseToCompletions converts a chat completions response back to
+// text_completion format, prepending the prompt when echo was requested.
+func convertChatCompletionsResponseToCompletions(rawJSON []byte, opts *completionsOptions) []byte {
+	root := gjson.ParseBytes(rawJSON)
+
+	out := `{"id":"","object":"text_completion","created":0,"model":"","choices":[]}`
+	out, _ = sjson.Set(out, "id", root.Get("id").String())
+	out, _ = sjson.Set(out, "created", root.Get("created").Int())
+	out, _ = sjson.Set(out, "model", root.Get("model").String())
+	if fp := root.Get("system_fingerprint"); fp.Exists() {
+		out, _ = sjson.Set(out, "system_fingerprint", fp.String())
+	}
+	if usage := root.Get("usage"); usage.Exists() {
+		out, _ = sjson.SetRaw(out, "usage", usage.Raw)
+	}
+
+	root.Get("choices").ForEach(func(_, choice gjson.Result) bool {
+		text := choice.Get("message.content").String()
+		if opts != nil && opts.echo {
+			text = opts.prompt + text
+		}
+		c := `{"text":"","index":0,"logprobs":null,"finish_reason":null}`
+		c, _ = sjson.Set(c, "text", text)
+		c, _ = sjson.Set(c, "index", choice.Get("index").Int())
+		if lp := choice.Get("logprobs"); lp.Exists() {
+			c, _ = sjson.SetRaw(c, "logprobs", lp.Raw)
+		}
+		if fr := completionsFinishReason(choice.Get("finish_reason").String()); fr != "" {
+			c, _ = sjson.Set(c, "finish_reason", fr)
+		}
+		out, _ = sjson.SetRaw(out, "choices.-1", c)
+		return true
+	})
+
+	return []byte(out)
+}
+
+// completionsStreamConverter renders chat completion chunks as text_completion chunks.
+// Upstream chunks may carry several SSE events, so each data line is converted separately.
+type completionsStreamConverter struct {
+	opts       *completionsOptions
+	echoedSent bool
+}
+
+// Convert returns zero or more SSE-framed completion chunks for one upstream chunk.
+func (s *completionsStreamConverter) Convert(chunk []byte) []byte {
+	var out []byte
+	for _, line := range bytes.Split(chunk, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if !bytes.HasPrefix(line, []byte("data:")) {
+			if len(line) == 0 || line[0] != '{' {
+				continue
+			}
+		} else {
+			line = bytes.TrimSpace(line[len("data:"):])
+		}
+		if len(line) == 0 || bytes.Equal(line, []byte("[DONE]")) {
+			continue
+		}
+		for _, converted := range s.convertEvent(line) {
+			out = append(out, sseDataPrefix...)
+			out = append(out, converted...)
+			out = append(out, sseNewline...)
+		}
+	}
+	return out
+}
+
+func (s *completionsStreamConverter) convertEvent(data []byte) [][]byte {
+	root := gjson.ParseBytes(data)
+	if !root.IsObject() {
+		return nil
+	}
+
+	base := `{"id":"","object":"text_completion","created":0,"model":"","choices":[]}`
+	base, _ = sjson.Set(base, "id", root.Get("id").String())
+	base, _ = sjson.Set(base, "created", root.Get("created").Int())
+	base, _ = sjson.Set(base, "model", root.Get("model").String())
+
+	var events [][]byte
+	if s.opts != nil && s.opts.echo && !s.echoedSent {
+		s.echoedSent = true
+		echo, _ := sjson.SetRaw(base, "choices.-1", `{"text":"","index":0,"logprobs":null,"finish_reason":null}`)
+		echo, _ = sjson.Set(echo, "choices.0.text", s.opts.prompt)
+		events = append(events, []byte(echo))
+	}
+
+	out := base
+	hasChoice := false
+	root.Get("choices").ForEach(func(_, choice gjson.Result) bool {
+		text := choice.Get("delta.content").String()
+		fr := completionsFinishReason(choice.Get("finish_reason").String())
+		if text == "" && fr == "" {
+			return true
+		}
+		c := `{"text":"","index":0,"logprobs":null,"finish_reason":null}`
+		c, _ = sjson.Set(c, "text", text)
+		c, _ = sjson.Set(c, "index", choice.Get("index").Int())
+		if lp := choice.Get("logprobs"); lp.Exists() && lp.Type != gjson.Null {
+			c, _ = sjson.SetRaw(c, "logprobs", lp.Raw)
+		}
+		if fr != "" {
+			c, _ = sjson.Set(c, "finish_reason", fr)
+		}
+		out, _ = sjson.SetRaw(out, "choices.-1", c)
+		hasChoice = true
+		return true
+	})
+
+	usage := root.Get("usage")
+	if usage.Exists() && usage.Type != gjson.Null {
+		out, _ = sjson.SetRaw(out, "usage", usage.Raw)
+	} else if !hasChoice {
+		return events
+	}
+	return append(events, []byte(out))
+}
+
+// handleCompletionsNonStreamingResponse executes the converted chat request and renders
+// the result as a text_completion object.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+//   - chatJSON: The converted chat completions request
+//   - opts: Legacy options applied while rendering
+func (h *OpenAIAPIHandler) handleCompletionsNonStreamingResponse(c *gin.Context, chatJSON []byte, opts *completionsOptions) {
+	c.Header("Content-Type", "application/json")
+
+	modelName := gjson.GetBytes(chatJSON, "model").String()
+	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
+	resp, errMsg := h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), modelName, chatJSON, "")
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	_, _ = c.Writer.Write(convertChatCompletionsResponseToCompletions(resp, opts))
+	cliCancel()
+}
+
+// handleCompletionsStreamingResponse streams the converted chat request and renders each
+// chunk as a text_completion SSE event, terminated by [DONE].
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+//   - chatJSON: The converted chat completions request
+//   - opts: Legacy options applied while rendering
+func (h *OpenAIAPIHandler) handleCompletionsStreamingResponse(c *gin.Context, chatJSON []byte, opts *completionsOptions) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: "Streaming not supported",
+				Type:    "server_error",
+			},
+		})
+		return
+	}
+
+	modelName := gjson.GetBytes(chatJSON, "model").String()
+	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
+	dataChan, errChan := h.ExecuteStreamWithAuthManager(cliCtx, h.HandlerType(), modelName, chatJSON, "")
+
+	converter := &completionsStreamConverter{opts: opts}
+	sw := format.NewSSEWriter(c.Writer)
+	write := func(chunk []byte) bool {
+		if converted := converter.Convert(chunk); len(converted) > 0 {
+			sw.Write(converted)
+			if !sw.Ok() {
+				cliCancel(sw.Err())
+				return false
+			}
+			flusher.Flush()
+		}
+		return true
+	}
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			cliCancel(c.Request.Context().Err())
+			return
+		case chunk, isOk := <-dataChan:
+			if !isOk {
+				if errMsg := format.PendingStreamError(errChan); errMsg != nil {
+					h.writeStreamError(c, flusher, func(err error) { cliCancel(err) }, errMsg)
+					return
+				}
+				sw.Write(sseDoneMarker)
+				flusher.Flush()
+				cliCancel()
+				return
+			}
+			if !write(chunk) {
+				return
+			}
+		case errMsg, isOk := <-errChan:
+			if !isOk {
+				errChan = nil
+				continue
+			}
+			for chunk := range dataChan {
+				if !write(chunk) {
+					return
+				}
+			}
+			h.writeStreamError(c, flusher, func(err error) { cliCancel(err) }, errMsg)
+			return
+		}
+	}
+}
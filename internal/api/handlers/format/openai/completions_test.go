@@ -0,0 +1,96 @@
+package openai
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+func runCompletions(t *testing.T, h *OpenAIAPIHandler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/completions", strings.NewReader(body))
+	h.Completions(c)
+	return w
+}
+
+func TestCompletions(t *testing.T) {
+	exec := &scriptedExecutor{responses: []string{
+		`{"id":"chatcmpl-1","object":"chat.completion","created":7,"model":"fake-model","choices":[{"index":0,"message":{"role":"assistant","content":" world"},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`,
+	}}
+	h := newScriptedHandler(t, exec)
+	w := runCompletions(t, h, `{"model":"fake-model","prompt":["Hello"],"suffix":"!","echo":true,"max_tokens":8,"stop":["\n"],"logprobs":2}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+
+	if len(exec.requests) != 1 {
+		t.Fatalf("requests = %d", len(exec.requests))
+	}
+	req := gjson.ParseBytes(exec.requests[0])
+	if msgs := req.Get("messages").Array(); len(msgs) != 1 ||
+		msgs[0].Get("role").String() != "user" || msgs[0].Get("content").String() != suffixInstruction+"Hello"+suffixSeparator+"!" {
+		t.Errorf("messages = %s", req.Get("messages").Raw)
+	}
+	if req.Get("max_tokens").Int() != 8 || req.Get("stop").Raw != `["\n"]` || req.Get("prompt").Exists() || req.Get("suffix").Exists() {
+		t.Errorf("request = %s", req.Raw)
+	}
+	if !req.Get("logprobs").Bool() || req.Get("top_logprobs").Int() != 2 {
+		t.Errorf("logprobs = %s, top_logprobs = %s", req.Get("logprobs").Raw, req.Get("top_logprobs").Raw)
+	}
+
+	resp := gjson.Parse(w.Body.String())
+	if resp.Get("object").String() != "text_completion" || resp.Get("id").String() != "chatcmpl-1" || resp.Get("created").Int() != 7 {
+		t.Errorf("response = %s", resp.Raw)
+	}
+	if choice := resp.Get("choices.0"); choice.Get("text").String() != "Hello world" || choice.Get("finish_reason").String() != "stop" || choice.Get("message").Exists() {
+		t.Errorf("choice = %s", choice.Raw)
+	}
+	if resp.Get("usage.total_tokens").Int() != 7 {
+		t.Errorf("usage = %s", resp.Get("usage").Raw)
+	}
+}
+
+func TestCompletionsStream(t *testing.T) {
+	exec := &scriptedExecutor{responses: []string{
+		"data: {\"id\":\"chatcmpl-1\",\"created\":7,\"model\":\"fake-model\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":\" wor\"}}]}\n\n" +
+			"data: {\"id\":\"chatcmpl-1\",\"created\":7,\"model\":\"fake-model\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"ld\"}}]}\n\n",
+		"data: {\"id\":\"chatcmpl-1\",\"created\":7,\"model\":\"fake-model\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"length\"}]}\n\n",
+		"data: [DONE]\n\n",
+	}}
+	h := newScriptedHandler(t, exec)
+	w := runCompletions(t, h, `{"model":"fake-model","prompt":"Hello","echo":true,"stream":true}`)
+
+	events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	if len(events) != 5 || events[4] != "data: [DONE]" {
+		t.Fatalf("stream = %q", w.Body.String())
+	}
+	var text strings.Builder
+	for _, e := range events[:4] {
+		chunk := gjson.Parse(strings.TrimPrefix(e, "data: "))
+		if chunk.Get("object").String() != "text_completion" || chunk.Get("id").String() != "chatcmpl-1" || chunk.Get("choices.0.delta").Exists() {
+			t.Errorf("chunk = %s", chunk.Raw)
+		}
+		text.WriteString(chunk.Get("choices.0.text").String())
+	}
+	if text.String() != "Hello world" {
+		t.Errorf("text = %q", text.String())
+	}
+	if fr := gjson.Get(strings.TrimPrefix(events[3], "data: "), "choices.0.finish_reason").String(); fr != "length" {
+		t.Errorf("finish_reason = %q", fr)
+	}
+}
+
+func TestCompletionsRejectsBatchedPrompts(t *testing.T) {
+	h := newScriptedHandler(t, &scriptedExecutor{})
+	w := runCompletions(t, h, `{"model":"fake-model","prompt":["a","b"]}`)
+	if w.Code != http.StatusBadRequest || gjson.Get(w.Body.String(), "error.type").String() != "invalid_request_error" {
+		t.Errorf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+}
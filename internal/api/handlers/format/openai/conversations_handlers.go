@@ -0,0 +1,287 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/conversation"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// CreateConversation handles POST /v1/conversations.
+func (h *OpenAIResponsesAPIHandler) CreateConversation(c *gin.Context) {
+	store, ok := conversationStore(c)
+	if !ok {
+		return
+	}
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		conversationError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	var items [][]byte
+	if v := gjson.GetBytes(rawJSON, "items"); v.Exists() {
+		if !v.IsArray() {
+			conversationError(c, http.StatusBadRequest, "items must be an array")
+			return
+		}
+		for _, item := range v.Array() {
+			items = append(items, []byte(item.Raw))
+		}
+	}
+	conv, err := store.Create(c.Request.Context(), c.GetString("apiKey"), conversationMetadata(rawJSON), items)
+	if err != nil {
+		conversationStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, conv)
+}
+
+// GetConversation handles GET /v1/conversations/:id.
+func (h *OpenAIResponsesAPIHandler) GetConversation(c *gin.Context) {
+	store, ok := conversationStore(c)
+	if !ok {
+		return
+	}
+	conv, err := store.Get(c.Request.Context(), c.GetString("apiKey"), c.Param("id"))
+	if err != nil {
+		conversationStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, conv)
+}
+
+// UpdateConversation handles POST /v1/conversations/:id, replacing its metadata.
+func (h *OpenAIResponsesAPIHandler) UpdateConversation(c *gin.Context) {
+	store, ok := conversationStore(c)
+	if !ok {
+		return
+	}
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		conversationError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	conv, err := store.UpdateMetadata(c.Request.Context(), c.GetString("apiKey"), c.Param("id"), conversationMetadata(rawJSON))
+	if err != nil {
+		conversationStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, conv)
+}
+
+// DeleteConversation handles DELETE /v1/conversations/:id.
+func (h *OpenAIResponsesAPIHandler) DeleteConversation(c *gin.Context) {
+	store, ok := conversationStore(c)
+	if !ok {
+		return
+	}
+	id := c.Param("id")
+	if err := store.Delete(c.Request.Context(), c.GetString("apiKey"), id); err != nil {
+		conversationStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "object": "conversation.deleted", "deleted": true})
+}
+
+// CreateConversationItems handles POST /v1/conversations/:id/items.
+func (h *OpenAIResponsesAPIHandler) CreateConversationItems(c *gin.Context) {
+	store, ok := conversationStore(c)
+	if !ok {
+		return
+	}
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		conversationError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	v := gjson.GetBytes(rawJSON, "items")
+	if !v.IsArray() {
+		conversationError(c, http.StatusBadRequest, "items must be an array")
+		return
+	}
+	var items [][]byte
+	for _, item := range v.Array() {
+		items = append(items, []byte(item.Raw))
+	}
+	added, err := store.AppendItems(c.Request.Context(), c.GetString("apiKey"), c.Param("id"), items)
+	if err != nil {
+		conversationStoreError(c, err)
+		return
+	}
+	writeItemList(c, added, false)
+}
+
+// ListConversationItems handles GET /v1/conversations/:id/items.
+// Supports the limit (1-100), order ("asc" or "desc", default "desc") and after query parameters.
+func (h *OpenAIResponsesAPIHandler) ListConversationItems(c *gin.Context) {
+	store, ok := conversationStore(c)
+	if !ok {
+		return
+	}
+	opts := conversation.ListOptions{After: c.Query("after"), Desc: c.Query("order") != "asc"}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			conversationError(c, http.StatusBadRequest, "limit must be between 1 and 100")
+			return
+		}
+		opts.Limit = n
+	}
+	items, hasMore, err := store.ListItems(c.Request.Context(), c.GetString("apiKey"), c.Param("id"), opts)
+	if err != nil {
+		conversationStoreError(c, err)
+		return
+	}
+	writeItemList(c, items, hasMore)
+}
+
+// GetConversationItem handles GET /v1/conversations/:id/items/:item_id.
+func (h *OpenAIResponsesAPIHandler) GetConversationItem(c *gin.Context) {
+	store, ok := conversationStore(c)
+	if !ok {
+		return
+	}
+	item, err := store.GetItem(c.Request.Context(), c.GetString("apiKey"), c.Param("id"), c.Param("item_id"))
+	if err != nil {
+		conversationStoreError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", item)
+}
+
+// DeleteConversationItem handles DELETE /v1/conversations/:id/items/:item_id
+// and returns the conversation.
+func (h *OpenAIResponsesAPIHandler) DeleteConversationItem(c *gin.Context) {
+	store, ok := conversationStore(c)
+	if !ok {
+		return
+	}
+	scope, id := c.GetString("apiKey"), c.Param("id")
+	if err := store.DeleteItem(c.Request.Context(), scope, id, c.Param("item_id")); err != nil {
+		conversationStoreError(c, err)
+		return
+	}
+	conv, err := store.Get(c.Request.Context(), scope, id)
+	if err != nil {
+		conversationStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, conv)
+}
+
+func conversationStore(c *gin.Context) (*conversation.Store, bool) {
+	store := conversation.Default()
+	if store == nil {
+		conversationError(c, http.StatusNotFound, "Conversations are disabled; set conversations.dsn to enable them")
+		return nil, false
+	}
+	return store, true
+}
+
+func conversationMetadata(rawJSON []byte) map[string]string {
+	meta := map[string]string{}
+	gjson.GetBytes(rawJSON, "metadata").ForEach(func(k, v gjson.Result) bool {
+		meta[k.String()] = v.String()
+		return true
+	})
+	return meta
+}
+
+func writeItemList(c *gin.Context, items [][]byte, hasMore bool) {
+	body := []byte(`{"object":"list","data":[],"first_id":null,"last_id":null,"has_more":false}`)
+	body, _ = sjson.SetRawBytes(body, "data", itemArray(bytesToStrings(items)))
+	if len(items) > 0 {
+		body, _ = sjson.SetBytes(body, "first_id", gjson.GetBytes(items[0], "id").String())
+		body, _ = sjson.SetBytes(body, "last_id", gjson.GetBytes(items[len(items)-1], "id").String())
+	}
+	body, _ = sjson.SetBytes(body, "has_more", hasMore)
+	c.Data(http.StatusOK, "application/json", body)
+}
+
+func bytesToStrings(items [][]byte) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = string(item)
+	}
+	return out
+}
+
+func conversationError(c *gin.Context, status int, msg string) {
+	c.JSON(status, format.ErrorResponse{
+		Error: format.ErrorDetail{
+			Message: msg,
+			Type:    "invalid_request_error",
+		},
+	})
+}
+
+func conversationStoreError(c *gin.Context, err error) {
+	if errors.Is(err, conversation.ErrNotFound) {
+		conversationError(c, http.StatusNotFound, "Conversation or item not found")
+		return
+	}
+	c.JSON(http.StatusInternalServerError, format.ErrorResponse{
+		Error: format.ErrorDetail{
+			Message: err.Error(),
+			Type:    "server_error",
+		},
+	})
+}
+
+// conversationRef returns the conversation a Responses request belongs to,
+// given either as an ID or as {"id": ...}.
+func conversationRef(rawJSON []byte) string {
+	v := gjson.GetBytes(rawJSON, "conversation")
+	if v.IsObject() {
+		return v.Get("id").String()
+	}
+	return v.String()
+}
+
+// useConversation prepends the conversation's items to the request input and
+// records the conversation so the new input and output are appended to it once
+// the response completes. It returns the HTTP status and message on failure.
+func (t *responseTurn) useConversation(ctx context.Context, store *conversation.Store, rawJSON []byte, convID string) ([]byte, int, string) {
+	if gjson.GetBytes(rawJSON, "previous_response_id").Exists() {
+		return nil, http.StatusBadRequest, "previous_response_id and conversation cannot be used together"
+	}
+	if store == nil {
+		return nil, http.StatusNotFound, "Conversations are disabled; set conversations.dsn to enable them"
+	}
+	items, err := store.Items(ctx, t.scope, convID)
+	if errors.Is(err, conversation.ErrNotFound) {
+		return nil, http.StatusNotFound, "Conversation with id '" + convID + "' not found"
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, err.Error()
+	}
+	t.conversations, t.conversationID, t.newInput = store, convID, t.input
+	t.input = joinItems(itemArray(bytesToStrings(items)), t.input)
+	body, err := sjson.SetRawBytes(rawJSON, "input", replayItems(t.input))
+	if err != nil {
+		return nil, http.StatusBadRequest, err.Error()
+	}
+	body, _ = sjson.DeleteBytes(body, "conversation")
+	return body, 0, ""
+}
+
+// appendToConversation adds the turn's new input and output items to its conversation.
+func (t *responseTurn) appendToConversation(output []byte) {
+	if t.conversationID == "" {
+		return
+	}
+	var items [][]byte
+	for _, item := range gjson.ParseBytes(joinItems(t.newInput, output)).Array() {
+		items = append(items, []byte(item.Raw))
+	}
+	if _, err := t.conversations.AppendItems(context.Background(), t.scope, t.conversationID, items); err != nil {
+		log.Warnf("failed to append response to conversation %s: %v", t.conversationID, err)
+	}
+}
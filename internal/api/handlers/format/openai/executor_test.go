@@ -0,0 +1,65 @@
+package openai
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// scriptedExecutor answers every request with responses: as one payload when
+// not streaming, or as one chunk per response.
+type scriptedExecutor struct {
+	mu        sync.Mutex
+	responses []string
+	requests  [][]byte
+}
+
+func (e *scriptedExecutor) Identifier() string { return "fake" }
+
+func (e *scriptedExecutor) Execute(_ context.Context, _ *provider.Auth, req provider.Request, _ provider.Options) (provider.Response, error) {
+	e.record(req)
+	return provider.Response{Payload: []byte(e.responses[0])}, nil
+}
+
+func (e *scriptedExecutor) ExecuteStream(_ context.Context, _ *provider.Auth, req provider.Request, _ provider.Options) (<-chan provider.StreamChunk, error) {
+	e.record(req)
+	ch := make(chan provider.StreamChunk, len(e.responses))
+	for _, r := range e.responses {
+		ch <- provider.StreamChunk{Payload: []byte(r)}
+	}
+	close(ch)
+	return ch, nil
+}
+
+func (e *scriptedExecutor) Refresh(_ context.Context, auth *provider.Auth) (*provider.Auth, error) {
+	return auth, nil
+}
+
+func (e *scriptedExecutor) CountTokens(context.Context, *provider.Auth, provider.Request, provider.Options) (provider.Response, error) {
+	return provider.Response{}, nil
+}
+
+func (e *scriptedExecutor) record(req provider.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.requests = append(e.requests, req.Payload)
+}
+
+// newScriptedHandler returns a handler whose requests for "fake-model" run on exec.
+func newScriptedHandler(t *testing.T, exec provider.ProviderExecutor) *OpenAIAPIHandler {
+	t.Helper()
+	manager := provider.NewManager(nil, nil, nil)
+	t.Cleanup(manager.Stop)
+	manager.RegisterExecutor(exec)
+	if _, err := manager.Register(context.Background(), &provider.Auth{ID: "fake-1", Provider: "fake"}); err != nil {
+		t.Fatal(err)
+	}
+	registry.GetGlobalRegistry().RegisterClient("fake-1", "fake", []*registry.ModelInfo{{ID: "fake-model", Type: "fake"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient("fake-1") })
+	return &OpenAIAPIHandler{BaseAPIHandler: format.NewBaseAPIHandlers(&config.SDKConfig{}, nil, manager, nil)}
+}
@@ -0,0 +1,48 @@
+package openai
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/util"
+)
+
+// Limits handles GET /v1/limits. It reports the caller's stream pacing rate
+// and the current availability of each model, optionally narrowed with the
+// model query parameter, so clients can schedule batches around capacity.
+func (h *OpenAIAPIHandler) Limits(c *gin.Context) {
+	ctx := provider.WithClientAPIKey(c.Request.Context(), c.GetString("apiKey"))
+	only := c.Query("model")
+	now := time.Now()
+
+	models := make([]gin.H, 0)
+	for _, model := range h.Models() {
+		id, _ := model["id"].(string)
+		if id == "" || (only != "" && id != only) {
+			continue
+		}
+		avail := h.AuthManager.Availability(ctx, util.GetProviderName(id), id)
+		entry := gin.H{
+			"id":              id,
+			"status":          avail.Status,
+			"available_auths": avail.Usable,
+			"total_auths":     avail.Total,
+		}
+		if !avail.CoolingDownUntil.IsZero() {
+			entry["cooling_down_until"] = avail.CoolingDownUntil.Unix()
+			entry["retry_after"] = int(math.Ceil(avail.CoolingDownUntil.Sub(now).Seconds()))
+		}
+		models = append(models, entry)
+	}
+
+	resp := gin.H{"object": "limits", "models": models}
+	if h.Cfg != nil {
+		if tps := h.Cfg.StreamPacing.RateFor(c.GetString("apiKey")); tps > 0 {
+			resp["rate_limits"] = gin.H{"stream_tokens_per_second": tps}
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
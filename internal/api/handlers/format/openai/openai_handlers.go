@@ -9,10 +9,8 @@ import (
 	"github.com/nghyane/llm-mux/internal/api/handlers/format"
 	"github.com/nghyane/llm-mux/internal/constant"
 	"github.com/nghyane/llm-mux/internal/interfaces"
-	"github.com/nghyane/llm-mux/internal/json"
 	"github.com/nghyane/llm-mux/internal/registry"
 	"github.com/tidwall/gjson"
-	"github.com/tidwall/sjson"
 )
 
 // SSE format prefixes (avoid string allocation per chunk)
@@ -27,6 +25,7 @@ var (
 // It holds a pool of clients to interact with the backend service.
 type OpenAIAPIHandler struct {
 	*format.BaseAPIHandler
+	results *completionResults
 }
 
 // NewOpenAIAPIHandler creates a new OpenAI API handlers instance.
@@ -40,6 +39,7 @@ type OpenAIAPIHandler struct {
 func NewOpenAIAPIHandler(apiHandlers *format.BaseAPIHandler) *OpenAIAPIHandler {
 	return &OpenAIAPIHandler{
 		BaseAPIHandler: apiHandlers,
+		results:        processCompletionResults(apiHandlers.Cfg),
 	}
 }
 
@@ -112,10 +112,10 @@ func (h *OpenAIAPIHandler) ChatCompletions(c *gin.Context) {
 
 }
 
-// Completions handles the /v1/completions endpoint.
-// It determines whether the request is for a streaming or non-streaming response
-// and calls the appropriate handler based on the model provider.
-// This endpoint follows the OpenAI completions API specification.
+// Completions handles the legacy /v1/completions endpoint.
+// The prompt is translated into a single-user-message chat request so it runs
+// through the same IR pipeline, and responses are rendered back in
+// text_completion format.
 //
 // Parameters:
 //   - c: The Gin context containing the HTTP request and response
@@ -132,248 +132,24 @@ func (h *OpenAIAPIHandler) Completions(c *gin.Context) {
 		return
 	}
 
-	streamResult := gjson.GetBytes(rawJSON, "stream")
-	if streamResult.Type == gjson.True {
-		h.handleCompletionsStreamingResponse(c, rawJSON)
-	} else {
-		h.handleCompletionsNonStreamingResponse(c, rawJSON)
-	}
-
-}
-
-// convertCompletionsRequestToChatCompletions converts OpenAI completions API request to chat completions format.
-// This allows the completions endpoint to use the existing chat completions infrastructure.
-//
-// Parameters:
-//   - rawJSON: The raw JSON bytes of the completions request
-//
-// Returns:
-//   - []byte: The converted chat completions request
-func convertCompletionsRequestToChatCompletions(rawJSON []byte) []byte {
-	root := gjson.ParseBytes(rawJSON)
-
-	// Extract prompt from completions request
-	prompt := root.Get("prompt").String()
-	if prompt == "" {
-		prompt = "Complete this:"
-	}
-
-	out := `{"model":"","messages":[{"role":"user","content":""}]}`
-
-	if model := root.Get("model"); model.Exists() {
-		out, _ = sjson.Set(out, "model", model.String())
-	}
-
-	out, _ = sjson.Set(out, "messages.0.content", prompt)
-
-	// Copy other parameters from completions to chat completions
-	if maxTokens := root.Get("max_tokens"); maxTokens.Exists() {
-		out, _ = sjson.Set(out, "max_tokens", maxTokens.Int())
-	}
-
-	if temperature := root.Get("temperature"); temperature.Exists() {
-		out, _ = sjson.Set(out, "temperature", temperature.Float())
-	}
-
-	if topP := root.Get("top_p"); topP.Exists() {
-		out, _ = sjson.Set(out, "top_p", topP.Float())
-	}
-
-	if frequencyPenalty := root.Get("frequency_penalty"); frequencyPenalty.Exists() {
-		out, _ = sjson.Set(out, "frequency_penalty", frequencyPenalty.Float())
-	}
-
-	if presencePenalty := root.Get("presence_penalty"); presencePenalty.Exists() {
-		out, _ = sjson.Set(out, "presence_penalty", presencePenalty.Float())
-	}
-
-	if stop := root.Get("stop"); stop.Exists() {
-		out, _ = sjson.SetRaw(out, "stop", stop.Raw)
-	}
-
-	if stream := root.Get("stream"); stream.Exists() {
-		out, _ = sjson.Set(out, "stream", stream.Bool())
-	}
-
-	if logprobs := root.Get("logprobs"); logprobs.Exists() {
-		out, _ = sjson.Set(out, "logprobs", logprobs.Bool())
-	}
-
-	if topLogprobs := root.Get("top_logprobs"); topLogprobs.Exists() {
-		out, _ = sjson.Set(out, "top_logprobs", topLogprobs.Int())
-	}
-
-	if echo := root.Get("echo"); echo.Exists() {
-		out, _ = sjson.Set(out, "echo", echo.Bool())
-	}
-
-	return []byte(out)
-}
-
-// convertChatCompletionsResponseToCompletions converts chat completions API response back to completions format.
-// This ensures the completions endpoint returns data in the expected format.
-//
-// Parameters:
-//   - rawJSON: The raw JSON bytes of the chat completions response
-//
-// Returns:
-//   - []byte: The converted completions response
-func convertChatCompletionsResponseToCompletions(rawJSON []byte) []byte {
-	root := gjson.ParseBytes(rawJSON)
-
-	// Base completions response structure
-	out := `{"id":"","object":"text_completion","created":0,"model":"","choices":[]}`
-
-	// Copy basic fields
-	if id := root.Get("id"); id.Exists() {
-		out, _ = sjson.Set(out, "id", id.String())
-	}
-
-	if created := root.Get("created"); created.Exists() {
-		out, _ = sjson.Set(out, "created", created.Int())
-	}
-
-	if model := root.Get("model"); model.Exists() {
-		out, _ = sjson.Set(out, "model", model.String())
-	}
-
-	if usage := root.Get("usage"); usage.Exists() {
-		out, _ = sjson.SetRaw(out, "usage", usage.Raw)
-	}
-
-	// Convert choices from chat completions to completions format
-	var choices []any
-	if chatChoices := root.Get("choices"); chatChoices.Exists() && chatChoices.IsArray() {
-		chatChoices.ForEach(func(_, choice gjson.Result) bool {
-			completionsChoice := map[string]any{
-				"index": choice.Get("index").Int(),
-			}
-
-			// Extract text content from message.content
-			if message := choice.Get("message"); message.Exists() {
-				if content := message.Get("content"); content.Exists() {
-					completionsChoice["text"] = content.String()
-				}
-			} else if delta := choice.Get("delta"); delta.Exists() {
-				// For streaming responses, use delta.content
-				if content := delta.Get("content"); content.Exists() {
-					completionsChoice["text"] = content.String()
-				}
-			}
-
-			// Copy finish_reason
-			if finishReason := choice.Get("finish_reason"); finishReason.Exists() {
-				completionsChoice["finish_reason"] = finishReason.String()
-			}
-
-			// Copy logprobs if present
-			if logprobs := choice.Get("logprobs"); logprobs.Exists() {
-				completionsChoice["logprobs"] = logprobs.Value()
-			}
-
-			choices = append(choices, completionsChoice)
-			return true
-		})
-	}
-
-	if len(choices) > 0 {
-		choicesJSON, _ := json.Marshal(choices)
-		out, _ = sjson.SetRaw(out, "choices", string(choicesJSON))
-	}
-
-	return []byte(out)
-}
-
-// convertChatCompletionsStreamChunkToCompletions converts a streaming chat completions chunk to completions format.
-// This handles the real-time conversion of streaming response chunks and filters out empty text responses.
-//
-// Parameters:
-//   - chunkData: The raw JSON bytes of a single chat completions stream chunk
-//
-// Returns:
-//   - []byte: The converted completions stream chunk, or nil if should be filtered out
-func convertChatCompletionsStreamChunkToCompletions(chunkData []byte) []byte {
-	root := gjson.ParseBytes(chunkData)
-
-	hasContent := false
-	if chatChoices := root.Get("choices"); chatChoices.Exists() && chatChoices.IsArray() {
-		chatChoices.ForEach(func(_, choice gjson.Result) bool {
-			if delta := choice.Get("delta"); delta.Exists() {
-				if content := delta.Get("content"); content.Exists() && content.String() != "" {
-					hasContent = true
-					return false // Break out of forEach
-				}
-			}
-			// Also check for finish_reason to ensure we don't skip final chunks
-			if finishReason := choice.Get("finish_reason"); finishReason.Exists() && finishReason.String() != "" && finishReason.String() != "null" {
-				hasContent = true
-				return false // Break out of forEach
-			}
-			return true
-		})
-	}
-
-	// If no meaningful content, return nil to indicate this chunk should be skipped
-	if !hasContent {
-		return nil
-	}
-
-	// Base completions stream response structure
-	out := `{"id":"","object":"text_completion","created":0,"model":"","choices":[]}`
-
-	// Copy basic fields
-	if id := root.Get("id"); id.Exists() {
-		out, _ = sjson.Set(out, "id", id.String())
-	}
-
-	if created := root.Get("created"); created.Exists() {
-		out, _ = sjson.Set(out, "created", created.Int())
-	}
-
-	if model := root.Get("model"); model.Exists() {
-		out, _ = sjson.Set(out, "model", model.String())
-	}
-
-	// Convert choices from chat completions delta to completions format
-	var choices []any
-	if chatChoices := root.Get("choices"); chatChoices.Exists() && chatChoices.IsArray() {
-		chatChoices.ForEach(func(_, choice gjson.Result) bool {
-			completionsChoice := map[string]any{
-				"index": choice.Get("index").Int(),
-			}
-
-			// Extract text content from delta.content
-			if delta := choice.Get("delta"); delta.Exists() {
-				if content := delta.Get("content"); content.Exists() && content.String() != "" {
-					completionsChoice["text"] = content.String()
-				} else {
-					completionsChoice["text"] = ""
-				}
-			} else {
-				completionsChoice["text"] = ""
-			}
-
-			// Copy finish_reason
-			if finishReason := choice.Get("finish_reason"); finishReason.Exists() && finishReason.String() != "null" {
-				completionsChoice["finish_reason"] = finishReason.String()
-			}
-
-			// Copy logprobs if present
-			if logprobs := choice.Get("logprobs"); logprobs.Exists() {
-				completionsChoice["logprobs"] = logprobs.Value()
-			}
-
-			choices = append(choices, completionsChoice)
-			return true
+	chatJSON, opts, err := convertCompletionsRequestToChatCompletions(rawJSON)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: err.Error(),
+				Type:    "invalid_request_error",
+			},
 		})
+		return
 	}
 
-	if len(choices) > 0 {
-		choicesJSON, _ := json.Marshal(choices)
-		out, _ = sjson.SetRaw(out, "choices", string(choicesJSON))
+	streamResult := gjson.GetBytes(rawJSON, "stream")
+	if streamResult.Type == gjson.True {
+		h.handleCompletionsStreamingResponse(c, chatJSON, opts)
+	} else {
+		h.handleCompletionsNonStreamingResponse(c, chatJSON, opts)
 	}
 
-	return []byte(out)
 }
 
 // handleNonStreamingResponse handles non-streaming chat completion responses
@@ -384,6 +160,10 @@ func convertChatCompletionsStreamChunkToCompletions(chunkData []byte) []byte {
 //   - c: The Gin context containing the HTTP request and response
 //   - rawJSON: The raw JSON bytes of the OpenAI-compatible request
 func (h *OpenAIAPIHandler) handleNonStreamingResponse(c *gin.Context, rawJSON []byte) {
+	if wd := h.Cfg.GenerationWatchdog; wd.Duration() > 0 {
+		h.handleWatchdogResponse(c, rawJSON, wd)
+		return
+	}
 	c.Header("Content-Type", "application/json")
 
 	modelName := gjson.GetBytes(rawJSON, "model").String()
@@ -394,6 +174,9 @@ func (h *OpenAIAPIHandler) handleNonStreamingResponse(c *gin.Context, rawJSON []
 		cliCancel(errMsg.Error)
 		return
 	}
+	if n := autoContinuations(h.Cfg.AutoContinue, rawJSON); n > 0 {
+		resp = h.continueCompletion(cliCtx, rawJSON, resp, n, h.Cfg.AutoContinue.Prompt, h.GetAlt(c))
+	}
 	_, _ = c.Writer.Write(resp)
 	cliCancel()
 }
@@ -409,7 +192,6 @@ func (h *OpenAIAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON []byt
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
-	c.Header("Access-Control-Allow-Origin", "*")
 
 	flusher, ok := c.Writer.(http.Flusher)
 	if !ok {
@@ -425,107 +207,37 @@ func (h *OpenAIAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON []byt
 	modelName := gjson.GetBytes(rawJSON, "model").String()
 	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
 	dataChan, errChan := h.ExecuteStreamWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, h.GetAlt(c))
-	h.handleStreamResult(c, flusher, func(err error) { cliCancel(err) }, dataChan, errChan)
-}
-
-// handleCompletionsNonStreamingResponse handles non-streaming completions responses.
-// It converts completions request to chat completions format, sends to backend,
-// then converts the response back to completions format before sending to client.
-//
-// Parameters:
-//   - c: The Gin context containing the HTTP request and response
-//   - rawJSON: The raw JSON bytes of the OpenAI-compatible completions request
-func (h *OpenAIAPIHandler) handleCompletionsNonStreamingResponse(c *gin.Context, rawJSON []byte) {
-	c.Header("Content-Type", "application/json")
-
-	chatCompletionsJSON := convertCompletionsRequestToChatCompletions(rawJSON)
-
-	modelName := gjson.GetBytes(chatCompletionsJSON, "model").String()
-	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
-	resp, errMsg := h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), modelName, chatCompletionsJSON, "")
-	if errMsg != nil {
-		h.WriteErrorResponse(c, errMsg)
-		cliCancel(errMsg.Error)
-		return
+	if n := autoContinuations(h.Cfg.AutoContinue, rawJSON); n > 0 && dataChan != nil {
+		dataChan, errChan = h.continueStream(cliCtx, rawJSON, n, h.Cfg.AutoContinue.Prompt, h.GetAlt(c), dataChan, errChan)
 	}
-	completionsResp := convertChatCompletionsResponseToCompletions(resp)
-	_, _ = c.Writer.Write(completionsResp)
-	cliCancel()
+	h.handleStreamResult(c, flusher, func(err error) { cliCancel(err) }, h.NewStreamEnding(c, rawJSON), dataChan, errChan)
 }
 
-// handleCompletionsStreamingResponse handles streaming completions responses.
-// It converts completions request to chat completions format, streams from backend,
-// then converts each response chunk back to completions format before sending to client.
-//
-// Parameters:
-//   - c: The Gin context containing the HTTP request and response
-//   - rawJSON: The raw JSON bytes of the OpenAI-compatible completions request
-func (h *OpenAIAPIHandler) handleCompletionsStreamingResponse(c *gin.Context, rawJSON []byte) {
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-	c.Header("Connection", "keep-alive")
-	c.Header("Access-Control-Allow-Origin", "*")
-
-	flusher, ok := c.Writer.(http.Flusher)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, format.ErrorResponse{
-			Error: format.ErrorDetail{
-				Message: "Streaming not supported",
-				Type:    "server_error",
-			},
-		})
-		return
-	}
-
-	chatCompletionsJSON := convertCompletionsRequestToChatCompletions(rawJSON)
-
-	modelName := gjson.GetBytes(chatCompletionsJSON, "model").String()
-	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
-	dataChan, errChan := h.ExecuteStreamWithAuthManager(cliCtx, h.HandlerType(), modelName, chatCompletionsJSON, "")
-
+func (h *OpenAIAPIHandler) handleStreamResult(c *gin.Context, flusher http.Flusher, cancel func(error), ending *format.StreamEnding, data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
 	sw := format.NewSSEWriter(c.Writer)
-	for {
-		select {
-		case <-c.Request.Context().Done():
-			cliCancel(c.Request.Context().Err())
-			return
-		case chunk, isOk := <-dataChan:
-			if !isOk {
-				sw.Write(sseDoneMarker)
-				flusher.Flush()
-				cliCancel()
-				return
-			}
-			converted := convertChatCompletionsStreamChunkToCompletions(chunk)
-			if converted != nil {
-				sw.Write(sseDataPrefix)
-				sw.Write(converted)
-				sw.Write(sseNewline)
-				if !sw.Ok() {
-					cliCancel(sw.Err())
-					return
-				}
-				flusher.Flush()
-			}
-		case errMsg, isOk := <-errChan:
-			if !isOk {
-				continue
-			}
-			if errMsg != nil {
-				h.WriteErrorResponse(c, errMsg)
-				flusher.Flush()
-			}
-			var execErr error
-			if errMsg != nil {
-				execErr = errMsg.Error
+	writeChunk := func(chunk []byte) bool {
+		if len(chunk) > 6 && (bytes.HasPrefix(chunk, sseEventPrefix) || bytes.HasPrefix(chunk, sseDataPrefix)) {
+			sw.Write(chunk)
+		} else {
+			sw.Write(sseDataPrefix)
+			sw.Write(chunk)
+			sw.Write(sseNewline)
+		}
+		if !sw.Ok() {
+			cancel(sw.Err())
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+	write := func(chunk []byte) bool {
+		for _, out := range ending.Rewrite(chunk) {
+			if !writeChunk(out) {
+				return false
 			}
-			cliCancel(execErr)
-			return
 		}
+		return true
 	}
-}
-func (h *OpenAIAPIHandler) handleStreamResult(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
-	sw := format.NewSSEWriter(c.Writer)
 	for {
 		select {
 		case <-c.Request.Context().Done():
@@ -533,37 +245,50 @@ func (h *OpenAIAPIHandler) handleStreamResult(c *gin.Context, flusher http.Flush
 			return
 		case chunk, ok := <-data:
 			if !ok {
-				sw.Write(sseDoneMarker)
-				flusher.Flush()
+				if errMsg := format.PendingStreamError(errs); errMsg != nil {
+					h.writeStreamError(c, flusher, cancel, errMsg)
+					return
+				}
+				if ending.Done() {
+					sw.Write(sseDoneMarker)
+					flusher.Flush()
+				}
 				cancel(nil)
 				return
 			}
-			if len(chunk) > 6 && (bytes.HasPrefix(chunk, sseEventPrefix) || bytes.HasPrefix(chunk, sseDataPrefix)) {
-				sw.Write(chunk)
-			} else {
-				sw.Write(sseDataPrefix)
-				sw.Write(chunk)
-				sw.Write(sseNewline)
-			}
-			if !sw.Ok() {
-				cancel(sw.Err())
+			if !write(chunk) {
 				return
 			}
-			flusher.Flush()
 		case errMsg, ok := <-errs:
 			if !ok {
+				errs = nil
 				continue
 			}
-			if errMsg != nil {
-				h.WriteErrorResponse(c, errMsg)
-				flusher.Flush()
-			}
-			var execErr error
-			if errMsg != nil {
-				execErr = errMsg.Error
+			// Chunks produced before the failure are still buffered.
+			for chunk := range data {
+				if !write(chunk) {
+					return
+				}
 			}
-			cancel(execErr)
+			h.writeStreamError(c, flusher, cancel, errMsg)
 			return
 		}
 	}
 }
+
+// writeStreamError ends a chat or completions stream. Before anything was sent
+// the client gets a regular error response; afterwards an error chunk and [DONE],
+// which OpenAI SDKs raise as an APIError.
+func (h *OpenAIAPIHandler) writeStreamError(c *gin.Context, flusher http.Flusher, cancel func(error), errMsg *interfaces.ErrorMessage) {
+	if errMsg == nil {
+		cancel(nil)
+		return
+	}
+	if c.Writer.Written() {
+		_, _ = c.Writer.Write(format.OpenAIStreamError(errMsg))
+	} else {
+		h.WriteErrorResponse(c, errMsg)
+	}
+	flusher.Flush()
+	cancel(errMsg.Error)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/nghyane/llm-mux/internal/api/handlers/format"
 	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/nghyane/llm-mux/internal/conversation"
 	"github.com/nghyane/llm-mux/internal/interfaces"
 	"github.com/nghyane/llm-mux/internal/registry"
 	"github.com/tidwall/gjson"
@@ -17,6 +18,7 @@ import (
 // It holds a pool of clients to interact with the backend service.
 type OpenAIResponsesAPIHandler struct {
 	*format.BaseAPIHandler
+	responses *responseStore
 }
 
 // NewOpenAIResponsesAPIHandler creates a new OpenAIResponses API handlers instance.
@@ -30,6 +32,7 @@ type OpenAIResponsesAPIHandler struct {
 func NewOpenAIResponsesAPIHandler(apiHandlers *format.BaseAPIHandler) *OpenAIResponsesAPIHandler {
 	return &OpenAIResponsesAPIHandler{
 		BaseAPIHandler: apiHandlers,
+		responses:      newResponseStore(responseStoreTTL, responseStoreMaxEntries),
 	}
 }
 
@@ -73,11 +76,20 @@ func (h *OpenAIResponsesAPIHandler) Responses(c *gin.Context) {
 		return
 	}
 
+	rawJSON, turn := h.responses.prepare(rawJSON, c.GetString("apiKey"))
+	if convID := conversationRef(rawJSON); convID != "" {
+		body, status, msg := turn.useConversation(c.Request.Context(), conversation.Default(), rawJSON, convID)
+		if status != 0 {
+			conversationError(c, status, msg)
+			return
+		}
+		rawJSON = body
+	}
 	streamResult := gjson.GetBytes(rawJSON, "stream")
 	if streamResult.Type == gjson.True {
-		h.handleStreamingResponse(c, rawJSON)
+		h.handleStreamingResponse(c, rawJSON, turn)
 	} else {
-		h.handleNonStreamingResponse(c, rawJSON)
+		h.handleNonStreamingResponse(c, rawJSON, turn)
 	}
 
 }
@@ -89,7 +101,8 @@ func (h *OpenAIResponsesAPIHandler) Responses(c *gin.Context) {
 // Parameters:
 //   - c: The Gin context containing the HTTP request and response
 //   - rawJSON: The raw JSON bytes of the OpenAIResponses-compatible request
-func (h *OpenAIResponsesAPIHandler) handleNonStreamingResponse(c *gin.Context, rawJSON []byte) {
+//   - turn: Records the conversation for later previous_response_id lookups
+func (h *OpenAIResponsesAPIHandler) handleNonStreamingResponse(c *gin.Context, rawJSON []byte, turn *responseTurn) {
 	c.Header("Content-Type", "application/json")
 
 	modelName := gjson.GetBytes(rawJSON, "model").String()
@@ -103,6 +116,7 @@ func (h *OpenAIResponsesAPIHandler) handleNonStreamingResponse(c *gin.Context, r
 		h.WriteErrorResponse(c, errMsg)
 		return
 	}
+	turn.completeFromResponse(resp)
 	_, _ = c.Writer.Write(resp)
 }
 
@@ -113,11 +127,11 @@ func (h *OpenAIResponsesAPIHandler) handleNonStreamingResponse(c *gin.Context, r
 // Parameters:
 //   - c: The Gin context containing the HTTP request and response
 //   - rawJSON: The raw JSON bytes of the OpenAIResponses-compatible request
-func (h *OpenAIResponsesAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON []byte) {
+//   - turn: Records the conversation for later previous_response_id lookups
+func (h *OpenAIResponsesAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON []byte, turn *responseTurn) {
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
-	c.Header("Access-Control-Allow-Origin", "*")
 
 	flusher, ok := c.Writer.(http.Flusher)
 	if !ok {
@@ -133,11 +147,28 @@ func (h *OpenAIResponsesAPIHandler) handleStreamingResponse(c *gin.Context, rawJ
 	modelName := gjson.GetBytes(rawJSON, "model").String()
 	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
 	dataChan, errChan := h.ExecuteStreamWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, "")
-	h.forwardResponsesStream(c, flusher, func(err error) { cliCancel(err) }, dataChan, errChan)
+	rec := &responseStreamRecorder{turn: turn}
+	h.forwardResponsesStream(c, flusher, func(err error) { cliCancel(err) }, dataChan, errChan, rec)
 }
 
-func (h *OpenAIResponsesAPIHandler) forwardResponsesStream(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
+func (h *OpenAIResponsesAPIHandler) forwardResponsesStream(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage, rec *responseStreamRecorder) {
 	sw := format.NewSSEWriter(c.Writer)
+	write := func(chunk []byte) bool {
+		rec.observe(chunk)
+
+		if bytes.HasPrefix(chunk, []byte("event:")) {
+			sw.Write([]byte("\n"))
+		}
+		sw.Write(chunk)
+		sw.Write([]byte("\n"))
+
+		if !sw.Ok() {
+			cancel(sw.Err())
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
 	for {
 		select {
 		case <-c.Request.Context().Done():
@@ -145,37 +176,50 @@ func (h *OpenAIResponsesAPIHandler) forwardResponsesStream(c *gin.Context, flush
 			return
 		case chunk, ok := <-data:
 			if !ok {
+				if errMsg := format.PendingStreamError(errs); errMsg != nil {
+					h.writeStreamError(c, flusher, cancel, errMsg, rec.id)
+					return
+				}
 				sw.Write([]byte("\n"))
 				flusher.Flush()
+				rec.finish()
 				cancel(nil)
 				return
 			}
-
-			if bytes.HasPrefix(chunk, []byte("event:")) {
-				sw.Write([]byte("\n"))
-			}
-			sw.Write(chunk)
-			sw.Write([]byte("\n"))
-
-			if !sw.Ok() {
-				cancel(sw.Err())
+			if !write(chunk) {
 				return
 			}
-			flusher.Flush()
 		case errMsg, ok := <-errs:
 			if !ok {
+				errs = nil
 				continue
 			}
-			if errMsg != nil {
-				h.WriteErrorResponse(c, errMsg)
-				flusher.Flush()
-			}
-			var execErr error
-			if errMsg != nil {
-				execErr = errMsg.Error
+			// Events produced before the failure are still buffered.
+			for chunk := range data {
+				if !write(chunk) {
+					return
+				}
 			}
-			cancel(execErr)
+			h.writeStreamError(c, flusher, cancel, errMsg, rec.id)
 			return
 		}
 	}
 }
+
+// writeStreamError ends a Responses stream. Before anything was sent the client
+// gets a regular error response; afterwards a response.failed event. The failed
+// response is not stored for previous_response_id.
+func (h *OpenAIResponsesAPIHandler) writeStreamError(c *gin.Context, flusher http.Flusher, cancel func(error), errMsg *interfaces.ErrorMessage, responseID string) {
+	if errMsg == nil {
+		cancel(nil)
+		return
+	}
+	if c.Writer.Written() {
+		_, _ = c.Writer.Write([]byte("\n"))
+		_, _ = c.Writer.Write(format.ResponsesStreamFailed(responseID, errMsg))
+	} else {
+		h.WriteErrorResponse(c, errMsg)
+	}
+	flusher.Flush()
+	cancel(errMsg.Error)
+}
@@ -0,0 +1,250 @@
+package openai
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/conversation"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	responseStoreTTL        = time.Hour
+	responseStoreMaxEntries = 1024
+)
+
+// responseStore keeps the conversation of recent Responses API responses so a
+// follow-up request can reference it with previous_response_id. Upstreams are
+// stateless, so the stored items are replayed as input instead.
+type responseStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	max     int
+	entries map[string]responseEntry
+	now     func() time.Time
+}
+
+type responseEntry struct {
+	items   []byte // JSON array of input and output items
+	expires time.Time
+}
+
+func newResponseStore(ttl time.Duration, maxEntries int) *responseStore {
+	return &responseStore{
+		ttl:     ttl,
+		max:     maxEntries,
+		entries: make(map[string]responseEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *responseStore) get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok || !s.now().Before(e.expires) {
+		return nil, false
+	}
+	return e.items, true
+}
+
+func (s *responseStore) put(key string, items []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= s.max {
+		s.evict(now)
+	}
+	s.entries[key] = responseEntry{items: items, expires: now.Add(s.ttl)}
+}
+
+// evict drops expired entries, or the one expiring first when none has.
+// Caller must hold s.mu.
+func (s *responseStore) evict(now time.Time) {
+	var oldest string
+	var oldestExpires time.Time
+	for k, e := range s.entries {
+		if !now.Before(e.expires) {
+			delete(s.entries, k)
+			continue
+		}
+		if oldest == "" || e.expires.Before(oldestExpires) {
+			oldest, oldestExpires = k, e.expires
+		}
+	}
+	if len(s.entries) >= s.max && oldest != "" {
+		delete(s.entries, oldest)
+	}
+}
+
+// responseTurn tracks one Responses API request so its conversation can be
+// stored once the response completes.
+type responseTurn struct {
+	store *responseStore
+	scope string // client API key; response IDs are only visible to their client
+	input []byte // JSON array of the full input, including replayed items
+	skip  bool   // request set store: false
+
+	conversations  *conversation.Store
+	conversationID string
+	newInput       []byte // input items not yet in the conversation
+}
+
+// prepare replaces previous_response_id with the stored conversation it refers
+// to. Unknown IDs are left in place for upstreams that keep their own state.
+func (s *responseStore) prepare(rawJSON []byte, scope string) ([]byte, *responseTurn) {
+	turn := &responseTurn{store: s, scope: scope, input: inputItems(gjson.GetBytes(rawJSON, "input"))}
+	if v := gjson.GetBytes(rawJSON, "store"); v.Exists() && !v.Bool() {
+		turn.skip = true
+	}
+	prevID := gjson.GetBytes(rawJSON, "previous_response_id").String()
+	if prevID == "" {
+		return rawJSON, turn
+	}
+	prior, ok := s.get(scope + "/" + prevID)
+	if !ok {
+		return rawJSON, turn
+	}
+	turn.input = joinItems(prior, turn.input)
+	body, err := sjson.SetRawBytes(rawJSON, "input", replayItems(turn.input))
+	if err != nil {
+		return rawJSON, turn
+	}
+	body, _ = sjson.DeleteBytes(body, "previous_response_id")
+	return body, turn
+}
+
+// complete stores the input and output items under the response ID. Reasoning
+// items without encrypted content cannot be replayed and are dropped.
+func (t *responseTurn) complete(responseID string, output []byte) {
+	if t == nil {
+		return
+	}
+	var kept []string
+	for _, item := range gjson.ParseBytes(output).Array() {
+		if item.Get("type").String() == "reasoning" && item.Get("encrypted_content").String() == "" {
+			continue
+		}
+		kept = append(kept, item.Raw)
+	}
+	output = itemArray(kept)
+	t.appendToConversation(output)
+	if t.skip || responseID == "" {
+		return
+	}
+	t.store.put(t.scope+"/"+responseID, joinItems(t.input, output))
+}
+
+// completeFromResponse stores the conversation of a non-streaming response body.
+func (t *responseTurn) completeFromResponse(body []byte) {
+	root := gjson.ParseBytes(body)
+	t.complete(root.Get("id").String(), []byte(root.Get("output").Raw))
+}
+
+// inputItems normalizes the input field to a JSON array of items.
+func inputItems(input gjson.Result) []byte {
+	switch {
+	case input.IsArray():
+		return []byte(input.Raw)
+	case input.Type == gjson.String:
+		item, _ := sjson.SetBytes([]byte(`{"role":"user"}`), "content", input.String())
+		return []byte(fmt.Sprintf("[%s]", item))
+	}
+	return []byte("[]")
+}
+
+// replayItems drops the IDs of items sent back upstream, which stateless
+// upstreams would try to resolve. Reasoning items keep theirs as it is required.
+func replayItems(items []byte) []byte {
+	var out []string
+	for _, item := range gjson.ParseBytes(items).Array() {
+		raw := item.Raw
+		if item.Get("id").Exists() && item.Get("type").String() != "reasoning" {
+			if stripped, err := sjson.Delete(raw, "id"); err == nil {
+				raw = stripped
+			}
+		}
+		out = append(out, raw)
+	}
+	return itemArray(out)
+}
+
+func itemArray(items []string) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, item := range items {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(item)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
+
+// joinItems concatenates two JSON arrays of items.
+func joinItems(a, b []byte) []byte {
+	a, b = bytes.TrimSpace(a), bytes.TrimSpace(b)
+	switch {
+	case len(a) < 2 || bytes.Equal(a, []byte("[]")):
+		if len(b) < 2 {
+			return []byte("[]")
+		}
+		return b
+	case len(b) < 2 || bytes.Equal(b, []byte("[]")):
+		return a
+	}
+	out := make([]byte, 0, len(a)+len(b))
+	out = append(out, a[:len(a)-1]...)
+	out = append(out, ',')
+	return append(out, b[1:]...)
+}
+
+// responseStreamRecorder collects the output items of a streamed response.
+type responseStreamRecorder struct {
+	turn      *responseTurn
+	id        string
+	items     []string
+	completed []byte
+}
+
+// observe inspects one stream chunk for the response ID and finished output items.
+func (r *responseStreamRecorder) observe(chunk []byte) {
+	for _, line := range bytes.Split(chunk, []byte("\n")) {
+		data, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data:"))
+		if !ok {
+			continue
+		}
+		ev := gjson.ParseBytes(bytes.TrimSpace(data))
+		switch ev.Get("type").String() {
+		case "response.created", "response.done":
+			if id := ev.Get("response.id").String(); id != "" {
+				r.id = id
+			}
+		case "response.completed":
+			if id := ev.Get("response.id").String(); id != "" {
+				r.id = id
+			}
+			if out := ev.Get("response.output"); out.IsArray() && len(out.Array()) > 0 {
+				r.completed = []byte(out.Raw)
+			}
+		case "response.output_item.done":
+			if item := ev.Get("item"); item.IsObject() {
+				r.items = append(r.items, item.Raw)
+			}
+		}
+	}
+}
+
+// finish stores the conversation, preferring the output reported by
+// response.completed over the individually collected items.
+func (r *responseStreamRecorder) finish() {
+	output := r.completed
+	if output == nil {
+		output = itemArray(r.items)
+	}
+	r.turn.complete(r.id, output)
+}
@@ -0,0 +1,47 @@
+package openai
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestResponseStorePreviousResponseID(t *testing.T) {
+	s := newResponseStore(time.Hour, 8)
+
+	body, turn := s.prepare([]byte(`{"model":"m","input":"hi"}`), "key")
+	if string(body) != `{"model":"m","input":"hi"}` {
+		t.Fatalf("request without previous_response_id was rewritten: %s", body)
+	}
+	turn.completeFromResponse([]byte(`{"id":"resp_1","output":[
+		{"type":"reasoning","id":"rs_1","summary":[]},
+		{"type":"message","role":"assistant","content":[{"type":"output_text","text":"hello"}]}]}`))
+
+	rec := &responseStreamRecorder{}
+	body, rec.turn = s.prepare([]byte(`{"model":"m","previous_response_id":"resp_1","input":[{"role":"user","content":"again"}]}`), "key")
+	if gjson.GetBytes(body, "previous_response_id").Exists() {
+		t.Fatal("previous_response_id was not removed")
+	}
+	input := gjson.GetBytes(body, "input").Array()
+	if len(input) != 3 || input[0].Get("content").String() != "hi" ||
+		input[1].Get("content.0.text").String() != "hello" || input[2].Get("content").String() != "again" {
+		t.Fatalf("input = %s", gjson.GetBytes(body, "input").Raw)
+	}
+
+	rec.observe([]byte("event: response.created\ndata: {\"type\":\"response.created\",\"response\":{\"id\":\"resp_2\"}}"))
+	rec.observe([]byte("event: response.output_item.done\ndata: {\"type\":\"response.output_item.done\",\"item\":{\"type\":\"function_call\",\"call_id\":\"c1\",\"name\":\"f\",\"arguments\":\"{}\"}}"))
+	rec.finish()
+	items, ok := s.get("key/resp_2")
+	if !ok || len(gjson.ParseBytes(items).Array()) != 4 {
+		t.Fatalf("stored items = %s", items)
+	}
+
+	if _, ok := s.get("other/resp_2"); ok {
+		t.Fatal("response visible to another client key")
+	}
+	body, _ = s.prepare([]byte(`{"previous_response_id":"resp_missing","input":"x"}`), "key")
+	if gjson.GetBytes(body, "previous_response_id").String() != "resp_missing" {
+		t.Fatal("unknown previous_response_id should pass through")
+	}
+}
@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/tidwall/gjson"
+)
+
+func runStreamResult(t *testing.T, chunks []string, errMsg *interfaces.ErrorMessage) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	c.Header("Content-Type", "text/event-stream")
+
+	data := make(chan []byte, len(chunks))
+	for _, chunk := range chunks {
+		data <- []byte(chunk)
+	}
+	close(data)
+	errs := make(chan *interfaces.ErrorMessage, 1)
+	errs <- errMsg
+	close(errs)
+
+	h := &OpenAIAPIHandler{BaseAPIHandler: &format.BaseAPIHandler{}}
+	h.handleStreamResult(c, c.Writer, func(error) {}, nil, data, errs)
+	return w
+}
+
+func TestHandleStreamResultMidStreamError(t *testing.T) {
+	w := runStreamResult(t, []string{`{"choices":[{"delta":{"content":"hi"}}]}`},
+		&interfaces.ErrorMessage{StatusCode: http.StatusTooManyRequests, Error: errors.New("slow down")})
+
+	events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	if len(events) != 3 || events[2] != "data: [DONE]" {
+		t.Fatalf("stream = %q", w.Body.String())
+	}
+	if !strings.Contains(events[0], `"hi"`) {
+		t.Fatalf("buffered chunk was dropped: %q", events[0])
+	}
+	errJSON := strings.TrimPrefix(events[1], "data: ")
+	if gjson.Get(errJSON, "error.type").String() != "rate_limit_error" || gjson.Get(errJSON, "error.message").String() != "slow down" {
+		t.Fatalf("error chunk = %s", errJSON)
+	}
+}
+
+func TestHandleStreamResultErrorBeforeFirstChunk(t *testing.T) {
+	w := runStreamResult(t, nil, &interfaces.ErrorMessage{StatusCode: 529, Error: errors.New("overloaded")})
+
+	if w.Code != 529 {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("content type = %q", ct)
+	}
+	if gjson.Get(w.Body.String(), "error.type").String() != "server_error" {
+		t.Fatalf("body = %s", w.Body.String())
+	}
+}
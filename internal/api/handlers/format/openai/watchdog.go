@@ -0,0 +1,333 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// watchdogContinueLimit bounds how long a generation keeps running after its
+// partial result was returned.
+const watchdogContinueLimit = 30 * time.Minute
+
+var errEmptyCompletion = errors.New("failed to build chat completion")
+
+// completionResults holds chat completions finished in the background after the
+// generation watchdog returned a partial result, keyed by completionResultKey.
+type completionResults struct {
+	store   *responseStore
+	pending sync.Map // key -> struct{}
+	journal *completionJournal
+}
+
+var (
+	sharedResultsOnce sync.Once
+	sharedResults     *completionResults
+)
+
+// processCompletionResults returns the results shared by all OpenAI handlers,
+// recovered from the journal configured at startup on first use.
+func processCompletionResults(cfg *config.SDKConfig) *completionResults {
+	sharedResultsOnce.Do(func() {
+		var wd config.GenerationWatchdogConfig
+		if cfg != nil {
+			wd = cfg.GenerationWatchdog
+		}
+		sharedResults = newCompletionResults(watchdogJournalPath(wd))
+	})
+	return sharedResults
+}
+
+// newCompletionResults returns results journaled to journalPath, loading the
+// ones a previous process left there.
+func newCompletionResults(journalPath string) *completionResults {
+	r := &completionResults{
+		store:   newResponseStore(responseStoreTTL, responseStoreMaxEntries),
+		journal: &completionJournal{path: journalPath},
+	}
+	recovered, err := r.journal.recoverResults(time.Now())
+	if err != nil {
+		log.Warnf("watchdog journal %s: %v", journalPath, err)
+	}
+	for key, body := range recovered {
+		r.store.put(key, body)
+	}
+	return r
+}
+
+func watchdogJournalPath(wd config.GenerationWatchdogConfig) string {
+	if p := strings.TrimSpace(wd.Journal); p != "" {
+		return p
+	}
+	dir := "logs"
+	if base := util.WritablePath(); base != "" {
+		dir = filepath.Join(base, "logs")
+	}
+	return filepath.Join(dir, "watchdog-results.jsonl")
+}
+
+// completionResultKey scopes a result ID to the client API key, which is
+// hashed since keys are journaled to disk.
+func completionResultKey(apiKey, id string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8]) + "/" + id
+}
+
+// start marks the generation of key as running in the background.
+func (r *completionResults) start(key string) {
+	r.pending.Store(key, struct{}{})
+	r.journal.add(journalRecord{Key: key, State: journalPending, Time: time.Now()})
+}
+
+// finish stores the completed response of key.
+func (r *completionResults) finish(key string, body []byte) {
+	r.store.put(key, body)
+	r.journal.add(journalRecord{Key: key, State: journalDone, Body: body, Time: time.Now()})
+}
+
+// handleWatchdogResponse serves a non-streaming chat completion from an
+// upstream stream, so the content generated so far can be returned once the
+// generation-watchdog timeout elapses.
+func (h *OpenAIAPIHandler) handleWatchdogResponse(c *gin.Context, rawJSON []byte, wd config.GenerationWatchdogConfig) {
+	c.Header("Content-Type", "application/json")
+
+	modelName := gjson.GetBytes(rawJSON, "model").String()
+	streamJSON, _ := sjson.SetBytes(rawJSON, "stream", true)
+	streamJSON, _ = sjson.SetBytes(streamJSON, "stream_options.include_usage", true)
+
+	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
+	execCtx, cancelExec := cliCtx, context.CancelFunc(func() {})
+	if wd.Continue {
+		// The upstream may outlive this request, so it must not hold on to the
+		// gin context, which is reused once the handler returns.
+		detached := provider.WithClientAPIKey(context.WithoutCancel(c.Request.Context()), c.GetString("apiKey"))
+		execCtx, cancelExec = context.WithTimeout(detached, watchdogContinueLimit)
+	}
+	data, errs := h.ExecuteStreamWithAuthManager(execCtx, h.HandlerType(), modelName, streamJSON, h.GetAlt(c))
+
+	acc := &completionAccumulator{model: modelName}
+	timer := time.NewTimer(wd.Duration())
+	defer timer.Stop()
+	fail := func(errMsg *interfaces.ErrorMessage) {
+		cancelExec()
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+	}
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			cancelExec()
+			cliCancel(c.Request.Context().Err())
+			return
+		case chunk, ok := <-data:
+			if !ok {
+				if errMsg := format.PendingStreamError(errs); errMsg != nil {
+					fail(errMsg)
+					return
+				}
+				cancelExec()
+				h.writeCompletion(c, cliCancel, acc.response(acc.finish, true))
+				return
+			}
+			acc.add(chunk)
+		case errMsg, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			fail(errMsg)
+			return
+		case <-timer.C:
+			partial := acc.response(ir.FinishReasonMaxTokens, false)
+			ext := map[string]any{"partial": true, "reason": "timeout"}
+			if wd.Continue {
+				id := "chatcmpl-" + uuid.NewString()
+				ext["result_id"] = id
+				key := completionResultKey(c.GetString("apiKey"), id)
+				h.results.start(key)
+				go h.results.complete(key, acc, data, errs, cancelExec)
+			} else {
+				cancelExec()
+			}
+			if strings.EqualFold(wd.FinishReason, "timeout") {
+				partial, _ = sjson.SetBytes(partial, "choices.0.finish_reason", "timeout")
+			}
+			partial, _ = sjson.SetBytes(partial, "llm_mux", ext)
+			h.writeCompletion(c, cliCancel, partial)
+			return
+		}
+	}
+}
+
+func (h *OpenAIAPIHandler) writeCompletion(c *gin.Context, cancel format.APIHandlerCancelFunc, body []byte) {
+	if body == nil {
+		h.WriteErrorResponse(c, &interfaces.ErrorMessage{StatusCode: http.StatusInternalServerError, Error: errEmptyCompletion})
+		cancel(errEmptyCompletion)
+		return
+	}
+	_, _ = c.Writer.Write(body)
+	cancel(body)
+}
+
+// complete drains the rest of a generation whose partial result was already
+// returned and stores the completed response.
+func (r *completionResults) complete(key string, acc *completionAccumulator, data <-chan []byte, errs <-chan *interfaces.ErrorMessage, cancel context.CancelFunc) {
+	defer cancel()
+	defer r.pending.Delete(key)
+	for chunk := range data {
+		acc.add(chunk)
+	}
+	if errMsg := format.PendingStreamError(errs); errMsg != nil {
+		body, _ := json.Marshal(format.ErrorResponse{Error: format.ErrorDetail{
+			Message: format.StreamErrorMessage(errMsg),
+			Type:    ir.OpenAIErrorType(format.StreamErrorStatus(errMsg)),
+		}})
+		r.finish(key, body)
+		return
+	}
+	if body := acc.response(acc.finish, true); body != nil {
+		r.finish(key, body)
+	}
+}
+
+// GetChatCompletion handles GET /v1/chat/completions/:id, returning a
+// completion the generation watchdog finished in the background.
+func (h *OpenAIAPIHandler) GetChatCompletion(c *gin.Context) {
+	key := completionResultKey(c.GetString("apiKey"), c.Param("id"))
+	if body, ok := h.results.store.get(key); ok {
+		status := http.StatusOK
+		if gjson.GetBytes(body, "error").Exists() {
+			status = http.StatusBadGateway
+		}
+		c.Data(status, "application/json", body)
+		return
+	}
+	if _, ok := h.results.pending.Load(key); ok {
+		c.JSON(http.StatusAccepted, gin.H{"id": c.Param("id"), "object": "chat.completion", "status": "in_progress"})
+		return
+	}
+	c.JSON(http.StatusNotFound, format.ErrorResponse{Error: format.ErrorDetail{
+		Message: "No chat completion found with id '" + c.Param("id") + "'",
+		Type:    "invalid_request_error",
+		Code:    "not_found",
+	}})
+}
+
+// completionAccumulator rebuilds a chat completion from OpenAI stream chunks.
+type completionAccumulator struct {
+	model     string
+	id        string
+	text      strings.Builder
+	reasoning strings.Builder
+	toolCalls []ir.ToolCall
+	usage     *ir.Usage
+	finish    ir.FinishReason
+}
+
+func (a *completionAccumulator) add(chunk []byte) {
+	chunk = bytes.TrimSpace(chunk)
+	if len(chunk) == 0 {
+		return
+	}
+	docs := [][]byte{chunk}
+	if bytes.HasPrefix(chunk, []byte("data:")) || bytes.HasPrefix(chunk, []byte("event:")) {
+		docs = nil
+		for _, line := range bytes.Split(chunk, []byte("\n")) {
+			if payload, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data:")); ok {
+				docs = append(docs, bytes.TrimSpace(payload))
+			}
+		}
+	}
+	for _, doc := range docs {
+		if len(doc) == 0 || bytes.Equal(doc, []byte("[DONE]")) {
+			continue
+		}
+		if a.id == "" {
+			a.id = gjson.GetBytes(doc, "id").String()
+		}
+		events, err := to_ir.ParseOpenAIChunk(doc)
+		if err != nil {
+			continue
+		}
+		for _, ev := range events {
+			a.addEvent(ev)
+		}
+	}
+}
+
+func (a *completionAccumulator) addEvent(ev *ir.UnifiedEvent) {
+	switch ev.Type {
+	case ir.EventTypeToken:
+		a.text.WriteString(ev.Content)
+	case ir.EventTypeReasoning:
+		a.reasoning.WriteString(ev.Reasoning)
+	case ir.EventTypeToolCall:
+		for len(a.toolCalls) <= ev.ToolCallIndex {
+			a.toolCalls = append(a.toolCalls, ir.ToolCall{})
+		}
+		tc := &a.toolCalls[ev.ToolCallIndex]
+		if ev.ToolCall.ID != "" {
+			tc.ID = ev.ToolCall.ID
+		}
+		if ev.ToolCall.Name != "" {
+			tc.Name = ev.ToolCall.Name
+		}
+		tc.Args += ev.ToolCall.Args
+	case ir.EventTypeFinish:
+		if ev.FinishReason != "" {
+			a.finish = ev.FinishReason
+		}
+	}
+	if ev.Usage != nil {
+		a.usage = ev.Usage
+	}
+}
+
+// response builds the chat completion accumulated so far. Tool calls are only
+// included when complete, since partial arguments are not valid JSON.
+func (a *completionAccumulator) response(finish ir.FinishReason, complete bool) []byte {
+	if finish == "" {
+		finish = ir.FinishReasonStop
+	}
+	msg := ir.Message{Role: ir.RoleAssistant}
+	if a.reasoning.Len() > 0 {
+		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeReasoning, Reasoning: a.reasoning.String()})
+	}
+	if a.text.Len() > 0 || len(msg.Content) == 0 {
+		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeText, Text: a.text.String()})
+	}
+	if complete {
+		msg.ToolCalls = a.toolCalls
+	}
+	id := a.id
+	if id == "" {
+		id = "chatcmpl-" + a.model
+	}
+	body, err := from_ir.ToOpenAIChatCompletionCandidates([]ir.CandidateResult{{Messages: []ir.Message{msg}, FinishReason: finish}}, a.usage, a.model, id, nil)
+	if err != nil {
+		return nil
+	}
+	return body
+}
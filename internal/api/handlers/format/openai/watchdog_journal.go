@@ -0,0 +1,177 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// journalCompactAfter is how many records are appended before the journal is
+// rewritten with only its live entries.
+const journalCompactAfter = 4 * responseStoreMaxEntries
+
+const (
+	journalPending = "pending"
+	journalDone    = "done"
+)
+
+// completionJournal records the generations the watchdog continues in the
+// background, so their results survive a restart and the ones a restart cut
+// short are reported as failed instead of unknown.
+type completionJournal struct {
+	mu       sync.Mutex
+	path     string
+	f        *os.File
+	appended int
+}
+
+type journalRecord struct {
+	Key   string          `json:"key"`
+	State string          `json:"state"`
+	Body  json.RawMessage `json:"body,omitempty"`
+	Time  time.Time       `json:"time"`
+}
+
+// expires returns when r stops being served: done results are kept for
+// responseStoreTTL, pending generations for as long as they may run on top.
+func (r journalRecord) expires() time.Time {
+	if r.State == journalPending {
+		return r.Time.Add(watchdogContinueLimit + responseStoreTTL)
+	}
+	return r.Time.Add(responseStoreTTL)
+}
+
+// interruptedCompletionBody is the result of a generation the process exited
+// before finishing.
+var interruptedCompletionBody = func() []byte {
+	body, _ := json.Marshal(format.ErrorResponse{Error: format.ErrorDetail{
+		Message: "The generation was interrupted by a server restart before it finished",
+		Type:    "server_error",
+	}})
+	return body
+}()
+
+// recoverResults returns the results in the journal at path, marking the
+// generations still pending as interrupted with a warning each, and rewrites
+// the journal with them.
+func (j *completionJournal) recoverResults(now time.Time) (map[string][]byte, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	records, err := j.read(now)
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	results := make(map[string][]byte, len(records))
+	for i, r := range records {
+		if r.State == journalPending {
+			log.Warnf("watchdog journal: generation %s was interrupted by a restart, marking it failed", r.Key)
+			records[i] = journalRecord{Key: r.Key, State: journalDone, Body: interruptedCompletionBody, Time: now}
+		}
+		results[r.Key] = records[i].Body
+	}
+	return results, j.rewrite(records)
+}
+
+// add appends r to the journal. Failures are logged, as the result is still
+// served from memory.
+func (j *completionJournal) add(r journalRecord) {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		if err = os.MkdirAll(filepath.Dir(j.path), 0o755); err == nil {
+			j.f, err = os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		}
+		if err != nil {
+			log.Warnf("watchdog journal: %v", err)
+			return
+		}
+	}
+	if _, err = j.f.Write(append(line, '\n')); err != nil {
+		log.Warnf("watchdog journal: %v", err)
+		return
+	}
+	if j.appended++; j.appended >= journalCompactAfter {
+		if records, errRead := j.read(time.Now()); errRead != nil {
+			log.Warnf("watchdog journal: %v", errRead)
+		} else if errWrite := j.rewrite(records); errWrite != nil {
+			log.Warnf("watchdog journal: %v", errWrite)
+		}
+	}
+}
+
+// read returns the latest unexpired record of each key, oldest first.
+// Caller must hold j.mu.
+func (j *completionJournal) read(now time.Time) ([]journalRecord, error) {
+	f, err := os.Open(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	latest := make(map[string]journalRecord)
+	rd := bufio.NewReader(f)
+	for {
+		line, errRead := rd.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var r journalRecord
+			// A torn last line from a crash is skipped.
+			if json.Unmarshal(line, &r) == nil && r.Key != "" {
+				latest[r.Key] = r
+			}
+		}
+		if errRead == io.EOF {
+			break
+		}
+		if errRead != nil {
+			return nil, errRead
+		}
+	}
+	records := make([]journalRecord, 0, len(latest))
+	for _, r := range latest {
+		if now.Before(r.expires()) {
+			records = append(records, r)
+		}
+	}
+	slices.SortFunc(records, func(a, b journalRecord) int { return a.Time.Compare(b.Time) })
+	return records, nil
+}
+
+// rewrite replaces the journal with records. Caller must hold j.mu.
+func (j *completionJournal) rewrite(records []journalRecord) error {
+	if j.f != nil {
+		_ = j.f.Close()
+		j.f = nil
+	}
+	j.appended = 0
+	var buf bytes.Buffer
+	for _, r := range records {
+		line, err := json.Marshal(r)
+		if err != nil {
+			continue
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
@@ -0,0 +1,71 @@
+package openai
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestCompletionAccumulator(t *testing.T) {
+	acc := &completionAccumulator{model: "gpt-4o"}
+	for _, chunk := range []string{
+		`data: {"id":"chatcmpl-1","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}`,
+		`{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"lo"}}]}`,
+		"data: {\"id\":\"chatcmpl-1\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"id\":\"call_1\",\"function\":{\"name\":\"f\",\"arguments\":\"{\\\"a\\\"\"}}]}}]}\n\n",
+	} {
+		acc.add([]byte(chunk))
+	}
+
+	partial := acc.response(ir.FinishReasonMaxTokens, false)
+	if got := gjson.GetBytes(partial, "choices.0.message.content").String(); got != "Hello" {
+		t.Errorf("partial content = %q", got)
+	}
+	if gjson.GetBytes(partial, "choices.0.message.tool_calls").Exists() {
+		t.Error("partial response includes an incomplete tool call")
+	}
+	if got := gjson.GetBytes(partial, "choices.0.finish_reason").String(); got != "length" {
+		t.Errorf("partial finish_reason = %q", got)
+	}
+	if got := gjson.GetBytes(partial, "id").String(); got != "chatcmpl-1" {
+		t.Errorf("id = %q", got)
+	}
+
+	acc.add([]byte(`data: {"id":"chatcmpl-1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":":1}"}}]},"finish_reason":"tool_calls"}]}`))
+	acc.add([]byte(`data: {"id":"chatcmpl-1","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":5,"total_tokens":8}}`))
+	full := acc.response(acc.finish, true)
+	if got := gjson.GetBytes(full, "choices.0.message.tool_calls.0.function.arguments").String(); got != `{"a":1}` {
+		t.Errorf("tool call arguments = %q", got)
+	}
+	if got := gjson.GetBytes(full, "choices.0.finish_reason").String(); got != "tool_calls" {
+		t.Errorf("finish_reason = %q", got)
+	}
+	if got := gjson.GetBytes(full, "usage.completion_tokens").Int(); got != 5 {
+		t.Errorf("completion_tokens = %d", got)
+	}
+}
+
+func TestCompletionResultsJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	r := newCompletionResults(path)
+	key := completionResultKey("sk-client", "chatcmpl-done")
+	r.start(key)
+	r.finish(key, []byte(`{"id":"chatcmpl-done"}`))
+	r.start(completionResultKey("sk-client", "chatcmpl-running"))
+
+	// Each restart recovers the same results.
+	for range 2 {
+		r = newCompletionResults(path)
+		if body, ok := r.store.get(key); !ok || gjson.GetBytes(body, "id").String() != "chatcmpl-done" {
+			t.Fatalf("finished result = %s, %v", body, ok)
+		}
+		body, ok := r.store.get(completionResultKey("sk-client", "chatcmpl-running"))
+		if !ok || gjson.GetBytes(body, "error.type").String() != "server_error" {
+			t.Fatalf("interrupted result = %s, %v", body, ok)
+		}
+		if _, ok = r.store.get(completionResultKey("sk-other", "chatcmpl-done")); ok {
+			t.Fatal("result served to another API key")
+		}
+	}
+}
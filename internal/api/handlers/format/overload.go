@@ -0,0 +1,29 @@
+package format
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/runtime/overload"
+)
+
+// shedOverload rejects the request with 503 and Retry-After when the server
+// is overloaded and its priority is being shed.
+func shedOverload(ctx context.Context) *interfaces.ErrorMessage {
+	p := provider.PriorityFrom(ctx)
+	ok, retryAfter := overload.Admit(p)
+	if ok {
+		return nil
+	}
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	return &interfaces.ErrorMessage{
+		StatusCode: http.StatusServiceUnavailable,
+		Error:      errors.New("server overloaded; " + p.String() + "-priority requests are temporarily rejected"),
+		Addon:      http.Header{"Retry-After": []string{strconv.Itoa(seconds)}},
+	}
+}
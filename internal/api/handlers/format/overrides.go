@@ -0,0 +1,111 @@
+package format
+
+import (
+	"context"
+
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// OverridesField is the top-level body section carrying per-request overrides.
+// It lets SDKs that only support body extensions (OpenAI extra_body, Anthropic
+// extra_body) do what would otherwise need custom headers.
+const OverridesField = "llm-mux"
+
+// requestOverrides holds the parsed OverridesField section.
+type requestOverrides struct {
+	Provider        string
+	Priority        string
+	ThinkingBudget  *int
+	IncludeThoughts *bool
+}
+
+// extractOverrides parses and removes the OverridesField section from rawJSON.
+// Safety settings are moved to where handlerType's parser already reads them;
+// the other fields are returned for routing and request metadata.
+func extractOverrides(handlerType string, rawJSON []byte) (requestOverrides, []byte) {
+	var o requestOverrides
+	section := gjson.GetBytes(rawJSON, OverridesField)
+	if !section.Exists() {
+		return o, rawJSON
+	}
+	if stripped, err := sjson.DeleteBytes(rawJSON, OverridesField); err == nil {
+		rawJSON = stripped
+	}
+	if !section.IsObject() {
+		return o, rawJSON
+	}
+
+	o.Provider = section.Get("provider").String()
+	o.Priority = section.Get("priority").String()
+	if v := section.Get("thinking_budget"); v.Type == gjson.Number {
+		b := int(v.Int())
+		o.ThinkingBudget = &b
+	}
+	if v := section.Get("include_thoughts"); v.IsBool() {
+		include := v.Bool()
+		o.IncludeThoughts = &include
+	}
+	if v := section.Get("safety"); v.IsArray() || v.IsObject() {
+		path := safetySettingsPath(handlerType)
+		if !gjson.GetBytes(rawJSON, path).Exists() {
+			if updated, err := sjson.SetRawBytes(rawJSON, path, []byte(v.Raw)); err == nil {
+				rawJSON = updated
+			}
+		}
+	}
+	return o, rawJSON
+}
+
+// safetySettingsPath returns where handlerType's request parser reads safety
+// settings from. Explicit settings at that path take precedence.
+func safetySettingsPath(handlerType string) string {
+	switch handlerType {
+	case constant.Gemini:
+		return "safetySettings"
+	case constant.GeminiCLI:
+		return "request.safetySettings"
+	default:
+		return "safety_settings"
+	}
+}
+
+// apply restricts providers to the requested one and adds the thinking
+// overrides to metadata. Fallback models only get applyMetadata, as the
+// provider override names the primary model's provider.
+func (o requestOverrides) apply(providers []string, metadata map[string]any) ([]string, map[string]any) {
+	if o.Provider != "" {
+		providers = []string{o.Provider}
+	}
+	return providers, o.applyMetadata(metadata)
+}
+
+// applyMetadata returns a copy of metadata with the thinking overrides set.
+func (o requestOverrides) applyMetadata(metadata map[string]any) map[string]any {
+	if o.ThinkingBudget == nil && o.IncludeThoughts == nil {
+		return metadata
+	}
+	metadata = cloneMetadata(metadata)
+	if metadata == nil {
+		metadata = make(map[string]any, 4)
+	}
+	if o.ThinkingBudget != nil {
+		metadata["thinking_budget"] = *o.ThinkingBudget
+		metadata[util.GeminiThinkingBudgetMetadataKey] = *o.ThinkingBudget
+	}
+	if o.IncludeThoughts != nil {
+		metadata["include_thoughts"] = *o.IncludeThoughts
+		metadata[util.GeminiIncludeThoughtsMetadataKey] = *o.IncludeThoughts
+	}
+	return metadata
+}
+
+// withOverrides extracts the OverridesField section and records the request
+// priority in ctx.
+func (h *BaseAPIHandler) withOverrides(ctx context.Context, handlerType string, rawJSON []byte) (context.Context, []byte, requestOverrides) {
+	o, rawJSON := extractOverrides(handlerType, rawJSON)
+	ctx, rawJSON = h.withPriority(ctx, rawJSON, o.Priority)
+	return ctx, rawJSON, o
+}
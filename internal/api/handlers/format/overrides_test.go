@@ -0,0 +1,40 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/tidwall/gjson"
+)
+
+func TestExtractOverrides(t *testing.T) {
+	raw := `{"model":"m","llm-mux":{"provider":"claude","priority":"high","thinking_budget":2048,"include_thoughts":true,"safety":[{"category":"HARM_CATEGORY_HARASSMENT","threshold":"BLOCK_NONE"}]}}`
+	o, body := extractOverrides(constant.OpenAI, []byte(raw))
+	if gjson.GetBytes(body, "llm-mux").Exists() {
+		t.Fatalf("section not stripped: %s", body)
+	}
+	if got := gjson.GetBytes(body, "safety_settings.0.threshold").String(); got != "BLOCK_NONE" {
+		t.Errorf("safety_settings = %s", body)
+	}
+	if o.Provider != "claude" || o.Priority != "high" || o.ThinkingBudget == nil || *o.ThinkingBudget != 2048 || o.IncludeThoughts == nil || !*o.IncludeThoughts {
+		t.Fatalf("overrides = %+v", o)
+	}
+
+	providers, metadata := o.apply([]string{"gemini", "claude"}, map[string]any{"k": "v"})
+	if len(providers) != 1 || providers[0] != "claude" {
+		t.Errorf("providers = %v", providers)
+	}
+	if metadata["thinking_budget"] != 2048 || metadata["include_thoughts"] != true || metadata["k"] != "v" {
+		t.Errorf("metadata = %v", metadata)
+	}
+
+	_, body = extractOverrides(constant.Gemini, []byte(`{"safetySettings":[],"llm-mux":{"safety":[{"category":"x","threshold":"y"}]}}`))
+	if n := len(gjson.GetBytes(body, "safetySettings").Array()); n != 0 {
+		t.Errorf("explicit safetySettings overwritten: %s", body)
+	}
+
+	o, body = extractOverrides(constant.OpenAI, []byte(`{"model":"m"}`))
+	if string(body) != `{"model":"m"}` || o.Provider != "" || o.applyMetadata(nil) != nil {
+		t.Errorf("no section: overrides = %+v, body = %s", o, body)
+	}
+}
@@ -0,0 +1,55 @@
+package format
+
+import (
+	"context"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"golang.org/x/time/rate"
+)
+
+// bytesPerToken approximates the token count of a streamed chunk from its size.
+// SSE framing and JSON envelopes inflate chunks, so this deliberately errs high.
+const bytesPerToken = 16
+
+// streamPacer delays streamed chunks so that output is delivered to the client
+// at no more than a fixed tokens-per-second rate. Chunks are never merged or
+// held in a queue; the sender simply waits before forwarding each one.
+type streamPacer struct {
+	limiter *rate.Limiter
+	burst   int
+}
+
+// newStreamPacer returns nil when pacing is disabled for the request's client.
+func (h *BaseAPIHandler) newStreamPacer(ctx context.Context) *streamPacer {
+	if h == nil || h.Cfg == nil {
+		return nil
+	}
+	pacing := h.Cfg.StreamPacing
+	if pacing.TokensPerSecond <= 0 && len(pacing.Clients) == 0 {
+		return nil
+	}
+	tps := pacing.RateFor(provider.ClientAPIKey(ctx))
+	if tps <= 0 {
+		return nil
+	}
+	burst := pacing.Burst
+	if burst <= 0 {
+		burst = tps
+	}
+	return &streamPacer{limiter: rate.NewLimiter(rate.Limit(tps), burst), burst: burst}
+}
+
+// Wait blocks until chunk may be delivered or ctx is done.
+func (p *streamPacer) Wait(ctx context.Context, chunk []byte) error {
+	if p == nil {
+		return nil
+	}
+	tokens := len(chunk) / bytesPerToken
+	if tokens < 1 {
+		tokens = 1
+	}
+	if tokens > p.burst {
+		tokens = p.burst
+	}
+	return p.limiter.WaitN(ctx, tokens)
+}
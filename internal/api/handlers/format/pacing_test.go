@@ -0,0 +1,70 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"golang.org/x/time/rate"
+)
+
+func TestNewStreamPacerSelectsClientRate(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		pacing    config.StreamPacingConfig
+		apiKey    string
+		wantRate  rate.Limit // 0: no pacer
+		wantBurst int
+	}{
+		{"disabled", config.StreamPacingConfig{}, "sk-a", 0, 0},
+		{"default rate", config.StreamPacingConfig{TokensPerSecond: 80}, "sk-a", 80, 80},
+		{"explicit burst", config.StreamPacingConfig{TokensPerSecond: 80, Burst: 20}, "sk-a", 80, 20},
+		{"client override", config.StreamPacingConfig{TokensPerSecond: 80, Clients: map[string]int{"sk-a": 10}}, "sk-a", 10, 10},
+		{"other client", config.StreamPacingConfig{TokensPerSecond: 80, Clients: map[string]int{"sk-a": 10}}, "sk-b", 80, 80},
+		{"client exempt", config.StreamPacingConfig{TokensPerSecond: 80, Clients: map[string]int{"sk-a": 0}}, "sk-a", 0, 0},
+		{"only clients", config.StreamPacingConfig{Clients: map[string]int{"sk-a": 10}}, "sk-b", 0, 0},
+	} {
+		h := &BaseAPIHandler{Cfg: &config.SDKConfig{StreamPacing: tt.pacing}}
+		p := h.newStreamPacer(provider.WithClientAPIKey(context.Background(), tt.apiKey))
+		if tt.wantRate == 0 {
+			if p != nil {
+				t.Errorf("%s: pacer at %v tokens/s, want none", tt.name, p.limiter.Limit())
+			}
+			continue
+		}
+		if p == nil || p.limiter.Limit() != tt.wantRate || p.burst != tt.wantBurst {
+			t.Errorf("%s: pacer = %+v, want %v tokens/s with burst %d", tt.name, p, tt.wantRate, tt.wantBurst)
+		}
+	}
+}
+
+func TestStreamPacerThrottles(t *testing.T) {
+	h := &BaseAPIHandler{Cfg: &config.SDKConfig{StreamPacing: config.StreamPacingConfig{TokensPerSecond: 100, Burst: 10}}}
+	p := h.newStreamPacer(provider.WithClientAPIKey(context.Background(), "sk-a"))
+	chunk := bytes.Repeat([]byte("x"), 10*bytesPerToken)
+
+	start := time.Now()
+	for range 3 {
+		if err := p.Wait(context.Background(), chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The burst covers the first chunk; the next two wait 100ms each.
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("3 chunks of 10 tokens at 100 tokens/s took %v", elapsed)
+	}
+
+	// Chunks larger than the burst are capped instead of failing.
+	if err := p.Wait(context.Background(), bytes.Repeat(chunk, 10)); err != nil {
+		t.Errorf("oversized chunk: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.Wait(ctx, chunk); err == nil {
+		t.Error("Wait ignored a cancelled context")
+	}
+}
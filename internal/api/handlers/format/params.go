@@ -0,0 +1,126 @@
+package format
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+// IgnoredParamsHeader lists the request fields that were dropped because
+// the client's format has no translation for them, comma separated.
+const IgnoredParamsHeader = "X-LLMMUX-Ignored-Params"
+
+// openAISamplingParams are the sampling fields ir.ApplyCommonParams,
+// ApplyOpenAIExtendedParams and ApplyLocalSamplingParams read.
+var openAISamplingParams = []string{
+	"temperature", "top_p", "top_k", "max_tokens", "max_completion_tokens", "max_output_tokens",
+	"stop", "frequency_penalty", "presence_penalty", "seed", "logprobs", "top_logprobs", "n",
+	"min_p", "repetition_penalty", "repeat_penalty",
+}
+
+// openAIParams are the chat completion and Responses API fields
+// to_ir.ParseOpenAIRequest translates or the handlers use.
+var openAIParams = append([]string{
+	"model", "stream", "stream_options", "messages", "tools", "tool_choice", "parallel_tool_calls",
+	"response_format", "reasoning", "reasoning_effort", "extra_body", "safety_settings", "metadata",
+	"modalities", "image_config", "audio", "prediction", "logit_bias", "user", "service_tier", "store",
+	// Responses API
+	"input", "instructions", "previous_response_id", "conversation", "prompt", "prompt_cache_key",
+}, openAISamplingParams...)
+
+// geminiParams are the fields to_ir.ParseGeminiRequest translates. Entries
+// below an object field make its keys checked too.
+var geminiParams = []string{
+	"model", "contents", "systemInstruction", "tools", "toolConfig", "safetySettings",
+	"cachedContent", "labels", "service_tier", "prediction", "generationConfig",
+	"generationConfig.maxOutputTokens", "generationConfig.temperature", "generationConfig.topP",
+	"generationConfig.topK", "generationConfig.stopSequences", "generationConfig.frequencyPenalty",
+	"generationConfig.presencePenalty", "generationConfig.seed", "generationConfig.thinkingConfig",
+	"generationConfig.responseModalities", "generationConfig.responseSchema",
+	"generationConfig.responseJsonSchema",
+}
+
+// knownParams lists, per handler type, the request fields llm-mux
+// translates. Formats without an entry are not checked.
+var knownParams = map[string][]string{
+	constant.OpenAI:         openAIParams,
+	constant.OpenaiResponse: openAIParams,
+	constant.Claude: {
+		"model", "stream", "messages", "system", "max_tokens", "temperature", "top_p", "top_k",
+		"stop_sequences", "tools", "tool_choice", "thinking", "metadata", "mcp_servers", "safety_settings",
+		"prediction",
+	},
+	constant.Gemini:    geminiParams,
+	constant.GeminiCLI: append([]string{"model", "project", "request"}, prefixParams("request.", geminiParams)...),
+}
+
+func prefixParams(prefix string, params []string) []string {
+	out := make([]string, len(params))
+	for i, p := range params {
+		out[i] = prefix + p
+	}
+	return out
+}
+
+// unknownParams returns the fields of rawJSON that are missing from
+// handlerType's known-fields table, as dotted paths in body order.
+func unknownParams(handlerType string, rawJSON []byte) []string {
+	known, ok := knownParams[handlerType]
+	if !ok {
+		return nil
+	}
+	var unknown []string
+	var walk func(prefix string, v gjson.Result)
+	walk = func(prefix string, v gjson.Result) {
+		v.ForEach(func(key, value gjson.Result) bool {
+			path := prefix + key.String()
+			if !slices.Contains(known, path) {
+				unknown = append(unknown, path)
+			} else if value.IsObject() && slices.ContainsFunc(known, func(p string) bool { return strings.HasPrefix(p, path+".") }) {
+				walk(path+".", value)
+			}
+			return true
+		})
+	}
+	walk("", gjson.ParseBytes(rawJSON))
+	return unknown
+}
+
+// checkUnknownParams applies unknown-params.mode to the fields of rawJSON
+// that handlerType's parser drops: it lists them in IgnoredParamsHeader or
+// rejects the request naming them.
+func (h *BaseAPIHandler) checkUnknownParams(ctx context.Context, handlerType string, rawJSON []byte) *interfaces.ErrorMessage {
+	if h.Cfg == nil || h.Cfg.UnknownParams.Mode == "" {
+		return nil
+	}
+	mode := h.Cfg.UnknownParams.ModeFor(provider.ClientAPIKey(ctx))
+	if mode != config.UnknownParamsHeader && mode != config.UnknownParamsReject {
+		return nil
+	}
+	unknown := unknownParams(handlerType, rawJSON)
+	if len(unknown) == 0 {
+		return nil
+	}
+	if mode == config.UnknownParamsReject {
+		return &interfaces.ErrorMessage{
+			StatusCode: http.StatusBadRequest,
+			Error: &provider.Error{
+				Code:       "unsupported_parameter",
+				Message:    "unsupported request fields: " + strings.Join(unknown, ", "),
+				HTTPStatus: http.StatusBadRequest,
+			},
+		}
+	}
+	if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil {
+		c.Header(IgnoredParamsHeader, strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/constant"
+)
+
+func TestUnknownParams(t *testing.T) {
+	tests := []struct {
+		handlerType string
+		body        string
+		want        string
+	}{
+		{constant.OpenAI, `{"model":"m","messages":[],"temperature":1,"logit_bias":{"1":2},"functions":[],"truncation":"auto"}`, "functions,truncation"},
+		{constant.OpenaiResponse, `{"model":"m","input":"hi","max_output_tokens":5,"text":{"verbosity":"low"}}`, "text"},
+		{constant.Claude, `{"model":"m","messages":[],"max_tokens":5,"container":"c"}`, "container"},
+		{constant.Gemini, `{"contents":[],"generationConfig":{"temperature":1,"candidateCount":2},"system_instruction":{}}`, "generationConfig.candidateCount,system_instruction"},
+		{constant.GeminiCLI, `{"model":"m","project":"p","request":{"contents":[],"generationConfig":{"topK":3,"mediaResolution":"low"}}}`, "request.generationConfig.mediaResolution"},
+		{constant.Ollama, `{"model":"m","anything":1}`, ""},
+	}
+	for _, tt := range tests {
+		got := strings.Join(unknownParams(tt.handlerType, []byte(tt.body)), ",")
+		if got != tt.want {
+			t.Errorf("%s: unknownParams = %q, want %q", tt.handlerType, got, tt.want)
+		}
+	}
+}
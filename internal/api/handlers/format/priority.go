@@ -0,0 +1,54 @@
+package format
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// PriorityHeader sets the priority of a request: "low", "normal" or "high".
+const PriorityHeader = "X-LLMMUX-Priority"
+
+// withPriority records the request priority in ctx. It is taken from
+// PriorityHeader, metadata.priority or bodyPriority (from OverridesField),
+// defaults to the configured level and is capped at the client's maximum.
+// metadata.priority is removed from the body as upstreams reject unknown
+// metadata.
+func (h *BaseAPIHandler) withPriority(ctx context.Context, rawJSON []byte, bodyPriority string) (context.Context, []byte) {
+	var requested string
+	if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil {
+		requested = c.GetHeader(PriorityHeader)
+	}
+	if v := gjson.GetBytes(rawJSON, "metadata.priority"); v.Exists() {
+		if requested == "" {
+			requested = v.String()
+		}
+		if stripped, err := sjson.DeleteBytes(rawJSON, "metadata.priority"); err == nil {
+			rawJSON = stripped
+		}
+	}
+	if requested == "" {
+		requested = bodyPriority
+	}
+	return provider.WithPriority(ctx, h.resolvePriority(provider.ClientAPIKey(ctx), requested)), rawJSON
+}
+
+func (h *BaseAPIHandler) resolvePriority(apiKey, requested string) provider.Priority {
+	var defaultLevel, maxLevel string
+	if h.Routing != nil {
+		defaultLevel = h.Routing.RequestPriority.Default
+		maxLevel = h.Routing.RequestPriority.MaxFor(apiKey)
+	}
+	p, ok := provider.ParsePriority(requested)
+	if !ok {
+		p, _ = provider.ParsePriority(defaultLevel)
+	}
+	// An unset or invalid maximum allows normal.
+	if ceiling, _ := provider.ParsePriority(maxLevel); p > ceiling {
+		p = ceiling
+	}
+	return p
+}
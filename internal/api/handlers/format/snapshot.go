@@ -0,0 +1,191 @@
+package format
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+// ModelSnapshotHeader reports the upstream model that served a request
+// when snapshot pinning is enabled.
+const ModelSnapshotHeader = "X-LLMMUX-Model-Snapshot"
+
+const (
+	defaultSnapshotPinTTL        = time.Hour
+	defaultSnapshotPinMaxEntries = 10000
+	snapshotPinSweepInterval     = time.Minute
+)
+
+// snapshotPinStore keeps the snapshots pinned per conversation fingerprint.
+// The zero value is ready to use.
+type snapshotPinStore struct {
+	mu        sync.Mutex
+	entries   map[string]*snapshotPinEntry
+	lastSweep time.Time
+}
+
+type snapshotPinEntry struct {
+	// snapshots is replaced, never modified, once handed to a request.
+	snapshots map[string]provider.PinnedSnapshot
+	expires   time.Time
+}
+
+// withSnapshotPin attaches to ctx the snapshots pinned for the conversation
+// of rawJSON when snapshot pinning is enabled. It returns the conversation
+// fingerprint, empty when the request is not pinned.
+func (h *BaseAPIHandler) withSnapshotPin(ctx context.Context, rawJSON []byte) (context.Context, string) {
+	if h.Routing == nil || !h.Routing.SnapshotPinning.Enabled {
+		return ctx, ""
+	}
+	fingerprint := snapshotConversationKey(provider.ClientAPIKey(ctx), rawJSON)
+	if fingerprint == "" {
+		return ctx, ""
+	}
+	pin := provider.NewSnapshotPin(h.snapshotPins.get(fingerprint))
+	return provider.WithSnapshotPin(ctx, pin), fingerprint
+}
+
+// reportSnapshot pins the snapshot that served the request for later turns
+// of its conversation, unless one is pinned already, and reports it in
+// ModelSnapshotHeader.
+func (h *BaseAPIHandler) reportSnapshot(ctx context.Context, fingerprint string) {
+	if fingerprint == "" {
+		return
+	}
+	model, resolved, ok := provider.SnapshotPinFrom(ctx).Resolved()
+	if !ok {
+		return
+	}
+	cfg := h.Routing.SnapshotPinning
+	h.snapshotPins.pin(fingerprint, model, resolved, snapshotPinTTL(cfg.TTL), cfg.MaxEntries)
+	if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil {
+		c.Header(ModelSnapshotHeader, resolved.Snapshot)
+	}
+}
+
+// get returns the snapshots pinned for fingerprint, or nil.
+func (s *snapshotPinStore) get(fingerprint string) map[string]provider.PinnedSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[fingerprint]
+	if !ok || !time.Now().Before(e.expires) {
+		return nil
+	}
+	return e.snapshots
+}
+
+// pin records snapshot for model in the conversation fingerprint unless it
+// has one, and extends the conversation's pins by ttl.
+func (s *snapshotPinStore) pin(fingerprint, model string, snapshot provider.PinnedSnapshot, ttl time.Duration, maxEntries int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= snapshotPinSweepInterval {
+		s.sweep(now)
+	}
+	e, ok := s.entries[fingerprint]
+	if !ok || !now.Before(e.expires) {
+		if maxEntries <= 0 {
+			maxEntries = defaultSnapshotPinMaxEntries
+		}
+		if len(s.entries) >= maxEntries {
+			s.sweep(now)
+			if len(s.entries) >= maxEntries {
+				return
+			}
+		}
+		if s.entries == nil {
+			s.entries = make(map[string]*snapshotPinEntry)
+		}
+		e = &snapshotPinEntry{}
+		s.entries[fingerprint] = e
+	}
+	if _, pinned := e.snapshots[model]; !pinned {
+		snapshots := maps.Clone(e.snapshots)
+		if snapshots == nil {
+			snapshots = make(map[string]provider.PinnedSnapshot, 1)
+		}
+		snapshots[model] = snapshot
+		e.snapshots = snapshots
+	}
+	e.expires = now.Add(ttl)
+}
+
+// sweep removes expired entries. Requires s.mu.
+func (s *snapshotPinStore) sweep(now time.Time) {
+	s.lastSweep = now
+	for k, e := range s.entries {
+		if !now.Before(e.expires) {
+			delete(s.entries, k)
+		}
+	}
+}
+
+func snapshotPinTTL(raw string) time.Duration {
+	if d, err := time.ParseDuration(strings.TrimSpace(raw)); err == nil && d > 0 {
+		return d
+	}
+	return defaultSnapshotPinTTL
+}
+
+// snapshotConversationKey identifies the conversation of a request body in
+// any of the supported formats by the client API key, the system prompt and
+// the first user message, which stay the same across turns. It returns ""
+// when the body has no user message, as for Responses API turns continuing
+// from previous_response_id.
+func snapshotConversationKey(apiKey string, rawJSON []byte) string {
+	body := gjson.ParseBytes(rawJSON)
+	if inner := body.Get("request"); inner.IsObject() {
+		body = inner // Gemini CLI envelope
+	}
+	h := sha256.New()
+	h.Write([]byte(apiKey))
+	for _, path := range []string{"system", "systemInstruction", "instructions"} {
+		h.Write([]byte{0})
+		h.Write([]byte(body.Get(path).Raw))
+	}
+
+	var turns gjson.Result
+	for _, path := range []string{"messages", "contents", "input"} {
+		if turns = body.Get(path); turns.Exists() {
+			break
+		}
+	}
+	if turns.Type == gjson.String {
+		h.Write([]byte{0})
+		h.Write([]byte(turns.Str))
+		return hex.EncodeToString(h.Sum(nil))[:16]
+	}
+	found := false
+	turns.ForEach(func(_, turn gjson.Result) bool {
+		role := turn.Get("role").String()
+		// Gemini contents may omit the user role; Responses items without
+		// a role are tool calls and their outputs.
+		if role == "" && turn.Get("parts").Exists() {
+			role = "user"
+		}
+		switch role {
+		case "system", "developer":
+		case "user":
+			found = true
+		default:
+			return true
+		}
+		h.Write([]byte{0})
+		h.Write([]byte(turn.Get("content").Raw))
+		h.Write([]byte(turn.Get("parts").Raw))
+		return !found
+	})
+	if !found {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
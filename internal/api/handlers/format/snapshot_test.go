@@ -0,0 +1,59 @@
+package format
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestSnapshotConversationKey(t *testing.T) {
+	first := `{"model":"m","messages":[{"role":"system","content":"be brief"},{"role":"user","content":"hi"}]}`
+	later := `{"model":"m","messages":[{"role":"system","content":"be brief"},{"role":"user","content":"hi"},` +
+		`{"role":"assistant","content":"hello"},{"role":"user","content":"more"}]}`
+	fp := snapshotConversationKey("key", []byte(first))
+	if fp == "" || fp != snapshotConversationKey("key", []byte(later)) {
+		t.Fatalf("turns of one conversation differ: %q", fp)
+	}
+	if fp == snapshotConversationKey("other", []byte(first)) {
+		t.Error("fingerprint does not depend on the API key")
+	}
+	if fp == snapshotConversationKey("key", []byte(`{"messages":[{"role":"system","content":"be brief"},{"role":"user","content":"bye"}]}`)) {
+		t.Error("fingerprint does not depend on the first user message")
+	}
+
+	gemini := `{"systemInstruction":{"parts":[{"text":"s"}]},"contents":[{"parts":[{"text":"hi"}]}]}`
+	if got := snapshotConversationKey("key", []byte(`{"model":"m","request":`+gemini+`}`)); got == "" || got != snapshotConversationKey("key", []byte(gemini)) {
+		t.Errorf("Gemini CLI envelope fingerprint %q differs from Gemini", got)
+	}
+	if got := snapshotConversationKey("key", []byte(`{"previous_response_id":"r","input":[{"type":"function_call_output","output":"x"}]}`)); got != "" {
+		t.Errorf("continued Responses turn fingerprint = %q, want none", got)
+	}
+}
+
+func TestSnapshotPinStoreKeepsFirstTurn(t *testing.T) {
+	var s snapshotPinStore
+	first := provider.PinnedSnapshot{Provider: "a", Snapshot: "m-2024-08-06"}
+	s.pin("conv", "m", first, time.Hour, 0)
+	pinned := s.get("conv")
+	s.pin("conv", "m", provider.PinnedSnapshot{Provider: "b", Snapshot: "m-2024-11-20"}, time.Hour, 0)
+	if got := s.get("conv")["m"]; got != first {
+		t.Errorf("pin replaced by later turn: %+v", got)
+	}
+	s.pin("conv", "other", provider.PinnedSnapshot{Provider: "b", Snapshot: "o-1"}, time.Hour, 0)
+	if _, ok := pinned["other"]; ok {
+		t.Error("snapshots handed to a request were modified")
+	}
+	if got := s.get("conv")["other"].Snapshot; got != "o-1" {
+		t.Errorf("second model pin = %q", got)
+	}
+
+	s.pin("full", "m", first, time.Hour, 1)
+	if s.get("full") != nil {
+		t.Error("pin stored beyond max entries")
+	}
+	s.pin("expired", "m", first, -time.Second, 0)
+	if s.get("expired") != nil {
+		t.Error("expired pin returned")
+	}
+}
@@ -0,0 +1,104 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+var (
+	usageKey        = []byte(`"usage"`)
+	finishReasonKey = []byte(`"finish_reason"`)
+)
+
+// StreamEnding rewrites the terminal chunks of an OpenAI chat completion
+// stream to the shape the client expects. A nil StreamEnding leaves chunks
+// unchanged and sends [DONE].
+type StreamEnding struct {
+	usage        string
+	omitDone     bool
+	contentDelta bool
+}
+
+// NewStreamEnding resolves the stream ending options of the client in c
+// against the stream_options of rawJSON.
+func (h *BaseAPIHandler) NewStreamEnding(c *gin.Context, rawJSON []byte) *StreamEnding {
+	var opts config.StreamEndingOptions
+	if h.Cfg != nil {
+		opts = h.Cfg.StreamEnding.For(c.GetString("apiKey"))
+	}
+	usage := strings.ToLower(strings.TrimSpace(opts.Usage))
+	if usage == "" || usage == config.StreamUsageAuto {
+		switch v := gjson.GetBytes(rawJSON, "stream_options.include_usage"); {
+		case !v.Exists():
+			usage = config.StreamUsageFinish
+		case v.Bool():
+			usage = config.StreamUsageSeparate
+		default:
+			usage = config.StreamUsageOmit
+		}
+	}
+	return &StreamEnding{
+		usage:        usage,
+		omitDone:     opts.OmitDone,
+		contentDelta: strings.EqualFold(strings.TrimSpace(opts.FinishDelta), "content"),
+	}
+}
+
+// Done reports whether the stream ends with "data: [DONE]".
+func (e *StreamEnding) Done() bool {
+	return e == nil || !e.omitDone
+}
+
+// Rewrite returns the chunks to send in place of chunk, an SSE data line or a
+// bare JSON chunk. Only chunks carrying usage or a finish_reason are touched.
+func (e *StreamEnding) Rewrite(chunk []byte) [][]byte {
+	if e == nil || (e.usage == config.StreamUsageFinish && !e.contentDelta) {
+		return [][]byte{chunk}
+	}
+	payload := bytes.TrimSpace(bytes.TrimPrefix(chunk, []byte("data:")))
+	if len(payload) == 0 || payload[0] != '{' ||
+		(!bytes.Contains(payload, usageKey) && !bytes.Contains(payload, finishReasonKey)) {
+		return [][]byte{chunk}
+	}
+
+	out := payload
+	hasChoices := len(gjson.GetBytes(payload, "choices").Array()) > 0
+	if reason := gjson.GetBytes(payload, "choices.0.finish_reason"); e.contentDelta && reason.Exists() && reason.Type != gjson.Null {
+		out, _ = sjson.SetRawBytes(out, "choices.0.delta", []byte(`{"role":"assistant","content":""}`))
+	}
+	var usageChunk []byte
+	if usage := gjson.GetBytes(payload, "usage"); usage.Exists() && usage.Type != gjson.Null {
+		switch e.usage {
+		case config.StreamUsageOmit:
+			if !hasChoices {
+				return nil
+			}
+			out, _ = sjson.DeleteBytes(out, "usage")
+		case config.StreamUsageSeparate:
+			if hasChoices {
+				usageChunk, _ = sjson.SetRawBytes(bytes.Clone(out), "choices", []byte("[]"))
+				out, _ = sjson.DeleteBytes(out, "usage")
+			}
+		}
+	}
+	if bytes.Equal(out, payload) {
+		return [][]byte{chunk}
+	}
+	chunks := [][]byte{sseData(out)}
+	if usageChunk != nil {
+		chunks = append(chunks, sseData(usageChunk))
+	}
+	return chunks
+}
+
+func sseData(payload []byte) []byte {
+	out := make([]byte, 0, len(payload)+8)
+	out = append(out, "data: "...)
+	out = append(out, payload...)
+	return append(out, "\n\n"...)
+}
@@ -0,0 +1,61 @@
+package format
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+const finishChunk = "data: {\"id\":\"c1\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}],\"usage\":{\"total_tokens\":3}}\n\n"
+
+func newTestStreamEnding(opts config.StreamEndingOptions, rawJSON string) *StreamEnding {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	h := &BaseAPIHandler{Cfg: &config.SDKConfig{StreamEnding: config.StreamEndingConfig{StreamEndingOptions: opts}}}
+	return h.NewStreamEnding(c, []byte(rawJSON))
+}
+
+func TestStreamEndingUsagePlacement(t *testing.T) {
+	legacy := newTestStreamEnding(config.StreamEndingOptions{}, `{}`)
+	if got := legacy.Rewrite([]byte(finishChunk)); len(got) != 1 || string(got[0]) != finishChunk {
+		t.Fatalf("legacy ending rewrote chunk: %q", got)
+	}
+
+	separate := newTestStreamEnding(config.StreamEndingOptions{}, `{"stream_options":{"include_usage":true}}`)
+	got := separate.Rewrite([]byte(finishChunk))
+	if len(got) != 2 {
+		t.Fatalf("separate = %q", got)
+	}
+	if first := gjson.ParseBytes(got[0][len("data: "):]); first.Get("usage").Exists() || first.Get("choices.0.finish_reason").String() != "stop" {
+		t.Errorf("finish chunk = %s", got[0])
+	}
+	if second := gjson.ParseBytes(got[1][len("data: "):]); second.Get("usage.total_tokens").Int() != 3 || len(second.Get("choices").Array()) != 0 {
+		t.Errorf("usage chunk = %s", got[1])
+	}
+
+	omit := newTestStreamEnding(config.StreamEndingOptions{}, `{"stream_options":{"include_usage":false}}`)
+	if got := omit.Rewrite([]byte(finishChunk)); len(got) != 1 || gjson.GetBytes(got[0][len("data: "):], "usage").Exists() {
+		t.Errorf("omit = %q", got)
+	}
+	if got := omit.Rewrite([]byte(`{"choices":[],"usage":{"total_tokens":3}}`)); len(got) != 0 {
+		t.Errorf("usage-only chunk kept: %q", got)
+	}
+}
+
+func TestStreamEndingFinishDeltaAndDone(t *testing.T) {
+	e := newTestStreamEnding(config.StreamEndingOptions{Usage: "finish", FinishDelta: "content", OmitDone: true}, `{}`)
+	if e.Done() {
+		t.Error("omit-done ignored")
+	}
+	got := e.Rewrite([]byte(finishChunk))
+	if len(got) != 1 {
+		t.Fatalf("rewrite = %q", got)
+	}
+	chunk := gjson.ParseBytes(got[0][len("data: "):])
+	if chunk.Get("choices.0.delta.role").String() != "assistant" || !chunk.Get("choices.0.delta.content").Exists() || !chunk.Get("usage").Exists() {
+		t.Errorf("finish chunk = %s", got[0])
+	}
+}
@@ -0,0 +1,74 @@
+package format
+
+import (
+	"net/http"
+
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// PendingStreamError returns an error already queued on errs. The stream
+// goroutine closes errs before data, so a stream that ended on an upstream
+// failure has its error waiting once data is closed.
+func PendingStreamError(errs <-chan *interfaces.ErrorMessage) *interfaces.ErrorMessage {
+	select {
+	case msg := <-errs:
+		return msg
+	default:
+		return nil
+	}
+}
+
+// StreamErrorStatus returns the HTTP status describing msg.
+func StreamErrorStatus(msg *interfaces.ErrorMessage) int {
+	if msg == nil {
+		return http.StatusInternalServerError
+	}
+	if msg.StatusCode > 0 {
+		return msg.StatusCode
+	}
+	return ir.ErrorStatus(msg.Error)
+}
+
+// StreamErrorMessage returns the client-facing message of msg.
+func StreamErrorMessage(msg *interfaces.ErrorMessage) string {
+	if msg == nil || msg.Error == nil {
+		return http.StatusText(StreamErrorStatus(msg))
+	}
+	return log.Redact(msg.Error.Error())
+}
+
+// OpenAIStreamError ends an OpenAI chat or completions stream that has already
+// started: an error chunk followed by [DONE].
+func OpenAIStreamError(msg *interfaces.ErrorMessage) []byte {
+	jb, _ := json.Marshal(ErrorResponse{Error: ErrorDetail{
+		Message: StreamErrorMessage(msg),
+		Type:    ir.OpenAIErrorType(StreamErrorStatus(msg)),
+	}})
+	out := make([]byte, 0, len(jb)+32)
+	out = append(out, "data: "...)
+	out = append(out, jb...)
+	return append(out, "\n\ndata: [DONE]\n\n"...)
+}
+
+// ResponsesStreamFailed ends a Responses API stream with response.failed.
+func ResponsesStreamFailed(responseID string, msg *interfaces.ErrorMessage) []byte {
+	jb, _ := json.Marshal(map[string]any{
+		"type": "response.failed",
+		"response": map[string]any{
+			"id":     responseID,
+			"object": "response",
+			"status": "failed",
+			"error": map[string]any{
+				"code":    ir.OpenAIErrorType(StreamErrorStatus(msg)),
+				"message": StreamErrorMessage(msg),
+			},
+		},
+	})
+	out := make([]byte, 0, len(jb)+48)
+	out = append(out, "event: response.failed\ndata: "...)
+	out = append(out, jb...)
+	return append(out, "\n\n"...)
+}
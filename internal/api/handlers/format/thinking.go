@@ -0,0 +1,117 @@
+package format
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/gjson"
+)
+
+// ThinkingBudgetHeader reports the thinking budget adaptive thinking chose
+// for a request, after clamping to the model's range.
+const ThinkingBudgetHeader = "X-LLMMUX-Thinking-Budget"
+
+// explicitThinkingPaths are the body fields through which clients of the
+// supported formats set a thinking budget, effort or level, or turn
+// thinking off. Requests setting any of them keep their own choice.
+var explicitThinkingPaths = []string{
+	"thinking",
+	"think",
+	"reasoning",
+	"reasoning_effort",
+	"generationConfig.thinkingConfig",
+	"request.generationConfig.thinkingConfig",
+}
+
+// textFields are the keys holding prompt text across the request formats.
+var textFields = map[string]bool{
+	"content":      true,
+	"text":         true,
+	"input":        true,
+	"instructions": true,
+	"system":       true,
+	"prompt":       true,
+}
+
+// withAdaptiveThinking sets the thinking budget override of requests that
+// do not choose a budget themselves when adaptive thinking is enabled and
+// model supports thinking. The budget also applies to fallback models.
+func (h *BaseAPIHandler) withAdaptiveThinking(ctx context.Context, model string, rawJSON []byte, metadata map[string]any, o requestOverrides) requestOverrides {
+	if h.Cfg == nil || !h.Cfg.AdaptiveThinking.Enabled || o.ThinkingBudget != nil {
+		return o
+	}
+	if _, ok := metadata[util.GeminiThinkingBudgetMetadataKey]; ok {
+		return o
+	}
+	info := registry.GetGlobalRegistry().GetModelInfo(model)
+	if info == nil || info.Thinking == nil || setsThinking(rawJSON) {
+		return o
+	}
+
+	chars, hasCode := promptText(gjson.ParseBytes(rawJSON))
+	budget := h.Cfg.AdaptiveThinking.Budget(chars/4, hasCode, hasTools(rawJSON))
+	budget = max(info.Thinking.Min, budget)
+	if info.Thinking.Max > 0 {
+		budget = min(info.Thinking.Max, budget)
+	}
+	o.ThinkingBudget = &budget
+
+	if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil {
+		c.Header(ThinkingBudgetHeader, strconv.Itoa(budget))
+	}
+	return o
+}
+
+func setsThinking(rawJSON []byte) bool {
+	for _, path := range explicitThinkingPaths {
+		if gjson.GetBytes(rawJSON, path).Exists() {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTools(rawJSON []byte) bool {
+	for _, path := range []string{"tools", "functions", "request.tools"} {
+		if len(gjson.GetBytes(rawJSON, path).Array()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// promptText returns the length of the prompt text in v and whether it
+// contains code. Only string values under textFields count, which skips
+// tool schemas, inline images and other non-text payloads.
+func promptText(v gjson.Result) (chars int, hasCode bool) {
+	v.ForEach(func(key, value gjson.Result) bool {
+		if value.Type == gjson.String {
+			if textFields[key.String()] {
+				chars += len(value.Str)
+				hasCode = hasCode || looksLikeCode(value.Str)
+			}
+			return true
+		}
+		n, code := promptText(value)
+		chars += n
+		hasCode = hasCode || code
+		return true
+	})
+	return chars, hasCode
+}
+
+// codeMarkers are substrings that are rare in prose but common in source code.
+var codeMarkers = []string{"```", "func ", "def ", "#include", "=> {", "};\n", "import ", "public class "}
+
+func looksLikeCode(s string) bool {
+	for _, m := range codeMarkers {
+		if strings.Contains(s, m) {
+			return true
+		}
+	}
+	return false
+}
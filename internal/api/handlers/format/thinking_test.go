@@ -0,0 +1,42 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestAdaptiveThinkingBudget(t *testing.T) {
+	raw := []byte(`{"model":"m","messages":[{"role":"system","content":"Be brief."},{"role":"user","content":[{"type":"text","text":"Fix this:\n` + "```go\\nfunc f() {}\\n```" + `"},{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA"}}]}],"tools":[{"type":"function","function":{"name":"run","description":"runs code"}}]}`)
+	chars, hasCode := promptText(gjson.ParseBytes(raw))
+	if chars != len("Be brief.")+len("Fix this:\n```go\nfunc f() {}\n```") || !hasCode {
+		t.Fatalf("promptText = %d, %v", chars, hasCode)
+	}
+	if !hasTools(raw) || setsThinking(raw) {
+		t.Fatalf("hasTools = %v, setsThinking = %v", hasTools(raw), setsThinking(raw))
+	}
+	if !setsThinking([]byte(`{"reasoning_effort":"low"}`)) || !setsThinking([]byte(`{"thinking":{"type":"disabled"}}`)) {
+		t.Fatal("explicit thinking settings not detected")
+	}
+
+	cfg := config.AdaptiveThinkingConfig{
+		Enabled: true,
+		Curve:   []config.ThinkingCurvePoint{{PromptTokens: 1000, Budget: 4000}, {PromptTokens: 0, Budget: 1000}},
+	}
+	for _, tc := range []struct {
+		tokens            int
+		hasCode, hasTools bool
+		want              int
+	}{
+		{0, false, false, 1000},
+		{500, false, false, 2500},
+		{5000, false, false, 4000},
+		{500, true, false, 3750},
+		{500, true, true, 4688},
+	} {
+		if got := cfg.Budget(tc.tokens, tc.hasCode, tc.hasTools); got != tc.want {
+			t.Errorf("Budget(%d, %v, %v) = %d, want %d", tc.tokens, tc.hasCode, tc.hasTools, got, tc.want)
+		}
+	}
+}
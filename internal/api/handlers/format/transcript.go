@@ -0,0 +1,334 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/usage"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// TranscriptHeader marks a request for transcript persistence when
+	// transcripts.header is enabled. A value other than "true" or "1" is
+	// stored as the transcript label.
+	TranscriptHeader = "X-LLMMUX-Transcript"
+
+	// TranscriptIDHeader returns the ID of the persisted transcript.
+	TranscriptIDHeader = "X-LLMMUX-Transcript-Id"
+
+	transcriptSaveTimeout = 10 * time.Second
+)
+
+// transcriptRecorder assembles the response of a marked request, in the
+// client's format, and stores it with the request once complete.
+type transcriptRecorder struct {
+	backend    usage.Backend
+	transcript usage.Transcript
+
+	text      strings.Builder
+	reasoning strings.Builder
+	toolCalls []transcriptToolCall
+	// toolIndex maps stream tool call indexes to toolCalls.
+	toolIndex    map[int64]int
+	finishReason string
+}
+
+type transcriptToolCall struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// transcriptResponse is the stored form of an assembled response.
+type transcriptResponse struct {
+	Text         string               `json:"text,omitempty"`
+	Reasoning    string               `json:"reasoning,omitempty"`
+	ToolCalls    []transcriptToolCall `json:"tool_calls,omitempty"`
+	FinishReason string               `json:"finish_reason,omitempty"`
+}
+
+// newTranscript returns a recorder when the request is selected for
+// transcript persistence by its API key or TranscriptHeader, or nil.
+func (h *BaseAPIHandler) newTranscript(ctx context.Context, handlerType, model string, rawJSON []byte, stream bool) *transcriptRecorder {
+	if h.Cfg == nil {
+		return nil
+	}
+	c, _ := ctx.Value(ctxKeyGin).(*gin.Context)
+	if c == nil {
+		return nil
+	}
+	cfg := h.Cfg.Transcripts
+	apiKey := c.GetString("apiKey")
+	header := strings.TrimSpace(c.GetHeader(TranscriptHeader))
+	selected := cfg.AppliesTo(apiKey)
+	if cfg.Header && header != "" && !strings.EqualFold(header, "false") && header != "0" {
+		selected = true
+	}
+	if !selected {
+		return nil
+	}
+	backend := usage.GetLoggerPlugin().GetBackend()
+	if backend == nil {
+		return nil
+	}
+	label := header
+	if strings.EqualFold(label, "true") || label == "1" {
+		label = ""
+	}
+
+	id := uuid.NewString()
+	c.Header(TranscriptIDHeader, id)
+	return &transcriptRecorder{
+		backend: backend,
+		transcript: usage.Transcript{
+			ID:          id,
+			RequestedAt: time.Now(),
+			APIKey:      apiKey,
+			Model:       model,
+			Format:      handlerType,
+			Label:       label,
+			Stream:      stream,
+			Request:     bytes.Clone(rawJSON),
+		},
+		toolIndex: make(map[int64]int),
+	}
+}
+
+// complete records a non-streaming response and saves the transcript.
+func (r *transcriptRecorder) complete(payload []byte) {
+	if r == nil {
+		return
+	}
+	if gjson.ValidBytes(payload) {
+		r.observeDocument(gjson.ParseBytes(payload))
+	}
+	r.save()
+}
+
+// observe records a stream chunk: SSE events or newline-delimited JSON.
+func (r *transcriptRecorder) observe(chunk []byte) {
+	if r == nil {
+		return
+	}
+	for _, line := range bytes.Split(chunk, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if bytes.HasPrefix(line, []byte("data:")) {
+			line = bytes.TrimSpace(line[len("data:"):])
+		}
+		if len(line) == 0 || line[0] != '{' || !gjson.ValidBytes(line) {
+			continue
+		}
+		r.observeDocument(gjson.ParseBytes(line))
+	}
+}
+
+// finish saves the transcript of a stream that ended normally.
+func (r *transcriptRecorder) finish() {
+	if r == nil {
+		return
+	}
+	r.save()
+}
+
+func (r *transcriptRecorder) observeDocument(doc gjson.Result) {
+	switch r.transcript.Format {
+	case constant.Claude:
+		r.observeClaude(doc)
+	case constant.Gemini, constant.GeminiCLI:
+		if resp := doc.Get("response"); resp.IsObject() {
+			doc = resp
+		}
+		r.observeGemini(doc)
+	case constant.OpenaiResponse, constant.Codex:
+		r.observeResponses(doc)
+	case constant.Ollama:
+		r.observeOllama(doc)
+	default:
+		r.observeOpenAI(doc)
+	}
+}
+
+func (r *transcriptRecorder) observeOpenAI(doc gjson.Result) {
+	choice := doc.Get("choices.0")
+	msg := choice.Get("message")
+	if !msg.Exists() {
+		msg = choice.Get("delta")
+	}
+	r.text.WriteString(msg.Get("content").String())
+	r.text.WriteString(choice.Get("text").String())
+	r.reasoning.WriteString(msg.Get("reasoning_content").String())
+	for i, tc := range msg.Get("tool_calls").Array() {
+		index := int64(i)
+		if v := tc.Get("index"); v.Exists() {
+			index = v.Int()
+		}
+		call := r.toolCall(index, tc.Get("id").String())
+		if name := tc.Get("function.name").String(); name != "" {
+			call.Name = name
+		}
+		call.Arguments += tc.Get("function.arguments").String()
+	}
+	r.setFinish(choice.Get("finish_reason").String())
+}
+
+func (r *transcriptRecorder) observeClaude(doc gjson.Result) {
+	switch doc.Get("type").String() {
+	case "message":
+		for i, block := range doc.Get("content").Array() {
+			r.observeClaudeBlock(int64(i), block)
+			if input := block.Get("input"); block.Get("type").String() == "tool_use" && input.Exists() {
+				r.toolCall(int64(i), "").Arguments = input.Raw
+			}
+		}
+		r.setFinish(doc.Get("stop_reason").String())
+	case "content_block_start":
+		r.observeClaudeBlock(doc.Get("index").Int(), doc.Get("content_block"))
+	case "content_block_delta":
+		delta := doc.Get("delta")
+		switch delta.Get("type").String() {
+		case "text_delta":
+			r.text.WriteString(delta.Get("text").String())
+		case "thinking_delta":
+			r.reasoning.WriteString(delta.Get("thinking").String())
+		case "input_json_delta":
+			r.toolCall(doc.Get("index").Int(), "").Arguments += delta.Get("partial_json").String()
+		}
+	case "message_delta":
+		r.setFinish(doc.Get("delta.stop_reason").String())
+	}
+}
+
+// observeClaudeBlock records a complete content block or the start of a
+// streamed one; streamed tool input arrives in deltas.
+func (r *transcriptRecorder) observeClaudeBlock(index int64, block gjson.Result) {
+	switch block.Get("type").String() {
+	case "text":
+		r.text.WriteString(block.Get("text").String())
+	case "thinking":
+		r.reasoning.WriteString(block.Get("thinking").String())
+	case "tool_use":
+		r.toolCall(index, block.Get("id").String()).Name = block.Get("name").String()
+	}
+}
+
+func (r *transcriptRecorder) observeGemini(doc gjson.Result) {
+	candidate := doc.Get("candidates.0")
+	for _, part := range candidate.Get("content.parts").Array() {
+		if fc := part.Get("functionCall"); fc.Exists() {
+			r.toolCalls = append(r.toolCalls, transcriptToolCall{
+				ID:        fc.Get("id").String(),
+				Name:      fc.Get("name").String(),
+				Arguments: fc.Get("args").Raw,
+			})
+			continue
+		}
+		if part.Get("thought").Bool() {
+			r.reasoning.WriteString(part.Get("text").String())
+		} else {
+			r.text.WriteString(part.Get("text").String())
+		}
+	}
+	r.setFinish(candidate.Get("finishReason").String())
+}
+
+// observeResponses records complete output items: those of a response body
+// and those of response.output_item.done events.
+func (r *transcriptRecorder) observeResponses(doc gjson.Result) {
+	switch doc.Get("type").String() {
+	case "response.output_item.done":
+		r.observeResponsesItem(doc.Get("item"))
+	case "response.completed", "response.done", "response.incomplete", "response.failed":
+		r.setFinish(doc.Get("response.status").String())
+	case "":
+		for _, item := range doc.Get("output").Array() {
+			r.observeResponsesItem(item)
+		}
+		r.setFinish(doc.Get("status").String())
+	}
+}
+
+func (r *transcriptRecorder) observeResponsesItem(item gjson.Result) {
+	switch item.Get("type").String() {
+	case "message":
+		for _, part := range item.Get("content").Array() {
+			r.text.WriteString(part.Get("text").String())
+		}
+	case "reasoning":
+		for _, part := range item.Get("summary").Array() {
+			r.reasoning.WriteString(part.Get("text").String())
+		}
+	case "function_call":
+		r.toolCalls = append(r.toolCalls, transcriptToolCall{
+			ID:        item.Get("call_id").String(),
+			Name:      item.Get("name").String(),
+			Arguments: item.Get("arguments").String(),
+		})
+	}
+}
+
+func (r *transcriptRecorder) observeOllama(doc gjson.Result) {
+	msg := doc.Get("message")
+	r.text.WriteString(msg.Get("content").String())
+	r.text.WriteString(doc.Get("response").String())
+	r.reasoning.WriteString(msg.Get("thinking").String())
+	for _, tc := range msg.Get("tool_calls").Array() {
+		r.toolCalls = append(r.toolCalls, transcriptToolCall{
+			ID:        tc.Get("id").String(),
+			Name:      tc.Get("function.name").String(),
+			Arguments: tc.Get("function.arguments").Raw,
+		})
+	}
+	r.setFinish(doc.Get("done_reason").String())
+}
+
+// toolCall returns the tool call at a stream index, adding it when the index
+// is new or a different call ID starts there.
+func (r *transcriptRecorder) toolCall(index int64, id string) *transcriptToolCall {
+	if i, ok := r.toolIndex[index]; ok && (id == "" || r.toolCalls[i].ID == id) {
+		return &r.toolCalls[i]
+	}
+	r.toolCalls = append(r.toolCalls, transcriptToolCall{ID: id})
+	r.toolIndex[index] = len(r.toolCalls) - 1
+	return &r.toolCalls[len(r.toolCalls)-1]
+}
+
+func (r *transcriptRecorder) setFinish(reason string) {
+	if reason != "" {
+		r.finishReason = reason
+	}
+}
+
+// response returns the assembled response as stored.
+func (r *transcriptRecorder) response() []byte {
+	out, _ := json.Marshal(transcriptResponse{
+		Text:         r.text.String(),
+		Reasoning:    r.reasoning.String(),
+		ToolCalls:    r.toolCalls,
+		FinishReason: r.finishReason,
+	})
+	return out
+}
+
+// save stores the transcript in the background.
+func (r *transcriptRecorder) save() {
+	t := r.transcript
+	t.Response = r.response()
+	if !json.Valid(t.Request) {
+		t.Request, _ = json.Marshal(string(t.Request))
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), transcriptSaveTimeout)
+		defer cancel()
+		if err := r.backend.SaveTranscript(ctx, t); err != nil {
+			log.Warnf("transcript %s: %v", t.ID, err)
+		}
+	}()
+}
@@ -0,0 +1,77 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/nghyane/llm-mux/internal/usage"
+	"github.com/tidwall/gjson"
+)
+
+func TestTranscriptRecorderAssemblesResponses(t *testing.T) {
+	tests := []struct {
+		format string
+		chunks []string
+		want   string
+	}{
+		{
+			format: constant.OpenAI,
+			chunks: []string{
+				"data: {\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":\"Hel\"}}]}\n\n",
+				"data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"lo\"}}]}\n\n",
+				"data: {\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"id\":\"call_1\",\"function\":{\"name\":\"f\",\"arguments\":\"{\\\"a\\\"\"}}]}}]}\n\n",
+				"data: {\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\":1}\"}}]}}]}\n\n",
+				"data: {\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"tool_calls\"}]}\n\ndata: [DONE]\n\n",
+			},
+			want: `{"text":"Hello","tool_calls":[{"id":"call_1","name":"f","arguments":"{\"a\":1}"}],"finish_reason":"tool_calls"}`,
+		},
+		{
+			format: constant.Claude,
+			chunks: []string{
+				"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{}}\n\n",
+				"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"thinking\",\"thinking\":\"\"}}\n\nevent: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"thinking_delta\",\"thinking\":\"hmm\"}}\n\n",
+				"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":1,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\nevent: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\n\n",
+				"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":2,\"content_block\":{\"type\":\"tool_use\",\"id\":\"toolu_1\",\"name\":\"f\",\"input\":{}}}\n\nevent: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":2,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"{}\"}}\n\n",
+				"event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"tool_use\"}}\n\n",
+			},
+			want: `{"text":"Hi","reasoning":"hmm","tool_calls":[{"id":"toolu_1","name":"f","arguments":"{}"}],"finish_reason":"tool_use"}`,
+		},
+		{
+			format: constant.OpenaiResponse,
+			chunks: []string{
+				"event: response.output_text.delta\ndata: {\"type\":\"response.output_text.delta\",\"delta\":\"Hi\"}\n\n",
+				"event: response.output_item.done\ndata: {\"type\":\"response.output_item.done\",\"item\":{\"type\":\"message\",\"content\":[{\"type\":\"output_text\",\"text\":\"Hi\"}]}}\n\n",
+				"event: response.completed\ndata: {\"type\":\"response.completed\",\"response\":{\"status\":\"completed\"}}\n\n",
+			},
+			want: `{"text":"Hi","finish_reason":"completed"}`,
+		},
+		{
+			format: constant.Gemini,
+			chunks: []string{
+				"{\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"think\",\"thought\":true},{\"text\":\"Hi\"}]}}]}\n",
+				"{\"candidates\":[{\"content\":{\"parts\":[{\"functionCall\":{\"name\":\"f\",\"args\":{\"a\":1}}}]},\"finishReason\":\"STOP\"}]}\n",
+			},
+			want: `{"text":"Hi","reasoning":"think","tool_calls":[{"name":"f","arguments":"{\"a\":1}"}],"finish_reason":"STOP"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			r := &transcriptRecorder{transcript: usage.Transcript{Format: tt.format}, toolIndex: make(map[int64]int)}
+			for _, chunk := range tt.chunks {
+				r.observe([]byte(chunk))
+			}
+			if got := string(r.response()); got != tt.want {
+				t.Errorf("response = %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscriptRecorderNonStream(t *testing.T) {
+	r := &transcriptRecorder{transcript: usage.Transcript{Format: constant.Claude}, toolIndex: make(map[int64]int)}
+	r.observeDocument(gjson.Parse(`{"type":"message","content":[{"type":"text","text":"Hi"},{"type":"tool_use","id":"toolu_1","name":"f","input":{"a":1}}],"stop_reason":"tool_use"}`))
+	want := `{"text":"Hi","tool_calls":[{"id":"toolu_1","name":"f","arguments":"{\"a\":1}"}],"finish_reason":"tool_use"}`
+	if got := string(r.response()); got != want {
+		t.Errorf("response = %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+package format
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
+	"github.com/nghyane/llm-mux/internal/sseutil"
+	"github.com/nghyane/llm-mux/internal/translator"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/preprocess"
+	"github.com/tidwall/gjson"
+)
+
+// Validate answers POST /v1/validate: it runs the request body, in the
+// client format given by the format query parameter (default openai),
+// through parsing, IR conversion, limit normalization and translation for
+// one provider, and returns the payload that would be sent upstream with
+// warnings about anything changed or dropped. Nothing is sent upstream.
+// The provider query parameter picks the provider; by default the first
+// one serving the model is used. cfg supplies the payload rules.
+func (h *BaseAPIHandler) Validate(c *gin.Context, cfg *config.Config) {
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		h.WriteErrorResponse(c, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: err})
+		return
+	}
+	handlerType := c.DefaultQuery("format", constant.OpenAI)
+	modelName := c.Query("model")
+	if modelName == "" {
+		modelName = gjson.GetBytes(rawJSON, "model").String()
+	}
+	ctx := provider.WithClientAPIKey(c.Request.Context(), c.GetString("apiKey"))
+	ctx = context.WithValue(ctx, ctxKeyGin, c)
+	ctx = preprocess.WithPreview(ctx)
+
+	report := gin.H{
+		"object":        "llm-mux.validate",
+		"source_format": handlerType,
+	}
+	warnings := make([]string, 0)
+	fail := func(msg string) {
+		report["valid"] = false
+		report["error"] = msg
+		report["warnings"] = warnings
+		c.JSON(http.StatusOK, report)
+	}
+
+	requested, err := translator.ParseRequest(handlerType, sseutil.SanitizeUndefinedValues(rawJSON))
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+	for _, field := range unknownParams(handlerType, rawJSON) {
+		warnings = append(warnings, fmt.Sprintf("%s is not translated and would be dropped", field))
+	}
+
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(ctx, modelName)
+	report["model"] = gin.H{"requested": modelName, "resolved": normalizedModel}
+	if errMsg != nil {
+		fail(errMsg.Error.Error())
+		return
+	}
+	target := providers[0]
+	if p := strings.ToLower(strings.TrimSpace(c.Query("provider"))); p != "" {
+		if !slices.Contains(providers, p) {
+			warnings = append(warnings, fmt.Sprintf("provider %s does not serve %s (served by %s)", p, normalizedModel, strings.Join(providers, ", ")))
+		}
+		target = p
+	}
+	upstreamModel := registry.GetGlobalRegistry().GetModelIDForProvider(normalizedModel, target)
+	report["provider"] = target
+	report["model"] = gin.H{"requested": modelName, "resolved": normalizedModel, "upstream": upstreamModel}
+
+	from := provider.Format(handlerType)
+	effective, err := stream.ConvertRequestToIR(ctx, from, upstreamModel, rawJSON, metadata)
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+	warnings = append(warnings, limitWarnings(requested, effective)...)
+
+	upstreamFormat, payload, err := stream.TranslateForProvider(ctx, cfg, target, from, upstreamModel, rawJSON, metadata)
+	if err != nil {
+		fail(fmt.Sprintf("%s: %v", target, err))
+		return
+	}
+	report["valid"] = true
+	report["upstream_format"] = upstreamFormat
+	report["payload"] = json.RawMessage(payload)
+	report["warnings"] = warnings
+	c.JSON(http.StatusOK, report)
+}
+
+// limitWarnings describes the max_tokens and thinking changes limit
+// normalization made between the requested and effective request.
+func limitWarnings(requested, effective *ir.UnifiedChatRequest) []string {
+	var out []string
+	if requested.MaxTokens != nil && effective.MaxTokens != nil && *requested.MaxTokens != *effective.MaxTokens {
+		out = append(out, fmt.Sprintf("max_tokens %d lowered to the model limit %d", *requested.MaxTokens, *effective.MaxTokens))
+	}
+	if requested.Thinking != nil && effective.Thinking != nil {
+		from, to := requested.Thinking.ThinkingBudget, effective.Thinking.ThinkingBudget
+		if from != nil && to != nil && *from != *to {
+			out = append(out, fmt.Sprintf("thinking budget %d adjusted to %d for the model", *from, *to))
+		}
+	}
+	return out
+}
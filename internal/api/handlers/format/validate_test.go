@@ -0,0 +1,33 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func TestLimitWarnings(t *testing.T) {
+	intp := func(v int) *int { return &v }
+	budget := func(v int32) *ir.ThinkingConfig { return &ir.ThinkingConfig{ThinkingBudget: &v} }
+
+	requested := &ir.UnifiedChatRequest{MaxTokens: intp(200000), Thinking: budget(64000)}
+	effective := &ir.UnifiedChatRequest{MaxTokens: intp(64000), Thinking: budget(32000)}
+	got := limitWarnings(requested, effective)
+	want := []string{
+		"max_tokens 200000 lowered to the model limit 64000",
+		"thinking budget 64000 adjusted to 32000 for the model",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("warnings = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("warning %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	unchanged := &ir.UnifiedChatRequest{MaxTokens: intp(1024)}
+	if got := limitWarnings(unchanged, &ir.UnifiedChatRequest{MaxTokens: intp(1024)}); len(got) != 0 {
+		t.Errorf("unchanged request warnings = %q", got)
+	}
+}
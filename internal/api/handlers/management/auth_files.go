@@ -113,11 +113,18 @@ func (h *Handler) ListAuthFiles(c *gin.Context) {
 	auths := h.authManager.List()
 	quotaManager := h.authManager.GetQuotaManager()
 	now := time.Now()
+	tagFilter := parseTagQuery(c.QueryArray("tag"))
 
 	files := make([]gin.H, 0, len(auths))
 	for _, auth := range auths {
+		if !provider.MatchTags(auth.Tags(), tagFilter) {
+			continue
+		}
 		if entry := h.buildAuthFileEntry(auth); entry != nil {
 			h.enrichWithQuotaState(entry, auth.ID, quotaManager, now)
+			if spend, ok := h.authManager.SpendLimiter().Status(auth.Provider, auth.ID); ok {
+				entry["spend"] = spend
+			}
 			files = append(files, entry)
 		}
 	}
@@ -237,6 +244,12 @@ func (h *Handler) buildAuthFileEntry(auth *provider.Auth) gin.H {
 	if email := authEmail(auth); email != "" {
 		entry["email"] = email
 	}
+	if tenant, _ := auth.Metadata["tenant"].(string); tenant != "" {
+		entry["tenant"] = tenant
+	}
+	if tags := auth.Tags(); len(tags) > 0 {
+		entry["tags"] = tags
+	}
 	if accountType, account := auth.AccountInfo(); accountType != "" || account != "" {
 		if accountType != "" {
 			entry["account_type"] = accountType
@@ -513,6 +526,83 @@ func (h *Handler) DeleteAuthFile(c *gin.Context) {
 	respondOK(c, gin.H{"status": "ok"})
 }
 
+// authFilePatch is the body of PATCH /auth-files. Nil fields are left unchanged;
+// an empty tags object clears all tags.
+type authFilePatch struct {
+	Name  string            `json:"name" binding:"required"`
+	Label *string           `json:"label,omitempty"`
+	Tags  map[string]string `json:"tags,omitempty"`
+}
+
+// PatchAuthFile updates the label and tags of an auth and saves them to its file.
+func (h *Handler) PatchAuthFile(c *gin.Context) {
+	if h.authManager == nil {
+		respondError(c, http.StatusServiceUnavailable, ErrCodeInternalError, "core auth manager unavailable")
+		return
+	}
+	var body authFilePatch
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondBadRequest(c, "invalid body: name is required")
+		return
+	}
+	name := filepath.Base(body.Name)
+	if name != body.Name || !strings.HasSuffix(strings.ToLower(name), ".json") {
+		respondBadRequest(c, "invalid name")
+		return
+	}
+	auth, ok := h.authManager.GetByID(h.authIDForPath(filepath.Join(h.cfg.AuthDir, name)))
+	if !ok {
+		respondNotFound(c, "auth not found")
+		return
+	}
+	if err := flattenTokenStorage(auth); err != nil {
+		respondInternalError(c, err.Error())
+		return
+	}
+	if body.Label != nil {
+		label := strings.TrimSpace(*body.Label)
+		auth.Label = label
+		if auth.Metadata == nil {
+			auth.Metadata = make(map[string]any)
+		}
+		if label == "" {
+			delete(auth.Metadata, "label")
+		} else {
+			auth.Metadata["label"] = label
+		}
+	}
+	if body.Tags != nil {
+		tags := make(map[string]string, len(body.Tags))
+		for k, v := range body.Tags {
+			if k = strings.TrimSpace(k); k != "" {
+				tags[k] = strings.TrimSpace(v)
+			}
+		}
+		auth.SetTags(tags)
+	}
+	auth.UpdatedAt = time.Now()
+	if _, err := h.authManager.Update(c.Request.Context(), auth); err != nil {
+		respondInternalError(c, fmt.Sprintf("failed to update auth: %v", err))
+		return
+	}
+	respondOK(c, gin.H{"status": "ok", "label": auth.Label, "tags": auth.Tags()})
+}
+
+// parseTagQuery parses repeated ?tag=key=value filters.
+func parseTagQuery(values []string) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(values))
+	for _, v := range values {
+		key, value, _ := strings.Cut(v, "=")
+		if key = strings.TrimSpace(key); key != "" {
+			tags[key] = strings.TrimSpace(value)
+		}
+	}
+	return tags
+}
+
 func (h *Handler) authIDForPath(path string) string {
 	path = strings.TrimSpace(path)
 	if path == "" {
@@ -0,0 +1,56 @@
+package management
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/auth/login"
+)
+
+// cliImportRequest selects which official CLI tools to import. Empty imports all.
+type cliImportRequest struct {
+	Tools []string `json:"tools,omitempty"`
+}
+
+// ImportCLICredentials handles POST /v1/management/cli/import.
+// It converts the Gemini CLI, Claude Code, Codex CLI and Qwen CLI logins found in
+// the server user's home directory into auth files.
+func (h *Handler) ImportCLICredentials(c *gin.Context) {
+	if h == nil || h.cfg == nil || h.cfg.AuthDir == "" {
+		respondError(c, http.StatusServiceUnavailable, ErrCodeInternalError, "auth directory not configured")
+		return
+	}
+	var req cliImportRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "invalid body")
+			return
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		respondInternalError(c, err.Error())
+		return
+	}
+
+	type importedFile struct {
+		login.CLIImport
+		AuthFile string `json:"auth_file,omitempty"`
+	}
+	results := login.DiscoverCLICredentials(home, req.Tools)
+	files := make([]importedFile, 0, len(results))
+	imported := 0
+	for _, r := range results {
+		entry := importedFile{CLIImport: r}
+		if r.Error == "" {
+			if entry.AuthFile, err = h.saveTokenRecord(c.Request.Context(), r.Auth); err != nil {
+				entry.Error = err.Error()
+			} else {
+				imported++
+			}
+		}
+		files = append(files, entry)
+	}
+	respondOK(c, gin.H{"status": "ok", "imported": imported, "files": files})
+}
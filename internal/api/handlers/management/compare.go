@@ -0,0 +1,123 @@
+package management
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const compareTimeout = 5 * time.Minute
+
+// compareRequest is the body of POST /compare.
+type compareRequest struct {
+	// Request is an OpenAI chat completion request; its model is replaced
+	// by each target's and it is always sent non-streaming.
+	Request json.RawMessage `json:"request"`
+	Targets []compareTarget `json:"targets"`
+}
+
+// compareTarget is a model and, optionally, the provider that must serve it.
+type compareTarget struct {
+	Model    string `json:"model"`
+	Provider string `json:"provider,omitempty"`
+}
+
+// compareResult is the outcome of the request on one target.
+type compareResult struct {
+	Model     string                `json:"model"`
+	Providers []string              `json:"providers"`
+	OK        bool                  `json:"ok"`
+	LatencyMs int64                 `json:"latency_ms"`
+	Response  *providerTestResponse `json:"response,omitempty"`
+	Error     string                `json:"error,omitempty"`
+}
+
+// Compare sends the same chat request to two models or providers at once
+// and returns both responses with their latency and usage side by side, for
+// manual quality checks. Upstream failures are reported in the results.
+func (h *Handler) Compare(c *gin.Context) {
+	if h.authManager == nil {
+		respondInternalError(c, "auth manager unavailable")
+		return
+	}
+	var body compareRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondBadRequest(c, "invalid body")
+		return
+	}
+	if !gjson.GetBytes(body.Request, "messages").IsArray() {
+		respondBadRequest(c, "request must be a chat completion request with messages")
+		return
+	}
+	if len(body.Targets) != 2 {
+		respondBadRequest(c, "targets must name exactly two models")
+		return
+	}
+	providers := make([][]string, len(body.Targets))
+	for i := range body.Targets {
+		t := &body.Targets[i]
+		t.Model = strings.TrimSpace(t.Model)
+		t.Provider = strings.ToLower(strings.TrimSpace(t.Provider))
+		switch {
+		case t.Model == "":
+			respondBadRequest(c, "every target needs a model")
+			return
+		case t.Provider != "":
+			if !h.authManager.HasExecutor(t.Provider) {
+				respondNotFound(c, "provider not found: "+t.Provider)
+				return
+			}
+			providers[i] = []string{t.Provider}
+		default:
+			if providers[i] = util.GetProviderName(t.Model); len(providers[i]) == 0 {
+				respondBadRequest(c, "no provider serves model "+t.Model)
+				return
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), compareTimeout)
+	defer cancel()
+	results := make([]compareResult, len(body.Targets))
+	var wg sync.WaitGroup
+	for i, t := range body.Targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.compareOne(ctx, body.Request, t.Model, providers[i])
+		}()
+	}
+	wg.Wait()
+	respondOK(c, gin.H{"results": results})
+}
+
+func (h *Handler) compareOne(ctx context.Context, request []byte, model string, providers []string) compareResult {
+	payload, _ := sjson.SetBytes(request, "model", model)
+	payload, _ = sjson.SetBytes(payload, "stream", false)
+
+	start := time.Now()
+	resp, err := h.authManager.Execute(ctx, providers, provider.Request{Model: model, Payload: payload}, provider.Options{
+		SourceFormat:    provider.FormatOpenAI,
+		OriginalRequest: payload,
+	})
+	result := compareResult{
+		Model:     model,
+		Providers: providers,
+		OK:        err == nil,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Response = summarizeResponse(resp.Payload, 0)
+	}
+	return result
+}
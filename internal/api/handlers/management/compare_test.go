@@ -0,0 +1,130 @@
+package management
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/tidwall/gjson"
+)
+
+// compareExecutor answers once every executor sharing started has been
+// called, so targets run one after another fail.
+type compareExecutor struct {
+	name    string
+	err     error
+	started *sync.WaitGroup
+}
+
+func (e *compareExecutor) Identifier() string { return e.name }
+
+func (e *compareExecutor) Execute(_ context.Context, _ *provider.Auth, req provider.Request, _ provider.Options) (provider.Response, error) {
+	e.started.Done()
+	all := make(chan struct{})
+	go func() {
+		e.started.Wait()
+		close(all)
+	}()
+	select {
+	case <-all:
+	case <-time.After(5 * time.Second):
+		return provider.Response{}, errors.New("targets did not run concurrently")
+	}
+	if e.err != nil {
+		return provider.Response{}, e.err
+	}
+	payload := `{"choices":[{"index":0,"message":{"role":"assistant","content":"from ` + req.Model + `"},"finish_reason":"stop"}],"usage":{"total_tokens":3}}`
+	return provider.Response{Payload: []byte(payload)}, nil
+}
+
+func (e *compareExecutor) ExecuteStream(context.Context, *provider.Auth, provider.Request, provider.Options) (<-chan provider.StreamChunk, error) {
+	return nil, errors.New("not supported")
+}
+
+func (e *compareExecutor) Refresh(_ context.Context, auth *provider.Auth) (*provider.Auth, error) {
+	return auth, nil
+}
+
+func (e *compareExecutor) CountTokens(context.Context, *provider.Auth, provider.Request, provider.Options) (provider.Response, error) {
+	return provider.Response{}, nil
+}
+
+func newCompareHandler(t *testing.T) *Handler {
+	t.Helper()
+	manager := provider.NewManager(nil, nil, nil)
+	t.Cleanup(manager.Stop)
+	started := new(sync.WaitGroup)
+	started.Add(2)
+	for _, e := range []*compareExecutor{
+		{name: "compare-ok", started: started},
+		{name: "compare-down", err: errors.New("upstream down"), started: started},
+	} {
+		manager.RegisterExecutor(e)
+		if _, err := manager.Register(context.Background(), &provider.Auth{ID: e.name + "-1", Provider: e.name}); err != nil {
+			t.Fatal(err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(e.name+"-1", e.name, []*registry.ModelInfo{{ID: e.name + "-model", Type: e.name}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(e.name + "-1") })
+	}
+	return &Handler{authManager: manager}
+}
+
+func runCompare(t *testing.T, h *Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/management/compare", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	h.Compare(c)
+	return w
+}
+
+func TestCompareValidatesTargets(t *testing.T) {
+	h := newCompareHandler(t)
+	const request = `"request":{"messages":[{"role":"user","content":"hi"}]}`
+	for _, tt := range []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"one target", `{` + request + `,"targets":[{"model":"compare-ok-model"}]}`, http.StatusBadRequest},
+		{"three targets", `{` + request + `,"targets":[{"model":"compare-ok-model"},{"model":"compare-ok-model"},{"model":"compare-ok-model"}]}`, http.StatusBadRequest},
+		{"no messages", `{"request":{"prompt":"hi"},"targets":[{"model":"compare-ok-model"},{"model":"compare-down-model"}]}`, http.StatusBadRequest},
+		{"missing model", `{` + request + `,"targets":[{"model":"compare-ok-model"},{"model":" "}]}`, http.StatusBadRequest},
+		{"unknown provider", `{` + request + `,"targets":[{"model":"compare-ok-model"},{"model":"compare-ok-model","provider":"nope"}]}`, http.StatusNotFound},
+		{"unservable model", `{` + request + `,"targets":[{"model":"compare-ok-model"},{"model":"compare-missing-model"}]}`, http.StatusBadRequest},
+	} {
+		if w := runCompare(t, h, tt.body); w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, w.Code, tt.status, w.Body.String())
+		}
+	}
+}
+
+func TestCompareRunsTargetsConcurrently(t *testing.T) {
+	h := newCompareHandler(t)
+	w := runCompare(t, h, `{"request":{"model":"ignored","messages":[{"role":"user","content":"hi"}]},"targets":[{"model":"compare-ok-model"},{"model":"compare-down-model","provider":"Compare-Down"}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+
+	results := gjson.Get(w.Body.String(), "data.results").Array()
+	if len(results) != 2 {
+		t.Fatalf("results = %s", w.Body.String())
+	}
+	ok, down := results[0], results[1]
+	if !ok.Get("ok").Bool() || ok.Get("response.text").String() != "from compare-ok-model" || ok.Get("providers").Raw != `["compare-ok"]` {
+		t.Errorf("successful target = %s", ok.Raw)
+	}
+	if down.Get("ok").Bool() || !strings.Contains(down.Get("error").String(), "upstream down") || down.Get("response").Exists() || down.Get("providers").Raw != `["compare-down"]` {
+		t.Errorf("failing target = %s", down.Raw)
+	}
+}
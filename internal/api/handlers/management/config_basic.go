@@ -15,6 +15,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/config"
 	"github.com/nghyane/llm-mux/internal/usage"
 	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/nghyane/llm-mux/internal/watcher"
 	"gopkg.in/yaml.v3"
 )
 
@@ -43,6 +44,16 @@ func (h *Handler) GetConfig(c *gin.Context) {
 	respondOK(c, &cfgCopy)
 }
 
+// GetConfigReloadStatus reports whether the last config hot reload was applied or
+// rejected (in which case the previous config is still being served).
+func (h *Handler) GetConfigReloadStatus(c *gin.Context) {
+	if h.reloadStatus == nil {
+		respondOK(c, watcher.ReloadStatus{})
+		return
+	}
+	respondOK(c, h.reloadStatus())
+}
+
 type releaseInfo struct {
 	TagName string `json:"tag_name"`
 	Name    string `json:"name"`
@@ -228,6 +239,11 @@ func (h *Handler) GetConfigYAML(c *gin.Context) {
 	_, _ = c.Writer.Write(data)
 }
 
+// GetConfigSchema serves the JSON Schema of config.yaml for editors and linters.
+func (h *Handler) GetConfigSchema(c *gin.Context) {
+	c.Data(http.StatusOK, "application/schema+json", config.Schema())
+}
+
 func (h *Handler) GetDebug(c *gin.Context) {
 	cfg := h.getConfig()
 	respondOK(c, gin.H{"debug": cfg.Debug})
@@ -319,6 +335,32 @@ func (h *Handler) PutRequestLog(c *gin.Context) {
 	respondOK(c, gin.H{"request-log": cfg.RequestLog})
 }
 
+// Access log
+func (h *Handler) GetAccessLog(c *gin.Context) {
+	cfg := h.getConfig()
+	respondOK(c, gin.H{"access-log": cfg.AccessLog})
+}
+func (h *Handler) PutAccessLog(c *gin.Context) {
+	var body config.AccessLogConfig
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondBadRequest(c, "invalid body")
+		return
+	}
+	if body.SampleRate < 0 || body.SampleRate > 1 {
+		respondBadRequest(c, "sample-rate must be between 0 and 1")
+		return
+	}
+	h.cfgMu.Lock()
+	h.cfg.AccessLog = body
+	h.cfgMu.Unlock()
+	if !h.persistSilent() {
+		respondInternalError(c, "failed to save config")
+		return
+	}
+	cfg := h.getConfig()
+	respondOK(c, gin.H{"access-log": cfg.AccessLog})
+}
+
 // Websocket auth
 func (h *Handler) GetWebsocketAuth(c *gin.Context) {
 	cfg := h.getConfig()
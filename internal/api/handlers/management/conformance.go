@@ -0,0 +1,470 @@
+package management
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	conformanceModel     = "conformance-model"
+	conformanceMessageID = "msg_conformance"
+	conformanceText      = "Hello world"
+	conformanceToolName  = "get_weather"
+	conformanceTimeout   = 10 * time.Second
+)
+
+// conformanceFormats are the client formats checked by GET /conformance, in
+// report order.
+var conformanceFormats = []string{
+	constant.OpenAI,
+	constant.OpenaiResponse,
+	constant.Claude,
+	constant.Gemini,
+	constant.GeminiCLI,
+	constant.Ollama,
+}
+
+// conformanceStream is the canned upstream stream: text in two deltas, one
+// tool call, then a finish chunk with usage.
+const conformanceStream = `data: {"id":"chatcmpl-conformance","object":"chat.completion.chunk","created":1,"model":"conformance-model","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-conformance","object":"chat.completion.chunk","created":1,"model":"conformance-model","choices":[{"index":0,"delta":{"content":" world"},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-conformance","object":"chat.completion.chunk","created":1,"model":"conformance-model","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_conformance","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-conformance","object":"chat.completion.chunk","created":1,"model":"conformance-model","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":12,"completion_tokens":8,"total_tokens":20}}
+
+data: [DONE]
+
+`
+
+// conformanceResponse is the non-streaming equivalent of conformanceStream.
+const conformanceResponse = `{"id":"chatcmpl-conformance","object":"chat.completion","created":1,"model":"conformance-model","choices":[{"index":0,"message":{"role":"assistant","content":"Hello world","tool_calls":[{"id":"call_conformance","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":12,"completion_tokens":8,"total_tokens":20}}`
+
+// conformanceReport is the result of GET /conformance.
+type conformanceReport struct {
+	OK      bool                `json:"ok"`
+	Formats []conformanceResult `json:"formats"`
+}
+
+// conformanceResult holds the checks run for one client format.
+type conformanceResult struct {
+	Format string             `json:"format"`
+	OK     bool               `json:"ok"`
+	Checks []conformanceCheck `json:"checks"`
+}
+
+// conformanceCheck is a single pass/fail assertion.
+type conformanceCheck struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// GetConformance streams a canned upstream response through the translation
+// path of every client format and validates event ordering and JSON shapes, so
+// an upgrade can be checked before it serves traffic. Failures are reported
+// in the body; the status is 200 whenever the checks ran.
+func (h *Handler) GetConformance(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), conformanceTimeout)
+	defer cancel()
+	respondOK(c, runConformance(ctx, h.getConfig()))
+}
+
+func runConformance(ctx context.Context, cfg *config.Config) conformanceReport {
+	report := conformanceReport{OK: true}
+	for _, format := range conformanceFormats {
+		result := conformanceResult{Format: format, OK: true}
+		add := func(name string, err error) {
+			check := conformanceCheck{Name: name, OK: err == nil}
+			if err != nil {
+				check.Error = err.Error()
+				result.OK = false
+			}
+			result.Checks = append(result.Checks, check)
+		}
+
+		events, err := conformanceStreamEvents(ctx, cfg, format)
+		add("stream", err)
+		if err == nil {
+			add("stream shape", conformanceValidators[format].stream(events))
+		}
+		add("response shape", conformanceNonStream(cfg, format))
+
+		report.OK = report.OK && result.OK
+		report.Formats = append(report.Formats, result)
+	}
+	return report
+}
+
+// conformanceEvent is one event of a translated stream: the SSE event name,
+// if any, and its JSON data.
+type conformanceEvent struct {
+	name string
+	data gjson.Result
+}
+
+// conformanceStreamEvents runs conformanceStream through the stream pipeline
+// used by OpenAI-format executors and splits the output into events.
+func conformanceStreamEvents(ctx context.Context, cfg *config.Config, format string) ([]conformanceEvent, error) {
+	processor := stream.NewOpenAIStreamProcessor(cfg, provider.FromString(format), conformanceModel, conformanceMessageID)
+	chunks := stream.RunSSEStream(ctx, io.NopCloser(strings.NewReader(conformanceStream)), nil, processor, stream.StreamConfig{
+		ExecutorName:     "conformance",
+		Preprocessor:     stream.DataTagPreprocessor(),
+		HandleDoneSignal: true,
+	})
+	var out bytes.Buffer
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			return nil, chunk.Err
+		}
+		out.Write(chunk.Payload)
+		out.WriteByte('\n')
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return parseConformanceEvents(out.Bytes())
+}
+
+// parseConformanceEvents splits SSE or newline-delimited JSON output into
+// events. Every data payload must be a JSON object.
+func parseConformanceEvents(out []byte) ([]conformanceEvent, error) {
+	var events []conformanceEvent
+	name := ""
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		switch {
+		case len(line) == 0:
+			continue
+		case bytes.HasPrefix(line, []byte("event:")):
+			name = string(bytes.TrimSpace(line[len("event:"):]))
+			continue
+		case bytes.HasPrefix(line, []byte("data:")):
+			line = bytes.TrimSpace(line[len("data:"):])
+		}
+		if string(line) == "[DONE]" {
+			continue
+		}
+		if !gjson.ValidBytes(line) || !gjson.ParseBytes(line).IsObject() {
+			return nil, fmt.Errorf("event %d is not a JSON object: %.120s", len(events), line)
+		}
+		events = append(events, conformanceEvent{name: name, data: gjson.ParseBytes(line)})
+		name = ""
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("stream produced no events")
+	}
+	return events, nil
+}
+
+func conformanceNonStream(cfg *config.Config, format string) error {
+	out, err := stream.TranslateResponseNonStream(cfg, provider.FormatOpenAI, provider.FromString(format), []byte(conformanceResponse), conformanceModel)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		out = []byte(conformanceResponse)
+	}
+	if !gjson.ValidBytes(out) {
+		return fmt.Errorf("response is not valid JSON: %.120s", out)
+	}
+	return conformanceValidators[format].response(gjson.ParseBytes(out))
+}
+
+// conformanceValidator checks the translated stream and response of a format.
+type conformanceValidator struct {
+	stream   func([]conformanceEvent) error
+	response func(gjson.Result) error
+}
+
+var conformanceValidators = map[string]conformanceValidator{
+	constant.OpenAI:         {validateOpenAIStream, validateOpenAIResponse},
+	constant.OpenaiResponse: {validateResponsesStream, validateResponsesResponse},
+	constant.Claude:         {validateClaudeStream, validateClaudeResponse},
+	constant.Gemini:         {validateGeminiStream, validateGeminiResponse},
+	constant.GeminiCLI:      {validateGeminiStream, validateGeminiResponse},
+	constant.Ollama:         {validateOllamaStream, validateOllamaResponse},
+}
+
+func validateOpenAIStream(events []conformanceEvent) error {
+	var text strings.Builder
+	tool, finishes := "", 0
+	for i, ev := range events {
+		if got := ev.data.Get("object").String(); got != "chat.completion.chunk" {
+			return fmt.Errorf("chunk %d: object = %q, want chat.completion.chunk", i, got)
+		}
+		if !ev.data.Get("choices").IsArray() {
+			return fmt.Errorf("chunk %d: choices is not an array", i)
+		}
+		if finishes > 0 && len(ev.data.Get("choices").Array()) > 0 {
+			return fmt.Errorf("chunk %d: choices after finish_reason", i)
+		}
+		text.WriteString(ev.data.Get("choices.0.delta.content").String())
+		if name := ev.data.Get("choices.0.delta.tool_calls.0.function.name").String(); name != "" {
+			tool = name
+		}
+		if reason := ev.data.Get("choices.0.finish_reason"); reason.Exists() && reason.Type != gjson.Null {
+			finishes++
+		}
+	}
+	if finishes != 1 {
+		return fmt.Errorf("%d chunks carry finish_reason, want 1", finishes)
+	}
+	return expectContent(text.String(), tool)
+}
+
+func validateOpenAIResponse(resp gjson.Result) error {
+	if got := resp.Get("object").String(); got != "chat.completion" {
+		return fmt.Errorf("object = %q, want chat.completion", got)
+	}
+	if resp.Get("choices.0.finish_reason").String() == "" {
+		return fmt.Errorf("choices.0.finish_reason missing")
+	}
+	return expectContent(resp.Get("choices.0.message.content").String(), resp.Get("choices.0.message.tool_calls.0.function.name").String())
+}
+
+func validateClaudeStream(events []conformanceEvent) error {
+	if err := expectNamedEvents(events); err != nil {
+		return err
+	}
+	if first := events[0].name; first != "message_start" {
+		return fmt.Errorf("first event = %s, want message_start", first)
+	}
+	if last := events[len(events)-1].name; last != "message_stop" {
+		return fmt.Errorf("last event = %s, want message_stop", last)
+	}
+	var text strings.Builder
+	tool, open, next, deltas := "", int64(-1), int64(0), 0
+	for i, ev := range events {
+		index := ev.data.Get("index").Int()
+		switch ev.name {
+		case "content_block_start":
+			if open >= 0 {
+				return fmt.Errorf("event %d: block %d started before block %d stopped", i, index, open)
+			}
+			if index != next {
+				return fmt.Errorf("event %d: block index %d, want %d", i, index, next)
+			}
+			open, next = index, index+1
+			if ev.data.Get("content_block.type").String() == "tool_use" {
+				tool = ev.data.Get("content_block.name").String()
+			}
+		case "content_block_delta":
+			if open < 0 || index != open {
+				return fmt.Errorf("event %d: delta for block %d, which is not open", i, index)
+			}
+			text.WriteString(ev.data.Get("delta.text").String())
+		case "content_block_stop":
+			if open < 0 || index != open {
+				return fmt.Errorf("event %d: stop for block %d, which is not open", i, index)
+			}
+			open = -1
+		case "message_delta":
+			if open >= 0 {
+				return fmt.Errorf("event %d: message_delta while block %d is open", i, open)
+			}
+			if ev.data.Get("delta.stop_reason").String() == "" {
+				return fmt.Errorf("event %d: message_delta without stop_reason", i)
+			}
+			deltas++
+		case "message_start", "message_stop", "ping":
+		default:
+			return fmt.Errorf("event %d: unexpected event %s", i, ev.name)
+		}
+	}
+	if deltas != 1 {
+		return fmt.Errorf("%d message_delta events, want 1", deltas)
+	}
+	return expectContent(text.String(), tool)
+}
+
+func validateClaudeResponse(resp gjson.Result) error {
+	if resp.Get("type").String() != "message" || resp.Get("role").String() != "assistant" {
+		return fmt.Errorf("type/role = %s/%s, want message/assistant", resp.Get("type"), resp.Get("role"))
+	}
+	if resp.Get("stop_reason").String() == "" {
+		return fmt.Errorf("stop_reason missing")
+	}
+	var text strings.Builder
+	tool := ""
+	for _, block := range resp.Get("content").Array() {
+		switch block.Get("type").String() {
+		case "text":
+			text.WriteString(block.Get("text").String())
+		case "tool_use":
+			tool = block.Get("name").String()
+		}
+	}
+	return expectContent(text.String(), tool)
+}
+
+func validateResponsesStream(events []conformanceEvent) error {
+	if err := expectNamedEvents(events); err != nil {
+		return err
+	}
+	if first := events[0].name; first != "response.created" {
+		return fmt.Errorf("first event = %s, want response.created", first)
+	}
+	if last := events[len(events)-1].name; last != "response.completed" && last != "response.done" {
+		return fmt.Errorf("last event = %s, want response.completed", last)
+	}
+	var text strings.Builder
+	tool, seq := "", int64(0)
+	open := make(map[string]bool)
+	for i, ev := range events {
+		n := ev.data.Get("sequence_number").Int()
+		if n <= seq {
+			return fmt.Errorf("event %d: sequence_number %d after %d", i, n, seq)
+		}
+		seq = n
+		itemID := ev.data.Get("item_id").String()
+		switch ev.name {
+		case "response.output_item.added":
+			id := ev.data.Get("item.id").String()
+			if open[id] {
+				return fmt.Errorf("event %d: item %s added twice", i, id)
+			}
+			open[id] = true
+			if ev.data.Get("item.type").String() == "function_call" {
+				tool = ev.data.Get("item.name").String()
+			}
+		case "response.output_item.done":
+			id := ev.data.Get("item.id").String()
+			if !open[id] {
+				return fmt.Errorf("event %d: item %s done but not open", i, id)
+			}
+			delete(open, id)
+		case "response.output_text.delta", "response.function_call_arguments.delta", "response.content_part.added", "response.content_part.done":
+			if !open[itemID] {
+				return fmt.Errorf("event %d: %s for item %s, which is not open", i, ev.name, itemID)
+			}
+			if ev.name == "response.output_text.delta" {
+				text.WriteString(ev.data.Get("delta").String())
+			}
+		}
+	}
+	if len(open) > 0 {
+		return fmt.Errorf("%d output items never completed", len(open))
+	}
+	return expectContent(text.String(), tool)
+}
+
+func validateResponsesResponse(resp gjson.Result) error {
+	if got := resp.Get("object").String(); got != "response" {
+		return fmt.Errorf("object = %q, want response", got)
+	}
+	var text strings.Builder
+	tool := ""
+	for _, item := range resp.Get("output").Array() {
+		switch item.Get("type").String() {
+		case "message":
+			for _, part := range item.Get("content").Array() {
+				text.WriteString(part.Get("text").String())
+			}
+		case "function_call":
+			tool = item.Get("name").String()
+		}
+	}
+	return expectContent(text.String(), tool)
+}
+
+func validateGeminiStream(events []conformanceEvent) error {
+	var text strings.Builder
+	tool := ""
+	for i, ev := range events {
+		if !ev.data.Get("candidates").IsArray() {
+			return fmt.Errorf("chunk %d: candidates is not an array", i)
+		}
+		reason := ev.data.Get("candidates.0.finishReason").String()
+		if reason != "" && i != len(events)-1 {
+			return fmt.Errorf("chunk %d: finishReason before the last chunk", i)
+		}
+		if reason == "" && i == len(events)-1 {
+			return fmt.Errorf("last chunk has no finishReason")
+		}
+		t, name := geminiParts(ev.data)
+		text.WriteString(t)
+		if name != "" {
+			tool = name
+		}
+	}
+	return expectContent(text.String(), tool)
+}
+
+func validateGeminiResponse(resp gjson.Result) error {
+	if resp.Get("candidates.0.finishReason").String() == "" {
+		return fmt.Errorf("candidates.0.finishReason missing")
+	}
+	text, tool := geminiParts(resp)
+	return expectContent(text, tool)
+}
+
+func geminiParts(resp gjson.Result) (text, tool string) {
+	for _, part := range resp.Get("candidates.0.content.parts").Array() {
+		text += part.Get("text").String()
+		if name := part.Get("functionCall.name").String(); name != "" {
+			tool = name
+		}
+	}
+	return text, tool
+}
+
+func validateOllamaStream(events []conformanceEvent) error {
+	var text strings.Builder
+	tool := ""
+	for i, ev := range events {
+		if ev.data.Get("model").String() == "" || !ev.data.Get("message").IsObject() {
+			return fmt.Errorf("chunk %d: model or message missing", i)
+		}
+		last := i == len(events)-1
+		if done := ev.data.Get("done").Bool(); done != last {
+			return fmt.Errorf("chunk %d: done = %t", i, done)
+		}
+		text.WriteString(ev.data.Get("message.content").String())
+		if name := ev.data.Get("message.tool_calls.0.function.name").String(); name != "" {
+			tool = name
+		}
+	}
+	return expectContent(text.String(), tool)
+}
+
+func validateOllamaResponse(resp gjson.Result) error {
+	if !resp.Get("done").Bool() {
+		return fmt.Errorf("done is not true")
+	}
+	return expectContent(resp.Get("message.content").String(), resp.Get("message.tool_calls.0.function.name").String())
+}
+
+// expectNamedEvents checks that every SSE event name matches its data type.
+func expectNamedEvents(events []conformanceEvent) error {
+	for i, ev := range events {
+		if typ := ev.data.Get("type").String(); ev.name == "" || ev.name != typ {
+			return fmt.Errorf("event %d: event name %q does not match type %q", i, ev.name, typ)
+		}
+	}
+	return nil
+}
+
+// expectContent checks the text and tool call of the canned response survived
+// translation.
+func expectContent(text, tool string) error {
+	if text != conformanceText {
+		return fmt.Errorf("text = %q, want %q", text, conformanceText)
+	}
+	if tool != conformanceToolName {
+		return fmt.Errorf("tool call = %q, want %q", tool, conformanceToolName)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package management
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+func TestRunConformance(t *testing.T) {
+	report := runConformance(context.Background(), &config.Config{})
+	if len(report.Formats) != len(conformanceFormats) {
+		t.Fatalf("got %d formats, want %d", len(report.Formats), len(conformanceFormats))
+	}
+	for _, result := range report.Formats {
+		for _, check := range result.Checks {
+			if !check.OK {
+				t.Errorf("%s %s: %s", result.Format, check.Name, check.Error)
+			}
+		}
+	}
+}
+
+func TestParseConformanceEventsRejectsInvalidJSON(t *testing.T) {
+	if _, err := parseConformanceEvents([]byte("event: message_start\ndata: {\"type\":\n\n")); err == nil {
+		t.Fatal("expected error for truncated JSON")
+	}
+}
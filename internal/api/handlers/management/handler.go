@@ -3,6 +3,7 @@
 package management
 
 import (
+	"context"
 	"crypto/subtle"
 	"fmt"
 	"net/http"
@@ -13,12 +14,15 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/middleware"
 	"github.com/nghyane/llm-mux/internal/auth/login"
 	"github.com/nghyane/llm-mux/internal/buildinfo"
 	"github.com/nghyane/llm-mux/internal/config"
 	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
 	"github.com/nghyane/llm-mux/internal/usage"
 	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/nghyane/llm-mux/internal/watcher"
 )
 
 type attemptInfo struct {
@@ -41,8 +45,15 @@ type Handler struct {
 	logDir         string
 	httpClient     *http.Client
 	httpClientOnce sync.Once
+	reloadStatus   func() watcher.ReloadStatus
+	modelRefresher ModelRefresher
+	aliasCallers   func() []middleware.EndpointAliasCaller
 }
 
+// ModelRefresher re-fetches the model list of a provider from upstream and
+// reports how it changed.
+type ModelRefresher func(ctx context.Context, provider string) (registry.ModelChanges, error)
+
 func NewHandler(cfg *config.Config, configFilePath string, manager *provider.Manager) *Handler {
 	return &Handler{
 		cfg:            cfg,
@@ -84,6 +95,17 @@ func (h *Handler) SetUsagePlugin(plugin *usage.LoggerPlugin) { h.usagePlugin = p
 // SetLocalPassword configures the runtime-local password accepted for localhost requests.
 func (h *Handler) SetLocalPassword(password string) { h.localPassword = password }
 
+// SetReloadStatusProvider registers the source of config hot-reload status.
+func (h *Handler) SetReloadStatusProvider(fn func() watcher.ReloadStatus) { h.reloadStatus = fn }
+
+// SetModelRefresher registers the function refreshing provider model lists.
+func (h *Handler) SetModelRefresher(fn ModelRefresher) { h.modelRefresher = fn }
+
+// SetEndpointAliasCallers registers the source of deprecated endpoint callers.
+func (h *Handler) SetEndpointAliasCallers(fn func() []middleware.EndpointAliasCaller) {
+	h.aliasCallers = fn
+}
+
 // SetLogDirectory updates the directory where main.log should be looked up.
 func (h *Handler) SetLogDirectory(dir string) {
 	if dir == "" {
@@ -3,6 +3,7 @@ package management
 import (
 	"bufio"
 	"fmt"
+	"log/slog"
 	"math"
 	"net/http"
 	"os"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/util"
 )
 
@@ -20,8 +22,79 @@ const (
 	defaultLogFileName      = "main.log"
 	logScannerInitialBuffer = 64 * 1024
 	logScannerMaxBuffer     = 8 * 1024 * 1024
+	defaultRecentLogLines   = 200
 )
 
+// GetLogLevel returns the global log level and the per-module levels.
+func (h *Handler) GetLogLevel(c *gin.Context) {
+	respondOK(c, logLevelBody())
+}
+
+// PutLogLevel changes the global log level and replaces the per-module
+// levels at runtime. Omitted fields are left unchanged; an empty modules
+// object removes all module levels. Changes are not persisted.
+func (h *Handler) PutLogLevel(c *gin.Context) {
+	var body struct {
+		Level   *string           `json:"level"`
+		Modules map[string]string `json:"modules"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondBadRequest(c, "invalid body")
+		return
+	}
+	var level slog.Level
+	if body.Level != nil {
+		var err error
+		if level, err = logging.ParseLevel(*body.Level); err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+	}
+	var modules map[string]slog.Level
+	if body.Modules != nil {
+		modules = make(map[string]slog.Level, len(body.Modules))
+		for name, raw := range body.Modules {
+			l, err := logging.ParseLevel(raw)
+			if err != nil {
+				respondBadRequest(c, fmt.Sprintf("module %s: %v", name, err))
+				return
+			}
+			modules[name] = l
+		}
+	}
+	if body.Level != nil {
+		logging.SetLevel(level)
+	}
+	if body.Modules != nil {
+		logging.SetModuleLevels(modules)
+	}
+	logging.Infof("log level set to %s with %d module levels", logging.LevelName(logging.GetLevel()), len(logging.ModuleLevels()))
+	respondOK(c, logLevelBody())
+}
+
+func logLevelBody() gin.H {
+	modules := make(map[string]string)
+	for name, level := range logging.ModuleLevels() {
+		modules[name] = logging.LevelName(level)
+	}
+	return gin.H{"level": logging.LevelName(logging.GetLevel()), "modules": modules}
+}
+
+// GetRecentLogs returns the last log lines kept in memory, which works
+// whether or not logging to file is enabled.
+func (h *Handler) GetRecentLogs(c *gin.Context) {
+	limit, err := parseLimit(c.Query("lines"))
+	if err != nil {
+		respondBadRequest(c, fmt.Sprintf("invalid lines: %v", err))
+		return
+	}
+	if limit == 0 {
+		limit = defaultRecentLogLines
+	}
+	lines := logging.RecentLines(limit)
+	respondOK(c, gin.H{"lines": lines, "line_count": len(lines)})
+}
+
 // GetLogs returns log lines with optional incremental loading.
 func (h *Handler) GetLogs(c *gin.Context) {
 	if h == nil {
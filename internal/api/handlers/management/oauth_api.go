@@ -16,11 +16,13 @@ import (
 	"github.com/nghyane/llm-mux/internal/auth/claude"
 	"github.com/nghyane/llm-mux/internal/auth/codex"
 	"github.com/nghyane/llm-mux/internal/auth/copilot"
+	"github.com/nghyane/llm-mux/internal/auth/gemini"
 	"github.com/nghyane/llm-mux/internal/auth/iflow"
 	"github.com/nghyane/llm-mux/internal/auth/qwen"
 	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/misc"
 	"github.com/nghyane/llm-mux/internal/oauth"
+	"github.com/nghyane/llm-mux/internal/oauth/qrcode"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -42,12 +44,16 @@ var emailReplacer = strings.NewReplacer("@", "_", ".", "_")
 type OAuthStartRequest struct {
 	Provider  string `json:"provider" binding:"required"`
 	ProjectID string `json:"project_id,omitempty"`
+	// Label and Tenant are written into the resulting auth file.
+	Label  string `json:"label,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
 }
 
 // OAuthStartResponse represents the response for starting an OAuth flow.
 type OAuthStartResponse struct {
 	Status        string `json:"status"`
 	AuthURL       string `json:"auth_url,omitempty"`
+	QRCode        string `json:"qr_code,omitempty"` // PNG data URI of AuthURL for scanning on a phone
 	State         string `json:"state,omitempty"`
 	ID            string `json:"id,omitempty"`
 	Error         string `json:"error,omitempty"`
@@ -87,10 +93,10 @@ func (h *Handler) OAuthStart(c *gin.Context) {
 	// Handle device flow providers separately
 	switch providerName {
 	case "qwen":
-		h.startQwenDeviceFlow(c)
+		h.startQwenDeviceFlow(c, req)
 		return
 	case "copilot":
-		h.startCopilotDeviceFlow(c)
+		h.startCopilotDeviceFlow(c, req)
 		return
 	}
 
@@ -105,7 +111,7 @@ func (h *Handler) OAuthStart(c *gin.Context) {
 	}
 
 	// Register OAuth request with codeVerifier for PKCE providers
-	oauthReq := oauthService.Registry().Create(state, providerName, oauth.ModeWebUI)
+	oauthReq := registerOAuthRequest(state, providerName, req)
 	oauthReq.CodeVerifier = codeVerifier
 
 	// Start callback forwarder for WebUI mode
@@ -123,12 +129,36 @@ func (h *Handler) OAuthStart(c *gin.Context) {
 		Status:       "ok",
 		FlowType:     "oauth",
 		AuthURL:      authURL,
+		QRCode:       qrCodeDataURI(authURL),
 		State:        state,
 		ID:           state,
 		CodeVerifier: codeVerifier,
 	})
 }
 
+// registerOAuthRequest records a pending WebUI flow along with the requested auth binding.
+func registerOAuthRequest(state, providerName string, req OAuthStartRequest) *oauth.OAuthRequest {
+	oauthReq := oauthService.Registry().Create(state, providerName, oauth.ModeWebUI)
+	oauthReq.Label = strings.TrimSpace(req.Label)
+	oauthReq.Tenant = strings.TrimSpace(req.Tenant)
+	oauthReq.ProjectID = strings.TrimSpace(req.ProjectID)
+	return oauthReq
+}
+
+// qrCodeDataURI renders authURL as a QR code so a headless login can be approved from a phone.
+// The URL is still returned on its own, so a rendering failure only drops the image.
+func qrCodeDataURI(authURL string) string {
+	if authURL == "" {
+		return ""
+	}
+	uri, err := qrcode.DataURI(authURL)
+	if err != nil {
+		log.WithError(err).Debug("Failed to render OAuth QR code")
+		return ""
+	}
+	return uri
+}
+
 // normalizeProvider converts provider aliases to canonical names.
 func normalizeProvider(provider string) string {
 	switch provider {
@@ -170,14 +200,7 @@ func (h *Handler) pollOAuthCallback(ctx context.Context, cancel context.CancelFu
 		return
 	}
 
-	savedPath, err := h.saveTokenRecord(ctx, record)
-	if err != nil {
-		oauthService.Registry().Fail(state, fmt.Sprintf("Failed to save: %v", err))
-		return
-	}
-
-	oauthService.Registry().Complete(state, &oauth.OAuthResult{State: state, Code: "success"})
-	log.WithFields(log.Fields{"state": state, "path": savedPath, "provider": providerName}).Infof("%s authentication successful", providerName)
+	h.finishAuthFlow(ctx, state, record)
 }
 
 // waitForCallbackFile polls for the OAuth callback file and returns parsed data.
@@ -217,7 +240,7 @@ func (h *Handler) waitForCallbackFile(ctx context.Context, providerName, state s
 func (h *Handler) exchangeOAuthCode(ctx context.Context, providerName, state string, callback *oauthCallbackData) (*provider.Auth, error) {
 	switch providerName {
 	case "gemini", "antigravity":
-		return h.exchangeGoogleCode(ctx, providerName, callback.Code)
+		return h.exchangeGoogleCode(ctx, providerName, state, callback.Code)
 	case "claude":
 		return h.exchangeClaudeCode(ctx, state, callback.Code)
 	case "codex":
@@ -230,7 +253,7 @@ func (h *Handler) exchangeOAuthCode(ctx context.Context, providerName, state str
 }
 
 // startQwenDeviceFlow initiates Qwen device authorization flow.
-func (h *Handler) startQwenDeviceFlow(c *gin.Context) {
+func (h *Handler) startQwenDeviceFlow(c *gin.Context, req OAuthStartRequest) {
 	ctx, cancel := context.WithTimeout(context.Background(), deviceFlowTimeout)
 
 	qwenAuth := qwen.NewQwenAuth(h.cfg)
@@ -245,7 +268,7 @@ func (h *Handler) startQwenDeviceFlow(c *gin.Context) {
 	}
 
 	state := fmt.Sprintf("qwen-%d", time.Now().UnixNano())
-	oauthService.Registry().Create(state, "qwen", oauth.ModeWebUI)
+	registerOAuthRequest(state, "qwen", req)
 
 	go h.pollQwenToken(ctx, cancel, qwenAuth, deviceFlow, state)
 
@@ -256,6 +279,7 @@ func (h *Handler) startQwenDeviceFlow(c *gin.Context) {
 		ID:              state,
 		UserCode:        deviceFlow.UserCode,
 		AuthURL:         deviceFlow.VerificationURIComplete,
+		QRCode:          qrCodeDataURI(deviceFlow.VerificationURIComplete),
 		VerificationURL: deviceFlow.VerificationURI,
 		ExpiresIn:       deviceFlow.ExpiresIn,
 		Interval:        deviceFlow.Interval,
@@ -289,7 +313,7 @@ func (h *Handler) pollQwenToken(ctx context.Context, cancel context.CancelFunc,
 }
 
 // startCopilotDeviceFlow initiates GitHub Copilot device authorization flow.
-func (h *Handler) startCopilotDeviceFlow(c *gin.Context) {
+func (h *Handler) startCopilotDeviceFlow(c *gin.Context, req OAuthStartRequest) {
 	ctx, cancel := context.WithTimeout(context.Background(), deviceFlowTimeout)
 
 	copilotAuth := copilot.NewCopilotAuth(h.cfg)
@@ -304,7 +328,7 @@ func (h *Handler) startCopilotDeviceFlow(c *gin.Context) {
 	}
 
 	state := fmt.Sprintf("copilot-%s", deviceCode.DeviceCode[:8])
-	oauthService.Registry().Create(state, "copilot", oauth.ModeWebUI)
+	registerOAuthRequest(state, "copilot", req)
 
 	go h.pollCopilotToken(ctx, cancel, copilotAuth, deviceCode, state)
 
@@ -315,6 +339,7 @@ func (h *Handler) startCopilotDeviceFlow(c *gin.Context) {
 		ID:              state,
 		UserCode:        deviceCode.UserCode,
 		AuthURL:         deviceCode.VerificationURI,
+		QRCode:          qrCodeDataURI(deviceCode.VerificationURI),
 		VerificationURL: deviceCode.VerificationURI,
 		ExpiresIn:       deviceCode.ExpiresIn,
 		Interval:        deviceCode.Interval,
@@ -371,6 +396,14 @@ func (h *Handler) handlePollError(ctx context.Context, state, providerName strin
 
 // finishAuthFlow saves the auth record and completes the OAuth flow.
 func (h *Handler) finishAuthFlow(ctx context.Context, state string, record *provider.Auth) {
+	if oauthReq := oauthService.Registry().Get(state); oauthReq != nil {
+		if err := bindAuthRecord(record, oauthReq.Label, oauthReq.Tenant); err != nil {
+			oauthService.Registry().Fail(state, fmt.Sprintf("Failed to bind auth: %v", err))
+			log.WithError(err).WithField("state", state).Error("Failed to bind auth")
+			return
+		}
+	}
+
 	savedPath, err := h.saveTokenRecord(ctx, record)
 	if err != nil {
 		oauthService.Registry().Fail(state, fmt.Sprintf("Failed to save tokens: %v", err))
@@ -536,7 +569,7 @@ var googleOAuthConfigs = map[string]googleOAuthConfig{
 	},
 }
 
-func (h *Handler) exchangeGoogleCode(ctx context.Context, providerName, code string) (*provider.Auth, error) {
+func (h *Handler) exchangeGoogleCode(ctx context.Context, providerName, state, code string) (*provider.Auth, error) {
 	cfg, ok := googleOAuthConfigs[providerName]
 	if !ok {
 		return nil, fmt.Errorf("unknown Google OAuth provider: %s", providerName)
@@ -564,7 +597,39 @@ func (h *Handler) exchangeGoogleCode(ctx context.Context, providerName, code str
 		projectID, _ = fetchAntigravityProjectID(ctx, tokenResp.AccessToken, httpClient)
 	}
 
-	return buildGoogleAuthRecord(providerName, tokenResp, email, projectID), nil
+	record := buildGoogleAuthRecord(providerName, tokenResp, email, projectID)
+	if providerName == "gemini" && tokenResp.AccessToken != "" {
+		if err := h.onboardGemini(ctx, state, record, tokenResp.AccessToken); err != nil {
+			return nil, fmt.Errorf("gemini onboarding failed: %w", err)
+		}
+	}
+	return record, nil
+}
+
+// onboardGemini onboards a new Gemini CLI login to Code Assist, so it is
+// usable right away, and records the project and tier in record. Each step
+// is reported as the flow's progress.
+func (h *Handler) onboardGemini(ctx context.Context, state string, record *provider.Auth, accessToken string) error {
+	var requested string
+	if oauthReq := oauthService.Registry().Get(state); oauthReq != nil {
+		requested = oauthReq.ProjectID
+	}
+	clientCtx := context.WithValue(ctx, oauth2.HTTPClient, h.getHTTPClient())
+	client := oauth2.NewClient(clientCtx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken}))
+	result, err := gemini.OnboardAuto(ctx, client, requested, func(step string) {
+		oauthService.Registry().SetProgress(state, step)
+	})
+	if err != nil {
+		return err
+	}
+
+	email, _ := record.Metadata["email"].(string)
+	record.Metadata["project_id"] = result.ProjectID
+	record.Metadata["auto"] = false
+	record.Metadata["tier"] = result.TierID
+	record.ID = gemini.CredentialFileName(email, result.ProjectID, true)
+	record.FileName = record.ID
+	return nil
 }
 
 func (h *Handler) exchangeClaudeCode(ctx context.Context, state, code string) (*provider.Auth, error) {
@@ -652,6 +717,55 @@ func buildAuthRecordWithEmail(providerName, email string, storage auth.TokenStor
 	}
 }
 
+// bindAuthRecord applies the label and tenant requested at login start.
+func bindAuthRecord(record *provider.Auth, label, tenant string) error {
+	if label == "" && tenant == "" {
+		return nil
+	}
+	if err := flattenTokenStorage(record); err != nil {
+		return err
+	}
+	if record.Metadata == nil {
+		record.Metadata = make(map[string]any)
+	}
+	if label != "" {
+		record.Label = label
+		record.Metadata["label"] = label
+	}
+	if tenant != "" {
+		record.Metadata["tenant"] = tenant
+	}
+	return nil
+}
+
+// flattenTokenStorage moves a record's token storage into its metadata. Token
+// storages serialize only their own fields, so fields added to metadata (label,
+// tenant, tags) would otherwise be dropped when the auth file is saved.
+func flattenTokenStorage(record *provider.Auth) error {
+	if record.Storage == nil {
+		return nil
+	}
+	raw, err := json.Marshal(record.Storage)
+	if err != nil {
+		return fmt.Errorf("marshal token storage: %w", err)
+	}
+	var metadata map[string]any
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return fmt.Errorf("unmarshal token storage: %w", err)
+	}
+	if t, _ := metadata["type"].(string); t == "" {
+		metadata["type"] = record.Provider
+	}
+	for k, v := range record.Metadata {
+		if _, exists := metadata[k]; !exists {
+			metadata[k] = v
+		}
+	}
+	record.Metadata = metadata
+	record.Storage = nil
+	return nil
+}
+
 // buildGoogleAuthRecord creates auth record for Google OAuth providers.
 func buildGoogleAuthRecord(providerType string, tokenResp *googleTokenResponse, email, projectID string) *provider.Auth {
 	now := time.Now()
@@ -0,0 +1,49 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+func TestPprof(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	h := &Handler{cfg: cfg}
+	r := gin.New()
+	r.GET("/v1/management/debug/pprof/*profile", h.Pprof)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/management/debug/pprof"+path, nil))
+		return w
+	}
+
+	for _, path := range []string{"/", "/heap", "/cmdline"} {
+		if w := get(path); w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "pprof.enable") {
+			t.Errorf("disabled %s: status = %d, body = %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	cfg.RemoteManagement.Pprof.Enable = true
+	for _, tt := range []struct {
+		path   string
+		status int
+		want   string
+	}{
+		{"/", http.StatusOK, "Types of profiles available"},
+		{"/cmdline", http.StatusOK, ""},
+		{"/symbol", http.StatusOK, "num_symbols"},
+		{"/goroutine?debug=1", http.StatusOK, "goroutine profile:"},
+		{"/heap?debug=1", http.StatusOK, "heap profile:"},
+		{"/no-such-profile", http.StatusNotFound, "Unknown profile"},
+	} {
+		w := get(tt.path)
+		if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%s: status = %d, body = %.200s", tt.path, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package management
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/runtime/executor"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	smokeTestDefaultPrompt = "Reply with the single word: pong"
+	smokeTestMaxTokens     = 16
+	smokeTestTimeout       = 60 * time.Second
+	smokeTestMaxText       = 512
+)
+
+// providerTestRequest is the optional body of POST /providers/:name/test.
+type providerTestRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+// providerTestResult describes one smoke test round trip.
+type providerTestResult struct {
+	Provider  string                      `json:"provider"`
+	Model     string                      `json:"model"`
+	OK        bool                        `json:"ok"`
+	LatencyMs int64                       `json:"latency_ms"`
+	Request   json.RawMessage             `json:"request"`
+	Upstream  []executor.UpstreamExchange `json:"upstream"`
+	Response  *providerTestResponse       `json:"response,omitempty"`
+	Error     string                      `json:"error,omitempty"`
+}
+
+// providerTestResponse summarizes the translated response returned to clients.
+type providerTestResponse struct {
+	Text         string          `json:"text"`
+	ToolCalls    json.RawMessage `json:"tool_calls,omitempty"`
+	FinishReason string          `json:"finish_reason,omitempty"`
+	Usage        json.RawMessage `json:"usage,omitempty"`
+}
+
+// TestProvider sends a minimal chat request through the production translation and
+// executor path for a single provider and reports latency, the translated upstream
+// payload and a summary of the response. Provider failures are reported in the
+// result body rather than as HTTP errors.
+func (h *Handler) TestProvider(c *gin.Context) {
+	name := strings.ToLower(strings.TrimSpace(c.Param("name")))
+	if h.authManager == nil {
+		respondInternalError(c, "auth manager unavailable")
+		return
+	}
+	if name == "" || !h.authManager.HasExecutor(name) {
+		respondNotFound(c, "provider not found: "+name)
+		return
+	}
+
+	var body providerTestRequest
+	if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		respondBadRequest(c, "invalid body")
+		return
+	}
+	model := strings.TrimSpace(body.Model)
+	if model == "" {
+		models := registry.GetGlobalRegistry().GetProviderModels(name)
+		if len(models) == 0 {
+			respondBadRequest(c, "no models registered for provider "+name+"; specify model")
+			return
+		}
+		model = models[0]
+	}
+	prompt := body.Prompt
+	if strings.TrimSpace(prompt) == "" {
+		prompt = smokeTestDefaultPrompt
+	}
+
+	payload := []byte(`{"model":"","stream":false,"max_tokens":0,"messages":[{"role":"user","content":""}]}`)
+	payload, _ = sjson.SetBytes(payload, "model", model)
+	payload, _ = sjson.SetBytes(payload, "max_tokens", smokeTestMaxTokens)
+	payload, _ = sjson.SetBytes(payload, "messages.0.content", prompt)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), smokeTestTimeout)
+	defer cancel()
+	ctx, capture := executor.WithUpstreamCapture(ctx)
+
+	start := time.Now()
+	resp, err := h.authManager.Execute(ctx, []string{name}, provider.Request{Model: model, Payload: payload}, provider.Options{
+		SourceFormat:    provider.FormatOpenAI,
+		OriginalRequest: payload,
+	})
+	result := providerTestResult{
+		Provider:  name,
+		Model:     model,
+		OK:        err == nil,
+		LatencyMs: time.Since(start).Milliseconds(),
+		Request:   payload,
+		Upstream:  capture.Exchanges(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Response = summarizeResponse(resp.Payload, smokeTestMaxText)
+	}
+	respondOK(c, result)
+}
+
+// RefreshProviderModels re-fetches the model lists of a provider that
+// publishes them (gemini, vertex, gemini-cli, aistudio, antigravity) from
+// upstream for every enabled auth, and returns the models added, removed
+// and with changed token limits.
+func (h *Handler) RefreshProviderModels(c *gin.Context) {
+	if h.modelRefresher == nil {
+		respondInternalError(c, "model refresh unavailable")
+		return
+	}
+	name := strings.ToLower(strings.TrimSpace(c.Param("name")))
+	changes, err := h.modelRefresher(c.Request.Context(), name)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	respondOK(c, gin.H{"provider": name, "changes": changes})
+}
+
+// summarizeResponse extracts the text, tool calls, finish reason and usage
+// of an OpenAI chat completion, keeping at most maxText bytes of text when
+// maxText is positive.
+func summarizeResponse(payload []byte, maxText int) *providerTestResponse {
+	root := gjson.ParseBytes(payload)
+	text := root.Get("choices.0.message.content").String()
+	if maxText > 0 && len(text) > maxText {
+		text = text[:maxText]
+	}
+	out := &providerTestResponse{
+		Text:         text,
+		FinishReason: root.Get("choices.0.finish_reason").String(),
+	}
+	if calls := root.Get("choices.0.message.tool_calls"); calls.IsArray() {
+		out.ToolCalls = json.RawMessage(calls.Raw)
+	}
+	if usage := root.Get("usage"); usage.Exists() && usage.Type != gjson.Null {
+		out.Usage = json.RawMessage(usage.Raw)
+	}
+	return out
+}
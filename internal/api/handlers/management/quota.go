@@ -1,6 +1,12 @@
 package management
 
-import "github.com/gin-gonic/gin"
+import (
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
 
 func (h *Handler) GetSwitchProject(c *gin.Context) {
 	respondOK(c, gin.H{"switch-project": h.cfg.QuotaExceeded.SwitchProject})
@@ -33,3 +39,44 @@ func (h *Handler) PutSwitchPreviewModel(c *gin.Context) {
 	}
 	respondOK(c, gin.H{"switch-preview-model": h.cfg.QuotaExceeded.SwitchPreviewModel})
 }
+
+// GetQuotaWindows reports each account's use of its rolling quota window
+// (Claude Pro/Max 5-hour windows by default) and the capacity estimated to
+// remain, lowest first.
+func (h *Handler) GetQuotaWindows(c *gin.Context) {
+	if h.authManager == nil {
+		respondInternalError(c, "auth manager not initialized")
+		return
+	}
+	qm := h.authManager.GetQuotaManager()
+	if qm == nil {
+		respondInternalError(c, "quota tracking is not enabled")
+		return
+	}
+	prov := c.DefaultQuery("provider", "claude")
+	now := time.Now()
+	accounts := make([]provider.QuotaWindowReport, 0)
+	for _, auth := range h.authManager.List() {
+		if auth.Provider != prov || auth.Disabled {
+			continue
+		}
+		accounts = append(accounts, qm.WindowReport(auth, now))
+	}
+	remaining := func(r provider.QuotaWindowReport) float64 {
+		if r.RemainingFraction == nil {
+			return 1
+		}
+		return *r.RemainingFraction
+	}
+	sort.SliceStable(accounts, func(i, j int) bool {
+		if ri, rj := remaining(accounts[i]), remaining(accounts[j]); ri != rj {
+			return ri < rj
+		}
+		return accounts[i].AuthID < accounts[j].AuthID
+	})
+	respondOK(c, gin.H{
+		"provider": prov,
+		"window":   provider.GetProviderQuotaConfig(prov).WindowDuration.String(),
+		"accounts": accounts,
+	})
+}
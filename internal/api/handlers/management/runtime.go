@@ -0,0 +1,95 @@
+package management
+
+import (
+	"net/http/pprof"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/middleware"
+	"github.com/nghyane/llm-mux/internal/runtime/canary"
+	"github.com/nghyane/llm-mux/internal/runtime/gctuning"
+	"github.com/nghyane/llm-mux/internal/runtime/overload"
+	"github.com/nghyane/llm-mux/internal/runtime/warmpool"
+	"github.com/nghyane/llm-mux/internal/transport"
+)
+
+// GetRuntimeStats returns Go memory statistics, GC settings and the goroutine count.
+func (h *Handler) GetRuntimeStats(c *gin.Context) {
+	respondOK(c, gctuning.ReadStats())
+}
+
+// GetOverload returns the overload shedding level, its inputs and how many
+// requests were shed per priority since startup.
+func (h *Handler) GetOverload(c *gin.Context) {
+	respondOK(c, overload.CurrentStatus())
+}
+
+// GetWarmPool returns, per Ollama provider with keep-warm models, the last
+// keep-alive ping of each model and the models the server reports loaded.
+func (h *Handler) GetWarmPool(c *gin.Context) {
+	respondOK(c, gin.H{"providers": warmpool.Status(c.Request.Context())})
+}
+
+// GetCanaries returns the latest run of each configured canary on each of
+// its providers. Failing ones mark their provider degraded for the model.
+func (h *Handler) GetCanaries(c *gin.Context) {
+	respondOK(c, gin.H{"canaries": canary.Status()})
+}
+
+// GetStreamRepairs returns, per provider, how many streamed chunks had invalid
+// UTF-8 or unpaired surrogates replaced since startup.
+func (h *Handler) GetStreamRepairs(c *gin.Context) {
+	respondOK(c, gin.H{"repairs": h.authManager.EncodingRepairs()})
+}
+
+// GetUpstreamOverloads returns, per provider, how many requests the upstream
+// rejected as overloaded (Anthropic 529) since startup.
+func (h *Handler) GetUpstreamOverloads(c *gin.Context) {
+	respondOK(c, gin.H{"overloads": h.authManager.UpstreamOverloads()})
+}
+
+// GetAbandonedStreams returns, per provider, how many upstream streams kept
+// running after their request was cancelled since startup.
+func (h *Handler) GetAbandonedStreams(c *gin.Context) {
+	respondOK(c, gin.H{"abandoned": h.authManager.AbandonedStreams()})
+}
+
+// GetEndpointAliasCallers returns who called deprecated endpoint aliases since
+// startup, per path and client, to tell when a path can be removed.
+func (h *Handler) GetEndpointAliasCallers(c *gin.Context) {
+	callers := []middleware.EndpointAliasCaller{}
+	if h.aliasCallers != nil {
+		callers = h.aliasCallers()
+	}
+	respondOK(c, gin.H{"callers": callers})
+}
+
+// GetTLSSessions returns, per dialer entry and upstream host, the TLS
+// handshakes since startup and how many resumed a cached session.
+func (h *Handler) GetTLSSessions(c *gin.Context) {
+	respondOK(c, gin.H{"sessions": transport.TLSSessions()})
+}
+
+// Pprof serves net/http/pprof under /debug/pprof when remote-management.pprof
+// is enabled. CPU profiles (profile?seconds=N) and execution traces
+// (trace?seconds=N) are collected on demand for the requested duration.
+func (h *Handler) Pprof(c *gin.Context) {
+	if cfg := h.getConfig(); cfg == nil || !cfg.RemoteManagement.Pprof.Enable {
+		respondNotFound(c, "pprof is disabled; set remote-management.pprof.enable")
+		return
+	}
+	switch name := strings.TrimPrefix(c.Param("profile"), "/"); name {
+	case "":
+		pprof.Index(c.Writer, c.Request)
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+	}
+}
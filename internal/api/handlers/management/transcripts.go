@@ -0,0 +1,144 @@
+package management
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/translator/export"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+// transcriptsResponse is the body of GET /transcripts.
+type transcriptsResponse struct {
+	Transcripts []usage.Transcript `json:"transcripts"`
+}
+
+// GetTranscripts lists stored transcripts, newest first. Filters: days,
+// from, to, api_key, model, label, q (substring of request or response) and
+// limit (default 50, at most 500).
+func (h *Handler) GetTranscripts(c *gin.Context) {
+	backend := h.transcriptBackend(c)
+	if backend == nil {
+		return
+	}
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		respondBadRequest(c, "limit "+err.Error())
+		return
+	}
+	q := usage.TranscriptQuery{
+		APIKey: strings.TrimSpace(c.Query("api_key")),
+		Model:  strings.TrimSpace(c.Query("model")),
+		Label:  strings.TrimSpace(c.Query("label")),
+		Search: c.Query("q"),
+		Limit:  limit,
+	}
+	q.From, q.To = h.parseTimeRange(c, h.transcriptRetentionDays())
+
+	transcripts, err := backend.QueryTranscripts(c.Request.Context(), q)
+	if err != nil {
+		respondInternalError(c, err.Error())
+		return
+	}
+	if transcripts == nil {
+		transcripts = []usage.Transcript{}
+	}
+	respondOK(c, transcriptsResponse{Transcripts: transcripts})
+}
+
+// GetTranscript returns one transcript by ID.
+func (h *Handler) GetTranscript(c *gin.Context) {
+	backend := h.transcriptBackend(c)
+	if backend == nil {
+		return
+	}
+	id := c.Param("id")
+	t, err := backend.GetTranscript(c.Request.Context(), id)
+	if err != nil {
+		respondInternalError(c, err.Error())
+		return
+	}
+	if t == nil {
+		respondNotFound(c, "transcript not found: "+id)
+		return
+	}
+	respondOK(c, t)
+}
+
+// conversationExportRequest is the body of POST /conversations/export.
+// It names a stored transcript or carries the conversation itself.
+type conversationExportRequest struct {
+	To           string          `json:"to"`
+	TranscriptID string          `json:"transcript_id"`
+	From         string          `json:"from"`
+	Request      json.RawMessage `json:"request"`
+	Response     json.RawMessage `json:"response"`
+}
+
+// conversationExportResponse is the body of POST /conversations/export.
+type conversationExportResponse struct {
+	Format       string          `json:"format"`
+	Conversation json.RawMessage `json:"conversation"`
+}
+
+// ExportConversation re-renders a transcript, or a client-supplied request
+// and optional transcript-style response, as a request in another format.
+func (h *Handler) ExportConversation(c *gin.Context) {
+	var body conversationExportRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondBadRequest(c, "invalid body")
+		return
+	}
+	if body.TranscriptID != "" {
+		backend := h.transcriptBackend(c)
+		if backend == nil {
+			return
+		}
+		t, err := backend.GetTranscript(c.Request.Context(), body.TranscriptID)
+		if err != nil {
+			respondInternalError(c, err.Error())
+			return
+		}
+		if t == nil {
+			respondNotFound(c, "transcript not found: "+body.TranscriptID)
+			return
+		}
+		body.From, body.Request, body.Response = t.Format, t.Request, t.Response
+	}
+	if len(body.Request) == 0 || body.From == "" {
+		respondBadRequest(c, "transcript_id or from and request are required")
+		return
+	}
+	out, err := export.Conversation(body.From, body.To, body.Request, body.Response)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	respondOK(c, conversationExportResponse{Format: body.To, Conversation: out})
+}
+
+// transcriptBackend returns the usage backend, or responds with an error
+// and returns nil when usage persistence is disabled.
+func (h *Handler) transcriptBackend(c *gin.Context) usage.Backend {
+	var backend usage.Backend
+	if h != nil && h.usagePlugin != nil {
+		backend = h.usagePlugin.GetBackend()
+	}
+	if backend == nil {
+		respondBadRequest(c, "usage persistence is not enabled")
+	}
+	return backend
+}
+
+// transcriptRetentionDays is the default lookback of GET /transcripts.
+func (h *Handler) transcriptRetentionDays() int {
+	cfg := h.getConfig()
+	switch {
+	case cfg != nil && cfg.Transcripts.RetentionDays > 0:
+		return cfg.Transcripts.RetentionDays
+	case cfg != nil && cfg.Usage.RetentionDays > 0:
+		return cfg.Usage.RetentionDays
+	}
+	return 30
+}
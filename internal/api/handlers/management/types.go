@@ -1,6 +1,10 @@
 package management
 
-import "time"
+import (
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/usage"
+)
 
 // UsageStatsResponse represents the structured usage statistics response.
 type UsageStatsResponse struct {
@@ -18,6 +22,8 @@ type UsageSummary struct {
 	SuccessCount  int64        `json:"success_count"`
 	FailureCount  int64        `json:"failure_count"`
 	Tokens        TokenSummary `json:"tokens"`
+	// EstimatedSavings is the USD saved by cost-based routing in the period.
+	EstimatedSavings float64 `json:"estimated_savings,omitempty"`
 }
 
 // TokenSummary holds token breakdown.
@@ -28,6 +34,83 @@ type TokenSummary struct {
 	Reasoning int64 `json:"reasoning,omitempty"`
 }
 
+// UsageDriftResponse is the reconciliation report of recorded versus provider-reported usage.
+type UsageDriftResponse struct {
+	From time.Time          `json:"from"`
+	Days []usage.UsageDrift `json:"days"`
+}
+
+// UsageQueryResponse is the result of GET /usage/query. Each row holds
+// "time", the group-by dimensions and the selected metrics as flat fields.
+type UsageQueryResponse struct {
+	From   time.Time        `json:"from"`
+	To     time.Time        `json:"to"`
+	Bucket string           `json:"bucket,omitempty"`
+	Rows   []map[string]any `json:"rows"`
+}
+
+// LatencySummary summarizes one latency histogram in milliseconds.
+type LatencySummary struct {
+	Count int64   `json:"count"`
+	AvgMs float64 `json:"avg_ms"`
+	P50Ms float64 `json:"p50_ms"`
+	P95Ms float64 `json:"p95_ms"`
+	P99Ms float64 `json:"p99_ms"`
+}
+
+// LatencyStats holds the latency and TTFT percentiles of a provider and model.
+type LatencyStats struct {
+	Provider string          `json:"provider"`
+	Model    string          `json:"model"`
+	Latency  *LatencySummary `json:"latency,omitempty"`
+	TTFT     *LatencySummary `json:"ttft,omitempty"`
+}
+
+// UsageLatencyResponse is the result of GET /usage/latency.
+type UsageLatencyResponse struct {
+	From   time.Time      `json:"from"`
+	To     time.Time      `json:"to"`
+	Models []LatencyStats `json:"models"`
+}
+
+// SizeSummary summarizes one payload size histogram in bytes.
+type SizeSummary struct {
+	Count    int64   `json:"count"`
+	AvgBytes float64 `json:"avg_bytes"`
+	P50Bytes float64 `json:"p50_bytes"`
+	P95Bytes float64 `json:"p95_bytes"`
+	P99Bytes float64 `json:"p99_bytes"`
+}
+
+// SizeStats holds the request and response size percentiles of a provider.
+type SizeStats struct {
+	Provider string       `json:"provider"`
+	Request  *SizeSummary `json:"request,omitempty"`
+	Response *SizeSummary `json:"response,omitempty"`
+}
+
+// UsageSizesResponse is the result of GET /usage/sizes.
+type UsageSizesResponse struct {
+	From      time.Time   `json:"from"`
+	To        time.Time   `json:"to"`
+	Providers []SizeStats `json:"providers"`
+	// Limits holds the current windowed request sizes of providers with a
+	// size limit, as evaluated for payload alerts.
+	Limits []usage.PayloadAlertStatus `json:"limits"`
+}
+
+// UsageBackupRequest is the body of POST /usage/backup.
+type UsageBackupRequest struct {
+	Path string `json:"path"`
+}
+
+// UsageBackupResponse reports a completed usage database backup.
+type UsageBackupResponse struct {
+	Path     string `json:"path"`
+	Bytes    int64  `json:"bytes"`
+	Duration string `json:"duration"`
+}
+
 // UsageProviderStats represents per-provider statistics.
 type UsageProviderStats struct {
 	Requests     int64        `json:"requests"`
@@ -1,11 +1,15 @@
 package management
 
 import (
+	"fmt"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/usage"
 )
 
 func (h *Handler) GetUsageStatistics(c *gin.Context) {
@@ -47,6 +51,12 @@ func (h *Handler) GetUsageStatistics(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
+	if globalStats, err := backend.QueryGlobalStats(ctx, from); err != nil {
+		log.Warnf("usage: failed to query global stats: %v", err)
+	} else {
+		response.Summary.EstimatedSavings = globalStats.EstimatedSavings
+	}
+
 	if providerStats, err := backend.QueryProviderStats(ctx, from); err != nil {
 		log.Warnf("usage: failed to query provider stats: %v", err)
 	} else if len(providerStats) > 0 {
@@ -160,6 +170,169 @@ func (h *Handler) GetUsageStatistics(c *gin.Context) {
 	respondOK(c, response)
 }
 
+// GetUsageDrift compares recorded usage with the totals reported by provider
+// usage APIs, per provider and UTC day. It defaults to the last 7 days.
+func (h *Handler) GetUsageDrift(c *gin.Context) {
+	var backend usage.Backend
+	if h != nil && h.usagePlugin != nil {
+		backend = h.usagePlugin.GetBackend()
+	}
+	if backend == nil {
+		respondOK(c, UsageDriftResponse{Days: []usage.UsageDrift{}})
+		return
+	}
+
+	from, _ := h.parseTimeRange(c, 7)
+	from = from.UTC().Truncate(24 * time.Hour)
+	ctx := c.Request.Context()
+
+	local, err := backend.QueryDailyProviderStats(ctx, from)
+	if err != nil {
+		respondInternalError(c, err.Error())
+		return
+	}
+	reported, err := backend.QueryProviderReports(ctx, from)
+	if err != nil {
+		respondInternalError(c, err.Error())
+		return
+	}
+	respondOK(c, UsageDriftResponse{From: from, Days: usage.BuildDriftReport(local, reported)})
+}
+
+// maxUsageQueryBuckets bounds the number of time buckets a usage query may return.
+const maxUsageQueryBuckets = 10000
+
+// GetUsageQuery aggregates usage over a custom time bucket with optional
+// filters, grouping and metric selection. It defaults to the last day.
+func (h *Handler) GetUsageQuery(c *gin.Context) {
+	var backend usage.Backend
+	if h != nil && h.usagePlugin != nil {
+		backend = h.usagePlugin.GetBackend()
+	}
+	if backend == nil {
+		respondBadRequest(c, "usage persistence is not enabled")
+		return
+	}
+
+	q := usage.UsageQuery{
+		GroupBy: queryList(c, "group_by"),
+		Metrics: queryList(c, "metrics"),
+		Filters: make(map[string][]string),
+	}
+	q.From, q.To = h.parseTimeRange(c, 1)
+	if len(q.Metrics) == 0 {
+		q.Metrics = []string{"requests", "total_tokens"}
+	}
+	for _, dim := range usage.UsageQueryDimensions {
+		if values := queryList(c, dim); len(values) > 0 {
+			q.Filters[dim] = values
+		}
+	}
+	if v := c.Query("failed"); v != "" {
+		failed, err := strconv.ParseBool(v)
+		if err != nil {
+			respondBadRequest(c, "failed must be true or false")
+			return
+		}
+		q.Failed = &failed
+	}
+	if v := c.Query("bucket"); v != "" {
+		bucket, err := parseBucket(v)
+		if err != nil || bucket < time.Minute {
+			respondBadRequest(c, "bucket must be a duration of at least 1m, such as 5m, 1h or 1d")
+			return
+		}
+		q.Bucket = bucket
+	}
+	if err := q.Validate(); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	if q.Bucket > 0 && q.To.Sub(q.From)/q.Bucket > maxUsageQueryBuckets {
+		respondBadRequest(c, "too many buckets, use a larger bucket or a shorter range")
+		return
+	}
+
+	rows, err := backend.QueryUsage(c.Request.Context(), q)
+	if err != nil {
+		respondInternalError(c, err.Error())
+		return
+	}
+	resp := UsageQueryResponse{From: q.From, To: q.To, Rows: make([]map[string]any, 0, len(rows))}
+	if q.Bucket > 0 {
+		resp.Bucket = c.Query("bucket")
+	}
+	for _, r := range rows {
+		out := make(map[string]any, 1+len(r.Group)+len(r.Values))
+		out["time"] = r.Time
+		for k, v := range r.Group {
+			out[k] = v
+		}
+		for k, v := range r.Values {
+			out[k] = v
+		}
+		resp.Rows = append(resp.Rows, out)
+	}
+	respondOK(c, resp)
+}
+
+// queryList returns the values of a repeated or comma-separated query parameter.
+func queryList(c *gin.Context, key string) []string {
+	var out []string
+	for _, raw := range c.QueryArray(key) {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+// parseBucket parses a Go duration, also accepting whole days such as "1d".
+func parseBucket(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid bucket %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// PostUsageBackup copies the SQLite usage database to the requested path
+// while it stays in use.
+func (h *Handler) PostUsageBackup(c *gin.Context) {
+	var backend usage.Backend
+	if h != nil && h.usagePlugin != nil {
+		backend = h.usagePlugin.GetBackend()
+	}
+	backuper, ok := backend.(usage.Backuper)
+	if !ok {
+		respondBadRequest(c, "usage backup requires a sqlite:// usage database")
+		return
+	}
+	var body UsageBackupRequest
+	if err := c.ShouldBindJSON(&body); err != nil || strings.TrimSpace(body.Path) == "" {
+		respondBadRequest(c, "path is required")
+		return
+	}
+	path := strings.TrimSpace(body.Path)
+
+	start := time.Now()
+	if err := backuper.Backup(c.Request.Context(), path); err != nil {
+		respondInternalError(c, err.Error())
+		return
+	}
+	resp := UsageBackupResponse{Path: path, Duration: time.Since(start).Round(time.Millisecond).String()}
+	if info, err := os.Stat(path); err == nil {
+		resp.Bytes = info.Size()
+	}
+	log.Infof("usage: database backed up to %s", path)
+	respondOK(c, resp)
+}
+
 func (h *Handler) parseTimeRange(c *gin.Context, retentionDays int) (from, to time.Time) {
 	to = time.Now()
 	from = to.AddDate(0, 0, -retentionDays)
@@ -0,0 +1,118 @@
+package management
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+// GetUsageLatency reports latency and TTFT percentiles per provider and model
+// from the persisted histograms. It defaults to the last day.
+func (h *Handler) GetUsageLatency(c *gin.Context) {
+	from, to := h.parseTimeRange(c, 1)
+	resp := UsageLatencyResponse{From: from, To: to, Models: []LatencyStats{}}
+	hists, ok := h.latencyHistograms(c, from, to)
+	if !ok {
+		return
+	}
+
+	index := make(map[[2]string]int)
+	for i := range hists {
+		hist := &hists[i]
+		key := [2]string{hist.Provider, hist.Model}
+		pos, seen := index[key]
+		if !seen {
+			pos = len(resp.Models)
+			index[key] = pos
+			resp.Models = append(resp.Models, LatencyStats{Provider: hist.Provider, Model: hist.Model})
+		}
+		summary := summarizeLatency(hist)
+		switch hist.Kind {
+		case usage.LatencyKindTotal:
+			resp.Models[pos].Latency = summary
+		case usage.LatencyKindTTFT:
+			resp.Models[pos].TTFT = summary
+		}
+	}
+	respondOK(c, resp)
+}
+
+// GetUsageLatencyMetrics exports the persisted histograms in the Prometheus
+// text format. Without a range it covers the whole retention period.
+func (h *Handler) GetUsageLatencyMetrics(c *gin.Context) {
+	from, to := time.Time{}, time.Now()
+	if c.Query("days") != "" || c.Query("from") != "" || c.Query("to") != "" {
+		from, to = h.parseTimeRange(c, 1)
+	}
+	hists, ok := h.latencyHistograms(c, from, to)
+	if !ok {
+		return
+	}
+
+	var sb strings.Builder
+	for _, kind := range []string{usage.LatencyKindTotal, usage.LatencyKindTTFT} {
+		name := "llm_mux_request_latency_seconds"
+		help := "Time from sending a provider request to the end of the response."
+		if kind == usage.LatencyKindTTFT {
+			name = "llm_mux_time_to_first_token_seconds"
+			help = "Time from sending a provider request to the first streamed chunk."
+		}
+		fmt.Fprintf(&sb, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+		for i := range hists {
+			hist := &hists[i]
+			if hist.Kind != kind {
+				continue
+			}
+			labels := fmt.Sprintf("provider=%s,model=%s", strconv.Quote(hist.Provider), strconv.Quote(hist.Model))
+			var cumulative int64
+			for b, count := range hist.Counts {
+				cumulative += count
+				le := "+Inf"
+				if b < len(usage.LatencyBoundsMs) {
+					le = strconv.FormatFloat(float64(usage.LatencyBoundsMs[b])/1000, 'f', -1, 64)
+				}
+				fmt.Fprintf(&sb, "%s_bucket{%s,le=%q} %d\n", name, labels, le, cumulative)
+			}
+			fmt.Fprintf(&sb, "%s_sum{%s} %s\n", name, labels, strconv.FormatFloat(float64(hist.SumMs)/1000, 'f', -1, 64))
+			fmt.Fprintf(&sb, "%s_count{%s} %d\n", name, labels, cumulative)
+		}
+	}
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(sb.String()))
+}
+
+// latencyHistograms loads histograms for [from, to), writing an error
+// response when it fails.
+func (h *Handler) latencyHistograms(c *gin.Context, from, to time.Time) ([]usage.LatencyHistogram, bool) {
+	var backend usage.Backend
+	if h != nil && h.usagePlugin != nil {
+		backend = h.usagePlugin.GetBackend()
+	}
+	if backend == nil {
+		return nil, true
+	}
+	hists, err := backend.QueryLatencyHistograms(c.Request.Context(), from, to)
+	if err != nil {
+		respondInternalError(c, err.Error())
+		return nil, false
+	}
+	return hists, true
+}
+
+func summarizeLatency(hist *usage.LatencyHistogram) *LatencySummary {
+	total := hist.Total()
+	if total == 0 {
+		return nil
+	}
+	return &LatencySummary{
+		Count: total,
+		AvgMs: float64(hist.SumMs) / float64(total),
+		P50Ms: hist.Quantile(0.50),
+		P95Ms: hist.Quantile(0.95),
+		P99Ms: hist.Quantile(0.99),
+	}
+}
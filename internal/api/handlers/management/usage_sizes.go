@@ -0,0 +1,69 @@
+package management
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+// GetUsageSizes reports translated request and response size percentiles
+// per provider from the persisted histograms, defaulting to the last day,
+// along with the windowed request sizes checked against provider limits.
+func (h *Handler) GetUsageSizes(c *gin.Context) {
+	from, to := h.parseTimeRange(c, 1)
+	resp := UsageSizesResponse{
+		From:      from,
+		To:        to,
+		Providers: []SizeStats{},
+		Limits:    usage.GetPayloadMonitor().Status(),
+	}
+	if resp.Limits == nil {
+		resp.Limits = []usage.PayloadAlertStatus{}
+	}
+
+	var backend usage.Backend
+	if h != nil && h.usagePlugin != nil {
+		backend = h.usagePlugin.GetBackend()
+	}
+	if backend == nil {
+		respondOK(c, resp)
+		return
+	}
+	hists, err := backend.QuerySizeHistograms(c.Request.Context(), from, to)
+	if err != nil {
+		respondInternalError(c, err.Error())
+		return
+	}
+
+	index := make(map[string]int)
+	for i := range hists {
+		hist := &hists[i]
+		pos, seen := index[hist.Provider]
+		if !seen {
+			pos = len(resp.Providers)
+			index[hist.Provider] = pos
+			resp.Providers = append(resp.Providers, SizeStats{Provider: hist.Provider})
+		}
+		summary := summarizeSize(hist)
+		switch hist.Kind {
+		case usage.SizeKindRequest:
+			resp.Providers[pos].Request = summary
+		case usage.SizeKindResponse:
+			resp.Providers[pos].Response = summary
+		}
+	}
+	respondOK(c, resp)
+}
+
+func summarizeSize(hist *usage.SizeHistogram) *SizeSummary {
+	total := hist.Total()
+	if total == 0 {
+		return nil
+	}
+	return &SizeSummary{
+		Count:    total,
+		AvgBytes: float64(hist.SumBytes) / float64(total),
+		P50Bytes: hist.Quantile(0.50),
+		P95Bytes: hist.Quantile(0.95),
+		P99Bytes: hist.Quantile(0.99),
+	}
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// configureHTTP2 applies HTTP/2 protocol selection and tunables to srv.
+// HTTP/2 is negotiated over TLS unless disabled; h2c adds cleartext HTTP/2
+// with prior knowledge so internal load balancers can multiplex SSE streams.
+func configureHTTP2(srv *http.Server, cfg config.HTTP2Config) {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(!cfg.Disable)
+	protocols.SetUnencryptedHTTP2(cfg.H2C && !cfg.Disable)
+	srv.Protocols = protocols
+
+	h2 := &http.HTTP2Config{
+		MaxConcurrentStreams:          cfg.MaxConcurrentStreams,
+		MaxReadFrameSize:              cfg.MaxReadFrameSize,
+		MaxReceiveBufferPerStream:     cfg.MaxReceiveBufferPerStream,
+		MaxReceiveBufferPerConnection: cfg.MaxReceiveBufferPerConnection,
+	}
+	if raw := strings.TrimSpace(cfg.PingTimeout); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			h2.PingTimeout = d
+			h2.SendPingTimeout = d
+		} else {
+			log.Warnf("ignoring invalid http2.ping-timeout %q", cfg.PingTimeout)
+		}
+	}
+	srv.HTTP2 = h2
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+func TestConfigureHTTP2ServesH2C(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	configureHTTP2(srv.Config, config.HTTP2Config{H2C: true, MaxConcurrentStreams: 500})
+	srv.Start()
+	defer srv.Close()
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.ProtoMajor != 2 {
+		t.Fatalf("expected HTTP/2 over cleartext, got %s", resp.Proto)
+	}
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// sdListenFdsStart is the first file descriptor passed by systemd socket activation.
+const sdListenFdsStart = 3
+
+const defaultUnixSocketMode = 0o660
+
+// listen returns the listener the API server should serve on, in order of preference:
+// a systemd-activated socket, the configured unix socket, then the TCP address.
+func (s *Server) listen() (net.Listener, error) {
+	trustLocal := s.cfg != nil && s.cfg.UnixSocketTrustLocal
+	if ln, err := systemdListener(); ln != nil || err != nil {
+		return unixPeers(ln, trustLocal), err
+	}
+	if s.cfg != nil {
+		if path := strings.TrimSpace(s.cfg.UnixSocket); path != "" {
+			ln, err := listenUnix(path, s.cfg.UnixSocketMode)
+			return unixPeers(ln, trustLocal), err
+		}
+	}
+	return net.Listen("tcp", s.server.Addr)
+}
+
+var (
+	// unixLoopbackAddr is reported for unix socket peers with
+	// unix-socket-trust-local, so the localhost checks in auth and
+	// management accept them.
+	unixLoopbackAddr = &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}
+	// unixPeerAddr is reported for other unix socket peers. It is not
+	// loopback, as a reverse proxy on the socket may forward any client.
+	unixPeerAddr = &net.TCPAddr{IP: net.IPv4zero}
+)
+
+type unixListener struct {
+	net.Listener
+	peer net.Addr
+}
+
+type unixConn struct {
+	net.Conn
+	peer net.Addr
+}
+
+func (l unixListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return unixConn{c, l.peer}, nil
+}
+
+func (c unixConn) RemoteAddr() net.Addr { return c.peer }
+
+// unixPeers wraps unix listeners so their peers report an IP address:
+// loopback when trustLocal is set, otherwise the unspecified address.
+func unixPeers(ln net.Listener, trustLocal bool) net.Listener {
+	if ln == nil || ln.Addr().Network() != "unix" {
+		return ln
+	}
+	if trustLocal {
+		return unixListener{ln, unixLoopbackAddr}
+	}
+	return unixListener{ln, unixPeerAddr}
+}
+
+// systemdListener returns the first socket passed via LISTEN_FDS, or nil when the
+// process was not socket-activated. The activation variables are cleared so child
+// processes do not inherit them.
+func systemdListener() (net.Listener, error) {
+	pid, errPID := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	fds, errFds := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if errPID != nil || errFds != nil || pid != os.Getpid() || fds < 1 {
+		return nil, nil
+	}
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+	if fds > 1 {
+		log.Warnf("systemd passed %d sockets, only the first is used", fds)
+	}
+
+	f := os.NewFile(uintptr(sdListenFdsStart), "systemd-socket")
+	ln, err := net.FileListener(f)
+	_ = f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("systemd socket activation: %w", err)
+	}
+	log.Infof("using systemd-activated socket %s", ln.Addr())
+	return ln, nil
+}
+
+// listenUnix listens on a unix socket, replacing a stale socket file left by a
+// previous run and applying mode (octal string, default 0660).
+func listenUnix(path, mode string) (net.Listener, error) {
+	perm := fs.FileMode(defaultUnixSocketMode)
+	if m := strings.TrimSpace(mode); m != "" {
+		parsed, err := strconv.ParseUint(m, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unix-socket-mode %q: %w", mode, err)
+		}
+		perm = fs.FileMode(parsed)
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("unix-socket %s exists and is not a socket", path)
+		}
+		if conn, errDial := net.Dial("unix", path); errDial == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("unix-socket %s is already in use", path)
+		}
+		if errRemove := os.Remove(path); errRemove != nil {
+			return nil, fmt.Errorf("remove stale unix socket: %w", errRemove)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("stat unix socket: %w", err)
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(path, perm); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("chmod unix socket: %w", err)
+	}
+	return ln, nil
+}
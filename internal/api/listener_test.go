@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListenUnixReplacesStaleSocketAndAppliesMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "llm-mux.sock")
+
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	// Leave the socket file behind as a crashed process would.
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	ln, err := listenUnix(path, "0600")
+	if err != nil {
+		t.Fatalf("listenUnix: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("socket mode = %o, want 600", perm)
+	}
+
+	if _, err = listenUnix(path, ""); err == nil {
+		t.Fatal("expected error when socket is in use")
+	}
+
+	for _, tt := range []struct {
+		trustLocal bool
+		want       string
+	}{
+		{false, "0.0.0.0:0"},
+		{true, "127.0.0.1:0"},
+	} {
+		wrapped := unixPeers(ln, tt.trustLocal)
+		go func() {
+			if c, errDial := net.Dial("unix", path); errDial == nil {
+				_ = c.Close()
+			}
+		}()
+		conn, err := wrapped.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = conn.Close()
+		if got := conn.RemoteAddr().String(); got != tt.want {
+			t.Errorf("trustLocal=%v: RemoteAddr = %s, want %s", tt.trustLocal, got, tt.want)
+		}
+	}
+}
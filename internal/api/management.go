@@ -22,10 +22,33 @@ func (s *Server) registerManagementRoutes() {
 	mgmt.Use(s.managementAvailabilityMiddleware(), s.mgmt.Middleware())
 	{
 		mgmt.GET("/usage", s.mgmt.GetUsageStatistics)
+		mgmt.GET("/usage/drift", s.mgmt.GetUsageDrift)
+		mgmt.GET("/usage/query", s.mgmt.GetUsageQuery)
+		mgmt.GET("/usage/latency", s.mgmt.GetUsageLatency)
+		mgmt.GET("/usage/latency/metrics", s.mgmt.GetUsageLatencyMetrics)
+		mgmt.GET("/usage/sizes", s.mgmt.GetUsageSizes)
+		mgmt.POST("/usage/backup", s.mgmt.PostUsageBackup)
+		mgmt.GET("/transcripts", s.mgmt.GetTranscripts)
+		mgmt.GET("/transcripts/:id", s.mgmt.GetTranscript)
+		mgmt.POST("/conversations/export", s.mgmt.ExportConversation)
 		mgmt.GET("/config", s.mgmt.GetConfig)
 		mgmt.GET("/config.yaml", s.mgmt.GetConfigYAML)
+		mgmt.GET("/config/schema", s.mgmt.GetConfigSchema)
 		mgmt.PUT("/config.yaml", s.mgmt.PutConfigYAML)
+		mgmt.GET("/config/reload-status", s.mgmt.GetConfigReloadStatus)
 		mgmt.GET("/latest-version", s.mgmt.GetLatestVersion)
+		mgmt.GET("/runtime", s.mgmt.GetRuntimeStats)
+		mgmt.GET("/runtime/overload", s.mgmt.GetOverload)
+		mgmt.GET("/warm-pool", s.mgmt.GetWarmPool)
+		mgmt.GET("/canaries", s.mgmt.GetCanaries)
+		mgmt.GET("/stream-repairs", s.mgmt.GetStreamRepairs)
+		mgmt.GET("/upstream-overloads", s.mgmt.GetUpstreamOverloads)
+		mgmt.GET("/abandoned-streams", s.mgmt.GetAbandonedStreams)
+		mgmt.GET("/endpoint-aliases/callers", s.mgmt.GetEndpointAliasCallers)
+		mgmt.GET("/tls-sessions", s.mgmt.GetTLSSessions)
+		mgmt.GET("/conformance", s.mgmt.GetConformance)
+		mgmt.GET("/debug/pprof/*profile", s.mgmt.Pprof)
+		mgmt.POST("/debug/pprof/*profile", s.mgmt.Pprof)
 
 		mgmt.GET("/debug", s.mgmt.GetDebug)
 		mgmt.PUT("/debug", s.mgmt.PutDebug)
@@ -45,6 +68,7 @@ func (s *Server) registerManagementRoutes() {
 
 		mgmt.GET("/quota-exceeded/switch-preview-model", s.mgmt.GetSwitchPreviewModel)
 		mgmt.PUT("/quota-exceeded/switch-preview-model", s.mgmt.PutSwitchPreviewModel)
+		mgmt.GET("/quota/windows", s.mgmt.GetQuotaWindows)
 
 		mgmt.GET("/api-keys", s.mgmt.GetAPIKeys)
 		mgmt.PUT("/api-keys", s.mgmt.PutAPIKeys)
@@ -54,13 +78,21 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/providers", s.mgmt.GetProviders)
 		mgmt.PUT("/providers", s.mgmt.PutProviders)
 		mgmt.DELETE("/providers", s.mgmt.DeleteProvider)
+		mgmt.POST("/providers/:name/test", s.mgmt.TestProvider)
+		mgmt.POST("/providers/:name/models/refresh", s.mgmt.RefreshProviderModels)
+		mgmt.POST("/compare", s.mgmt.Compare)
 
 		mgmt.GET("/logs", s.mgmt.GetLogs)
+		mgmt.GET("/logs/recent", s.mgmt.GetRecentLogs)
+		mgmt.GET("/log-level", s.mgmt.GetLogLevel)
+		mgmt.PUT("/log-level", s.mgmt.PutLogLevel)
 		mgmt.DELETE("/logs", s.mgmt.DeleteLogs)
 		mgmt.GET("/request-error-logs", s.mgmt.GetRequestErrorLogs)
 		mgmt.GET("/request-error-logs/:name", s.mgmt.DownloadRequestErrorLog)
 		mgmt.GET("/request-log", s.mgmt.GetRequestLog)
 		mgmt.PUT("/request-log", s.mgmt.PutRequestLog)
+		mgmt.GET("/access-log", s.mgmt.GetAccessLog)
+		mgmt.PUT("/access-log", s.mgmt.PutAccessLog)
 		mgmt.GET("/ws-auth", s.mgmt.GetWebsocketAuth)
 		mgmt.PUT("/ws-auth", s.mgmt.PutWebsocketAuth)
 
@@ -78,7 +110,9 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/auth-files/download", s.mgmt.DownloadAuthFile)
 		mgmt.POST("/auth-files", s.mgmt.UploadAuthFile)
 		mgmt.DELETE("/auth-files", s.mgmt.DeleteAuthFile)
+		mgmt.PATCH("/auth-files", s.mgmt.PatchAuthFile)
 		mgmt.POST("/vertex/import", s.mgmt.ImportVertexCredential)
+		mgmt.POST("/cli/import", s.mgmt.ImportCLICredentials)
 
 		// Unified OAuth API endpoints
 		mgmt.POST("/oauth/start", s.mgmt.OAuthStart)
@@ -3,37 +3,46 @@ package api
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/tidwall/gjson"
 )
 
-// corsMiddleware returns a Gin middleware handler that adds CORS headers
-// to every response, allowing cross-origin requests.
-// Returns:
-//   - gin.HandlerFunc: The CORS middleware handler
-func corsMiddleware() gin.HandlerFunc {
+// managementAvailabilityMiddleware returns middleware that checks if management routes are enabled.
+// If management routes are disabled, it returns a 404 status.
+func (s *Server) managementAvailabilityMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "*")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusNoContent)
+		if !s.managementRoutesEnabled.Load() {
+			c.AbortWithStatus(http.StatusNotFound)
 			return
 		}
-
 		c.Next()
 	}
 }
 
-// managementAvailabilityMiddleware returns middleware that checks if management routes are enabled.
-// If management routes are disabled, it returns a 404 status.
-func (s *Server) managementAvailabilityMiddleware() gin.HandlerFunc {
+// explainMiddleware returns middleware that answers requests in explain mode
+// with a routing and translation trace instead of executing them. The body is
+// in handlerType's format; Gemini routes carry the model in the action path.
+func (s *Server) explainMiddleware(handlerType string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if !s.managementRoutesEnabled.Load() {
-			c.AbortWithStatus(http.StatusNotFound)
+		if !format.ExplainRequested(c) {
+			c.Next()
 			return
 		}
-		c.Next()
+		rawJSON, err := c.GetRawData()
+		if err != nil {
+			s.handlers.WriteErrorResponse(c, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: err})
+			c.Abort()
+			return
+		}
+		modelName := gjson.GetBytes(rawJSON, "model").String()
+		if action := c.Param("action"); action != "" {
+			modelName, _, _ = strings.Cut(action, ":")
+		}
+		s.handlers.Explain(c, handlerType, modelName, rawJSON, s.cfg)
+		c.Abort()
 	}
 }
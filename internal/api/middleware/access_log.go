@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// AccessLogMiddleware writes a structured access log entry for a sample of
+// client requests. Sampled requests carry provider.RequestTimings in their
+// context so the proxy can record queueing and upstream phases; streaming
+// responses are logged once the stream has finished.
+func AccessLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/v1/management") || !logging.AccessLog().Sample() {
+			c.Next()
+			return
+		}
+		timings := provider.NewRequestTimings(time.Now())
+		c.Request = c.Request.WithContext(provider.WithRequestTimings(c.Request.Context(), timings))
+
+		c.Next()
+
+		logging.AccessLog().Write(accessLogEntry(c, timings.Snapshot(), time.Now()))
+	}
+}
+
+// accessLogEntry splits the request into its phases: queue is the wait for a
+// concurrency slot, translate the remaining time before the first upstream
+// request, upstream TTFB the wait for response headers of the last attempt
+// and stream the time from then until the response was complete.
+func accessLogEntry(c *gin.Context, t provider.RequestTimingsSnapshot, end time.Time) logging.AccessLogEntry {
+	entry := logging.AccessLogEntry{
+		Time:     t.Start,
+		Method:   c.Request.Method,
+		Path:     c.Request.URL.Path,
+		Status:   c.Writer.Status(),
+		Stream:   strings.HasPrefix(c.Writer.Header().Get("Content-Type"), "text/event-stream"),
+		Bytes:    int64(max(c.Writer.Size(), 0)),
+		Provider: t.Provider,
+		Model:    t.Model,
+		AuthID:   t.AuthID,
+		Attempts: t.Attempts,
+		QueueMs:  t.Queue.Milliseconds(),
+		TotalMs:  end.Sub(t.Start).Milliseconds(),
+	}
+	if !t.Sent.IsZero() {
+		entry.TranslateMs = max(t.Sent.Sub(t.Start)-t.Queue, 0).Milliseconds()
+	}
+	if !t.FirstByte.IsZero() {
+		entry.TTFBMs = t.FirstByte.Sub(t.LastSent).Milliseconds()
+		entry.StreamMs = end.Sub(t.FirstByte).Milliseconds()
+	}
+	if errs := c.Errors.ByType(gin.ErrorTypeAny); len(errs) > 0 {
+		entry.Error = errs.Last().Error()
+	}
+	return entry
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestAccessLogMiddlewareRecordsTimings(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "access.jsonl")
+	if err := logging.AccessLog().Configure(logging.AccessLogSettings{Enabled: true, File: file}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = logging.AccessLog().Configure(logging.AccessLogSettings{}) })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(AccessLogMiddleware())
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		timings := provider.RequestTimingsFrom(c.Request.Context())
+		timings.SetTarget("openai", "gpt-4o", "auth-1")
+		timings.MarkSent()
+		time.Sleep(5 * time.Millisecond)
+		timings.MarkFirstByte()
+		c.Header("Content-Type", "text/event-stream")
+		c.String(http.StatusOK, "data: {}\n\n")
+	})
+	r.GET("/v1/management/config", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil),
+		httptest.NewRequest(http.MethodGet, "/v1/management/config", nil),
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d entries, want 1 (management requests are skipped): %s", len(lines), data)
+	}
+	var entry logging.AccessLogEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Status != http.StatusOK || !entry.Stream || entry.Provider != "openai" || entry.Model != "gpt-4o" || entry.Attempts != 1 {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if entry.TTFBMs < 5 || entry.TotalMs < entry.TTFBMs {
+		t.Errorf("timings = ttfb %dms, total %dms", entry.TTFBMs, entry.TotalMs)
+	}
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+const defaultCORSMaxAge = 10 * time.Minute
+
+// DefaultCORSExposedHeaders are the response headers browser scripts may
+// read unless cors.exposed-headers is set.
+var DefaultCORSExposedHeaders = []string{
+	"Retry-After",
+	"X-Request-ID",
+	"Idempotent-Replayed",
+	"X-LLMMUX-Transcript-Id",
+	"X-LLMMUX-Thinking-Budget",
+	"X-LLMMUX-Model-Substitution",
+	"X-LLMMUX-Ignored-Params",
+	"X-LLMMUX-Model-Snapshot",
+}
+
+const corsAllowedMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+
+// CORSMiddleware adds CORS headers for allowed origins and answers
+// preflight requests. Headers are set before the handler runs, so streamed
+// responses carry them too.
+//
+// Parameters:
+//   - getConfig: Function returning the current CORS settings (hot-reload aware)
+func CORSMiddleware(getConfig func() config.CORSConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := getConfig()
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions
+		if cfg.Disable {
+			if preflight {
+				c.AbortWithStatus(http.StatusNoContent)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		allowed, anyOrigin := matchOrigin(cfg.AllowedOrigins, origin)
+		switch {
+		case anyOrigin:
+			// Credentials are never allowed for "*": echoing any origin
+			// would let every website read credentialed responses.
+			h.Set("Access-Control-Allow-Origin", "*")
+		case allowed && origin != "":
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Add("Vary", "Origin")
+			if cfg.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+		case preflight && origin != "":
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		if !preflight {
+			if allowed {
+				exposed := cfg.ExposedHeaders
+				if len(exposed) == 0 {
+					exposed = DefaultCORSExposedHeaders
+				}
+				h.Set("Access-Control-Expose-Headers", strings.Join(exposed, ", "))
+			}
+			c.Next()
+			return
+		}
+
+		h.Set("Access-Control-Allow-Methods", corsAllowedMethods)
+		switch requested := c.GetHeader("Access-Control-Request-Headers"); {
+		case len(cfg.AllowedHeaders) > 0:
+			h.Set("Access-Control-Allow-Headers", strings.Join(cfg.AllowedHeaders, ", "))
+		case anyOrigin:
+			// "*" does not cover Authorization, so arbitrary pages cannot
+			// send API keys.
+			h.Set("Access-Control-Allow-Headers", "*")
+		case requested != "":
+			h.Set("Access-Control-Allow-Headers", requested)
+			h.Add("Vary", "Access-Control-Request-Headers")
+		}
+		if allowed && !anyOrigin && c.GetHeader("Access-Control-Request-Private-Network") == "true" {
+			// Lets pages on listed public origins reach a local llm-mux.
+			h.Set("Access-Control-Allow-Private-Network", "true")
+		}
+		maxAge := defaultCORSMaxAge
+		if d, err := time.ParseDuration(strings.TrimSpace(cfg.MaxAge)); err == nil && d >= 0 {
+			maxAge = d
+		}
+		h.Set("Access-Control-Max-Age", strconv.Itoa(int(maxAge.Seconds())))
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// matchOrigin reports whether origin is allowed and whether every origin is.
+// Patterns may be "*" or contain one "*" for a subdomain, such as
+// "https://*.example.com".
+func matchOrigin(patterns []string, origin string) (allowed, anyOrigin bool) {
+	if len(patterns) == 0 || slices.Contains(patterns, "*") {
+		return true, true
+	}
+	for _, p := range patterns {
+		if strings.EqualFold(p, origin) {
+			return true, false
+		}
+		if prefix, suffix, ok := strings.Cut(p, "*"); ok && len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true, false
+		}
+	}
+	return false, false
+}
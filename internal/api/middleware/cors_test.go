@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+func serveCORS(cfg config.CORSConfig, req *http.Request) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CORSMiddleware(func() config.CORSConfig { return cfg }))
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.String(http.StatusOK, "data: [DONE]\n\n")
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	cfg := config.CORSConfig{AllowedOrigins: []string{"https://*.example.com"}, AllowCredentials: true, MaxAge: "1h"}
+
+	req := httptest.NewRequest(http.MethodOptions, "/v1/chat/completions", nil)
+	req.Header.Set("Origin", "https://chat.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "authorization, content-type, anthropic-version")
+	w := serveCORS(cfg, req)
+	h := w.Header()
+	if w.Code != http.StatusNoContent || h.Get("Access-Control-Allow-Origin") != "https://chat.example.com" ||
+		h.Get("Access-Control-Allow-Credentials") != "true" || h.Get("Access-Control-Max-Age") != "3600" ||
+		h.Get("Access-Control-Allow-Headers") != "authorization, content-type, anthropic-version" {
+		t.Fatalf("preflight: %d %v", w.Code, h)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("Origin", "https://chat.example.com")
+	w = serveCORS(cfg, req)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://chat.example.com" || w.Header().Get("Access-Control-Expose-Headers") == "" {
+		t.Fatalf("stream headers: %v", w.Header())
+	}
+
+	req = httptest.NewRequest(http.MethodOptions, "/v1/chat/completions", nil)
+	req.Header.Set("Origin", "https://evil.test")
+	if w = serveCORS(cfg, req); w.Code != http.StatusForbidden || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("disallowed preflight: %d %v", w.Code, w.Header())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("Origin", "https://any.test")
+	if got := serveCORS(config.CORSConfig{}, req).Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("default origin = %q", got)
+	}
+}
+
+func TestCORSMiddleware_CredentialsWithAnyOrigin(t *testing.T) {
+	for _, origins := range [][]string{nil, {"*"}, {"https://chat.example.com", "*"}} {
+		cfg := config.CORSConfig{AllowedOrigins: origins, AllowCredentials: true}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%q: allow-credentials accepted", origins)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		req.Header.Set("Origin", "https://evil.test")
+		h := serveCORS(cfg, req).Header()
+		if h.Get("Access-Control-Allow-Origin") != "*" || h.Get("Access-Control-Allow-Credentials") != "" {
+			t.Errorf("%q: credentialed origin echoed: %v", origins, h)
+		}
+	}
+	if err := (config.CORSConfig{AllowedOrigins: []string{"https://*.example.com"}, AllowCredentials: true}).Validate(); err != nil {
+		t.Errorf("listed origins rejected: %v", err)
+	}
+}
+
+func TestCORSMiddleware_PrivateNetwork(t *testing.T) {
+	preflight := func(origin string) *http.Request {
+		req := httptest.NewRequest(http.MethodOptions, "/v1/chat/completions", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "authorization")
+		req.Header.Set("Access-Control-Request-Private-Network", "true")
+		return req
+	}
+
+	h := serveCORS(config.CORSConfig{}, preflight("https://any.test")).Header()
+	if h.Get("Access-Control-Allow-Private-Network") != "" || h.Get("Access-Control-Allow-Headers") != "*" {
+		t.Fatalf("default preflight: %v", h)
+	}
+
+	cfg := config.CORSConfig{AllowedOrigins: []string{"https://chat.example.com"}}
+	h = serveCORS(cfg, preflight("https://chat.example.com")).Header()
+	if h.Get("Access-Control-Allow-Private-Network") != "true" || h.Get("Access-Control-Allow-Headers") != "authorization" {
+		t.Fatalf("listed origin preflight: %v", h)
+	}
+}
@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"cmp"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/util"
+)
+
+// maxEndpointAliasCallers bounds the callers tracked per instance; callers
+// seen after that are still served but not counted.
+const maxEndpointAliasCallers = 10000
+
+// EndpointAliases serves the configured endpoint aliases. It wraps the
+// router instead of being a gin middleware, as gin picks the route before
+// any middleware runs.
+type EndpointAliases struct {
+	getConfig func() []config.EndpointAlias
+
+	mu      sync.Mutex
+	callers map[endpointAliasCallerKey]*EndpointAliasCaller
+}
+
+type endpointAliasCallerKey struct {
+	path, client, userAgent string
+}
+
+// EndpointAliasCaller counts the requests one client sent to a deprecated
+// path since startup.
+type EndpointAliasCaller struct {
+	Path      string    `json:"path"`
+	Target    string    `json:"target"`
+	Client    string    `json:"client"` // masked API key, or the address of unauthenticated clients
+	UserAgent string    `json:"user_agent,omitempty"`
+	Requests  int64     `json:"requests"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// NewEndpointAliases returns the aliases handler.
+//
+// Parameters:
+//   - getConfig: Function returning the current aliases (hot-reload aware)
+func NewEndpointAliases(getConfig func() []config.EndpointAlias) *EndpointAliases {
+	return &EndpointAliases{getConfig: getConfig, callers: make(map[endpointAliasCallerKey]*EndpointAliasCaller)}
+}
+
+// Handler serves requests for alias paths from their target, either in
+// place or by redirecting the client, and passes other requests to next
+// unchanged.
+func (a *EndpointAliases) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		alias, target, ok := matchEndpointAlias(a.getConfig(), r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if alias.Deprecated {
+			a.record(alias, r)
+			setDeprecationHeaders(w.Header(), alias)
+		}
+
+		u := *r.URL
+		u.Path, u.RawPath = target, ""
+		if alias.Redirect {
+			http.Redirect(w, r, u.String(), http.StatusPermanentRedirect)
+			return
+		}
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = &u
+		r2.RequestURI = u.RequestURI()
+		next.ServeHTTP(w, r2)
+	})
+}
+
+// matchEndpointAlias returns the alias of path and the path it is served
+// from. An exact path wins over wildcards, and a longer wildcard over a
+// shorter one.
+func matchEndpointAlias(aliases []config.EndpointAlias, path string) (config.EndpointAlias, string, bool) {
+	var (
+		best   config.EndpointAlias
+		target string
+		found  bool
+	)
+	for _, a := range aliases {
+		prefix, wildcard := strings.CutSuffix(a.Path, "*")
+		switch {
+		case !wildcard && path == a.Path:
+			return a, a.Target, true
+		case wildcard && strings.HasPrefix(path, prefix) && (!found || len(a.Path) > len(best.Path)):
+			best, target, found = a, strings.TrimSuffix(a.Target, "*")+strings.TrimPrefix(path, prefix), true
+		}
+	}
+	return best, target, found
+}
+
+func setDeprecationHeaders(h http.Header, alias config.EndpointAlias) {
+	h.Set("Deprecation", "true")
+	if t, err := time.Parse(time.DateOnly, alias.Sunset); err == nil {
+		h.Set("Sunset", t.UTC().Format(http.TimeFormat))
+	}
+	if alias.Link != "" {
+		h.Add("Link", "<"+alias.Link+`>; rel="deprecation"`)
+	}
+}
+
+// record counts a request to a deprecated alias, logging each caller the
+// first time it is seen.
+func (a *EndpointAliases) record(alias config.EndpointAlias, r *http.Request) {
+	key := endpointAliasCallerKey{path: alias.Path, client: endpointAliasClient(r), userAgent: r.UserAgent()}
+	now := time.Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if c := a.callers[key]; c != nil {
+		c.Requests++
+		c.LastSeen = now
+		return
+	}
+	if len(a.callers) >= maxEndpointAliasCallers {
+		return
+	}
+	a.callers[key] = &EndpointAliasCaller{
+		Path:      alias.Path,
+		Target:    alias.Target,
+		Client:    key.client,
+		UserAgent: key.userAgent,
+		Requests:  1,
+		FirstSeen: now,
+		LastSeen:  now,
+	}
+	logging.Warnf("deprecated endpoint %s called by %s (%s); clients should move to %s", r.URL.Path, key.client, key.userAgent, alias.Target)
+}
+
+// endpointAliasClient identifies the caller by its masked API key, or by its
+// address when it sent none.
+func endpointAliasClient(r *http.Request) string {
+	key := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	for _, v := range []string{r.Header.Get("X-Api-Key"), r.Header.Get("X-Goog-Api-Key"), r.URL.Query().Get("key")} {
+		if key == "" {
+			key = v
+		}
+	}
+	if key != "" {
+		return util.HideAPIKey(key)
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// Callers returns the callers of deprecated aliases, busiest first per path.
+func (a *EndpointAliases) Callers() []EndpointAliasCaller {
+	a.mu.Lock()
+	out := make([]EndpointAliasCaller, 0, len(a.callers))
+	for _, c := range a.callers {
+		out = append(out, *c)
+	}
+	a.mu.Unlock()
+	slices.SortFunc(out, func(x, y EndpointAliasCaller) int {
+		return cmp.Or(strings.Compare(x.Path, y.Path), cmp.Compare(y.Requests, x.Requests), strings.Compare(x.Client, y.Client))
+	})
+	return out
+}
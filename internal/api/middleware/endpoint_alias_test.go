@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+func TestEndpointAliases(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/v1/chat/completions", func(c *gin.Context) { c.String(http.StatusOK, "chat") })
+	r.GET("/v1/models/:id", func(c *gin.Context) { c.String(http.StatusOK, "model "+c.Param("id")+" "+c.Query("alt")) })
+	r.POST("/api/generate", func(c *gin.Context) { c.String(http.StatusOK, "generate") })
+
+	aliases := NewEndpointAliases(func() []config.EndpointAlias {
+		return []config.EndpointAlias{
+			{Path: "/team-a/chat", Target: "/v1/chat/completions"},
+			{Path: "/api/generate", Target: "/v1/chat/completions", Deprecated: true, Sunset: "2027-01-31", Link: "https://docs.example.com/migrate"},
+			{Path: "/legacy/*", Target: "/v1/*", Deprecated: true},
+			{Path: "/legacy/models/*", Target: "/v1/models/*"},
+			{Path: "/old/chat", Target: "/v1/chat/completions", Redirect: true},
+		}
+	})
+	h := aliases.Handler(r)
+	serve := func(method, target, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		req.Header.Set("User-Agent", "client/1.0")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve(http.MethodPost, "/team-a/chat", ""); w.Body.String() != "chat" || w.Header().Get("Deprecation") != "" {
+		t.Errorf("vanity path = %d %q, deprecation %q", w.Code, w.Body.String(), w.Header().Get("Deprecation"))
+	}
+	w := serve(http.MethodPost, "/api/generate", "sk-team-b-secret")
+	if w.Body.String() != "chat" {
+		t.Errorf("shadowed route = %q", w.Body.String())
+	}
+	if w.Header().Get("Deprecation") != "true" || w.Header().Get("Sunset") != "Sun, 31 Jan 2027 00:00:00 GMT" || w.Header().Get("Link") != `<https://docs.example.com/migrate>; rel="deprecation"` {
+		t.Errorf("deprecation headers = %v", w.Header())
+	}
+	serve(http.MethodPost, "/api/generate", "sk-team-b-secret")
+	if w := serve(http.MethodGet, "/legacy/models/m1?alt=json", ""); w.Body.String() != "model m1 json" || w.Header().Get("Deprecation") != "" {
+		t.Errorf("longest wildcard = %q, deprecation %q", w.Body.String(), w.Header().Get("Deprecation"))
+	}
+	if w := serve(http.MethodPost, "/legacy/chat/completions", ""); w.Body.String() != "chat" || w.Header().Get("Deprecation") != "true" {
+		t.Errorf("wildcard = %q, deprecation %q", w.Body.String(), w.Header().Get("Deprecation"))
+	}
+	if w := serve(http.MethodPost, "/old/chat?stream=true", ""); w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != "/v1/chat/completions?stream=true" {
+		t.Errorf("redirect = %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	callers := aliases.Callers()
+	if len(callers) != 2 {
+		t.Fatalf("callers = %+v", callers)
+	}
+	if c := callers[0]; c.Path != "/api/generate" || c.Client != "sk-t...cret" || c.UserAgent != "client/1.0" || c.Requests != 2 {
+		t.Errorf("caller = %+v", c)
+	}
+	if c := callers[1]; c.Path != "/legacy/*" || c.Client != "192.0.2.1" || c.Requests != 1 {
+		t.Errorf("caller = %+v", c)
+	}
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/logging"
+)
+
+// MirroredHeader marks requests sent by a mirror, which are never mirrored
+// again.
+const MirroredHeader = "X-LLMMUX-Mirrored"
+
+const (
+	defaultMirrorTimeout     = 60 * time.Second
+	defaultMirrorMaxInFlight = 16
+)
+
+// mirrorStrippedHeaders are client headers not copied to the mirror.
+var mirrorStrippedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"X-Api-Key",
+	"X-Goog-Api-Key",
+	"X-Management-Key",
+	"Cookie",
+	"Connection",
+	"Content-Length",
+	"Accept-Encoding",
+	"Content-Encoding",
+	"Idempotency-Key",
+}
+
+// mirrorStrippedQuery are query parameters that carry credentials.
+var mirrorStrippedQuery = []string{"key", "api_key", "auth_token"}
+
+var mirrorClient = &http.Client{}
+
+// MirrorMiddleware sends an asynchronous copy of a sample of POST requests,
+// with client credentials stripped, to the mirror configured in
+// getConfig. The copy is sent once the request finished, unless it failed
+// authentication; the mirror's response is discarded.
+//
+// Parameters:
+//   - getConfig: Function returning the current mirror settings (hot-reload aware)
+func MirrorMiddleware(getConfig func() config.MirrorConfig) gin.HandlerFunc {
+	var mu sync.Mutex
+	var sem chan struct{}
+	slots := func(n int) chan struct{} {
+		mu.Lock()
+		defer mu.Unlock()
+		if sem == nil || cap(sem) != n {
+			sem = make(chan struct{}, n)
+		}
+		return sem
+	}
+
+	return func(c *gin.Context) {
+		cfg := getConfig()
+		if !shouldMirror(cfg, c.Request) {
+			c.Next()
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Next()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(bytes.Clone(body)))
+		target := mirrorURL(cfg.URL, c.Request)
+		header := mirrorHeader(c.Request.Header, cfg.APIKey)
+
+		c.Next()
+
+		if status := c.Writer.Status(); status == http.StatusUnauthorized || status == http.StatusForbidden {
+			return
+		}
+		maxInFlight := cfg.MaxInFlight
+		if maxInFlight <= 0 {
+			maxInFlight = defaultMirrorMaxInFlight
+		}
+		sem := slots(maxInFlight)
+		select {
+		case sem <- struct{}{}:
+		default:
+			logging.Debugf("mirror: dropping copy of %s, %d requests in flight", c.Request.URL.Path, maxInFlight)
+			return
+		}
+		timeout := defaultMirrorTimeout
+		if d, err := time.ParseDuration(strings.TrimSpace(cfg.Timeout)); err == nil && d > 0 {
+			timeout = d
+		}
+		go func() {
+			defer func() { <-sem }()
+			sendMirror(target, header, body, timeout)
+		}()
+	}
+}
+
+func shouldMirror(cfg config.MirrorConfig, r *http.Request) bool {
+	if cfg.URL == "" || r.Method != http.MethodPost || r.Header.Get(MirroredHeader) != "" {
+		return false
+	}
+	path := r.URL.Path
+	if strings.HasPrefix(path, "/v1/management") {
+		return false
+	}
+	if len(cfg.Paths) > 0 && !slices.ContainsFunc(cfg.Paths, func(p string) bool { return strings.HasPrefix(path, p) }) {
+		return false
+	}
+	return cfg.SampleRate <= 0 || cfg.SampleRate >= 1 || rand.Float64() < cfg.SampleRate
+}
+
+// mirrorURL appends the path and the credential-free query of r to base.
+func mirrorURL(base string, r *http.Request) string {
+	query := r.URL.Query()
+	for _, k := range mirrorStrippedQuery {
+		query.Del(k)
+	}
+	target := strings.TrimRight(base, "/") + r.URL.Path
+	if encoded := query.Encode(); encoded != "" {
+		target += "?" + encoded
+	}
+	return target
+}
+
+func mirrorHeader(in http.Header, apiKey string) http.Header {
+	h := in.Clone()
+	for _, k := range mirrorStrippedHeaders {
+		h.Del(k)
+	}
+	if apiKey != "" {
+		h.Set("Authorization", "Bearer "+apiKey)
+	}
+	h.Set(MirroredHeader, "true")
+	return h
+}
+
+func sendMirror(target string, header http.Header, body []byte, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		logging.Debugf("mirror: invalid request to %s: %v", target, err)
+		return
+	}
+	req.Header = header
+	resp, err := mirrorClient.Do(req)
+	if err != nil {
+		logging.Debugf("mirror: %s: %v", target, err)
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		logging.Debugf("mirror: %s returned %d", target, resp.StatusCode)
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+func TestMirrorMiddleware(t *testing.T) {
+	received := make(chan *http.Request, 4)
+	bodies := make(chan string, 4)
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- string(body)
+	}))
+	defer mirror.Close()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(MirrorMiddleware(func() config.MirrorConfig {
+		return config.MirrorConfig{URL: mirror.URL + "/", APIKey: "staging-key"}
+	}))
+	r.POST("/v1beta/models/*action", func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" && c.Query("key") == "" {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:generateContent?key=client-secret&alt=sse", strings.NewReader(`{"contents":[]}`))
+	req.Header.Set("X-Goog-Api-Key", "client-secret")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Body.String() != `{"contents":[]}` {
+		t.Fatalf("handler body = %q", w.Body.String())
+	}
+
+	select {
+	case got := <-received:
+		if got.URL.Path != "/v1beta/models/gemini-2.5-pro:generateContent" || got.URL.RawQuery != "alt=sse" {
+			t.Errorf("mirrored URL = %s", got.URL)
+		}
+		if got.Header.Get("Authorization") != "Bearer staging-key" || got.Header.Get("X-Goog-Api-Key") != "" {
+			t.Errorf("mirrored credentials = %v", got.Header)
+		}
+		if got.Header.Get(MirroredHeader) != "true" {
+			t.Errorf("missing %s header", MirroredHeader)
+		}
+		if body := <-bodies; body != `{"contents":[]}` {
+			t.Errorf("mirrored body = %q", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request was not mirrored")
+	}
+
+	// Requests failing authentication are not mirrored.
+	req = httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:generateContent", strings.NewReader(`{}`))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	select {
+	case got := <-received:
+		t.Errorf("unauthenticated request mirrored to %s", got.URL)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
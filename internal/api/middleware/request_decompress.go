@@ -0,0 +1,63 @@
+// Package middleware provides HTTP middleware components for the CLI Proxy API server.
+// This file contains the request decompression middleware that lets clients upload
+// large payloads with Content-Encoding: gzip.
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gzipBody closes both the gzip stream and the underlying request body.
+type gzipBody struct {
+	*gzip.Reader
+	src io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	_ = b.Reader.Close()
+	return b.src.Close()
+}
+
+// RequestDecompressionMiddleware transparently decodes gzip-encoded request bodies.
+// The body is decoded while it is read, so chunked uploads are never buffered twice and
+// size limits applied later count decompressed bytes. Unsupported encodings are
+// rejected with HTTP 415.
+func RequestDecompressionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		if encoding == "" || encoding == "identity" || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		if encoding != "gzip" && encoding != "x-gzip" {
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{
+				"error": gin.H{
+					"message": "unsupported Content-Encoding: " + encoding,
+					"type":    "invalid_request_error",
+				},
+			})
+			return
+		}
+
+		zr, err := gzip.NewReader(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"message": "invalid gzip request body: " + err.Error(),
+					"type":    "invalid_request_error",
+				},
+			})
+			return
+		}
+		c.Request.Body = &gzipBody{Reader: zr, src: c.Request.Body}
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+		c.Request.ContentLength = -1
+		c.Next()
+	}
+}
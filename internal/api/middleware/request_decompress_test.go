@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newDecompressEngine(limit int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestDecompressionMiddleware())
+	r.Use(RequestSizeLimitMiddleware(limit))
+	r.POST("/", func(c *gin.Context) {
+		body, err := c.GetRawData()
+		if err != nil {
+			c.String(http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		c.String(http.StatusOK, string(body))
+	})
+	return r
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRequestDecompressionMiddlewareDecodesGzip(t *testing.T) {
+	payload := []byte(`{"model":"m","messages":[]}`)
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(gzipBytes(t, payload)))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	newDecompressEngine(0).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != string(payload) {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestRequestDecompressionMiddlewareLimitsDecodedSize(t *testing.T) {
+	payload := bytes.Repeat([]byte("a"), 4096)
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(gzipBytes(t, payload)))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	newDecompressEngine(1024).ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected decoded size to be limited, got %d", w.Code)
+	}
+}
+
+func TestRequestDecompressionMiddlewareRejectsUnknownEncoding(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(bytes.NewReader([]byte("x"))))
+	req.Header.Set("Content-Encoding", "br")
+	w := httptest.NewRecorder()
+
+	newDecompressEngine(0).ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected 415, got %d", w.Code)
+	}
+}
@@ -92,8 +92,12 @@ func captureRequestInfo(c *gin.Context) (*RequestInfo, error) {
 			return nil, err
 		}
 
-		// Restore the body for the actual request processing
-		c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+		// Restore the body for the actual request processing. Anything beyond the
+		// logged prefix is still streamed from the original body.
+		c.Request.Body = &replayBody{
+			Reader: io.MultiReader(bytes.NewReader(bodyBytes), c.Request.Body),
+			src:    c.Request.Body,
+		}
 		body = bodyBytes
 	}
 
@@ -114,3 +118,11 @@ func shouldLogRequest(path string) bool {
 	}
 	return true
 }
+
+// replayBody re-reads the captured prefix followed by the rest of the original body.
+type replayBody struct {
+	io.Reader
+	src io.ReadCloser
+}
+
+func (b *replayBody) Close() error { return b.src.Close() }
@@ -0,0 +1,207 @@
+// Package middleware provides HTTP middleware components for the CLI Proxy API server.
+// This file contains the response compression middleware that negotiates gzip or
+// brotli encoding with the client based on Accept-Encoding.
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+// DefaultCompressionMinSize is the smallest response body worth compressing.
+const DefaultCompressionMinSize = 1024
+
+type flushWriteCloser interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// compressWriter buffers the start of a response until it can decide whether
+// compression is worthwhile, then streams the rest through the encoder.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	level    int
+	minSize  int
+	sse      bool
+
+	decided bool
+	enc     flushWriteCloser
+	pending []byte
+}
+
+// ResponseCompressionMiddleware compresses responses with gzip or brotli when the client
+// advertises support. Non-streaming responses smaller than the configured minimum are sent
+// as-is. Server-sent event streams are only compressed when explicitly enabled, and every
+// flush is propagated through the encoder so events are not delayed.
+//
+// Parameters:
+//   - getConfig: Function returning the current compression settings (hot-reload aware)
+func ResponseCompressionMiddleware(getConfig func() config.CompressionConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := getConfig()
+		if !cfg.Enable || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+		minSize := cfg.MinSize
+		if minSize <= 0 {
+			minSize = DefaultCompressionMinSize
+		}
+
+		cw := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			level:          cfg.Level,
+			minSize:        minSize,
+			sse:            cfg.SSE,
+		}
+		c.Writer = cw
+		defer cw.finish()
+		c.Next()
+	}
+}
+
+// negotiateEncoding picks brotli or gzip from an Accept-Encoding header, honoring q-values.
+func negotiateEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, item := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "br" && name != "gzip" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		// Prefer brotli on ties; it compresses JSON noticeably better.
+		if q > bestQ || (q == bestQ && name == "br") {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		if !w.eligible() {
+			w.decided = true
+		} else if w.isSSE() {
+			w.decided = true
+			w.start()
+		} else {
+			w.pending = append(w.pending, data...)
+			if len(w.pending) < w.minSize {
+				return len(data), nil
+			}
+			w.decided = true
+			w.start()
+			buffered := w.pending
+			w.pending = nil
+			if _, err := w.enc.Write(buffered); err != nil {
+				return 0, err
+			}
+			return len(data), nil
+		}
+	}
+	if w.enc != nil {
+		return w.enc.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush forces a decision for buffered data and pushes encoder output to the client.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decided = true
+		w.writePending()
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) finish() {
+	if !w.decided {
+		w.decided = true
+		w.writePending()
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+	}
+}
+
+func (w *compressWriter) writePending() {
+	if len(w.pending) > 0 {
+		_, _ = w.ResponseWriter.Write(w.pending)
+		w.pending = nil
+	}
+}
+
+func (w *compressWriter) isSSE() bool {
+	return strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream")
+}
+
+// eligible reports whether the response may be compressed at all.
+func (w *compressWriter) eligible() bool {
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	if status := w.Status(); status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	if w.isSSE() {
+		return w.sse
+	}
+	return true
+}
+
+func (w *compressWriter) start() {
+	h := w.Header()
+	h.Set("Content-Encoding", w.encoding)
+	h.Add("Vary", "Accept-Encoding")
+	h.Del("Content-Length")
+
+	switch w.encoding {
+	case "br":
+		level := w.level
+		if level <= 0 || level > brotli.BestCompression {
+			level = brotli.DefaultCompression
+		}
+		w.enc = brotli.NewWriterLevel(w.ResponseWriter, level)
+	default:
+		level := w.level
+		if level <= 0 || level > gzip.BestCompression {
+			level = gzip.DefaultCompression
+		}
+		zw, err := gzip.NewWriterLevel(w.ResponseWriter, level)
+		if err != nil {
+			zw = gzip.NewWriter(w.ResponseWriter)
+		}
+		w.enc = zw
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+func newCompressEngine(cfg config.CompressionConfig, body string, contentType string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ResponseCompressionMiddleware(func() config.CompressionConfig { return cfg }))
+	r.GET("/", func(c *gin.Context) {
+		c.Data(http.StatusOK, contentType, []byte(body))
+	})
+	return r
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := map[string]string{
+		"":                     "",
+		"gzip":                 "gzip",
+		"gzip, deflate, br":    "br",
+		"br;q=0.5, gzip;q=0.8": "gzip",
+		"br;q=0, gzip":         "gzip",
+		"identity, deflate":    "",
+		"GZIP;q=1.0, br;q=1.0": "br",
+	}
+	for header, want := range cases {
+		if got := negotiateEncoding(header); got != want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestResponseCompressionMiddlewareCompressesLargeBodies(t *testing.T) {
+	body := strings.Repeat(`{"text":"hello"}`, 200)
+	r := newCompressEngine(config.CompressionConfig{Enable: true}, body, "application/json")
+
+	for _, enc := range []string{"gzip", "br"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", enc)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Content-Encoding"); got != enc {
+			t.Fatalf("Content-Encoding = %q, want %q", got, enc)
+		}
+		var rd io.Reader
+		if enc == "gzip" {
+			zr, err := gzip.NewReader(w.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rd = zr
+		} else {
+			rd = brotli.NewReader(w.Body)
+		}
+		decoded, err := io.ReadAll(rd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(decoded) != body {
+			t.Fatalf("%s round trip mismatch", enc)
+		}
+	}
+}
+
+func TestResponseCompressionMiddlewareSkipsSmallBodiesAndSSE(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	w := httptest.NewRecorder()
+	newCompressEngine(config.CompressionConfig{Enable: true}, "{}", "application/json").ServeHTTP(w, req)
+	if w.Header().Get("Content-Encoding") != "" || w.Body.String() != "{}" {
+		t.Fatalf("small body should not be compressed: %q", w.Body.String())
+	}
+
+	sse := strings.Repeat("data: {}\n\n", 500)
+	w = httptest.NewRecorder()
+	newCompressEngine(config.CompressionConfig{Enable: true}, sse, "text/event-stream").ServeHTTP(w, req)
+	if w.Header().Get("Content-Encoding") != "" || !bytes.Equal(w.Body.Bytes(), []byte(sse)) {
+		t.Fatal("SSE should not be compressed unless enabled")
+	}
+
+	w = httptest.NewRecorder()
+	newCompressEngine(config.CompressionConfig{Enable: true, SSE: true}, sse, "text/event-stream").ServeHTTP(w, req)
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatal("SSE should be compressed when enabled")
+	}
+}
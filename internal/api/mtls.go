@@ -0,0 +1,38 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+// clientAuthTLSConfig returns the server TLS settings verifying client
+// certificates against tls.client-ca, or nil when no client CA is set.
+func clientAuthTLSConfig(cfg config.TLSConfig) (*tls.Config, error) {
+	path := strings.TrimSpace(cfg.ClientCA)
+	if path == "" {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tls.client-ca: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("tls.client-ca %s contains no PEM certificates", path)
+	}
+	tlsCfg := &tls.Config{ClientCAs: pool, MinVersion: tls.VersionTLS12}
+	switch strings.ToLower(strings.TrimSpace(cfg.ClientAuth)) {
+	case "", "require":
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	case "optional":
+		tlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+	default:
+		return nil, fmt.Errorf("tls.client-auth must be require or optional, got %q", cfg.ClientAuth)
+	}
+	return tlsCfg, nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/api/handlers/format/ollama"
 	"github.com/nghyane/llm-mux/internal/api/handlers/format/openai"
 	"github.com/nghyane/llm-mux/internal/api/middleware"
+	"github.com/nghyane/llm-mux/internal/constant"
 	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/oauth"
 )
@@ -36,11 +37,22 @@ func (s *Server) setupRoutes() {
 	v1.Use(s.conditionalAuthMiddleware())
 	{
 		v1.GET("/models", s.unifiedModelsHandler(openaiHandlers, claudeCodeHandlers))
-		v1.POST("/chat/completions", openaiHandlers.ChatCompletions)
+		v1.GET("/limits", openaiHandlers.Limits)
+		v1.POST("/chat/completions", s.explainMiddleware(constant.OpenAI), openaiHandlers.ChatCompletions)
+		v1.GET("/chat/completions/:id", openaiHandlers.GetChatCompletion)
 		v1.POST("/completions", openaiHandlers.Completions)
-		v1.POST("/messages", claudeCodeHandlers.ClaudeMessages)
+		v1.POST("/messages", s.explainMiddleware(constant.Claude), claudeCodeHandlers.ClaudeMessages)
 		v1.POST("/messages/count_tokens", claudeCodeHandlers.ClaudeCountTokens)
-		v1.POST("/responses", openaiResponsesHandlers.Responses)
+		v1.POST("/validate", func(c *gin.Context) { s.handlers.Validate(c, s.cfg) })
+		v1.POST("/responses", s.explainMiddleware(constant.OpenaiResponse), openaiResponsesHandlers.Responses)
+		v1.POST("/conversations", openaiResponsesHandlers.CreateConversation)
+		v1.GET("/conversations/:id", openaiResponsesHandlers.GetConversation)
+		v1.POST("/conversations/:id", openaiResponsesHandlers.UpdateConversation)
+		v1.DELETE("/conversations/:id", openaiResponsesHandlers.DeleteConversation)
+		v1.POST("/conversations/:id/items", openaiResponsesHandlers.CreateConversationItems)
+		v1.GET("/conversations/:id/items", openaiResponsesHandlers.ListConversationItems)
+		v1.GET("/conversations/:id/items/:item_id", openaiResponsesHandlers.GetConversationItem)
+		v1.DELETE("/conversations/:id/items/:item_id", openaiResponsesHandlers.DeleteConversationItem)
 	}
 
 	// Gemini compatible API routes
@@ -49,7 +61,7 @@ func (s *Server) setupRoutes() {
 	v1beta.Use(s.conditionalAuthMiddleware())
 	{
 		v1beta.GET("/models", geminiHandlers.GeminiModels)
-		v1beta.POST("/models/:action", geminiHandlers.GeminiHandler)
+		v1beta.POST("/models/:action", s.explainMiddleware(constant.Gemini), geminiHandlers.GeminiHandler)
 		v1beta.GET("/models/:action", geminiHandlers.GeminiGetHandler)
 	}
 
@@ -76,7 +88,7 @@ func (s *Server) setupRoutes() {
 	apiGroup.Use(middleware.RequestSizeLimitMiddleware(s.cfg.MaxRequestSize))
 	{
 		apiGroup.GET("/tags", ollamaHandlers.Tags)
-		apiGroup.POST("/chat", ollamaHandlers.Chat)
+		apiGroup.POST("/chat", s.explainMiddleware(constant.Ollama), ollamaHandlers.Chat)
 		apiGroup.POST("/generate", ollamaHandlers.Generate)
 		apiGroup.POST("/show", ollamaHandlers.Show)
 	}
@@ -86,7 +98,7 @@ func (s *Server) setupRoutes() {
 	ollamaGroup.Use(middleware.RequestSizeLimitMiddleware(s.cfg.MaxRequestSize))
 	{
 		ollamaGroup.GET("/tags", ollamaHandlers.Tags)
-		ollamaGroup.POST("/chat", ollamaHandlers.Chat)
+		ollamaGroup.POST("/chat", s.explainMiddleware(constant.Ollama), ollamaHandlers.Chat)
 		ollamaGroup.POST("/generate", ollamaHandlers.Generate)
 		ollamaGroup.POST("/show", ollamaHandlers.Show)
 	}
@@ -29,6 +29,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/registry"
 	"github.com/nghyane/llm-mux/internal/usage"
 	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/nghyane/llm-mux/internal/watcher"
 	"gopkg.in/yaml.v3"
 )
 
@@ -94,6 +95,22 @@ func WithKeepAliveEndpoint(timeout time.Duration, onTimeout func()) ServerOption
 	}
 }
 
+// applyAccessLogConfig configures the sampled access log from cfg.
+func applyAccessLogConfig(cfg *config.Config) {
+	al := cfg.AccessLog
+	if err := log.AccessLog().Configure(log.AccessLogSettings{
+		Enabled:    al.Enabled,
+		SampleRate: al.SampleRate,
+		File:       al.File,
+		MaxSizeMB:  al.MaxSizeMB,
+		MaxBackups: al.MaxBackups,
+		MaxAgeDays: al.MaxAgeDays,
+		Compress:   al.Compress,
+	}); err != nil {
+		log.Errorf("failed to configure access log: %v", err)
+	}
+}
+
 // WithRequestLoggerFactory customises request logger creation.
 func WithRequestLoggerFactory(factory func(*config.Config, string) log.RequestLogger) ServerOption {
 	return func(cfg *serverOptionConfig) {
@@ -125,6 +142,9 @@ type Server struct {
 	mgmt      *managementHandlers.Handler
 	ampModule *ampmodule.AmpModule
 
+	// endpointAliases serves configured alias paths ahead of the router.
+	endpointAliases *middleware.EndpointAliases
+
 	managementRoutesRegistered atomic.Bool
 	managementRoutesEnabled    atomic.Bool
 
@@ -167,6 +187,22 @@ func NewServer(cfg *config.Config, authManager *provider.Manager, accessManager
 		engine.Use(mw)
 	}
 
+	// The access log wraps everything below it so streams are timed to the end.
+	applyAccessLogConfig(cfg)
+	engine.Use(middleware.AccessLogMiddleware())
+
+	// Decode compressed request bodies before anything reads them.
+	engine.Use(middleware.RequestDecompressionMiddleware())
+
+	// Compression must sit below request logging so logs capture the plain body.
+	var s *Server
+	engine.Use(middleware.ResponseCompressionMiddleware(func() config.CompressionConfig {
+		if s == nil || s.cfg == nil {
+			return config.CompressionConfig{}
+		}
+		return s.cfg.Compression
+	}))
+
 	// Add request logging middleware (positioned after recovery, before auth)
 	// Resolve logs directory relative to the configuration file directory.
 	var requestLogger log.RequestLogger
@@ -181,7 +217,18 @@ func NewServer(cfg *config.Config, authManager *provider.Manager, accessManager
 		}
 	}
 
-	engine.Use(corsMiddleware())
+	engine.Use(middleware.CORSMiddleware(func() config.CORSConfig {
+		if s == nil || s.cfg == nil {
+			return config.CORSConfig{}
+		}
+		return s.cfg.CORS
+	}))
+	engine.Use(middleware.MirrorMiddleware(func() config.MirrorConfig {
+		if s == nil || s.cfg == nil {
+			return config.MirrorConfig{}
+		}
+		return s.cfg.Mirror
+	}))
 	wd, err := os.Getwd()
 	if err != nil {
 		wd = configFilePath
@@ -189,11 +236,11 @@ func NewServer(cfg *config.Config, authManager *provider.Manager, accessManager
 
 	providerNames := make([]string, 0, len(cfg.Providers))
 	for _, p := range cfg.Providers {
-		if p.Type == "openai" || p.Type == "vertex-compat" {
+		if p.Type.IsOpenAICompatible() || p.Type == "vertex-compat" {
 			providerNames = append(providerNames, p.GetDisplayName())
 		}
 	}
-	s := &Server{
+	s = &Server{
 		engine:         engine,
 		handlers:       format.NewBaseAPIHandlers(&cfg.SDKConfig, &cfg.Routing, authManager, providerNames),
 		cfg:            cfg,
@@ -225,6 +272,13 @@ func NewServer(cfg *config.Config, authManager *provider.Manager, accessManager
 		logDir = filepath.Join(base, "logs")
 	}
 	s.mgmt.SetLogDirectory(logDir)
+	s.endpointAliases = middleware.NewEndpointAliases(func() []config.EndpointAlias {
+		if s.cfg == nil {
+			return nil
+		}
+		return s.cfg.EndpointAliases
+	})
+	s.mgmt.SetEndpointAliasCallers(s.endpointAliases.Callers)
 	s.localPassword = optionState.localPassword
 
 	// Setup routes
@@ -264,8 +318,9 @@ func NewServer(cfg *config.Config, authManager *provider.Manager, accessManager
 	// Create HTTP server
 	s.server = &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Port),
-		Handler: engine,
+		Handler: s.endpointAliases.Handler(engine),
 	}
+	configureHTTP2(s.server, cfg.HTTP2)
 
 	return s
 }
@@ -280,21 +335,35 @@ func (s *Server) Start() error {
 	}
 
 	useTLS := s.cfg != nil && s.cfg.TLS.Enable
+	var cert, key string
 	if useTLS {
-		cert := strings.TrimSpace(s.cfg.TLS.Cert)
-		key := strings.TrimSpace(s.cfg.TLS.Key)
+		cert = strings.TrimSpace(s.cfg.TLS.Cert)
+		key = strings.TrimSpace(s.cfg.TLS.Key)
 		if cert == "" || key == "" {
 			return fmt.Errorf("failed to start HTTPS server: tls.cert or tls.key is empty")
 		}
-		log.Debugf("Starting API server on %s with TLS", s.server.Addr)
-		if errServeTLS := s.server.ListenAndServeTLS(cert, key); errServeTLS != nil && !errors.Is(errServeTLS, http.ErrServerClosed) {
+		tlsCfg, err := clientAuthTLSConfig(s.cfg.TLS)
+		if err != nil {
+			return fmt.Errorf("failed to start HTTPS server: %v", err)
+		}
+		s.server.TLSConfig = tlsCfg
+	}
+
+	ln, errListen := s.listen()
+	if errListen != nil {
+		return fmt.Errorf("failed to start HTTP server: %v", errListen)
+	}
+
+	if useTLS {
+		log.Debugf("Starting API server on %s with TLS", ln.Addr())
+		if errServeTLS := s.server.ServeTLS(ln, cert, key); errServeTLS != nil && !errors.Is(errServeTLS, http.ErrServerClosed) {
 			return fmt.Errorf("failed to start HTTPS server: %v", errServeTLS)
 		}
 		return nil
 	}
 
-	log.Debugf("Starting API server on %s", s.server.Addr)
-	if errServe := s.server.ListenAndServe(); errServe != nil && !errors.Is(errServe, http.ErrServerClosed) {
+	log.Debugf("Starting API server on %s", ln.Addr())
+	if errServe := s.server.Serve(ln); errServe != nil && !errors.Is(errServe, http.ErrServerClosed) {
 		return fmt.Errorf("failed to start HTTP server: %v", errServe)
 	}
 
@@ -371,6 +440,10 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 		}
 	}
 
+	if oldCfg == nil || oldCfg.AccessLog != cfg.AccessLog {
+		applyAccessLogConfig(cfg)
+	}
+
 	if oldCfg != nil && oldCfg.LoggingToFile != cfg.LoggingToFile {
 		if err := log.ConfigureLogOutput(cfg.LoggingToFile); err != nil {
 			log.Errorf("failed to reconfigure log output: %v", err)
@@ -448,7 +521,7 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 
 	providerNames := make([]string, 0, len(cfg.Providers))
 	for _, p := range cfg.Providers {
-		if p.Type == "openai" || p.Type == "vertex-compat" {
+		if p.Type.IsOpenAICompatible() || p.Type == "vertex-compat" {
 			providerNames = append(providerNames, p.GetDisplayName())
 		}
 	}
@@ -489,7 +562,7 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 			} else {
 				claudeAPIKeyCount += len(keys)
 			}
-		case "openai":
+		case "openai", "llamacpp", "vllm":
 			openAICompatCount += len(keys)
 		case "vertex-compat":
 			vertexAICompatCount += len(keys)
@@ -508,6 +581,22 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 	)
 }
 
+// SetReloadStatusProvider exposes config hot-reload status through the management API.
+func (s *Server) SetReloadStatusProvider(fn func() watcher.ReloadStatus) {
+	if s == nil || s.mgmt == nil {
+		return
+	}
+	s.mgmt.SetReloadStatusProvider(fn)
+}
+
+// SetModelRefresher exposes provider model list refreshes through the management API.
+func (s *Server) SetModelRefresher(fn managementHandlers.ModelRefresher) {
+	if s == nil || s.mgmt == nil {
+		return
+	}
+	s.mgmt.SetModelRefresher(fn)
+}
+
 func (s *Server) SetWebsocketAuthChangeHandler(fn func(bool, bool)) {
 	if s == nil {
 		return
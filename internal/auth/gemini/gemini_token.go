@@ -21,6 +21,7 @@ type GeminiTokenStorage struct {
 	Email     string `json:"email"`
 	Auto      bool   `json:"auto"`
 	Checked   bool   `json:"checked"`
+	Tier      string `json:"tier,omitempty"`
 	Type      string `json:"type"`
 }
 
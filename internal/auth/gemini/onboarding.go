@@ -0,0 +1,258 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+const (
+	codeAssistEndpoint       = "https://cloudcode-pa.googleapis.com"
+	codeAssistVersion        = "v1internal"
+	codeAssistUserAgent      = "google-api-nodejs-client/9.15.1"
+	codeAssistAPIClient      = "gl-node/22.17.0"
+	codeAssistClientMetadata = "ideType=IDE_UNSPECIFIED,platform=PLATFORM_UNSPECIFIED,pluginType=GEMINI"
+
+	onboardPollInterval = 5 * time.Second
+	legacyTierID        = "legacy-tier"
+)
+
+// ErrProjectRequired is returned by Onboard when the account's tier needs a
+// Google Cloud project and none was given or assigned.
+var ErrProjectRequired = errors.New("gemini cli: project selection required")
+
+// OnboardResult describes the Code Assist setup of an account.
+type OnboardResult struct {
+	ProjectID string
+	TierID    string
+	TierName  string
+}
+
+type codeAssistTier struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	IsDefault          bool   `json:"isDefault"`
+	UserDefinedProject bool   `json:"userDefinedCloudaicompanionProject"`
+}
+
+type loadCodeAssistResponse struct {
+	CurrentTier  *codeAssistTier  `json:"currentTier"`
+	AllowedTiers []codeAssistTier `json:"allowedTiers"`
+	Project      json.RawMessage  `json:"cloudaicompanionProject"`
+}
+
+type onboardUserResponse struct {
+	Done     bool `json:"done"`
+	Response struct {
+		Project json.RawMessage `json:"cloudaicompanionProject"`
+	} `json:"response"`
+}
+
+// projectID reads a cloudaicompanionProject value, which is either the ID or
+// an object holding it.
+func projectID(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var id string
+	if json.Unmarshal(raw, &id) == nil {
+		return strings.TrimSpace(id)
+	}
+	var obj struct {
+		ID string `json:"id"`
+	}
+	if json.Unmarshal(raw, &obj) == nil {
+		return strings.TrimSpace(obj.ID)
+	}
+	return ""
+}
+
+// Onboard prepares an account for Gemini CLI requests: it loads the Code
+// Assist setup and, unless the account is already onboarded to the project,
+// onboards it to the default tier, polling until the operation is done. With
+// no requested project, tiers that provision a managed project get one; the
+// others return ErrProjectRequired. progress, if set, receives each step.
+func Onboard(ctx context.Context, httpClient *http.Client, requestedProject string, progress func(string)) (*OnboardResult, error) {
+	report := func(step string) {
+		log.Info(step)
+		if progress != nil {
+			progress(step)
+		}
+	}
+	metadata := map[string]string{
+		"ideType":    "IDE_UNSPECIFIED",
+		"platform":   "PLATFORM_UNSPECIFIED",
+		"pluginType": "GEMINI",
+	}
+	requested := strings.TrimSpace(requestedProject)
+
+	report("Loading Code Assist setup")
+	loadReq := map[string]any{"metadata": metadata}
+	if requested != "" {
+		loadReq["cloudaicompanionProject"] = requested
+	}
+	var load loadCodeAssistResponse
+	if err := callCodeAssist(ctx, httpClient, "loadCodeAssist", loadReq, &load); err != nil {
+		return nil, fmt.Errorf("load code assist: %w", err)
+	}
+
+	assigned := projectID(load.Project)
+	if load.CurrentTier != nil && assigned != "" && (requested == "" || strings.EqualFold(requested, assigned)) {
+		report(fmt.Sprintf("Already onboarded to %s (%s)", assigned, tierLabel(*load.CurrentTier)))
+		return &OnboardResult{ProjectID: assigned, TierID: load.CurrentTier.ID, TierName: load.CurrentTier.Name}, nil
+	}
+
+	tier := codeAssistTier{ID: legacyTierID}
+	for _, t := range load.AllowedTiers {
+		if t.IsDefault && strings.TrimSpace(t.ID) != "" {
+			tier = t
+			break
+		}
+	}
+	project := requested
+	if project == "" {
+		project = assigned
+	}
+	if project == "" && (tier.UserDefinedProject || tier.ID == legacyTierID) {
+		return nil, ErrProjectRequired
+	}
+
+	onboardReq := map[string]any{"tierId": tier.ID, "metadata": metadata}
+	if project != "" {
+		onboardReq["cloudaicompanionProject"] = project
+		report(fmt.Sprintf("Onboarding project %s to %s", project, tierLabel(tier)))
+	} else {
+		report(fmt.Sprintf("Onboarding to %s with a managed project", tierLabel(tier)))
+	}
+	for {
+		var resp onboardUserResponse
+		if err := callCodeAssist(ctx, httpClient, "onboardUser", onboardReq, &resp); err != nil {
+			return nil, fmt.Errorf("onboard user: %w", err)
+		}
+		if resp.Done {
+			final := project
+			if id := projectID(resp.Response.Project); id != "" {
+				if requested != "" && !strings.EqualFold(id, requested) {
+					log.Warnf("Gemini onboarding returned project %s instead of requested %s; keeping requested project ID.", id, requested)
+				} else {
+					final = id
+				}
+			}
+			if final == "" {
+				return nil, fmt.Errorf("onboard user completed without project id")
+			}
+			report(fmt.Sprintf("Onboarding complete. Using Project ID: %s", final))
+			return &OnboardResult{ProjectID: final, TierID: tier.ID, TierName: tier.Name}, nil
+		}
+		report("Onboarding in progress, waiting 5 seconds...")
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(onboardPollInterval):
+		}
+	}
+}
+
+func tierLabel(t codeAssistTier) string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return t.ID
+}
+
+func callCodeAssist(ctx context.Context, httpClient *http.Client, endpoint string, body any, result any) error {
+	url := fmt.Sprintf("%s/%s:%s", codeAssistEndpoint, codeAssistVersion, endpoint)
+
+	rawBody, errMarshal := json.Marshal(body)
+	if errMarshal != nil {
+		return fmt.Errorf("marshal request body: %w", errMarshal)
+	}
+	req, errRequest := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(rawBody))
+	if errRequest != nil {
+		return fmt.Errorf("create request: %w", errRequest)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", codeAssistUserAgent)
+	req.Header.Set("X-Goog-Api-Client", codeAssistAPIClient)
+	req.Header.Set("Client-Metadata", codeAssistClientMetadata)
+
+	resp, errDo := httpClient.Do(req)
+	if errDo != nil {
+		return fmt.Errorf("execute request: %w", errDo)
+	}
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			log.Errorf("response body close error: %v", errClose)
+		}
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("api request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+	}
+	if errDecode := json.NewDecoder(resp.Body).Decode(result); errDecode != nil {
+		return fmt.Errorf("decode response body: %w", errDecode)
+	}
+	return nil
+}
+
+// FetchProjects lists the Google Cloud projects the account can access.
+func FetchProjects(ctx context.Context, httpClient *http.Client) ([]interfaces.GCPProjectProjects, error) {
+	req, errRequest := http.NewRequestWithContext(ctx, http.MethodGet, "https://cloudresourcemanager.googleapis.com/v1/projects", nil)
+	if errRequest != nil {
+		return nil, fmt.Errorf("could not create project list request: %w", errRequest)
+	}
+
+	resp, errDo := httpClient.Do(req)
+	if errDo != nil {
+		return nil, fmt.Errorf("failed to execute project list request: %w", errDo)
+	}
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			log.Errorf("response body close error: %v", errClose)
+		}
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("project list request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+	}
+
+	var projects interfaces.GCPProject
+	if errDecode := json.NewDecoder(resp.Body).Decode(&projects); errDecode != nil {
+		return nil, fmt.Errorf("failed to unmarshal project list: %w", errDecode)
+	}
+	return projects.Projects, nil
+}
+
+// OnboardAuto onboards an account without user interaction: the requested
+// project if given, else the project Code Assist assigns, else the first
+// active project the account can access.
+func OnboardAuto(ctx context.Context, httpClient *http.Client, requestedProject string, progress func(string)) (*OnboardResult, error) {
+	result, err := Onboard(ctx, httpClient, requestedProject, progress)
+	if !errors.Is(err, ErrProjectRequired) {
+		return result, err
+	}
+	if progress != nil {
+		progress("Selecting a Google Cloud project")
+	}
+	projects, errProjects := FetchProjects(ctx, httpClient)
+	if errProjects != nil {
+		return nil, errProjects
+	}
+	for _, p := range projects {
+		if p.LifecycleState == "" || strings.EqualFold(p.LifecycleState, "ACTIVE") {
+			return Onboard(ctx, httpClient, p.ProjectID, progress)
+		}
+	}
+	return nil, fmt.Errorf("%w: the account has no active Google Cloud project", ErrProjectRequired)
+}
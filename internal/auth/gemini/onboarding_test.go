@@ -0,0 +1,93 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/json"
+)
+
+type codeAssistStub map[string]func(body map[string]any) string
+
+func (s codeAssistStub) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body map[string]any
+	if req.Body != nil {
+		_ = json.NewDecoder(req.Body).Decode(&body)
+	}
+	name := req.URL.Path[strings.LastIndex(req.URL.Path, ":")+1:]
+	if strings.HasSuffix(req.URL.Path, "/v1/projects") {
+		name = "projects"
+	}
+	handler, ok := s[name]
+	if !ok {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(handler(body)))}, nil
+}
+
+func TestOnboardFreeTierGetsManagedProject(t *testing.T) {
+	var onboardBody map[string]any
+	client := &http.Client{Transport: codeAssistStub{
+		"loadCodeAssist": func(map[string]any) string {
+			return `{"allowedTiers":[{"id":"free-tier","name":"Gemini Code Assist for individuals","isDefault":true}]}`
+		},
+		"onboardUser": func(body map[string]any) string {
+			onboardBody = body
+			return `{"done":true,"response":{"cloudaicompanionProject":{"id":"managed-123"}}}`
+		},
+	}}
+	var steps []string
+	result, err := Onboard(context.Background(), client, "", func(s string) { steps = append(steps, s) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ProjectID != "managed-123" || result.TierID != "free-tier" {
+		t.Errorf("result = %+v", result)
+	}
+	if _, ok := onboardBody["cloudaicompanionProject"]; ok {
+		t.Errorf("free tier onboarding sent a project: %v", onboardBody)
+	}
+	if len(steps) < 3 {
+		t.Errorf("progress steps = %v", steps)
+	}
+}
+
+func TestOnboardAutoSelectsProject(t *testing.T) {
+	client := &http.Client{Transport: codeAssistStub{
+		"loadCodeAssist": func(map[string]any) string {
+			return `{"allowedTiers":[{"id":"standard-tier","isDefault":true,"userDefinedCloudaicompanionProject":true}]}`
+		},
+		"onboardUser": func(body map[string]any) string {
+			return `{"done":true,"response":{"cloudaicompanionProject":"` + body["cloudaicompanionProject"].(string) + `"}}`
+		},
+		"projects": func(map[string]any) string {
+			return `{"projects":[{"projectId":"old","lifecycleState":"DELETE_REQUESTED"},{"projectId":"work","lifecycleState":"ACTIVE"}]}`
+		},
+	}}
+	if _, err := Onboard(context.Background(), client, "", nil); !errors.Is(err, ErrProjectRequired) {
+		t.Fatalf("Onboard without project: err = %v", err)
+	}
+	result, err := OnboardAuto(context.Background(), client, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ProjectID != "work" || result.TierID != "standard-tier" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestOnboardSkipsOnboardedAccount(t *testing.T) {
+	client := &http.Client{Transport: codeAssistStub{
+		"loadCodeAssist": func(map[string]any) string {
+			return `{"currentTier":{"id":"standard-tier"},"cloudaicompanionProject":"proj"}`
+		},
+	}}
+	result, err := Onboard(context.Background(), client, "proj", nil)
+	if err != nil || result.ProjectID != "proj" || result.TierID != "standard-tier" {
+		t.Fatalf("result = %+v, err = %v", result, err)
+	}
+}
@@ -0,0 +1,206 @@
+package login
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	baseauth "github.com/nghyane/llm-mux/internal/auth"
+	"github.com/nghyane/llm-mux/internal/auth/claude"
+	"github.com/nghyane/llm-mux/internal/auth/codex"
+	"github.com/nghyane/llm-mux/internal/auth/qwen"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+// CLICredentialSource describes where an official CLI tool keeps its OAuth credentials.
+type CLICredentialSource struct {
+	// Tool is the name accepted by the import command ("gemini", "claude", "codex", "qwen").
+	Tool string
+	// Path is the credentials file relative to the user's home directory.
+	Path    string
+	convert func(home string, data []byte) (*provider.Auth, error)
+}
+
+// CLICredentialSources lists the official CLI tools whose logins can be imported.
+var CLICredentialSources = []CLICredentialSource{
+	{Tool: "gemini", Path: ".gemini/oauth_creds.json", convert: convertGeminiCLICredentials},
+	{Tool: "claude", Path: ".claude/.credentials.json", convert: convertClaudeCodeCredentials},
+	{Tool: "codex", Path: ".codex/auth.json", convert: convertCodexCLICredentials},
+	{Tool: "qwen", Path: ".qwen/oauth_creds.json", convert: convertQwenCLICredentials},
+}
+
+// CLIImport is the outcome of converting one official CLI credentials file.
+type CLIImport struct {
+	Tool  string         `json:"tool"`
+	Path  string         `json:"path"`
+	Auth  *provider.Auth `json:"-"`
+	Error string         `json:"error,omitempty"`
+}
+
+// DiscoverCLICredentials reads the credentials of the official CLI tools under
+// home and converts them into llm-mux auth records. Tools without a credentials
+// file are skipped. An empty tools list checks every known tool.
+func DiscoverCLICredentials(home string, tools []string) []CLIImport {
+	var results []CLIImport
+	for _, src := range CLICredentialSources {
+		if len(tools) > 0 && !slices.Contains(tools, src.Tool) {
+			continue
+		}
+		path := filepath.Join(home, src.Path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				results = append(results, CLIImport{Tool: src.Tool, Path: path, Error: err.Error()})
+			}
+			continue
+		}
+		result := CLIImport{Tool: src.Tool, Path: path}
+		if result.Auth, err = src.convert(home, data); err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// millisToRFC3339 converts the millisecond expiry timestamps the CLIs store.
+func millisToRFC3339(ms int64) string {
+	if ms <= 0 {
+		return ""
+	}
+	return time.UnixMilli(ms).Format(time.RFC3339)
+}
+
+// convertGeminiCLICredentials reads ~/.gemini/oauth_creds.json. The Gemini CLI
+// uses the same OAuth client as llm-mux, so its refresh token works as is; the
+// project is discovered on first use.
+func convertGeminiCLICredentials(home string, data []byte) (*provider.Auth, error) {
+	creds := gjson.ParseBytes(data)
+	refreshToken := creds.Get("refresh_token").String()
+	if refreshToken == "" {
+		return nil, fmt.Errorf("gemini: refresh_token missing")
+	}
+	token := map[string]any{
+		"access_token":  creds.Get("access_token").String(),
+		"refresh_token": refreshToken,
+		"token_type":    creds.Get("token_type").String(),
+	}
+	if expiry := millisToRFC3339(creds.Get("expiry_date").Int()); expiry != "" {
+		token["expiry"] = expiry
+	}
+
+	var email string
+	if accounts, err := os.ReadFile(filepath.Join(home, ".gemini", "google_accounts.json")); err == nil {
+		email = strings.TrimSpace(gjson.GetBytes(accounts, "active").String())
+	}
+
+	fileName := "gemini.json"
+	label := "gemini"
+	if email != "" {
+		fileName = fmt.Sprintf("gemini-%s-all.json", email)
+		label = email
+	}
+	return &provider.Auth{
+		ID:       fileName,
+		Provider: "gemini",
+		FileName: fileName,
+		Label:    label,
+		Metadata: map[string]any{
+			"type": "gemini", "token": token, "email": email,
+			"project_id": "", "auto": true, "checked": false,
+		},
+	}, nil
+}
+
+// convertClaudeCodeCredentials reads ~/.claude/.credentials.json, taking the
+// account email from ~/.claude.json when available.
+func convertClaudeCodeCredentials(home string, data []byte) (*provider.Auth, error) {
+	creds := gjson.GetBytes(data, "claudeAiOauth")
+	refreshToken := creds.Get("refreshToken").String()
+	if refreshToken == "" {
+		return nil, fmt.Errorf("claude: claudeAiOauth.refreshToken missing")
+	}
+
+	var email string
+	if cfg, err := os.ReadFile(filepath.Join(home, ".claude.json")); err == nil {
+		email = strings.TrimSpace(gjson.GetBytes(cfg, "oauthAccount.emailAddress").String())
+	}
+
+	storage := &claude.ClaudeTokenStorage{
+		AccessToken:  creds.Get("accessToken").String(),
+		RefreshToken: refreshToken,
+		LastRefresh:  time.Now().Format(time.RFC3339),
+		Email:        email,
+		Expire:       millisToRFC3339(creds.Get("expiresAt").Int()),
+	}
+	return cliImportRecord("claude", email, storage), nil
+}
+
+// convertCodexCLICredentials reads ~/.codex/auth.json. The email and account
+// are taken from the ID token claims when the file does not carry them.
+func convertCodexCLICredentials(_ string, data []byte) (*provider.Auth, error) {
+	tokens := gjson.GetBytes(data, "tokens")
+	refreshToken := tokens.Get("refresh_token").String()
+	if refreshToken == "" {
+		return nil, fmt.Errorf("codex: tokens.refresh_token missing (API key logins cannot be imported)")
+	}
+
+	storage := &codex.CodexTokenStorage{
+		IDToken:      tokens.Get("id_token").String(),
+		AccessToken:  tokens.Get("access_token").String(),
+		RefreshToken: refreshToken,
+		AccountID:    tokens.Get("account_id").String(),
+		LastRefresh:  gjson.GetBytes(data, "last_refresh").String(),
+	}
+	if claims, err := codex.ParseJWTToken(storage.IDToken); err == nil {
+		storage.Email = claims.GetUserEmail()
+		if storage.AccountID == "" {
+			storage.AccountID = claims.GetAccountID()
+		}
+	}
+	if claims, err := codex.ParseJWTToken(storage.AccessToken); err == nil && claims.Exp > 0 {
+		storage.Expire = time.Unix(int64(claims.Exp), 0).Format(time.RFC3339)
+	}
+	record := cliImportRecord("codex", storage.Email, storage)
+	record.Metadata["account_id"] = storage.AccountID
+	return record, nil
+}
+
+// convertQwenCLICredentials reads ~/.qwen/oauth_creds.json.
+func convertQwenCLICredentials(_ string, data []byte) (*provider.Auth, error) {
+	creds := gjson.ParseBytes(data)
+	refreshToken := creds.Get("refresh_token").String()
+	if refreshToken == "" {
+		return nil, fmt.Errorf("qwen: refresh_token missing")
+	}
+	storage := &qwen.QwenTokenStorage{
+		AccessToken:  creds.Get("access_token").String(),
+		RefreshToken: refreshToken,
+		LastRefresh:  time.Now().Format(time.RFC3339),
+		ResourceURL:  creds.Get("resource_url").String(),
+		Email:        "qwen-cli",
+		Expire:       millisToRFC3339(creds.Get("expiry_date").Int()),
+	}
+	return cliImportRecord("qwen", storage.Email, storage), nil
+}
+
+func cliImportRecord(providerName, email string, storage baseauth.TokenStorage) *provider.Auth {
+	fileName := providerName + ".json"
+	label := providerName
+	if email != "" {
+		fileName = fmt.Sprintf("%s-%s.json", providerName, email)
+		label = email
+	}
+	return &provider.Auth{
+		ID:       fileName,
+		Provider: providerName,
+		FileName: fileName,
+		Label:    label,
+		Storage:  storage,
+		Metadata: map[string]any{"email": email},
+	}
+}
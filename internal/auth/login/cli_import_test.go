@@ -0,0 +1,46 @@
+package login
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/auth/claude"
+)
+
+func TestDiscoverCLICredentials(t *testing.T) {
+	home := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(home, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".claude/.credentials.json", `{"claudeAiOauth":{"accessToken":"at","refreshToken":"rt","expiresAt":1760000000000}}`)
+	write(".claude.json", `{"oauthAccount":{"emailAddress":"a@example.com"}}`)
+	write(".gemini/oauth_creds.json", `{"access_token":"gat","token_type":"Bearer"}`)
+
+	results := DiscoverCLICredentials(home, nil)
+	if len(results) != 2 {
+		t.Fatalf("expected gemini and claude results, got %+v", results)
+	}
+	if results[0].Tool != "gemini" || results[0].Error == "" {
+		t.Errorf("gemini without refresh token should fail, got %+v", results[0])
+	}
+	c := results[1]
+	if c.Error != "" || c.Auth.FileName != "claude-a@example.com.json" {
+		t.Fatalf("unexpected claude result %+v", c)
+	}
+	storage, ok := c.Auth.Storage.(*claude.ClaudeTokenStorage)
+	if !ok || storage.RefreshToken != "rt" || storage.Expire == "" {
+		t.Errorf("unexpected claude storage %+v", c.Auth.Storage)
+	}
+
+	if got := DiscoverCLICredentials(home, []string{"codex"}); len(got) != 0 {
+		t.Errorf("expected no codex credentials, got %+v", got)
+	}
+}
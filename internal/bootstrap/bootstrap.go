@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/joho/godotenv"
+	certaccess "github.com/nghyane/llm-mux/internal/access/cert_access"
 	configaccess "github.com/nghyane/llm-mux/internal/access/config_access"
 	authlogin "github.com/nghyane/llm-mux/internal/auth/login"
 	"github.com/nghyane/llm-mux/internal/cli/env"
@@ -119,6 +120,7 @@ func Bootstrap(configPath string) (*Result, error) {
 
 	// Register built-in access providers
 	configaccess.Register()
+	certaccess.Register()
 
 	return &Result{
 		Config:         cfg,
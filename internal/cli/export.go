@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nghyane/llm-mux/internal/translator/export"
+	"github.com/spf13/cobra"
+	"github.com/tidwall/gjson"
+)
+
+var (
+	exportFrom string
+	exportTo   string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Convert a conversation to another API format",
+	Long: `Convert a conversation to another API format: openai, claude, gemini or ollama.
+
+The input, read from file or stdin, is a request body in the --from format or a
+transcript as returned by GET /v1/management/transcripts/{id}, whose format and
+response are used. The converted request is written to stdout.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var input []byte
+		var err error
+		if len(args) == 1 {
+			input, err = os.ReadFile(args[0])
+		} else {
+			input, err = io.ReadAll(os.Stdin)
+		}
+		if err != nil {
+			return err
+		}
+
+		from, request, reply := exportFrom, input, []byte(nil)
+		t := gjson.ParseBytes(input)
+		if d := t.Get("data"); d.IsObject() {
+			t = d
+		}
+		if t.Get("request").IsObject() && t.Get("format").Exists() {
+			from, request, reply = t.Get("format").String(), []byte(t.Get("request").Raw), []byte(t.Get("response").Raw)
+		}
+		if from == "" {
+			return fmt.Errorf("--from is required for request bodies")
+		}
+
+		out, err := export.Conversation(from, exportTo, request, reply)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(os.Stdout, string(out))
+		return err
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFrom, "from", "", "format of a request body input (openai, claude, gemini, ollama)")
+	exportCmd.Flags().StringVar(&exportTo, "to", "openai", "target format")
+	rootCmd.AddCommand(exportCmd)
+}
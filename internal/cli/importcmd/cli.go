@@ -0,0 +1,33 @@
+package importcmd
+
+import (
+	"github.com/nghyane/llm-mux/internal/cmd"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var cliCmd = &cobra.Command{
+	Use:       "cli [gemini|claude|codex|qwen]...",
+	Short:     "Import logins from the official Gemini, Claude Code, Codex and Qwen CLIs",
+	ValidArgs: []string{"gemini", "claude", "codex", "qwen"},
+	Args:      cobra.OnlyValidArgs,
+	Long: `Import existing logins from the official CLI tools, including refresh tokens.
+
+Reads ~/.gemini/oauth_creds.json, ~/.claude/.credentials.json, ~/.codex/auth.json
+and ~/.qwen/oauth_creds.json. Pass tool names to import only those.`,
+	RunE: func(c *cobra.Command, args []string) error {
+		cfgPath, _ := c.Flags().GetString("config")
+
+		cfg, err := config.LoadConfig(cfgPath)
+		if err != nil {
+			return err
+		}
+
+		cmd.DoCLIImport(cfg, args)
+		return nil
+	},
+}
+
+func init() {
+	ImportCmd.AddCommand(cliCmd)
+}
@@ -6,5 +6,5 @@ import "github.com/spf13/cobra"
 var ImportCmd = &cobra.Command{
 	Use:   "import",
 	Short: "Import credentials from external files",
-	Long:  `Import credentials from external files (e.g. Vertex AI service accounts).`,
+	Long:  `Import credentials from external files (e.g. Vertex AI service accounts) or official CLI tools.`,
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/bootstrap"
 	"github.com/nghyane/llm-mux/internal/cmd"
 	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/conversation"
 	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/usage"
 	"github.com/spf13/cobra"
@@ -46,6 +47,9 @@ initializes the token stores, and starts the HTTP server.`,
 		if cfg.Usage.DSN != "" {
 			initUsageBackend(cfg)
 		}
+		if cfg.Conversations.DSN != "" {
+			initConversationStore(cfg)
+		}
 
 		if err := log.ConfigureLogOutput(cfg.LoggingToFile); err != nil {
 			log.Fatalf("Failed to configure log output: %v", err)
@@ -78,6 +82,15 @@ func initUsageBackend(cfg *config.Config) {
 		BatchSize:     batchSize,
 		FlushInterval: flushInterval,
 		RetentionDays: retentionDays,
+
+		TranscriptRetentionDays: cfg.Transcripts.RetentionDays,
+
+		VacuumInterval:     parseUsageInterval("vacuum-interval", cfg.Usage.SQLite.VacuumInterval),
+		AnalyzeInterval:    parseUsageInterval("analyze-interval", cfg.Usage.SQLite.AnalyzeInterval),
+		CheckpointInterval: parseUsageInterval("checkpoint-interval", cfg.Usage.SQLite.CheckpointInterval),
+		WALAutocheckpoint:  cfg.Usage.SQLite.WALAutocheckpoint,
+		JournalSizeLimit:   cfg.Usage.SQLite.JournalSizeLimit,
+		Partition:          cfg.Usage.SQLite.Partition,
 	}
 	if initErr := usage.Initialize(backendCfg); initErr != nil {
 		log.Warnf("Failed to initialize usage backend: %v", initErr)
@@ -86,6 +99,37 @@ func initUsageBackend(cfg *config.Config) {
 	}
 }
 
+func parseUsageInterval(name, value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Warnf("Invalid usage.sqlite.%s %q, disabled", name, value)
+		return 0
+	}
+	return d
+}
+
+func initConversationStore(cfg *config.Config) {
+	parsed, err := config.ParseDSN(cfg.Conversations.DSN)
+	if err != nil {
+		log.Warnf("Invalid conversations DSN: %v", err)
+		return
+	}
+	if !parsed.IsSQLite() {
+		log.Warnf("Conversations require a sqlite:// DSN, disabled")
+		return
+	}
+	store, err := conversation.OpenSQLite(parsed.Path)
+	if err != nil {
+		log.Warnf("Failed to open conversation store: %v", err)
+		return
+	}
+	conversation.SetDefault(store)
+	log.Infof("Conversation store initialized: %s", parsed.Path)
+}
+
 func init() {
 	serveCmd.Flags().IntVarP(&servePort, "port", "p", 8317, "server port")
 	rootCmd.AddCommand(serveCmd)
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/nghyane/llm-mux/internal/auth/login"
+	"github.com/nghyane/llm-mux/internal/config"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/util"
+)
+
+// DoCLIImport converts the logins of the official Gemini CLI, Claude Code, Codex
+// CLI and Qwen CLI found in the user's home directory into llm-mux auth files.
+// An empty tools list imports every tool that has credentials.
+func DoCLIImport(cfg *config.Config, tools []string) {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	if resolved, errResolve := util.ResolveAuthDir(cfg.AuthDir); errResolve == nil {
+		cfg.AuthDir = resolved
+	}
+	home, errHome := os.UserHomeDir()
+	if errHome != nil {
+		log.Fatalf("cli-import: resolve home directory: %v", errHome)
+		return
+	}
+
+	store := login.GetTokenStore()
+	if setter, ok := store.(interface{ SetBaseDir(string) }); ok {
+		setter.SetBaseDir(cfg.AuthDir)
+	}
+
+	results := login.DiscoverCLICredentials(home, tools)
+	if len(results) == 0 {
+		fmt.Println("No official CLI credentials found.")
+		return
+	}
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Printf("%s: skipped %s: %s\n", r.Tool, r.Path, r.Error)
+			continue
+		}
+		path, errSave := store.Save(context.Background(), r.Auth)
+		if errSave != nil {
+			fmt.Printf("%s: save failed: %v\n", r.Tool, errSave)
+			continue
+		}
+		fmt.Printf("%s: imported %s -> %s\n", r.Tool, r.Path, path)
+	}
+}
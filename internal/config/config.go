@@ -9,10 +9,15 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/nghyane/llm-mux/internal/json"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
 	"gopkg.in/yaml.v3"
 )
 
@@ -34,6 +39,344 @@ type SDKConfig struct {
 	// ShowProviderPrefixes enables visual provider prefixes in model IDs (e.g., "[Gemini CLI] gemini-2.5-pro").
 	// This is purely cosmetic and does not affect actual model routing to providers.
 	ShowProviderPrefixes bool `yaml:"show-provider-prefixes" json:"show-provider-prefixes"`
+
+	// StreamPacing caps the rate at which streamed output is delivered to clients.
+	StreamPacing StreamPacingConfig `yaml:"stream-pacing,omitempty" json:"stream-pacing,omitempty"`
+
+	// StreamEnding shapes the terminal chunks of OpenAI chat completion streams.
+	StreamEnding StreamEndingConfig `yaml:"stream-ending,omitempty" json:"stream-ending,omitempty"`
+
+	// StreamEncoding controls the Unicode repair pass on streamed output.
+	StreamEncoding StreamEncodingConfig `yaml:"stream-encoding,omitempty" json:"stream-encoding,omitempty"`
+
+	// GenerationWatchdog bounds how long non-streaming chat completions wait.
+	GenerationWatchdog GenerationWatchdogConfig `yaml:"generation-watchdog,omitempty" json:"generation-watchdog,omitempty"`
+
+	// AutoContinue continues chat completions cut off at max_tokens.
+	AutoContinue AutoContinueConfig `yaml:"auto-continue,omitempty" json:"auto-continue,omitempty"`
+
+	// OfflineMode forbids external retrieval for selected client API keys.
+	OfflineMode OfflineModeConfig `yaml:"offline-mode,omitempty" json:"offline-mode,omitempty"`
+
+	// Transcripts persists the conversations of selected requests.
+	Transcripts TranscriptsConfig `yaml:"transcripts,omitempty" json:"transcripts,omitempty"`
+
+	// RequestCoalescing serves identical concurrent requests with one upstream call.
+	RequestCoalescing RequestCoalescingConfig `yaml:"request-coalescing,omitempty" json:"request-coalescing,omitempty"`
+
+	// Idempotency replays stored responses for repeated Idempotency-Key headers.
+	Idempotency IdempotencyConfig `yaml:"idempotency,omitempty" json:"idempotency,omitempty"`
+
+	// AdaptiveThinking picks the thinking budget of each request from its prompt.
+	AdaptiveThinking AdaptiveThinkingConfig `yaml:"adaptive-thinking,omitempty" json:"adaptive-thinking,omitempty"`
+
+	// UnknownParams reports or rejects request fields llm-mux does not translate.
+	UnknownParams UnknownParamsConfig `yaml:"unknown-params,omitempty" json:"unknown-params,omitempty"`
+}
+
+// UnknownParamsMode is how request fields llm-mux does not translate are
+// handled.
+type UnknownParamsMode string
+
+const (
+	// UnknownParamsIgnore drops them silently.
+	UnknownParamsIgnore UnknownParamsMode = "ignore"
+	// UnknownParamsHeader lists them in the X-LLMMUX-Ignored-Params response
+	// header.
+	UnknownParamsHeader UnknownParamsMode = "header"
+	// UnknownParamsReject fails the request with 400 naming them.
+	UnknownParamsReject UnknownParamsMode = "reject"
+)
+
+// UnknownParamsConfig surfaces request fields that are dropped because the
+// client's API format has no translation for them, such as a Responses API
+// "truncation" or an OpenAI "functions" list.
+type UnknownParamsConfig struct {
+	// Mode is "ignore" (default), "header" or "reject".
+	Mode UnknownParamsMode `yaml:"mode,omitempty" json:"mode,omitempty"`
+
+	// Keys limits the mode to these client API keys. Empty applies it to all
+	// clients.
+	Keys []string `yaml:"keys,omitempty" json:"keys,omitempty"`
+}
+
+// ModeFor returns the mode applying to requests of the given client API key.
+func (u UnknownParamsConfig) ModeFor(apiKey string) UnknownParamsMode {
+	if u.Mode == "" || (len(u.Keys) > 0 && !slices.Contains(u.Keys, apiKey)) {
+		return UnknownParamsIgnore
+	}
+	return u.Mode
+}
+
+// IdempotencyConfig stores the responses of non-streaming requests sent
+// with an Idempotency-Key header, so a retried request gets the stored
+// response instead of running again. Keys are scoped to the client API key.
+type IdempotencyConfig struct {
+	// Enabled turns Idempotency-Key handling on.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// TTL is how long responses are kept (e.g., "24h"). Default: "24h".
+	TTL string `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+
+	// MaxEntries caps the stored responses; when full, new keys are not
+	// protected until entries expire. Default: 10000.
+	MaxEntries int `yaml:"max-entries,omitempty" json:"max-entries,omitempty"`
+}
+
+// AdaptiveThinkingConfig chooses the thinking budget of requests that do not
+// set one, from the estimated prompt size and whether the prompt contains
+// code or the request offers tools. The chosen budget is still clamped to
+// the model's supported range.
+type AdaptiveThinkingConfig struct {
+	// Enabled turns adaptive budgets on.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// Curve maps estimated prompt tokens to a budget, interpolating linearly
+	// between points. Default: DefaultThinkingCurve.
+	Curve []ThinkingCurvePoint `yaml:"curve,omitempty" json:"curve,omitempty"`
+
+	// CodeMultiplier scales the budget of prompts containing code. Default: 1.5.
+	CodeMultiplier float64 `yaml:"code-multiplier,omitempty" json:"code-multiplier,omitempty"`
+
+	// ToolsMultiplier scales the budget of requests offering tools. Default: 1.25.
+	ToolsMultiplier float64 `yaml:"tools-multiplier,omitempty" json:"tools-multiplier,omitempty"`
+}
+
+// ThinkingCurvePoint is the budget for prompts of PromptTokens tokens.
+type ThinkingCurvePoint struct {
+	PromptTokens int `yaml:"prompt-tokens" json:"prompt-tokens"`
+	Budget       int `yaml:"budget" json:"budget"`
+}
+
+// DefaultThinkingCurve is the adaptive thinking curve used when none is configured.
+var DefaultThinkingCurve = []ThinkingCurvePoint{
+	{PromptTokens: 0, Budget: 1024},
+	{PromptTokens: 1000, Budget: 2048},
+	{PromptTokens: 8000, Budget: 8192},
+	{PromptTokens: 32000, Budget: 16384},
+	{PromptTokens: 128000, Budget: 24576},
+}
+
+// Budget returns the thinking budget for a prompt of promptTokens tokens.
+func (a AdaptiveThinkingConfig) Budget(promptTokens int, hasCode, hasTools bool) int {
+	curve := a.Curve
+	if len(curve) == 0 {
+		curve = DefaultThinkingCurve
+	}
+	curve = slices.Clone(curve)
+	slices.SortFunc(curve, func(x, y ThinkingCurvePoint) int { return x.PromptTokens - y.PromptTokens })
+
+	budget := float64(curve[len(curve)-1].Budget)
+	if promptTokens <= curve[0].PromptTokens {
+		budget = float64(curve[0].Budget)
+	} else {
+		for i := 1; i < len(curve); i++ {
+			lo, hi := curve[i-1], curve[i]
+			if promptTokens > hi.PromptTokens {
+				continue
+			}
+			frac := float64(promptTokens-lo.PromptTokens) / float64(hi.PromptTokens-lo.PromptTokens)
+			budget = float64(lo.Budget) + frac*float64(hi.Budget-lo.Budget)
+			break
+		}
+	}
+	if hasCode {
+		budget *= multiplierOrDefault(a.CodeMultiplier, 1.5)
+	}
+	if hasTools {
+		budget *= multiplierOrDefault(a.ToolsMultiplier, 1.25)
+	}
+	return int(budget + 0.5)
+}
+
+func multiplierOrDefault(v, def float64) float64 {
+	if v > 0 {
+		return v
+	}
+	return def
+}
+
+// RequestCoalescingConfig deduplicates identical non-streaming requests that
+// are in flight at the same time, such as client retry storms: one request
+// goes upstream and every caller receives its response. Requests are
+// identical when client API key, endpoint, model and JSON body, ignoring
+// key order and whitespace, match.
+type RequestCoalescingConfig struct {
+	// Enabled turns coalescing on.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// ExcludeKeys lists client API keys whose requests always go upstream.
+	ExcludeKeys []string `yaml:"exclude-keys,omitempty" json:"exclude-keys,omitempty"`
+}
+
+// AppliesTo reports whether requests of the given client API key are coalesced.
+func (r RequestCoalescingConfig) AppliesTo(apiKey string) bool {
+	return r.Enabled && !slices.Contains(r.ExcludeKeys, apiKey)
+}
+
+// TranscriptsConfig stores the final conversation of selected requests,
+// the client request and the assembled response, in the usage database.
+// Requires a usage DSN.
+type TranscriptsConfig struct {
+	// All persists every request.
+	All bool `yaml:"all,omitempty" json:"all,omitempty"`
+
+	// Keys lists the client API keys whose requests are persisted.
+	Keys []string `yaml:"keys,omitempty" json:"keys,omitempty"`
+
+	// Header lets clients mark single requests with the
+	// X-LLMMUX-Transcript header; its value, other than "true", is stored
+	// as the transcript label.
+	Header bool `yaml:"header,omitempty" json:"header,omitempty"`
+
+	// RetentionDays is how many days transcripts are kept.
+	// Default: usage.retention-days.
+	RetentionDays int `yaml:"retention-days,omitempty" json:"retention-days,omitempty"`
+}
+
+// AppliesTo reports whether requests of the given client API key are
+// persisted regardless of the header.
+func (t TranscriptsConfig) AppliesTo(apiKey string) bool {
+	return t.All || (apiKey != "" && slices.Contains(t.Keys, apiKey))
+}
+
+// OfflineModeConfig guarantees that no external retrieval happens on behalf
+// of the selected clients: requests using web search, grounding, URL fetching,
+// hosted file search, computer use, remote MCP or search models are rejected,
+// and such features are stripped from anything sent upstream.
+type OfflineModeConfig struct {
+	// All puts every client in offline mode.
+	All bool `yaml:"all,omitempty" json:"all,omitempty"`
+
+	// Keys lists the client API keys in offline mode.
+	Keys []string `yaml:"keys,omitempty" json:"keys,omitempty"`
+}
+
+// AppliesTo reports whether the given client API key is in offline mode.
+func (o OfflineModeConfig) AppliesTo(apiKey string) bool {
+	return o.All || (apiKey != "" && slices.Contains(o.Keys, apiKey))
+}
+
+// AutoContinueConfig transparently continues chat completions that finish
+// because of max_tokens: the partial output is fed back and the continuations
+// are stitched into a single response or stream.
+type AutoContinueConfig struct {
+	// MaxContinuations is the number of continuation requests per completion.
+	// 0 disables auto-continue.
+	MaxContinuations int `yaml:"max-continuations,omitempty" json:"max-continuations,omitempty"`
+
+	// Models limits auto-continue to glob-style model patterns. Empty: all models.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// Prompt is the user message asking the model to continue.
+	Prompt string `yaml:"prompt,omitempty" json:"prompt,omitempty"`
+}
+
+// GenerationWatchdogConfig returns the content generated so far when a
+// non-streaming chat completion runs longer than Timeout, instead of waiting
+// for it or failing with a 504. Watched requests are streamed from the
+// upstream so partial content is available.
+type GenerationWatchdogConfig struct {
+	// Timeout is the longest a non-streaming chat completion may run (e.g.
+	// "90s"). Empty or zero disables the watchdog.
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// FinishReason reported for partial results: "length" (default) or "timeout".
+	FinishReason string `yaml:"finish-reason,omitempty" json:"finish-reason,omitempty"`
+
+	// Continue keeps the upstream generating after the partial result is
+	// returned and caches the completed response for a follow-up
+	// GET /v1/chat/completions/{id}.
+	Continue bool `yaml:"continue,omitempty" json:"continue,omitempty"`
+
+	// Journal is the file continued generations are recorded in, so their
+	// results survive a restart and interrupted ones are reported as failed.
+	// Read at startup. Default: "watchdog-results.jsonl" in the logs directory.
+	Journal string `yaml:"journal,omitempty" json:"journal,omitempty"`
+}
+
+// Duration returns the parsed Timeout, or 0 when unset or invalid.
+func (w GenerationWatchdogConfig) Duration() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(w.Timeout))
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// StreamEncodingConfig configures the Unicode pass on streamed chunks. Invalid
+// UTF-8 and unpaired surrogate escapes are always replaced with U+FFFD.
+type StreamEncodingConfig struct {
+	// Normalize applies a Unicode normalization form: "nfc" or "nfkc".
+	// Default: no normalization.
+	Normalize string `yaml:"normalize,omitempty" json:"normalize,omitempty"`
+}
+
+// Usage placements for StreamEndingOptions.
+const (
+	StreamUsageAuto     = "auto"
+	StreamUsageFinish   = "finish"
+	StreamUsageSeparate = "separate"
+	StreamUsageOmit     = "omit"
+)
+
+// StreamEndingOptions controls how a chat completion stream ends. OpenAI-compatible
+// clients disagree about where usage is reported and whether [DONE] is sent.
+type StreamEndingOptions struct {
+	// Usage places token usage: "finish" on the finish_reason chunk, "separate"
+	// in a chunk with empty choices before [DONE] (OpenAI), or "omit".
+	// Default "auto": separate when stream_options.include_usage is true, omitted
+	// when it is false, and on the finish chunk when it is not set.
+	Usage string `yaml:"usage,omitempty" json:"usage,omitempty"`
+
+	// OmitDone drops the final "data: [DONE]" line.
+	OmitDone bool `yaml:"omit-done,omitempty" json:"omit-done,omitempty"`
+
+	// FinishDelta shapes the delta of the finish_reason chunk: "empty" (default)
+	// sends {}, "content" sends {"role":"assistant","content":""}.
+	FinishDelta string `yaml:"finish-delta,omitempty" json:"finish-delta,omitempty"`
+}
+
+// StreamEndingConfig holds the default stream ending options and per-client overrides.
+type StreamEndingConfig struct {
+	StreamEndingOptions `yaml:",inline"`
+
+	// Clients replaces the options per client API key.
+	Clients map[string]StreamEndingOptions `yaml:"clients,omitempty" json:"clients,omitempty"`
+}
+
+// For returns the stream ending options for the given client API key.
+func (s StreamEndingConfig) For(apiKey string) StreamEndingOptions {
+	if apiKey != "" {
+		if opts, ok := s.Clients[apiKey]; ok {
+			return opts
+		}
+	}
+	return s.StreamEndingOptions
+}
+
+// StreamPacingConfig smooths streaming output to a maximum tokens-per-second rate.
+// Token counts are estimated from chunk size, so pacing is approximate.
+type StreamPacingConfig struct {
+	// TokensPerSecond is the default delivery cap. 0 disables pacing.
+	TokensPerSecond int `yaml:"tokens-per-second,omitempty" json:"tokens-per-second,omitempty"`
+
+	// Burst is the number of tokens that may be delivered without delay.
+	// Default: equal to the effective tokens-per-second.
+	Burst int `yaml:"burst,omitempty" json:"burst,omitempty"`
+
+	// Clients overrides TokensPerSecond per client API key. 0 disables pacing for that key.
+	Clients map[string]int `yaml:"clients,omitempty" json:"clients,omitempty"`
+}
+
+// RateFor returns the tokens-per-second cap for the given client API key.
+func (p StreamPacingConfig) RateFor(apiKey string) int {
+	if apiKey != "" {
+		if rate, ok := p.Clients[apiKey]; ok {
+			return rate
+		}
+	}
+	return p.TokensPerSecond
 }
 
 // AccessConfig groups request authentication providers.
@@ -64,6 +407,10 @@ const (
 	// AccessProviderTypeConfigAPIKey is the built-in provider validating inline API keys.
 	AccessProviderTypeConfigAPIKey = "config-api-key"
 
+	// AccessProviderTypeClientCert is the built-in provider mapping verified
+	// TLS client certificates to identities.
+	AccessProviderTypeClientCert = "client-certificate"
+
 	// DefaultAccessProviderName is applied when no provider name is supplied.
 	DefaultAccessProviderName = "config-inline"
 )
@@ -103,11 +450,27 @@ type Config struct {
 	SDKConfig        `yaml:",inline"`
 	Port             int              `yaml:"port" json:"-"`
 	TLS              TLSConfig        `yaml:"tls" json:"tls"`
+	HTTP2            HTTP2Config      `yaml:"http2,omitempty" json:"-"`
 	RemoteManagement RemoteManagement `yaml:"remote-management" json:"-"`
 	AuthDir          string           `yaml:"auth-dir" json:"-"`
 	Debug            bool             `yaml:"debug" json:"debug"`
 	LoggingToFile    bool             `yaml:"logging-to-file" json:"logging-to-file"`
 
+	// AccessLog writes a sampled, structured log of client requests with timings.
+	AccessLog AccessLogConfig `yaml:"access-log,omitempty" json:"access-log,omitempty"`
+
+	// UnixSocket listens on a unix domain socket path instead of the TCP port.
+	// Ignored when the process is started via systemd socket activation.
+	UnixSocket string `yaml:"unix-socket,omitempty" json:"-"`
+
+	// UnixSocketMode is the octal file mode applied to UnixSocket (e.g., "0660"). Default: "0660".
+	UnixSocketMode string `yaml:"unix-socket-mode,omitempty" json:"-"`
+
+	// UnixSocketTrustLocal treats peers of the unix socket, including a
+	// systemd-activated one, as localhost clients for management and auth.
+	// Leave it off when a reverse proxy forwards remote clients to the socket.
+	UnixSocketTrustLocal bool `yaml:"unix-socket-trust-local,omitempty" json:"-"`
+
 	Usage            UsageConfig   `yaml:"usage" json:"usage"`
 	DisableCooling   bool          `yaml:"disable-cooling" json:"disable-cooling"`
 	RequestRetry     int           `yaml:"request-retry" json:"request-retry"`
@@ -130,6 +493,27 @@ type Config struct {
 	Payload             PayloadConfig       `yaml:"payload" json:"payload"`
 	Routing             RoutingConfig       `yaml:"routing,omitempty" json:"routing,omitempty"`
 
+	// HeaderPassthrough lists, per provider name, the client request headers
+	// forwarded upstream, e.g. claude: [anthropic-beta]. Client headers not
+	// listed are not forwarded.
+	HeaderPassthrough map[string][]string `yaml:"header-passthrough,omitempty" json:"header-passthrough,omitempty"`
+
+	// ToolResultGuard truncates or summarizes oversized tool results before translation.
+	ToolResultGuard ToolResultGuardConfig `yaml:"tool-result-guard,omitempty" json:"tool-result-guard,omitempty"`
+
+	// ToolLoopGuard stops agents repeating the same tool call with the same arguments.
+	ToolLoopGuard ToolLoopGuardConfig `yaml:"tool-loop-guard,omitempty" json:"tool-loop-guard,omitempty"`
+
+	// ResponseValidation detects anomalous upstream responses.
+	ResponseValidation ResponseValidationConfig `yaml:"response-validation,omitempty" json:"response-validation,omitempty"`
+
+	// PostProcessing rewrites the final text of responses per model.
+	PostProcessing []PostProcessRule `yaml:"post-processing,omitempty" json:"post-processing,omitempty"`
+
+	// SystemPrompts add a templated system prompt to requests per model
+	// family, e.g. to keep a model from answering in prose when given tools.
+	SystemPrompts []SystemPromptTemplate `yaml:"system-prompts,omitempty" json:"system-prompts,omitempty"`
+
 	// UseCanonicalTranslator enables the unified IR translator architecture (default: true).
 	UseCanonicalTranslator bool `yaml:"use-canonical-translator" json:"use-canonical-translator" default:"true"`
 
@@ -141,6 +525,292 @@ type Config struct {
 	// MaxResponseSize is the maximum response body size to read into memory in bytes.
 	// Set to 0 to use the default (100MB). Applies to non-streaming responses only.
 	MaxResponseSize int64 `yaml:"max-response-size" json:"max-response-size"`
+
+	// Compression configures negotiated gzip/brotli compression of responses to clients.
+	Compression CompressionConfig `yaml:"compression,omitempty" json:"compression,omitempty"`
+
+	// CORS controls cross-origin access for browser clients.
+	CORS CORSConfig `yaml:"cors,omitempty" json:"cors,omitempty"`
+
+	// Mirror sends copies of sampled requests to a test instance.
+	Mirror MirrorConfig `yaml:"mirror,omitempty" json:"mirror,omitempty"`
+
+	// EndpointAliases serve extra paths from existing endpoints, e.g. vanity
+	// paths per team or deprecated paths during a client migration.
+	EndpointAliases []EndpointAlias `yaml:"endpoint-aliases,omitempty" json:"endpoint-aliases,omitempty"`
+
+	// Dialer tunes direct upstream connections per provider name ("gemini-cli").
+	// The "*" entry applies to providers without their own entry.
+	Dialer map[string]DialerConfig `yaml:"dialer,omitempty" json:"dialer,omitempty"`
+
+	// Runtime tunes the Go garbage collector for high-throughput instances.
+	Runtime RuntimeConfig `yaml:"runtime,omitempty" json:"runtime,omitempty"`
+
+	// Conversations enables the /v1/conversations endpoints.
+	Conversations ConversationsConfig `yaml:"conversations,omitempty" json:"conversations,omitempty"`
+
+	// Canaries are test prompts sent on a schedule to detect silent upstream
+	// quality regressions.
+	Canaries []CanaryConfig `yaml:"canaries,omitempty" json:"canaries,omitempty"`
+
+	// ModelRefresh re-fetches model lists from upstreams that publish them.
+	ModelRefresh ModelRefreshConfig `yaml:"model-refresh,omitempty" json:"model-refresh,omitempty"`
+
+	// envPlaceholders maps the paths of env-expanded values to their ${VAR} source text.
+	envPlaceholders map[string]envPlaceholder
+}
+
+// DialerConfig controls address family selection, DNS and TLS session reuse for
+// upstream connections.
+type DialerConfig struct {
+	// IPPreference is "prefer-ipv4", "prefer-ipv6", "ipv4-only" or "ipv6-only".
+	// Empty keeps the resolver's order.
+	IPPreference string `yaml:"ip-preference,omitempty" json:"ip-preference,omitempty"`
+
+	// HappyEyeballsDelay is how long the preferred family is tried before the
+	// other one is raced against it (e.g., "300ms"). "-1s" disables racing.
+	HappyEyeballsDelay string `yaml:"happy-eyeballs-delay,omitempty" json:"happy-eyeballs-delay,omitempty"`
+
+	// Hosts maps upstream hostnames to fixed IP addresses.
+	Hosts map[string]string `yaml:"hosts,omitempty" json:"hosts,omitempty"`
+
+	// DNSCacheTTL caches DNS answers in process for this long (e.g., "60s").
+	// Empty or zero resolves on every new connection.
+	DNSCacheTTL string `yaml:"dns-cache-ttl,omitempty" json:"dns-cache-ttl,omitempty"`
+
+	// TLSSessionCacheSize is how many TLS sessions are kept for resumption,
+	// which skips the full handshake on new connections. Zero keeps the
+	// default (256); a negative value disables resumption.
+	TLSSessionCacheSize int `yaml:"tls-session-cache-size,omitempty" json:"tls-session-cache-size,omitempty"`
+}
+
+// RuntimeConfig overrides garbage collector settings without restarting with GOGC/GOMEMLIMIT.
+type RuntimeConfig struct {
+	// GCPercent sets GOGC. Unset keeps the environment or Go default (100).
+	GCPercent *int `yaml:"gc-percent,omitempty" json:"gc-percent,omitempty"`
+
+	// MemoryLimit sets GOMEMLIMIT (e.g., "3GiB"). Empty keeps the default.
+	MemoryLimit string `yaml:"memory-limit,omitempty" json:"memory-limit,omitempty"`
+
+	// Ballast reserves an untouched heap allocation (e.g., "512MiB") so the GC
+	// runs less often at low live heap sizes. Empty disables it.
+	Ballast string `yaml:"ballast,omitempty" json:"ballast,omitempty"`
+
+	// Overload sheds low-priority requests while the process is overloaded.
+	Overload OverloadConfig `yaml:"overload,omitempty" json:"overload,omitempty"`
+}
+
+// OverloadConfig sheds requests by priority, with 503 and Retry-After, when
+// goroutine count or scheduler latency pass their thresholds: low-priority
+// requests first, then normal-priority ones; high-priority requests are
+// always served.
+type OverloadConfig struct {
+	// Enabled turns on load monitoring and shedding.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// MaxGoroutines is the goroutine count at which low-priority requests
+	// are shed. 0 does not check it.
+	MaxGoroutines int `yaml:"max-goroutines,omitempty" json:"max-goroutines,omitempty"`
+
+	// MaxSchedulerLatency is the smoothed scheduling delay (e.g., "50ms") at
+	// which low-priority requests are shed. Default: "50ms".
+	MaxSchedulerLatency string `yaml:"max-scheduler-latency,omitempty" json:"max-scheduler-latency,omitempty"`
+
+	// CriticalFactor is the multiple of a threshold past which
+	// normal-priority requests are shed too. Default: 1.5.
+	CriticalFactor float64 `yaml:"critical-factor,omitempty" json:"critical-factor,omitempty"`
+
+	// RetryAfter is the delay suggested to shed clients (e.g., "5s"). Default: "5s".
+	RetryAfter string `yaml:"retry-after,omitempty" json:"retry-after,omitempty"`
+}
+
+// CompressionConfig controls response compression negotiated via Accept-Encoding.
+type CompressionConfig struct {
+	// Enable turns on gzip/brotli compression for clients that accept it.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// MinSize is the smallest non-streaming response compressed, in bytes. Default: 1024.
+	MinSize int `yaml:"min-size,omitempty" json:"min-size,omitempty"`
+
+	// Level is the encoder compression level. 0 uses the encoder default.
+	Level int `yaml:"level,omitempty" json:"level,omitempty"`
+
+	// SSE also compresses server-sent event streams. Each event is flushed through
+	// the encoder, so latency is unchanged but the ratio is lower than for bodies.
+	SSE bool `yaml:"sse,omitempty" json:"sse,omitempty"`
+}
+
+// CORSConfig controls the CORS headers of API responses. Without it any
+// origin may call the API, without credentials.
+type CORSConfig struct {
+	// Disable sends no CORS headers, so browsers cannot call the API cross-origin.
+	Disable bool `yaml:"disable,omitempty" json:"disable,omitempty"`
+
+	// AllowedOrigins lists origins allowed to call the API, such as
+	// "https://app.example.com" or "https://*.example.com". "*" allows any.
+	// Default: ["*"].
+	AllowedOrigins []string `yaml:"allowed-origins,omitempty" json:"allowed-origins,omitempty"`
+
+	// AllowedHeaders lists request headers browsers may send. Default: the
+	// headers a preflight request asks for when AllowedOrigins lists origins,
+	// otherwise "*", which excludes Authorization.
+	AllowedHeaders []string `yaml:"allowed-headers,omitempty" json:"allowed-headers,omitempty"`
+
+	// ExposedHeaders lists response headers readable by browser scripts.
+	// Default: Retry-After, X-Request-ID and the X-LLMMUX-* response headers.
+	ExposedHeaders []string `yaml:"exposed-headers,omitempty" json:"exposed-headers,omitempty"`
+
+	// AllowCredentials lets browsers send cookies and TLS client
+	// certificates. The request origin is echoed instead of "*". It requires
+	// AllowedOrigins to list origins, as echoing any origin with credentials
+	// would let every website read credentialed responses.
+	AllowCredentials bool `yaml:"allow-credentials,omitempty" json:"allow-credentials,omitempty"`
+
+	// MaxAge is how long browsers cache preflight results (e.g., "10m"). Default: "10m".
+	MaxAge string `yaml:"max-age,omitempty" json:"max-age,omitempty"`
+}
+
+// Validate rejects AllowCredentials unless AllowedOrigins lists origins.
+func (c CORSConfig) Validate() error {
+	if c.Disable || !c.AllowCredentials {
+		return nil
+	}
+	if len(c.AllowedOrigins) == 0 || slices.Contains(c.AllowedOrigins, "*") {
+		return errors.New(`allow-credentials requires allowed-origins to list origins instead of "*"`)
+	}
+	return nil
+}
+
+// MirrorConfig sends an asynchronous copy of a sample of client requests to
+// another llm-mux or OpenAI-compatible endpoint, to validate a staging
+// version against production traffic. Mirror responses are discarded.
+type MirrorConfig struct {
+	// URL is the base URL of the mirror; the request path and query are
+	// appended. Empty disables mirroring.
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+
+	// SampleRate is the fraction of requests mirrored, from 0 to 1. Default: 1.
+	SampleRate float64 `yaml:"sample-rate,omitempty" json:"sample-rate,omitempty"`
+
+	// APIKey is sent to the mirror as a bearer token. Client credentials
+	// are always stripped.
+	APIKey string `yaml:"api-key,omitempty" json:"api-key,omitempty"`
+
+	// Paths lists the path prefixes mirrored. Default: all POST requests
+	// outside the management API.
+	Paths []string `yaml:"paths,omitempty" json:"paths,omitempty"`
+
+	// Timeout bounds each mirrored request (e.g., "60s"). Default: "60s".
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// MaxInFlight is the number of mirrored requests sent at once; further
+	// copies are dropped. Default: 16.
+	MaxInFlight int `yaml:"max-in-flight,omitempty" json:"max-in-flight,omitempty"`
+}
+
+// EndpointAlias serves requests for Path from the endpoint at Target, as if
+// they were sent there; the body must be in the target endpoint's format.
+type EndpointAlias struct {
+	// Path is the path clients call (e.g., "/team-a/chat"). A trailing "/*"
+	// matches every path below it, and the matched rest is appended to a
+	// Target ending in "/*" too.
+	Path string `yaml:"path" json:"path"`
+
+	// Target is the endpoint serving the requests (e.g., "/v1/chat/completions").
+	Target string `yaml:"target" json:"target"`
+
+	// Redirect answers with a 308 redirect to Target instead of serving the
+	// request in place, for clients that follow redirects.
+	Redirect bool `yaml:"redirect,omitempty" json:"redirect,omitempty"`
+
+	// Deprecated marks Path as deprecated: responses carry a Deprecation
+	// header and callers are logged once and counted in the management API.
+	Deprecated bool `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+
+	// Sunset is the date Path is removed (e.g., "2027-01-31"), sent in the
+	// Sunset header of deprecated paths.
+	Sunset string `yaml:"sunset,omitempty" json:"sunset,omitempty"`
+
+	// Link is a URL documenting the migration, sent in a Link header with
+	// rel="deprecation".
+	Link string `yaml:"link,omitempty" json:"link,omitempty"`
+}
+
+// ValidateEndpointAliases checks that aliases have absolute paths, that
+// wildcards match on both sides and that no path is aliased twice.
+func ValidateEndpointAliases(aliases []EndpointAlias) error {
+	paths := make(map[string]bool, len(aliases))
+	for i, a := range aliases {
+		switch {
+		case !strings.HasPrefix(a.Path, "/"):
+			return fmt.Errorf("endpoint-aliases[%d]: path must start with /", i)
+		case !strings.HasPrefix(a.Target, "/"):
+			return fmt.Errorf("endpoint-aliases[%d]: target must start with /", i)
+		case a.Path == a.Target:
+			return fmt.Errorf("endpoint-aliases[%d]: path and target are the same", i)
+		case strings.HasSuffix(a.Path, "/*") != strings.HasSuffix(a.Target, "/*"):
+			return fmt.Errorf("endpoint-aliases[%d]: path and target must both end in /* or neither", i)
+		case paths[a.Path]:
+			return fmt.Errorf("endpoint-aliases[%d]: duplicate path %q", i, a.Path)
+		}
+		paths[a.Path] = true
+		if a.Sunset != "" {
+			if _, err := time.Parse(time.DateOnly, a.Sunset); err != nil {
+				return fmt.Errorf("endpoint-aliases[%d]: sunset must be a date (YYYY-MM-DD)", i)
+			}
+		}
+	}
+	return nil
+}
+
+// CanaryConfig is a test prompt sent through the normal request path on a
+// schedule. A run fails when the request errors, the answer is empty, or
+// the answer does not match Expect or Schema; a failing provider is
+// reported degraded and moved behind healthy ones for the model until a
+// later run passes.
+type CanaryConfig struct {
+	// Name identifies the canary in logs and the management API. Default:
+	// the model name.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+
+	// Model is the model the prompt is sent to.
+	Model string `yaml:"model" json:"model"`
+
+	// Providers restricts the canary to these providers. Default: every
+	// provider serving the model, each checked separately.
+	Providers []string `yaml:"providers,omitempty" json:"providers,omitempty"`
+
+	// Prompt is sent as the single user message.
+	Prompt string `yaml:"prompt" json:"prompt"`
+
+	// Expect is a substring the answer must contain, compared case-insensitively.
+	Expect string `yaml:"expect,omitempty" json:"expect,omitempty"`
+
+	// Schema is a JSON schema the answer must be a JSON document of. The
+	// type, properties, required, items and enum keywords are checked.
+	Schema map[string]any `yaml:"schema,omitempty" json:"schema,omitempty"`
+
+	// MaxTokens bounds the answer. Default: 256.
+	MaxTokens int `yaml:"max-tokens,omitempty" json:"max-tokens,omitempty"`
+
+	// Interval between runs (e.g., "10m"). Default: "15m".
+	Interval string `yaml:"interval,omitempty" json:"interval,omitempty"`
+
+	// Timeout bounds each run (e.g., "60s"). Default: "60s".
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// ModelRefreshConfig schedules re-fetching the model lists, with their
+// context windows and capabilities, of providers that publish them
+// (gemini, vertex, gemini-cli, aistudio, antigravity). Without it they are
+// only fetched when an auth is loaded or changes.
+type ModelRefreshConfig struct {
+	// Interval between refreshes (e.g., "6h"). Default: "6h". "0" disables.
+	Interval string `yaml:"interval,omitempty" json:"interval,omitempty"`
+
+	// Jitter spreads refreshes by up to this fraction of Interval in either
+	// direction. Default: 0.1.
+	Jitter float64 `yaml:"jitter,omitempty" json:"jitter,omitempty"`
 }
 
 // TLSConfig holds HTTPS server settings.
@@ -148,11 +818,58 @@ type TLSConfig struct {
 	Enable bool   `yaml:"enable" json:"enable"`
 	Cert   string `yaml:"cert" json:"cert"`
 	Key    string `yaml:"key" json:"key"`
+
+	// ClientCA is a PEM bundle of CAs that sign client certificates. When
+	// set, clients are asked for a certificate, which must verify against it.
+	ClientCA string `yaml:"client-ca,omitempty" json:"client-ca,omitempty"`
+
+	// ClientAuth is "require" (every client presents a certificate) or
+	// "optional" (clients without one may use API keys). Default: "require".
+	ClientAuth string `yaml:"client-auth,omitempty" json:"client-auth,omitempty"`
+}
+
+// HTTP2Config holds server-side HTTP/2 settings. Changes require a restart.
+type HTTP2Config struct {
+	// Disable turns off HTTP/2 on the TLS listener (it is negotiated via ALPN by default).
+	Disable bool `yaml:"disable,omitempty" json:"disable,omitempty"`
+
+	// H2C serves cleartext HTTP/2 with prior knowledge alongside HTTP/1.1,
+	// for deployments behind an internal load balancer that terminates TLS.
+	H2C bool `yaml:"h2c,omitempty" json:"h2c,omitempty"`
+
+	// MaxConcurrentStreams limits concurrent streams per connection. Default: 250.
+	MaxConcurrentStreams int `yaml:"max-concurrent-streams,omitempty" json:"max-concurrent-streams,omitempty"`
+
+	// MaxReadFrameSize is the largest frame the server will read (16KB-16MB). Default: 1MB.
+	MaxReadFrameSize int `yaml:"max-read-frame-size,omitempty" json:"max-read-frame-size,omitempty"`
+
+	// MaxReceiveBufferPerStream and MaxReceiveBufferPerConnection set flow-control windows.
+	MaxReceiveBufferPerStream     int `yaml:"max-receive-buffer-per-stream,omitempty" json:"max-receive-buffer-per-stream,omitempty"`
+	MaxReceiveBufferPerConnection int `yaml:"max-receive-buffer-per-connection,omitempty" json:"max-receive-buffer-per-connection,omitempty"`
+
+	// PingTimeout closes connections whose keepalive ping is not answered in time (e.g., "15s").
+	PingTimeout string `yaml:"ping-timeout,omitempty" json:"ping-timeout,omitempty"`
 }
 
 // RemoteManagement holds management API configuration under 'remote-management'.
 type RemoteManagement struct {
 	AllowRemote bool `yaml:"allow-remote"`
+
+	// Pprof exposes runtime profiling under /v1/management/debug/pprof.
+	Pprof PprofConfig `yaml:"pprof,omitempty"`
+}
+
+// PprofConfig controls the profiling endpoints of the management API.
+type PprofConfig struct {
+	// Enable mounts the net/http/pprof handlers. Default: false.
+	Enable bool `yaml:"enable"`
+
+	// BlockProfileRate samples one blocking event per this many nanoseconds
+	// blocked (runtime.SetBlockProfileRate). 0 disables the block profile.
+	BlockProfileRate int `yaml:"block-profile-rate,omitempty"`
+
+	// MutexProfileFraction samples 1/n mutex contention events. 0 disables the mutex profile.
+	MutexProfileFraction int `yaml:"mutex-profile-fraction,omitempty"`
 }
 
 // QuotaExceeded defines the behavior when API quota limits are exceeded.
@@ -178,6 +895,99 @@ type UsageConfig struct {
 
 	// RetentionDays defines how many days of records to keep. Default: 30.
 	RetentionDays int `yaml:"retention-days" json:"retention-days"`
+
+	// Reconciliation compares recorded usage against provider usage APIs.
+	Reconciliation UsageReconciliationConfig `yaml:"reconciliation,omitempty" json:"reconciliation,omitempty"`
+
+	// SQLite tunes maintenance of a sqlite:// usage database.
+	SQLite UsageSQLiteConfig `yaml:"sqlite,omitempty" json:"sqlite,omitempty"`
+
+	// PayloadAlerts warns when translated requests approach provider size limits.
+	PayloadAlerts PayloadAlertsConfig `yaml:"payload-alerts,omitempty" json:"payload-alerts,omitempty"`
+}
+
+// PayloadAlertsConfig logs a warning when a percentile of a provider's
+// translated request sizes over a sliding window exceeds the provider's
+// request size limit. Built-in limits cover claude (32 MiB) and gemini
+// (20 MiB).
+type PayloadAlertsConfig struct {
+	// Limits adds to or overrides the request size limits in bytes, keyed by
+	// provider name. A limit of 0 removes a built-in one.
+	Limits map[string]int64 `yaml:"limits,omitempty" json:"limits,omitempty"`
+
+	// Percentile of the request sizes compared against the limit. Default: 95.
+	Percentile float64 `yaml:"percentile,omitempty" json:"percentile,omitempty"`
+
+	// Window is the sliding window of requests considered (e.g., "15m").
+	// A provider is alerted on at most once per window. Default: "15m".
+	Window string `yaml:"window,omitempty" json:"window,omitempty"`
+
+	// MinSamples is the number of requests in the window before alerting. Default: 20.
+	MinSamples int `yaml:"min-samples,omitempty" json:"min-samples,omitempty"`
+}
+
+// UsageSQLiteConfig schedules maintenance of the SQLite usage database.
+type UsageSQLiteConfig struct {
+	// VacuumInterval is how often the database is rebuilt with VACUUM to
+	// return free pages (e.g., "168h"). Empty disables.
+	VacuumInterval string `yaml:"vacuum-interval,omitempty" json:"vacuum-interval,omitempty"`
+
+	// AnalyzeInterval is how often query planner statistics are refreshed
+	// with ANALYZE (e.g., "24h"). Empty disables.
+	AnalyzeInterval string `yaml:"analyze-interval,omitempty" json:"analyze-interval,omitempty"`
+
+	// CheckpointInterval is how often the WAL is checkpointed and truncated
+	// (e.g., "1h"). Empty leaves checkpoints to SQLite.
+	CheckpointInterval string `yaml:"checkpoint-interval,omitempty" json:"checkpoint-interval,omitempty"`
+
+	// WALAutocheckpoint is the WAL size in pages that triggers an automatic
+	// checkpoint. Default: 1000 (SQLite default).
+	WALAutocheckpoint int `yaml:"wal-autocheckpoint,omitempty" json:"wal-autocheckpoint,omitempty"`
+
+	// JournalSizeLimit caps the WAL file size in bytes kept after a checkpoint.
+	// 0 leaves the WAL at its largest size.
+	JournalSizeLimit int64 `yaml:"journal-size-limit,omitempty" json:"journal-size-limit,omitempty"`
+
+	// Partition splits usage records into per-period database files next to
+	// the main database. "month" writes usage-YYYY-MM.db files; empty keeps
+	// them in the main database.
+	Partition string `yaml:"partition,omitempty" json:"partition,omitempty"`
+}
+
+// ConversationsConfig persists Responses API conversations.
+type ConversationsConfig struct {
+	// DSN is the conversation database, e.g. "sqlite://~/.config/llm-mux/conversations.db".
+	// Only SQLite is supported. Empty disables the conversations API.
+	DSN string `yaml:"dsn" json:"dsn"`
+}
+
+// UsageReconciliationConfig fetches provider-reported usage so drift against
+// llm-mux's own records can be reported. Requires a usage DSN.
+type UsageReconciliationConfig struct {
+	// Interval between fetches (e.g., "24h"). Default: "24h".
+	Interval string `yaml:"interval,omitempty" json:"interval,omitempty"`
+
+	// LookbackDays is how many days, including today, each fetch refreshes. Default: 3.
+	LookbackDays int `yaml:"lookback-days,omitempty" json:"lookback-days,omitempty"`
+
+	// Sources lists the provider usage APIs to query. Empty disables reconciliation.
+	Sources []UsageReportSource `yaml:"sources,omitempty" json:"sources,omitempty"`
+}
+
+// UsageReportSource is one provider usage API queried during reconciliation.
+type UsageReportSource struct {
+	// Type is "anthropic" (Admin API usage report) or "openai" (organization usage API).
+	Type string `yaml:"type" json:"type"`
+
+	// Provider is the llm-mux provider the report is compared against.
+	// Default: "claude" for anthropic, "openai" for openai.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+
+	// AdminKey is the organization admin API key used to read usage.
+	AdminKey string `yaml:"admin-key" json:"admin-key"`
+
+	// BaseURL overrides the usage endpoint URL.
+	BaseURL string `yaml:"base-url,omitempty" json:"base-url,omitempty"`
 }
 
 // AmpModelMapping defines a model name mapping for Amp CLI requests.
@@ -210,6 +1020,11 @@ type PayloadConfig struct {
 type PayloadRule struct {
 	Models []PayloadModelRule `yaml:"models" json:"models"`
 	Params map[string]any     `yaml:"params" json:"params"`
+
+	// APIKeys applies the rule only to these client API keys. Empty applies
+	// it to all clients. Client rules take precedence over rules for all
+	// clients.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
 }
 
 // PayloadModelRule ties a model name pattern to a specific translator protocol.
@@ -218,6 +1033,37 @@ type PayloadModelRule struct {
 	Protocol string `yaml:"protocol" json:"protocol"`
 }
 
+// Validate checks rule params with enumerated values, currently Gemini
+// safetySettings categories and thresholds, and normalizes their case.
+func (p PayloadConfig) Validate() error {
+	check := func(kind string, rules []PayloadRule) error {
+		for i, rule := range rules {
+			v, ok := rule.Params["safetySettings"]
+			if !ok {
+				continue
+			}
+			raw, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("payload.%s[%d].safetySettings: %w", kind, i, err)
+			}
+			settings, err := ir.ParseSafetySettings(gjson.ParseBytes(raw))
+			if err != nil {
+				return fmt.Errorf("payload.%s[%d]: %w", kind, i, err)
+			}
+			normalized := make([]map[string]string, len(settings))
+			for j, st := range settings {
+				normalized[j] = map[string]string{"category": st.Category, "threshold": st.Threshold}
+			}
+			rule.Params["safetySettings"] = normalized
+		}
+		return nil
+	}
+	if err := check("default", p.Default); err != nil {
+		return err
+	}
+	return check("override", p.Override)
+}
+
 // RoutingConfig defines provider routing and priority settings.
 type RoutingConfig struct {
 	// ProviderPriority maps provider names to their routing priority.
@@ -237,11 +1083,522 @@ type RoutingConfig struct {
 	// Example: "claude-opus-4-5" -> ["claude-sonnet-4-5", "gpt-4o"]
 	Fallbacks map[string][]string `yaml:"fallbacks,omitempty" json:"fallbacks,omitempty"`
 
+	// LatencySLOs defines first-token latency objectives per model pattern.
+	// Providers that repeatedly violate an objective are demoted in routing
+	// for the configured duration.
+	LatencySLOs []LatencySLO `yaml:"latency-slos,omitempty" json:"latency-slos,omitempty"`
+
+	// ErrorRateDisable takes auths out of rotation whose requests keep
+	// failing with auth errors, such as 401s or 400s from revoked scopes.
+	ErrorRateDisable ErrorRateDisableConfig `yaml:"error-rate-disable,omitempty" json:"error-rate-disable,omitempty"`
+
+	// ConcurrencyLimits caps concurrent requests per auth for matching models.
+	// Busy auths are skipped; when all are busy requests queue for a free slot.
+	ConcurrencyLimits []ConcurrencyLimit `yaml:"concurrency-limits,omitempty" json:"concurrency-limits,omitempty"`
+
+	// AuthFilters restrict matching requests to auths carrying the given tags,
+	// optionally only for specific client API keys.
+	AuthFilters []AuthFilter `yaml:"auth-filters,omitempty" json:"auth-filters,omitempty"`
+
+	// RouteBy selects the provider ordering policy. "cost" prefers the cheapest
+	// provider according to Pricing; empty keeps performance-based ordering.
+	RouteBy string `yaml:"route-by,omitempty" json:"route-by,omitempty"`
+
+	// Pricing lists per-model prices used by cost-based routing and savings estimates.
+	Pricing []ModelPrice `yaml:"pricing,omitempty" json:"pricing,omitempty"`
+
+	// Timezone is the IANA zone used by Schedules and for resetting QuotaBudgets
+	// and SpendLimits (e.g., "Europe/Berlin"). Default: the server's local time zone.
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+
+	// Schedules prefer or avoid providers during time windows. The first active
+	// schedule matching the model applies.
+	Schedules []RoutingSchedule `yaml:"schedules,omitempty" json:"schedules,omitempty"`
+
+	// QuotaBudgets cap daily usage per auth; exhausted auths are skipped until midnight.
+	QuotaBudgets []QuotaBudget `yaml:"quota-budgets,omitempty" json:"quota-budgets,omitempty"`
+
+	// SpendLimits cap monthly tokens or cost per auth; blocked auths are skipped until the next month.
+	SpendLimits []SpendLimit `yaml:"spend-limits,omitempty" json:"spend-limits,omitempty"`
+
+	// RequestPriority controls the X-LLMMUX-Priority header and premium auth reservation.
+	RequestPriority RequestPriorityConfig `yaml:"request-priority,omitempty" json:"request-priority,omitempty"`
+
+	// AliasConflicts controls model IDs exposed by more than one openai provider.
+	AliasConflicts AliasConflictConfig `yaml:"alias-conflicts,omitempty" json:"alias-conflicts,omitempty"`
+
+	// Deprecations replaces retired models that no auth serves anymore.
+	Deprecations DeprecationConfig `yaml:"deprecations,omitempty" json:"deprecations,omitempty"`
+
+	// SnapshotPinning keeps multi-turn conversations on the upstream snapshot
+	// their first turn was served by.
+	SnapshotPinning SnapshotPinningConfig `yaml:"snapshot-pinning,omitempty" json:"snapshot-pinning,omitempty"`
+
 	hasAliases   bool
 	hasFallbacks bool
 	hasPriority  bool
 }
 
+// AccessLogConfig controls the structured access log: one JSON line per
+// sampled client request with its timing breakdown.
+type AccessLogConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// SampleRate is the fraction of requests logged, from 0 to 1. Default: 1.
+	SampleRate float64 `yaml:"sample-rate,omitempty" json:"sample-rate,omitempty"`
+
+	// File is the log path. Default: "access.jsonl" in the logs directory.
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+
+	// MaxSizeMB is the size at which the file is rotated. Default: 100.
+	MaxSizeMB int `yaml:"max-size-mb,omitempty" json:"max-size-mb,omitempty"`
+
+	// MaxBackups is the number of rotated files kept. Default: 5.
+	MaxBackups int `yaml:"max-backups,omitempty" json:"max-backups,omitempty"`
+
+	// MaxAgeDays removes rotated files older than this. 0 keeps them.
+	MaxAgeDays int `yaml:"max-age-days,omitempty" json:"max-age-days,omitempty"`
+
+	// Compress gzips rotated files.
+	Compress bool `yaml:"compress,omitempty" json:"compress,omitempty"`
+}
+
+// ToolResultGuardConfig limits how much tool output is forwarded to providers.
+type ToolResultGuardConfig struct {
+	// MaxBytes is the largest tool result forwarded unchanged. 0 disables the guard.
+	MaxBytes int `yaml:"max-bytes,omitempty" json:"max-bytes,omitempty"`
+
+	// Strategy is "truncate" (keep head and tail) or "summarize". Default: "truncate".
+	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
+	// HeadBytes and TailBytes are kept around the truncation marker.
+	// Default: two thirds head, one third tail of MaxBytes.
+	HeadBytes int `yaml:"head-bytes,omitempty" json:"head-bytes,omitempty"`
+	TailBytes int `yaml:"tail-bytes,omitempty" json:"tail-bytes,omitempty"`
+
+	// SummaryModel is the model used by the summarize strategy.
+	SummaryModel string `yaml:"summary-model,omitempty" json:"summary-model,omitempty"`
+
+	// SummaryTimeout bounds each summarization call (e.g., "30s"). Default: "30s".
+	SummaryTimeout string `yaml:"summary-timeout,omitempty" json:"summary-timeout,omitempty"`
+}
+
+// ToolLoopGuardConfig detects conversations in which the assistant keeps making
+// the same tool calls with the same arguments.
+type ToolLoopGuardConfig struct {
+	// Threshold is the number of consecutive identical tool-call turns treated
+	// as a loop. 0 disables the guard.
+	Threshold int `yaml:"threshold,omitempty" json:"threshold,omitempty"`
+
+	// Action is "notice" (add a system message asking the model to change
+	// course) or "error" (reject the request). Default: "notice".
+	Action string `yaml:"action,omitempty" json:"action,omitempty"`
+
+	// Notice replaces the default system message.
+	Notice string `yaml:"notice,omitempty" json:"notice,omitempty"`
+}
+
+// ResponseValidationConfig detects anomalous upstream responses: empty content
+// with nonzero output tokens, truncated JSON and streams repeating a chunk.
+// Anomalies are logged with provider, model and auth.
+type ResponseValidationConfig struct {
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// Retry retries anomalous non-streaming responses on another auth or
+	// provider and cuts off streams repeating a chunk. The auth cools down
+	// as after a 502.
+	Retry bool `yaml:"retry,omitempty" json:"retry,omitempty"`
+
+	// RepeatThreshold is the number of identical consecutive stream chunks
+	// treated as anomalous. Default: 50.
+	RepeatThreshold int `yaml:"repeat-threshold,omitempty" json:"repeat-threshold,omitempty"`
+}
+
+// PostProcessRule rewrites the text content of responses for matching models,
+// streaming or not. Tool calls and reasoning are left untouched.
+type PostProcessRule struct {
+	// Models are glob-style model patterns (e.g., "gpt-*"). The first rule
+	// matching the requested model applies.
+	Models []string `yaml:"models" json:"models"`
+
+	// Strip removes every occurrence of these strings, e.g. provider
+	// watermarks or disclaimers.
+	Strip []string `yaml:"strip,omitempty" json:"strip,omitempty"`
+
+	// Prefix and Suffix are added before and after the text content.
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+	Suffix string `yaml:"suffix,omitempty" json:"suffix,omitempty"`
+
+	// Wrap surrounds the text content with a template containing {{content}},
+	// inside Prefix and Suffix.
+	Wrap string `yaml:"wrap,omitempty" json:"wrap,omitempty"`
+}
+
+// Around returns the text placed before and after the content: Prefix and
+// the part of Wrap before {{content}}, and the rest of Wrap and Suffix.
+func (r PostProcessRule) Around() (before, after string) {
+	before, after = r.Prefix, r.Suffix
+	if r.Wrap != "" {
+		head, tail, _ := strings.Cut(r.Wrap, postProcessContent)
+		before += head
+		after = tail + after
+	}
+	return before, after
+}
+
+const postProcessContent = "{{content}}"
+
+// ValidatePostProcessing checks that every rule targets models and that wrap
+// templates contain {{content}}.
+func ValidatePostProcessing(rules []PostProcessRule) error {
+	for i, r := range rules {
+		if len(r.Models) == 0 {
+			return fmt.Errorf("post-processing[%d]: models is required", i)
+		}
+		if r.Wrap != "" && !strings.Contains(r.Wrap, postProcessContent) {
+			return fmt.Errorf("post-processing[%d]: wrap must contain %s", i, postProcessContent)
+		}
+		for _, s := range r.Strip {
+			if s == "" {
+				return fmt.Errorf("post-processing[%d]: strip entries must not be empty", i)
+			}
+		}
+	}
+	return nil
+}
+
+// SystemPromptTemplate is a system prompt added to requests for matching
+// models. The template may reference {{tool_names}} (comma-separated names of
+// the request's tools), {{date}} (today in UTC, YYYY-MM-DD) and {{model}}.
+type SystemPromptTemplate struct {
+	// Name identifies the template in logs.
+	Name string `yaml:"name" json:"name"`
+
+	// Version is bumped with every change to Template, so the prompt a
+	// request was sent with can be traced. Must be 1 or greater.
+	Version int `yaml:"version" json:"version"`
+
+	// Models are glob-style model patterns (e.g., "qwen*"). The first
+	// template matching the model applies.
+	Models []string `yaml:"models" json:"models"`
+
+	// WithTools applies the template only to requests carrying tools.
+	WithTools bool `yaml:"with-tools,omitempty" json:"with-tools,omitempty"`
+
+	// Position is "prepend" (before the client's system prompt) or "append"
+	// (after it). Default: "prepend".
+	Position string `yaml:"position,omitempty" json:"position,omitempty"`
+
+	// Template is the prompt text.
+	Template string `yaml:"template" json:"template"`
+}
+
+// systemPromptVariables are the variables a system prompt template may use.
+var systemPromptVariables = []string{"{{tool_names}}", "{{date}}", "{{model}}"}
+
+// ValidateSystemPrompts checks that every template is named, versioned and
+// targets models, and that it only uses known variables.
+func ValidateSystemPrompts(templates []SystemPromptTemplate) error {
+	names := make(map[string]bool, len(templates))
+	for i, t := range templates {
+		switch {
+		case strings.TrimSpace(t.Name) == "":
+			return fmt.Errorf("system-prompts[%d]: name is required", i)
+		case names[t.Name]:
+			return fmt.Errorf("system-prompts[%d]: duplicate name %q", i, t.Name)
+		case t.Version < 1:
+			return fmt.Errorf("system-prompts[%d]: version must be 1 or greater", i)
+		case len(t.Models) == 0:
+			return fmt.Errorf("system-prompts[%d]: models is required", i)
+		case strings.TrimSpace(t.Template) == "":
+			return fmt.Errorf("system-prompts[%d]: template is required", i)
+		}
+		names[t.Name] = true
+		if p := strings.ToLower(strings.TrimSpace(t.Position)); p != "" && p != "prepend" && p != "append" {
+			return fmt.Errorf("system-prompts[%d]: position must be prepend or append", i)
+		}
+		rest := t.Template
+		for _, v := range systemPromptVariables {
+			rest = strings.ReplaceAll(rest, v, "")
+		}
+		if start := strings.Index(rest, "{{"); start >= 0 {
+			end := strings.Index(rest[start:], "}}")
+			if end < 0 {
+				end = len(rest) - start - 2
+			}
+			return fmt.Errorf("system-prompts[%d]: unknown variable %s", i, rest[start:start+end+2])
+		}
+	}
+	return nil
+}
+
+// LatencySLO describes a time-to-first-token objective for streaming requests.
+type LatencySLO struct {
+	// Model is a glob-style model pattern (e.g., "claude-*", "*").
+	Model string `yaml:"model" json:"model"`
+
+	// TTFT is the first-token latency target (e.g., "4s").
+	TTFT string `yaml:"ttft" json:"ttft"`
+
+	// Percentile evaluated against TTFT. Default: 95.
+	Percentile float64 `yaml:"percentile,omitempty" json:"percentile,omitempty"`
+
+	// Window is the sliding window of samples considered. Default: "5m".
+	Window string `yaml:"window,omitempty" json:"window,omitempty"`
+
+	// MinSamples is the number of samples required before enforcement. Default: 20.
+	MinSamples int `yaml:"min-samples,omitempty" json:"min-samples,omitempty"`
+
+	// DemoteFor is how long a violating provider is demoted. Default: "10m".
+	DemoteFor string `yaml:"demote-for,omitempty" json:"demote-for,omitempty"`
+}
+
+// ErrorRateDisableConfig disables an auth for a while when too many of its
+// requests in a rolling window fail with the given statuses. An auth is only
+// disabled while another auth of its provider stays below the rate.
+type ErrorRateDisableConfig struct {
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// Window is the rolling window of requests considered. Default: "10m".
+	Window string `yaml:"window,omitempty" json:"window,omitempty"`
+
+	// MinRequests is the number of requests in the window before an auth
+	// can be disabled. Default: 20.
+	MinRequests int `yaml:"min-requests,omitempty" json:"min-requests,omitempty"`
+
+	// MaxErrorRate is the fraction of failed requests that disables an auth.
+	// Default: 0.5.
+	MaxErrorRate float64 `yaml:"max-error-rate,omitempty" json:"max-error-rate,omitempty"`
+
+	// Statuses are the upstream statuses counted as failures. Other failures
+	// are not counted. Default: [400, 401, 403].
+	Statuses []int `yaml:"statuses,omitempty" json:"statuses,omitempty"`
+
+	// DisableFor is how long a disabled auth gets no traffic. Default: "1h".
+	DisableFor string `yaml:"disable-for,omitempty" json:"disable-for,omitempty"`
+}
+
+// ConcurrencyLimit caps in-flight requests per auth for a provider and model pattern.
+type ConcurrencyLimit struct {
+	// Provider is the provider name (e.g., "gemini-cli"). Empty matches all providers.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+
+	// Model is a glob-style model pattern (e.g., "gemini-2.5-pro").
+	Model string `yaml:"model" json:"model"`
+
+	// MaxPerAuth is the number of concurrent requests allowed on one auth.
+	MaxPerAuth int `yaml:"max-per-auth" json:"max-per-auth"`
+
+	// QueueTimeout is how long a request waits for a free slot (e.g., "30s").
+	// Default: "30s". "0s" fails at once so other providers are tried.
+	QueueTimeout string `yaml:"queue-timeout,omitempty" json:"queue-timeout,omitempty"`
+}
+
+// AuthFilter limits which auths may serve a request by their tags.
+type AuthFilter struct {
+	// Provider is the provider name (e.g., "claude"). Empty matches all providers.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+
+	// Model is a glob-style model pattern. Empty matches all models.
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+
+	// APIKeys applies the filter only to these client API keys. Empty applies it to all clients.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+
+	// Tags an auth must carry (e.g., region: eu). All must match.
+	Tags map[string]string `yaml:"tags" json:"tags"`
+}
+
+// ModelPrice is the price of a model on a provider.
+type ModelPrice struct {
+	// Provider is the provider name (e.g., "vertex"). Empty matches all providers.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+
+	// Model is a glob-style model pattern (e.g., "claude-sonnet-*").
+	Model string `yaml:"model" json:"model"`
+
+	// Input and Output are USD per million prompt and completion tokens.
+	Input  float64 `yaml:"input" json:"input"`
+	Output float64 `yaml:"output" json:"output"`
+}
+
+// RoutingSchedule changes provider order during a time window.
+type RoutingSchedule struct {
+	// Model is a glob-style model pattern. Empty matches all models.
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+
+	// Days limits the schedule to weekdays ("mon", "tue", ...). Empty means every day.
+	Days []string `yaml:"days,omitempty" json:"days,omitempty"`
+
+	// From and To bound the window as "HH:MM". A window ending before it starts
+	// runs past midnight. Both empty means all day.
+	From string `yaml:"from,omitempty" json:"from,omitempty"`
+	To   string `yaml:"to,omitempty" json:"to,omitempty"`
+
+	// Prefer lists providers tried first, in order.
+	Prefer []string `yaml:"prefer,omitempty" json:"prefer,omitempty"`
+
+	// Avoid lists providers tried last.
+	Avoid []string `yaml:"avoid,omitempty" json:"avoid,omitempty"`
+}
+
+// QuotaBudget caps daily usage of each auth for a provider and model pattern.
+type QuotaBudget struct {
+	// Provider is the provider name (e.g., "gemini-cli"). Empty matches all providers.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+
+	// Model is a glob-style model pattern. Empty matches all models.
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+
+	// DailyTokens and DailyRequests are per-auth limits. 0 means unlimited.
+	DailyTokens   int64 `yaml:"daily-tokens,omitempty" json:"daily-tokens,omitempty"`
+	DailyRequests int64 `yaml:"daily-requests,omitempty" json:"daily-requests,omitempty"`
+}
+
+// SpendLimit caps the monthly usage of an auth.
+type SpendLimit struct {
+	// Provider is the provider name. Empty matches all providers.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+
+	// Auth is an auth ID (e.g., "claude-user@example.com.json"). Empty applies the
+	// limit to each auth of the provider separately.
+	Auth string `yaml:"auth,omitempty" json:"auth,omitempty"`
+
+	// MonthlyTokens is the token ceiling. 0 means unlimited.
+	MonthlyTokens int64 `yaml:"monthly-tokens,omitempty" json:"monthly-tokens,omitempty"`
+
+	// MonthlyCost is the USD ceiling, priced with Pricing. 0 means unlimited.
+	MonthlyCost float64 `yaml:"monthly-cost,omitempty" json:"monthly-cost,omitempty"`
+
+	// WarnAt is the fraction of a ceiling that logs a warning. Default: 0.8.
+	WarnAt float64 `yaml:"warn-at,omitempty" json:"warn-at,omitempty"`
+}
+
+// RequestPriorityConfig sets the priority levels ("low", "normal", "high")
+// clients may request with the X-LLMMUX-Priority header or metadata.priority.
+type RequestPriorityConfig struct {
+	// Default is the level of requests that do not set one. Default: "normal".
+	Default string `yaml:"default,omitempty" json:"default,omitempty"`
+
+	// Max is the highest level a client may request; higher requests are lowered
+	// to it. Default: "normal".
+	Max string `yaml:"max,omitempty" json:"max,omitempty"`
+
+	// Clients overrides Max per client API key.
+	Clients map[string]string `yaml:"clients,omitempty" json:"clients,omitempty"`
+
+	// PremiumTags reserves auths carrying these tags for requests at PremiumMin or above.
+	PremiumTags map[string]string `yaml:"premium-tags,omitempty" json:"premium-tags,omitempty"`
+
+	// PremiumMin is the lowest level allowed on premium auths. Default: "high".
+	PremiumMin string `yaml:"premium-min,omitempty" json:"premium-min,omitempty"`
+}
+
+// Alias conflict strategies.
+const (
+	AliasConflictFirstWins        = "first-wins"
+	AliasConflictSuffixByProvider = "suffix-by-provider"
+	AliasConflictLoadBalance      = "load-balance"
+)
+
+// AliasConflictConfig resolves model IDs (alias, or name without alias) that
+// several openai providers expose.
+type AliasConflictConfig struct {
+	// Strategy is "first-wins" (only the preferred provider serves the ID),
+	// "suffix-by-provider" (the preferred provider serves the ID and every
+	// provider also serves "<id>@<provider>") or "load-balance" (all providers
+	// serve the ID). Default: "load-balance".
+	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
+	// Prefer lists providers per model ID in order of preference. The first
+	// listed provider exposing the ID is preferred; otherwise the first in
+	// config order is. With load-balance, only listed providers serve the ID.
+	Prefer map[string][]string `yaml:"prefer,omitempty" json:"prefer,omitempty"`
+}
+
+// DeprecationConfig controls requests for retired models. When no auth
+// serves the requested model and it has a replacement, the request goes to
+// the replacement, or fails with the replacement's name under HardFail.
+type DeprecationConfig struct {
+	// Replacements adds to or overrides DefaultModelReplacements. An empty
+	// replacement removes a built-in entry.
+	Replacements map[string]string `yaml:"replacements,omitempty" json:"replacements,omitempty"`
+
+	// HardFail rejects requests for replaced models instead of substituting.
+	HardFail bool `yaml:"hard-fail,omitempty" json:"hard-fail,omitempty"`
+}
+
+// SnapshotPinningConfig controls snapshot pinning. When a model is served
+// by several upstream snapshots, for instance an alias configured on more
+// than one provider, later turns of a conversation prefer the snapshot of
+// its first turn. Conversations are identified by client API key, system
+// prompt and first user message.
+type SnapshotPinningConfig struct {
+	// Enabled turns snapshot pinning on.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// TTL is how long a pin is kept after the conversation's last turn
+	// (e.g., "1h"). Default: "1h".
+	TTL string `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+
+	// MaxEntries caps the pinned conversations; when full, new conversations
+	// are not pinned until entries expire. Default: 10000.
+	MaxEntries int `yaml:"max-entries,omitempty" json:"max-entries,omitempty"`
+}
+
+// DefaultModelReplacements maps retired model IDs to their successors.
+// Dated and -latest variants of a key are covered by the undated entry.
+var DefaultModelReplacements = map[string]string{
+	"gemini-1.0-pro":        "gemini-2.5-flash",
+	"gemini-1.5-pro":        "gemini-2.5-pro",
+	"gemini-1.5-flash":      "gemini-2.5-flash",
+	"gemini-1.5-flash-8b":   "gemini-2.5-flash-lite",
+	"gemini-2.0-flash":      "gemini-2.5-flash",
+	"gemini-2.0-flash-lite": "gemini-2.5-flash-lite",
+	"gemini-2.0-pro-exp":    "gemini-2.5-pro",
+	"claude-3-opus":         "claude-opus-4-5",
+	"claude-3-sonnet":       "claude-sonnet-4-5",
+	"claude-3-haiku":        "claude-haiku-4-5",
+	"claude-3-5-sonnet":     "claude-sonnet-4-5",
+	"claude-3-5-haiku":      "claude-haiku-4-5",
+	"claude-3-7-sonnet":     "claude-sonnet-4-5",
+	"gpt-4":                 "gpt-4o",
+	"gpt-4-turbo":           "gpt-4o",
+	"gpt-4-32k":             "gpt-4o",
+}
+
+// datedModelSuffix matches the release date or -latest suffix of model IDs
+// such as claude-3-5-sonnet-20241022.
+var datedModelSuffix = regexp.MustCompile(`-(\d{8}|\d{4}-\d{2}-\d{2}|\d{3,4}|latest)$`)
+
+// Replacement returns the successor of a retired model.
+func (d DeprecationConfig) Replacement(model string) (string, bool) {
+	lookup := func(m string) (string, bool) {
+		if r, ok := d.Replacements[m]; ok {
+			return r, r != ""
+		}
+		r, ok := DefaultModelReplacements[m]
+		return r, ok
+	}
+	if r, ok := lookup(model); ok {
+		return r, true
+	}
+	if base := datedModelSuffix.ReplaceAllString(model, ""); base != model {
+		return lookup(base)
+	}
+	return "", false
+}
+
+// MaxFor returns the highest level apiKey may request.
+func (p RequestPriorityConfig) MaxFor(apiKey string) string {
+	if apiKey != "" {
+		if level, ok := p.Clients[apiKey]; ok {
+			return level
+		}
+	}
+	return p.Max
+}
+
 func (r *RoutingConfig) Init() {
 	if r == nil {
 		return
@@ -364,6 +1721,11 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	// Expand ${VAR} references so secrets can be supplied through the environment.
+	if err = expandConfigEnv(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to expand config environment variables: %w", err)
+	}
+
 	// Sync request authentication providers with inline API keys for backwards compatibility.
 	syncInlineAccessProvider(&cfg)
 
@@ -374,6 +1736,25 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 
 	cfg.Routing.Init()
 
+	if cfg.HeaderPassthrough, err = NormalizeHeaderPassthrough(cfg.HeaderPassthrough); err != nil {
+		return nil, fmt.Errorf("invalid header-passthrough config: %w", err)
+	}
+	if err = cfg.CORS.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid cors config: %w", err)
+	}
+	if err = cfg.Payload.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid payload config: %w", err)
+	}
+	if err = ValidatePostProcessing(cfg.PostProcessing); err != nil {
+		return nil, fmt.Errorf("invalid post-processing config: %w", err)
+	}
+	if err = ValidateSystemPrompts(cfg.SystemPrompts); err != nil {
+		return nil, fmt.Errorf("invalid system-prompts config: %w", err)
+	}
+	if err = ValidateEndpointAliases(cfg.EndpointAliases); err != nil {
+		return nil, fmt.Errorf("invalid endpoint-aliases config: %w", err)
+	}
+
 	// Return the populated configuration struct.
 	return &cfg, nil
 }
@@ -496,6 +1877,9 @@ func SaveConfigPreserveComments(configFile string, cfg *Config) error {
 		return fmt.Errorf("expected generated root mapping node")
 	}
 
+	// Never write env-resolved secrets back into the file.
+	restoreEnvPlaceholders(generated.Content[0], cfg.envPlaceholders)
+
 	pruneMappingToGeneratedKeys(original.Content[0], generated.Content[0], "oauth-excluded-models")
 
 	// Merge generated into original in-place, preserving comments/order of existing nodes.
@@ -0,0 +1,24 @@
+package config
+
+import "testing"
+
+func TestDeprecationReplacement(t *testing.T) {
+	d := DeprecationConfig{Replacements: map[string]string{
+		"gpt-4":          "",
+		"my-old-model":   "my-new-model",
+		"gemini-1.5-pro": "gemini-3-pro-preview",
+	}}
+	for model, want := range map[string]string{
+		"claude-3-5-sonnet-20241022": "claude-sonnet-4-5",
+		"claude-3-5-haiku-latest":    "claude-haiku-4-5",
+		"gemini-1.5-pro-002":         "gemini-3-pro-preview",
+		"my-old-model":               "my-new-model",
+		"gpt-4":                      "",
+		"gpt-4o":                     "",
+	} {
+		got, ok := d.Replacement(model)
+		if got != want || ok != (want != "") {
+			t.Errorf("Replacement(%q) = %q, %v; want %q", model, got, ok, want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExpandEnvString expands ${VAR} references in s using the process environment.
+// Supported forms:
+//   - ${VAR}          value of VAR, empty if unset
+//   - ${VAR:-default} value of VAR, or default when VAR is unset or empty
+//   - ${VAR:?message} value of VAR, or an error carrying message when VAR is unset or empty
+//
+// A literal "${" can be written as "$${". Bare $VAR references are left untouched
+// because API keys and passwords frequently contain '$'.
+func ExpandEnvString(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "$${") {
+			b.WriteString("${")
+			i += 3
+			continue
+		}
+		if !strings.HasPrefix(s[i:], "${") {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		end := strings.IndexByte(s[i+2:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable reference in %q", s)
+		}
+		expr := s[i+2 : i+2+end]
+		val, err := resolveEnvExpr(expr)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(val)
+		i += end + 3
+	}
+	return b.String(), nil
+}
+
+func resolveEnvExpr(expr string) (string, error) {
+	name, op, arg := expr, "", ""
+	if idx := strings.Index(expr, ":-"); idx >= 0 {
+		name, op, arg = expr[:idx], ":-", expr[idx+2:]
+	} else if idx = strings.Index(expr, ":?"); idx >= 0 {
+		name, op, arg = expr[:idx], ":?", expr[idx+2:]
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("empty variable name in ${%s}", expr)
+	}
+	val := os.Getenv(name)
+	if val != "" {
+		return val, nil
+	}
+	switch op {
+	case ":-":
+		return arg, nil
+	case ":?":
+		if arg == "" {
+			arg = "required but not set"
+		}
+		return "", fmt.Errorf("environment variable %s: %s", name, arg)
+	}
+	return "", nil
+}
+
+// expandConfigEnv expands ${VAR} references in secret-bearing and connection fields
+// (API keys, DSNs, proxy URLs, base URLs, headers). Expanded values are remembered
+// so that SaveConfigPreserveComments writes the original placeholders back instead
+// of the resolved secrets.
+func expandConfigEnv(cfg *Config) error {
+	if cfg == nil {
+		return nil
+	}
+	var errs []error
+	expand := func(field string, p *string) {
+		if p == nil || *p == "" {
+			return
+		}
+		raw := *p
+		val, err := ExpandEnvString(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field, err))
+			return
+		}
+		if val != raw {
+			if cfg.envPlaceholders == nil {
+				cfg.envPlaceholders = make(map[string]envPlaceholder)
+			}
+			cfg.envPlaceholders[field] = envPlaceholder{value: val, raw: raw}
+		}
+		*p = val
+	}
+	expandHeaders := func(field string, headers map[string]string) {
+		for k, v := range headers {
+			expand(field+"."+k, &v)
+			headers[k] = v
+		}
+	}
+
+	expand("proxy-url", &cfg.ProxyURL)
+	for i := range cfg.APIKeys {
+		expand(fmt.Sprintf("api-keys[%d]", i), &cfg.APIKeys[i])
+	}
+	expand("usage.dsn", &cfg.Usage.DSN)
+	for i := range cfg.Usage.Reconciliation.Sources {
+		src := &cfg.Usage.Reconciliation.Sources[i]
+		prefix := fmt.Sprintf("usage.reconciliation.sources[%d]", i)
+		expand(prefix+".admin-key", &src.AdminKey)
+		expand(prefix+".base-url", &src.BaseURL)
+	}
+	expand("conversations.dsn", &cfg.Conversations.DSN)
+	expand("ampcode.upstream-url", &cfg.AmpCode.UpstreamURL)
+	expand("ampcode.upstream-api-key", &cfg.AmpCode.UpstreamAPIKey)
+
+	for i := range cfg.Providers {
+		p := &cfg.Providers[i]
+		prefix := fmt.Sprintf("providers[%d]", i)
+		expand(prefix+".api-key", &p.APIKey)
+		expand(prefix+".base-url", &p.BaseURL)
+		expand(prefix+".proxy-url", &p.ProxyURL)
+		expandHeaders(prefix+".headers", p.Headers)
+		expand(prefix+".signing-secret", &p.SigningSecret)
+		for j := range p.APIKeys {
+			expand(fmt.Sprintf("%s.api-keys[%d].key", prefix, j), &p.APIKeys[j].Key)
+			expand(fmt.Sprintf("%s.api-keys[%d].proxy-url", prefix, j), &p.APIKeys[j].ProxyURL)
+		}
+	}
+
+	for i := range cfg.VertexCompatAPIKey {
+		v := &cfg.VertexCompatAPIKey[i]
+		prefix := fmt.Sprintf("vertex-api-key[%d]", i)
+		expand(prefix+".api-key", &v.APIKey)
+		expand(prefix+".base-url", &v.BaseURL)
+		expand(prefix+".proxy-url", &v.ProxyURL)
+		expandHeaders(prefix+".headers", v.Headers)
+	}
+
+	return errors.Join(errs...)
+}
+
+// envPlaceholder is the source text of a config value that was expanded.
+type envPlaceholder struct {
+	value string // expanded value
+	raw   string // text with ${VAR} references
+}
+
+// restoreEnvPlaceholders replaces scalar values that were produced by env expansion
+// with their original ${VAR} placeholders. Values are matched by their path in the
+// document, as written by expandConfigEnv ("providers[0].api-key"), and only
+// restored while they still hold the expanded value.
+func restoreEnvPlaceholders(node *yaml.Node, placeholders map[string]envPlaceholder) {
+	if len(placeholders) == 0 {
+		return
+	}
+	restoreEnvPlaceholdersAt(node, "", placeholders)
+}
+
+func restoreEnvPlaceholdersAt(node *yaml.Node, path string, placeholders map[string]envPlaceholder) {
+	if node == nil {
+		return
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if p, ok := placeholders[path]; ok && node.Value == p.value {
+			node.Value = p.raw
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if path != "" {
+				key = path + "." + key
+			}
+			restoreEnvPlaceholdersAt(node.Content[i+1], key, placeholders)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			restoreEnvPlaceholdersAt(child, fmt.Sprintf("%s[%d]", path, i), placeholders)
+		}
+	}
+}
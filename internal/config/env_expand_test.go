@@ -0,0 +1,160 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandEnvString(t *testing.T) {
+	t.Setenv("LLM_MUX_TEST_KEY", "sk-secret")
+	t.Setenv("LLM_MUX_TEST_EMPTY", "")
+
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{name: "no reference", in: "plain$value", want: "plain$value"},
+		{name: "simple", in: "${LLM_MUX_TEST_KEY}", want: "sk-secret"},
+		{name: "embedded", in: "Bearer ${LLM_MUX_TEST_KEY}!", want: "Bearer sk-secret!"},
+		{name: "unset is empty", in: "${LLM_MUX_TEST_MISSING}", want: ""},
+		{name: "default when unset", in: "${LLM_MUX_TEST_MISSING:-fallback}", want: "fallback"},
+		{name: "default when empty", in: "${LLM_MUX_TEST_EMPTY:-fallback}", want: "fallback"},
+		{name: "default ignored when set", in: "${LLM_MUX_TEST_KEY:-fallback}", want: "sk-secret"},
+		{name: "required set", in: "${LLM_MUX_TEST_KEY:?missing}", want: "sk-secret"},
+		{name: "required unset", in: "${LLM_MUX_TEST_MISSING:?missing}", wantErr: true},
+		{name: "escaped", in: "$${LLM_MUX_TEST_KEY}", want: "${LLM_MUX_TEST_KEY}"},
+		{name: "unterminated", in: "${LLM_MUX_TEST_KEY", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandEnvString(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ExpandEnvString(%q) expected error, got %q", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandEnvString(%q) unexpected error: %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandEnvString(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_ExpandsEnvAndPreservesPlaceholders(t *testing.T) {
+	t.Setenv("LLM_MUX_TEST_PROVIDER_KEY", "sk-provider")
+	t.Setenv("LLM_MUX_TEST_DSN", "sqlite:///tmp/usage.db")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := `port: 8317
+api-keys:
+  - "${LLM_MUX_TEST_CLIENT_KEY:-client-default}"
+usage:
+  dsn: "${LLM_MUX_TEST_DSN}"
+providers:
+  - type: anthropic
+    api-key: "${LLM_MUX_TEST_PROVIDER_KEY}"
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if len(cfg.APIKeys) != 1 || cfg.APIKeys[0] != "client-default" {
+		t.Errorf("api-keys = %v, want [client-default]", cfg.APIKeys)
+	}
+	if cfg.Usage.DSN != "sqlite:///tmp/usage.db" {
+		t.Errorf("usage.dsn = %q", cfg.Usage.DSN)
+	}
+	if len(cfg.Providers) != 1 || cfg.Providers[0].APIKey != "sk-provider" {
+		t.Fatalf("providers = %+v", cfg.Providers)
+	}
+
+	cfg.Port = 9000
+	if err = SaveConfigPreserveComments(path, cfg); err != nil {
+		t.Fatalf("SaveConfigPreserveComments: %v", err)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read saved config: %v", err)
+	}
+	if strings.Contains(string(saved), "sk-provider") {
+		t.Errorf("saved config leaked expanded secret:\n%s", saved)
+	}
+	if !strings.Contains(string(saved), "${LLM_MUX_TEST_PROVIDER_KEY}") {
+		t.Errorf("saved config lost placeholder:\n%s", saved)
+	}
+}
+
+func TestSaveConfig_RestoresPlaceholdersByPath(t *testing.T) {
+	t.Setenv("LLM_MUX_TEST_COMMON_KEY", "false")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := `port: 8317
+debug: false
+usage:
+  dsn: "${LLM_MUX_TEST_UNSET_DSN}"
+providers:
+  - type: anthropic
+    api-key: "${LLM_MUX_TEST_COMMON_KEY}"
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	// Saving twice checks that placeholders of empty values survive a round trip.
+	for range 2 {
+		if err = SaveConfigPreserveComments(path, cfg); err != nil {
+			t.Fatalf("SaveConfigPreserveComments: %v", err)
+		}
+		if cfg, err = LoadConfig(path); err != nil {
+			t.Fatalf("LoadConfig: %v", err)
+		}
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read saved config: %v", err)
+	}
+	if n := strings.Count(string(saved), "${LLM_MUX_TEST_COMMON_KEY}"); n != 1 {
+		t.Errorf("placeholder written %d times, want once:\n%s", n, saved)
+	}
+	if !strings.Contains(string(saved), "debug: false") {
+		t.Errorf("unrelated value with the expanded text was replaced:\n%s", saved)
+	}
+	if !strings.Contains(string(saved), "${LLM_MUX_TEST_UNSET_DSN}") {
+		t.Errorf("placeholder of empty value lost:\n%s", saved)
+	}
+}
+
+func TestLoadConfig_RequiredEnvMissing(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := "api-keys:\n  - \"${LLM_MUX_TEST_REQUIRED:?set the client key}\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	_, err := LoadConfig(path)
+	if err == nil {
+		t.Fatal("expected error for missing required variable")
+	}
+	if !strings.Contains(err.Error(), "LLM_MUX_TEST_REQUIRED") {
+		t.Errorf("error %q should name the variable", err)
+	}
+}
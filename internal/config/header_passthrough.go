@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// protectedPassthroughHeaders carry credentials or describe the connection
+// and body llm-mux sends, so they are never taken from the client.
+var protectedPassthroughHeaders = []string{
+	"Authorization", "Proxy-Authorization", "X-Api-Key", "X-Goog-Api-Key", "Api-Key", "Cookie",
+	"Host", "Connection", "Keep-Alive", "Upgrade", "Te", "Trailer", "Transfer-Encoding",
+	"Content-Length", "Content-Type", "Content-Encoding", "Accept-Encoding",
+}
+
+// NormalizeHeaderPassthrough lower-cases provider names, canonicalizes and
+// deduplicates header names, and rejects headers that must not be forwarded.
+func NormalizeHeaderPassthrough(entries map[string][]string) (map[string][]string, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	out := make(map[string][]string, len(entries))
+	for provider, headers := range entries {
+		key := strings.ToLower(strings.TrimSpace(provider))
+		if key == "" {
+			continue
+		}
+		var names []string
+		for _, h := range headers {
+			name := http.CanonicalHeaderKey(strings.TrimSpace(h))
+			if name == "" || slices.Contains(names, name) {
+				continue
+			}
+			if slices.Contains(protectedPassthroughHeaders, name) {
+				return nil, fmt.Errorf("%s: %s cannot be forwarded", key, name)
+			}
+			names = append(names, name)
+		}
+		if len(names) > 0 {
+			out[key] = names
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
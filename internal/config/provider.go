@@ -17,13 +17,43 @@ const (
 
 	// ProviderTypeVertexCompat uses Vertex AI-compatible endpoints (zenmux, etc.).
 	ProviderTypeVertexCompat ProviderType = "vertex-compat"
+
+	// ProviderTypeLlamaCpp is a llama.cpp server (llama-server).
+	ProviderTypeLlamaCpp ProviderType = "llamacpp"
+
+	// ProviderTypeVLLM is a vLLM OpenAI-compatible server.
+	ProviderTypeVLLM ProviderType = "vllm"
+
+	// ProviderTypeMock is a built-in synthetic provider for load testing. It
+	// generates responses locally and never contacts an upstream.
+	ProviderTypeMock ProviderType = "mock"
+
+	// DefaultMockModel is served by mock providers without models.
+	DefaultMockModel = "mock-model"
+
+	// NoAPIKey is the account key of local servers configured without one.
+	// It is never sent upstream.
+	NoAPIKey = "no-key"
 )
 
+// IsOpenAICompatible reports whether providers of this type speak the OpenAI
+// chat completions API and are configured like openai providers.
+func (t ProviderType) IsOpenAICompatible() bool {
+	return t == ProviderTypeOpenAI || t.IsLocalServer()
+}
+
+// IsLocalServer reports whether providers of this type are self-hosted
+// inference servers, probed for health and models on startup.
+func (t ProviderType) IsLocalServer() bool {
+	return t == ProviderTypeLlamaCpp || t == ProviderTypeVLLM
+}
+
 // Provider represents a unified API provider configuration.
 // This replaces the legacy gemini-api-key, claude-api-key, codex-api-key,
 // openai-compatibility, and vertex-api-key configurations.
 type Provider struct {
-	// Type specifies the provider type (gemini, anthropic, openai, vertex-compat).
+	// Type specifies the provider type (gemini, anthropic, openai, vertex-compat,
+	// llamacpp, vllm, mock).
 	Type ProviderType `yaml:"type" json:"type"`
 
 	// Name is a display name for this provider instance.
@@ -50,9 +80,14 @@ type Provider struct {
 	// ProxyURL sets a proxy for this provider's requests.
 	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
 
-	// Headers adds custom HTTP headers to requests.
+	// Headers adds custom HTTP headers to requests. Values may contain
+	// per-request placeholders such as {{timestamp}}, {{request_id}} and
+	// {{hmac_sha256}}.
 	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
 
+	// SigningSecret keys the {{hmac_sha256}} header placeholder.
+	SigningSecret string `yaml:"signing-secret,omitempty" json:"signing-secret,omitempty"`
+
 	// Models defines available models for this provider.
 	// Required for: openai, vertex-compat
 	// Optional for: gemini, anthropic (uses built-in registry if not set)
@@ -60,6 +95,56 @@ type Provider struct {
 
 	// ExcludedModels lists model names to exclude from this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
+	// FoldLateSystemMessages moves system messages sent after the first turn
+	// into the next user message, for openai upstreams that reject them.
+	FoldLateSystemMessages bool `yaml:"fold-late-system-messages,omitempty" json:"fold-late-system-messages,omitempty"`
+
+	// Mock shapes the output of a mock provider.
+	Mock MockSettings `yaml:"mock,omitempty" json:"mock,omitempty"`
+
+	// KeepWarm keeps models of an openai provider backed by Ollama loaded.
+	KeepWarm KeepWarmSettings `yaml:"keep-warm,omitempty" json:"keep-warm,omitempty"`
+}
+
+// KeepWarmSettings periodically pings models on an Ollama server through its
+// native API so they stay loaded between requests.
+type KeepWarmSettings struct {
+	// Models lists the upstream model names to keep loaded.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// Interval is the time between pings, e.g. "4m". Default: "4m".
+	Interval string `yaml:"interval,omitempty" json:"interval,omitempty"`
+
+	// KeepAlive is how long Ollama keeps a pinged model loaded, e.g. "10m".
+	// It should exceed Interval. Default: "10m".
+	KeepAlive string `yaml:"keep-alive,omitempty" json:"keep-alive,omitempty"`
+}
+
+// MockSettings controls the synthetic responses of a mock provider. Each
+// api-key entry is a separate account with its own rate limit.
+type MockSettings struct {
+	// TokensPerSecond is the streaming output rate. Default: 50.
+	TokensPerSecond int `yaml:"tokens-per-second,omitempty" json:"tokens-per-second,omitempty"`
+
+	// FirstTokenLatency delays the first token (and non-streaming responses), e.g. "300ms".
+	FirstTokenLatency string `yaml:"first-token-latency,omitempty" json:"first-token-latency,omitempty"`
+
+	// OutputTokens is the length of each response. Default: 100.
+	OutputTokens int `yaml:"output-tokens,omitempty" json:"output-tokens,omitempty"`
+
+	// ErrorRate is the fraction of requests failing with ErrorStatus (0-1).
+	ErrorRate float64 `yaml:"error-rate,omitempty" json:"error-rate,omitempty"`
+
+	// ErrorStatus is the HTTP status of injected errors. Default: 500.
+	ErrorStatus int `yaml:"error-status,omitempty" json:"error-status,omitempty"`
+
+	// RequestsPerMinute limits each account; excess requests fail with 429 and
+	// a Retry-After until the minute ends. 0 is unlimited.
+	RequestsPerMinute int `yaml:"requests-per-minute,omitempty" json:"requests-per-minute,omitempty"`
+
+	// Seed makes error injection reproducible across restarts.
+	Seed int64 `yaml:"seed,omitempty" json:"seed,omitempty"`
 }
 
 // ProviderAPIKey represents an API key with optional per-key settings.
@@ -91,6 +176,8 @@ func (p *Provider) IsEnabled() bool {
 
 // GetAPIKeys returns all API keys for this provider.
 // If APIKey is set and APIKeys is empty, returns APIKey as a single entry.
+// A mock provider without keys has a single account keyed "mock", and a
+// local server without keys one keyed NoAPIKey.
 func (p *Provider) GetAPIKeys() []ProviderAPIKey {
 	if len(p.APIKeys) > 0 {
 		return p.APIKeys
@@ -98,6 +185,12 @@ func (p *Provider) GetAPIKeys() []ProviderAPIKey {
 	if p.APIKey != "" {
 		return []ProviderAPIKey{{Key: p.APIKey, ProxyURL: p.ProxyURL}}
 	}
+	if p.Type == ProviderTypeMock {
+		return []ProviderAPIKey{{Key: string(ProviderTypeMock)}}
+	}
+	if p.Type.IsLocalServer() {
+		return []ProviderAPIKey{{Key: NoAPIKey, ProxyURL: p.ProxyURL}}
+	}
 	return nil
 }
 
@@ -117,10 +210,18 @@ func (p *Provider) Validate() error {
 	}
 
 	// Check API key
-	if p.APIKey == "" && len(p.APIKeys) == 0 {
+	if p.APIKey == "" && len(p.APIKeys) == 0 && p.Type != ProviderTypeMock && !p.Type.IsLocalServer() {
 		return &ProviderValidationError{Field: "api-key", Message: "api-key or api-keys is required"}
 	}
 
+	if p.SigningSecret == "" {
+		for name, value := range p.Headers {
+			if strings.Contains(value, "{{hmac_sha256}}") {
+				return &ProviderValidationError{Field: "headers." + name, Message: "{{hmac_sha256}} requires signing-secret"}
+			}
+		}
+	}
+
 	// Type-specific validation
 	switch p.Type {
 	case ProviderTypeOpenAI, ProviderTypeVertexCompat:
@@ -130,6 +231,11 @@ func (p *Provider) Validate() error {
 		if len(p.Models) == 0 {
 			return &ProviderValidationError{Field: "models", Message: "models is required for " + string(p.Type)}
 		}
+	case ProviderTypeLlamaCpp, ProviderTypeVLLM:
+		// Models are discovered from the server when not listed.
+		if p.BaseURL == "" {
+			return &ProviderValidationError{Field: "base-url", Message: "base-url is required for " + string(p.Type)}
+		}
 	}
 
 	return nil
@@ -169,6 +275,10 @@ func SanitizeProviders(providers []Provider) []Provider {
 		p.BaseURL = strings.TrimRight(strings.TrimSpace(p.BaseURL), "/")
 		p.ProxyURL = strings.TrimSpace(p.ProxyURL)
 		p.Headers = NormalizeHeaders(p.Headers)
+		p.SigningSecret = strings.TrimSpace(p.SigningSecret)
+		if p.Name == "" && p.Type.IsLocalServer() {
+			p.Name = string(p.Type)
+		}
 
 		// Normalize API keys
 		validKeys := make([]ProviderAPIKey, 0, len(p.APIKeys))
@@ -210,6 +320,96 @@ func SanitizeProviders(providers []Provider) []Provider {
 	return result
 }
 
+// ModelID returns the ID a model is exposed under: its alias, or its name.
+func (m ProviderModel) ModelID() string {
+	if m.Alias != "" {
+		return m.Alias
+	}
+	return m.Name
+}
+
+// OpenAIAliasConflicts returns, for each model ID exposed by more than one
+// openai-compatible provider, the names of those providers in config order.
+func (cfg *Config) OpenAIAliasConflicts() map[string][]string {
+	if cfg == nil {
+		return nil
+	}
+	owners := make(map[string][]string)
+	for i := range cfg.Providers {
+		p := &cfg.Providers[i]
+		if !p.Type.IsOpenAICompatible() || !p.IsEnabled() {
+			continue
+		}
+		seen := make(map[string]struct{}, len(p.Models))
+		for _, m := range p.Models {
+			id := m.ModelID()
+			if _, dup := seen[id]; dup {
+				continue
+			}
+			seen[id] = struct{}{}
+			owners[id] = append(owners[id], p.Name)
+		}
+	}
+	for id, names := range owners {
+		if len(names) < 2 {
+			delete(owners, id)
+		}
+	}
+	return owners
+}
+
+// OpenAIModelIDs returns the model IDs the openai provider named providerName
+// registers for id, applying Routing.AliasConflicts when other providers
+// expose the same ID. The result is empty when the provider does not serve it.
+func (cfg *Config) OpenAIModelIDs(providerName, id string) []string {
+	if cfg == nil {
+		return []string{id}
+	}
+	owners := cfg.OpenAIAliasConflicts()[id]
+	if len(owners) == 0 {
+		return []string{id}
+	}
+	ac := cfg.Routing.AliasConflicts
+	preferred := owners[0]
+	listed := false
+	for _, name := range ac.Prefer[id] {
+		if i := indexFold(owners, name); i >= 0 {
+			preferred = owners[i]
+			listed = true
+			break
+		}
+	}
+	isPreferred := strings.EqualFold(preferred, providerName)
+
+	switch strings.ToLower(strings.TrimSpace(ac.Strategy)) {
+	case AliasConflictFirstWins:
+		if isPreferred {
+			return []string{id}
+		}
+		return nil
+	case AliasConflictSuffixByProvider:
+		suffixed := id + "@" + strings.ToLower(providerName)
+		if isPreferred {
+			return []string{id, suffixed}
+		}
+		return []string{suffixed}
+	default:
+		if listed && indexFold(ac.Prefer[id], providerName) < 0 {
+			return nil
+		}
+		return []string{id}
+	}
+}
+
+func indexFold(list []string, s string) int {
+	for i, v := range list {
+		if strings.EqualFold(strings.TrimSpace(v), s) {
+			return i
+		}
+	}
+	return -1
+}
+
 // GetProvidersByType returns all providers of the specified type.
 func (cfg *Config) GetProvidersByType(t ProviderType) []Provider {
 	if cfg == nil {
@@ -236,3 +436,22 @@ func (cfg *Config) GetProviderByName(name string) *Provider {
 	}
 	return nil
 }
+
+// MockProvider returns the mock provider named name that has the given key.
+func (cfg *Config) MockProvider(name, key string) *Provider {
+	if cfg == nil {
+		return nil
+	}
+	for i := range cfg.Providers {
+		p := &cfg.Providers[i]
+		if p.Type != ProviderTypeMock || p.GetDisplayName() != name {
+			continue
+		}
+		for _, k := range p.GetAPIKeys() {
+			if k.Key == key {
+				return p
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOpenAIModelIDs(t *testing.T) {
+	cfg := &Config{Providers: []Provider{
+		{Type: ProviderTypeOpenAI, Name: "groq", Models: []ProviderModel{{Name: "llama-3.3-70b-versatile", Alias: "llama-70b"}, {Name: "mixtral"}}},
+		{Type: ProviderTypeOpenAI, Name: "Together", Models: []ProviderModel{{Name: "meta-llama/Llama-3.3-70B", Alias: "llama-70b"}}},
+	}}
+
+	if got := cfg.OpenAIAliasConflicts(); !reflect.DeepEqual(got, map[string][]string{"llama-70b": {"groq", "Together"}}) {
+		t.Fatalf("conflicts = %v", got)
+	}
+
+	tests := []struct {
+		strategy string
+		prefer   []string
+		provider string
+		want     []string
+	}{
+		{"", nil, "groq", []string{"llama-70b"}},
+		{"", nil, "together", []string{"llama-70b"}},
+		{"", []string{"together"}, "groq", nil},
+		{AliasConflictFirstWins, nil, "groq", []string{"llama-70b"}},
+		{AliasConflictFirstWins, nil, "Together", nil},
+		{AliasConflictFirstWins, []string{"missing", "together"}, "Together", []string{"llama-70b"}},
+		{AliasConflictSuffixByProvider, nil, "groq", []string{"llama-70b", "llama-70b@groq"}},
+		{AliasConflictSuffixByProvider, nil, "Together", []string{"llama-70b@together"}},
+	}
+	for _, tt := range tests {
+		cfg.Routing.AliasConflicts = AliasConflictConfig{Strategy: tt.strategy}
+		if tt.prefer != nil {
+			cfg.Routing.AliasConflicts.Prefer = map[string][]string{"llama-70b": tt.prefer}
+		}
+		if got := cfg.OpenAIModelIDs(tt.provider, "llama-70b"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q prefer=%v %s: got %v, want %v", tt.strategy, tt.prefer, tt.provider, got, tt.want)
+		}
+	}
+
+	if got := cfg.OpenAIModelIDs("groq", "mixtral"); !reflect.DeepEqual(got, []string{"mixtral"}) {
+		t.Errorf("unconflicted ID = %v", got)
+	}
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//go:generate go run ./schemagen
+
+// schemaJSON is config.schema.json, generated by GenerateSchema.
+//
+//go:embed config.schema.json
+var schemaJSON []byte
+
+// Schema returns the JSON Schema of config.yaml.
+func Schema() []byte { return schemaJSON }
+
+// schemaEnums lists the values of string fields that only accept a few.
+// Named string types get their enum from their constants instead.
+var schemaEnums = map[string][]string{
+	"AliasConflictConfig.Strategy":   {"first-wins", "suffix-by-provider", "load-balance"},
+	"ToolResultGuardConfig.Strategy": {"truncate", "summarize"},
+	"SystemPromptTemplate.Position":  {"", "prepend", "append"},
+	"RoutingConfig.RouteBy":          {"", "cost"},
+	"RequestPriorityConfig.Default":  {"low", "normal", "high"},
+	"RequestPriorityConfig.Max":      {"low", "normal", "high"},
+	"TLSConfig.ClientAuth":           {"require", "optional"},
+	"UsageSQLiteConfig.Partition":    {"", "month"},
+}
+
+// GenerateSchema returns the JSON Schema of Config, with descriptions taken
+// from the doc comments of the Go sources in dir.
+func GenerateSchema(dir string) ([]byte, error) {
+	g := &schemaGenerator{
+		docs:  make(map[string]string),
+		enums: make(map[string][]string),
+		defs:  make(map[string]any),
+	}
+	if err := g.parseSources(dir); err != nil {
+		return nil, err
+	}
+	root := map[string]any{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"title":       "llm-mux configuration",
+		"description": "config.yaml of llm-mux. Strings may reference environment variables as ${VAR}.",
+		"$ref":        g.ref(reflect.TypeFor[Config]()),
+		"$defs":       g.defs,
+	}
+	out, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+type schemaGenerator struct {
+	// docs maps "Type" and "Type.Field" to doc comments.
+	docs map[string]string
+	// enums maps named string types to the values of their constants.
+	enums map[string][]string
+	defs  map[string]any
+}
+
+func (g *schemaGenerator) parseSources(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					g.parseType(gd, s)
+				case *ast.ValueSpec:
+					g.parseConst(gd, s)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (g *schemaGenerator) parseType(gd *ast.GenDecl, s *ast.TypeSpec) {
+	doc := s.Doc
+	if doc == nil && len(gd.Specs) == 1 {
+		doc = gd.Doc
+	}
+	if text := commentText(doc); text != "" {
+		g.docs[s.Name.Name] = text
+	}
+	st, ok := s.Type.(*ast.StructType)
+	if !ok {
+		return
+	}
+	for _, field := range st.Fields.List {
+		text := commentText(field.Doc)
+		if text == "" {
+			text = commentText(field.Comment)
+		}
+		for _, name := range field.Names {
+			if text != "" {
+				g.docs[s.Name.Name+"."+name.Name] = text
+			}
+		}
+	}
+}
+
+func (g *schemaGenerator) parseConst(gd *ast.GenDecl, s *ast.ValueSpec) {
+	if gd.Tok != token.CONST {
+		return
+	}
+	typ, ok := s.Type.(*ast.Ident)
+	if !ok {
+		return
+	}
+	for _, v := range s.Values {
+		if lit, ok := v.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if value, err := strconv.Unquote(lit.Value); err == nil {
+				g.enums[typ.Name] = append(g.enums[typ.Name], value)
+			}
+		}
+	}
+}
+
+func commentText(cg *ast.CommentGroup) string {
+	return strings.Join(strings.Fields(cg.Text()), " ")
+}
+
+// ref returns a reference to the definition of struct type t, adding it to
+// defs on first use.
+func (g *schemaGenerator) ref(t reflect.Type) string {
+	name := t.Name()
+	if _, ok := g.defs[name]; !ok {
+		// Reserve the name first so recursive types terminate.
+		g.defs[name] = nil
+		def := map[string]any{
+			"type":                 "object",
+			"properties":           g.properties(t),
+			"additionalProperties": false,
+		}
+		if doc := g.docs[name]; doc != "" {
+			def["description"] = doc
+		}
+		g.defs[name] = def
+	}
+	return "#/$defs/" + name
+}
+
+// properties returns the schemas of the YAML keys of struct type t,
+// merging inline fields.
+func (g *schemaGenerator) properties(t reflect.Type) map[string]any {
+	props := make(map[string]any)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("yaml")
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			for k, v := range g.properties(derefType(f.Type)) {
+				props[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		schema := g.schemaFor(f.Type)
+		if doc := g.docs[t.Name()+"."+f.Name]; doc != "" {
+			schema["description"] = doc
+		}
+		if enum, ok := schemaEnums[t.Name()+"."+f.Name]; ok {
+			schema["enum"] = enum
+		}
+		props[name] = schema
+	}
+	return props
+}
+
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]any {
+	t = derefType(t)
+	switch t.Kind() {
+	case reflect.Struct:
+		return map[string]any{"$ref": g.ref(t)}
+	case reflect.String:
+		s := map[string]any{"type": "string"}
+		if enum, ok := g.enums[t.Name()]; ok && t.Name() != "string" {
+			s["enum"] = enum
+		}
+		return s
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	}
+	// Interfaces accept any value.
+	return map[string]any{}
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
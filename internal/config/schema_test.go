@@ -0,0 +1,16 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSchemaUpToDate(t *testing.T) {
+	schema, err := GenerateSchema(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(schema, Schema()) {
+		t.Fatal("config.schema.json is stale; run go generate ./internal/config")
+	}
+}
@@ -0,0 +1,20 @@
+// Command schemagen writes config.schema.json from the config package
+// sources. Run it with go generate in internal/config.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+func main() {
+	schema, err := config.GenerateSchema(".")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("config.schema.json", schema, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
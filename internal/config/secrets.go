@@ -0,0 +1,32 @@
+package config
+
+// SecretValues returns the credentials set in the configuration: client and
+// provider API keys, signing secrets and admin keys. Empty values are skipped.
+func (cfg *Config) SecretValues() []string {
+	if cfg == nil {
+		return nil
+	}
+	var out []string
+	add := func(values ...string) {
+		for _, v := range values {
+			if v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	add(cfg.APIKeys...)
+	for _, src := range cfg.Usage.Reconciliation.Sources {
+		add(src.AdminKey)
+	}
+	add(cfg.AmpCode.UpstreamAPIKey, cfg.Mirror.APIKey)
+	for _, p := range cfg.Providers {
+		add(p.APIKey, p.SigningSecret)
+		for _, k := range p.APIKeys {
+			add(k.Key)
+		}
+	}
+	for _, v := range cfg.VertexCompatAPIKey {
+		add(v.APIKey)
+	}
+	return out
+}
@@ -0,0 +1,371 @@
+// Package conversation persists Responses API conversations so clients relying
+// on server-side conversation state work against stateless upstreams.
+package conversation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	_ "modernc.org/sqlite"
+)
+
+// ErrNotFound is returned when a conversation or item does not exist for the caller.
+var ErrNotFound = errors.New("conversation: not found")
+
+// Conversation is a stored conversation. Items are kept separately.
+type Conversation struct {
+	ID        string            `json:"id"`
+	Object    string            `json:"object"`
+	CreatedAt int64             `json:"created_at"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
+// ListOptions pages through conversation items.
+type ListOptions struct {
+	// After is the item ID to start after. Empty starts at the beginning.
+	After string
+	// Limit caps the number of items. Zero uses 20.
+	Limit int
+	// Desc lists the newest items first.
+	Desc bool
+}
+
+const defaultListLimit = 20
+
+// Store keeps conversations and their items in SQLite. Every conversation is
+// scoped to the client API key that created it.
+type Store struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens (creating if needed) the conversation database at path.
+func OpenSQLite(path string) (*Store, error) {
+	if path == "" {
+		return nil, fmt.Errorf("conversation: SQLite path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("conversation: failed to create database directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", path+"?_journal_mode=WAL&_synchronous=NORMAL")
+	if err != nil {
+		return nil, fmt.Errorf("conversation: failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS conversations (
+		id TEXT PRIMARY KEY,
+		scope TEXT NOT NULL DEFAULT '',
+		metadata TEXT NOT NULL DEFAULT '{}',
+		created_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS conversation_items (
+		seq INTEGER PRIMARY KEY AUTOINCREMENT,
+		conversation_id TEXT NOT NULL,
+		id TEXT NOT NULL,
+		item TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_conversation_items_id ON conversation_items(conversation_id, id);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("conversation: failed to initialize schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Create stores a new conversation with optional initial items.
+func (s *Store) Create(ctx context.Context, scope string, metadata map[string]string, items [][]byte) (*Conversation, error) {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	meta, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	conv := &Conversation{
+		ID:        "conv_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Object:    "conversation",
+		CreatedAt: time.Now().Unix(),
+		Metadata:  metadata,
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, "INSERT INTO conversations (id, scope, metadata, created_at) VALUES (?, ?, ?, ?)",
+		conv.ID, scope, string(meta), conv.CreatedAt); err != nil {
+		return nil, err
+	}
+	if _, err := insertItems(ctx, tx, conv.ID, items); err != nil {
+		return nil, err
+	}
+	return conv, tx.Commit()
+}
+
+// Get returns the conversation with id.
+func (s *Store) Get(ctx context.Context, scope, id string) (*Conversation, error) {
+	var meta string
+	conv := &Conversation{ID: id, Object: "conversation"}
+	err := s.db.QueryRowContext(ctx, "SELECT metadata, created_at FROM conversations WHERE id = ? AND scope = ?", id, scope).
+		Scan(&meta, &conv.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(meta), &conv.Metadata); err != nil || conv.Metadata == nil {
+		conv.Metadata = map[string]string{}
+	}
+	return conv, nil
+}
+
+// UpdateMetadata replaces the metadata of a conversation.
+func (s *Store) UpdateMetadata(ctx context.Context, scope, id string, metadata map[string]string) (*Conversation, error) {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	meta, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.db.ExecContext(ctx, "UPDATE conversations SET metadata = ? WHERE id = ? AND scope = ?", string(meta), id, scope)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrNotFound
+	}
+	return s.Get(ctx, scope, id)
+}
+
+// Delete removes a conversation and its items.
+func (s *Store) Delete(ctx context.Context, scope, id string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	res, err := tx.ExecContext(ctx, "DELETE FROM conversations WHERE id = ? AND scope = ?", id, scope)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM conversation_items WHERE conversation_id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AppendItems adds items to the end of a conversation and returns them with
+// their assigned IDs.
+func (s *Store) AppendItems(ctx context.Context, scope, id string, items [][]byte) ([][]byte, error) {
+	if _, err := s.Get(ctx, scope, id); err != nil {
+		return nil, err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	added, err := insertItems(ctx, tx, id, items)
+	if err != nil {
+		return nil, err
+	}
+	return added, tx.Commit()
+}
+
+// Items returns every item of a conversation, oldest first.
+func (s *Store) Items(ctx context.Context, scope, id string) ([][]byte, error) {
+	if _, err := s.Get(ctx, scope, id); err != nil {
+		return nil, err
+	}
+	return s.queryItems(ctx, "SELECT item FROM conversation_items WHERE conversation_id = ? ORDER BY seq", id)
+}
+
+// ListItems returns one page of items and whether more follow.
+func (s *Store) ListItems(ctx context.Context, scope, id string, opts ListOptions) ([][]byte, bool, error) {
+	if _, err := s.Get(ctx, scope, id); err != nil {
+		return nil, false, err
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	order, cmp := "ASC", ">"
+	if opts.Desc {
+		order, cmp = "DESC", "<"
+	}
+	query := "SELECT item FROM conversation_items WHERE conversation_id = ?"
+	args := []any{id}
+	if opts.After != "" {
+		query += " AND seq " + cmp + " (SELECT seq FROM conversation_items WHERE conversation_id = ? AND id = ?)"
+		args = append(args, id, opts.After)
+	}
+	query += " ORDER BY seq " + order + " LIMIT ?"
+	args = append(args, limit+1)
+	items, err := s.queryItems(ctx, query, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(items) > limit {
+		return items[:limit], true, nil
+	}
+	return items, false, nil
+}
+
+// GetItem returns a single item of a conversation.
+func (s *Store) GetItem(ctx context.Context, scope, id, itemID string) ([]byte, error) {
+	if _, err := s.Get(ctx, scope, id); err != nil {
+		return nil, err
+	}
+	var item string
+	err := s.db.QueryRowContext(ctx, "SELECT item FROM conversation_items WHERE conversation_id = ? AND id = ?", id, itemID).Scan(&item)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return []byte(item), err
+}
+
+// DeleteItem removes a single item from a conversation.
+func (s *Store) DeleteItem(ctx context.Context, scope, id, itemID string) error {
+	if _, err := s.Get(ctx, scope, id); err != nil {
+		return err
+	}
+	res, err := s.db.ExecContext(ctx, "DELETE FROM conversation_items WHERE conversation_id = ? AND id = ?", id, itemID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) queryItems(ctx context.Context, query string, args ...any) ([][]byte, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items [][]byte
+	for rows.Next() {
+		var item string
+		if err := rows.Scan(&item); err != nil {
+			return nil, err
+		}
+		items = append(items, []byte(item))
+	}
+	return items, rows.Err()
+}
+
+func insertItems(ctx context.Context, tx *sql.Tx, convID string, items [][]byte) ([][]byte, error) {
+	now := time.Now().Unix()
+	added := make([][]byte, 0, len(items))
+	for _, raw := range items {
+		item, id, err := normalizeItem(raw)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO conversation_items (conversation_id, id, item, created_at) VALUES (?, ?, ?, ?) ON CONFLICT(conversation_id, id) DO NOTHING",
+			convID, id, string(item), now); err != nil {
+			return nil, err
+		}
+		added = append(added, item)
+	}
+	return added, nil
+}
+
+// normalizeItem fills in the type of bare role messages and assigns an ID to
+// items that have none.
+func normalizeItem(raw []byte) ([]byte, string, error) {
+	item := gjson.ParseBytes(raw)
+	if !item.IsObject() {
+		return nil, "", fmt.Errorf("conversation: item must be a JSON object")
+	}
+	out := []byte(item.Raw)
+	typ := item.Get("type").String()
+	if typ == "" && item.Get("role").Exists() {
+		typ = "message"
+		out, _ = sjson.SetBytes(out, "type", typ)
+	}
+	id := item.Get("id").String()
+	if id == "" {
+		id = itemIDPrefix(typ) + strings.ReplaceAll(uuid.NewString(), "-", "")
+		out, _ = sjson.SetBytes(out, "id", id)
+	}
+	return out, id, nil
+}
+
+func itemIDPrefix(typ string) string {
+	switch typ {
+	case "message":
+		return "msg_"
+	case "function_call":
+		return "fc_"
+	case "function_call_output":
+		return "fco_"
+	case "reasoning":
+		return "rs_"
+	}
+	return "item_"
+}
+
+var (
+	defaultMu    sync.RWMutex
+	defaultStore *Store
+)
+
+// SetDefault installs the store used by the HTTP handlers. Nil disables conversations.
+func SetDefault(s *Store) {
+	defaultMu.Lock()
+	defaultStore = s
+	defaultMu.Unlock()
+}
+
+// Default returns the store installed with SetDefault, or nil.
+func Default() *Store {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultStore
+}
+
+// CloseDefault closes and uninstalls the default store.
+func CloseDefault() {
+	defaultMu.Lock()
+	s := defaultStore
+	defaultStore = nil
+	defaultMu.Unlock()
+	if err := s.Close(); err != nil {
+		log.Warnf("failed to close conversation store: %v", err)
+	}
+}
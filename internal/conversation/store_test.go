@@ -0,0 +1,62 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestStoreItems(t *testing.T) {
+	s, err := OpenSQLite(filepath.Join(t.TempDir(), "conversations.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	conv, err := s.Create(ctx, "key", map[string]string{"topic": "demo"}, [][]byte{[]byte(`{"role":"user","content":"hi"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, "other", conv.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("conversation visible to another scope: %v", err)
+	}
+
+	added, err := s.AppendItems(ctx, "key", conv.ID, [][]byte{
+		[]byte(`{"type":"message","role":"assistant","content":[{"type":"output_text","text":"hello"}]}`),
+		[]byte(`{"type":"function_call","call_id":"c1","name":"f","arguments":"{}"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := gjson.GetBytes(added[1], "id").String(); len(id) < 4 || id[:3] != "fc_" {
+		t.Fatalf("function_call id = %q", id)
+	}
+
+	items, err := s.Items(ctx, "key", conv.ID)
+	if err != nil || len(items) != 3 {
+		t.Fatalf("items = %d, err = %v", len(items), err)
+	}
+	if gjson.GetBytes(items[0], "type").String() != "message" {
+		t.Fatalf("role item was not typed as message: %s", items[0])
+	}
+
+	first := gjson.GetBytes(items[0], "id").String()
+	page, more, err := s.ListItems(ctx, "key", conv.ID, ListOptions{After: first, Limit: 1})
+	if err != nil || len(page) != 1 || !more || gjson.GetBytes(page[0], "id").String() != gjson.GetBytes(items[1], "id").String() {
+		t.Fatalf("page = %s, more = %v, err = %v", page, more, err)
+	}
+
+	if err := s.DeleteItem(ctx, "key", conv.ID, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, "key", conv.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Items(ctx, "key", conv.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("items after delete: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package logging
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	defaultAccessLogMaxSizeMB  = 100
+	defaultAccessLogMaxBackups = 5
+)
+
+// AccessLogSettings configures the access log.
+type AccessLogSettings struct {
+	Enabled    bool
+	SampleRate float64 // 0 < rate <= 1; other values log every request
+	File       string  // default: access.jsonl in the logs directory
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	Compress   bool
+}
+
+// AccessLogEntry is one line of the access log. Durations are milliseconds.
+type AccessLogEntry struct {
+	Time        time.Time `json:"time"`
+	Method      string    `json:"method"`
+	Path        string    `json:"path"`
+	Status      int       `json:"status"`
+	Stream      bool      `json:"stream"`
+	Bytes       int64     `json:"bytes"`
+	Provider    string    `json:"provider,omitempty"`
+	Model       string    `json:"model,omitempty"`
+	AuthID      string    `json:"auth_id,omitempty"`
+	Attempts    int       `json:"attempts,omitempty"`
+	QueueMs     int64     `json:"queue_ms"`
+	TranslateMs int64     `json:"translate_ms"`
+	TTFBMs      int64     `json:"upstream_ttfb_ms"`
+	StreamMs    int64     `json:"stream_ms"`
+	TotalMs     int64     `json:"total_ms"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// AccessLogger writes sampled access log entries as JSON lines to a rotated file.
+type AccessLogger struct {
+	mu       sync.Mutex
+	settings AccessLogSettings
+	writer   *lumberjack.Logger
+}
+
+var accessLog = &AccessLogger{}
+
+// AccessLog returns the process-wide access logger.
+func AccessLog() *AccessLogger { return accessLog }
+
+// Configure applies settings, reopening the file when its path or rotation
+// changed. A disabled logger closes its file.
+func (l *AccessLogger) Configure(s AccessLogSettings) error {
+	if s.File == "" {
+		dir := "logs"
+		if base := writablePath(); base != "" {
+			dir = filepath.Join(base, "logs")
+		}
+		s.File = filepath.Join(dir, "access.jsonl")
+	}
+	if s.MaxSizeMB <= 0 {
+		s.MaxSizeMB = defaultAccessLogMaxSizeMB
+	}
+	if s.MaxBackups <= 0 {
+		s.MaxBackups = defaultAccessLogMaxBackups
+	}
+	if s.SampleRate <= 0 || s.SampleRate > 1 {
+		s.SampleRate = 1
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rotation := func(a AccessLogSettings) AccessLogSettings {
+		return AccessLogSettings{File: a.File, MaxSizeMB: a.MaxSizeMB, MaxBackups: a.MaxBackups, MaxAgeDays: a.MaxAgeDays, Compress: a.Compress}
+	}
+	if l.writer != nil && (!s.Enabled || rotation(s) != rotation(l.settings)) {
+		_ = l.writer.Close()
+		l.writer = nil
+	}
+	if s.Enabled && l.writer == nil {
+		if err := os.MkdirAll(filepath.Dir(s.File), 0o755); err != nil {
+			return fmt.Errorf("access log: failed to create directory: %w", err)
+		}
+		l.writer = &lumberjack.Logger{
+			Filename:   s.File,
+			MaxSize:    s.MaxSizeMB,
+			MaxBackups: s.MaxBackups,
+			MaxAge:     s.MaxAgeDays,
+			Compress:   s.Compress,
+		}
+	}
+	l.settings = s
+	return nil
+}
+
+// Settings returns the active settings with defaults applied.
+func (l *AccessLogger) Settings() AccessLogSettings {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.settings
+}
+
+// Sample reports whether the next request should be logged.
+func (l *AccessLogger) Sample() bool {
+	l.mu.Lock()
+	enabled, rate := l.settings.Enabled && l.writer != nil, l.settings.SampleRate
+	l.mu.Unlock()
+	return enabled && (rate >= 1 || rand.Float64() < rate)
+}
+
+// Write appends entry to the log.
+func (l *AccessLogger) Write(entry AccessLogEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.writer == nil {
+		return
+	}
+	if _, err := l.writer.Write(line); err != nil {
+		Warnf("access log: write failed: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// modulePrefix is stripped from package paths to form module names such as
+// "translator/claude".
+const modulePrefix = "github.com/nghyane/llm-mux/"
+
+// moduleFilter holds per-module minimum levels. A module matches its own
+// package and the packages below it; the longest match wins.
+type moduleFilter struct {
+	levels map[string]slog.Level
+	// min is the lowest level of any module.
+	min slog.Level
+}
+
+var (
+	modules     atomic.Pointer[moduleFilter]
+	moduleCache sync.Map // pc -> module name
+)
+
+// ParseLevel parses "debug", "info", "warn" or "error".
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", s)
+	}
+	return level, nil
+}
+
+// LevelName returns the lower-case name of level.
+func LevelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// SetModuleLevels replaces the per-module levels, which override the global
+// level for log calls made from the given modules. Module names are package
+// paths below the repository root with "internal/" dropped, such as
+// "translator/claude" or "api". An empty map removes all filters.
+func SetModuleLevels(levels map[string]slog.Level) {
+	if len(levels) == 0 {
+		modules.Store(nil)
+		return
+	}
+	f := &moduleFilter{levels: make(map[string]slog.Level, len(levels)), min: slog.LevelError}
+	for name, level := range levels {
+		name = strings.Trim(strings.TrimPrefix(strings.TrimPrefix(name, modulePrefix), "internal/"), "/")
+		f.levels[name] = level
+		f.min = min(f.min, level)
+	}
+	modules.Store(f)
+}
+
+// ModuleLevels returns a copy of the per-module levels.
+func ModuleLevels() map[string]slog.Level {
+	f := modules.Load()
+	out := make(map[string]slog.Level)
+	if f != nil {
+		for name, level := range f.levels {
+			out[name] = level
+		}
+	}
+	return out
+}
+
+// minEnabledLevel returns the lowest level any log call may be emitted at.
+func minEnabledLevel(global slog.Level) slog.Level {
+	if f := modules.Load(); f != nil {
+		return min(global, f.min)
+	}
+	return global
+}
+
+// moduleEnabled reports whether a record at level from the caller at pc is
+// emitted.
+func moduleEnabled(global, level slog.Level, pc uintptr) bool {
+	f := modules.Load()
+	if f == nil || pc == 0 {
+		return level >= global
+	}
+	for mod := callerModule(pc); ; {
+		if threshold, ok := f.levels[mod]; ok {
+			return level >= threshold
+		}
+		i := strings.LastIndexByte(mod, '/')
+		if i < 0 {
+			return level >= global
+		}
+		mod = mod[:i]
+	}
+}
+
+// callerModule returns the module name of the function at pc.
+func callerModule(pc uintptr) string {
+	if v, ok := moduleCache.Load(pc); ok {
+		return v.(string)
+	}
+	fs := runtime.CallersFrames([]uintptr{pc})
+	frame, _ := fs.Next()
+	mod := packagePath(frame.Function)
+	mod = strings.TrimPrefix(strings.TrimPrefix(mod, modulePrefix), "internal/")
+	moduleCache.Store(pc, mod)
+	return mod
+}
+
+// packagePath returns the import path of a fully qualified function name
+// such as "example.com/a/b.(*T).Method".
+func packagePath(fn string) string {
+	slash := strings.LastIndexByte(fn, '/')
+	if dot := strings.IndexByte(fn[slash+1:], '.'); dot >= 0 {
+		return fn[:slash+1+dot]
+	}
+	return fn
+}
+
+// defaultRecentLines is the capacity of the in-memory log buffer.
+const defaultRecentLines = 2000
+
+// lineRing keeps the most recent formatted log lines.
+type lineRing struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+var recentLines = &lineRing{lines: make([]string, defaultRecentLines)}
+
+func (r *lineRing) add(line string) {
+	r.mu.Lock()
+	r.lines[r.next] = line
+	r.next++
+	if r.next == len(r.lines) {
+		r.next, r.full = 0, true
+	}
+	r.mu.Unlock()
+}
+
+func (r *lineRing) last(n int) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	size := r.next
+	if r.full {
+		size = len(r.lines)
+	}
+	if n <= 0 || n > size {
+		n = size
+	}
+	out := make([]string, 0, n)
+	for i := r.next - n; i < r.next; i++ {
+		out = append(out, r.lines[(i+len(r.lines))%len(r.lines)])
+	}
+	return out
+}
+
+// RecentLines returns up to n of the most recently emitted log lines, oldest
+// first, regardless of where log output goes. n <= 0 returns all buffered
+// lines.
+func RecentLines(n int) []string {
+	return recentLines.last(n)
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestModuleLevels(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stdout)
+	defer SetLevel(GetLevel())
+	defer SetModuleLevels(nil)
+
+	SetLevel(slog.LevelWarn)
+	Debug("hidden before filter")
+	SetModuleLevels(map[string]slog.Level{"internal/logging": slog.LevelDebug})
+	Debug("shown by module level")
+	SetModuleLevels(map[string]slog.Level{"translator/claude": slog.LevelDebug})
+	Debug("hidden by other module level")
+	SetModuleLevels(map[string]slog.Level{"logging": slog.LevelError})
+	Warn("hidden by raised module level")
+
+	out := buf.String()
+	if !strings.Contains(out, "shown by module level") {
+		t.Errorf("module debug line missing from %q", out)
+	}
+	if strings.Contains(out, "hidden") {
+		t.Errorf("filtered line emitted: %q", out)
+	}
+	lines := RecentLines(1)
+	if len(lines) != 1 || !strings.HasSuffix(lines[0], "shown by module level") {
+		t.Errorf("RecentLines(1) = %q", lines)
+	}
+}
+
+func TestLineRing(t *testing.T) {
+	r := &lineRing{lines: make([]string, 3)}
+	if got := r.last(5); len(got) != 0 {
+		t.Fatalf("empty ring returned %q", got)
+	}
+	for _, l := range []string{"a", "b", "c", "d"} {
+		r.add(l)
+	}
+	if got := strings.Join(r.last(0), ","); got != "b,c,d" {
+		t.Errorf("last(0) = %s", got)
+	}
+	if got := strings.Join(r.last(2), ","); got != "c,d" {
+		t.Errorf("last(2) = %s", got)
+	}
+}
+
+func TestPackagePath(t *testing.T) {
+	tests := map[string]string{
+		"github.com/nghyane/llm-mux/internal/translator/claude.(*Converter).Convert": "github.com/nghyane/llm-mux/internal/translator/claude",
+		"github.com/nghyane/llm-mux/internal/api.(*Server).Start.func1":              "github.com/nghyane/llm-mux/internal/api",
+		"main.main": "main",
+	}
+	for fn, want := range tests {
+		if got := packagePath(fn); got != want {
+			t.Errorf("packagePath(%q) = %q, want %q", fn, got, want)
+		}
+	}
+}
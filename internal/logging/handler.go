@@ -28,10 +28,13 @@ func NewCustomHandler(w io.Writer, level *slog.LevelVar, addSource bool) *Custom
 }
 
 func (h *CustomHandler) Enabled(_ context.Context, level slog.Level) bool {
-	return level >= h.level.Level()
+	return level >= minEnabledLevel(h.level.Level())
 }
 
 func (h *CustomHandler) Handle(_ context.Context, r slog.Record) error {
+	if !moduleEnabled(h.level.Level(), r.Level, r.PC) {
+		return nil
+	}
 	timeStr := r.Time.Format("2006-01-02 15:04:05")
 	levelStr := strings.ToLower(r.Level.String())
 
@@ -52,24 +55,25 @@ func (h *CustomHandler) Handle(_ context.Context, r slog.Record) error {
 		attrs.WriteString(fmt.Sprintf("%v", a.Value.Any()))
 		return true
 	})
+	msg := Redact(r.Message)
+	attrStr := Redact(attrs.String())
 
-	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	if source != "" {
-		if attrs.Len() > 0 {
-			_, err := fmt.Fprintf(h.w, "[%s] [%s] [%s] %s | %s\n", timeStr, levelStr, source, r.Message, attrs.String())
-			return err
-		}
-		_, err := fmt.Fprintf(h.w, "[%s] [%s] [%s] %s\n", timeStr, levelStr, source, r.Message)
-		return err
+	var line string
+	switch {
+	case source != "" && attrStr != "":
+		line = fmt.Sprintf("[%s] [%s] [%s] %s | %s\n", timeStr, levelStr, source, msg, attrStr)
+	case source != "":
+		line = fmt.Sprintf("[%s] [%s] [%s] %s\n", timeStr, levelStr, source, msg)
+	case attrStr != "":
+		line = fmt.Sprintf("[%s] [%s] %s | %s\n", timeStr, levelStr, msg, attrStr)
+	default:
+		line = fmt.Sprintf("[%s] [%s] %s\n", timeStr, levelStr, msg)
 	}
+	recentLines.add(strings.TrimSuffix(line, "\n"))
 
-	if attrs.Len() > 0 {
-		_, err := fmt.Fprintf(h.w, "[%s] [%s] %s | %s\n", timeStr, levelStr, r.Message, attrs.String())
-		return err
-	}
-	_, err := fmt.Fprintf(h.w, "[%s] [%s] %s\n", timeStr, levelStr, r.Message)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, line)
 	return err
 }
 
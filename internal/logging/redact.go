@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// credentialPatterns match well-known credential formats, so they are masked
+// even when they are not part of the configuration.
+var credentialPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`sk-(?:ant-|proj-)?[A-Za-z0-9_\-]{20,}`),                           // OpenAI, Anthropic
+	regexp.MustCompile(`AIza[0-9A-Za-z_\-]{35}`),                                          // Google API key
+	regexp.MustCompile(`ya29\.[0-9A-Za-z_\-.]{20,}`),                                      // Google OAuth access token
+	regexp.MustCompile(`1//[0-9A-Za-z_\-]{20,}`),                                          // Google OAuth refresh token
+	regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{30,}`),                                      // GitHub token
+	regexp.MustCompile(`AKIA[0-9A-Z]{16}`),                                                // AWS access key ID
+	regexp.MustCompile(`eyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]+`), // JWT
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/\-]{16,}=*`),
+}
+
+// credentialFields match JSON fields and query parameters holding credentials;
+// the value is group 2.
+var credentialFields = regexp.MustCompile(`(?i)("?(?:api[_-]?key|access[_-]?token|refresh[_-]?token|id[_-]?token|client[_-]?secret|x-goog-api-key|password)"?\s*[:=]\s*"?)([^"&\s,}]{8,})`)
+
+var redactedSecrets atomic.Pointer[[]string]
+
+// SetRedactedSecrets replaces the configured secret values masked by Redact.
+// Values shorter than 8 characters are ignored to avoid masking common words.
+func SetRedactedSecrets(values []string) {
+	secrets := make([]string, 0, len(values))
+	seen := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if len(v) < 8 {
+			continue
+		}
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		secrets = append(secrets, v)
+	}
+	// Longest first, so a secret containing another is masked whole.
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	redactedSecrets.Store(&secrets)
+}
+
+// Redact masks configured secret values and known credential patterns in s,
+// keeping a short prefix and suffix of each for correlation.
+func Redact(s string) string {
+	if s == "" {
+		return s
+	}
+	if secrets := redactedSecrets.Load(); secrets != nil {
+		for _, secret := range *secrets {
+			if strings.Contains(s, secret) {
+				s = strings.ReplaceAll(s, secret, hideAPIKey(secret))
+			}
+		}
+	}
+	for _, re := range credentialPatterns {
+		s = re.ReplaceAllStringFunc(s, redactMatch)
+	}
+	return credentialFields.ReplaceAllStringFunc(s, func(m string) string {
+		sub := credentialFields.FindStringSubmatch(m)
+		if strings.Contains(sub[2], "...") {
+			return m
+		}
+		return sub[1] + hideAPIKey(sub[2])
+	})
+}
+
+// RedactBytes is Redact for byte slices. b is returned unchanged when nothing
+// was masked.
+func RedactBytes(b []byte) []byte {
+	if len(b) == 0 {
+		return b
+	}
+	s := string(b)
+	if r := Redact(s); r != s {
+		return []byte(r)
+	}
+	return b
+}
+
+func redactMatch(m string) string {
+	if i := strings.LastIndexAny(m, " \t"); i >= 0 {
+		return m[:i+1] + hideAPIKey(m[i+1:])
+	}
+	return hideAPIKey(m)
+}
@@ -189,7 +189,7 @@ func (l *FileRequestLogger) logRequest(url, method string, requestHeaders map[st
 	content := l.formatLogContent(url, method, requestHeaders, body, apiRequest, apiResponse, decompressedResponse, statusCode, responseHeaders, apiResponseErrors)
 
 	// Write to file
-	if err = os.WriteFile(filePath, []byte(content), 0644); err != nil {
+	if err = os.WriteFile(filePath, []byte(Redact(content)), 0644); err != nil {
 		return fmt.Errorf("failed to write log file: %w", err)
 	}
 
@@ -233,7 +233,7 @@ func (l *FileRequestLogger) LogStreamingRequest(url, method string, headers map[
 	}
 
 	// Write initial request information
-	requestInfo := l.formatRequestInfo(url, method, headers, body)
+	requestInfo := Redact(l.formatRequestInfo(url, method, headers, body))
 	if _, err = file.WriteString(requestInfo); err != nil {
 		_ = file.Close()
 		return nil, fmt.Errorf("failed to write request info: %w", err)
@@ -691,7 +691,7 @@ func (w *FileStreamingLogWriter) asyncWriter() {
 
 	for chunk := range w.chunkChan {
 		if w.file != nil {
-			_, _ = w.file.Write(chunk)
+			_, _ = w.file.Write(RedactBytes(chunk))
 		}
 	}
 }
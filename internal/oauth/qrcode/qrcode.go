@@ -0,0 +1,478 @@
+// Package qrcode renders OAuth URLs as QR codes so a login started on a
+// headless server can be approved by scanning it with a phone.
+//
+// It implements the byte-mode subset of ISO/IEC 18004 at error correction
+// level M, which is all an authorization URL needs.
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+const (
+	// quietZone is the light border, in modules, required around the symbol.
+	quietZone = 4
+	// moduleScale is the PNG size of one module in pixels.
+	moduleScale = 6
+)
+
+// eccPerBlock and numBlocks describe the level M error correction layout, indexed by version.
+var (
+	eccPerBlock = [41]int{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28}
+	numBlocks   = [41]int{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49}
+)
+
+// Code is an encoded QR symbol. Modules are indexed [y][x]; true is dark.
+type Code struct {
+	Version int
+	Size    int
+	modules [][]bool
+	isFunc  [][]bool
+}
+
+// Encode builds the smallest QR symbol that holds text.
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+	version := 0
+	for v := 1; v <= 40; v++ {
+		if 4+countBits(v)+len(data)*8 <= dataCodewords(v)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("qrcode: %d bytes exceed QR capacity", len(data))
+	}
+
+	var bb bitBuffer
+	bb.append(0x4, 4) // byte mode
+	bb.append(uint32(len(data)), countBits(version))
+	for _, b := range data {
+		bb.append(uint32(b), 8)
+	}
+	capacity := dataCodewords(version) * 8
+	bb.append(0, min(4, capacity-len(bb)))
+	bb.append(0, (8-len(bb)%8)%8)
+	for pad := uint32(0xEC); len(bb) < capacity; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+	codewords := make([]byte, len(bb)/8)
+	for i, bit := range bb {
+		if bit {
+			codewords[i>>3] |= 1 << (7 - i&7)
+		}
+	}
+
+	size := version*4 + 17
+	c := &Code{Version: version, Size: size, modules: newGrid(size), isFunc: newGrid(size)}
+	c.drawFunctionPatterns()
+	c.drawCodewords(addECCAndInterleave(codewords, version))
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		c.applyMask(mask) // masking is an XOR, so applying it again undoes it
+	}
+	c.applyMask(best)
+	c.drawFormatBits(best)
+	return c, nil
+}
+
+// Dark reports whether the module at x, y is dark.
+func (c *Code) Dark(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.modules[y][x]
+}
+
+// PNG renders the symbol with a quiet zone as a two-colour PNG.
+func (c *Code) PNG() ([]byte, error) {
+	dim := (c.Size + 2*quietZone) * moduleScale
+	img := image.NewPaletted(image.Rect(0, 0, dim, dim), color.Palette{color.White, color.Black})
+	for y := 0; y < dim; y++ {
+		for x := 0; x < dim; x++ {
+			if c.Dark(x/moduleScale-quietZone, y/moduleScale-quietZone) {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("qrcode: encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DataURI encodes text and returns the PNG as a data:image/png;base64 URI,
+// ready for an <img> tag.
+func DataURI(text string) (string, error) {
+	c, err := Encode(text)
+	if err != nil {
+		return "", err
+	}
+	img, err := c.PNG()
+	if err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(img), nil
+}
+
+func newGrid(size int) [][]bool {
+	grid := make([][]bool, size)
+	for i := range grid {
+		grid[i] = make([]bool, size)
+	}
+	return grid
+}
+
+// countBits is the width of the byte-mode character count field.
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// rawModules is the number of modules available for data and error correction.
+func rawModules(version int) int {
+	n := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		n -= (25*align-10)*align - 55
+		if version >= 7 {
+			n -= 36
+		}
+	}
+	return n
+}
+
+func dataCodewords(version int) int {
+	return rawModules(version)/8 - eccPerBlock[version]*numBlocks[version]
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(val uint32, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (val>>i)&1 != 0)
+	}
+}
+
+// addECCAndInterleave splits data into blocks, appends Reed-Solomon error
+// correction to each and interleaves the result.
+func addECCAndInterleave(data []byte, version int) []byte {
+	blocks, ecc := numBlocks[version], eccPerBlock[version]
+	raw := rawModules(version) / 8
+	numShort := blocks - raw%blocks
+	shortLen := raw / blocks
+	divisor := rsDivisor(ecc)
+
+	out := make([][]byte, blocks)
+	k := 0
+	for i := range out {
+		n := shortLen - ecc
+		if i >= numShort {
+			n++
+		}
+		dat := data[k : k+n]
+		k += n
+		block := append([]byte(nil), dat...)
+		if i < numShort {
+			block = append(block, 0) // padding so all blocks line up
+		}
+		out[i] = append(block, rsRemainder(dat, divisor)...)
+	}
+
+	result := make([]byte, 0, raw)
+	for i := range out[0] {
+		for j, block := range out {
+			if i != shortLen-ecc || j >= numShort {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// rsDivisor returns the Reed-Solomon generator polynomial of the given degree,
+// highest coefficient first with the leading 1 omitted.
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for range degree {
+		for j := range result {
+			result[j] = rsMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = rsMultiply(root, 0x02)
+	}
+	return result
+}
+
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coef := range divisor {
+			result[i] ^= rsMultiply(coef, factor)
+		}
+	}
+	return result
+}
+
+// rsMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func rsMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+func (c *Code) setFunc(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.isFunc[y][x] = true
+}
+
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.setFunc(6, i, i%2 == 0)
+		c.setFunc(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	pos := alignmentPositions(c.Version)
+	n := len(pos)
+	for i := range n {
+		for j := range n {
+			// Skip the three corners occupied by finder patterns.
+			if (i == 0 && j == 0) || (i == 0 && j == n-1) || (i == n-1 && j == 0) {
+				continue
+			}
+			c.drawAlignment(pos[i], pos[j])
+		}
+	}
+
+	c.drawFormatBits(0) // reserve the area; the real mask is drawn later
+	c.drawVersion()
+}
+
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= c.Size || yy >= c.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.setFunc(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunc(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	n := version/7 + 2
+	step := (version*8 + n*3 + 5) / (n*4 - 4) * 2
+	result := make([]int, n)
+	result[0] = 6
+	for i, pos := n-1, version*4+10; i >= 1; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+	return result
+}
+
+// drawFormatBits draws both copies of the format information for level M and mask.
+func (c *Code) drawFormatBits(mask int) {
+	data := mask // level M is 00
+	rem := data
+	for range 10 {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return (bits>>i)&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		c.setFunc(8, i, bit(i))
+	}
+	c.setFunc(8, 7, bit(6))
+	c.setFunc(8, 8, bit(7))
+	c.setFunc(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.setFunc(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.setFunc(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunc(8, c.Size-15+i, bit(i))
+	}
+	c.setFunc(8, c.Size-8, true) // always-dark module
+}
+
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+	rem := c.Version
+	for range 12 {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := c.Version<<12 | rem
+	for i := range 18 {
+		dark := (bits>>i)&1 != 0
+		a, b := c.Size-11+i%3, i/3
+		c.setFunc(a, b, dark)
+		c.setFunc(b, a, dark)
+	}
+}
+
+// drawCodewords places data in the zigzag column pairs, skipping function modules.
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // skip the vertical timing pattern
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if !c.isFunc[y][x] && i < len(data)*8 {
+					c.modules[y][x] = (data[i>>3]>>(7-i&7))&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.isFunc[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			c.modules[y][x] = c.modules[y][x] != invert
+		}
+	}
+}
+
+// finderLike is the 1:1:3:1:1 pattern with four light modules on one side.
+var finderLike = [2][11]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+// penalty scores the symbol per the mask evaluation rules; lower is easier to scan.
+func (c *Code) penalty() int {
+	score := 0
+	dark := 0
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x+1 < c.Size && y+1 < c.Size {
+				v := c.modules[y][x]
+				if v == c.modules[y][x+1] && v == c.modules[y+1][x] && v == c.modules[y+1][x+1] {
+					score += 3
+				}
+			}
+		}
+	}
+	for i := 0; i < c.Size; i++ {
+		score += c.linePenalty(func(j int) bool { return c.modules[i][j] })
+		score += c.linePenalty(func(j int) bool { return c.modules[j][i] })
+	}
+	total := c.Size * c.Size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return score + k*10
+}
+
+func (c *Code) linePenalty(at func(int) bool) int {
+	score := 0
+	run := 1
+	for j := 1; j <= c.Size; j++ {
+		if j < c.Size && at(j) == at(j-1) {
+			run++
+			continue
+		}
+		if run >= 5 {
+			score += 3 + run - 5
+		}
+		run = 1
+	}
+	for j := 0; j+11 <= c.Size; j++ {
+		for _, pat := range finderLike {
+			match := true
+			for k, want := range pat {
+				if at(j+k) != want {
+					match = false
+					break
+				}
+			}
+			if match {
+				score += 40
+			}
+		}
+	}
+	return score
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,461 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestRSRemainderMatchesSpecExample(t *testing.T) {
+	// ISO/IEC 18004 Annex I: "01234567" encoded as 1-M.
+	data := []byte{0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11}
+	want := []byte{0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55}
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("ecc = % X, want % X", got, want)
+	}
+}
+
+func TestEncodePicksVersionAndFormat(t *testing.T) {
+	c, err := Encode("https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Version != 2 || c.Size != 25 {
+		t.Errorf("got version %d size %d, want 2 and 25", c.Version, c.Size)
+	}
+
+	// Both format copies must agree and decode to level M.
+	var first, second int
+	for i := 0; i <= 5; i++ {
+		first |= b2i(c.Dark(8, i)) << i
+	}
+	first |= b2i(c.Dark(8, 7))<<6 | b2i(c.Dark(8, 8))<<7 | b2i(c.Dark(7, 8))<<8
+	for i := 9; i < 15; i++ {
+		first |= b2i(c.Dark(14-i, 8)) << i
+	}
+	for i := 0; i < 8; i++ {
+		second |= b2i(c.Dark(c.Size-1-i, 8)) << i
+	}
+	for i := 8; i < 15; i++ {
+		second |= b2i(c.Dark(8, c.Size-15+i)) << i
+	}
+	if first != second {
+		t.Fatalf("format copies differ: %015b vs %015b", first, second)
+	}
+	if level := (first ^ 0x5412) >> 13; level != 0 {
+		t.Errorf("error correction level bits = %02b, want 00 (M)", level)
+	}
+
+	long := "https://accounts.google.com/o/oauth2/auth?" + strings.Repeat("scope=x&", 60)
+	if c, err = Encode(long); err != nil || c.Version < 7 {
+		t.Errorf("long URL: version %v err %v", c, err)
+	}
+}
+
+func TestDataURIRendersPNG(t *testing.T) {
+	uri, err := DataURI("https://claude.ai/oauth/authorize?code=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(uri, "data:image/png;base64,"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := img.Bounds().Dx(); w%moduleScale != 0 || w/moduleScale < 21+2*quietZone {
+		t.Errorf("unexpected image width %d", w)
+	}
+	// Top-left finder pattern starts after the quiet zone.
+	if r, _, _, _ := img.At(quietZone*moduleScale, quietZone*moduleScale).RGBA(); r != 0 {
+		t.Error("expected dark finder module after quiet zone")
+	}
+}
+
+// specVersions lists, per ISO/IEC 18004, the alignment pattern centres and
+// the level M error correction layout (codewords per block, blocks) of the
+// versions the round-trip test covers.
+var specVersions = map[int]struct {
+	align      []int
+	ecc, block int
+}{
+	1:  {nil, 10, 1},
+	2:  {[]int{6, 18}, 16, 1},
+	3:  {[]int{6, 22}, 26, 1},
+	5:  {[]int{6, 30}, 24, 2},
+	6:  {[]int{6, 34}, 16, 4},
+	7:  {[]int{6, 22, 38}, 18, 4},
+	8:  {[]int{6, 24, 42}, 22, 4},
+	10: {[]int{6, 28, 50}, 26, 5},
+	14: {[]int{6, 26, 46, 66}, 24, 9},
+	15: {[]int{6, 26, 48, 70}, 24, 10},
+	20: {[]int{6, 34, 62, 90}, 26, 16},
+	25: {[]int{6, 32, 58, 84, 110}, 28, 21},
+	32: {[]int{6, 34, 60, 86, 112, 138}, 28, 33},
+	40: {[]int{6, 30, 58, 86, 114, 142, 170}, 28, 49},
+}
+
+// specVersionInfo is the version information of some versions from Annex D.
+var specVersionInfo = map[int]int{7: 0x07C94, 8: 0x085BC, 10: 0x0A4D3, 20: 0x149A6, 32: 0x209D5, 40: 0x28C69}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	const url = "https://accounts.google.com/o/oauth2/auth?client_id=llm-mux&state=é&scope="
+	for version, spec := range specVersions {
+		// Fill the version to capacity: one more byte must need the next version.
+		layout, _ := decodeLayout(version)
+		dataBits := (layout.total - spec.ecc*spec.block) * 8
+		n := (dataBits - 4 - countBitsSpec(version)) / 8
+		text := strings.Repeat(url, n/len(url)+1)[:n]
+
+		c, err := Encode(text)
+		if err != nil {
+			t.Fatalf("version %d: %v", version, err)
+		}
+		if c.Version != version {
+			t.Errorf("%d bytes: version %d, want %d", n, c.Version, version)
+			continue
+		}
+		if next, err := Encode(text + "x"); err == nil && next.Version <= version {
+			t.Errorf("%d bytes: still version %d", n+1, next.Version)
+		}
+		png, err := c.PNG()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := decodePNG(png)
+		if err != nil {
+			t.Errorf("version %d: %v", version, err)
+		} else if got != text {
+			t.Errorf("version %d: decoded %q, want %q", version, got, text)
+		}
+	}
+}
+
+func TestDecodeDetectsDamage(t *testing.T) {
+	c, err := Encode("https://example.com/oauth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Flip a data module in the bottom-right corner, the first codeword.
+	c.modules[c.Size-1][c.Size-1] = !c.modules[c.Size-1][c.Size-1]
+	png, err := c.PNG()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decodePNG(png); err == nil {
+		t.Error("damaged symbol decoded without error")
+	}
+}
+
+// qrLayout marks the function modules of a version and counts its codewords.
+type qrLayout struct {
+	size   int
+	isFunc [][]bool
+	total  int
+}
+
+func decodeLayout(version int) (qrLayout, error) {
+	spec, ok := specVersions[version]
+	if !ok {
+		return qrLayout{}, fmt.Errorf("version %d not in spec table", version)
+	}
+	size := 17 + 4*version
+	l := qrLayout{size: size, isFunc: make([][]bool, size)}
+	for y := range l.isFunc {
+		l.isFunc[y] = make([]bool, size)
+	}
+	mark := func(x0, y0, x1, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				l.isFunc[y][x] = true
+			}
+		}
+	}
+	// Finders with separators and format areas, the timing patterns.
+	mark(0, 0, 9, 9)
+	mark(size-8, 0, size, 9)
+	mark(0, size-8, 9, size)
+	mark(6, 0, 7, size)
+	mark(0, 6, size, 7)
+	last := len(spec.align) - 1
+	for i, ay := range spec.align {
+		for j, ax := range spec.align {
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue // a finder corner
+			}
+			mark(ax-2, ay-2, ax+3, ay+3)
+		}
+	}
+	if version >= 7 {
+		mark(size-11, 0, size-8, 6)
+		mark(0, size-11, 6, size-8)
+	}
+	for y := range size {
+		for x := range size {
+			if !l.isFunc[y][x] {
+				l.total++
+			}
+		}
+	}
+	l.total /= 8
+	return l, nil
+}
+
+// decodePNG reads a byte-mode, level M symbol rendered by PNG.
+func decodePNG(data []byte) (string, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	size := img.Bounds().Dx()/moduleScale - 2*quietZone
+	version := (size - 17) / 4
+	l, err := decodeLayout(version)
+	if err != nil {
+		return "", err
+	}
+	dark := func(x, y int) bool {
+		r, _, _, _ := img.At((x+quietZone)*moduleScale+moduleScale/2, (y+quietZone)*moduleScale+moduleScale/2).RGBA()
+		return r == 0
+	}
+	return decodeModules(dark, l, version)
+}
+
+func decodeModules(dark func(x, y int) bool, l qrLayout, version int) (string, error) {
+	size := l.size
+	bits := func(coords [][2]int) int {
+		v := 0
+		for i, c := range coords {
+			if dark(c[0], c[1]) {
+				v |= 1 << i
+			}
+		}
+		return v
+	}
+
+	// Format information, least significant bit first, in both copies.
+	var first, second [][2]int
+	for i := 0; i <= 5; i++ {
+		first = append(first, [2]int{8, i})
+	}
+	first = append(first, [2]int{8, 7}, [2]int{8, 8}, [2]int{7, 8})
+	for i := 9; i < 15; i++ {
+		first = append(first, [2]int{14 - i, 8})
+	}
+	for i := 0; i < 8; i++ {
+		second = append(second, [2]int{size - 1 - i, 8})
+	}
+	for i := 8; i < 15; i++ {
+		second = append(second, [2]int{8, size - 15 + i})
+	}
+	format := bits(first)
+	if format != bits(second) {
+		return "", errors.New("format information copies differ")
+	}
+	mask := -1
+	for m := range 8 {
+		if bchEncode(m, 0x537, 10)^0x5412 == format { // level M is 00
+			mask = m
+		}
+	}
+	if mask < 0 {
+		return "", fmt.Errorf("format information %015b is not level M", format)
+	}
+	if !dark(8, size-8) {
+		return "", errors.New("dark module is light")
+	}
+
+	if version >= 7 {
+		var a, b [][2]int
+		for i := range 18 {
+			a = append(a, [2]int{size - 11 + i%3, i / 3})
+			b = append(b, [2]int{i / 3, size - 11 + i%3})
+		}
+		info := bits(a)
+		if info != bits(b) || info != bchEncode(version, 0x1F25, 12) {
+			return "", fmt.Errorf("version information %018b does not encode version %d", info, version)
+		}
+		if want, ok := specVersionInfo[version]; ok && info != want {
+			return "", fmt.Errorf("version information %05X, want %05X", info, want)
+		}
+	}
+
+	// Codewords in the zigzag order, unmasked.
+	masked := func(i, j int) bool {
+		switch mask {
+		case 0:
+			return (i+j)%2 == 0
+		case 1:
+			return i%2 == 0
+		case 2:
+			return j%3 == 0
+		case 3:
+			return (i+j)%3 == 0
+		case 4:
+			return (i/2+j/3)%2 == 0
+		case 5:
+			return (i*j)%2+(i*j)%3 == 0
+		case 6:
+			return ((i*j)%2+(i*j)%3)%2 == 0
+		default:
+			return ((i+j)%2+(i*j)%3)%2 == 0
+		}
+	}
+	raw := make([]byte, l.total)
+	n := 0
+	upward := true
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right--
+		}
+		for vert := range size {
+			y := vert
+			if upward {
+				y = size - 1 - vert
+			}
+			for _, x := range []int{right, right - 1} {
+				if l.isFunc[y][x] || n >= l.total*8 {
+					continue
+				}
+				if dark(x, y) != masked(y, x) {
+					raw[n/8] |= 1 << (7 - n%8)
+				}
+				n++
+			}
+		}
+		upward = !upward
+	}
+
+	// De-interleave the blocks and check each one's Reed-Solomon syndromes.
+	spec := specVersions[version]
+	short := spec.block - l.total%spec.block
+	shortData := l.total/spec.block - spec.ecc
+	blocks := make([][]byte, spec.block)
+	k := 0
+	for i := range shortData + 1 {
+		for b := range blocks {
+			if i < shortData || b >= short {
+				blocks[b] = append(blocks[b], raw[k])
+				k++
+			}
+		}
+	}
+	for range spec.ecc {
+		for b := range blocks {
+			blocks[b] = append(blocks[b], raw[k])
+			k++
+		}
+	}
+	var payload []byte
+	for b, block := range blocks {
+		for i := range spec.ecc {
+			if s := gfEval(block, gfExp[i]); s != 0 {
+				return "", fmt.Errorf("block %d: syndrome %d is %d", b, i, s)
+			}
+		}
+		payload = append(payload, block[:len(block)-spec.ecc]...)
+	}
+
+	// Byte mode segment, terminator and padding.
+	read := func(pos, width int) int {
+		v := 0
+		for i := pos; i < pos+width; i++ {
+			v = v<<1 | int(payload[i/8]>>(7-i%8)&1)
+		}
+		return v
+	}
+	if mode := read(0, 4); mode != 0x4 {
+		return "", fmt.Errorf("mode %04b, want byte mode", mode)
+	}
+	width := countBitsSpec(version)
+	length := read(4, width)
+	start := 4 + width
+	if end := start + length*8; end > len(payload)*8 {
+		return "", fmt.Errorf("length %d exceeds the payload", length)
+	}
+	text := make([]byte, length)
+	for i := range text {
+		text[i] = byte(read(start+i*8, 8))
+	}
+	pos := start + length*8
+	term := min(4, len(payload)*8-pos)
+	if read(pos, term) != 0 {
+		return "", errors.New("missing terminator")
+	}
+	for i, pad := (pos+term+7)/8, byte(0xEC); i < len(payload); i, pad = i+1, pad^0xEC^0x11 {
+		if payload[i] != pad {
+			return "", fmt.Errorf("pad codeword %d is %02X, want %02X", i, payload[i], pad)
+		}
+	}
+	return string(text), nil
+}
+
+// countBitsSpec is the byte mode character count width from Table 3.
+func countBitsSpec(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// bchEncode appends the remainder of data times x^degree modulo poly.
+func bchEncode(data, poly, degree int) int {
+	rem := data << degree
+	for i := bitLen(rem) - 1; i >= degree; i-- {
+		if rem>>i&1 != 0 {
+			rem ^= poly << (i - degree)
+		}
+	}
+	return data<<degree | rem
+}
+
+func bitLen(v int) int {
+	n := 0
+	for ; v > 0; v >>= 1 {
+		n++
+	}
+	return n
+}
+
+// gfExp and gfLog are the power and logarithm tables of GF(256) with
+// primitive polynomial 0x11D and generator 2.
+var gfExp, gfLog = func() (exp [256]byte, log [256]int) {
+	x := 1
+	for i := range 255 {
+		exp[i] = byte(x)
+		log[x] = i
+		if x <<= 1; x&0x100 != 0 {
+			x ^= 0x11D
+		}
+	}
+	exp[255] = exp[0]
+	return exp, log
+}()
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[(gfLog[a]+gfLog[b])%255]
+}
+
+// gfEval evaluates the polynomial with coefficients p, highest first, at x.
+func gfEval(p []byte, x byte) byte {
+	var y byte
+	for _, c := range p {
+		y = gfMul(y, x) ^ c
+	}
+	return y
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
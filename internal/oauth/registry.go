@@ -60,6 +60,17 @@ type OAuthRequest struct {
 	// Additional metadata
 	RedirectURI string
 	Scopes      []string
+
+	// Label and Tenant are stamped onto the saved auth file when the flow completes.
+	Label  string
+	Tenant string
+
+	// ProjectID is the Google Cloud project requested for Gemini CLI onboarding.
+	ProjectID string
+
+	// Progress describes the post-authorization step in progress, such as
+	// account onboarding. Guarded by the registry lock.
+	Progress string
 }
 
 // Registry manages pending OAuth requests with thread-safe access.
@@ -225,6 +236,26 @@ func (r *Registry) Remove(state string) {
 	}
 }
 
+// SetProgress records the step a pending request is at.
+func (r *Registry) SetProgress(state, progress string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if req, exists := r.requests[state]; exists && req.Status == StatusPending {
+		req.Progress = progress
+	}
+}
+
+// GetProgress returns the last step recorded for a request and its error
+// message, if it failed.
+func (r *Registry) GetProgress(state string) (progress, errMsg string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if req, exists := r.requests[state]; exists {
+		return req.Progress, req.Error
+	}
+	return "", ""
+}
+
 // GetStatus returns the current status of a request.
 func (r *Registry) GetStatus(state string) (RequestStatus, bool) {
 	r.mu.RLock()
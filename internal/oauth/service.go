@@ -181,11 +181,15 @@ func (s *Service) GetStatus(state string) (*StatusResponse, error) {
 		return nil, fmt.Errorf("unknown OAuth state: %s", state)
 	}
 
+	status, _ := s.registry.GetStatus(state)
+	progress, errMsg := s.registry.GetProgress(state)
 	return &StatusResponse{
 		State:    req.State,
 		Provider: req.Provider,
-		Status:   string(req.Status),
+		Status:   string(status),
 		Mode:     string(req.Mode),
+		Error:    errMsg,
+		Progress: progress,
 	}, nil
 }
 
@@ -196,6 +200,7 @@ type StatusResponse struct {
 	Status   string `json:"status"`
 	Mode     string `json:"mode"`
 	Error    string `json:"error,omitempty"`
+	Progress string `json:"progress,omitempty"`
 }
 
 // Cancel cancels a pending OAuth request.
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// abandonedStreamGrace is how long an executor may keep its stream open
+// after the request was cancelled before the stream counts as abandoned.
+const abandonedStreamGrace = 5 * time.Second
+
+// abandonedStreams counts, per provider, upstream streams that kept running
+// after their request was cancelled, such as by a client disconnect. Each is
+// a layer that does not pass the cancellation on to the upstream request,
+// which keeps consuming quota for output nobody reads.
+type abandonedStreams struct {
+	counts sync.Map // provider -> *atomic.Int64
+	grace  time.Duration
+}
+
+// drain reads chunks until the executor closes it, so the executor is never
+// blocked on a send once the request is gone, and counts the stream when it
+// is still open after the grace period.
+func (a *abandonedStreams) drain(provider string, chunks <-chan StreamChunk) {
+	grace := a.grace
+	if grace <= 0 {
+		grace = abandonedStreamGrace
+	}
+	go func() {
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		for {
+			select {
+			case _, ok := <-chunks:
+				if !ok {
+					return
+				}
+			case <-timer.C:
+				counter, _ := a.counts.LoadOrStore(provider, new(atomic.Int64))
+				counter.(*atomic.Int64).Add(1)
+				log.Warnf("%s upstream stream still open %s after its request was cancelled", provider, grace)
+				for range chunks {
+				}
+				return
+			}
+		}
+	}()
+}
+
+// AbandonedStreams returns, per provider, how many upstream streams kept
+// running after their request was cancelled.
+func (m *Manager) AbandonedStreams() map[string]int64 {
+	out := make(map[string]int64)
+	if m == nil {
+		return out
+	}
+	m.abandoned.counts.Range(func(k, v any) bool {
+		out[k.(string)] = v.(*atomic.Int64).Load()
+		return true
+	})
+	return out
+}
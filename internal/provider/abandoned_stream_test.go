@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAbandonedStreams(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	defer manager.Stop()
+	manager.abandoned.grace = 10 * time.Millisecond
+
+	closed := make(chan StreamChunk)
+	close(closed)
+	manager.abandoned.drain("claude", closed)
+
+	open := make(chan StreamChunk)
+	manager.abandoned.drain("gemini", open)
+	// The executor must never block on a send after cancellation.
+	select {
+	case open <- StreamChunk{Payload: []byte("late")}:
+	case <-time.After(time.Second):
+		t.Fatal("abandoned stream is not drained")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for manager.AbandonedStreams()["gemini"] != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("AbandonedStreams() = %v, want gemini: 1", manager.AbandonedStreams())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(open)
+	if got := manager.AbandonedStreams(); len(got) != 1 {
+		t.Errorf("AbandonedStreams() = %v, want only gemini", got)
+	}
+}
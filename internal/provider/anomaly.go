@@ -0,0 +1,248 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/tidwall/gjson"
+)
+
+// Anomalous upstream response kinds.
+const (
+	AnomalyEmptyContent   = "empty_content"
+	AnomalyTruncatedJSON  = "truncated_json"
+	AnomalyRepeatedChunks = "repeated_chunks"
+)
+
+const defaultRepeatThreshold = 50
+
+// ResponseValidation configures detection of anomalous upstream responses:
+// empty content with nonzero output tokens, truncated JSON and streams
+// repeating the same chunk.
+type ResponseValidation struct {
+	Enabled bool
+	// Retry fails anomalous non-streaming responses so they are retried on
+	// another auth or provider, and cuts off streams repeating chunks. The
+	// auth cools down as after a 502.
+	Retry bool
+	// RepeatThreshold is the number of identical consecutive stream chunks
+	// treated as anomalous (default 50).
+	RepeatThreshold int
+}
+
+// AnomalyError reports an anomalous upstream response.
+type AnomalyError struct {
+	Kind   string
+	Detail string
+}
+
+func (e *AnomalyError) Error() string {
+	return fmt.Sprintf("anomalous upstream response (%s): %s", e.Kind, e.Detail)
+}
+
+// StatusCode implements StatusCodeError.
+func (e *AnomalyError) StatusCode() int { return http.StatusBadGateway }
+
+// Category implements CategoryProvider.
+func (e *AnomalyError) Category() ErrorCategory { return CategoryTransient }
+
+// SetResponseValidation replaces the upstream response validation settings.
+func (m *Manager) SetResponseValidation(v ResponseValidation) {
+	if m == nil {
+		return
+	}
+	if v.RepeatThreshold <= 0 {
+		v.RepeatThreshold = defaultRepeatThreshold
+	}
+	m.validation.Store(&v)
+}
+
+func (m *Manager) responseValidation() *ResponseValidation {
+	if v := m.validation.Load(); v != nil && v.Enabled {
+		return v
+	}
+	return nil
+}
+
+// validateResponse checks a non-streaming response and reports whether it
+// should be retried on another auth.
+func (m *Manager) validateResponse(ctx context.Context, provider, model, authID string, format Format, payload []byte) (*AnomalyError, bool) {
+	v := m.responseValidation()
+	if v == nil {
+		return nil, false
+	}
+	check := newResponseCheck(format, 0)
+	anomaly := check.observe(payload)
+	if anomaly == nil {
+		anomaly = check.finish()
+	}
+	if anomaly == nil {
+		return nil, false
+	}
+	action := "logged"
+	if v.Retry {
+		action = "retried"
+	}
+	recordAnomaly(ctx, anomaly, provider, model, authID, false, action)
+	return anomaly, v.Retry
+}
+
+// anomalyResult is the failed result recorded for an auth that returned an
+// anomalous response.
+func anomalyResult(authID, provider, model string, anomaly *AnomalyError) Result {
+	return Result{AuthID: authID, Provider: provider, Model: model, Success: false, Error: &Error{
+		Code:        anomaly.Kind,
+		Message:     anomaly.Error(),
+		HTTPStatus:  anomaly.StatusCode(),
+		ErrCategory: anomaly.Category(),
+	}}
+}
+
+// recordAnomaly logs a structured record of an anomalous response.
+func recordAnomaly(ctx context.Context, err *AnomalyError, provider, model, authID string, stream bool, action string) {
+	fields := log.Fields{
+		"anomaly":  err.Kind,
+		"provider": provider,
+		"model":    model,
+		"auth_id":  authID,
+		"stream":   stream,
+		"priority": PriorityFrom(ctx).String(),
+		"action":   action,
+	}
+	log.WithFields(fields).Warnf("anomalous upstream response: %s", err.Detail)
+}
+
+// Paths holding generated content and output token counts per client format,
+// covering both complete responses and stream chunks.
+var (
+	anomalyContentPaths = map[Format][]string{
+		"openai": {
+			"choices.#.message.content", "choices.#.message.tool_calls", "choices.#.message.reasoning_content",
+			"choices.#.delta.content", "choices.#.delta.tool_calls", "choices.#.delta.reasoning_content",
+			"choices.#.text",
+		},
+		"claude": {
+			"content.#.text", "content.#.thinking", "content.#.name",
+			"delta.text", "delta.thinking", "delta.partial_json", "content_block.name",
+		},
+		"gemini": {
+			"candidates.#.content.parts.#.text", "candidates.#.content.parts.#.functionCall",
+			"candidates.#.content.parts.#.executableCode", "candidates.#.content.parts.#.inlineData",
+		},
+		"openai-response": {
+			"output.#.content.#.text", "output.#.arguments", "output.#.summary.#.text",
+			"response.output.#.content.#.text", "response.output.#.arguments", "delta",
+		},
+		"ollama": {"message.content", "message.tool_calls", "message.thinking", "response"},
+	}
+	anomalyTokenPaths = map[Format][]string{
+		"openai":          {"usage.completion_tokens"},
+		"claude":          {"usage.output_tokens"},
+		"gemini":          {"usageMetadata.candidatesTokenCount"},
+		"openai-response": {"usage.output_tokens", "response.usage.output_tokens"},
+		"ollama":          {"eval_count"},
+	}
+)
+
+// responseCheck inspects a response, or the chunks of a stream, in the
+// client's format.
+type responseCheck struct {
+	format          Format
+	repeatThreshold int
+
+	content      bool
+	outputTokens int64
+	last         []byte
+	repeats      int
+}
+
+func newResponseCheck(format Format, repeatThreshold int) *responseCheck {
+	return &responseCheck{format: format, repeatThreshold: repeatThreshold}
+}
+
+// observe inspects a response body or stream chunk and returns the anomaly it
+// shows, if any. Empty content is only reported by finish.
+func (c *responseCheck) observe(payload []byte) *AnomalyError {
+	if c.repeatThreshold > 0 && len(bytes.TrimSpace(payload)) > 0 {
+		if bytes.Equal(payload, c.last) {
+			c.repeats++
+			if c.repeats == c.repeatThreshold {
+				return &AnomalyError{Kind: AnomalyRepeatedChunks, Detail: fmt.Sprintf("%d identical consecutive chunks", c.repeats)}
+			}
+		} else {
+			c.last = append(c.last[:0], payload...)
+			c.repeats = 1
+		}
+	}
+	for _, doc := range jsonDocuments(payload) {
+		if !json.Valid(doc) {
+			return &AnomalyError{Kind: AnomalyTruncatedJSON, Detail: fmt.Sprintf("unparseable JSON body of %d bytes", len(doc))}
+		}
+		parsed := gjson.ParseBytes(doc)
+		if !c.content {
+			for _, path := range anomalyContentPaths[c.format] {
+				if hasContent(parsed.Get(path)) {
+					c.content = true
+					break
+				}
+			}
+		}
+		for _, path := range anomalyTokenPaths[c.format] {
+			if n := parsed.Get(path).Int(); n > c.outputTokens {
+				c.outputTokens = n
+			}
+		}
+	}
+	return nil
+}
+
+// finish reports a response that claims output tokens but carries no content.
+func (c *responseCheck) finish() *AnomalyError {
+	if c.content || c.outputTokens <= 0 {
+		return nil
+	}
+	return &AnomalyError{Kind: AnomalyEmptyContent, Detail: fmt.Sprintf("no content with %d output tokens", c.outputTokens)}
+}
+
+// jsonDocuments returns the JSON documents in a response body or SSE chunk.
+func jsonDocuments(payload []byte) [][]byte {
+	trimmed := bytes.TrimSpace(payload)
+	if len(trimmed) == 0 {
+		return nil
+	}
+	if trimmed[0] == '{' || trimmed[0] == '[' {
+		return [][]byte{trimmed}
+	}
+	var docs [][]byte
+	for _, line := range bytes.Split(trimmed, []byte("\n")) {
+		data, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data:"))
+		if !ok {
+			continue
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '{' {
+			docs = append(docs, data)
+		}
+	}
+	return docs
+}
+
+func hasContent(v gjson.Result) bool {
+	switch {
+	case !v.Exists():
+		return false
+	case v.IsArray():
+		for _, item := range v.Array() {
+			if hasContent(item) {
+				return true
+			}
+		}
+		return false
+	case v.IsObject():
+		return len(v.Map()) > 0
+	}
+	return v.String() != ""
+}
@@ -0,0 +1,52 @@
+package provider
+
+import "testing"
+
+func TestResponseCheckEmptyContent(t *testing.T) {
+	tests := []struct {
+		format  Format
+		payload string
+		want    bool
+	}{
+		{"openai", `{"choices":[{"message":{"role":"assistant","content":""}}],"usage":{"completion_tokens":42}}`, true},
+		{"openai", `{"choices":[{"message":{"content":null,"tool_calls":[{"id":"t1"}]}}],"usage":{"completion_tokens":42}}`, false},
+		{"openai", `{"choices":[{"message":{"content":""}}],"usage":{"completion_tokens":0}}`, false},
+		{"claude", `{"content":[],"usage":{"output_tokens":7}}`, true},
+		{"claude", `{"content":[{"type":"text","text":"hi"}],"usage":{"output_tokens":7}}`, false},
+		{"gemini", `{"candidates":[{"content":{"parts":[{"text":""}]}}],"usageMetadata":{"candidatesTokenCount":3}}`, true},
+		{"ollama", `{"message":{"content":""},"eval_count":12}`, true},
+	}
+	for _, tt := range tests {
+		c := newResponseCheck(tt.format, 0)
+		if a := c.observe([]byte(tt.payload)); a != nil {
+			t.Fatalf("%s %s: unexpected %v", tt.format, tt.payload, a)
+		}
+		if got := c.finish() != nil; got != tt.want {
+			t.Errorf("%s %s: empty content = %v, want %v", tt.format, tt.payload, got, tt.want)
+		}
+	}
+}
+
+func TestResponseCheckStream(t *testing.T) {
+	c := newResponseCheck("openai", 3)
+	chunk := []byte("data: {\"choices\":[{\"delta\":{\"content\":\"\\n\"}}]}\n\n")
+	for i := 0; i < 2; i++ {
+		if a := c.observe(chunk); a != nil {
+			t.Fatalf("chunk %d: unexpected %v", i, a)
+		}
+	}
+	if a := c.observe(chunk); a == nil || a.Kind != AnomalyRepeatedChunks {
+		t.Fatalf("third identical chunk: got %v", a)
+	}
+	if a := c.finish(); a != nil {
+		t.Errorf("stream with content reported %v", a)
+	}
+
+	c = newResponseCheck("claude", 3)
+	if a := c.observe([]byte("event: content_block_delta\ndata: {\"delta\":{\"text\":\"hel")); a == nil || a.Kind != AnomalyTruncatedJSON {
+		t.Errorf("truncated chunk: got %v", a)
+	}
+	if a := c.observe([]byte("data: [DONE]\n\n")); a != nil {
+		t.Errorf("done marker: got %v", a)
+	}
+}
@@ -70,7 +70,8 @@ type AuthRegistry struct {
 	stopOnce sync.Once
 	wg       sync.WaitGroup
 
-	getExecutor func(provider string) ProviderExecutor
+	getExecutor  func(provider string) ProviderExecutor
+	refreshHooks *refreshHooks
 
 	hook Hook
 
@@ -108,6 +109,11 @@ func (r *AuthRegistry) SetExecutorProvider(fn func(provider string) ProviderExec
 	r.getExecutor = fn
 }
 
+// SetRefreshHooks makes refreshes run hooks ahead of the executors' refreshers.
+func (r *AuthRegistry) SetRefreshHooks(hooks *refreshHooks) {
+	r.refreshHooks = hooks
+}
+
 func (r *AuthRegistry) Start() {
 	r.wg.Add(2)
 	go r.refreshLoop()
@@ -536,7 +542,13 @@ func (r *AuthRegistry) doRefresh(authID string) {
 	auth := entry.ToAuth()
 	for attempt := 0; attempt < 3; attempt++ {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		updated, err := exec.Refresh(ctx, auth)
+		var updated *Auth
+		var err error
+		if r.refreshHooks != nil {
+			updated, err = r.refreshHooks.refresh(ctx, exec, auth)
+		} else {
+			updated, err = exec.Refresh(ctx, auth)
+		}
 		cancel()
 
 		if err == nil && updated != nil {
@@ -765,6 +777,8 @@ func (r *AuthRegistry) handleFailureResult(ctx context.Context, entry *AuthEntry
 			newState.QuotaExceeded = true
 			newState.QuotaReason = "quota"
 			newState.QuotaRecover = next.UnixNano()
+		case StatusOverloaded:
+			newState.NextRetryAfter = now.Add(overloadCooldown(result.RetryAfter)).UnixNano()
 		case 408, 500, 502, 503, 504:
 			newState.NextRetryAfter = now.Add(time.Minute).UnixNano()
 		default:
@@ -841,6 +855,9 @@ func (r *AuthRegistry) applyAuthLevelFailure(entry *AuthEntry, result Result, no
 		case CategoryTransient:
 			newMeta.StatusMessage = "transient upstream error"
 			newMeta.NextRetryAfter = now.Add(time.Minute)
+		case CategoryOverloaded:
+			newMeta.StatusMessage = "upstream overloaded"
+			newMeta.NextRetryAfter = now.Add(overloadCooldown(result.RetryAfter))
 		case CategoryUserError:
 			newMeta.StatusMessage = "user_request_error"
 			newMeta.Status = StatusActive
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/nghyane/llm-mux/internal/sseutil"
+)
+
+// authTagsKey is the auth file field holding user-defined tags.
+const authTagsKey = "tags"
+
+// AuthTags returns the user-defined tags (tier=pro, region=eu) stored in auth metadata.
+func AuthTags(metadata map[string]any) map[string]string {
+	switch raw := metadata[authTagsKey].(type) {
+	case map[string]string:
+		return raw
+	case map[string]any:
+		tags := make(map[string]string, len(raw))
+		for k, v := range raw {
+			if s, ok := v.(string); ok {
+				tags[k] = s
+			}
+		}
+		return tags
+	}
+	return nil
+}
+
+// Tags returns the auth's user-defined tags.
+func (a *Auth) Tags() map[string]string {
+	if a == nil {
+		return nil
+	}
+	return AuthTags(a.Metadata)
+}
+
+// SetTags replaces the auth's tags in metadata so they persist with the auth file.
+// An empty map removes them.
+func (a *Auth) SetTags(tags map[string]string) {
+	if len(tags) == 0 {
+		delete(a.Metadata, authTagsKey)
+		return
+	}
+	if a.Metadata == nil {
+		a.Metadata = make(map[string]any)
+	}
+	raw := make(map[string]any, len(tags))
+	for k, v := range tags {
+		raw[k] = v
+	}
+	a.Metadata[authTagsKey] = raw
+}
+
+// AuthTagFilter restricts requests for matching models to auths carrying Tags.
+type AuthTagFilter struct {
+	// Provider is the provider identifier. Empty matches every provider.
+	Provider string
+	// ModelPattern is a glob-style model pattern. Empty matches every model.
+	ModelPattern string
+	// APIKeys limits the filter to these client keys. Empty applies it to every client.
+	APIKeys []string
+	// Tags must all be present on an auth with the same values.
+	Tags map[string]string
+}
+
+type authTagFilters struct {
+	mu    sync.RWMutex
+	rules []AuthTagFilter
+}
+
+// required merges the tags of every filter matching the request.
+func (f *authTagFilters) required(ctx context.Context, provider, model string) map[string]string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if len(f.rules) == 0 {
+		return nil
+	}
+	apiKey := ClientAPIKey(ctx)
+	var required map[string]string
+	for _, r := range f.rules {
+		if r.Provider != "" && r.Provider != provider {
+			continue
+		}
+		if r.ModelPattern != "" && !sseutil.MatchModelPattern(r.ModelPattern, model) {
+			continue
+		}
+		if len(r.APIKeys) > 0 && !slices.Contains(r.APIKeys, apiKey) {
+			continue
+		}
+		if required == nil {
+			required = make(map[string]string, len(r.Tags))
+		}
+		for k, v := range r.Tags {
+			required[k] = v
+		}
+	}
+	return required
+}
+
+// MatchTags reports whether have carries every tag in want.
+func MatchTags(have, want map[string]string) bool {
+	for k, v := range want {
+		if got, ok := have[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *authTagFilters) set(rules []AuthTagFilter) {
+	f.mu.Lock()
+	f.rules = append([]AuthTagFilter(nil), rules...)
+	f.mu.Unlock()
+}
+
+type clientAPIKeyContextKey struct{}
+
+// WithClientAPIKey records the API key the client authenticated with, so
+// per-key routing filters can apply during auth selection.
+func WithClientAPIKey(ctx context.Context, apiKey string) context.Context {
+	if apiKey == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, clientAPIKeyContextKey{}, apiKey)
+}
+
+// ClientAPIKey returns the client API key recorded by WithClientAPIKey.
+func ClientAPIKey(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	key, _ := ctx.Value(clientAPIKeyContextKey{}).(string)
+	return key
+}
@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAuthTagFiltersRequired(t *testing.T) {
+	var f authTagFilters
+	f.set([]AuthTagFilter{
+		{ModelPattern: "claude-*", Tags: map[string]string{"tier": "pro"}},
+		{APIKeys: []string{"sk-eu"}, Tags: map[string]string{"region": "eu"}},
+	})
+
+	eu := WithClientAPIKey(context.Background(), "sk-eu")
+	got := f.required(eu, "claude", "claude-sonnet-4-5")
+	if got["tier"] != "pro" || got["region"] != "eu" {
+		t.Fatalf("expected merged tags, got %v", got)
+	}
+	if got := f.required(context.Background(), "claude", "claude-sonnet-4-5"); got["region"] != "" || got["tier"] != "pro" {
+		t.Fatalf("key filter should not apply to other clients, got %v", got)
+	}
+	if got := f.required(context.Background(), "gemini", "gemini-2.5-pro"); got != nil {
+		t.Fatalf("expected no required tags, got %v", got)
+	}
+}
+
+func TestAuthTagsRoundTrip(t *testing.T) {
+	a := &Auth{}
+	a.SetTags(map[string]string{"region": "eu", "owner": "alice"})
+	if !MatchTags(a.Tags(), map[string]string{"region": "eu"}) {
+		t.Fatalf("tags = %v", a.Tags())
+	}
+	if MatchTags(a.Tags(), map[string]string{"region": "us"}) {
+		t.Fatal("mismatched tag value should not match")
+	}
+	a.SetTags(nil)
+	if _, ok := a.Metadata[authTagsKey]; ok {
+		t.Fatal("empty tags should be removed from metadata")
+	}
+}
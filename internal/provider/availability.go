@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// Model availability states reported by Availability.
+const (
+	AvailabilityAvailable   = "available"
+	AvailabilityDegraded    = "degraded"
+	AvailabilityCoolingDown = "cooling_down"
+	AvailabilityUnavailable = "unavailable"
+)
+
+// ModelAvailability summarizes whether requests for a model can be served now.
+type ModelAvailability struct {
+	Status string
+	// CoolingDownUntil is when the first blocked auth recovers. It is only set
+	// when no auth is usable.
+	CoolingDownUntil time.Time
+	// Usable counts auths that would be picked now; Total counts every enabled
+	// auth serving the model for the caller.
+	Usable int
+	Total  int
+}
+
+// Availability reports the state of model across providers as seen by the
+// client in ctx: auth tag filters and premium reservations apply, and auths
+// blocked by cooldowns, daily budgets, spend limits or concurrency limits are
+// not usable. A model is degraded when only some auths are usable, a
+// provider circuit is not closed or a provider fails its canary.
+func (m *Manager) Availability(ctx context.Context, providers []string, model string) ModelAvailability {
+	var out ModelAvailability
+	if m == nil {
+		out.Status = AvailabilityUnavailable
+		return out
+	}
+	now := time.Now()
+	providerSet := make(map[string]struct{}, len(providers))
+	for _, p := range providers {
+		if key := strings.ToLower(strings.TrimSpace(p)); key != "" {
+			providerSet[key] = struct{}{}
+		}
+	}
+	modelKey := strings.TrimSpace(model)
+	required := make(map[string]map[string]string, len(providerSet))
+	for p := range providerSet {
+		required[p] = m.tagFilters.required(ctx, p, modelKey)
+	}
+
+	m.mu.RLock()
+	for _, auth := range m.auths {
+		if auth == nil {
+			continue
+		}
+		req, ok := required[strings.ToLower(strings.TrimSpace(auth.Provider))]
+		if !ok {
+			continue
+		}
+		reason, next := m.authRejection(ctx, auth, modelKey, req, now)
+		switch reason {
+		case RejectDisabled, RejectModelUnsupported, RejectTagFilter, RejectPremiumReserved:
+			continue
+		}
+		out.Total++
+		switch reason {
+		case "":
+			out.Usable++
+		case RejectCoolingDown, RejectUnavailable:
+			if !next.IsZero() && (out.CoolingDownUntil.IsZero() || next.Before(out.CoolingDownUntil)) {
+				out.CoolingDownUntil = next
+			}
+		}
+	}
+	m.mu.RUnlock()
+
+	providerDegraded := false
+	for p := range providerSet {
+		if m.BreakerState(p) != gobreaker.StateClosed || m.canaries.isFailing(p, modelKey) {
+			providerDegraded = true
+		}
+	}
+
+	switch {
+	case out.Usable > 0:
+		out.CoolingDownUntil = time.Time{}
+		out.Status = AvailabilityAvailable
+		if out.Usable < out.Total || providerDegraded {
+			out.Status = AvailabilityDegraded
+		}
+	case !out.CoolingDownUntil.IsZero():
+		out.Status = AvailabilityCoolingDown
+	default:
+		out.Status = AvailabilityUnavailable
+	}
+	return out
+}
@@ -0,0 +1,34 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestManagerAvailability(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	defer manager.Stop()
+	ctx := context.Background()
+
+	recoverAt := time.Now().Add(time.Minute)
+	_, _ = manager.Register(ctx, &Auth{ID: "a1", Provider: "claude", Unavailable: true, NextRetryAfter: recoverAt})
+
+	got := manager.Availability(ctx, []string{"claude"}, "")
+	if got.Status != AvailabilityCoolingDown || got.Usable != 0 || got.Total != 1 {
+		t.Fatalf("availability = %+v", got)
+	}
+	if !got.CoolingDownUntil.Equal(recoverAt) {
+		t.Errorf("cooling down until %v, want %v", got.CoolingDownUntil, recoverAt)
+	}
+
+	_, _ = manager.Register(ctx, &Auth{ID: "a2", Provider: "claude"})
+	got = manager.Availability(ctx, []string{"claude"}, "")
+	if got.Status != AvailabilityDegraded || got.Usable != 1 || got.Total != 2 || !got.CoolingDownUntil.IsZero() {
+		t.Fatalf("availability = %+v", got)
+	}
+
+	if got := manager.Availability(ctx, []string{"gemini"}, ""); got.Status != AvailabilityUnavailable {
+		t.Fatalf("availability without auths = %+v", got)
+	}
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// CanaryFailure is a canary whose latest run of a model on a provider failed.
+type CanaryFailure struct {
+	Canary   string    `json:"canary"`
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Error    string    `json:"error"`
+	Since    time.Time `json:"since"`
+}
+
+// canaryHealth tracks the failing canaries per provider:model. The zero
+// value is ready to use.
+type canaryHealth struct {
+	mu      sync.RWMutex
+	failing map[string]map[string]CanaryFailure // provider:model -> canary
+}
+
+func (h *canaryHealth) record(canary, provider, model string, err error) {
+	key := provider + ":" + model
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil {
+		delete(h.failing[key], canary)
+		if len(h.failing[key]) == 0 {
+			delete(h.failing, key)
+		}
+		return
+	}
+	if h.failing == nil {
+		h.failing = make(map[string]map[string]CanaryFailure)
+	}
+	if h.failing[key] == nil {
+		h.failing[key] = make(map[string]CanaryFailure)
+	}
+	since := time.Now()
+	if prev, ok := h.failing[key][canary]; ok {
+		since = prev.Since
+	}
+	h.failing[key][canary] = CanaryFailure{Canary: canary, Provider: provider, Model: model, Error: err.Error(), Since: since}
+}
+
+func (h *canaryHealth) isFailing(provider, model string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.failing[provider+":"+model]) > 0
+}
+
+// reorder moves providers failing a canary for model behind the others,
+// preserving relative order. If every provider fails the order is kept.
+func (h *canaryHealth) reorder(providers []string, model string) []string {
+	if len(providers) <= 1 {
+		return providers
+	}
+	h.mu.RLock()
+	empty := len(h.failing) == 0
+	h.mu.RUnlock()
+	if empty {
+		return providers
+	}
+	healthy := make([]string, 0, len(providers))
+	var failing []string
+	for _, p := range providers {
+		if h.isFailing(p, model) {
+			failing = append(failing, p)
+		} else {
+			healthy = append(healthy, p)
+		}
+	}
+	if len(failing) == 0 || len(healthy) == 0 {
+		return providers
+	}
+	return append(healthy, failing...)
+}
+
+// MarkCanaryResult records the outcome of a run of canary for model on
+// provider. While any canary of the pair fails, the model is reported
+// degraded and the provider is moved behind healthy ones.
+func (m *Manager) MarkCanaryResult(canary, provider, model string, err error) {
+	if m == nil {
+		return
+	}
+	m.canaries.record(canary, provider, model, err)
+}
+
+// CanaryFailures returns the failing canaries ordered by provider, model
+// and canary.
+func (m *Manager) CanaryFailures() []CanaryFailure {
+	if m == nil {
+		return nil
+	}
+	m.canaries.mu.RLock()
+	out := make([]CanaryFailure, 0, len(m.canaries.failing))
+	for _, byCanary := range m.canaries.failing {
+		for _, f := range byCanary {
+			out = append(out, f)
+		}
+	}
+	m.canaries.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		return a.Canary < b.Canary
+	})
+	return out
+}
@@ -0,0 +1,267 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/sseutil"
+)
+
+const defaultConcurrencyQueueTimeout = 30 * time.Second
+
+// ConcurrencyLimit caps in-flight requests per auth for models matching ModelPattern.
+type ConcurrencyLimit struct {
+	// Provider is the provider identifier ("gemini-cli"). Empty matches every provider.
+	Provider string
+	// ModelPattern is a glob-style model pattern ("gemini-2.5-pro", "*pro*").
+	ModelPattern string
+	// MaxPerAuth is how many requests may run at once on a single auth.
+	MaxPerAuth int
+	// QueueTimeout is how long a request waits for a free slot once every auth is busy
+	// (default 30s). Negative fails at once so the next provider is tried.
+	QueueTimeout time.Duration
+}
+
+// ConcurrencyLimiter counts in-flight requests per provider, model and auth.
+// Auths at their limit are skipped during selection; when all are busy the
+// request queues until a slot is released instead of hitting the upstream 429.
+// Queued requests of higher priority take released slots first.
+type ConcurrencyLimiter struct {
+	mu       sync.Mutex
+	rules    []ConcurrencyLimit
+	inFlight map[string]int
+	waiting  map[string]map[Priority]int // queued requests per provider:model
+	wake     chan struct{}               // closed and replaced whenever a slot is released
+}
+
+// NewConcurrencyLimiter creates a limiter with no rules.
+func NewConcurrencyLimiter() *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		inFlight: make(map[string]int),
+		waiting:  make(map[string]map[Priority]int),
+		wake:     make(chan struct{}),
+	}
+}
+
+// SetRules replaces the active limits. In-flight counts are kept.
+func (l *ConcurrencyLimiter) SetRules(rules []ConcurrencyLimit) {
+	if l == nil {
+		return
+	}
+	normalized := make([]ConcurrencyLimit, 0, len(rules))
+	for _, r := range rules {
+		if r.ModelPattern == "" || r.MaxPerAuth <= 0 {
+			continue
+		}
+		if r.QueueTimeout == 0 {
+			r.QueueTimeout = defaultConcurrencyQueueTimeout
+		}
+		normalized = append(normalized, r)
+	}
+	l.mu.Lock()
+	l.rules = normalized
+	l.mu.Unlock()
+}
+
+// ruleFor returns the first rule matching provider and model. Caller must hold l.mu.
+func (l *ConcurrencyLimiter) ruleFor(provider, model string) (ConcurrencyLimit, bool) {
+	for _, r := range l.rules {
+		if (r.Provider == "" || r.Provider == provider) && sseutil.MatchModelPattern(r.ModelPattern, model) {
+			return r, true
+		}
+	}
+	return ConcurrencyLimit{}, false
+}
+
+// Saturated reports whether authID has no free slot for model.
+func (l *ConcurrencyLimiter) Saturated(provider, model, authID string) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rule, ok := l.ruleFor(provider, model)
+	return ok && l.inFlight[provider+":"+model+":"+authID] >= rule.MaxPerAuth
+}
+
+// TryAcquire takes a slot for authID if one is free. The returned release func
+// must be called exactly once when the request finishes; it is a no-op when no
+// limit applies.
+func (l *ConcurrencyLimiter) TryAcquire(provider, model, authID string) (func(), bool) {
+	if l == nil {
+		return func() {}, true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rule, ok := l.ruleFor(provider, model)
+	if !ok {
+		return func() {}, true
+	}
+	key := provider + ":" + model + ":" + authID
+	if l.inFlight[key] >= rule.MaxPerAuth {
+		return nil, false
+	}
+	l.inFlight[key]++
+	var once sync.Once
+	return func() { once.Do(func() { l.release(key) }) }, true
+}
+
+func (l *ConcurrencyLimiter) release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inFlight[key] <= 1 {
+		delete(l.inFlight, key)
+	} else {
+		l.inFlight[key]--
+	}
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// enqueue records a request waiting for a slot on provider and model. The
+// returned func removes it and must be called once the request stops waiting.
+func (l *ConcurrencyLimiter) enqueue(provider, model string, p Priority) func() {
+	if l == nil {
+		return func() {}
+	}
+	key := provider + ":" + model
+	l.mu.Lock()
+	if l.waiting[key] == nil {
+		l.waiting[key] = make(map[Priority]int)
+	}
+	l.waiting[key][p]++
+	l.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			if l.waiting[key][p] <= 1 {
+				delete(l.waiting[key], p)
+				if len(l.waiting[key]) == 0 {
+					delete(l.waiting, key)
+				}
+			} else {
+				l.waiting[key][p]--
+			}
+			// Lower-priority waiters may now take a free slot.
+			close(l.wake)
+			l.wake = make(chan struct{})
+		})
+	}
+}
+
+// outranked reports whether a request of higher priority than p is waiting
+// for a slot on provider and model.
+func (l *ConcurrencyLimiter) outranked(provider, model string, p Priority) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for queued := range l.waiting[provider+":"+model] {
+		if queued > p {
+			return true
+		}
+	}
+	return false
+}
+
+// released returns a channel closed on the next slot release.
+func (l *ConcurrencyLimiter) released() <-chan struct{} {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.wake
+}
+
+// queueTimeout returns how long to wait for a slot on provider and model.
+func (l *ConcurrencyLimiter) queueTimeout(provider, model string) time.Duration {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if rule, ok := l.ruleFor(provider, model); ok {
+		return rule.QueueTimeout
+	}
+	return 0
+}
+
+// InFlight returns the current in-flight counts keyed by provider:model:auth.
+func (l *ConcurrencyLimiter) InFlight() map[string]int {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make(map[string]int, len(l.inFlight))
+	for k, v := range l.inFlight {
+		out[k] = v
+	}
+	return out
+}
+
+func isConcurrencyLimited(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == "concurrency_limited"
+}
+
+// pickNextWithSlot picks an auth and takes a concurrency slot on it. Auths at
+// their limit are skipped so the request routes to an idle one; when every auth
+// is busy it waits for a release up to the rule's queue timeout.
+func (m *Manager) pickNextWithSlot(ctx context.Context, provider, model string, opts Options, tried map[string]struct{}) (*Auth, ProviderExecutor, func(), error) {
+	priority := PriorityFrom(ctx)
+	var deadline <-chan time.Time
+	for {
+		if errCtx := ctx.Err(); errCtx != nil {
+			return nil, nil, nil, errCtx
+		}
+		wake := m.concurrency.released()
+		// Free slots go to queued requests of higher priority first.
+		if !m.concurrency.outranked(provider, model, priority) {
+			auth, executor, err := m.pickNextFromRegistry(ctx, provider, model, opts, tried)
+			if err == nil {
+				if release, ok := m.concurrency.TryAcquire(provider, model, auth.ID); ok {
+					RequestTimingsFrom(ctx).SetTarget(provider, model, auth.ID)
+					return auth, executor, release, nil
+				}
+			} else if !isConcurrencyLimited(err) {
+				return nil, nil, nil, err
+			}
+		}
+
+		if deadline == nil {
+			wait := m.concurrency.queueTimeout(provider, model)
+			if wait <= 0 {
+				return nil, nil, nil, concurrencyLimitedError(provider, model)
+			}
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			deadline = timer.C
+			defer m.concurrency.enqueue(provider, model, priority)()
+			defer RequestTimingsFrom(ctx).AddQueue(time.Now())
+		}
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return nil, nil, nil, ctx.Err()
+		case <-deadline:
+			return nil, nil, nil, concurrencyLimitedError(provider, model)
+		}
+	}
+}
+
+func concurrencyLimitedError(provider, model string) *Error {
+	return &Error{
+		Code:       "concurrency_limited",
+		Message:    fmt.Sprintf("all %s auths are at their concurrency limit for %s", provider, model),
+		Retryable:  true,
+		HTTPStatus: 429,
+	}
+}
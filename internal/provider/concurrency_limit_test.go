@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimiterCapsPerAuth(t *testing.T) {
+	l := NewConcurrencyLimiter()
+	l.SetRules([]ConcurrencyLimit{{Provider: "gemini-cli", ModelPattern: "gemini-2.5-pro", MaxPerAuth: 2}})
+
+	r1, ok1 := l.TryAcquire("gemini-cli", "gemini-2.5-pro", "a")
+	r2, ok2 := l.TryAcquire("gemini-cli", "gemini-2.5-pro", "a")
+	if !ok1 || !ok2 {
+		t.Fatal("expected two slots on auth a")
+	}
+	if _, ok := l.TryAcquire("gemini-cli", "gemini-2.5-pro", "a"); ok {
+		t.Fatal("third request on auth a should be refused")
+	}
+	if !l.Saturated("gemini-cli", "gemini-2.5-pro", "a") || l.Saturated("gemini-cli", "gemini-2.5-pro", "b") {
+		t.Fatal("only auth a should be saturated")
+	}
+	if _, ok := l.TryAcquire("gemini-cli", "gemini-2.5-flash", "a"); !ok {
+		t.Fatal("unlimited model should always get a slot")
+	}
+
+	wake := l.released()
+	r1()
+	r1() // double release is ignored
+	select {
+	case <-wake:
+	case <-time.After(time.Second):
+		t.Fatal("release should wake waiters")
+	}
+	if got := l.InFlight()["gemini-cli:gemini-2.5-pro:a"]; got != 1 {
+		t.Fatalf("in-flight = %d, want 1", got)
+	}
+	r2()
+	if len(l.InFlight()) != 0 {
+		t.Fatalf("expected no in-flight requests, got %v", l.InFlight())
+	}
+}
@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/nghyane/llm-mux/internal/sseutil"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// RouteByCost is the routing policy that prefers the cheapest provider.
+const RouteByCost = "cost"
+
+// ModelPrice is the price of a model on a provider in USD per million tokens.
+type ModelPrice struct {
+	// Provider is the provider identifier. Empty matches every provider.
+	Provider string
+	// ModelPattern is a glob-style model pattern ("claude-sonnet-*").
+	ModelPattern string
+	// Input and Output are USD per million prompt and completion tokens.
+	Input  float64
+	Output float64
+}
+
+// Cost returns the USD cost of u at this price.
+func (p ModelPrice) Cost(u *ir.Usage) float64 {
+	if u == nil {
+		return 0
+	}
+	return (float64(u.PromptTokens)*p.Input + float64(u.CompletionTokens)*p.Output) / 1e6
+}
+
+// CostRouter orders providers by price when the cost routing policy is active.
+type CostRouter struct {
+	mu      sync.RWMutex
+	enabled bool
+	prices  []ModelPrice
+}
+
+// NewCostRouter creates a router with cost routing disabled.
+func NewCostRouter() *CostRouter {
+	return &CostRouter{}
+}
+
+// SetPolicy replaces the pricing table and enables cost ordering when enabled is set.
+func (r *CostRouter) SetPolicy(enabled bool, prices []ModelPrice) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.enabled = enabled
+	r.prices = append([]ModelPrice(nil), prices...)
+	r.mu.Unlock()
+}
+
+// Price returns the first pricing entry matching provider and model.
+func (r *CostRouter) Price(provider, model string) (ModelPrice, bool) {
+	if r == nil {
+		return ModelPrice{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.priceLocked(provider, model)
+}
+
+func (r *CostRouter) priceLocked(provider, model string) (ModelPrice, bool) {
+	for _, p := range r.prices {
+		if (p.Provider == "" || p.Provider == provider) && sseutil.MatchModelPattern(p.ModelPattern, model) {
+			return p, true
+		}
+	}
+	return ModelPrice{}, false
+}
+
+// Order sorts providers cheapest first by their combined input and output price.
+// Unpriced providers keep their relative order after the priced ones. It returns
+// providers unchanged and a nil decision when the policy is off or fewer than
+// two providers have a price.
+func (r *CostRouter) Order(providers []string, model string) ([]string, *RoutingDecision) {
+	if r == nil || len(providers) <= 1 {
+		return providers, nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.enabled {
+		return providers, nil
+	}
+	prices := make(map[string]ModelPrice, len(providers))
+	for _, p := range providers {
+		if price, ok := r.priceLocked(p, model); ok {
+			prices[p] = price
+		}
+	}
+	if len(prices) < 2 {
+		return providers, nil
+	}
+	ordered := slices.Clone(providers)
+	slices.SortStableFunc(ordered, func(a, b string) int {
+		pa, okA := prices[a]
+		pb, okB := prices[b]
+		switch {
+		case okA && okB:
+			ca, cb := pa.Input+pa.Output, pb.Input+pb.Output
+			if ca < cb {
+				return -1
+			}
+			if ca > cb {
+				return 1
+			}
+			return 0
+		case okA:
+			return -1
+		case okB:
+			return 1
+		}
+		return 0
+	})
+	return ordered, &RoutingDecision{Policy: RouteByCost, Baseline: providers[0], prices: prices}
+}
+
+// RoutingDecision records why a provider order was chosen so usage reporting
+// can attribute the estimated savings of the request.
+type RoutingDecision struct {
+	// Policy is the routing policy that reordered providers ("cost").
+	Policy string
+	// Baseline is the provider the default score-based order would have used.
+	Baseline string
+	prices   map[string]ModelPrice
+}
+
+// EstimatedSavings returns how much cheaper serving u on provider was than on
+// the baseline provider, in USD. It is zero when either price is unknown.
+func (d *RoutingDecision) EstimatedSavings(provider string, u *ir.Usage) float64 {
+	if d == nil || u == nil {
+		return 0
+	}
+	chosen, ok := d.prices[provider]
+	if !ok {
+		return 0
+	}
+	baseline, ok := d.prices[d.Baseline]
+	if !ok {
+		return 0
+	}
+	return baseline.Cost(u) - chosen.Cost(u)
+}
+
+type routingDecisionContextKey struct{}
+
+// WithRoutingDecision attaches the routing decision for a request to ctx.
+func WithRoutingDecision(ctx context.Context, d *RoutingDecision) context.Context {
+	if d == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, routingDecisionContextKey{}, d)
+}
+
+// RoutingDecisionFromContext returns the decision attached by WithRoutingDecision.
+func RoutingDecisionFromContext(ctx context.Context) *RoutingDecision {
+	if ctx == nil {
+		return nil
+	}
+	d, _ := ctx.Value(routingDecisionContextKey{}).(*RoutingDecision)
+	return d
+}
@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func TestCostRouterOrder(t *testing.T) {
+	r := NewCostRouter()
+	prices := []ModelPrice{
+		{Provider: "vertex", ModelPattern: "claude-*", Input: 3, Output: 15},
+		{Provider: "claude", ModelPattern: "claude-*", Input: 1, Output: 5},
+	}
+	providers := []string{"vertex", "kiro", "claude"}
+
+	if got, d := r.Order(providers, "claude-sonnet-4-5"); d != nil || !slices.Equal(got, providers) {
+		t.Fatalf("disabled router reordered providers: %v", got)
+	}
+
+	r.SetPolicy(true, prices)
+	got, d := r.Order(providers, "claude-sonnet-4-5")
+	if want := []string{"claude", "vertex", "kiro"}; !slices.Equal(got, want) {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+	if d == nil || d.Policy != RouteByCost || d.Baseline != "vertex" {
+		t.Fatalf("decision = %+v", d)
+	}
+
+	u := &ir.Usage{PromptTokens: 1_000_000, CompletionTokens: 100_000}
+	if s := d.EstimatedSavings("claude", u); math.Abs(s-3) > 1e-9 {
+		t.Fatalf("savings = %v, want 3", s)
+	}
+	if s := d.EstimatedSavings("kiro", u); s != 0 {
+		t.Fatalf("unpriced provider savings = %v, want 0", s)
+	}
+
+	if _, d := r.Order(providers, "gemini-2.5-pro"); d != nil {
+		t.Fatal("expected no decision when models are unpriced")
+	}
+}
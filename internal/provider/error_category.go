@@ -39,8 +39,16 @@ const (
 	// CategoryClientCanceled indicates client-side cancellation (context canceled, deadline exceeded)
 	// Should NOT affect provider status - this is client behavior, not provider failure
 	CategoryClientCanceled
+
+	// CategoryOverloaded indicates the upstream is overloaded (Anthropic 529 overloaded_error)
+	// Should fallback to another provider after a short cooldown; the account's quota is fine
+	CategoryOverloaded
 )
 
+// StatusOverloaded is the non-standard status Anthropic returns with
+// overloaded_error when its API is temporarily overloaded.
+const StatusOverloaded = 529
+
 // String returns human-readable category name
 func (c ErrorCategory) String() string {
 	switch c {
@@ -58,6 +66,8 @@ func (c ErrorCategory) String() string {
 		return "not_found"
 	case CategoryClientCanceled:
 		return "client_canceled"
+	case CategoryOverloaded:
+		return "overloaded"
 	default:
 		return "unknown"
 	}
@@ -65,7 +75,7 @@ func (c ErrorCategory) String() string {
 
 // ShouldFallback returns true if should try another auth/provider
 func (c ErrorCategory) ShouldFallback() bool {
-	return c == CategoryQuotaError || c == CategoryTransient || c == CategoryAuthError
+	return c == CategoryQuotaError || c == CategoryTransient || c == CategoryAuthError || c == CategoryOverloaded
 }
 
 // ShouldDisableAuth returns true if auth should be disabled
@@ -96,6 +106,8 @@ func CategorizeHTTPStatus(statusCode int) ErrorCategory {
 		return CategoryNotFound
 	case http.StatusTooManyRequests: // 429
 		return CategoryQuotaError
+	case StatusOverloaded: // 529
+		return CategoryOverloaded
 	case http.StatusInternalServerError, // 500
 		http.StatusBadGateway,         // 502
 		http.StatusServiceUnavailable, // 503
@@ -124,6 +136,12 @@ func CategorizeError(statusCode int, message string) ErrorCategory {
 		return CategoryAuthRevoked
 	}
 
+	// Overload is checked before quota: Anthropic's overloaded_error says
+	// nothing about the account's rate limits
+	if statusCode == StatusOverloaded || isOverloadedError(message) {
+		return CategoryOverloaded
+	}
+
 	// Check for user errors in message
 	if isUserError(message) {
 		return CategoryUserError
@@ -178,8 +196,12 @@ func isQuotaError(msg string) bool {
 		strings.Contains(lower, "rate limit") ||
 		strings.Contains(lower, "rate_limit_error") ||
 		strings.Contains(lower, "too many requests") ||
-		strings.Contains(lower, "credit balance is too low") ||
-		strings.Contains(lower, "overloaded_error")
+		strings.Contains(lower, "credit balance is too low")
+}
+
+// isOverloadedError checks if message is Anthropic's overloaded_error
+func isOverloadedError(msg string) bool {
+	return msg != "" && strings.Contains(strings.ToLower(msg), "overloaded_error")
 }
 
 func isContextCanceledError(msg string) bool {
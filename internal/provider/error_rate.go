@@ -0,0 +1,356 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+const (
+	defaultErrorRateWindow      = 10 * time.Minute
+	defaultErrorRateMinRequests = 20
+	defaultMaxErrorRate         = 0.5
+	defaultErrorRateDisableFor  = time.Hour
+	maxErrorRateSamples         = 1024
+)
+
+// defaultErrorRateStatuses are the statuses that point at the auth itself,
+// such as an expired token or revoked scopes, rather than at the upstream.
+var defaultErrorRateStatuses = []int{400, 401, 403}
+
+// ErrorRatePolicy takes auths out of rotation whose requests keep failing
+// with auth-related errors.
+type ErrorRatePolicy struct {
+	// Window is the rolling window of results considered (default 10m).
+	Window time.Duration
+	// MinRequests is the number of results in the window before an auth
+	// can be disabled (default 20).
+	MinRequests int
+	// MaxErrorRate is the fraction of failed results that disables an auth
+	// (default 0.5).
+	MaxErrorRate float64
+	// Statuses are the upstream statuses counted as failures (default 400,
+	// 401 and 403). Other failures, such as 429 and 5xx, are not counted.
+	Statuses []int
+	// DisableFor is how long a disabled auth gets no traffic (default 1h).
+	DisableFor time.Duration
+}
+
+// AuthDisabled describes an auth taken out of rotation for its error rate.
+type AuthDisabled struct {
+	AuthID    string    `json:"auth_id"`
+	Provider  string    `json:"provider"`
+	Errors    int       `json:"errors"`
+	Requests  int       `json:"requests"`
+	ErrorRate float64   `json:"error_rate"`
+	Until     time.Time `json:"until"`
+	Message   string    `json:"message"`
+}
+
+// ErrorRateNotifier receives auths disabled for their error rate.
+type ErrorRateNotifier func(AuthDisabled)
+
+type resultSample struct {
+	at     time.Time
+	status int // zero for successes
+}
+
+type errorRateSeries struct {
+	provider string
+	samples  []resultSample
+	disabled *AuthDisabled
+}
+
+// ErrorRateTracker records request results per auth over a rolling window
+// and disables auths whose failure rate exceeds the policy. An auth is only
+// disabled while another auth of its provider stays below the rate, so
+// errors caused by the client's requests do not disable every auth.
+type ErrorRateTracker struct {
+	mu       sync.Mutex
+	policy   *ErrorRatePolicy
+	series   map[string]*errorRateSeries // authID -> results
+	notifier ErrorRateNotifier
+	now      func() time.Time
+}
+
+// NewErrorRateTracker creates a tracker with no policy.
+func NewErrorRateTracker() *ErrorRateTracker {
+	return &ErrorRateTracker{
+		series: make(map[string]*errorRateSeries),
+		now:    time.Now,
+	}
+}
+
+// SetPolicy replaces the policy; nil turns tracking off and re-enables
+// every auth.
+func (t *ErrorRateTracker) SetPolicy(p *ErrorRatePolicy) {
+	if t == nil {
+		return
+	}
+	if p != nil {
+		normalized := *p
+		if normalized.Window <= 0 {
+			normalized.Window = defaultErrorRateWindow
+		}
+		if normalized.MinRequests <= 0 {
+			normalized.MinRequests = defaultErrorRateMinRequests
+		}
+		if normalized.MaxErrorRate <= 0 || normalized.MaxErrorRate > 1 {
+			normalized.MaxErrorRate = defaultMaxErrorRate
+		}
+		if len(normalized.Statuses) == 0 {
+			normalized.Statuses = defaultErrorRateStatuses
+		}
+		if normalized.DisableFor <= 0 {
+			normalized.DisableFor = defaultErrorRateDisableFor
+		}
+		p = &normalized
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.policy = p
+	if p == nil {
+		clear(t.series)
+	}
+}
+
+// SetNotifier registers a callback invoked when an auth is disabled.
+func (t *ErrorRateTracker) SetNotifier(fn ErrorRateNotifier) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.notifier = fn
+	t.mu.Unlock()
+}
+
+// Record adds the result of a request and returns the auth's disablement
+// when this result disabled it.
+func (t *ErrorRateTracker) Record(result Result) *AuthDisabled {
+	if t == nil || result.AuthID == "" {
+		return nil
+	}
+	t.mu.Lock()
+	p := t.policy
+	if p == nil {
+		t.mu.Unlock()
+		return nil
+	}
+	sample := resultSample{at: t.now()}
+	if !result.Success {
+		sample.status = result.Error.StatusCode()
+		if !slices.Contains(p.Statuses, sample.status) {
+			t.mu.Unlock()
+			return nil
+		}
+	}
+	s := t.series[result.AuthID]
+	if s == nil {
+		s = &errorRateSeries{provider: result.Provider}
+		t.series[result.AuthID] = s
+	}
+	if s.disabled != nil {
+		if sample.at.Before(s.disabled.Until) {
+			// A request that was in flight when the auth was disabled.
+			t.mu.Unlock()
+			return nil
+		}
+		s.disabled = nil
+	}
+	s.samples = append(s.samples, sample)
+	s.samples = pruneResultSamples(s.samples, sample.at.Add(-p.Window))
+
+	errs, total := s.counts()
+	if sample.status == 0 || total < p.MinRequests || float64(errs)/float64(total) < p.MaxErrorRate || !t.peerHealthy(result.AuthID, s.provider, p) {
+		t.mu.Unlock()
+		return nil
+	}
+	disabled := &AuthDisabled{
+		AuthID:    result.AuthID,
+		Provider:  s.provider,
+		Errors:    errs,
+		Requests:  total,
+		ErrorRate: float64(errs) / float64(total),
+		Until:     sample.at.Add(p.DisableFor),
+		Message:   fmt.Sprintf("disabled for error rate: %d of %d requests in %s failed (%s)", errs, total, p.Window, s.statusSummary()),
+	}
+	s.disabled = disabled
+	// Start a fresh window so the auth is judged on its traffic after re-enabling.
+	s.samples = s.samples[:0]
+	notifier := t.notifier
+	t.mu.Unlock()
+
+	log.Warnf("auth %s (%s) %s; no traffic until %s", disabled.AuthID, disabled.Provider, disabled.Message, disabled.Until.Format(time.RFC3339))
+	if notifier != nil {
+		notifier(*disabled)
+	}
+	out := *disabled
+	return &out
+}
+
+// peerHealthy reports whether another auth of provider has results in the
+// window below the error rate. Caller must hold t.mu.
+func (t *ErrorRateTracker) peerHealthy(authID, provider string, p *ErrorRatePolicy) bool {
+	cutoff := t.now().Add(-p.Window)
+	for id, s := range t.series {
+		if id == authID || s.provider != provider || s.disabled != nil {
+			continue
+		}
+		s.samples = pruneResultSamples(s.samples, cutoff)
+		if errs, total := s.counts(); total > 0 && float64(errs)/float64(total) < p.MaxErrorRate {
+			return true
+		}
+	}
+	return false
+}
+
+// Disabled reports whether authID is currently disabled.
+func (t *ErrorRateTracker) Disabled(authID string) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.series[authID]
+	return s != nil && s.disabled != nil && t.now().Before(s.disabled.Until)
+}
+
+// DisabledAuths returns the auths currently disabled, soonest re-enabled
+// first.
+func (t *ErrorRateTracker) DisabledAuths() []AuthDisabled {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	out := make([]AuthDisabled, 0)
+	for _, s := range t.series {
+		if s.disabled != nil && now.Before(s.disabled.Until) {
+			out = append(out, *s.disabled)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Until.Before(out[j].Until) })
+	return out
+}
+
+func (s *errorRateSeries) counts() (errs, total int) {
+	for _, r := range s.samples {
+		if r.status != 0 {
+			errs++
+		}
+	}
+	return errs, len(s.samples)
+}
+
+// statusSummary lists the failure statuses in the window by count, such as
+// "401 x12, 400 x3".
+func (s *errorRateSeries) statusSummary() string {
+	counts := make(map[int]int)
+	for _, r := range s.samples {
+		if r.status != 0 {
+			counts[r.status]++
+		}
+	}
+	statuses := make([]int, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if counts[statuses[i]] != counts[statuses[j]] {
+			return counts[statuses[i]] > counts[statuses[j]]
+		}
+		return statuses[i] < statuses[j]
+	})
+	parts := make([]string, len(statuses))
+	for i, status := range statuses {
+		parts[i] = strconv.Itoa(status) + " x" + strconv.Itoa(counts[status])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func pruneResultSamples(samples []resultSample, cutoff time.Time) []resultSample {
+	drop := 0
+	for drop < len(samples) && samples[drop].at.Before(cutoff) {
+		drop++
+	}
+	if over := len(samples) - drop - maxErrorRateSamples; over > 0 {
+		drop += over
+	}
+	if drop == 0 {
+		return samples
+	}
+	return append(samples[:0], samples[drop:]...)
+}
+
+// SetErrorRatePolicy replaces the error rate policy; nil turns it off.
+func (m *Manager) SetErrorRatePolicy(p *ErrorRatePolicy) {
+	if m == nil {
+		return
+	}
+	m.errorRates.SetPolicy(p)
+}
+
+// SetErrorRateNotifier registers a callback fired whenever an auth is
+// disabled for its error rate.
+func (m *Manager) SetErrorRateNotifier(fn ErrorRateNotifier) {
+	if m == nil {
+		return
+	}
+	m.errorRates.SetNotifier(fn)
+}
+
+// ErrorRateDisabled returns the auths currently disabled for their error rate.
+func (m *Manager) ErrorRateDisabled() []AuthDisabled {
+	if m == nil {
+		return nil
+	}
+	return m.errorRates.DisabledAuths()
+}
+
+// recordErrorRate feeds result to the error rate tracker and, when it
+// disables the auth, marks the auth unavailable with the reason so that
+// hooks and the management API see it.
+func (m *Manager) recordErrorRate(ctx context.Context, result Result) {
+	disabled := m.errorRates.Record(result)
+	if disabled == nil {
+		return
+	}
+	if m.registry != nil {
+		if entry := m.registry.GetEntry(disabled.AuthID); entry != nil {
+			entry.UpdateMetadata(func(old *AuthMetadata) *AuthMetadata {
+				newMeta := old.Clone()
+				newMeta.Status = StatusError
+				newMeta.StatusMessage = disabled.Message
+				newMeta.NextRetryAfter = disabled.Until
+				newMeta.UpdatedAt = time.Now()
+				return newMeta
+			})
+			entry.SetUnavailable(true)
+			m.registry.markDirty(disabled.AuthID)
+			m.hook.OnAuthUpdated(ctx, entry.ToAuth())
+			return
+		}
+	}
+	m.mu.Lock()
+	auth, ok := m.auths[disabled.AuthID]
+	if !ok || auth == nil {
+		m.mu.Unlock()
+		return
+	}
+	auth.Status = StatusError
+	auth.StatusMessage = disabled.Message
+	auth.Unavailable = true
+	auth.NextRetryAfter = disabled.Until
+	auth.UpdatedAt = time.Now()
+	snapshot := auth.Clone()
+	m.mu.Unlock()
+	m.hook.OnAuthUpdated(ctx, snapshot)
+}
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestErrorRateTrackerDisablesFailingAuth(t *testing.T) {
+	tracker := NewErrorRateTracker()
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+	tracker.SetPolicy(&ErrorRatePolicy{MinRequests: 4, DisableFor: time.Hour})
+
+	var notified []AuthDisabled
+	tracker.SetNotifier(func(d AuthDisabled) { notified = append(notified, d) })
+
+	failure := func(id string, status int) Result {
+		return Result{AuthID: id, Provider: "claude", Error: &Error{HTTPStatus: status}}
+	}
+	tracker.Record(Result{AuthID: "good", Provider: "claude", Success: true})
+	tracker.Record(failure("good", 429)) // not an auth error
+	for i := 0; i < 3; i++ {
+		if d := tracker.Record(failure("revoked", 401)); d != nil {
+			t.Fatalf("disabled before min-requests: %+v", d)
+		}
+	}
+	d := tracker.Record(failure("revoked", 400))
+	if d == nil || !tracker.Disabled("revoked") || tracker.Disabled("good") {
+		t.Fatalf("disabled = %+v", d)
+	}
+	if d.Message != "disabled for error rate: 4 of 4 requests in 10m0s failed (401 x3, 400 x1)" {
+		t.Fatalf("message = %q", d.Message)
+	}
+	if len(notified) != 1 || notified[0].AuthID != "revoked" {
+		t.Fatalf("notified = %+v", notified)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if tracker.Disabled("revoked") || len(tracker.DisabledAuths()) != 0 {
+		t.Fatal("auth still disabled after disable-for")
+	}
+}
+
+func TestErrorRateTrackerNeedsHealthyPeer(t *testing.T) {
+	tracker := NewErrorRateTracker()
+	tracker.SetPolicy(&ErrorRatePolicy{MinRequests: 2})
+
+	// Every auth of the provider fails, as with malformed client requests.
+	for _, id := range []string{"a", "b", "a", "b", "a", "b"} {
+		tracker.Record(Result{AuthID: id, Provider: "gemini", Error: &Error{HTTPStatus: 400}})
+	}
+	if tracker.Disabled("a") || tracker.Disabled("b") {
+		t.Fatal("auths disabled although no peer is healthy")
+	}
+}
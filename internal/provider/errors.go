@@ -1,6 +1,12 @@
 package provider
 
-import "errors"
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
 
 var ErrTokenNotReady = errors.New("token not ready")
 
@@ -11,6 +17,9 @@ type Error struct {
 	Retryable   bool          `json:"retryable"`
 	HTTPStatus  int           `json:"http_status,omitempty"`
 	ErrCategory ErrorCategory `json:"category,omitempty"`
+	// RetryIn is how long until an auth becomes available again. It is sent
+	// to clients as Retry-After.
+	RetryIn time.Duration `json:"-"`
 }
 
 // Error implements the error interface.
@@ -40,3 +49,22 @@ func (e *Error) Category() ErrorCategory {
 	}
 	return e.ErrCategory
 }
+
+// RetryAfter returns RetryIn, or nil when unknown.
+func (e *Error) RetryAfter() *time.Duration {
+	if e == nil || e.RetryIn <= 0 {
+		return nil
+	}
+	d := e.RetryIn
+	return &d
+}
+
+// Headers returns the Retry-After header when RetryIn is known.
+func (e *Error) Headers() http.Header {
+	if e == nil || e.RetryIn <= 0 {
+		return nil
+	}
+	headers := make(http.Header)
+	headers.Set("Retry-After", strconv.Itoa(int(math.Ceil(e.RetryIn.Seconds()))))
+	return headers
+}
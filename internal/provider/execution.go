@@ -10,6 +10,17 @@ import (
 	"github.com/sony/gobreaker"
 )
 
+// executionContext prepares ctx for running a request with auth.
+func (m *Manager) executionContext(ctx context.Context, auth *Auth) context.Context {
+	if rt := m.roundTripperFor(auth); rt != nil {
+		ctx = context.WithValue(ctx, roundTripperContextKey{}, rt)
+	}
+	if m.quotaManager != nil {
+		ctx = context.WithValue(ctx, quotaManagerContextKey{}, m.quotaManager)
+	}
+	return WithPayloadSizes(ctx, &PayloadSizes{})
+}
+
 // ExecuteWithProvider handles non-streaming execution for a single provider, attempting
 // multiple auth candidates until one succeeds or all are exhausted.
 func (m *Manager) executeWithProvider(ctx context.Context, provider string, req Request, opts Options) (Response, error) {
@@ -29,12 +40,14 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 		return Response{}, &Error{Code: "circuit_open", Message: "provider circuit breaker is open"}
 	}
 
+	requested := req.Model
+	ctx = withPinnedSnapshot(ctx, requested)
 	req.Model = registry.GetGlobalRegistry().GetModelIDForProvider(req.Model, provider)
 
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, errPick := m.pickNextFromRegistry(ctx, provider, req.Model, opts, tried)
+		auth, executor, release, errPick := m.pickNextWithSlot(ctx, provider, req.Model, opts, tried)
 		if errPick != nil {
 			telemetry.RecordError(span, errPick)
 			if lastErr != nil {
@@ -44,16 +57,14 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 		}
 
 		tried[auth.ID] = struct{}{}
-		execCtx := ctx
-		if rt := m.roundTripperFor(auth); rt != nil {
-			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
-		}
+		execCtx := m.executionContext(ctx, auth)
 
 		authCopy := auth
 		reqCopy := req
 		result, errBreaker := breaker.Execute(func() (any, error) {
 			return executor.Execute(execCtx, authCopy, reqCopy, opts)
 		})
+		release()
 
 		if errBreaker != nil {
 			telemetry.RecordError(span, errBreaker)
@@ -81,12 +92,23 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 				markResult.RetryAfter = ra
 			}
 			m.MarkResult(execCtx, markResult)
+			if isUpstreamOverload(errBreaker) {
+				return Response{}, errBreaker
+			}
 			lastErr = errBreaker
 			continue
 		}
 
 		resp := result.(Response)
+		if anomaly, retry := m.validateResponse(ctx, provider, req.Model, auth.ID, opts.SourceFormat, resp.Payload); retry {
+			m.MarkResult(execCtx, anomalyResult(auth.ID, provider, req.Model, anomaly))
+			lastErr = anomaly
+			continue
+		}
 		m.MarkResult(execCtx, Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: true})
+		if pin := SnapshotPinFrom(ctx); pin != nil {
+			pin.resolve(requested, PinnedSnapshot{Provider: provider, Snapshot: upstreamSnapshot(executor, auth, req.Model)})
+		}
 		return resp, nil
 	}
 }
@@ -117,10 +139,7 @@ func (m *Manager) executeCountWithProvider(ctx context.Context, provider string,
 		}
 
 		tried[auth.ID] = struct{}{}
-		execCtx := ctx
-		if rt := m.roundTripperFor(auth); rt != nil {
-			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
-		}
+		execCtx := m.executionContext(ctx, auth)
 
 		authCopy := auth
 		reqCopy := req
@@ -153,6 +172,9 @@ func (m *Manager) executeCountWithProvider(ctx context.Context, provider string,
 				markResult.RetryAfter = ra
 			}
 			m.MarkResult(execCtx, markResult)
+			if isUpstreamOverload(errBreaker) {
+				return Response{}, errBreaker
+			}
 			lastErr = errBreaker
 			continue
 		}
@@ -177,12 +199,14 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 		return nil, &Error{Code: "circuit_open", Message: "provider circuit breaker is open"}
 	}
 
+	sloModel := req.Model
+	ctx = withPinnedSnapshot(ctx, sloModel)
 	req.Model = registry.GetGlobalRegistry().GetModelIDForProvider(req.Model, provider)
 
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, errPick := m.pickNextFromRegistry(ctx, provider, req.Model, opts, tried)
+		auth, executor, release, errPick := m.pickNextWithSlot(ctx, provider, req.Model, opts, tried)
 		if errPick != nil {
 			done(false)
 			if lastErr != nil {
@@ -192,12 +216,11 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 		}
 
 		tried[auth.ID] = struct{}{}
-		execCtx := ctx
-		if rt := m.roundTripperFor(auth); rt != nil {
-			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
-		}
+		execCtx := m.executionContext(ctx, auth)
+		requestStart := time.Now()
 		chunks, errStream := executor.ExecuteStream(execCtx, auth, req, opts)
 		if errStream != nil {
+			release()
 			if errors.Is(errStream, context.Canceled) || errors.Is(errStream, context.DeadlineExceeded) {
 				done(false)
 				return nil, errStream
@@ -221,22 +244,56 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 			result := Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: false, Error: rerr}
 			result.RetryAfter = retryAfterFromError(errStream)
 			m.MarkResult(execCtx, result)
+			if isUpstreamOverload(errStream) {
+				done(false)
+				return nil, errStream
+			}
 			lastErr = errStream
 			continue
 		}
 
+		if pin := SnapshotPinFrom(ctx); pin != nil {
+			pin.resolve(sloModel, PinnedSnapshot{Provider: provider, Snapshot: upstreamSnapshot(executor, auth, req.Model)})
+		}
+
 		// Single output channel - consolidates previous 2 wrapper layers
 		out := make(chan StreamChunk, 128) // Unified buffer size for all stream operations
 		startTime := time.Now()
 
 		go func(streamCtx context.Context, streamAuth *Auth, streamProvider string, streamModel string, streamChunks <-chan StreamChunk, cbDone func(bool)) {
 			defer close(out)
+			defer release()
 			var failed bool
+			var firstTokenSeen bool
+			validation := m.responseValidation()
+			var check *responseCheck
+			if validation != nil {
+				check = newResponseCheck(opts.SourceFormat, validation.RepeatThreshold)
+			}
+			// flagAnomaly records the first anomaly of the stream. With retry
+			// enabled the auth is marked failed; the stream cannot be replayed.
+			flagAnomaly := func(anomaly *AnomalyError) {
+				check = nil
+				if !validation.Retry {
+					recordAnomaly(streamCtx, anomaly, streamProvider, streamModel, streamAuth.ID, true, "logged")
+					return
+				}
+				action := "quarantined"
+				if anomaly.Kind == AnomalyRepeatedChunks {
+					action = "cut_off"
+				}
+				recordAnomaly(streamCtx, anomaly, streamProvider, streamModel, streamAuth.ID, true, action)
+				if !failed {
+					failed = true
+					m.MarkResult(streamCtx, anomalyResult(streamAuth.ID, streamProvider, streamModel, anomaly))
+				}
+			}
 
 			for {
 				select {
 				case <-streamCtx.Done():
 					// Context cancelled - record stats but don't count as failure
+					m.abandoned.drain(streamProvider, streamChunks)
 					m.recordProviderResult(streamProvider, streamModel, !failed, time.Since(startTime))
 					cbDone(!failed)
 					return
@@ -244,6 +301,11 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 				case chunk, ok := <-streamChunks:
 					if !ok {
 						// Stream complete
+						if check != nil {
+							if anomaly := check.finish(); anomaly != nil {
+								flagAnomaly(anomaly)
+							}
+						}
 						if !failed {
 							m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: streamModel, Success: true})
 						}
@@ -255,6 +317,7 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 					// Check for errors in chunk
 					if chunk.Err != nil && !failed {
 						if errors.Is(chunk.Err, context.Canceled) || errors.Is(chunk.Err, context.DeadlineExceeded) {
+							m.abandoned.drain(streamProvider, streamChunks)
 							m.recordProviderResult(streamProvider, streamModel, true, time.Since(startTime))
 							cbDone(true)
 							return
@@ -274,10 +337,41 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 						m.MarkResult(streamCtx, result)
 					}
 
+					if !firstTokenSeen && chunk.Err == nil && len(chunk.Payload) > 0 {
+						firstTokenSeen = true
+						m.latencySLO.Record(streamProvider, sloModel, time.Since(requestStart))
+					}
+
+					if check != nil && chunk.Err == nil {
+						if anomaly := check.observe(chunk.Payload); anomaly != nil {
+							if anomaly.Kind == AnomalyRepeatedChunks && validation.Retry {
+								// Cut off a looping stream and drain it so the executor can finish.
+								flagAnomaly(anomaly)
+								go func() {
+									for range streamChunks {
+									}
+								}()
+								select {
+								case out <- StreamChunk{Err: anomaly}:
+								case <-streamCtx.Done():
+								}
+								m.recordProviderResult(streamProvider, streamModel, false, time.Since(startTime))
+								cbDone(false)
+								return
+							}
+							flagAnomaly(anomaly)
+						}
+					}
+
+					if chunk.Err == nil && len(chunk.Payload) > 0 {
+						chunk.Payload = m.encoding.sanitize(streamProvider, chunk.Payload)
+					}
+
 					// Forward chunk - non-blocking with context check
 					select {
 					case out <- chunk:
 					case <-streamCtx.Done():
+						m.abandoned.drain(streamProvider, streamChunks)
 						m.recordProviderResult(streamProvider, streamModel, !failed, time.Since(startTime))
 						cbDone(!failed)
 						return
@@ -298,6 +392,10 @@ func (m *Manager) executeProvidersOnce(ctx context.Context, providers []string,
 	}
 	var lastErr error
 	for _, provider := range providers {
+		if errCtx := ctx.Err(); errCtx != nil {
+			// The request is gone; do not start one on the next provider.
+			return Response{}, errCtx
+		}
 		resp, errExec := fn(ctx, provider)
 		if errExec == nil {
 			return resp, nil
@@ -318,6 +416,10 @@ func (m *Manager) executeStreamProvidersOnce(ctx context.Context, providers []st
 	}
 	var lastErr error
 	for _, provider := range providers {
+		if errCtx := ctx.Err(); errCtx != nil {
+			// The request is gone; do not start one on the next provider.
+			return nil, errCtx
+		}
 		chunks, errExec := fn(ctx, provider)
 		if errExec == nil {
 			return chunks, nil
@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// Reasons an auth is not picked for a request, as reported by ExplainRouting.
+const (
+	RejectDisabled         = "disabled"
+	RejectModelUnsupported = "model_not_supported"
+	RejectTagFilter        = "tag_filter"
+	RejectPremiumReserved  = "premium_reserved"
+	RejectCoolingDown      = "cooling_down"
+	RejectUnavailable      = "unavailable"
+	RejectDailyBudget      = "daily_budget_exhausted"
+	RejectSpendLimit       = "spend_limit"
+	RejectConcurrencyLimit = "concurrency_limit"
+	RejectCircuitOpen      = "circuit_open"
+	RejectNoMatchingAuths  = "no_auths"
+)
+
+// RoutingExplanation describes how a request for a model would be routed now.
+type RoutingExplanation struct {
+	Providers []ProviderExplanation
+	// Policy and Baseline are set when a routing policy reordered providers.
+	Policy   string
+	Baseline string
+}
+
+// ProviderExplanation describes how one provider was considered.
+type ProviderExplanation struct {
+	Provider string
+	// Rank is the position in the execution order, starting at 1. It is zero
+	// when the provider is skipped, in which case Skipped gives the reason.
+	Rank    int
+	Skipped string
+	Circuit string
+	Auths   []AuthExplanation
+}
+
+// AuthExplanation describes whether an auth would be picked. Rejected is empty
+// for usable auths; Until is when a cooling down auth recovers.
+type AuthExplanation struct {
+	ID       string
+	Rejected string
+	Until    time.Time
+}
+
+// ExplainRouting reports the provider order Execute would use for model and,
+// for every auth of those providers, why it would or would not be picked for
+// the client in ctx. It does not reserve slots or change any state besides
+// creating missing circuit breakers.
+func (m *Manager) ExplainRouting(ctx context.Context, providers []string, model string) RoutingExplanation {
+	var out RoutingExplanation
+	if m == nil {
+		return out
+	}
+	normalized := m.normalizeProviders(providers)
+	ordered, decision := m.selectProviders(model, normalized)
+	if decision != nil {
+		out.Policy = decision.Policy
+		out.Baseline = decision.Baseline
+	}
+	rank := make(map[string]int, len(ordered))
+	for i, p := range ordered {
+		rank[p] = i + 1
+	}
+
+	now := time.Now()
+	modelKey := strings.TrimSpace(model)
+	byProvider := make(map[string][]AuthExplanation, len(normalized))
+	required := make(map[string]map[string]string, len(normalized))
+	for _, p := range normalized {
+		required[p] = m.tagFilters.required(ctx, p, modelKey)
+	}
+	m.mu.RLock()
+	for _, auth := range m.auths {
+		if auth == nil {
+			continue
+		}
+		providerKey := strings.ToLower(strings.TrimSpace(auth.Provider))
+		req, ok := required[providerKey]
+		if !ok {
+			continue
+		}
+		reason, until := m.authRejection(ctx, auth, modelKey, req, now)
+		byProvider[providerKey] = append(byProvider[providerKey], AuthExplanation{ID: auth.ID, Rejected: reason, Until: until})
+	}
+	m.mu.RUnlock()
+
+	for _, p := range normalized {
+		entry := ProviderExplanation{
+			Provider: p,
+			Rank:     rank[p],
+			Circuit:  m.BreakerState(p).String(),
+			Auths:    byProvider[p],
+		}
+		switch {
+		case entry.Rank == 0:
+			entry.Skipped = RejectCircuitOpen
+		case len(entry.Auths) == 0:
+			entry.Skipped = RejectNoMatchingAuths
+		}
+		out.Providers = append(out.Providers, entry)
+	}
+	// Skipped providers go last, in the order they were requested.
+	sort.SliceStable(out.Providers, func(i, j int) bool {
+		a, b := out.Providers[i].Rank, out.Providers[j].Rank
+		if a == 0 || b == 0 {
+			return a != 0 && b == 0
+		}
+		return a < b
+	})
+	return out
+}
+
+// authRejection returns why auth would not be picked for model, or "" when it
+// would. required holds the caller's tag filter for the auth's provider. The
+// caller must hold m.mu.
+func (m *Manager) authRejection(ctx context.Context, auth *Auth, model string, required map[string]string, now time.Time) (string, time.Time) {
+	if auth.Disabled {
+		return RejectDisabled, time.Time{}
+	}
+	if model != "" {
+		if reg := registry.GetGlobalRegistry(); reg != nil && !reg.ClientSupportsModel(auth.ID, model) {
+			return RejectModelUnsupported, time.Time{}
+		}
+	}
+	tags := auth.Tags()
+	if len(required) > 0 && !MatchTags(tags, required) {
+		return RejectTagFilter, time.Time{}
+	}
+	if m.premium.reserved(ctx, tags) {
+		return RejectPremiumReserved, time.Time{}
+	}
+	switch blocked, reason, next := isAuthBlockedForModel(auth, model, now); {
+	case reason == blockReasonDisabled:
+		return RejectDisabled, time.Time{}
+	case blocked && reason == blockReasonCooldown:
+		return RejectCoolingDown, next
+	case blocked:
+		return RejectUnavailable, next
+	}
+	switch {
+	case m.scheduler.Exhausted(auth.Provider, model, auth.ID):
+		return RejectDailyBudget, time.Time{}
+	case m.spend.Blocked(auth.Provider, auth.ID):
+		return RejectSpendLimit, time.Time{}
+	case m.concurrency.Saturated(auth.Provider, model, auth.ID):
+		return RejectConcurrencyLimit, time.Time{}
+	}
+	return "", time.Time{}
+}
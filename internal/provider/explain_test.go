@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestManagerExplainRouting(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	defer manager.Stop()
+	ctx := context.Background()
+
+	recoverAt := time.Now().Add(time.Minute)
+	_, _ = manager.Register(ctx, &Auth{ID: "a1", Provider: "claude", Unavailable: true, NextRetryAfter: recoverAt})
+	_, _ = manager.Register(ctx, &Auth{ID: "a2", Provider: "claude"})
+	_, _ = manager.Register(ctx, &Auth{ID: "a3", Provider: "claude", Disabled: true})
+
+	got := manager.ExplainRouting(ctx, []string{"claude", "gemini"}, "")
+	if len(got.Providers) != 2 {
+		t.Fatalf("providers = %+v", got.Providers)
+	}
+	claude := got.Providers[0]
+	if claude.Provider != "claude" || claude.Rank == 0 || claude.Skipped != "" || claude.Circuit != "closed" {
+		t.Fatalf("claude = %+v", claude)
+	}
+	reasons := make(map[string]AuthExplanation)
+	for _, a := range claude.Auths {
+		reasons[a.ID] = a
+	}
+	if a := reasons["a1"]; a.Rejected != RejectUnavailable || !a.Until.Equal(recoverAt) {
+		t.Errorf("a1 = %+v", a)
+	}
+	if a := reasons["a2"]; a.Rejected != "" {
+		t.Errorf("a2 = %+v", a)
+	}
+	if a := reasons["a3"]; a.Rejected != RejectDisabled {
+		t.Errorf("a3 = %+v", a)
+	}
+	if gemini := got.Providers[1]; gemini.Skipped != RejectNoMatchingAuths {
+		t.Errorf("gemini = %+v", gemini)
+	}
+}
@@ -0,0 +1,261 @@
+package provider
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/sseutil"
+)
+
+const (
+	defaultSLOPercentile = 95.0
+	defaultSLOWindow     = 5 * time.Minute
+	defaultSLOMinSamples = 20
+	defaultSLODemoteFor  = 10 * time.Minute
+	maxSLOSamples        = 512
+)
+
+// LatencySLO defines a first-token latency objective for models matching ModelPattern.
+type LatencySLO struct {
+	// ModelPattern is a glob-style model pattern ("claude-*", "*flash*", "*").
+	ModelPattern string
+	// TTFT is the first-token latency target at the given percentile.
+	TTFT time.Duration
+	// Percentile is the percentile evaluated against TTFT (default 95).
+	Percentile float64
+	// Window is the sliding window of samples considered (default 5m).
+	Window time.Duration
+	// MinSamples is the minimum number of samples before enforcement (default 20).
+	MinSamples int
+	// DemoteFor is how long a violating provider stays demoted (default 10m).
+	DemoteFor time.Duration
+}
+
+// SLOViolation describes a provider demoted for violating a latency SLO.
+type SLOViolation struct {
+	Provider      string
+	Model         string
+	Percentile    float64
+	Target        time.Duration
+	Observed      time.Duration
+	Samples       int
+	DemotedUntil  time.Time
+	ViolationTime time.Time
+}
+
+// SLONotifier receives latency SLO violations.
+type SLONotifier func(SLOViolation)
+
+type ttftSample struct {
+	at  time.Time
+	ttf time.Duration
+}
+
+type sloSeries struct {
+	samples      []ttftSample
+	demotedUntil time.Time
+}
+
+// LatencySLOTracker records time-to-first-token samples per provider:model and
+// demotes providers whose windowed percentile exceeds the configured target.
+type LatencySLOTracker struct {
+	mu       sync.Mutex
+	rules    []LatencySLO
+	series   map[string]*sloSeries
+	notifier SLONotifier
+	now      func() time.Time
+}
+
+// NewLatencySLOTracker creates an empty tracker with no rules.
+func NewLatencySLOTracker() *LatencySLOTracker {
+	return &LatencySLOTracker{
+		series: make(map[string]*sloSeries),
+		now:    time.Now,
+	}
+}
+
+// SetRules replaces the active SLO rules. Existing samples are kept, demotions are preserved.
+func (t *LatencySLOTracker) SetRules(rules []LatencySLO) {
+	if t == nil {
+		return
+	}
+	normalized := make([]LatencySLO, 0, len(rules))
+	for _, r := range rules {
+		if r.ModelPattern == "" || r.TTFT <= 0 {
+			continue
+		}
+		if r.Percentile <= 0 || r.Percentile > 100 {
+			r.Percentile = defaultSLOPercentile
+		}
+		if r.Window <= 0 {
+			r.Window = defaultSLOWindow
+		}
+		if r.MinSamples <= 0 {
+			r.MinSamples = defaultSLOMinSamples
+		}
+		if r.DemoteFor <= 0 {
+			r.DemoteFor = defaultSLODemoteFor
+		}
+		normalized = append(normalized, r)
+	}
+	t.mu.Lock()
+	t.rules = normalized
+	t.mu.Unlock()
+}
+
+// SetNotifier registers a callback invoked on each new demotion.
+func (t *LatencySLOTracker) SetNotifier(fn SLONotifier) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.notifier = fn
+	t.mu.Unlock()
+}
+
+// ruleFor returns the first rule matching model. Caller must hold t.mu.
+func (t *LatencySLOTracker) ruleFor(model string) (LatencySLO, bool) {
+	for _, r := range t.rules {
+		if sseutil.MatchModelPattern(r.ModelPattern, model) {
+			return r, true
+		}
+	}
+	return LatencySLO{}, false
+}
+
+// Record adds a time-to-first-token sample and evaluates the SLO.
+func (t *LatencySLOTracker) Record(provider, model string, ttft time.Duration) {
+	if t == nil || provider == "" || ttft <= 0 {
+		return
+	}
+	t.mu.Lock()
+	rule, ok := t.ruleFor(model)
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	now := t.now()
+	key := provider + ":" + model
+	s := t.series[key]
+	if s == nil {
+		s = &sloSeries{}
+		t.series[key] = s
+	}
+	s.samples = append(s.samples, ttftSample{at: now, ttf: ttft})
+	s.samples = pruneSamples(s.samples, now.Add(-rule.Window))
+
+	if len(s.samples) < rule.MinSamples || now.Before(s.demotedUntil) {
+		t.mu.Unlock()
+		return
+	}
+	observed := samplePercentile(s.samples, rule.Percentile)
+	if observed <= rule.TTFT {
+		t.mu.Unlock()
+		return
+	}
+	s.demotedUntil = now.Add(rule.DemoteFor)
+	violation := SLOViolation{
+		Provider:      provider,
+		Model:         model,
+		Percentile:    rule.Percentile,
+		Target:        rule.TTFT,
+		Observed:      observed,
+		Samples:       len(s.samples),
+		DemotedUntil:  s.demotedUntil,
+		ViolationTime: now,
+	}
+	// Start a fresh window so the provider is re-evaluated on post-demotion traffic only.
+	s.samples = s.samples[:0]
+	notifier := t.notifier
+	t.mu.Unlock()
+
+	log.Warnf("latency SLO violated: provider=%s model=%s p%.0f ttft=%s target=%s samples=%d, demoted until %s",
+		provider, model, violation.Percentile, observed, rule.TTFT, violation.Samples, violation.DemotedUntil.Format(time.RFC3339))
+	if notifier != nil {
+		notifier(violation)
+	}
+}
+
+// IsDemoted reports whether provider is currently demoted for model.
+func (t *LatencySLOTracker) IsDemoted(provider, model string) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.series[provider+":"+model]
+	return s != nil && t.now().Before(s.demotedUntil)
+}
+
+// Reorder moves demoted providers behind healthy ones, preserving relative order.
+// If every provider is demoted the original order is returned.
+func (t *LatencySLOTracker) Reorder(providers []string, model string) []string {
+	if t == nil || len(providers) <= 1 {
+		return providers
+	}
+	healthy := make([]string, 0, len(providers))
+	var demoted []string
+	for _, p := range providers {
+		if t.IsDemoted(p, model) {
+			demoted = append(demoted, p)
+		} else {
+			healthy = append(healthy, p)
+		}
+	}
+	if len(demoted) == 0 || len(healthy) == 0 {
+		return providers
+	}
+	return append(healthy, demoted...)
+}
+
+// Demotions returns the active demotions keyed by provider:model.
+func (t *LatencySLOTracker) Demotions() map[string]time.Time {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	out := make(map[string]time.Time)
+	for key, s := range t.series {
+		if now.Before(s.demotedUntil) {
+			out[key] = s.demotedUntil
+		}
+	}
+	return out
+}
+
+func pruneSamples(samples []ttftSample, cutoff time.Time) []ttftSample {
+	drop := 0
+	for drop < len(samples) && samples[drop].at.Before(cutoff) {
+		drop++
+	}
+	if over := len(samples) - drop - maxSLOSamples; over > 0 {
+		drop += over
+	}
+	if drop == 0 {
+		return samples
+	}
+	return append(samples[:0], samples[drop:]...)
+}
+
+func samplePercentile(samples []ttftSample, percentile float64) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	values := make([]time.Duration, len(samples))
+	for i, s := range samples {
+		values[i] = s.ttf
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	idx := int(float64(len(values))*percentile/100+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(values) {
+		idx = len(values) - 1
+	}
+	return values[idx]
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencySLOTrackerDemotesViolatingProvider(t *testing.T) {
+	tracker := NewLatencySLOTracker()
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+	tracker.SetRules([]LatencySLO{{
+		ModelPattern: "claude-*",
+		TTFT:         4 * time.Second,
+		MinSamples:   5,
+		DemoteFor:    time.Minute,
+	}})
+
+	var violations []SLOViolation
+	tracker.SetNotifier(func(v SLOViolation) { violations = append(violations, v) })
+
+	for i := 0; i < 5; i++ {
+		tracker.Record("fast", "claude-sonnet-4", time.Second)
+		tracker.Record("slow", "claude-sonnet-4", 6*time.Second)
+	}
+
+	if !tracker.IsDemoted("slow", "claude-sonnet-4") {
+		t.Fatal("expected slow provider to be demoted")
+	}
+	if tracker.IsDemoted("fast", "claude-sonnet-4") {
+		t.Fatal("fast provider should not be demoted")
+	}
+	if len(violations) != 1 || violations[0].Provider != "slow" {
+		t.Fatalf("expected one violation for slow provider, got %+v", violations)
+	}
+
+	order := tracker.Reorder([]string{"slow", "fast", "other"}, "claude-sonnet-4")
+	want := []string{"fast", "other", "slow"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("Reorder() = %v, want %v", order, want)
+		}
+	}
+
+	now = now.Add(2 * time.Minute)
+	if tracker.IsDemoted("slow", "claude-sonnet-4") {
+		t.Fatal("demotion should expire after DemoteFor")
+	}
+}
+
+func TestLatencySLOTrackerIgnoresUnmatchedModels(t *testing.T) {
+	tracker := NewLatencySLOTracker()
+	tracker.SetRules([]LatencySLO{{ModelPattern: "gpt-*", TTFT: time.Second, MinSamples: 1}})
+
+	tracker.Record("slow", "gemini-2.5-pro", 10*time.Second)
+	if tracker.IsDemoted("slow", "gemini-2.5-pro") {
+		t.Fatal("models without a matching SLO must not be demoted")
+	}
+}
+
+func TestLatencySLOTrackerKeepsOrderWhenAllDemoted(t *testing.T) {
+	tracker := NewLatencySLOTracker()
+	tracker.SetRules([]LatencySLO{{ModelPattern: "*", TTFT: time.Second, MinSamples: 1}})
+
+	tracker.Record("a", "m", 2*time.Second)
+	tracker.Record("b", "m", 2*time.Second)
+
+	order := tracker.Reorder([]string{"a", "b"}, "m")
+	if order[0] != "a" || order[1] != "b" {
+		t.Fatalf("expected original order when every provider is demoted, got %v", order)
+	}
+}
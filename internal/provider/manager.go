@@ -110,9 +110,23 @@ type Manager struct {
 	auths     map[string]*Auth
 
 	providerStats *ProviderStats
+	latencySLO    *LatencySLOTracker
+	errorRates    *ErrorRateTracker
+	concurrency   *ConcurrencyLimiter
+	costRouter    *CostRouter
+	scheduler     *RoutingScheduler
+	spend         *SpendLimiter
+	tagFilters    authTagFilters
+	premium       premiumAuths
 
 	requestRetry     atomic.Int32
 	maxRetryInterval atomic.Int64
+	validation       atomic.Pointer[ResponseValidation]
+	encoding         streamEncoding
+	overloads        upstreamOverloads
+	abandoned        abandonedStreams
+	canaries         canaryHealth
+	refreshHooks     refreshHooks
 
 	rtProvider RoundTripperProvider
 
@@ -148,14 +162,21 @@ func NewManager(store Store, selector Selector, hook Hook) *Manager {
 		hook:              hook,
 		auths:             make(map[string]*Auth),
 		providerStats:     NewProviderStats(),
+		latencySLO:        NewLatencySLOTracker(),
+		errorRates:        NewErrorRateTracker(),
+		concurrency:       NewConcurrencyLimiter(),
+		costRouter:        NewCostRouter(),
+		scheduler:         NewRoutingScheduler(),
 		breakers:          make(map[string]*resilience.CircuitBreaker),
 		streamingBreakers: make(map[string]*resilience.StreamingCircuitBreaker),
 		retryBudget:       resilience.NewRetryBudget(100),
 		refreshSem:        newRefreshSemaphore(),
 		quotaManager:      quotaManager,
 	}
+	m.spend = NewSpendLimiter(m.costRouter)
 	m.registry = NewAuthRegistry(store, hook, m.quotaManager)
 	m.registry.SetExecutorProvider(m.executorFor)
+	m.registry.SetRefreshHooks(&m.refreshHooks)
 	m.registry.Start()
 	if lc, ok := selector.(SelectorLifecycle); ok {
 		lc.Start()
@@ -208,6 +229,95 @@ func (m *Manager) SetRetryConfig(retry int, maxRetryInterval time.Duration) {
 	m.maxRetryInterval.Store(maxRetryInterval.Nanoseconds())
 }
 
+// SetLatencySLOs replaces the first-token latency objectives used to demote slow providers.
+func (m *Manager) SetLatencySLOs(rules []LatencySLO) {
+	if m == nil {
+		return
+	}
+	m.latencySLO.SetRules(rules)
+}
+
+// SetConcurrencyLimits replaces the per-model, per-auth concurrency limits.
+func (m *Manager) SetConcurrencyLimits(rules []ConcurrencyLimit) {
+	if m == nil {
+		return
+	}
+	m.concurrency.SetRules(rules)
+}
+
+// SetAuthTagFilters replaces the tag filters that restrict which auths may serve a request.
+func (m *Manager) SetAuthTagFilters(rules []AuthTagFilter) {
+	if m == nil {
+		return
+	}
+	m.tagFilters.set(rules)
+}
+
+// SetCostRouting replaces the pricing table and toggles least-cost provider ordering.
+func (m *Manager) SetCostRouting(enabled bool, prices []ModelPrice) {
+	if m == nil {
+		return
+	}
+	m.costRouter.SetPolicy(enabled, prices)
+}
+
+// SetRoutingSchedules replaces the time-of-day provider preferences and the
+// daily per-auth quota budgets. Times and day boundaries use loc.
+func (m *Manager) SetRoutingSchedules(loc *time.Location, schedules []RoutingSchedule, budgets []QuotaBudget) {
+	if m == nil {
+		return
+	}
+	m.scheduler.SetRules(loc, schedules, budgets)
+}
+
+// SetSpendLimits replaces the monthly per-auth spend limits. Month boundaries use loc.
+func (m *Manager) SetSpendLimits(loc *time.Location, limits []SpendLimit) {
+	if m == nil {
+		return
+	}
+	m.spend.SetLimits(loc, limits)
+}
+
+// SpendLimiter returns the spend limiter so it can be registered as a usage plugin.
+func (m *Manager) SpendLimiter() *SpendLimiter {
+	if m == nil {
+		return nil
+	}
+	return m.spend
+}
+
+// RoutingScheduler returns the scheduler so it can be registered as a usage plugin.
+func (m *Manager) RoutingScheduler() *RoutingScheduler {
+	if m == nil {
+		return nil
+	}
+	return m.scheduler
+}
+
+// ConcurrencyInFlight returns in-flight request counts for limited models keyed by provider:model:auth.
+func (m *Manager) ConcurrencyInFlight() map[string]int {
+	if m == nil {
+		return nil
+	}
+	return m.concurrency.InFlight()
+}
+
+// SetSLONotifier registers a callback fired whenever a provider is demoted for an SLO violation.
+func (m *Manager) SetSLONotifier(fn SLONotifier) {
+	if m == nil {
+		return
+	}
+	m.latencySLO.SetNotifier(fn)
+}
+
+// LatencySLODemotions returns active SLO demotions keyed by provider:model.
+func (m *Manager) LatencySLODemotions() map[string]time.Time {
+	if m == nil {
+		return nil
+	}
+	return m.latencySLO.Demotions()
+}
+
 // RegisterExecutor registers a provider executor with the manager.
 func (m *Manager) RegisterExecutor(executor ProviderExecutor) {
 	if executor == nil {
@@ -304,7 +414,9 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req Request,
 	if len(normalized) == 0 {
 		return Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
-	selected := m.selectProviders(req.Model, normalized)
+	selected, decision := m.selectProviders(req.Model, normalized)
+	selected = preferPinnedProvider(ctx, req.Model, selected)
+	ctx = WithRoutingDecision(ctx, decision)
 
 	retryTimes, maxWait := m.retrySettings()
 	attempts := retryTimes + 1
@@ -317,10 +429,10 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req Request,
 	for attempt := 0; attempt < attempts; attempt++ {
 		acquiredBudget := false
 		if attempt > 0 {
-			if !m.retryBudget.TryAcquire() {
+			var ok bool
+			if acquiredBudget, ok = m.acquireRetry(ctx); !ok {
 				break
 			}
-			acquiredBudget = true
 		}
 
 		start := time.Now()
@@ -367,7 +479,8 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req Requ
 	if len(normalized) == 0 {
 		return Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
-	selected := m.selectProviders(req.Model, normalized)
+	selected, decision := m.selectProviders(req.Model, normalized)
+	ctx = WithRoutingDecision(ctx, decision)
 
 	retryTimes, maxWait := m.retrySettings()
 	attempts := retryTimes + 1
@@ -380,10 +493,10 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req Requ
 	for attempt := 0; attempt < attempts; attempt++ {
 		acquiredBudget := false
 		if attempt > 0 {
-			if !m.retryBudget.TryAcquire() {
+			var ok bool
+			if acquiredBudget, ok = m.acquireRetry(ctx); !ok {
 				break
 			}
-			acquiredBudget = true
 		}
 
 		start := time.Now()
@@ -429,7 +542,9 @@ func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req Req
 	if len(normalized) == 0 {
 		return nil, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
-	selected := m.selectProviders(req.Model, normalized)
+	selected, decision := m.selectProviders(req.Model, normalized)
+	selected = preferPinnedProvider(ctx, req.Model, selected)
+	ctx = WithRoutingDecision(ctx, decision)
 
 	retryTimes, maxWait := m.retrySettings()
 	attempts := retryTimes + 1
@@ -441,10 +556,10 @@ func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req Req
 	for attempt := 0; attempt < attempts; attempt++ {
 		acquiredBudget := false
 		if attempt > 0 {
-			if !m.retryBudget.TryAcquire() {
+			var ok bool
+			if acquiredBudget, ok = m.acquireRetry(ctx); !ok {
 				break
 			}
-			acquiredBudget = true
 		}
 
 		// Stats are now tracked inside executeStreamWithProvider - no need for wrapStreamForStats
@@ -485,6 +600,7 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	if result.AuthID == "" {
 		return
 	}
+	m.overloads.record(result)
 	// Delegate to AuthRegistry for lock-free path
 	if m.registry != nil {
 		m.registry.MarkResult(ctx, result)
@@ -493,10 +609,11 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 				qm.RecordQuotaHit(result.AuthID, result.Provider, result.Model, result.RetryAfter)
 			}
 		}
-		return
+	} else {
+		// Fallback to sync processing when registry is not available (legacy mode)
+		m.markResultSync(ctx, result)
 	}
-	// Fallback to sync processing when registry is not available (legacy mode)
-	m.markResultSync(ctx, result)
+	m.recordErrorRate(ctx, result)
 }
 
 // markResultSync is the synchronous fallback for MarkResult.
@@ -612,6 +729,8 @@ func (m *Manager) markResultSync(ctx context.Context, result Result) {
 						registry.GetGlobalRegistry().SetModelQuotaExceeded(result.AuthID, affectedModel)
 						registry.GetGlobalRegistry().SuspendClientModel(result.AuthID, affectedModel, "quota_group")
 					}
+				case StatusOverloaded:
+					state.NextRetryAfter = now.Add(overloadCooldown(result.RetryAfter))
 				case 408, 500, 502, 503, 504:
 					next := now.Add(1 * time.Minute)
 					state.NextRetryAfter = next
@@ -717,6 +836,8 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts Opt
 	// Collect candidate pointers under lock (cheap - no cloning yet)
 	candidatePtrs := make([]*Auth, 0, len(m.auths))
 	registryRef := registry.GetGlobalRegistry()
+	requiredTags := m.tagFilters.required(ctx, provider, model)
+	overBudget, overSpend := false, false
 	for _, candidate := range m.auths {
 		if candidate.Provider != provider || candidate.Disabled {
 			continue
@@ -727,10 +848,34 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts Opt
 		if modelKey != "" && registryRef != nil && !registryRef.ClientSupportsModel(candidate.ID, modelKey) {
 			continue
 		}
+		if len(requiredTags) > 0 && !MatchTags(candidate.Tags(), requiredTags) {
+			continue
+		}
+		if m.premium.reserved(ctx, candidate.Tags()) {
+			continue
+		}
+		if m.scheduler.Exhausted(provider, model, candidate.ID) {
+			overBudget = true
+			continue
+		}
+		if m.spend.Blocked(provider, candidate.ID) {
+			overSpend = true
+			continue
+		}
+		if m.errorRates.Disabled(candidate.ID) {
+			continue
+		}
 		candidatePtrs = append(candidatePtrs, candidate)
 	}
+	candidatePtrs = preferPinnedSnapshot(ctx, candidatePtrs, func(a *Auth) string { return upstreamSnapshot(executor, a, model) })
 	if len(candidatePtrs) == 0 {
 		m.mu.RUnlock()
+		if overBudget {
+			return nil, nil, quotaBudgetExhaustedError(provider, model)
+		}
+		if overSpend {
+			return nil, nil, spendLimitExceededError(provider)
+		}
 		return nil, nil, &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
 
@@ -782,7 +927,9 @@ func (m *Manager) pickNextFromRegistry(ctx context.Context, provider, model stri
 	}
 
 	var entries []*AuthEntry
+	saturated, overBudget, overSpend := false, false, false
 	registryRef := registry.GetGlobalRegistry()
+	requiredTags := m.tagFilters.required(ctx, provider, model)
 	for _, entry := range m.registry.ListByProvider(provider) {
 		if entry.IsDisabled() {
 			continue
@@ -793,10 +940,42 @@ func (m *Manager) pickNextFromRegistry(ctx context.Context, provider, model stri
 		if modelKey != "" && registryRef != nil && !registryRef.ClientSupportsModel(entry.ID(), modelKey) {
 			continue
 		}
+		tags := AuthTags(entry.Metadata().Metadata)
+		if len(requiredTags) > 0 && !MatchTags(tags, requiredTags) {
+			continue
+		}
+		if m.premium.reserved(ctx, tags) {
+			continue
+		}
+		if m.scheduler.Exhausted(provider, model, entry.ID()) {
+			overBudget = true
+			continue
+		}
+		if m.spend.Blocked(provider, entry.ID()) {
+			overSpend = true
+			continue
+		}
+		if m.errorRates.Disabled(entry.ID()) {
+			continue
+		}
+		if m.concurrency.Saturated(provider, model, entry.ID()) {
+			saturated = true
+			continue
+		}
 		entries = append(entries, entry)
 	}
+	entries = preferPinnedSnapshot(ctx, entries, func(e *AuthEntry) string { return upstreamSnapshot(executor, e.ToAuth(), model) })
 
 	if len(entries) == 0 {
+		if saturated {
+			return nil, nil, concurrencyLimitedError(provider, model)
+		}
+		if overBudget {
+			return nil, nil, quotaBudgetExhaustedError(provider, model)
+		}
+		if overSpend {
+			return nil, nil, spendLimitExceededError(provider)
+		}
 		return nil, nil, &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
 
@@ -856,7 +1035,7 @@ func (m *Manager) refreshAuth(ctx context.Context, id string) {
 	}
 	cloned := auth.Clone()
 	authUpdatedAt := auth.UpdatedAt
-	updated, err := exec.Refresh(ctx, cloned)
+	updated, err := m.refreshHooks.refresh(ctx, exec, cloned)
 	log.Debugf("refreshed %s, %s, %v", auth.Provider, auth.ID, err)
 	now := time.Now()
 	if err != nil {
@@ -903,6 +1082,11 @@ func (m *Manager) executorFor(provider string) ProviderExecutor {
 	return m.executors[provider]
 }
 
+// HasExecutor reports whether an executor is registered for provider.
+func (m *Manager) HasExecutor(provider string) bool {
+	return m.executorFor(provider) != nil
+}
+
 // roundTripperContextKey is an unexported context key type to avoid collisions.
 type roundTripperContextKey struct{}
 
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// externalToolTypePrefixes match the type of OpenAI and Claude hosted tools
+// that browse, search or reach remote servers from the provider side.
+var externalToolTypePrefixes = []string{"web_search", "web_fetch", "file_search", "computer", "mcp", "url_context"}
+
+// externalToolFields are Gemini tool fields for grounding and URL fetching.
+var externalToolFields = []string{"googleSearch", "googleSearchRetrieval", "urlContext", "google_search", "google_search_retrieval", "url_context"}
+
+// externalRequestFields are top-level request fields that enable retrieval.
+var externalRequestFields = []string{"web_search_options", "mcp_servers"}
+
+type offlineContextKey struct{}
+
+// WithOffline marks the request as offline: no external retrieval may happen
+// on its behalf.
+func WithOffline(ctx context.Context) context.Context {
+	return context.WithValue(ctx, offlineContextKey{}, true)
+}
+
+// IsOffline reports whether ctx was marked by WithOffline.
+func IsOffline(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	offline, _ := ctx.Value(offlineContextKey{}).(bool)
+	return offline
+}
+
+// IsSearchModel reports whether model searches the web on every request,
+// such as gpt-4o-search-preview.
+func IsSearchModel(model string) bool {
+	return strings.Contains(strings.ToLower(model), "search")
+}
+
+// StripExternalRetrieval removes web search, grounding, URL fetching, hosted
+// file search, computer use and remote MCP from a request payload in any
+// supported format. It returns the payload and the names of the removed
+// features; the payload is unchanged when nothing was removed.
+func StripExternalRetrieval(payload []byte) ([]byte, []string) {
+	var removed []string
+	for _, path := range []string{"tools", "request.tools"} {
+		tools := gjson.GetBytes(payload, path)
+		if !tools.IsArray() {
+			continue
+		}
+		kept := make([]string, 0, len(tools.Array()))
+		changed := false
+		for _, tool := range tools.Array() {
+			raw, names := stripExternalTool(tool)
+			if len(names) == 0 {
+				kept = append(kept, tool.Raw)
+				continue
+			}
+			changed = true
+			removed = append(removed, names...)
+			if raw != "" {
+				kept = append(kept, raw)
+			}
+		}
+		if !changed {
+			continue
+		}
+		if len(kept) == 0 {
+			payload, _ = sjson.DeleteBytes(payload, path)
+		} else {
+			payload, _ = sjson.SetRawBytes(payload, path, []byte("["+strings.Join(kept, ",")+"]"))
+		}
+	}
+	for _, field := range externalRequestFields {
+		if !gjson.GetBytes(payload, field).Exists() {
+			continue
+		}
+		removed = append(removed, field)
+		payload, _ = sjson.DeleteBytes(payload, field)
+	}
+	return payload, removed
+}
+
+// stripExternalTool returns tool without its external retrieval features, or
+// "" when nothing else is left, and the names of the removed features.
+func stripExternalTool(tool gjson.Result) (string, []string) {
+	if typ := tool.Get("type").String(); typ != "" {
+		for _, prefix := range externalToolTypePrefixes {
+			if strings.HasPrefix(typ, prefix) {
+				return "", []string{typ}
+			}
+		}
+	}
+	raw := tool.Raw
+	var removed []string
+	for _, field := range externalToolFields {
+		if !tool.Get(field).Exists() {
+			continue
+		}
+		removed = append(removed, field)
+		if stripped, err := sjson.Delete(raw, field); err == nil {
+			raw = stripped
+		}
+	}
+	if len(removed) > 0 && len(gjson.Parse(raw).Map()) == 0 {
+		raw = ""
+	}
+	return raw, removed
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestStripExternalRetrieval(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		removed []string
+		tools   string
+	}{
+		{
+			name:    "openai hosted tool",
+			payload: `{"tools":[{"type":"web_search_preview"},{"type":"function","function":{"name":"f"}}]}`,
+			removed: []string{"web_search_preview"},
+			tools:   `[{"type":"function","function":{"name":"f"}}]`,
+		},
+		{
+			name:    "claude server tools",
+			payload: `{"tools":[{"type":"web_search_20250305","name":"web_search"},{"type":"web_fetch_20250910","name":"web_fetch"}],"mcp_servers":[{"url":"https://x"}]}`,
+			removed: []string{"web_search_20250305", "web_fetch_20250910", "mcp_servers"},
+		},
+		{
+			name:    "gemini grounding beside functions",
+			payload: `{"request":{"tools":[{"functionDeclarations":[{"name":"f"}],"googleSearch":{}},{"urlContext":{}}]}}`,
+			removed: []string{"googleSearch", "urlContext"},
+		},
+		{
+			name:    "chat completions search options",
+			payload: `{"model":"m","web_search_options":{}}`,
+			removed: []string{"web_search_options"},
+		},
+		{
+			name:    "nothing to strip",
+			payload: `{"tools":[{"type":"function","function":{"name":"f"}}]}`,
+			tools:   `[{"type":"function","function":{"name":"f"}}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, removed := StripExternalRetrieval([]byte(tt.payload))
+			if !slices.Equal(removed, tt.removed) {
+				t.Fatalf("removed = %v, want %v", removed, tt.removed)
+			}
+			if got := gjson.GetBytes(out, "tools").Raw; got != tt.tools {
+				t.Errorf("tools = %s, want %s", got, tt.tools)
+			}
+			for _, field := range []string{"mcp_servers", "web_search_options", "request.tools.1", "request.tools.0.googleSearch"} {
+				if gjson.GetBytes(out, field).Exists() {
+					t.Errorf("%s left in %s", field, out)
+				}
+			}
+		})
+	}
+}
+
+func TestStripExternalRetrievalKeepsFunctions(t *testing.T) {
+	out, _ := StripExternalRetrieval([]byte(`{"request":{"tools":[{"functionDeclarations":[{"name":"f"}],"googleSearch":{}}]}}`))
+	if got := gjson.GetBytes(out, "request.tools.0.functionDeclarations.0.name").String(); got != "f" {
+		t.Errorf("function declarations lost: %s", out)
+	}
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// PayloadSizes counts the bytes of the translated request sent upstream and
+// of the response read back during one provider attempt. Every method is a
+// no-op on a nil receiver.
+type PayloadSizes struct {
+	request  atomic.Int64
+	response atomic.Int64
+}
+
+type payloadSizesKey struct{}
+
+// WithPayloadSizes attaches s to ctx.
+func WithPayloadSizes(ctx context.Context, s *PayloadSizes) context.Context {
+	return context.WithValue(ctx, payloadSizesKey{}, s)
+}
+
+// PayloadSizesFrom returns the sizes attached to ctx, or nil.
+func PayloadSizesFrom(ctx context.Context) *PayloadSizes {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(payloadSizesKey{}).(*PayloadSizes)
+	return s
+}
+
+// StartRequest records an upstream request of n bytes, replacing the
+// counts of earlier requests of the attempt such as token refreshes.
+func (s *PayloadSizes) StartRequest(n int64) {
+	if s == nil {
+		return
+	}
+	s.request.Store(n)
+	s.response.Store(0)
+}
+
+// AddRequest adds n bytes to a request whose size was not known upfront.
+func (s *PayloadSizes) AddRequest(n int64) {
+	if s == nil {
+		return
+	}
+	s.request.Add(n)
+}
+
+// AddResponse adds n bytes read from the response body.
+func (s *PayloadSizes) AddResponse(n int64) {
+	if s == nil {
+		return
+	}
+	s.response.Add(n)
+}
+
+// Sizes returns the request and response byte counts.
+func (s *PayloadSizes) Sizes() (request, response int64) {
+	if s == nil {
+		return 0, 0
+	}
+	return s.request.Load(), s.response.Load()
+}
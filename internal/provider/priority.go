@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Priority orders requests competing for the same auths.
+type Priority int
+
+// Request priority levels.
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// ParsePriority parses "low", "normal" or "high".
+func ParsePriority(s string) (Priority, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "low":
+		return PriorityLow, true
+	case "normal":
+		return PriorityNormal, true
+	case "high":
+		return PriorityHigh, true
+	}
+	return PriorityNormal, false
+}
+
+func (p Priority) String() string {
+	switch {
+	case p < PriorityNormal:
+		return "low"
+	case p > PriorityNormal:
+		return "high"
+	}
+	return "normal"
+}
+
+type priorityContextKey struct{}
+
+// WithPriority records the request priority used for queueing, retries and
+// premium auth eligibility.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	if p == PriorityNormal {
+		return ctx
+	}
+	return context.WithValue(ctx, priorityContextKey{}, p)
+}
+
+// PriorityFrom returns the priority recorded by WithPriority, or PriorityNormal.
+func PriorityFrom(ctx context.Context) Priority {
+	if ctx == nil {
+		return PriorityNormal
+	}
+	p, _ := ctx.Value(priorityContextKey{}).(Priority)
+	return p
+}
+
+// premiumAuths reserves auths carrying Tags for requests at MinPriority or above.
+type premiumAuths struct {
+	mu          sync.RWMutex
+	tags        map[string]string
+	minPriority Priority
+}
+
+func (p *premiumAuths) set(tags map[string]string, minPriority Priority) {
+	p.mu.Lock()
+	p.tags = tags
+	p.minPriority = minPriority
+	p.mu.Unlock()
+}
+
+// reserved reports whether an auth with tags may not serve a request in ctx.
+func (p *premiumAuths) reserved(ctx context.Context, tags map[string]string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(p.tags) == 0 || PriorityFrom(ctx) >= p.minPriority {
+		return false
+	}
+	return MatchTags(tags, p.tags)
+}
+
+// SetPremiumAuths reserves auths carrying tags for requests at minPriority or
+// above. Empty tags disable the reservation.
+func (m *Manager) SetPremiumAuths(tags map[string]string, minPriority Priority) {
+	if m == nil {
+		return
+	}
+	m.premium.set(tags, minPriority)
+}
+
+// acquireRetry decides whether a retry may run and whether it drew on the
+// shared retry budget. High-priority requests retry without drawing on it;
+// low-priority requests only retry while more than half of it is left.
+func (m *Manager) acquireRetry(ctx context.Context) (acquired, ok bool) {
+	switch p := PriorityFrom(ctx); {
+	case p > PriorityNormal:
+		return false, true
+	case p < PriorityNormal:
+		if available, capacity := m.RetryBudgetStats(); available*2 <= capacity {
+			return false, false
+		}
+	}
+	if !m.retryBudget.TryAcquire() {
+		return false, false
+	}
+	return true, true
+}
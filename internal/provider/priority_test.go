@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+func TestConcurrencyLimiterPriorityQueue(t *testing.T) {
+	l := NewConcurrencyLimiter()
+	if l.outranked("claude", "m", PriorityLow) {
+		t.Fatal("outranked with an empty queue")
+	}
+
+	dequeue := l.enqueue("claude", "m", PriorityHigh)
+	if !l.outranked("claude", "m", PriorityNormal) {
+		t.Error("normal request not outranked by a queued high one")
+	}
+	if l.outranked("claude", "m", PriorityHigh) {
+		t.Error("high request outranked by an equal one")
+	}
+	if l.outranked("claude", "other", PriorityLow) {
+		t.Error("queue leaked across models")
+	}
+
+	wake := l.released()
+	dequeue()
+	select {
+	case <-wake:
+	default:
+		t.Error("dequeue did not wake waiting requests")
+	}
+	if l.outranked("claude", "m", PriorityLow) {
+		t.Error("still outranked after the high request left the queue")
+	}
+}
+
+func TestPremiumAuthsReserved(t *testing.T) {
+	var p premiumAuths
+	tier := map[string]string{"tier": "premium"}
+	p.set(tier, PriorityHigh)
+
+	if !p.reserved(context.Background(), tier) {
+		t.Error("premium auth served a normal request")
+	}
+	if p.reserved(WithPriority(context.Background(), PriorityHigh), tier) {
+		t.Error("premium auth refused a high request")
+	}
+	if p.reserved(context.Background(), map[string]string{"tier": "free"}) {
+		t.Error("non-premium auth reserved")
+	}
+}
@@ -29,12 +29,15 @@ func (m *Manager) normalizeProviders(providers []string) []string {
 }
 
 // selectProviders returns providers ordered for execution.
-// It filters out providers with open circuit breakers (unavailable) and applies
-// performance-based scoring to the remaining candidates.
+// It filters out providers with open circuit breakers (unavailable), applies
+// performance-based scoring to the remaining candidates, reorders them by price
+// when cost routing is enabled, applies the active time-of-day schedule, and
+// moves providers demoted for latency SLO violations or failing their canary to the back. The returned decision is non-nil when cost routing
+// changed the basis of the order.
 // If all breakers are open, returns original list to allow fallback probes.
-func (m *Manager) selectProviders(model string, providers []string) []string {
+func (m *Manager) selectProviders(model string, providers []string) ([]string, *RoutingDecision) {
 	if len(providers) <= 1 {
-		return providers
+		return providers, nil
 	}
 
 	// Filter out providers with open circuit breakers
@@ -48,10 +51,13 @@ func (m *Manager) selectProviders(model string, providers []string) []string {
 
 	// If all breakers are open, allow fallback to original list for probe traffic
 	if len(available) == 0 {
-		return providers
+		return providers, nil
 	}
 
-	return m.providerStats.SortByScore(available, model)
+	scored := m.latencySLO.Reorder(m.providerStats.SortByScore(available, model), model)
+	ordered, decision := m.costRouter.Order(scored, model)
+	ordered = m.scheduler.Reorder(ordered, model)
+	return m.canaries.reorder(m.latencySLO.Reorder(ordered, model), model), decision
 }
 
 // recordProviderResult records success/failure for weighted selection.
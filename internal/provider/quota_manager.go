@@ -20,6 +20,13 @@ type AuthQuotaState struct {
 	LearnedLimit    atomic.Int64
 	LearnedCooldown atomic.Int64
 
+	// Usage of the current rolling quota window, see quota_window.go.
+	WindowStart         atomic.Int64
+	WindowMessages      atomic.Int64
+	WindowTokens        atomic.Int64
+	LearnedMessageLimit atomic.Int64
+	ClaudeRateLimit     atomic.Pointer[ClaudeRateLimit]
+
 	RealQuota      atomic.Pointer[RealQuotaSnapshot]
 	refreshTrigger chan struct{}
 	triggerOnce    sync.Once
@@ -446,6 +453,7 @@ func (m *QuotaManager) buildRetryError(auths []*Auth, now time.Time) error {
 			Code:       "quota_exhausted",
 			Message:    fmt.Sprintf("all accounts exhausted, retry after %.0fs", retryAfter.Seconds()),
 			HTTPStatus: 429,
+			RetryIn:    retryAfter,
 		}
 	}
 
@@ -470,6 +478,8 @@ func (m *QuotaManager) RecordRequestEnd(authID, provider string, tokens int64, f
 	}
 
 	if !failed {
+		state.recordWindowUsage(time.Now(), GetProviderQuotaConfig(provider).WindowDuration, tokens)
+
 		// Clear cooldown on successful request - this is the counterpart to
 		// RecordQuotaHit setting cooldown on 429 errors. Without this, an account
 		// could stay in cooldown forever even after successful requests prove
@@ -222,6 +222,9 @@ func TestQuotaManager_Pick_AllExhaustedReturnsError(t *testing.T) {
 	if provErr.HTTPStatus != 429 {
 		t.Errorf("expected 429 status, got %d", provErr.HTTPStatus)
 	}
+	if got := provErr.Headers().Get("Retry-After"); got != "3600" {
+		t.Errorf("expected Retry-After 3600, got %q", got)
+	}
 }
 
 func TestQuotaManager_RecordRequestEnd_ClearsCooldownOnSuccess(t *testing.T) {
@@ -0,0 +1,224 @@
+package provider
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Anthropic reports the state of a subscription's rolling windows on every
+// response to OAuth-authenticated requests.
+const (
+	claudeRateLimitStatusHeader   = "Anthropic-Ratelimit-Unified-Status"
+	claudeRateLimitResetHeader    = "Anthropic-Ratelimit-Unified-Reset"
+	claudeRateLimit5hUtilization  = "Anthropic-Ratelimit-Unified-5h-Utilization"
+	claudeRateLimit5hReset        = "Anthropic-Ratelimit-Unified-5h-Reset"
+	claudeRateLimit7dUtilization  = "Anthropic-Ratelimit-Unified-7d-Utilization"
+	claudeRateLimit7dReset        = "Anthropic-Ratelimit-Unified-7d-Reset"
+	claudeRateLimitStatusRejected = "rejected"
+	claudeFiveHourWindow          = 5 * time.Hour
+	quotaWindowSourceHeaders      = "headers"
+	quotaWindowSourceLearned      = "learned"
+	quotaWindowSourceEstimate     = "estimate"
+)
+
+// ClaudeRateLimit is the unified rate limit state Anthropic returned for a
+// Claude Pro/Max account. Utilizations are fractions of the window used.
+type ClaudeRateLimit struct {
+	Status              string
+	Reset               time.Time
+	FiveHourUtilization float64
+	FiveHourReset       time.Time
+	SevenDayUtilization float64
+	SevenDayReset       time.Time
+	ObservedAt          time.Time
+}
+
+// ParseClaudeRateLimit reads the unified rate limit headers, returning nil
+// when the response carries none (API key accounts).
+func ParseClaudeRateLimit(h http.Header, now time.Time) *ClaudeRateLimit {
+	rl := &ClaudeRateLimit{
+		Status:              h.Get(claudeRateLimitStatusHeader),
+		Reset:               parseUnixHeader(h.Get(claudeRateLimitResetHeader)),
+		FiveHourUtilization: parseFloatHeader(h.Get(claudeRateLimit5hUtilization)),
+		FiveHourReset:       parseUnixHeader(h.Get(claudeRateLimit5hReset)),
+		SevenDayUtilization: parseFloatHeader(h.Get(claudeRateLimit7dUtilization)),
+		SevenDayReset:       parseUnixHeader(h.Get(claudeRateLimit7dReset)),
+		ObservedAt:          now,
+	}
+	if rl.Status == "" && rl.FiveHourReset.IsZero() && rl.SevenDayReset.IsZero() {
+		return nil
+	}
+	return rl
+}
+
+func parseUnixHeader(v string) time.Time {
+	sec, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || sec <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+func parseFloatHeader(v string) float64 {
+	f, _ := strconv.ParseFloat(v, 64)
+	return math.Min(math.Max(f, 0), 1)
+}
+
+// binding returns the utilization and reset time of the window closest to
+// its limit.
+func (rl *ClaudeRateLimit) binding() (float64, time.Time) {
+	util, reset := rl.FiveHourUtilization, rl.FiveHourReset
+	if rl.SevenDayUtilization > util {
+		util, reset = rl.SevenDayUtilization, rl.SevenDayReset
+	}
+	if rl.Status == claudeRateLimitStatusRejected {
+		util = 1
+		if !rl.Reset.IsZero() {
+			reset = rl.Reset
+		}
+	}
+	return util, reset
+}
+
+// RetryAfter returns how long a rejected account must wait, or nil.
+func (rl *ClaudeRateLimit) RetryAfter() *time.Duration {
+	if rl == nil || rl.Status != claudeRateLimitStatusRejected {
+		return nil
+	}
+	_, reset := rl.binding()
+	if !reset.After(rl.ObservedAt) {
+		return nil
+	}
+	d := reset.Sub(rl.ObservedAt)
+	return &d
+}
+
+type quotaManagerContextKey struct{}
+
+// ObserveClaudeRateLimit records the rate limit headers of a Claude response
+// for authID, so selection can steer away from accounts nearing their window
+// limit. It is a no-op outside a manager-driven execution.
+func ObserveClaudeRateLimit(ctx context.Context, authID string, h http.Header) *ClaudeRateLimit {
+	rl := ParseClaudeRateLimit(h, time.Now())
+	if rl == nil || authID == "" {
+		return rl
+	}
+	if qm, _ := ctx.Value(quotaManagerContextKey{}).(*QuotaManager); qm != nil {
+		qm.observeClaudeRateLimit(authID, rl)
+	}
+	return rl
+}
+
+func (m *QuotaManager) observeClaudeRateLimit(authID string, rl *ClaudeRateLimit) {
+	state := m.getOrCreateState(authID)
+	state.ClaudeRateLimit.Store(rl)
+	if !rl.FiveHourReset.IsZero() {
+		state.WindowStart.Store(rl.FiveHourReset.Add(-claudeFiveHourWindow).UnixNano())
+	}
+	util, reset := rl.binding()
+	snapshot := &RealQuotaSnapshot{RemainingFraction: 1 - util, WindowResetAt: reset, FetchedAt: rl.ObservedAt}
+	state.SetRealQuota(snapshot)
+	m.handleQuotaSnapshotUpdate(state, snapshot)
+}
+
+// recordWindowUsage counts a completed request in the current rolling
+// window, opening a new one when the previous window has ended. Like
+// Claude's 5-hour limit, a window starts with the first request after the
+// last one expired.
+func (s *AuthQuotaState) recordWindowUsage(now time.Time, window time.Duration, tokens int64) {
+	if window <= 0 {
+		return
+	}
+	start := s.WindowStart.Load()
+	if start == 0 || now.UnixNano()-start >= int64(window) {
+		if s.WindowStart.CompareAndSwap(start, now.UnixNano()) {
+			s.WindowMessages.Store(0)
+			s.WindowTokens.Store(0)
+		}
+	}
+	s.WindowMessages.Add(1)
+	s.WindowTokens.Add(max(tokens, 0))
+}
+
+// QuotaWindowReport describes one account's use of its rolling quota window
+// and the capacity estimated to remain in it.
+type QuotaWindowReport struct {
+	AuthID              string     `json:"auth_id"`
+	Label               string     `json:"label,omitempty"`
+	WindowStart         *time.Time `json:"window_start,omitempty"`
+	WindowResetAt       *time.Time `json:"window_reset_at,omitempty"`
+	Messages            int64      `json:"messages"`
+	Tokens              int64      `json:"tokens"`
+	ActiveRequests      int64      `json:"active_requests"`
+	LearnedMessageLimit int64      `json:"learned_message_limit,omitempty"`
+	LearnedTokenLimit   int64      `json:"learned_token_limit,omitempty"`
+	RemainingFraction   *float64   `json:"remaining_fraction,omitempty"`
+	RemainingMessages   *int64     `json:"remaining_messages,omitempty"`
+	RemainingTokens     *int64     `json:"remaining_tokens,omitempty"`
+	// Source tells where RemainingFraction comes from: "headers" reported by
+	// the provider, "learned" from earlier 429s or "estimate" from defaults.
+	Source              string     `json:"source,omitempty"`
+	UpstreamStatus      string     `json:"upstream_status,omitempty"`
+	FiveHourUtilization *float64   `json:"five_hour_utilization,omitempty"`
+	SevenDayUtilization *float64   `json:"seven_day_utilization,omitempty"`
+	SevenDayResetAt     *time.Time `json:"seven_day_reset_at,omitempty"`
+	CooldownUntil       *time.Time `json:"cooldown_until,omitempty"`
+	LastExhaustedAt     *time.Time `json:"last_exhausted_at,omitempty"`
+}
+
+// WindowReport returns the quota window state of auth at now.
+func (m *QuotaManager) WindowReport(auth *Auth, now time.Time) QuotaWindowReport {
+	report := QuotaWindowReport{AuthID: auth.ID, Label: auth.Label}
+	state := m.getState(auth.ID)
+	if state == nil {
+		return report
+	}
+	window := GetProviderQuotaConfig(auth.Provider).WindowDuration
+	report.ActiveRequests = state.ActiveRequests.Load()
+	report.LearnedMessageLimit = state.LearnedMessageLimit.Load()
+	report.LearnedTokenLimit = state.LearnedLimit.Load()
+	if until := state.GetCooldownUntil(); until.After(now) {
+		report.CooldownUntil = &until
+	}
+	if at := state.GetLastExhaustedAt(); !at.IsZero() {
+		report.LastExhaustedAt = &at
+	}
+
+	if ns := state.WindowStart.Load(); ns != 0 && now.Sub(time.Unix(0, ns)) < window {
+		start, reset := time.Unix(0, ns), time.Unix(0, ns).Add(window)
+		report.WindowStart, report.WindowResetAt = &start, &reset
+		report.Messages = state.WindowMessages.Load()
+		report.Tokens = state.WindowTokens.Load()
+	}
+	if report.LearnedMessageLimit > 0 {
+		remaining := max(report.LearnedMessageLimit-report.Messages, 0)
+		report.RemainingMessages = &remaining
+	}
+
+	if rl := state.ClaudeRateLimit.Load(); rl != nil && (rl.FiveHourReset.IsZero() || rl.FiveHourReset.After(now)) {
+		util, _ := rl.binding()
+		remaining := 1 - util
+		five, seven := rl.FiveHourUtilization, rl.SevenDayUtilization
+		report.RemainingFraction, report.Source = &remaining, quotaWindowSourceHeaders
+		report.UpstreamStatus = rl.Status
+		report.FiveHourUtilization, report.SevenDayUtilization = &five, &seven
+		if !rl.SevenDayReset.IsZero() {
+			report.SevenDayResetAt = &rl.SevenDayReset
+		}
+		return report
+	}
+
+	limit, source := report.LearnedTokenLimit, quotaWindowSourceLearned
+	if limit <= 0 {
+		limit, source = GetProviderQuotaConfig(auth.Provider).EstimatedLimit, quotaWindowSourceEstimate
+	}
+	if limit > 0 {
+		remainingTokens := max(limit-report.Tokens, 0)
+		fraction := float64(remainingTokens) / float64(limit)
+		report.RemainingTokens, report.RemainingFraction, report.Source = &remainingTokens, &fraction, source
+	}
+	return report
+}
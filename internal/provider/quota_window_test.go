@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestQuotaWindowReportFromUsageAnd429(t *testing.T) {
+	m := NewQuotaManager()
+	auth := &Auth{ID: "claude-1", Provider: "claude", Label: "max"}
+
+	for range 3 {
+		m.RecordRequestStart(auth.ID)
+		m.RecordRequestEnd(auth.ID, "claude", 1000, false)
+	}
+	report := m.WindowReport(auth, time.Now())
+	if report.Messages != 3 || report.Tokens != 3000 || report.WindowResetAt == nil {
+		t.Fatalf("report = %+v", report)
+	}
+	if report.Source != quotaWindowSourceEstimate || *report.RemainingTokens != 500_000-3000 {
+		t.Errorf("estimate: source %q, remaining %d", report.Source, *report.RemainingTokens)
+	}
+
+	m.RecordQuotaHit(auth.ID, "claude", "claude-sonnet-4", nil)
+	report = m.WindowReport(auth, time.Now())
+	if report.LearnedMessageLimit != 3 || *report.RemainingMessages != 0 || report.CooldownUntil == nil {
+		t.Errorf("after 429: %+v", report)
+	}
+
+	// A new window opens once the previous one has ended.
+	report = m.WindowReport(auth, time.Now().Add(6*time.Hour))
+	if report.Messages != 0 || report.WindowStart != nil {
+		t.Errorf("expired window still reported: %+v", report)
+	}
+}
+
+func TestObserveClaudeRateLimit(t *testing.T) {
+	m := NewQuotaManager()
+	auth := &Auth{ID: "claude-1", Provider: "claude"}
+	ctx := context.WithValue(context.Background(), quotaManagerContextKey{}, m)
+	reset := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+
+	h := http.Header{}
+	h.Set(claudeRateLimitStatusHeader, "allowed_warning")
+	h.Set(claudeRateLimit5hUtilization, "0.99")
+	h.Set(claudeRateLimit5hReset, strconv.FormatInt(reset.Unix(), 10))
+	h.Set(claudeRateLimit7dUtilization, "0.4")
+	if rl := ObserveClaudeRateLimit(ctx, auth.ID, h); rl == nil || rl.RetryAfter() != nil {
+		t.Fatalf("rate limit = %+v", rl)
+	}
+
+	report := m.WindowReport(auth, time.Now())
+	if report.Source != quotaWindowSourceHeaders || *report.RemainingFraction > 0.011 {
+		t.Errorf("report = %+v", report)
+	}
+	if !report.WindowResetAt.Equal(reset) {
+		t.Errorf("window reset = %v, want %v", report.WindowResetAt, reset)
+	}
+	// Nearly exhausted accounts are taken out of rotation until the reset.
+	if got := m.checkAvailability(m.getState(auth.ID), auth, "claude-sonnet-4", time.Now()); got != availabilityBlocked {
+		t.Errorf("availability = %v, want blocked", got)
+	}
+
+	h.Set(claudeRateLimitStatusHeader, claudeRateLimitStatusRejected)
+	h.Set(claudeRateLimitResetHeader, strconv.FormatInt(reset.Unix(), 10))
+	if ra := ObserveClaudeRateLimit(ctx, auth.ID, h).RetryAfter(); ra == nil || *ra < time.Hour {
+		t.Errorf("retry after = %v", ra)
+	}
+	if ObserveClaudeRateLimit(ctx, auth.ID, http.Header{}) != nil {
+		t.Error("responses without unified headers should be ignored")
+	}
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// RefreshHook refreshes the credentials of an auth with custom logic, such
+// as a corporate SSO token exchange, ahead of the provider executor's
+// built-in refresher. Refresh receives a copy of the auth. It returns
+// handled false to pass the auth on to the next hook or the built-in
+// refresher; when handled is true, its result and error are final.
+type RefreshHook interface {
+	Refresh(ctx context.Context, auth *Auth) (updated *Auth, handled bool, err error)
+}
+
+// RefreshHookFunc adapts a function to RefreshHook.
+type RefreshHookFunc func(ctx context.Context, auth *Auth) (*Auth, bool, error)
+
+// Refresh implements RefreshHook.
+func (f RefreshHookFunc) Refresh(ctx context.Context, auth *Auth) (*Auth, bool, error) {
+	return f(ctx, auth)
+}
+
+// refreshHooks holds the registered hooks per provider. The zero value is
+// ready to use.
+type refreshHooks struct {
+	mu    sync.RWMutex
+	hooks map[string][]RefreshHook
+}
+
+func (h *refreshHooks) add(provider string, hook RefreshHook) {
+	key := strings.ToLower(strings.TrimSpace(provider))
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.hooks == nil {
+		h.hooks = make(map[string][]RefreshHook)
+	}
+	h.hooks[key] = append(h.hooks[key], hook)
+}
+
+// refresh runs the hooks of the auth's provider in registration order and
+// falls back to exec when none handles the auth.
+func (h *refreshHooks) refresh(ctx context.Context, exec ProviderExecutor, auth *Auth) (*Auth, error) {
+	h.mu.RLock()
+	hooks := h.hooks[strings.ToLower(strings.TrimSpace(auth.Provider))]
+	h.mu.RUnlock()
+	for _, hook := range hooks {
+		if updated, handled, err := hook.Refresh(ctx, auth); handled {
+			return updated, err
+		}
+	}
+	return exec.Refresh(ctx, auth)
+}
+
+// RegisterRefreshHook adds hook for the auths of provider. Hooks run in
+// registration order before the executor's built-in refresher, both for
+// scheduled refreshes and refreshes ahead of token expiry.
+func (m *Manager) RegisterRefreshHook(provider string, hook RefreshHook) {
+	if m == nil || hook == nil {
+		return
+	}
+	m.refreshHooks.add(provider, hook)
+}
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// refreshOnlyExecutor records built-in refreshes; other methods are unused.
+type refreshOnlyExecutor struct {
+	ProviderExecutor
+	calls int
+}
+
+func (e *refreshOnlyExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) {
+	e.calls++
+	return auth, nil
+}
+
+func TestRefreshHooksRunBeforeBuiltIn(t *testing.T) {
+	var hooks refreshHooks
+	var order []string
+	hooks.add("Claude", RefreshHookFunc(func(_ context.Context, auth *Auth) (*Auth, bool, error) {
+		order = append(order, "pass")
+		return nil, false, nil
+	}))
+	hooks.add("claude", RefreshHookFunc(func(_ context.Context, auth *Auth) (*Auth, bool, error) {
+		order = append(order, "sso")
+		if auth.Label == "broken" {
+			return nil, true, errors.New("sso exchange failed")
+		}
+		updated := auth.Clone()
+		updated.Metadata = map[string]any{"access_token": "from-sso"}
+		return updated, true, nil
+	}))
+	exec := &refreshOnlyExecutor{}
+
+	updated, err := hooks.refresh(context.Background(), exec, &Auth{ID: "a", Provider: "claude"})
+	if err != nil || updated.Metadata["access_token"] != "from-sso" {
+		t.Fatalf("updated = %+v, err = %v", updated, err)
+	}
+	if _, err := hooks.refresh(context.Background(), exec, &Auth{ID: "b", Provider: "claude", Label: "broken"}); err == nil {
+		t.Fatal("handled hook error was not returned")
+	}
+	if exec.calls != 0 {
+		t.Fatalf("built-in refresher ran %d times after a hook handled the auth", exec.calls)
+	}
+	if len(order) != 4 || order[0] != "pass" || order[1] != "sso" {
+		t.Fatalf("hook order = %v", order)
+	}
+
+	if _, err := hooks.refresh(context.Background(), exec, &Auth{ID: "c", Provider: "gemini"}); err != nil || exec.calls != 1 {
+		t.Fatalf("provider without hooks: err = %v, built-in calls = %d", err, exec.calls)
+	}
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RequestTimings collects the phases of one client request for the access
+// log. Requests that are not sampled carry none, and every method is a no-op
+// on a nil receiver.
+type RequestTimings struct {
+	mu        sync.Mutex
+	start     time.Time
+	queue     time.Duration
+	sent      time.Time // first upstream request
+	lastSent  time.Time // latest upstream attempt
+	firstByte time.Time // response headers of the latest attempt
+	attempts  int
+	provider  string
+	model     string
+	authID    string
+}
+
+// RequestTimingsSnapshot is a copy of the collected timings.
+type RequestTimingsSnapshot struct {
+	Start     time.Time
+	Queue     time.Duration
+	Sent      time.Time
+	LastSent  time.Time
+	FirstByte time.Time
+	Attempts  int
+	Provider  string
+	Model     string
+	AuthID    string
+}
+
+type requestTimingsKey struct{}
+
+// WithRequestTimings attaches t to ctx.
+func WithRequestTimings(ctx context.Context, t *RequestTimings) context.Context {
+	return context.WithValue(ctx, requestTimingsKey{}, t)
+}
+
+// RequestTimingsFrom returns the timings attached to ctx, or nil.
+func RequestTimingsFrom(ctx context.Context) *RequestTimings {
+	if ctx == nil {
+		return nil
+	}
+	t, _ := ctx.Value(requestTimingsKey{}).(*RequestTimings)
+	return t
+}
+
+// NewRequestTimings starts timing a request at start.
+func NewRequestTimings(start time.Time) *RequestTimings {
+	return &RequestTimings{start: start}
+}
+
+// AddQueue adds time spent waiting for a concurrency slot since since.
+func (t *RequestTimings) AddQueue(since time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.queue += time.Since(since)
+	t.mu.Unlock()
+}
+
+// SetTarget records the provider, model and auth serving the request.
+func (t *RequestTimings) SetTarget(provider, model, authID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.provider, t.model, t.authID = provider, model, authID
+	t.mu.Unlock()
+}
+
+// MarkSent records an upstream request being sent.
+func (t *RequestTimings) MarkSent() {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	t.mu.Lock()
+	if t.sent.IsZero() {
+		t.sent = now
+	}
+	t.lastSent = now
+	t.firstByte = time.Time{}
+	t.attempts++
+	t.mu.Unlock()
+}
+
+// MarkFirstByte records the upstream response headers arriving.
+func (t *RequestTimings) MarkFirstByte() {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	t.mu.Lock()
+	t.firstByte = now
+	t.mu.Unlock()
+}
+
+// Snapshot returns a copy of the timings.
+func (t *RequestTimings) Snapshot() RequestTimingsSnapshot {
+	if t == nil {
+		return RequestTimingsSnapshot{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return RequestTimingsSnapshot{
+		Start:     t.start,
+		Queue:     t.queue,
+		Sent:      t.sent,
+		LastSent:  t.lastSent,
+		FirstByte: t.firstByte,
+		Attempts:  t.attempts,
+		Provider:  t.provider,
+		Model:     t.model,
+		AuthID:    t.authID,
+	}
+}
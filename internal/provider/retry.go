@@ -14,6 +14,11 @@ import (
 const (
 	quotaBackoffBase = time.Second
 	quotaBackoffMax  = 30 * time.Minute
+
+	// Overload is upstream-wide and short-lived, so overloaded auths cool
+	// down briefly instead of entering quota backoff.
+	overloadCooldownDefault = 5 * time.Second
+	overloadCooldownMax     = time.Minute
 )
 
 var quotaCooldownDisabled atomic.Bool
@@ -258,3 +263,13 @@ func nextQuotaCooldown(prevLevel int) (time.Duration, int) {
 	}
 	return cooldown, prevLevel + 1
 }
+
+// overloadCooldown returns how long an auth rests after an overloaded
+// response: the upstream's Retry-After, capped at overloadCooldownMax, or
+// overloadCooldownDefault.
+func overloadCooldown(retryAfter *time.Duration) time.Duration {
+	if retryAfter == nil || *retryAfter <= 0 {
+		return overloadCooldownDefault
+	}
+	return min(*retryAfter, overloadCooldownMax)
+}
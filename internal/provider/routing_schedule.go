@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/sseutil"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+// RoutingSchedule reorders providers for matching models while its time window is active.
+type RoutingSchedule struct {
+	// ModelPattern is a glob-style model pattern. Empty matches every model.
+	ModelPattern string
+	// Days limits the schedule to these weekdays. Empty means every day.
+	Days []time.Weekday
+	// Start and End are offsets from midnight. End before Start wraps past
+	// midnight; both zero means the whole day.
+	Start, End time.Duration
+	// Prefer lists providers moved to the front, in order.
+	Prefer []string
+	// Avoid lists providers moved to the back. They remain available as fallbacks.
+	Avoid []string
+}
+
+// active reports whether now falls inside the schedule's window.
+func (s RoutingSchedule) active(now time.Time) bool {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	offset := now.Sub(midnight)
+	day := now.Weekday()
+	inWindow := true
+	switch {
+	case s.Start == s.End:
+	case s.Start < s.End:
+		inWindow = offset >= s.Start && offset < s.End
+	default:
+		// Overnight window: the part after midnight belongs to the previous day.
+		if offset < s.End {
+			day = (day + 6) % 7
+		} else {
+			inWindow = offset >= s.Start
+		}
+	}
+	return inWindow && (len(s.Days) == 0 || slices.Contains(s.Days, day))
+}
+
+func (s RoutingSchedule) apply(providers []string) []string {
+	front := make([]string, 0, len(providers))
+	for _, p := range s.Prefer {
+		if slices.Contains(providers, p) && !slices.Contains(front, p) {
+			front = append(front, p)
+		}
+	}
+	var middle, back []string
+	for _, p := range providers {
+		switch {
+		case slices.Contains(front, p):
+		case slices.Contains(s.Avoid, p):
+			back = append(back, p)
+		default:
+			middle = append(middle, p)
+		}
+	}
+	return append(append(front, middle...), back...)
+}
+
+// QuotaBudget caps how much a single auth may be used per day for matching models.
+type QuotaBudget struct {
+	// Provider is the provider identifier. Empty matches every provider.
+	Provider string
+	// ModelPattern is a glob-style model pattern. Empty matches every model.
+	ModelPattern string
+	// DailyTokens and DailyRequests are the per-auth limits. Zero disables a limit.
+	DailyTokens   int64
+	DailyRequests int64
+}
+
+type budgetUsage struct {
+	tokens   int64
+	requests int64
+}
+
+// RoutingScheduler applies time-of-day provider preferences and tracks daily
+// per-auth usage against quota budgets. It consumes usage records to count
+// consumption; counters reset at midnight in the configured location.
+type RoutingScheduler struct {
+	mu        sync.Mutex
+	location  *time.Location
+	schedules []RoutingSchedule
+	budgets   []QuotaBudget
+	day       string
+	used      map[string]map[string]*budgetUsage // authID -> model -> usage
+	now       func() time.Time
+}
+
+// NewRoutingScheduler creates a scheduler with no rules using the local time zone.
+func NewRoutingScheduler() *RoutingScheduler {
+	return &RoutingScheduler{
+		location: time.Local,
+		used:     make(map[string]map[string]*budgetUsage),
+		now:      time.Now,
+	}
+}
+
+// SetRules replaces the schedules and budgets. A nil location uses local time.
+// Usage counted so far today is kept.
+func (r *RoutingScheduler) SetRules(loc *time.Location, schedules []RoutingSchedule, budgets []QuotaBudget) {
+	if r == nil {
+		return
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	r.mu.Lock()
+	r.location = loc
+	r.schedules = append([]RoutingSchedule(nil), schedules...)
+	r.budgets = append([]QuotaBudget(nil), budgets...)
+	r.mu.Unlock()
+}
+
+// Reorder applies the first active schedule matching model.
+func (r *RoutingScheduler) Reorder(providers []string, model string) []string {
+	if r == nil || len(providers) <= 1 {
+		return providers
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now().In(r.location)
+	for _, s := range r.schedules {
+		if s.ModelPattern != "" && !sseutil.MatchModelPattern(s.ModelPattern, model) {
+			continue
+		}
+		if s.active(now) {
+			return s.apply(providers)
+		}
+	}
+	return providers
+}
+
+// Exhausted reports whether authID has used up its daily budget for model.
+func (r *RoutingScheduler) Exhausted(provider, model, authID string) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.budgets) == 0 {
+		return false
+	}
+	r.rollover()
+	for _, b := range r.budgets {
+		if b.Provider != "" && b.Provider != provider {
+			continue
+		}
+		if b.ModelPattern != "" && !sseutil.MatchModelPattern(b.ModelPattern, model) {
+			continue
+		}
+		var total budgetUsage
+		for m, u := range r.used[authID] {
+			if b.ModelPattern == "" || sseutil.MatchModelPattern(b.ModelPattern, m) {
+				total.tokens += u.tokens
+				total.requests += u.requests
+			}
+		}
+		return (b.DailyTokens > 0 && total.tokens >= b.DailyTokens) ||
+			(b.DailyRequests > 0 && total.requests >= b.DailyRequests)
+	}
+	return false
+}
+
+// HandleUsage implements usage.Plugin, counting successful requests per auth.
+func (r *RoutingScheduler) HandleUsage(_ context.Context, record usage.Record) {
+	if r == nil || record.AuthID == "" || record.Failed {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rollover()
+	models := r.used[record.AuthID]
+	if models == nil {
+		models = make(map[string]*budgetUsage)
+		r.used[record.AuthID] = models
+	}
+	u := models[record.Model]
+	if u == nil {
+		u = &budgetUsage{}
+		models[record.Model] = u
+	}
+	u.requests++
+	if record.Usage != nil {
+		u.tokens += record.Usage.TotalTokens
+	}
+}
+
+// rollover clears the counters when the day changes. Caller must hold r.mu.
+func (r *RoutingScheduler) rollover() {
+	day := r.now().In(r.location).Format(time.DateOnly)
+	if day != r.day {
+		r.day = day
+		clear(r.used)
+	}
+}
+
+func quotaBudgetExhaustedError(provider, model string) *Error {
+	return &Error{
+		Code:       "quota_budget_exhausted",
+		Message:    fmt.Sprintf("all %s auths have used their daily budget for %s", provider, model),
+		HTTPStatus: 429,
+	}
+}
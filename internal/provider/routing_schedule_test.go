@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+func TestRoutingSchedulerReorder(t *testing.T) {
+	r := NewRoutingScheduler()
+	r.SetRules(time.UTC, []RoutingSchedule{
+		{ModelPattern: "gemini-*", Days: []time.Weekday{time.Monday}, Start: 9 * time.Hour, End: 18 * time.Hour, Prefer: []string{"gemini"}},
+		{ModelPattern: "gemini-*", Start: 18 * time.Hour, End: 9 * time.Hour, Prefer: []string{"gemini-cli"}, Avoid: []string{"gemini"}},
+	}, nil)
+	providers := []string{"gemini", "vertex", "gemini-cli"}
+
+	// Monday 2026-10-12 10:00 UTC: business hours.
+	r.now = func() time.Time { return time.Date(2026, 10, 12, 10, 0, 0, 0, time.UTC) }
+	if got := r.Reorder(providers, "gemini-2.5-pro"); !slices.Equal(got, []string{"gemini", "vertex", "gemini-cli"}) {
+		t.Fatalf("business hours order = %v", got)
+	}
+
+	// Tuesday 02:00 UTC: overnight window.
+	r.now = func() time.Time { return time.Date(2026, 10, 13, 2, 0, 0, 0, time.UTC) }
+	if got := r.Reorder(providers, "gemini-2.5-pro"); !slices.Equal(got, []string{"gemini-cli", "vertex", "gemini"}) {
+		t.Fatalf("off-hours order = %v", got)
+	}
+	if got := r.Reorder(providers, "claude-sonnet-4-5"); !slices.Equal(got, providers) {
+		t.Fatalf("unmatched model reordered: %v", got)
+	}
+}
+
+func TestRoutingSchedulerBudget(t *testing.T) {
+	r := NewRoutingScheduler()
+	r.SetRules(time.UTC, nil, []QuotaBudget{{Provider: "gemini-cli", DailyRequests: 2}})
+	day := time.Date(2026, 10, 12, 10, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return day }
+
+	rec := usage.Record{Provider: "gemini-cli", Model: "gemini-2.5-pro", AuthID: "a", Usage: &ir.Usage{TotalTokens: 10}}
+	r.HandleUsage(context.Background(), rec)
+	if r.Exhausted("gemini-cli", "gemini-2.5-pro", "a") {
+		t.Fatal("budget exhausted after one request")
+	}
+	r.HandleUsage(context.Background(), rec)
+	if !r.Exhausted("gemini-cli", "gemini-2.5-pro", "a") {
+		t.Fatal("budget not exhausted after two requests")
+	}
+	if r.Exhausted("gemini-cli", "gemini-2.5-pro", "b") || r.Exhausted("claude", "gemini-2.5-pro", "a") {
+		t.Fatal("budget applied to other auth or provider")
+	}
+
+	day = day.Add(24 * time.Hour)
+	if r.Exhausted("gemini-cli", "gemini-2.5-pro", "a") {
+		t.Fatal("budget not reset on a new day")
+	}
+}
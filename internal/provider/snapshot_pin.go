@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"sync"
+)
+
+// UpstreamModelResolver is implemented by executors that send a model under
+// a different upstream name depending on the auth, such as configured model
+// aliases. Executors without it send the model name unchanged.
+type UpstreamModelResolver interface {
+	UpstreamModel(auth *Auth, model string) string
+}
+
+// upstreamSnapshot returns the upstream model executor sends for model
+// with auth.
+func upstreamSnapshot(executor ProviderExecutor, auth *Auth, model string) string {
+	if r, ok := executor.(UpstreamModelResolver); ok {
+		if snapshot := r.UpstreamModel(auth, model); snapshot != "" {
+			return snapshot
+		}
+	}
+	return model
+}
+
+// PinnedSnapshot is the upstream model that served a request and the
+// provider it came from.
+type PinnedSnapshot struct {
+	Provider string
+	Snapshot string
+}
+
+// SnapshotPin keeps a conversation on the upstream snapshot its first turn
+// was served by. It carries the snapshots pinned by earlier turns, keyed by
+// requested model, and records the snapshot that serves the current
+// request. Every method is a no-op on a nil receiver.
+type SnapshotPin struct {
+	pinned map[string]PinnedSnapshot
+
+	mu       sync.Mutex
+	model    string
+	resolved PinnedSnapshot
+}
+
+type snapshotPinKey struct{}
+
+type pinnedSnapshotKey struct{}
+
+// NewSnapshotPin returns a pin preferring the given snapshots, which must
+// not be modified afterwards.
+func NewSnapshotPin(pinned map[string]PinnedSnapshot) *SnapshotPin {
+	return &SnapshotPin{pinned: pinned}
+}
+
+// WithSnapshotPin attaches p to ctx.
+func WithSnapshotPin(ctx context.Context, p *SnapshotPin) context.Context {
+	return context.WithValue(ctx, snapshotPinKey{}, p)
+}
+
+// SnapshotPinFrom returns the pin attached to ctx, or nil.
+func SnapshotPinFrom(ctx context.Context) *SnapshotPin {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(snapshotPinKey{}).(*SnapshotPin)
+	return p
+}
+
+// Pinned returns the snapshot pinned for model.
+func (p *SnapshotPin) Pinned(model string) (PinnedSnapshot, bool) {
+	if p == nil {
+		return PinnedSnapshot{}, false
+	}
+	s, ok := p.pinned[model]
+	return s, ok
+}
+
+// Resolved returns the requested model and the snapshot that served it;
+// ok is false before a request succeeded.
+func (p *SnapshotPin) Resolved() (model string, s PinnedSnapshot, ok bool) {
+	if p == nil {
+		return "", PinnedSnapshot{}, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.model, p.resolved, p.model != ""
+}
+
+func (p *SnapshotPin) resolve(model string, s PinnedSnapshot) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.model, p.resolved = model, s
+	p.mu.Unlock()
+}
+
+// withPinnedSnapshot records in ctx the snapshot pinned for model, if the
+// conversation has one, for the auth pickers.
+func withPinnedSnapshot(ctx context.Context, model string) context.Context {
+	if s, ok := SnapshotPinFrom(ctx).Pinned(model); ok {
+		return context.WithValue(ctx, pinnedSnapshotKey{}, s.Snapshot)
+	}
+	return ctx
+}
+
+// preferPinnedSnapshot keeps the candidates serving the snapshot pinned in
+// ctx. When none does, for instance because its auths are cooling down, all
+// candidates are kept: a different snapshot beats failing the turn.
+func preferPinnedSnapshot[T any](ctx context.Context, candidates []T, snapshot func(T) string) []T {
+	pinned, _ := ctx.Value(pinnedSnapshotKey{}).(string)
+	if pinned == "" || len(candidates) < 2 {
+		return candidates
+	}
+	var matching []T
+	for _, c := range candidates {
+		if snapshot(c) == pinned {
+			matching = append(matching, c)
+		}
+	}
+	if len(matching) == 0 {
+		return candidates
+	}
+	return matching
+}
+
+// preferPinnedProvider moves the provider that served the pinned snapshot of
+// model to the front of providers.
+func preferPinnedProvider(ctx context.Context, model string, providers []string) []string {
+	s, ok := SnapshotPinFrom(ctx).Pinned(model)
+	if !ok || len(providers) < 2 || providers[0] == s.Provider {
+		return providers
+	}
+	for i, p := range providers {
+		if p == s.Provider {
+			out := make([]string, 0, len(providers))
+			out = append(out, p)
+			out = append(out, providers[:i]...)
+			return append(out, providers[i+1:]...)
+		}
+	}
+	return providers
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestSnapshotPinPreferences(t *testing.T) {
+	pin := NewSnapshotPin(map[string]PinnedSnapshot{"m": {Provider: "b", Snapshot: "m-v2"}})
+	ctx := WithSnapshotPin(context.Background(), pin)
+
+	if got := preferPinnedProvider(ctx, "m", []string{"a", "b", "c"}); !slices.Equal(got, []string{"b", "a", "c"}) {
+		t.Errorf("preferPinnedProvider = %v", got)
+	}
+	if got := preferPinnedProvider(ctx, "other", []string{"a", "b"}); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("unpinned model reordered: %v", got)
+	}
+
+	snapshots := map[string]string{"x": "m-v1", "y": "m-v2", "z": "m-v2"}
+	snapshot := func(id string) string { return snapshots[id] }
+	pickCtx := withPinnedSnapshot(ctx, "m")
+	if got := preferPinnedSnapshot(pickCtx, []string{"x", "y", "z"}, snapshot); !slices.Equal(got, []string{"y", "z"}) {
+		t.Errorf("preferPinnedSnapshot = %v", got)
+	}
+	if got := preferPinnedSnapshot(pickCtx, []string{"x"}, snapshot); !slices.Equal(got, []string{"x"}) {
+		t.Errorf("only candidate dropped: %v", got)
+	}
+	if got := preferPinnedSnapshot(ctx, []string{"x", "y"}, snapshot); len(got) != 2 {
+		t.Errorf("candidates filtered without a pinned model: %v", got)
+	}
+
+	pin.resolve("m", PinnedSnapshot{Provider: "b", Snapshot: "m-v2"})
+	if model, s, ok := pin.Resolved(); !ok || model != "m" || s.Snapshot != "m-v2" {
+		t.Errorf("Resolved = %q %+v %v", model, s, ok)
+	}
+	if _, _, ok := (*SnapshotPin)(nil).Resolved(); ok {
+		t.Error("nil pin resolved")
+	}
+}
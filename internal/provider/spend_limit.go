@@ -0,0 +1,244 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+// defaultSpendWarnAt is the fraction of a limit at which a warning is raised.
+const defaultSpendWarnAt = 0.8
+
+// SpendLimit caps the monthly tokens or cost of an auth.
+type SpendLimit struct {
+	// Provider is the provider identifier. Empty matches every provider.
+	Provider string
+	// AuthID limits the rule to one auth. Empty applies it to each matching auth.
+	AuthID string
+	// MonthlyTokens and MonthlyCost (USD, priced with the cost routing table)
+	// are hard ceilings. Zero disables a ceiling.
+	MonthlyTokens int64
+	MonthlyCost   float64
+	// WarnAt is the fraction of a ceiling that raises a warning. Zero uses 0.8.
+	WarnAt float64
+}
+
+// Spend statuses reported by SpendLimiter.Status.
+const (
+	SpendOK      = "ok"
+	SpendWarning = "warning"
+	SpendBlocked = "blocked"
+)
+
+// AuthSpend is an auth's month-to-date usage against its spend limit.
+type AuthSpend struct {
+	Month       string  `json:"month"`
+	Tokens      int64   `json:"tokens"`
+	Cost        float64 `json:"cost"`
+	TokenLimit  int64   `json:"token_limit,omitempty"`
+	CostLimit   float64 `json:"cost_limit,omitempty"`
+	UsedPercent float64 `json:"used_percent"`
+	Status      string  `json:"status"`
+}
+
+type spendUsage struct {
+	tokens int64
+	cost   float64
+	status string // last status logged
+}
+
+// SpendLimiter tracks month-to-date usage per auth from usage records and
+// blocks auths that reached their ceiling until the next month.
+type SpendLimiter struct {
+	mu       sync.Mutex
+	location *time.Location
+	limits   []SpendLimit
+	prices   *CostRouter
+	month    string
+	used     map[string]*spendUsage // authID -> usage
+	now      func() time.Time
+}
+
+// NewSpendLimiter creates a limiter with no limits that prices usage with prices.
+func NewSpendLimiter(prices *CostRouter) *SpendLimiter {
+	return &SpendLimiter{
+		location: time.Local,
+		prices:   prices,
+		used:     make(map[string]*spendUsage),
+		now:      time.Now,
+	}
+}
+
+// SetLimits replaces the limits. Month boundaries use loc; nil uses local time.
+// Usage counted so far this month is kept.
+func (l *SpendLimiter) SetLimits(loc *time.Location, limits []SpendLimit) {
+	if l == nil {
+		return
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	l.mu.Lock()
+	l.location = loc
+	l.limits = append([]SpendLimit(nil), limits...)
+	l.mu.Unlock()
+}
+
+// MonthStart returns the start of the current month in the limiter's location.
+func (l *SpendLimiter) MonthStart() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now().In(l.location)
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, l.location)
+}
+
+// Bootstrap seeds month-to-date token counts, e.g. from persisted usage after
+// a restart. Counts already recorded are kept when larger.
+func (l *SpendLimiter) Bootstrap(tokens map[string]int64) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollover()
+	for authID, n := range tokens {
+		u := l.usageLocked(authID)
+		u.tokens = max(u.tokens, n)
+	}
+}
+
+// limitFor returns the limit for authID, preferring rules naming the auth.
+// Caller must hold l.mu.
+func (l *SpendLimiter) limitFor(provider, authID string) (SpendLimit, bool) {
+	var fallback *SpendLimit
+	for i, lim := range l.limits {
+		if lim.Provider != "" && lim.Provider != provider {
+			continue
+		}
+		if lim.AuthID == authID {
+			return lim, true
+		}
+		if lim.AuthID == "" && fallback == nil {
+			fallback = &l.limits[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return SpendLimit{}, false
+}
+
+// statusLocked computes the spend of authID. Caller must hold l.mu.
+func (l *SpendLimiter) statusLocked(provider, authID string) (AuthSpend, bool) {
+	lim, ok := l.limitFor(provider, authID)
+	if !ok {
+		return AuthSpend{}, false
+	}
+	l.rollover()
+	s := AuthSpend{Month: l.month, TokenLimit: lim.MonthlyTokens, CostLimit: lim.MonthlyCost, Status: SpendOK}
+	if u := l.used[authID]; u != nil {
+		s.Tokens, s.Cost = u.tokens, u.cost
+	}
+	var fraction float64
+	if lim.MonthlyTokens > 0 {
+		fraction = float64(s.Tokens) / float64(lim.MonthlyTokens)
+	}
+	if lim.MonthlyCost > 0 && s.Cost/lim.MonthlyCost > fraction {
+		fraction = s.Cost / lim.MonthlyCost
+	}
+	warnAt := lim.WarnAt
+	if warnAt <= 0 {
+		warnAt = defaultSpendWarnAt
+	}
+	switch {
+	case fraction >= 1:
+		s.Status = SpendBlocked
+	case fraction >= warnAt:
+		s.Status = SpendWarning
+	}
+	s.UsedPercent = fraction * 100
+	return s, true
+}
+
+// Status returns authID's month-to-date spend, or false when no limit applies.
+func (l *SpendLimiter) Status(provider, authID string) (AuthSpend, bool) {
+	if l == nil {
+		return AuthSpend{}, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.statusLocked(provider, authID)
+}
+
+// Blocked reports whether authID has reached its monthly ceiling.
+func (l *SpendLimiter) Blocked(provider, authID string) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.limits) == 0 {
+		return false
+	}
+	s, ok := l.statusLocked(provider, authID)
+	return ok && s.Status == SpendBlocked
+}
+
+// HandleUsage implements usage.Plugin, adding the tokens and priced cost of
+// each successful request and logging when an auth crosses a threshold.
+func (l *SpendLimiter) HandleUsage(_ context.Context, record usage.Record) {
+	if l == nil || record.AuthID == "" || record.Failed || record.Usage == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollover()
+	u := l.usageLocked(record.AuthID)
+	u.tokens += record.Usage.TotalTokens
+	if price, ok := l.prices.Price(record.Provider, record.Model); ok {
+		u.cost += price.Cost(record.Usage)
+	}
+
+	s, ok := l.statusLocked(record.Provider, record.AuthID)
+	if !ok || s.Status == u.status {
+		return
+	}
+	u.status = s.Status
+	switch s.Status {
+	case SpendWarning:
+		log.Warnf("spend limit: auth %s (%s) at %.0f%% of its monthly limit", record.AuthID, record.Provider, s.UsedPercent)
+	case SpendBlocked:
+		log.Errorf("spend limit: auth %s (%s) reached its monthly limit and is blocked until next month", record.AuthID, record.Provider)
+	}
+}
+
+// usageLocked returns the counters for authID, creating them. Caller must hold l.mu.
+func (l *SpendLimiter) usageLocked(authID string) *spendUsage {
+	u := l.used[authID]
+	if u == nil {
+		u = &spendUsage{status: SpendOK}
+		l.used[authID] = u
+	}
+	return u
+}
+
+// rollover clears the counters when the month changes. Caller must hold l.mu.
+func (l *SpendLimiter) rollover() {
+	month := l.now().In(l.location).Format("2006-01")
+	if month != l.month {
+		l.month = month
+		clear(l.used)
+	}
+}
+
+func spendLimitExceededError(provider string) *Error {
+	return &Error{
+		Code:       "spend_limit_exceeded",
+		Message:    fmt.Sprintf("all %s auths have reached their monthly spend limit", provider),
+		HTTPStatus: 429,
+	}
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+func TestSpendLimiterThresholds(t *testing.T) {
+	prices := NewCostRouter()
+	prices.SetPolicy(false, []ModelPrice{{Provider: "claude", ModelPattern: "claude-*", Input: 3, Output: 15}})
+	l := NewSpendLimiter(prices)
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	l.SetLimits(time.UTC, []SpendLimit{
+		{Provider: "claude", MonthlyCost: 10},
+		{Provider: "claude", AuthID: "big", MonthlyTokens: 10_000_000},
+	})
+
+	spend := func(authID string, prompt int64) {
+		l.HandleUsage(context.Background(), usage.Record{
+			Provider: "claude", Model: "claude-sonnet-4-5", AuthID: authID,
+			Usage: &ir.Usage{PromptTokens: prompt, TotalTokens: prompt},
+		})
+	}
+
+	spend("a", 2_700_000) // $8.10
+	if s, _ := l.Status("claude", "a"); s.Status != SpendWarning || l.Blocked("claude", "a") {
+		t.Fatalf("status after $8.10 = %+v", s)
+	}
+	spend("a", 700_000) // $10.20
+	if !l.Blocked("claude", "a") {
+		t.Fatal("expected auth over its cost ceiling to be blocked")
+	}
+
+	spend("big", 4_000_000) // $12 but the auth-specific token rule applies
+	if s, _ := l.Status("claude", "big"); s.Status != SpendOK || s.TokenLimit != 10_000_000 {
+		t.Fatalf("auth-specific status = %+v", s)
+	}
+	if _, ok := l.Status("gemini", "g"); ok {
+		t.Fatal("expected no limit for unmatched provider")
+	}
+
+	now = now.AddDate(0, 1, 0)
+	if l.Blocked("claude", "a") {
+		t.Fatal("expected block to reset in the next month")
+	}
+}
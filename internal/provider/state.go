@@ -195,6 +195,9 @@ func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Durati
 	case CategoryTransient:
 		auth.StatusMessage = "transient upstream error"
 		auth.NextRetryAfter = now.Add(1 * time.Minute)
+	case CategoryOverloaded:
+		auth.StatusMessage = "upstream overloaded"
+		auth.NextRetryAfter = now.Add(overloadCooldown(retryAfter))
 	case CategoryUserError:
 		// User errors should not affect auth state significantly
 		auth.StatusMessage = "user_request_error"
@@ -14,6 +14,12 @@ func (s *ClaudeStrategy) Score(auth *Auth, state *AuthQuotaState, config *Provid
 	var priority int64
 	priority += state.ActiveRequests.Load() * 1000
 
+	// Prefer the utilization Anthropic reports; otherwise estimate it from the
+	// tokens used so far in the window.
+	if real := state.GetRealQuota(); real != nil && time.Since(real.FetchedAt) < realQuotaFreshness {
+		priority += int64((1 - real.RemainingFraction) * 500)
+		return priority
+	}
 	limit := state.LearnedLimit.Load()
 	if limit <= 0 && config != nil {
 		limit = config.EstimatedLimit
@@ -54,6 +60,16 @@ func (s *ClaudeStrategy) OnQuotaHit(state *AuthQuotaState, cooldown *time.Durati
 	}
 
 	state.TotalTokensUsed.Store(0)
+
+	// The messages sent in the current window when it ran out approximate the
+	// account's per-window allowance.
+	messages := state.WindowMessages.Load()
+	for {
+		current := state.LearnedMessageLimit.Load()
+		if messages <= current || state.LearnedMessageLimit.CompareAndSwap(current, messages) {
+			break
+		}
+	}
 }
 
 func (s *ClaudeStrategy) RecordUsage(state *AuthQuotaState, tokens int64) {
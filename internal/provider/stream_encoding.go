@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/nghyane/llm-mux/internal/sseutil"
+)
+
+// streamEncoding repairs streamed chunks that would break strict JSON parsers
+// and counts repairs per provider.
+type streamEncoding struct {
+	normalize atomic.Value // string
+	repairs   sync.Map     // provider -> *atomic.Int64
+}
+
+// sanitize returns chunk with invalid UTF-8 and unpaired surrogate escapes
+// replaced, normalized when configured.
+func (e *streamEncoding) sanitize(provider string, chunk []byte) []byte {
+	form, _ := e.normalize.Load().(string)
+	out, repaired := sseutil.SanitizeEncoding(chunk, form)
+	if repaired {
+		counter, _ := e.repairs.LoadOrStore(provider, new(atomic.Int64))
+		counter.(*atomic.Int64).Add(1)
+	}
+	return out
+}
+
+// SetStreamNormalization sets the Unicode normalization form ("nfc", "nfkc")
+// applied to streamed chunks. Empty disables normalization; invalid UTF-8 is
+// always repaired.
+func (m *Manager) SetStreamNormalization(form string) {
+	if m == nil {
+		return
+	}
+	m.encoding.normalize.Store(form)
+}
+
+// EncodingRepairs returns, per provider, how many streamed chunks contained
+// invalid UTF-8 or unpaired surrogates that were replaced.
+func (m *Manager) EncodingRepairs() map[string]int64 {
+	out := make(map[string]int64)
+	if m == nil {
+		return out
+	}
+	m.encoding.repairs.Range(func(k, v any) bool {
+		out[k.(string)] = v.(*atomic.Int64).Load()
+		return true
+	})
+	return out
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// upstreamOverloads counts overloaded responses per provider.
+type upstreamOverloads struct {
+	counts sync.Map // provider -> *atomic.Int64
+}
+
+func (o *upstreamOverloads) record(result Result) {
+	if result.Error == nil || (result.Error.HTTPStatus != StatusOverloaded && result.Error.ErrCategory != CategoryOverloaded) {
+		return
+	}
+	counter, _ := o.counts.LoadOrStore(result.Provider, new(atomic.Int64))
+	counter.(*atomic.Int64).Add(1)
+}
+
+// UpstreamOverloads returns, per provider, how many requests the upstream
+// rejected as overloaded (Anthropic's 529 overloaded_error).
+func (m *Manager) UpstreamOverloads() map[string]int64 {
+	out := make(map[string]int64)
+	if m == nil {
+		return out
+	}
+	m.overloads.counts.Range(func(k, v any) bool {
+		out[k.(string)] = v.(*atomic.Int64).Load()
+		return true
+	})
+	return out
+}
+
+// isUpstreamOverload reports whether err is an upstream overload. The
+// provider's other auths share the overloaded capacity, so executions fall
+// back to the next provider instead of trying each of them.
+func isUpstreamOverload(err error) bool {
+	var cp CategoryProvider
+	if errors.As(err, &cp) && cp != nil {
+		return cp.Category() == CategoryOverloaded
+	}
+	return statusCodeFromError(err) == StatusOverloaded
+}
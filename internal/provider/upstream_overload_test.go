@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCategorizeOverloaded(t *testing.T) {
+	tests := []struct {
+		status int
+		msg    string
+		want   ErrorCategory
+	}{
+		{StatusOverloaded, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`, CategoryOverloaded},
+		{StatusOverloaded, "", CategoryOverloaded},
+		{http.StatusOK, "overloaded_error: Overloaded", CategoryOverloaded},
+		{http.StatusTooManyRequests, `{"error":{"type":"rate_limit_error"}}`, CategoryQuotaError},
+		{http.StatusServiceUnavailable, "", CategoryTransient},
+	}
+	for _, tt := range tests {
+		if got := CategorizeError(tt.status, tt.msg); got != tt.want {
+			t.Errorf("CategorizeError(%d, %q) = %v, want %v", tt.status, tt.msg, got, tt.want)
+		}
+	}
+	if !CategoryOverloaded.ShouldFallback() {
+		t.Error("overloaded errors should fall back")
+	}
+}
+
+func TestOverloadCooldown(t *testing.T) {
+	d := func(v time.Duration) *time.Duration { return &v }
+	tests := []struct {
+		retryAfter *time.Duration
+		want       time.Duration
+	}{
+		{nil, overloadCooldownDefault},
+		{d(0), overloadCooldownDefault},
+		{d(2 * time.Second), 2 * time.Second},
+		{d(10 * time.Minute), overloadCooldownMax},
+	}
+	for _, tt := range tests {
+		if got := overloadCooldown(tt.retryAfter); got != tt.want {
+			t.Errorf("overloadCooldown(%v) = %v, want %v", tt.retryAfter, got, tt.want)
+		}
+	}
+	if overloadCooldownMax >= quotaBackoffMax {
+		t.Error("overload cooldown should be shorter than quota backoff")
+	}
+}
+
+func TestUpstreamOverloads(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	defer manager.Stop()
+	ctx := context.Background()
+
+	manager.MarkResult(ctx, Result{AuthID: "a1", Provider: "claude", Model: "m", Error: &Error{HTTPStatus: StatusOverloaded}})
+	manager.MarkResult(ctx, Result{AuthID: "a2", Provider: "claude", Model: "m", Error: &Error{ErrCategory: CategoryOverloaded}})
+	manager.MarkResult(ctx, Result{AuthID: "a1", Provider: "claude", Model: "m", Error: &Error{HTTPStatus: http.StatusTooManyRequests}})
+	manager.MarkResult(ctx, Result{AuthID: "a1", Provider: "claude", Model: "m", Success: true})
+
+	if got := manager.UpstreamOverloads(); len(got) != 1 || got["claude"] != 2 {
+		t.Errorf("UpstreamOverloads() = %v, want claude: 2", got)
+	}
+}
@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ModelChanges describes how the models of a provider differ between two
+// fetches from upstream.
+type ModelChanges struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	// Changed lists the models whose token limits changed, with the change.
+	Changed []string `json:"changed"`
+}
+
+// Empty reports whether nothing changed.
+func (c ModelChanges) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// DiffModels compares two model lists by ID and token limits. Each result
+// list is sorted.
+func DiffModels(before, after []*ModelInfo) ModelChanges {
+	index := func(models []*ModelInfo) map[string]*ModelInfo {
+		out := make(map[string]*ModelInfo, len(models))
+		for _, m := range models {
+			if m != nil && m.ID != "" {
+				if _, ok := out[m.ID]; !ok {
+					out[m.ID] = m
+				}
+			}
+		}
+		return out
+	}
+	old, cur := index(before), index(after)
+	out := ModelChanges{Added: []string{}, Removed: []string{}, Changed: []string{}}
+	for id, m := range cur {
+		prev, ok := old[id]
+		if !ok {
+			out.Added = append(out.Added, id)
+			continue
+		}
+		if change := limitChange(prev, m); change != "" {
+			out.Changed = append(out.Changed, id+" ("+change+")")
+		}
+	}
+	for id := range old {
+		if _, ok := cur[id]; !ok {
+			out.Removed = append(out.Removed, id)
+		}
+	}
+	sort.Strings(out.Added)
+	sort.Strings(out.Removed)
+	sort.Strings(out.Changed)
+	return out
+}
+
+// limitChange describes the token limit differences between a and b.
+func limitChange(a, b *ModelInfo) string {
+	var change string
+	add := func(name string, from, to int) {
+		if from == to {
+			return
+		}
+		if change != "" {
+			change += ", "
+		}
+		change += fmt.Sprintf("%s %d -> %d", name, from, to)
+	}
+	add("context_length", a.ContextLength, b.ContextLength)
+	add("input_token_limit", a.InputTokenLimit, b.InputTokenLimit)
+	add("output_token_limit", a.OutputTokenLimit, b.OutputTokenLimit)
+	add("max_completion_tokens", a.MaxCompletionTokens, b.MaxCompletionTokens)
+	return change
+}
@@ -0,0 +1,30 @@
+package registry
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffModels(t *testing.T) {
+	before := []*ModelInfo{
+		{ID: "gemini-2.5-pro", InputTokenLimit: 1048576, OutputTokenLimit: 65536},
+		{ID: "gemini-2.0-flash"},
+	}
+	after := []*ModelInfo{
+		{ID: "gemini-2.5-pro", InputTokenLimit: 1048576, OutputTokenLimit: 32768},
+		{ID: "gemini-3-pro-preview"},
+	}
+	got := DiffModels(before, after)
+	if !slices.Equal(got.Added, []string{"gemini-3-pro-preview"}) {
+		t.Errorf("added = %v", got.Added)
+	}
+	if !slices.Equal(got.Removed, []string{"gemini-2.0-flash"}) {
+		t.Errorf("removed = %v", got.Removed)
+	}
+	if !slices.Equal(got.Changed, []string{"gemini-2.5-pro (output_token_limit 65536 -> 32768)"}) {
+		t.Errorf("changed = %v", got.Changed)
+	}
+	if !DiffModels(before, before).Empty() {
+		t.Error("identical lists reported changes")
+	}
+}
@@ -174,6 +174,28 @@ func (r *ModelRegistry) GetAvailableProviders() []string {
 	return providers
 }
 
+// GetProviderModels returns the sorted IDs of visible models currently served by provider.
+func (r *ModelRegistry) GetProviderModels(provider string) []string {
+	s := r.snapshot()
+
+	seen := make(map[string]bool)
+	for _, reg := range s.models {
+		if reg == nil || reg.Count == 0 || reg.Info == nil || reg.Info.Hidden {
+			continue
+		}
+		if reg.Providers[provider] > 0 {
+			seen[reg.Info.ID] = true
+		}
+	}
+
+	models := make([]string, 0, len(seen))
+	for id := range seen {
+		models = append(models, id)
+	}
+	sort.Strings(models)
+	return models
+}
+
 func (r *ModelRegistry) GetFirstAvailableModel(handlerType string) (string, error) {
 	s := r.snapshot()
 
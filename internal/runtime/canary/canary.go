@@ -0,0 +1,293 @@
+// Package canary sends operator-defined test prompts through the normal
+// request path on a schedule and checks the answers, so upstream quality
+// regressions such as empty responses are noticed without client reports.
+package canary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	// DefaultInterval is the time between runs of a check.
+	DefaultInterval = 15 * time.Minute
+	// DefaultTimeout bounds each run.
+	DefaultTimeout = 60 * time.Second
+	// DefaultMaxTokens bounds the answer.
+	DefaultMaxTokens = 256
+
+	maxAnswer = 512
+)
+
+// Check is a test prompt and the answer expected for it.
+type Check struct {
+	Name  string
+	Model string
+	// Providers lists the providers checked; empty checks every provider
+	// serving Model at run time.
+	Providers []string
+	Prompt    string
+	// Expect is a substring the answer must contain, case-insensitively.
+	Expect string
+	// Schema is a JSON schema the answer must satisfy; see validateSchema.
+	Schema    map[string]any
+	MaxTokens int
+	Interval  time.Duration
+	Timeout   time.Duration
+}
+
+// Env connects a runner to the request path.
+type Env struct {
+	// Execute sends an OpenAI chat completion payload for model to
+	// provider and returns the OpenAI response body.
+	Execute func(ctx context.Context, provider, model string, payload []byte) ([]byte, error)
+	// Providers returns the providers serving model.
+	Providers func(model string) []string
+	// Report receives the outcome of every run; err is nil when it passed.
+	Report func(check, provider, model string, err error)
+}
+
+// Result is the latest run of a check on a provider.
+type Result struct {
+	Canary    string    `json:"canary"`
+	Provider  string    `json:"provider"`
+	Model     string    `json:"model"`
+	OK        bool      `json:"ok"`
+	LastRun   time.Time `json:"last_run"`
+	LatencyMs int64     `json:"latency_ms"`
+	// FailingSince is when the current failure streak started.
+	FailingSince *time.Time `json:"failing_since,omitempty"`
+	Answer       string     `json:"answer,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// Runner runs checks in the background.
+type Runner struct {
+	mu      sync.Mutex
+	env     Env
+	checks  []Check
+	results map[string]map[string]*Result // check -> provider
+	cancel  context.CancelFunc
+}
+
+var defaultRunner = &Runner{}
+
+// Configure replaces the checks of the default runner.
+func Configure(checks []Check, env Env) { defaultRunner.Configure(checks, env) }
+
+// Stop stops the default runner.
+func Stop() { defaultRunner.Stop() }
+
+// Status returns the results of the default runner.
+func Status() []Result { return defaultRunner.Status() }
+
+// Configure replaces the checks and restarts the background loops. Every
+// check runs immediately and then every interval. Results of checks kept
+// by name survive.
+func (r *Runner) Configure(checks []Check, env Env) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+	r.env = env
+	r.checks = nil
+	results := make(map[string]map[string]*Result)
+	for _, c := range checks {
+		if c.Model == "" || c.Prompt == "" {
+			continue
+		}
+		if c.Name == "" {
+			c.Name = c.Model
+		}
+		if _, dup := results[c.Name]; dup {
+			log.Warnf("canary: duplicate name %q, skipping", c.Name)
+			continue
+		}
+		if c.Interval <= 0 {
+			c.Interval = DefaultInterval
+		}
+		if c.Timeout <= 0 {
+			c.Timeout = DefaultTimeout
+		}
+		if c.MaxTokens <= 0 {
+			c.MaxTokens = DefaultMaxTokens
+		}
+		r.checks = append(r.checks, c)
+		results[c.Name] = r.results[c.Name]
+		if results[c.Name] == nil {
+			results[c.Name] = make(map[string]*Result)
+		}
+	}
+	r.results = results
+	if len(r.checks) == 0 || env.Execute == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	for _, c := range r.checks {
+		go r.loop(ctx, c)
+	}
+}
+
+// Stop stops the background loops.
+func (r *Runner) Stop() { r.Configure(nil, Env{}) }
+
+func (r *Runner) loop(ctx context.Context, c Check) {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		r.runOnce(ctx, c)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce runs c on each of its providers in turn.
+func (r *Runner) runOnce(ctx context.Context, c Check) {
+	r.mu.Lock()
+	env := r.env
+	r.mu.Unlock()
+	providers := c.Providers
+	if len(providers) == 0 && env.Providers != nil {
+		providers = env.Providers(c.Model)
+	}
+	r.forget(c, providers, env)
+	for _, p := range providers {
+		if ctx.Err() != nil {
+			return
+		}
+		r.run(ctx, c, p, env)
+	}
+}
+
+// forget drops the results of providers no longer serving the model and
+// clears their reported failures.
+func (r *Runner) forget(c Check, providers []string, env Env) {
+	r.mu.Lock()
+	var gone []string
+	for p := range r.results[c.Name] {
+		if !slices.Contains(providers, p) {
+			delete(r.results[c.Name], p)
+			gone = append(gone, p)
+		}
+	}
+	r.mu.Unlock()
+	if env.Report != nil {
+		for _, p := range gone {
+			env.Report(c.Name, p, c.Model, nil)
+		}
+	}
+}
+
+func (r *Runner) run(ctx context.Context, c Check, provider string, env Env) {
+	payload := []byte(`{"model":"","stream":false,"max_tokens":0,"messages":[{"role":"user","content":""}]}`)
+	payload, _ = sjson.SetBytes(payload, "model", c.Model)
+	payload, _ = sjson.SetBytes(payload, "max_tokens", c.MaxTokens)
+	payload, _ = sjson.SetBytes(payload, "messages.0.content", c.Prompt)
+
+	runCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+	start := time.Now()
+	resp, err := env.Execute(runCtx, provider, c.Model, payload)
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+	latency := time.Since(start)
+	var answer string
+	if err == nil {
+		answer = gjson.GetBytes(resp, "choices.0.message.content").String()
+		err = c.verify(answer)
+	}
+	if env.Report != nil {
+		env.Report(c.Name, provider, c.Model, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	byProvider, ok := r.results[c.Name]
+	if !ok {
+		return // reconfigured meanwhile
+	}
+	res := byProvider[provider]
+	if res == nil {
+		res = &Result{Canary: c.Name, Provider: provider, Model: c.Model, OK: true}
+		byProvider[provider] = res
+	}
+	wasOK := res.OK
+	res.LastRun = start
+	res.LatencyMs = latency.Milliseconds()
+	res.Answer = truncate(answer, maxAnswer)
+	if err != nil {
+		if wasOK {
+			log.Warnf("canary %s failed on %s (model %s): %v", c.Name, provider, c.Model, err)
+			res.FailingSince = &start
+		}
+		res.OK = false
+		res.Error = err.Error()
+		return
+	}
+	if !wasOK {
+		log.Infof("canary %s passes again on %s (model %s)", c.Name, provider, c.Model)
+	}
+	res.OK = true
+	res.Error = ""
+	res.FailingSince = nil
+}
+
+// verify checks answer against the expectations of c.
+func (c Check) verify(answer string) error {
+	if strings.TrimSpace(answer) == "" {
+		return errors.New("empty response")
+	}
+	if c.Expect != "" && !strings.Contains(strings.ToLower(answer), strings.ToLower(c.Expect)) {
+		return fmt.Errorf("answer does not contain %q", c.Expect)
+	}
+	if c.Schema != nil {
+		if err := validateAnswer(c.Schema, answer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Status returns the latest result of every check on every provider,
+// ordered by check and provider.
+func (r *Runner) Status() []Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Result, 0, len(r.results))
+	for _, byProvider := range r.results {
+		for _, res := range byProvider {
+			out = append(out, *res)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Canary != out[j].Canary {
+			return out[i].Canary < out[j].Canary
+		}
+		return out[i].Provider < out[j].Provider
+	})
+	return out
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
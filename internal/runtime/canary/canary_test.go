@@ -0,0 +1,89 @@
+package canary
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunnerReportsFailuresPerProvider(t *testing.T) {
+	answers := map[string]string{
+		"good":  `{"choices":[{"message":{"content":"Paris."}}]}`,
+		"empty": `{"choices":[{"message":{"content":""}}]}`,
+	}
+	var mu sync.Mutex
+	reports := make(map[string]error)
+	env := Env{
+		Execute: func(_ context.Context, provider, _ string, payload []byte) ([]byte, error) {
+			if !strings.Contains(string(payload), `"content":"Capital of France?"`) {
+				return nil, errors.New("unexpected payload " + string(payload))
+			}
+			return []byte(answers[provider]), nil
+		},
+		Providers: func(string) []string { return []string{"empty", "good"} },
+		Report: func(_, provider, _ string, err error) {
+			mu.Lock()
+			reports[provider] = err
+			mu.Unlock()
+		},
+	}
+
+	var r Runner
+	r.Configure([]Check{{Model: "m", Prompt: "Capital of France?", Expect: "paris"}}, env)
+	defer r.Stop()
+
+	var status []Result
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if status = r.Status(); len(status) == 2 {
+			break
+		}
+	}
+	if len(status) != 2 {
+		t.Fatalf("status = %+v", status)
+	}
+	empty, good := status[0], status[1]
+	if empty.Canary != "m" || empty.OK || empty.Error != "empty response" || empty.FailingSince == nil {
+		t.Errorf("empty provider result = %+v", empty)
+	}
+	if !good.OK || good.Answer != "Paris." || good.FailingSince != nil {
+		t.Errorf("good provider result = %+v", good)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if reports["empty"] == nil || reports["good"] != nil {
+		t.Errorf("reports = %v", reports)
+	}
+}
+
+func TestValidateAnswer(t *testing.T) {
+	schema := map[string]any{
+		"type":     "object",
+		"required": []any{"status", "items"},
+		"properties": map[string]any{
+			"status": map[string]any{"enum": []any{"ok"}},
+			"items":  map[string]any{"type": "array", "items": map[string]any{"type": "integer"}},
+		},
+	}
+	cases := []struct {
+		answer string
+		err    string
+	}{
+		{"```json\n{\"status\": \"ok\", \"items\": [1, 2]}\n```", ""},
+		{`{"status": "ok"}`, `missing required property "items"`},
+		{`{"status": "down", "items": []}`, "$.status: value not in enum"},
+		{`{"status": "ok", "items": [1.5]}`, "$.items[0]: expected integer"},
+		{`not json`, "answer is not JSON"},
+	}
+	for _, tc := range cases {
+		err := validateAnswer(schema, tc.answer)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("%q: unexpected error %v", tc.answer, err)
+		case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+			t.Errorf("%q: error = %v, want %q", tc.answer, err, tc.err)
+		}
+	}
+}
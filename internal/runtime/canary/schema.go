@@ -0,0 +1,118 @@
+package canary
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/json"
+)
+
+// validateAnswer parses answer, optionally wrapped in a Markdown code
+// fence, as JSON and validates it against schema.
+func validateAnswer(schema map[string]any, answer string) error {
+	text := strings.TrimSpace(answer)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```")
+		if nl := strings.IndexByte(text, '\n'); nl >= 0 {
+			text = text[nl+1:] // language tag
+		}
+		text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+	}
+	var doc any
+	if err := json.Unmarshal([]byte(text), &doc); err != nil {
+		return fmt.Errorf("answer is not JSON: %w", err)
+	}
+	return validateSchema(schema, doc, "$")
+}
+
+// validateSchema checks v against the type, properties, required, items
+// and enum keywords of schema. Other keywords are ignored.
+func validateSchema(schema map[string]any, v any, path string) error {
+	if want, ok := schema["type"].(string); ok && !hasType(v, want) {
+		return fmt.Errorf("%s: expected %s, got %s", path, want, typeName(v))
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return equalJSON(e, v) }) {
+		return fmt.Errorf("%s: value not in enum", path)
+	}
+	switch val := v.(type) {
+	case map[string]any:
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				key, _ := name.(string)
+				if _, present := val[key]; !present {
+					return fmt.Errorf("%s: missing required property %q", path, key)
+				}
+			}
+		}
+		if props, ok := schema["properties"].(map[string]any); ok {
+			for key, sub := range props {
+				subSchema, ok := sub.(map[string]any)
+				child, present := val[key]
+				if !ok || !present {
+					continue
+				}
+				if err := validateSchema(subSchema, child, path+"."+key); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range val {
+				if err := validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func hasType(v any, want string) bool {
+	switch want {
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	default:
+		return typeName(v) == want
+	}
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// equalJSON compares an enum entry from YAML, where numbers may decode as
+// ints, with a decoded JSON value.
+func equalJSON(a, b any) bool {
+	switch n := a.(type) {
+	case int:
+		a = float64(n)
+	case int64:
+		a = float64(n)
+	case uint64:
+		a = float64(n)
+	}
+	return reflect.DeepEqual(a, b)
+}
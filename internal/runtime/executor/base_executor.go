@@ -39,8 +39,8 @@ func (b *BaseExecutor) NewUsageReporter(ctx context.Context, prov, model string,
 	return NewUsageReporter(ctx, prov, model, auth)
 }
 
-func (b *BaseExecutor) ApplyPayloadConfig(model string, payload []byte) []byte {
-	return sseutil.ApplyPayloadConfig(b.Cfg, model, payload)
+func (b *BaseExecutor) ApplyPayloadConfig(ctx context.Context, model string, payload []byte) []byte {
+	return sseutil.ApplyPayloadConfig(b.Cfg, model, provider.ClientAPIKey(ctx), payload)
 }
 
 func (b *BaseExecutor) RefreshNoOp(_ context.Context, auth *provider.Auth) (*provider.Auth, error) {
@@ -0,0 +1,56 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// withCancelClose wraps a custom client transport so each upstream response
+// body is closed as soon as its request context is cancelled. The standard
+// transport already aborts reads on cancellation, but custom round trippers,
+// such as the ones carried by ctx, may not, which would leave a stream
+// reading from the upstream after the client is gone.
+func withCancelClose(client *http.Client) *http.Client {
+	if _, ok := client.Transport.(*http.Transport); ok || client.Transport == nil {
+		return client
+	}
+	return wrapTransport(client, func(next http.RoundTripper) http.RoundTripper {
+		return &cancelCloseTransport{next: next}
+	})
+}
+
+type cancelCloseTransport struct {
+	next http.RoundTripper
+}
+
+func (t *cancelCloseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.Body == nil || req.Context().Done() == nil {
+		return resp, err
+	}
+	body := &cancelCloseBody{ReadCloser: resp.Body}
+	body.stop = context.AfterFunc(req.Context(), func() { _ = body.close() })
+	resp.Body = body
+	return resp, nil
+}
+
+// cancelCloseBody closes the wrapped body once, either on cancellation or
+// when the executor closes it.
+type cancelCloseBody struct {
+	io.ReadCloser
+	stop func() bool
+	once sync.Once
+	err  error
+}
+
+func (b *cancelCloseBody) Close() error {
+	b.stop()
+	return b.close()
+}
+
+func (b *cancelCloseBody) close() error {
+	b.once.Do(func() { b.err = b.ReadCloser.Close() })
+	return b.err
+}
@@ -0,0 +1,58 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// blockingBody blocks reads until it is closed, like a transport that
+// ignores the request context.
+type blockingBody struct {
+	closed chan struct{}
+}
+
+func (b *blockingBody) Read([]byte) (int, error) {
+	<-b.closed
+	return 0, io.ErrClosedPipe
+}
+
+func (b *blockingBody) Close() error {
+	close(b.closed)
+	return nil
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestCancelCloseUnblocksRead(t *testing.T) {
+	body := &blockingBody{closed: make(chan struct{})}
+	client := withCancelClose(&http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: body}, nil
+	})})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://upstream.test", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := make(chan struct{})
+	go func() {
+		_, _ = resp.Body.Read(make([]byte, 1))
+		close(read)
+	}()
+	cancel()
+	select {
+	case <-read:
+	case <-time.After(time.Second):
+		t.Fatal("read still blocked after cancellation")
+	}
+	// Closing again after the cancellation closed the body is a no-op.
+	if err := resp.Body.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/transport"
+)
+
+// dialerTransportEntry caches the transport built for one dialer config entry.
+// transport is nil when the entry is invalid, so the warning is logged once.
+type dialerTransportEntry struct {
+	cfg       config.DialerConfig
+	transport *http.Transport
+}
+
+var (
+	dialerTransports   = make(map[string]*dialerTransportEntry)
+	dialerTransportsMu sync.Mutex
+)
+
+// providerDialerTransport returns the transport for providerName's dialer
+// settings, or nil when no dialer entry applies. Transports are rebuilt when
+// the entry changes on config reload.
+func providerDialerTransport(cfg *config.Config, providerName string) *http.Transport {
+	if cfg == nil || len(cfg.Dialer) == 0 {
+		return nil
+	}
+	key := providerName
+	dc, ok := cfg.Dialer[key]
+	if !ok {
+		key = "*"
+		if dc, ok = cfg.Dialer[key]; !ok {
+			return nil
+		}
+	}
+
+	dialerTransportsMu.Lock()
+	defer dialerTransportsMu.Unlock()
+	if cached := dialerTransports[key]; cached != nil {
+		if reflect.DeepEqual(cached.cfg, dc) {
+			return cached.transport
+		}
+		if cached.transport != nil {
+			cached.transport.CloseIdleConnections()
+		}
+	}
+
+	entry := &dialerTransportEntry{cfg: dc}
+	if opts, err := dialerOptions(dc); err != nil {
+		log.Warnf("dialer %q: %v, using default dialing", key, err)
+	} else {
+		entry.transport = baseTransport()
+		entry.transport.DialContext = opts.DialContext(newDialer())
+		transport.ConfigureTLSSessions(entry.transport.TLSClientConfig, key, dc.TLSSessionCacheSize)
+	}
+	dialerTransports[key] = entry
+	return entry.transport
+}
+
+func dialerOptions(dc config.DialerConfig) (transport.DialerOptions, error) {
+	opts := transport.DialerOptions{
+		IPPreference: strings.ToLower(strings.TrimSpace(dc.IPPreference)),
+	}
+	if d := strings.TrimSpace(dc.HappyEyeballsDelay); d != "" {
+		delay, err := time.ParseDuration(d)
+		if err != nil {
+			return opts, fmt.Errorf("invalid happy-eyeballs-delay %q", dc.HappyEyeballsDelay)
+		}
+		opts.FallbackDelay = delay
+	}
+	if t := strings.TrimSpace(dc.DNSCacheTTL); t != "" {
+		ttl, err := time.ParseDuration(t)
+		if err != nil || ttl < 0 {
+			return opts, fmt.Errorf("invalid dns-cache-ttl %q", dc.DNSCacheTTL)
+		}
+		if ttl > 0 {
+			opts.DNSCache = transport.NewDNSCache(ttl)
+		}
+	}
+	if len(dc.Hosts) > 0 {
+		opts.Hosts = make(map[string]string, len(dc.Hosts))
+		for host, ip := range dc.Hosts {
+			opts.Hosts[strings.ToLower(strings.TrimSpace(host))] = strings.TrimSpace(ip)
+		}
+	}
+	return opts, opts.Validate()
+}
@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// withHeaderPassthrough wraps the client transport to forward the client
+// request headers listed in header-passthrough for the auth's provider.
+// Headers the executor already set are kept, and unlisted client headers
+// are never forwarded.
+func withHeaderPassthrough(ctx context.Context, cfg *config.Config, auth *provider.Auth, client *http.Client) *http.Client {
+	if cfg == nil || auth == nil || len(cfg.HeaderPassthrough) == 0 {
+		return client
+	}
+	names := cfg.HeaderPassthrough[auth.Provider]
+	if len(names) == 0 {
+		return client
+	}
+	ginCtx, _ := ctx.Value("gin").(*gin.Context)
+	if ginCtx == nil || ginCtx.Request == nil {
+		return client
+	}
+	forward := make(http.Header, len(names))
+	for _, name := range names {
+		if values := ginCtx.Request.Header.Values(name); len(values) > 0 {
+			forward[name] = values
+		}
+	}
+	if len(forward) == 0 {
+		return client
+	}
+	return wrapTransport(client, func(next http.RoundTripper) http.RoundTripper {
+		return &passthroughTransport{next: next, headers: forward}
+	})
+}
+
+type passthroughTransport struct {
+	next    http.RoundTripper
+	headers http.Header
+}
+
+func (t *passthroughTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cloned := false
+	for name, values := range t.headers {
+		if req.Header.Get(name) != "" {
+			continue
+		}
+		if !cloned {
+			req = req.Clone(req.Context())
+			cloned = true
+		}
+		req.Header[name] = values
+	}
+	return t.next.RoundTrip(req)
+}
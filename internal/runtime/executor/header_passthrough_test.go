@@ -0,0 +1,68 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestHeaderPassthrough(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	inbound := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	inbound.Header.Set("Anthropic-Beta", "context-1m-2025-08-07")
+	inbound.Header.Set("Openai-Organization", "org-1")
+	inbound.Header.Set("X-Internal-Trace", "secret")
+	ginCtx := &gin.Context{Request: inbound}
+	ctx := context.WithValue(context.Background(), "gin", ginCtx)
+
+	passthrough, err := config.NormalizeHeaderPassthrough(map[string][]string{
+		"Claude": {"anthropic-beta", "openai-organization"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{HeaderPassthrough: passthrough}
+	client := NewProxyAwareHTTPClient(ctx, cfg, &provider.Auth{Provider: "claude"}, 0)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, nil)
+	req.Header.Set("Openai-Organization", "set-by-executor")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if v := got.Get("Anthropic-Beta"); v != "context-1m-2025-08-07" {
+		t.Errorf("Anthropic-Beta = %q, want forwarded", v)
+	}
+	if v := got.Get("Openai-Organization"); v != "set-by-executor" {
+		t.Errorf("Openai-Organization = %q, want the executor's value", v)
+	}
+	if v := got.Get("X-Internal-Trace"); v != "" {
+		t.Errorf("unlisted header forwarded: %q", v)
+	}
+	if req.Header.Get("Anthropic-Beta") != "" {
+		t.Error("caller's request was modified")
+	}
+
+	other := NewProxyAwareHTTPClient(ctx, cfg, &provider.Auth{Provider: "gemini"}, 0)
+	if other.Transport != SharedTransport {
+		t.Error("expected shared transport for a provider without passthrough")
+	}
+}
+
+func TestNormalizeHeaderPassthroughRejectsCredentials(t *testing.T) {
+	if _, err := config.NormalizeHeaderPassthrough(map[string][]string{"openai": {"authorization"}}); err == nil {
+		t.Error("expected Authorization to be rejected")
+	}
+}
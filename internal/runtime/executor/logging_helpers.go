@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"html"
 	"strings"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
 )
 
 func summarizeErrorBody(contentType string, body []byte) string {
@@ -20,7 +22,7 @@ func summarizeErrorBody(contentType string, body []byte) string {
 		}
 		return "[html body omitted]"
 	}
-	return string(body)
+	return log.Redact(string(body))
 }
 
 // SummarizeErrorBody is an exported alias for summarizeErrorBody.
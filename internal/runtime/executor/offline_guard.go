@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// withOfflineGuard wraps the client transport for offline requests, removing
+// any external retrieval feature from the upstream payload. Client requests
+// carrying such features are rejected earlier; this catches the ones added on
+// the way, such as by payload rules.
+func withOfflineGuard(ctx context.Context, client *http.Client) *http.Client {
+	if !provider.IsOffline(ctx) {
+		return client
+	}
+	return wrapTransport(client, func(next http.RoundTripper) http.RoundTripper {
+		return &offlineTransport{next: next}
+	})
+}
+
+type offlineTransport struct {
+	next http.RoundTripper
+}
+
+func (t *offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return t.next.RoundTrip(req)
+	}
+	data, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	gzipped := req.Header.Get("Content-Encoding") == "gzip"
+	payload := data
+	if gzipped {
+		zr, errGzip := gzip.NewReader(bytes.NewReader(data))
+		if errGzip != nil {
+			return nil, errGzip
+		}
+		if payload, err = io.ReadAll(zr); err != nil {
+			return nil, err
+		}
+	}
+
+	req = req.Clone(req.Context())
+	stripped, removed := provider.StripExternalRetrieval(payload)
+	if len(removed) == 0 {
+		stripped = data
+	} else {
+		log.Warnf("offline mode: removed %s from upstream request to %s", strings.Join(removed, ", "), req.URL.Host)
+		if gzipped {
+			// Sent uncompressed rather than re-encoded.
+			req.Header.Del("Content-Encoding")
+		}
+	}
+	req.Body = io.NopCloser(bytes.NewReader(stripped))
+	req.ContentLength = int64(len(stripped))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(stripped)), nil
+	}
+	return t.next.RoundTrip(req)
+}
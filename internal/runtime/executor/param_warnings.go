@@ -0,0 +1,37 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/tidwall/gjson"
+)
+
+// PenaltyAndSeedParams are OpenAI sampling parameters that not every upstream API accepts.
+var PenaltyAndSeedParams = []string{"frequency_penalty", "presence_penalty", "seed"}
+
+// WarnDroppedParams adds a Warning response header when the client request sets
+// parameters that providerName cannot honor. The translator drops them; the header
+// tells the client the request did not run exactly as specified.
+func WarnDroppedParams(ctx context.Context, providerName string, payload []byte, params ...string) {
+	var dropped []string
+	for _, p := range params {
+		if v := gjson.GetBytes(payload, p); v.Exists() && v.Type != gjson.Null {
+			dropped = append(dropped, p)
+		}
+	}
+	if len(dropped) == 0 {
+		return
+	}
+	list := strings.Join(dropped, ", ")
+	log.Debugf("%s: dropping unsupported parameters: %s", providerName, list)
+
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return
+	}
+	ginCtx.Writer.Header().Add("Warning", fmt.Sprintf(`299 llm-mux "%s does not support %s; ignored"`, providerName, list))
+}
@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// withPayloadSizes wraps the client transport when ctx carries payload
+// sizes, counting the bytes of each upstream request body as sent and of
+// its response body as read.
+func withPayloadSizes(ctx context.Context, client *http.Client) *http.Client {
+	sizes := provider.PayloadSizesFrom(ctx)
+	if sizes == nil {
+		return client
+	}
+	return wrapTransport(client, func(next http.RoundTripper) http.RoundTripper {
+		return &sizeTransport{next: next, sizes: sizes}
+	})
+}
+
+type sizeTransport struct {
+	next  http.RoundTripper
+	sizes *provider.PayloadSizes
+}
+
+func (t *sizeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch {
+	case req.Body == nil || req.Body == http.NoBody:
+		t.sizes.StartRequest(0)
+	case req.ContentLength >= 0:
+		t.sizes.StartRequest(req.ContentLength)
+	default:
+		t.sizes.StartRequest(0)
+		req = req.Clone(req.Context())
+		req.Body = &countingBody{ReadCloser: req.Body, add: t.sizes.AddRequest}
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Body != nil {
+		resp.Body = &countingBody{ReadCloser: resp.Body, add: t.sizes.AddResponse}
+	}
+	return resp, nil
+}
+
+// countingBody reports the bytes read through it to add.
+type countingBody struct {
+	io.ReadCloser
+	add func(int64)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.add(int64(n))
+	}
+	return n, err
+}
@@ -43,7 +43,7 @@ func (e *AIStudioExecutor) Execute(ctx context.Context, auth *provider.Auth, req
 	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth)
 	defer reporter.TrackFailure(ctx, &err)
 
-	_, body, err := e.translateRequest(req, opts, false)
+	_, body, err := e.translateRequest(ctx, req, opts, false)
 	if err != nil {
 		return resp, err
 	}
@@ -86,7 +86,7 @@ func (e *AIStudioExecutor) ExecuteStream(ctx context.Context, auth *provider.Aut
 	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth)
 	defer reporter.TrackFailure(ctx, &err)
 
-	_, body, err := e.translateRequest(req, opts, true)
+	_, body, err := e.translateRequest(ctx, req, opts, true)
 	if err != nil {
 		return nil, err
 	}
@@ -150,6 +150,8 @@ func (e *AIStudioExecutor) ExecuteStream(ctx context.Context, auth *provider.Aut
 		}()
 
 		streamCtx := stream.NewStreamContext()
+		streamCtx.JSONMode = stream.IsJSONObjectRequest(req.Payload)
+		streamCtx.StopSequences = stream.EmulatedStopSequences(req.Payload, ir.GeminiMaxStopSequences)
 		messageID := "chatcmpl-" + req.Model
 		translator := stream.NewStreamTranslator(e.Cfg, opts.SourceFormat, opts.SourceFormat.String(), req.Model, messageID, streamCtx)
 		processor := &aistudioStreamProcessor{
@@ -243,7 +245,7 @@ func (e *AIStudioExecutor) ExecuteStream(ctx context.Context, auth *provider.Aut
 }
 
 func (e *AIStudioExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
-	_, body, err := e.translateRequest(req, opts, false)
+	_, body, err := e.translateRequest(ctx, req, opts, false)
 	if err != nil {
 		return provider.Response{}, err
 	}
@@ -319,10 +321,10 @@ func (p *aistudioStreamProcessor) ProcessDone() ([][]byte, error) {
 	return p.translator.Flush()
 }
 
-func (e *AIStudioExecutor) translateRequest(req provider.Request, opts provider.Options, isStreaming bool) ([]byte, translatedPayload, error) {
+func (e *AIStudioExecutor) translateRequest(ctx context.Context, req provider.Request, opts provider.Options, isStreaming bool) ([]byte, translatedPayload, error) {
 	from := opts.SourceFormat
 	formatGemini := provider.FromString("gemini")
-	payload, err := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, isStreaming, req.Metadata)
+	payload, err := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, isStreaming, req.Metadata)
 	if err != nil {
 		return nil, translatedPayload{}, fmt.Errorf("translate request: %w", err)
 	}
@@ -330,7 +332,7 @@ func (e *AIStudioExecutor) translateRequest(req provider.Request, opts provider.
 		payload = util.ApplyGeminiThinkingConfig(payload, budgetOverride, includeOverride)
 	}
 	payload = util.StripThinkingConfigIfUnsupported(req.Model, payload)
-	payload = e.ApplyPayloadConfig(req.Model, payload)
+	payload = e.ApplyPayloadConfig(ctx, req.Model, payload)
 	payload, _ = sjson.DeleteBytes(payload, "generationConfig.maxOutputTokens")
 	payload, _ = sjson.DeleteBytes(payload, "generationConfig.responseMimeType")
 	payload, _ = sjson.DeleteBytes(payload, "generationConfig.responseJsonSchema")
@@ -83,7 +83,7 @@ func (e *AntigravityExecutor) Execute(ctx context.Context, auth *provider.Auth,
 
 	from := opts.SourceFormat
 
-	geminiPayload, errGemini := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+	geminiPayload, errGemini := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if errGemini != nil {
 		return resp, fmt.Errorf("failed to translate request: %w", errGemini)
 	}
@@ -258,7 +258,7 @@ func (e *AntigravityExecutor) ExecuteStream(ctx context.Context, auth *provider.
 
 	from := opts.SourceFormat
 
-	translation, errTranslate := stream.TranslateToGeminiWithTokens(e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
+	translation, errTranslate := stream.TranslateToGeminiWithTokens(ctx, e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
 	if errTranslate != nil {
 		return nil, fmt.Errorf("failed to translate request: %w", errTranslate)
 	}
@@ -353,6 +353,8 @@ func (e *AntigravityExecutor) ExecuteStream(ctx context.Context, auth *provider.
 		}
 
 		streamCtx := stream.NewStreamContextWithTools(opts.OriginalRequest)
+		streamCtx.JSONMode = stream.IsJSONObjectRequest(req.Payload)
+		streamCtx.StopSequences = stream.EmulatedStopSequences(req.Payload, ir.GeminiMaxStopSequences)
 		messageID := "chatcmpl-" + req.Model
 
 		processor := stream.NewGeminiStreamProcessor(e.Cfg, from, req.Model, messageID, streamCtx)
@@ -432,7 +434,7 @@ func (e *AntigravityExecutor) CountTokens(ctx context.Context, auth *provider.Au
 	}
 
 	from := opts.SourceFormat
-	geminiPayload, errGemini := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+	geminiPayload, errGemini := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if errGemini != nil {
 		return provider.Response{}, fmt.Errorf("failed to translate request: %w", errGemini)
 	}
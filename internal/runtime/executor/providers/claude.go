@@ -93,7 +93,13 @@ func NewClaudeExecutor(cfg *config.Config) *ClaudeExecutor {
 
 func (e *ClaudeExecutor) Identifier() string { return "claude" }
 
-func (e *ClaudeExecutor) PrepareRequest(_ *http.Request, _ *provider.Auth) error { return nil }
+// PrepareRequest applies the provider's custom headers, rendering any templates.
+func (e *ClaudeExecutor) PrepareRequest(req *http.Request, auth *provider.Auth) error {
+	if auth != nil {
+		util.ApplyCustomHeadersFromAttrs(req, auth.Attributes)
+	}
+	return nil
+}
 
 func (e *ClaudeExecutor) Execute(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
 	apiKey, baseURL := claudeCreds(auth)
@@ -105,7 +111,8 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 	defer reporter.TrackFailure(ctx, &err)
 	from := opts.SourceFormat
 	isStreaming := from.String() != "claude"
-	body, err := stream.TranslateToClaude(e.Cfg, from, req.Model, req.Payload, isStreaming, req.Metadata)
+	executor.WarnDroppedParams(ctx, e.Identifier(), req.Payload, executor.PenaltyAndSeedParams...)
+	body, err := stream.TranslateToClaude(ctx, e.Cfg, from, req.Model, req.Payload, isStreaming, req.Metadata)
 	if err != nil {
 		return resp, err
 	}
@@ -119,7 +126,7 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 	if !strings.HasPrefix(modelForUpstream, "claude-3-5-haiku") {
 		body = checkSystemInstructions(body)
 	}
-	body = e.ApplyPayloadConfig(req.Model, body)
+	body = e.ApplyPayloadConfig(ctx, req.Model, body)
 
 	body = ensureMaxTokensForThinking(req.Model, body)
 
@@ -146,10 +153,11 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 		}
 		return resp, err
 	}
+	rateLimit := provider.ObserveClaudeRateLimit(ctx, auth.ID, httpResp.Header)
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
 		b, _ := io.ReadAll(httpResp.Body)
 		log.Debugf("request error, error status: %d, error body: %s", httpResp.StatusCode, executor.SummarizeErrorBody(httpResp.Header.Get("Content-Type"), b))
-		err = executor.NewStatusError(httpResp.StatusCode, string(b), nil)
+		err = executor.NewStatusError(httpResp.StatusCode, string(b), claudeRetryAfter(rateLimit, httpResp.Header))
 		if errClose := httpResp.Body.Close(); errClose != nil {
 			log.Errorf("response body close error: %v", errClose)
 		}
@@ -206,7 +214,8 @@ func (e *ClaudeExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth)
 	defer reporter.TrackFailure(ctx, &err)
 	from := opts.SourceFormat
-	body, err := stream.TranslateToClaude(e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
+	executor.WarnDroppedParams(ctx, e.Identifier(), req.Payload, executor.PenaltyAndSeedParams...)
+	body, err := stream.TranslateToClaude(ctx, e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
 	if err != nil {
 		return nil, err
 	}
@@ -220,7 +229,7 @@ func (e *ClaudeExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	if !strings.HasPrefix(modelForUpstream, "claude-3-5-haiku") {
 		body = checkSystemInstructions(body)
 	}
-	body = e.ApplyPayloadConfig(req.Model, body)
+	body = e.ApplyPayloadConfig(ctx, req.Model, body)
 
 	body = ensureMaxTokensForThinking(req.Model, body)
 
@@ -249,13 +258,14 @@ func (e *ClaudeExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 		}
 		return nil, err
 	}
+	rateLimit := provider.ObserveClaudeRateLimit(ctx, auth.ID, httpResp.Header)
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
 		b, _ := io.ReadAll(httpResp.Body)
 		log.Debugf("request error, error status: %d, error body: %s", httpResp.StatusCode, executor.SummarizeErrorBody(httpResp.Header.Get("Content-Type"), b))
 		if errClose := httpResp.Body.Close(); errClose != nil {
 			log.Errorf("response body close error: %v", errClose)
 		}
-		err = executor.NewStatusError(httpResp.StatusCode, string(b), nil)
+		err = executor.NewStatusError(httpResp.StatusCode, string(b), claudeRetryAfter(rateLimit, httpResp.Header))
 		return nil, err
 	}
 	decodedBody, err := executor.DecodeResponseBody(httpResp.Body, httpResp.Header.Get("Content-Encoding"))
@@ -266,7 +276,7 @@ func (e *ClaudeExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 		return nil, err
 	}
 
-	if from.String() == "claude" {
+	if from.String() == "claude" && !stream.HasPostProcessing(e.Cfg, req.Model) {
 		processor := &claudePassthroughProcessor{}
 
 		preprocessor := func(line []byte) ([]byte, bool) {
@@ -313,7 +323,7 @@ func (e *ClaudeExecutor) CountTokens(ctx context.Context, auth *provider.Auth, r
 
 	from := opts.SourceFormat
 	isStreaming := from.String() != "claude"
-	body, err := stream.TranslateToClaude(e.Cfg, from, req.Model, req.Payload, isStreaming, req.Metadata)
+	body, err := stream.TranslateToClaude(ctx, e.Cfg, from, req.Model, req.Payload, isStreaming, req.Metadata)
 	if err != nil {
 		return provider.Response{}, err
 	}
@@ -437,6 +447,11 @@ func ensureMaxTokensForThinking(modelName string, body []byte) []byte {
 	return EnsureClaudeMaxTokens(modelName, body)
 }
 
+// UpstreamModel implements provider.UpstreamModelResolver.
+func (e *ClaudeExecutor) UpstreamModel(auth *provider.Auth, model string) string {
+	return e.resolveUpstreamModel(model, auth)
+}
+
 func (e *ClaudeExecutor) resolveUpstreamModel(alias string, auth *provider.Auth) string {
 	if alias == "" {
 		return ""
@@ -596,3 +611,13 @@ func checkSystemInstructions(payload []byte) []byte {
 	}
 	return payload
 }
+
+// claudeRetryAfter returns how long to wait after an error response: until
+// the reset of a rejected subscription window, else the Retry-After header
+// Anthropic sends with 429 and 529 overloaded responses.
+func claudeRetryAfter(rateLimit *provider.ClaudeRateLimit, h http.Header) *time.Duration {
+	if d := rateLimit.RetryAfter(); d != nil {
+		return d
+	}
+	return executor.ParseRetryAfterHeader(h)
+}
@@ -43,11 +43,11 @@ func (e *ClineExecutor) Execute(ctx context.Context, auth *provider.Auth, req pr
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, false, nil)
+	body, err := stream.TranslateToOpenAI(ctx, e.Cfg, from, req.Model, req.Payload, false, nil)
 	if err != nil {
 		return resp, err
 	}
-	body = e.ApplyPayloadConfig(req.Model, body)
+	body = e.ApplyPayloadConfig(ctx, req.Model, body)
 
 	url := strings.TrimSuffix(baseURL, "/") + "/api/v1/chat/completions"
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
@@ -110,11 +110,11 @@ func (e *ClineExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, true, nil)
+	body, err := stream.TranslateToOpenAI(ctx, e.Cfg, from, req.Model, req.Payload, true, nil)
 	if err != nil {
 		return nil, err
 	}
-	body = e.ApplyPayloadConfig(req.Model, body)
+	body = e.ApplyPayloadConfig(ctx, req.Model, body)
 
 	url := strings.TrimSuffix(baseURL, "/") + "/api/v1/chat/completions"
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
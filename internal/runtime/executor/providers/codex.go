@@ -39,7 +39,13 @@ func NewCodexExecutor(cfg *config.Config) *CodexExecutor {
 
 func (e *CodexExecutor) Identifier() string { return "codex" }
 
-func (e *CodexExecutor) PrepareRequest(_ *http.Request, _ *provider.Auth) error { return nil }
+// PrepareRequest applies the provider's custom headers, rendering any templates.
+func (e *CodexExecutor) PrepareRequest(req *http.Request, auth *provider.Auth) error {
+	if auth != nil {
+		util.ApplyCustomHeadersFromAttrs(req, auth.Attributes)
+	}
+	return nil
+}
 
 func (e *CodexExecutor) Execute(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
 	apiKey, baseURL := codexCreds(auth)
@@ -51,13 +57,14 @@ func (e *CodexExecutor) Execute(ctx context.Context, auth *provider.Auth, req pr
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, err := stream.TranslateToCodex(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+	executor.WarnDroppedParams(ctx, e.Identifier(), req.Payload, executor.PenaltyAndSeedParams...)
+	body, err := stream.TranslateToCodex(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if err != nil {
 		return resp, err
 	}
 
 	body = e.setReasoningEffortByAlias(req.Model, body)
-	body = e.ApplyPayloadConfig(req.Model, body)
+	body = e.ApplyPayloadConfig(ctx, req.Model, body)
 
 	body, _ = sjson.SetBytes(body, "stream", true)
 	body, _ = sjson.DeleteBytes(body, "previous_response_id")
@@ -131,13 +138,14 @@ func (e *CodexExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, err := stream.TranslateToCodex(e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
+	executor.WarnDroppedParams(ctx, e.Identifier(), req.Payload, executor.PenaltyAndSeedParams...)
+	body, err := stream.TranslateToCodex(ctx, e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
 	if err != nil {
 		return nil, err
 	}
 
 	body = e.setReasoningEffortByAlias(req.Model, body)
-	body = e.ApplyPayloadConfig(req.Model, body)
+	body = e.ApplyPayloadConfig(ctx, req.Model, body)
 	body, _ = sjson.DeleteBytes(body, "previous_response_id")
 
 	url := strings.TrimSuffix(baseURL, "/") + "/responses"
@@ -169,6 +177,7 @@ func (e *CodexExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 
 	messageID := "resp-" + req.Model
 	streamCtx := stream.NewStreamContext()
+	streamCtx.StopSequences = stream.EmulatedStopSequences(req.Payload, 0)
 	translator := stream.NewStreamTranslator(e.Cfg, from, from.String(), req.Model, messageID, streamCtx)
 	processor := &codexStreamProcessor{
 		translator: translator,
@@ -215,7 +224,7 @@ func (p *codexStreamProcessor) ProcessDone() ([][]byte, error) {
 
 func (e *CodexExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
 	from := opts.SourceFormat
-	body, err := stream.TranslateToCodex(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+	body, err := stream.TranslateToCodex(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if err != nil {
 		return provider.Response{}, err
 	}
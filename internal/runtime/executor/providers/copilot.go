@@ -50,11 +50,11 @@ func (e *CopilotExecutor) Execute(ctx context.Context, auth *provider.Auth, req
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, errTranslate := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, false, nil)
+	body, errTranslate := stream.TranslateToOpenAI(ctx, e.Cfg, from, req.Model, req.Payload, false, nil)
 	if errTranslate != nil {
 		return resp, errTranslate
 	}
-	body = e.ApplyPayloadConfig(req.Model, body)
+	body = e.ApplyPayloadConfig(ctx, req.Model, body)
 	body, _ = sjson.SetBytes(body, "stream", false)
 
 	url := executor.GitHubCopilotDefaultBaseURL + executor.GitHubCopilotChatPath
@@ -113,11 +113,11 @@ func (e *CopilotExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, errTranslate := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, true, nil)
+	body, errTranslate := stream.TranslateToOpenAI(ctx, e.Cfg, from, req.Model, req.Payload, true, nil)
 	if errTranslate != nil {
 		return nil, errTranslate
 	}
-	body = e.ApplyPayloadConfig(req.Model, body)
+	body = e.ApplyPayloadConfig(ctx, req.Model, body)
 	body, _ = sjson.SetBytes(body, "stream", true)
 	body, _ = sjson.SetBytes(body, "stream_options.include_usage", true)
 
@@ -20,7 +20,6 @@ import (
 	"github.com/nghyane/llm-mux/internal/sseutil"
 	"github.com/nghyane/llm-mux/internal/streamutil"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
-	"github.com/nghyane/llm-mux/internal/translator/preprocess"
 	"github.com/nghyane/llm-mux/internal/translator/to_ir"
 	"github.com/nghyane/llm-mux/internal/util"
 	"github.com/tidwall/sjson"
@@ -62,7 +61,13 @@ func (p *geminiStreamProcessor) ProcessDone() ([][]byte, error) {
 
 func (e *GeminiExecutor) Identifier() string { return "gemini" }
 
-func (e *GeminiExecutor) PrepareRequest(_ *http.Request, _ *provider.Auth) error { return nil }
+// PrepareRequest applies the provider's custom headers, rendering any templates.
+func (e *GeminiExecutor) PrepareRequest(req *http.Request, auth *provider.Auth) error {
+	if auth != nil {
+		util.ApplyCustomHeadersFromAttrs(req, auth.Attributes)
+	}
+	return nil
+}
 
 func (e *GeminiExecutor) Execute(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
 	apiKey, bearer := geminiCreds(auth)
@@ -71,7 +76,7 @@ func (e *GeminiExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, err := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+	body, err := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if err != nil {
 		return resp, fmt.Errorf("translate request: %w", err)
 	}
@@ -85,7 +90,7 @@ func (e *GeminiExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 		}
 	}
 	body = util.StripThinkingConfigIfUnsupported(req.Model, body)
-	body = e.ApplyPayloadConfig(req.Model, body)
+	body = e.ApplyPayloadConfig(ctx, req.Model, body)
 
 	action := "generateContent"
 	if req.Metadata != nil {
@@ -168,20 +173,16 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 
 	from := opts.SourceFormat
 
-	translation, err := stream.TranslateToGeminiWithTokens(e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
+	translation, err := stream.TranslateToGeminiWithTokens(ctx, e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
 	if err != nil {
 		return nil, fmt.Errorf("translate request: %w", err)
 	}
-	if translation.IR != nil {
-		preprocess.Apply(translation.IR)
-	}
-
 	body := translation.Payload
 	if budgetOverride, includeOverride, ok := util.GeminiThinkingFromMetadata(req.Metadata); ok && util.ModelSupportsThinking(req.Model) {
 		body = util.ApplyGeminiThinkingConfig(body, budgetOverride, includeOverride)
 	}
 	body = util.StripThinkingConfigIfUnsupported(req.Model, body)
-	body = e.ApplyPayloadConfig(req.Model, body)
+	body = e.ApplyPayloadConfig(ctx, req.Model, body)
 
 	baseURL := resolveGeminiBaseURL(auth)
 	ub := executor.GetURLBuilder()
@@ -252,6 +253,8 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 		scanner := bufio.NewScanner(httpResp.Body)
 		scanner.Buffer(*bufPtr, executor.DefaultStreamBufferSize)
 		streamCtx := stream.NewStreamContext()
+		streamCtx.JSONMode = stream.IsJSONObjectRequest(req.Payload)
+		streamCtx.StopSequences = stream.EmulatedStopSequences(req.Payload, ir.GeminiMaxStopSequences)
 		messageID := "chatcmpl-" + req.Model
 		translator := stream.NewStreamTranslator(e.Cfg, from, from.String(), req.Model, messageID, streamCtx)
 		processor := &geminiStreamProcessor{
@@ -326,7 +329,7 @@ func (e *GeminiExecutor) CountTokens(ctx context.Context, auth *provider.Auth, r
 	apiKey, bearer := geminiCreds(auth)
 
 	from := opts.SourceFormat
-	translatedReq, err := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+	translatedReq, err := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if err != nil {
 		return provider.Response{}, fmt.Errorf("translate request: %w", err)
 	}
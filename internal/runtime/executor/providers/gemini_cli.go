@@ -65,7 +65,7 @@ func (e *GeminiCLIExecutor) Execute(ctx context.Context, auth *provider.Auth, re
 
 	var basePayload []byte
 	if ir.IsClaudeModel(req.Model) {
-		irReq, errIR := stream.ConvertRequestToIR(from, req.Model, req.Payload, req.Metadata)
+		irReq, errIR := stream.ConvertRequestToIR(ctx, from, req.Model, req.Payload, req.Metadata)
 		if errIR != nil {
 			return resp, fmt.Errorf("failed to parse request: %w", errIR)
 		}
@@ -74,7 +74,7 @@ func (e *GeminiCLIExecutor) Execute(ctx context.Context, auth *provider.Auth, re
 			return resp, fmt.Errorf("failed to translate request: %w", err)
 		}
 	} else {
-		geminiPayload, errGemini := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+		geminiPayload, errGemini := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 		if errGemini != nil {
 			return resp, fmt.Errorf("failed to translate request: %w", errGemini)
 		}
@@ -218,7 +218,7 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *provider.Au
 
 	var translation *stream.TranslationResult
 	if ir.IsClaudeModel(req.Model) {
-		irReq, errIR := stream.ConvertRequestToIR(from, req.Model, req.Payload, req.Metadata)
+		irReq, errIR := stream.ConvertRequestToIR(ctx, from, req.Model, req.Payload, req.Metadata)
 		if errIR != nil {
 			return nil, fmt.Errorf("failed to parse request: %w", errIR)
 		}
@@ -232,7 +232,7 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *provider.Au
 		}
 	} else {
 		var errGemini error
-		translation, errGemini = stream.TranslateToGeminiWithTokens(e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
+		translation, errGemini = stream.TranslateToGeminiWithTokens(ctx, e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
 		if errGemini != nil {
 			return nil, fmt.Errorf("failed to translate request: %w", errGemini)
 		}
@@ -329,6 +329,8 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *provider.Au
 		}
 
 		streamCtx := stream.NewStreamContext()
+		streamCtx.JSONMode = stream.IsJSONObjectRequest(req.Payload)
+		streamCtx.StopSequences = stream.EmulatedStopSequences(req.Payload, ir.GeminiMaxStopSequences)
 		messageID := "chatcmpl-" + attemptModel
 
 		processor := stream.NewGeminiStreamProcessor(e.Cfg, from, attemptModel, messageID, streamCtx)
@@ -387,7 +389,7 @@ func (e *GeminiCLIExecutor) CountTokens(ctx context.Context, auth *provider.Auth
 		attemptModel := models[idx]
 		var payload []byte
 		if ir.IsClaudeModel(attemptModel) {
-			irReq, errIR := stream.ConvertRequestToIR(from, attemptModel, req.Payload, req.Metadata)
+			irReq, errIR := stream.ConvertRequestToIR(ctx, from, attemptModel, req.Payload, req.Metadata)
 			if errIR != nil {
 				return provider.Response{}, fmt.Errorf("failed to parse request: %w", errIR)
 			}
@@ -397,7 +399,7 @@ func (e *GeminiCLIExecutor) CountTokens(ctx context.Context, auth *provider.Auth
 				return provider.Response{}, fmt.Errorf("failed to translate request: %w", errClaude)
 			}
 		} else {
-			geminiPayload, errGemini := stream.TranslateToGemini(e.Cfg, from, attemptModel, req.Payload, false, req.Metadata)
+			geminiPayload, errGemini := stream.TranslateToGemini(ctx, e.Cfg, from, attemptModel, req.Payload, false, req.Metadata)
 			if errGemini != nil {
 				return provider.Response{}, fmt.Errorf("failed to translate request: %w", errGemini)
 			}
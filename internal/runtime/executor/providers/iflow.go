@@ -48,11 +48,11 @@ func (e *IFlowExecutor) Execute(ctx context.Context, auth *provider.Auth, req pr
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+	body, err := stream.TranslateToOpenAI(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if err != nil {
 		return resp, err
 	}
-	body = e.ApplyPayloadConfig(req.Model, body)
+	body = e.ApplyPayloadConfig(ctx, req.Model, body)
 
 	endpoint := strings.TrimSuffix(baseURL, "/") + executor.IFlowDefaultEndpoint
 
@@ -115,7 +115,7 @@ func (e *IFlowExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
+	body, err := stream.TranslateToOpenAI(ctx, e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +124,7 @@ func (e *IFlowExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	if toolsResult.Exists() && toolsResult.IsArray() && len(toolsResult.Array()) == 0 {
 		body = ensureToolsArray(body)
 	}
-	body = e.ApplyPayloadConfig(req.Model, body)
+	body = e.ApplyPayloadConfig(ctx, req.Model, body)
 
 	endpoint := strings.TrimSuffix(baseURL, "/") + executor.IFlowDefaultEndpoint
 
@@ -109,6 +109,7 @@ type kiroRequestContext struct {
 
 func (e *KiroExecutor) prepareRequest(ctx context.Context, auth *provider.Auth, req provider.Request) (*kiroRequestContext, error) {
 	rc := &kiroRequestContext{ctx: ctx, auth: auth, req: req, requestID: uuid.New().String()[:8]}
+	executor.WarnDroppedParams(ctx, e.Identifier(), req.Payload, executor.PenaltyAndSeedParams...)
 	var err error
 	rc.token, rc.auth, err = e.ensureValidToken(ctx, auth)
 	if err != nil {
@@ -174,17 +175,18 @@ func (e *KiroExecutor) Execute(ctx context.Context, auth *provider.Auth, req pro
 		return provider.Response{}, fmt.Errorf("upstream error %d: %s", resp.StatusCode, string(body))
 	}
 
+	stops := stream.EmulatedStopSequences(req.Payload, 0)
 	if hasEventStreamContentType(resp.Header.Get("Content-Type")) {
-		return e.handleEventStreamResponse(resp.Body, req.Model)
+		return e.handleEventStreamResponse(resp.Body, req.Model, stops)
 	}
-	return e.handleJSONResponse(resp.Body, req.Model)
+	return e.handleJSONResponse(resp.Body, req.Model, stops)
 }
 
 func hasEventStreamContentType(contentType string) bool {
 	return len(contentType) >= 37 && contentType[:37] == "application/vnd.amazon.eventstream"
 }
 
-func (e *KiroExecutor) handleEventStreamResponse(body io.ReadCloser, model string) (provider.Response, error) {
+func (e *KiroExecutor) handleEventStreamResponse(body io.ReadCloser, model string, stops []string) (provider.Response, error) {
 	bufPtr := stream.ScannerBufferPool.Get().(*[]byte)
 	defer stream.ScannerBufferPool.Put(bufPtr)
 
@@ -204,6 +206,7 @@ func (e *KiroExecutor) handleEventStreamResponse(body io.ReadCloser, model strin
 	if state.AccumulatedContent != "" {
 		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeText, Text: state.AccumulatedContent})
 	}
+	applyEmulatedStops([]ir.Message{*msg}, stops)
 
 	converted, err := from_ir.ToOpenAIChatCompletion([]ir.Message{*msg}, nil, model, "chatcmpl-"+uuid.New().String())
 	if err != nil {
@@ -212,7 +215,7 @@ func (e *KiroExecutor) handleEventStreamResponse(body io.ReadCloser, model strin
 	return provider.Response{Payload: converted}, nil
 }
 
-func (e *KiroExecutor) handleJSONResponse(body io.ReadCloser, model string) (provider.Response, error) {
+func (e *KiroExecutor) handleJSONResponse(body io.ReadCloser, model string, stops []string) (provider.Response, error) {
 	rawData, err := io.ReadAll(body)
 	if err != nil {
 		return provider.Response{}, err
@@ -222,6 +225,7 @@ func (e *KiroExecutor) handleJSONResponse(body io.ReadCloser, model string) (pro
 	if err != nil {
 		return provider.Response{}, err
 	}
+	applyEmulatedStops(messages, stops)
 
 	converted, err := from_ir.ToOpenAIChatCompletion(messages, usage, model, "chatcmpl-"+uuid.New().String())
 	if err != nil {
@@ -253,11 +257,11 @@ func (e *KiroExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth, r
 	}
 
 	out := make(chan provider.StreamChunk, 4096) // Single user: maximize throughput
-	go e.processStream(ctx, resp, req.Model, out)
+	go e.processStream(ctx, resp, req.Model, stream.EmulatedStopSequences(req.Payload, 0), out)
 	return out, nil
 }
 
-func (e *KiroExecutor) processStream(ctx context.Context, resp *http.Response, model string, out chan<- provider.StreamChunk) {
+func (e *KiroExecutor) processStream(ctx context.Context, resp *http.Response, model string, stops []string, out chan<- provider.StreamChunk) {
 	defer resp.Body.Close()
 	defer close(out)
 	defer func() {
@@ -276,6 +280,25 @@ func (e *KiroExecutor) processStream(ctx context.Context, resp *http.Response, m
 	messageID := "chatcmpl-" + uuid.New().String()
 	idx := 0
 
+	// Kiro ignores stop sequences, so they are emulated on the event stream.
+	var buffer stream.EventBufferStrategy = stream.NewPassthroughEventBuffer()
+	if len(stops) > 0 {
+		buffer = stream.NewStopSequenceEventBuffer(stops)
+	}
+	send := func(events []*ir.UnifiedEvent) bool {
+		for _, ev := range events {
+			if chunk, _ := from_ir.ToOpenAIChunk(*ev, model, messageID, idx); len(chunk) > 0 {
+				select {
+				case out <- provider.StreamChunk{Payload: chunk}:
+					idx++
+				case <-ctx.Done():
+					return false
+				}
+			}
+		}
+		return true
+	}
+
 	for scanner.Scan() {
 		select {
 		case <-ctx.Done():
@@ -288,23 +311,35 @@ func (e *KiroExecutor) processStream(ctx context.Context, resp *http.Response, m
 			continue
 		}
 		events, _ := state.ProcessChunk(payload)
-		for _, ev := range events {
-			if chunk, _ := from_ir.ToOpenAIChunk(ev, model, messageID, idx); len(chunk) > 0 {
-				select {
-				case out <- provider.StreamChunk{Payload: chunk}:
-					idx++
-				case <-ctx.Done():
-					return
-				}
+		for i := range events {
+			if !send(buffer.Process(&events[i])) {
+				return
 			}
 		}
 	}
 
-	finish := ir.UnifiedEvent{Type: ir.EventTypeFinish, FinishReason: state.DetermineFinishReason()}
-	if chunk, _ := from_ir.ToOpenAIChunk(finish, model, messageID, idx); len(chunk) > 0 {
-		select {
-		case out <- provider.StreamChunk{Payload: chunk}:
-		case <-ctx.Done():
+	finish := &ir.UnifiedEvent{Type: ir.EventTypeFinish, FinishReason: state.DetermineFinishReason()}
+	if send(buffer.Process(finish)) {
+		send(buffer.Flush())
+	}
+}
+
+// applyEmulatedStops truncates assistant text at the first stop sequence.
+func applyEmulatedStops(messages []ir.Message, stops []string) {
+	if len(stops) == 0 {
+		return
+	}
+	for i := range messages {
+		for j := range messages[i].Content {
+			part := &messages[i].Content[j]
+			if part.Type != ir.ContentTypeText {
+				continue
+			}
+			if text, found := stream.TruncateAtStopSequence(part.Text, stops); found {
+				part.Text = text
+				messages[i].Content = messages[i].Content[:j+1]
+				return
+			}
 		}
 	}
 }
@@ -0,0 +1,172 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// localServerTimeout bounds probe and tokenizer calls to llama.cpp and vLLM
+// servers, which are expected to answer from the local network.
+const localServerTimeout = 5 * time.Second
+
+// localServerRoot returns the server root of an OpenAI-compatible base URL
+// such as http://localhost:8080/v1, where /health and /tokenize live.
+func localServerRoot(baseURL string) string {
+	return strings.TrimSuffix(strings.TrimRight(baseURL, "/"), "/v1")
+}
+
+// ProbeLocalServer checks the health of a llama.cpp or vLLM server and
+// returns the models it serves, with their context length when reported.
+// An unhealthy server is logged and still asked for its models.
+func ProbeLocalServer(ctx context.Context, p *config.Provider, apiKey string) ([]*registry.ModelInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, localServerTimeout)
+	defer cancel()
+	name := p.GetDisplayName()
+
+	// llama.cpp answers 503 while the model is loading; vLLM answers 200
+	// with an empty body once ready.
+	if _, err := localServerCall(ctx, http.MethodGet, localServerRoot(p.BaseURL)+"/health", apiKey, nil); err != nil {
+		log.Warnf("%s %s: health check failed: %v", p.Type, name, err)
+	}
+
+	body, err := localServerCall(ctx, http.MethodGet, strings.TrimRight(p.BaseURL, "/")+"/models", apiKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	var models []*registry.ModelInfo
+	for _, m := range gjson.GetBytes(body, "data").Array() {
+		id := m.Get("id").String()
+		if id == "" {
+			continue
+		}
+		info := &registry.ModelInfo{
+			ID:          id,
+			Object:      "model",
+			Created:     now,
+			OwnedBy:     name,
+			Type:        "openai-compatibility",
+			DisplayName: id,
+		}
+		switch p.Type {
+		case config.ProviderTypeVLLM:
+			info.ContextLength = int(m.Get("max_model_len").Int())
+		case config.ProviderTypeLlamaCpp:
+			info.ContextLength = int(m.Get("meta.n_ctx_train").Int())
+		}
+		models = append(models, info)
+	}
+	return models, nil
+}
+
+// applyLocalSamplingParams adds the sampling parameters the OpenAI request
+// format lacks, under the names the server type expects.
+func applyLocalSamplingParams(t config.ProviderType, req *ir.UnifiedChatRequest, payload []byte) []byte {
+	if req.TopK != nil {
+		payload, _ = sjson.SetBytes(payload, "top_k", *req.TopK)
+	}
+	if v, ok := req.Metadata[ir.MetaMinP]; ok {
+		payload, _ = sjson.SetBytes(payload, "min_p", v)
+	}
+	if v, ok := req.Metadata[ir.MetaRepetitionPenalty]; ok {
+		key := "repetition_penalty"
+		if t == config.ProviderTypeLlamaCpp {
+			key = "repeat_penalty"
+		}
+		payload, _ = sjson.SetBytes(payload, key, v)
+	}
+	return payload
+}
+
+// countLocalServerTokens counts the prompt tokens of an OpenAI chat request
+// with the server's own tokenizer: vLLM tokenizes chat messages directly,
+// llama.cpp applies its chat template first.
+func countLocalServerTokens(ctx context.Context, p *config.Provider, apiKey string, request []byte) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, localServerTimeout)
+	defer cancel()
+	root := localServerRoot(p.BaseURL)
+	messages := gjson.GetBytes(request, "messages").Raw
+	tools := gjson.GetBytes(request, "tools")
+
+	switch p.Type {
+	case config.ProviderTypeVLLM:
+		body := []byte(`{}`)
+		body, _ = sjson.SetBytes(body, "model", gjson.GetBytes(request, "model").String())
+		body, _ = sjson.SetRawBytes(body, "messages", []byte(messages))
+		if tools.IsArray() {
+			body, _ = sjson.SetRawBytes(body, "tools", []byte(tools.Raw))
+		}
+		resp, err := localServerCall(ctx, http.MethodPost, root+"/tokenize", apiKey, body)
+		if err != nil {
+			return 0, err
+		}
+		return gjson.GetBytes(resp, "count").Int(), nil
+	case config.ProviderTypeLlamaCpp:
+		body, _ := sjson.SetRawBytes([]byte(`{}`), "messages", []byte(messages))
+		if tools.IsArray() {
+			body, _ = sjson.SetRawBytes(body, "tools", []byte(tools.Raw))
+		}
+		resp, err := localServerCall(ctx, http.MethodPost, root+"/apply-template", apiKey, body)
+		if err != nil {
+			return 0, err
+		}
+		body, _ = sjson.SetBytes([]byte(`{}`), "content", gjson.GetBytes(resp, "prompt").String())
+		if resp, err = localServerCall(ctx, http.MethodPost, root+"/tokenize", apiKey, body); err != nil {
+			return 0, err
+		}
+		return int64(len(gjson.GetBytes(resp, "tokens").Array())), nil
+	}
+	return 0, fmt.Errorf("%s has no tokenizer endpoint", p.Type)
+}
+
+func localServerCall(ctx context.Context, method, url, apiKey string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if apiKey != "" && apiKey != config.NoAPIKey {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s: status %d: %s", method, url, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// localServerConfig returns the llama.cpp or vLLM provider an auth belongs
+// to, or nil for other openai-compatible providers.
+func (e *OpenAICompatExecutor) localServerConfig(auth *provider.Auth) *config.Provider {
+	if compat := e.resolveCompatConfig(auth); compat != nil && compat.Type.IsLocalServer() {
+		return compat
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package providers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestProbeLocalServerDiscoversModels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+		case "/v1/models":
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("placeholder key sent upstream")
+			}
+			_, _ = io.WriteString(w, `{"data":[{"id":"Qwen/Qwen2.5-7B","max_model_len":32768}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	p := &config.Provider{Type: config.ProviderTypeVLLM, Name: "vllm", BaseURL: srv.URL + "/v1"}
+	models, err := ProbeLocalServer(context.Background(), p, config.NoAPIKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 || models[0].ID != "Qwen/Qwen2.5-7B" || models[0].ContextLength != 32768 {
+		t.Fatalf("models = %+v", models)
+	}
+}
+
+func TestCountLocalServerTokensLlamaCpp(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/apply-template":
+			if gjson.GetBytes(body, "messages.0.content").String() != "hi" {
+				t.Errorf("apply-template body = %s", body)
+			}
+			_, _ = io.WriteString(w, `{"prompt":"<|user|>hi<|assistant|>"}`)
+		case "/tokenize":
+			if gjson.GetBytes(body, "content").String() != "<|user|>hi<|assistant|>" {
+				t.Errorf("tokenize body = %s", body)
+			}
+			_, _ = io.WriteString(w, `{"tokens":[1,2,3,4]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	p := &config.Provider{Type: config.ProviderTypeLlamaCpp, BaseURL: srv.URL + "/v1"}
+	count, err := countLocalServerTokens(context.Background(), p, "", []byte(`{"model":"m","messages":[{"role":"user","content":"hi"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 {
+		t.Fatalf("count = %d, want 4", count)
+	}
+}
+
+func TestApplyLocalSamplingParams(t *testing.T) {
+	req, err := to_ir.ParseOpenAIRequest([]byte(`{"model":"m","messages":[{"role":"user","content":"hi"}],"top_k":40,"min_p":0.05,"repetition_penalty":1.1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vllm := applyLocalSamplingParams(config.ProviderTypeVLLM, req, []byte(`{"model":"m"}`))
+	if got := gjson.GetBytes(vllm, "repetition_penalty").Float(); got != 1.1 {
+		t.Fatalf("vllm repetition_penalty = %v in %s", got, vllm)
+	}
+	llama := applyLocalSamplingParams(config.ProviderTypeLlamaCpp, req, []byte(`{"model":"m"}`))
+	if got := gjson.GetBytes(llama, "repeat_penalty").Float(); got != 1.1 {
+		t.Fatalf("llama.cpp repeat_penalty = %v in %s", got, llama)
+	}
+	if gjson.GetBytes(llama, "min_p").Float() != 0.05 || gjson.GetBytes(llama, "top_k").Int() != 40 {
+		t.Fatalf("llama.cpp payload = %s", llama)
+	}
+}
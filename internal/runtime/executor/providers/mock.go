@@ -0,0 +1,302 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/runtime/executor"
+	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
+)
+
+const (
+	mockDefaultTokensPerSecond = 50
+	mockDefaultOutputTokens    = 100
+)
+
+// mockWords are cycled through to build responses; each word is one token.
+var mockWords = strings.Fields("lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod tempor incididunt ut labore et dolore magna aliqua enim ad minim veniam quis nostrud exercitation ullamco laboris nisi aliquip ex ea commodo consequat")
+
+// MockExecutor serves the built-in mock provider. Responses are generated
+// locally in OpenAI format and translated like any upstream response, so the
+// full routing, translation and usage pipeline is exercised without spending
+// tokens.
+type MockExecutor struct {
+	executor.BaseExecutor
+
+	mu       sync.Mutex
+	rngs     map[string]*rand.Rand
+	windows  map[string]mockWindow
+	nowFunc  func() time.Time
+	sleepFor func(ctx context.Context, d time.Duration) error
+}
+
+type mockWindow struct {
+	start time.Time
+	count int
+}
+
+func NewMockExecutor(cfg *config.Config) *MockExecutor {
+	return &MockExecutor{
+		BaseExecutor: executor.BaseExecutor{Cfg: cfg},
+		rngs:         make(map[string]*rand.Rand),
+		windows:      make(map[string]mockWindow),
+		nowFunc:      time.Now,
+		sleepFor:     sleepContext,
+	}
+}
+
+func (e *MockExecutor) Identifier() string { return "mock" }
+
+func (e *MockExecutor) Execute(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	settings := e.settings(auth)
+	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.TrackFailure(ctx, &err)
+
+	if err = e.admit(auth, settings); err != nil {
+		return resp, err
+	}
+	if err = e.sleepFor(ctx, mockFirstTokenLatency(settings)); err != nil {
+		return resp, err
+	}
+
+	words := mockOutput(req.Payload, mockOutputTokens(settings))
+	data, _ := json.Marshal(map[string]any{
+		"id":      mockResponseID(req.Model),
+		"object":  "chat.completion",
+		"created": e.nowFunc().Unix(),
+		"model":   req.Model,
+		"choices": []any{map[string]any{
+			"index":         0,
+			"message":       map[string]any{"role": "assistant", "content": strings.Join(words, " ")},
+			"finish_reason": "stop",
+		}},
+		"usage": mockUsage(req.Payload, len(words)),
+	})
+	reporter.Publish(ctx, executor.ExtractUsageFromOpenAIResponse(data))
+
+	translated, err := stream.TranslateResponseNonStream(e.Cfg, provider.FromString("openai"), opts.SourceFormat, data, req.Model)
+	if err != nil {
+		return resp, err
+	}
+	if translated == nil {
+		translated = data
+	}
+	return provider.Response{Payload: translated}, nil
+}
+
+func (e *MockExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (streamChan <-chan provider.StreamChunk, err error) {
+	settings := e.settings(auth)
+	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.TrackFailure(ctx, &err)
+
+	if err = e.admit(auth, settings); err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go e.writeStream(ctx, pw, req, settings)
+
+	messageID := mockResponseID(req.Model)
+	processor := stream.NewOpenAIStreamProcessor(e.Cfg, opts.SourceFormat, req.Model, messageID)
+	return stream.RunSSEStream(ctx, pr, reporter, processor, stream.StreamConfig{
+		ExecutorName:     "mock executor",
+		Preprocessor:     stream.DataTagPreprocessor(),
+		HandleDoneSignal: true,
+		EnsurePublished:  true,
+	}), nil
+}
+
+// writeStream writes an OpenAI chat completion stream to pw, one token per
+// chunk at the configured rate.
+func (e *MockExecutor) writeStream(ctx context.Context, pw *io.PipeWriter, req provider.Request, settings config.MockSettings) {
+	id := mockResponseID(req.Model)
+	created := e.nowFunc().Unix()
+	chunk := func(choices []any, usage any) []byte {
+		body := map[string]any{
+			"id":      id,
+			"object":  "chat.completion.chunk",
+			"created": created,
+			"model":   req.Model,
+			"choices": choices,
+		}
+		if usage != nil {
+			body["usage"] = usage
+		}
+		data, _ := json.Marshal(body)
+		return append(append([]byte("data: "), data...), '\n', '\n')
+	}
+	choice := func(delta map[string]any, finish any) []any {
+		return []any{map[string]any{"index": 0, "delta": delta, "finish_reason": finish}}
+	}
+
+	if err := e.sleepFor(ctx, mockFirstTokenLatency(settings)); err != nil {
+		_ = pw.CloseWithError(err)
+		return
+	}
+	words := mockOutput(req.Payload, mockOutputTokens(settings))
+	tps := settings.TokensPerSecond
+	if tps <= 0 {
+		tps = mockDefaultTokensPerSecond
+	}
+	interval := time.Second / time.Duration(tps)
+	for i, word := range words {
+		if i > 0 {
+			word = " " + word
+			if err := e.sleepFor(ctx, interval); err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+		}
+		delta := map[string]any{"content": word}
+		if i == 0 {
+			delta["role"] = "assistant"
+		}
+		if _, err := pw.Write(chunk(choice(delta, nil), nil)); err != nil {
+			return
+		}
+	}
+	// Usage follows in its own chunk, as with OpenAI's include_usage.
+	if _, err := pw.Write(chunk(choice(map[string]any{}, "stop"), nil)); err != nil {
+		return
+	}
+	if _, err := pw.Write(chunk([]any{}, mockUsage(req.Payload, len(words)))); err != nil {
+		return
+	}
+	_, _ = pw.Write([]byte("data: [DONE]\n\n"))
+	_ = pw.Close()
+}
+
+func (e *MockExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+	return executor.CountTokensForOpenAIProvider(ctx, e.Cfg, "mock executor", opts.SourceFormat, req.Model, req.Payload, nil)
+}
+
+func (e *MockExecutor) Refresh(_ context.Context, auth *provider.Auth) (*provider.Auth, error) {
+	return auth, nil
+}
+
+// settings returns the MockSettings of the provider entry auth was created from.
+func (e *MockExecutor) settings(auth *provider.Auth) config.MockSettings {
+	if auth == nil {
+		return config.MockSettings{}
+	}
+	if entry := e.Cfg.MockProvider(auth.Label, auth.Attributes["api_key"]); entry != nil {
+		return entry.Mock
+	}
+	return config.MockSettings{}
+}
+
+// admit applies the account's rate limit and error injection.
+func (e *MockExecutor) admit(auth *provider.Auth, settings config.MockSettings) error {
+	authID := ""
+	if auth != nil {
+		authID = auth.ID
+	}
+	now := e.nowFunc()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if limit := settings.RequestsPerMinute; limit > 0 {
+		w := e.windows[authID]
+		if now.Sub(w.start) >= time.Minute {
+			w = mockWindow{start: now}
+		}
+		if w.count >= limit {
+			retry := w.start.Add(time.Minute).Sub(now)
+			return executor.NewStatusError(http.StatusTooManyRequests, fmt.Sprintf(`{"error":{"message":"mock rate limit of %d requests per minute exceeded","type":"rate_limit_error"}}`, limit), &retry)
+		}
+		w.count++
+		e.windows[authID] = w
+	}
+
+	if settings.ErrorRate <= 0 {
+		return nil
+	}
+	rng, ok := e.rngs[authID]
+	if !ok {
+		seed := uint64(settings.Seed)
+		if seed == 0 {
+			seed = uint64(now.UnixNano())
+		}
+		rng = rand.New(rand.NewPCG(seed, mockHash([]byte(authID))))
+		e.rngs[authID] = rng
+	}
+	if rng.Float64() >= settings.ErrorRate {
+		return nil
+	}
+	status := settings.ErrorStatus
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	return executor.NewStatusError(status, fmt.Sprintf(`{"error":{"message":"mock injected error","type":"mock_error","code":%d}}`, status), nil)
+}
+
+// mockOutput returns n words chosen deterministically from payload, so the
+// same request always gets the same response.
+func mockOutput(payload []byte, n int) []string {
+	offset := int(mockHash(payload) % uint64(len(mockWords)))
+	words := make([]string, n)
+	for i := range words {
+		words[i] = mockWords[(offset+i)%len(mockWords)]
+	}
+	return words
+}
+
+// mockUsage estimates prompt tokens as a quarter of the payload size.
+func mockUsage(payload []byte, completionTokens int) map[string]any {
+	prompt := len(payload) / 4
+	return map[string]any{
+		"prompt_tokens":     prompt,
+		"completion_tokens": completionTokens,
+		"total_tokens":      prompt + completionTokens,
+	}
+}
+
+func mockOutputTokens(s config.MockSettings) int {
+	if s.OutputTokens > 0 {
+		return s.OutputTokens
+	}
+	return mockDefaultOutputTokens
+}
+
+func mockFirstTokenLatency(s config.MockSettings) time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(s.FirstTokenLatency))
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+func mockResponseID(model string) string {
+	return "chatcmpl-mock-" + model
+}
+
+func mockHash(b []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(b)
+	return h.Sum64()
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,74 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+func newTestMockExecutor(settings config.MockSettings) (*MockExecutor, *provider.Auth) {
+	cfg := &config.Config{Providers: []config.Provider{{Type: config.ProviderTypeMock, Mock: settings}}}
+	e := NewMockExecutor(cfg)
+	e.sleepFor = func(ctx context.Context, _ time.Duration) error { return ctx.Err() }
+	auth := &provider.Auth{ID: "mock-1", Provider: "mock", Label: "mock", Attributes: map[string]string{"api_key": "mock"}}
+	return e, auth
+}
+
+func TestMockExecutorExecute(t *testing.T) {
+	e, auth := newTestMockExecutor(config.MockSettings{OutputTokens: 5})
+	req := provider.Request{Model: config.DefaultMockModel, Payload: []byte(`{"model":"mock-model","messages":[{"role":"user","content":"hi"}]}`)}
+	opts := provider.Options{SourceFormat: provider.FromString("openai")}
+
+	first, err := e.Execute(context.Background(), auth, req, opts)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	content := gjson.GetBytes(first.Payload, "choices.0.message.content").String()
+	if n := len(strings.Fields(content)); n != 5 {
+		t.Fatalf("content = %q, want 5 words", content)
+	}
+	if got := gjson.GetBytes(first.Payload, "usage.completion_tokens").Int(); got != 5 {
+		t.Errorf("completion_tokens = %d", got)
+	}
+	second, _ := e.Execute(context.Background(), auth, req, opts)
+	if got := gjson.GetBytes(second.Payload, "choices.0.message.content").String(); got != content {
+		t.Errorf("output not deterministic: %q vs %q", got, content)
+	}
+}
+
+func TestMockExecutorRateLimitAndErrors(t *testing.T) {
+	e, auth := newTestMockExecutor(config.MockSettings{RequestsPerMinute: 2})
+	now := time.Unix(1000, 0)
+	e.nowFunc = func() time.Time { return now }
+	settings := e.settings(auth)
+
+	for i := 0; i < 2; i++ {
+		if err := e.admit(auth, settings); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	err := e.admit(auth, settings)
+	var se interface {
+		StatusCode() int
+		RetryAfter() *time.Duration
+	}
+	if !errors.As(err, &se) || se.StatusCode() != http.StatusTooManyRequests || se.RetryAfter() == nil || *se.RetryAfter() != time.Minute {
+		t.Fatalf("third request err = %v", err)
+	}
+	now = now.Add(time.Minute)
+	if err := e.admit(auth, settings); err != nil {
+		t.Fatalf("after window: %v", err)
+	}
+
+	e, auth = newTestMockExecutor(config.MockSettings{ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable})
+	if err := e.admit(auth, e.settings(auth)); !errors.As(err, &se) || se.StatusCode() != http.StatusServiceUnavailable {
+		t.Fatalf("injected err = %v", err)
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/nghyane/llm-mux/internal/runtime/executor"
 	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
 	"github.com/nghyane/llm-mux/internal/sseutil"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
+	"github.com/nghyane/llm-mux/internal/translator/preprocess"
 	"github.com/nghyane/llm-mux/internal/util"
 	"github.com/tidwall/sjson"
 )
@@ -30,7 +32,13 @@ func NewOpenAICompatExecutor(providerName string, cfg *config.Config) *OpenAICom
 
 func (e *OpenAICompatExecutor) Identifier() string { return e.provider }
 
-func (e *OpenAICompatExecutor) PrepareRequest(_ *http.Request, _ *provider.Auth) error { return nil }
+// PrepareRequest applies the provider's custom headers, rendering any templates.
+func (e *OpenAICompatExecutor) PrepareRequest(req *http.Request, auth *provider.Auth) error {
+	if auth != nil {
+		util.ApplyCustomHeadersFromAttrs(req, auth.Attributes)
+	}
+	return nil
+}
 
 func (e *OpenAICompatExecutor) Execute(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
 	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth)
@@ -43,14 +51,14 @@ func (e *OpenAICompatExecutor) Execute(ctx context.Context, auth *provider.Auth,
 	}
 
 	from := opts.SourceFormat
-	translated, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, opts.Stream, nil)
+	translated, err := e.translateRequest(ctx, auth, from, req.Model, req.Payload, opts.Stream)
 	if err != nil {
 		return resp, err
 	}
 	if modelOverride := e.resolveUpstreamModel(req.Model, auth); modelOverride != "" {
 		translated = e.overrideModel(translated, modelOverride)
 	}
-	translated = sseutil.ApplyPayloadConfigWithRoot(e.Cfg, req.Model, "openai", "", translated)
+	translated = sseutil.ApplyPayloadConfigWithRoot(e.Cfg, req.Model, provider.ClientAPIKey(ctx), "openai", "", translated)
 
 	url := strings.TrimSuffix(baseURL, "/") + "/chat/completions"
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(translated))
@@ -58,7 +66,7 @@ func (e *OpenAICompatExecutor) Execute(ctx context.Context, auth *provider.Auth,
 		return resp, err
 	}
 	executor.SetCommonHeaders(httpReq, "application/json")
-	if apiKey != "" {
+	if apiKey != "" && apiKey != config.NoAPIKey {
 		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
 	}
 	httpReq.Header.Set("User-Agent", "cli-proxy-openai-compat")
@@ -115,14 +123,14 @@ func (e *OpenAICompatExecutor) ExecuteStream(ctx context.Context, auth *provider
 		return nil, err
 	}
 	from := opts.SourceFormat
-	translated, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, true, nil)
+	translated, err := e.translateRequest(ctx, auth, from, req.Model, req.Payload, true)
 	if err != nil {
 		return nil, err
 	}
 	if modelOverride := e.resolveUpstreamModel(req.Model, auth); modelOverride != "" {
 		translated = e.overrideModel(translated, modelOverride)
 	}
-	translated = sseutil.ApplyPayloadConfigWithRoot(e.Cfg, req.Model, "openai", "", translated)
+	translated = sseutil.ApplyPayloadConfigWithRoot(e.Cfg, req.Model, provider.ClientAPIKey(ctx), "openai", "", translated)
 
 	url := strings.TrimSuffix(baseURL, "/") + "/chat/completions"
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(translated))
@@ -130,7 +138,7 @@ func (e *OpenAICompatExecutor) ExecuteStream(ctx context.Context, auth *provider
 		return nil, err
 	}
 	executor.SetCommonHeaders(httpReq, "application/json")
-	if apiKey != "" {
+	if apiKey != "" && apiKey != config.NoAPIKey {
 		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
 	}
 	httpReq.Header.Set("User-Agent", "cli-proxy-openai-compat")
@@ -168,7 +176,7 @@ func (e *OpenAICompatExecutor) ExecuteStream(ctx context.Context, auth *provider
 
 func (e *OpenAICompatExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
 	from := opts.SourceFormat
-	translated, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, false, nil)
+	translated, err := stream.TranslateToOpenAI(ctx, e.Cfg, from, req.Model, req.Payload, false, nil)
 	if err != nil {
 		return provider.Response{}, err
 	}
@@ -179,6 +187,15 @@ func (e *OpenAICompatExecutor) CountTokens(ctx context.Context, auth *provider.A
 		modelForCounting = modelOverride
 	}
 
+	if local := e.localServerConfig(auth); local != nil {
+		_, apiKey := e.resolveCredentials(auth)
+		count, err := countLocalServerTokens(ctx, local, apiKey, translated)
+		if err == nil {
+			return provider.Response{Payload: executor.BuildOpenAIUsageJSON(count)}, nil
+		}
+		log.Debugf("openai compat executor: %s tokenizer unavailable, estimating: %v", local.Type, err)
+	}
+
 	enc, err := executor.TokenizerForModel(modelForCounting)
 	if err != nil {
 		return provider.Response{}, fmt.Errorf("openai compat executor: tokenizer init failed: %w", err)
@@ -207,6 +224,11 @@ func (e *OpenAICompatExecutor) resolveCredentials(auth *provider.Auth) (baseURL,
 	return
 }
 
+// UpstreamModel implements provider.UpstreamModelResolver.
+func (e *OpenAICompatExecutor) UpstreamModel(auth *provider.Auth, model string) string {
+	return e.resolveUpstreamModel(model, auth)
+}
+
 func (e *OpenAICompatExecutor) resolveUpstreamModel(alias string, auth *provider.Auth) string {
 	if alias == "" || auth == nil || e.Cfg == nil {
 		return ""
@@ -215,6 +237,10 @@ func (e *OpenAICompatExecutor) resolveUpstreamModel(alias string, auth *provider
 	if compat == nil {
 		return ""
 	}
+	// Strip the suffix added for conflicting aliases (suffix-by-provider).
+	if base, name, ok := strings.Cut(alias, "@"); ok && strings.EqualFold(name, compat.Name) {
+		alias = base
+	}
 	for i := range compat.Models {
 		model := compat.Models[i]
 		if model.Alias != "" {
@@ -249,7 +275,7 @@ func (e *OpenAICompatExecutor) resolveCompatConfig(auth *provider.Auth) *config.
 	}
 	for i := range e.Cfg.Providers {
 		prov := &e.Cfg.Providers[i]
-		if prov.Type != config.ProviderTypeOpenAI {
+		if !prov.Type.IsOpenAICompatible() {
 			continue
 		}
 		for _, candidate := range candidates {
@@ -261,6 +287,35 @@ func (e *OpenAICompatExecutor) resolveCompatConfig(auth *provider.Auth) *config.
 	return nil
 }
 
+// translateRequest converts payload to an OpenAI request. Providers with
+// fold-late-system-messages always go through the IR so late system messages
+// can be folded, even for OpenAI clients, as do llama.cpp and vLLM providers
+// so their extra sampling parameters are mapped.
+func (e *OpenAICompatExecutor) translateRequest(ctx context.Context, auth *provider.Auth, from provider.Format, model string, payload []byte, streaming bool) ([]byte, error) {
+	compat := e.resolveCompatConfig(auth)
+	if compat == nil || (!compat.FoldLateSystemMessages && !compat.Type.IsLocalServer()) {
+		return stream.TranslateToOpenAI(ctx, e.Cfg, from, model, payload, streaming, nil)
+	}
+	irReq, err := stream.ConvertRequestToIR(ctx, from, model, payload, nil)
+	if err != nil {
+		return nil, err
+	}
+	if compat.FoldLateSystemMessages {
+		preprocess.FoldLateSystemMessages(irReq)
+	}
+	translated, err := from_ir.ToOpenAIRequest(irReq)
+	if err != nil {
+		return nil, err
+	}
+	if compat.Type.IsLocalServer() {
+		translated = applyLocalSamplingParams(compat.Type, irReq, translated)
+	}
+	if streaming {
+		translated, _ = sjson.SetBytes(translated, "stream", true)
+	}
+	return sseutil.ApplyPayloadConfig(e.Cfg, model, provider.ClientAPIKey(ctx), translated), nil
+}
+
 func (e *OpenAICompatExecutor) overrideModel(payload []byte, model string) []byte {
 	if len(payload) == 0 || model == "" {
 		return payload
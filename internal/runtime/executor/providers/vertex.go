@@ -143,7 +143,7 @@ func (e *VertexExecutor) executeWithStrategy(ctx context.Context, auth *provider
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, err := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+	body, err := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if err != nil {
 		return resp, err
 	}
@@ -227,7 +227,7 @@ func (e *VertexExecutor) executeStreamWithStrategy(ctx context.Context, auth *pr
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	translation, err := stream.TranslateToGeminiWithTokens(e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
+	translation, err := stream.TranslateToGeminiWithTokens(ctx, e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
 	if err != nil {
 		return nil, err
 	}
@@ -271,6 +271,8 @@ func (e *VertexExecutor) executeStreamWithStrategy(ctx context.Context, auth *pr
 	}
 
 	streamCtx := stream.NewStreamContext()
+	streamCtx.JSONMode = stream.IsJSONObjectRequest(req.Payload)
+	streamCtx.StopSequences = stream.EmulatedStopSequences(req.Payload, ir.GeminiMaxStopSequences)
 	translator := stream.NewStreamTranslator(e.Cfg, from, from.String(), req.Model, "chatcmpl-"+req.Model, streamCtx)
 	processor := &vertexStreamProcessor{
 		translator: translator,
@@ -310,7 +312,7 @@ func (e *VertexExecutor) CountTokens(ctx context.Context, auth *provider.Auth, r
 
 func (e *VertexExecutor) countTokensWithStrategy(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options, strategy VertexAuthStrategy) (provider.Response, error) {
 	from := opts.SourceFormat
-	translatedReq, err := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+	translatedReq, err := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if err != nil {
 		return provider.Response{}, err
 	}
@@ -33,18 +33,47 @@ func NewProxyAwareHTTPClient(ctx context.Context, cfg *config.Config, auth *prov
 		transport := getCachedTransport(proxyURL)
 		if transport != nil {
 			httpClient.Transport = transport
-			return httpClient
+			return wrapRequestClient(ctx, cfg, auth, httpClient)
 		}
 		log.Debugf("failed to setup proxy from URL: %s, falling back to context transport", proxyURL)
 	}
 
 	if rt, ok := ctx.Value("cliproxy.roundtripper").(http.RoundTripper); ok && rt != nil {
 		httpClient.Transport = rt
-		return httpClient
+		return wrapRequestClient(ctx, cfg, auth, httpClient)
+	}
+
+	if auth != nil {
+		if transport := providerDialerTransport(cfg, auth.Provider); transport != nil {
+			httpClient.Transport = transport
+			return wrapRequestClient(ctx, cfg, auth, httpClient)
+		}
 	}
 
 	httpClient.Transport = SharedTransport
-	return httpClient
+	return wrapRequestClient(ctx, cfg, auth, httpClient)
+}
+
+// wrapRequestClient applies the per-request transports carried by ctx and
+// the client headers forwarded to the auth's provider. The offline guard is
+// outermost so capture and timings see the stripped request.
+func wrapRequestClient(ctx context.Context, cfg *config.Config, auth *provider.Auth, client *http.Client) *http.Client {
+	client = withHeaderPassthrough(ctx, cfg, auth, withRequestTimings(ctx, withPayloadSizes(ctx, withUpstreamCapture(ctx, withCancelClose(client)))))
+	return withOfflineGuard(ctx, client)
+}
+
+// wrapTransport returns a client sending through wrap applied to client's
+// transport, or http.DefaultTransport when it has none, with the same timeout.
+func wrapTransport(client *http.Client, wrap func(next http.RoundTripper) http.RoundTripper) *http.Client {
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	// Use a dedicated client so the wrapped transport never leaks back into the pool.
+	return &http.Client{
+		Transport: wrap(next),
+		Timeout:   client.Timeout,
+	}
 }
 
 func buildProxyTransport(proxyURLStr string) *http.Transport {
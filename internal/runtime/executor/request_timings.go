@@ -0,0 +1,35 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// withRequestTimings wraps the client transport when ctx carries request
+// timings, recording when each upstream request is sent and its response
+// headers arrive.
+func withRequestTimings(ctx context.Context, client *http.Client) *http.Client {
+	timings := provider.RequestTimingsFrom(ctx)
+	if timings == nil {
+		return client
+	}
+	return wrapTransport(client, func(next http.RoundTripper) http.RoundTripper {
+		return &timingTransport{next: next, timings: timings}
+	})
+}
+
+type timingTransport struct {
+	next    http.RoundTripper
+	timings *provider.RequestTimings
+}
+
+func (t *timingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.timings.MarkSent()
+	resp, err := t.next.RoundTrip(req)
+	if err == nil {
+		t.timings.MarkFirstByte()
+	}
+	return resp, err
+}
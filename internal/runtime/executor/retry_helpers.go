@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/resilience"
 	"github.com/tidwall/gjson"
 )
@@ -61,7 +64,7 @@ func DefaultRetryConfig() RetryConfig {
 		MaxDelay:         RateLimitMaxDelay,
 		FallbackDelay:    100 * time.Millisecond,
 		RetryStatusCodes: []int{500},
-		FallbackCodes:    []int{429, 502, 503, 504},
+		FallbackCodes:    []int{429, 502, 503, 504, provider.StatusOverloaded},
 		RetryOnErrors:    true,
 	}
 }
@@ -73,7 +76,7 @@ func AntigravityRetryConfig() RetryConfig {
 		MaxDelay:         AntigravityRetryMaxDelay,
 		FallbackDelay:    0, // No delay: sandbox/prod URLs have independent rate limits
 		RetryStatusCodes: []int{500},
-		FallbackCodes:    []int{http.StatusInternalServerError, 429, 502, 503, 504},
+		FallbackCodes:    []int{http.StatusInternalServerError, 429, 502, 503, 504, provider.StatusOverloaded},
 		RetryOnErrors:    true,
 	}
 }
@@ -92,7 +95,7 @@ func NewRetryHandler(cfg RetryConfig) *RetryHandler {
 		cfg.RetryStatusCodes = []int{500}
 	}
 	if len(cfg.FallbackCodes) == 0 {
-		cfg.FallbackCodes = []int{429, 502, 503, 504}
+		cfg.FallbackCodes = []int{429, 502, 503, 504, provider.StatusOverloaded}
 	}
 
 	return &RetryHandler{
@@ -347,6 +350,26 @@ func ParseRetryDelay(errorBody []byte) (*time.Duration, error) {
 	return parseRetryDelay(errorBody)
 }
 
+// ParseRetryAfterHeader reads a Retry-After header given in seconds or as an
+// HTTP date, returning nil when it is missing or already passed.
+func ParseRetryAfterHeader(h http.Header) *time.Duration {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return nil
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(v); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if at, err := http.ParseTime(v); err == nil {
+		d = time.Until(at)
+	}
+	if d <= 0 {
+		return nil
+	}
+	d = capQuotaDelay(d)
+	return &d
+}
+
 func ParseQuotaRetryDelay(errorBody []byte) *time.Duration {
 	paths := []string{
 		"error.details",
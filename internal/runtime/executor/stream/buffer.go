@@ -128,12 +128,17 @@ func MergeGeminiFinishChunk(chunk []byte, finishEvent *ir.UnifiedEvent) ([]byte,
 		chunk = chunk[:len(chunk)-1]
 	}
 
-	finishReason := MapFinishReasonToGemini(finishEvent.FinishReason)
+	finishReason := ir.MapFinishReasonToGemini(finishEvent.FinishReason)
 
 	result, err := sjson.SetBytes(chunk, "candidates.0.finishReason", finishReason)
 	if err != nil {
 		return nil, err
 	}
+	if finishEvent.NativeFinishReason != "" {
+		if result, err = sjson.SetBytes(result, "candidates.0.nativeFinishReason", finishEvent.NativeFinishReason); err != nil {
+			return nil, err
+		}
+	}
 
 	if finishEvent.Usage != nil {
 		usageMetadata := map[string]any{
@@ -155,26 +160,3 @@ func MergeGeminiFinishChunk(chunk []byte, finishEvent *ir.UnifiedEvent) ([]byte,
 
 	return append(result, '\n'), nil
 }
-
-func MapFinishReasonToGemini(reason ir.FinishReason) string {
-	switch reason {
-	case ir.FinishReasonStop, ir.FinishReasonStopSequence:
-		return "STOP"
-	case ir.FinishReasonMaxTokens:
-		return "MAX_TOKENS"
-	case ir.FinishReasonToolCalls:
-		return "STOP"
-	case ir.FinishReasonContentFilter:
-		return "SAFETY"
-	case ir.FinishReasonRecitation:
-		return "RECITATION"
-	case ir.FinishReasonBlocklist:
-		return "BLOCKLIST"
-	case ir.FinishReasonProhibitedContent:
-		return "PROHIBITED_CONTENT"
-	case ir.FinishReasonSPII:
-		return "SPII"
-	default:
-		return "STOP"
-	}
-}
@@ -0,0 +1,102 @@
+package stream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestStreamTranslator_MultipleChoices(t *testing.T) {
+	upstream := []string{
+		`data: {"id":"c","choices":[{"index":0,"delta":{"role":"assistant","content":"Hi"}},{"index":1,"delta":{"role":"assistant","content":"Hello"}}]}`,
+		`data: {"id":"c","choices":[{"index":1,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"f","arguments":"{}"}}]}}]}`,
+		`data: {"id":"c","choices":[{"index":1,"delta":{},"finish_reason":"tool_calls"}]}`,
+		`data: {"id":"c","choices":[{"index":0,"delta":{"content":" there"}}]}`,
+		`data: {"id":"c","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+	}
+	chunks := translateChoices(t, upstream, nil)
+
+	content := map[int64]string{}
+	finish := map[int64]string{}
+	for _, c := range chunks {
+		choice := c.Get("choices.0")
+		i := choice.Get("index").Int()
+		content[i] += choice.Get("delta.content").String()
+		if fr := choice.Get("finish_reason").String(); fr != "" {
+			finish[i] = fr
+		}
+		if tc := choice.Get("delta.tool_calls.0"); tc.Exists() && (i != 1 || tc.Get("index").Int() != 0) {
+			t.Errorf("tool call rendered on choice %d index %d", i, tc.Get("index").Int())
+		}
+	}
+	if content[0] != "Hi there" || content[1] != "Hello" {
+		t.Errorf("content = %q", content)
+	}
+	if finish[0] != "stop" || finish[1] != "tool_calls" {
+		t.Errorf("finish = %q", finish)
+	}
+}
+
+func TestStreamTranslator_MultipleChoicesStopSequences(t *testing.T) {
+	upstream := []string{
+		`data: {"id":"c","choices":[{"index":0,"delta":{"role":"assistant","content":"Hi E"}},{"index":1,"delta":{"role":"assistant","content":"Hello E"}}]}`,
+		`data: {"id":"c","choices":[{"index":0,"delta":{"content":"ND more"}}]}`,
+		`data: {"id":"c","choices":[{"index":1,"delta":{"content":"nd world"}}]}`,
+		`data: {"id":"c","choices":[{"index":0,"delta":{},"finish_reason":"length"}]}`,
+		`data: {"id":"c","choices":[{"index":1,"delta":{"content":" E"}}]}`,
+		`data: {"id":"c","choices":[{"index":1,"delta":{},"finish_reason":"stop"}]}`,
+	}
+	ctx := NewStreamContext()
+	ctx.StopSequences = []string{"END"}
+	chunks := translateChoices(t, upstream, ctx)
+
+	content := map[int64]string{}
+	finish := map[int64]string{}
+	for _, c := range chunks {
+		choice := c.Get("choices.0")
+		i := choice.Get("index").Int()
+		content[i] += choice.Get("delta.content").String()
+		if fr := choice.Get("finish_reason").String(); fr != "" {
+			finish[i] = fr
+		}
+	}
+	if content[0] != "Hi " || content[1] != "Hello End world E" {
+		t.Errorf("content = %q", content)
+	}
+	if finish[0] != "stop" || finish[1] != "stop" {
+		t.Errorf("finish = %q", finish)
+	}
+}
+
+// translateChoices runs OpenAI chunks through a translator to OpenAI and
+// returns the parsed output chunks, including the flushed ones.
+func translateChoices(t *testing.T, upstream []string, ctx *StreamContext) []gjson.Result {
+	t.Helper()
+	tr := NewStreamTranslator(nil, provider.FormatOpenAI, "openai", "gpt", "chatcmpl-1", ctx)
+	var out [][]byte
+	for _, line := range upstream {
+		events, err := to_ir.ParseOpenAIChunk([]byte(line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := tr.Translate(events)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, res.Chunks...)
+	}
+	flushed, err := tr.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var chunks []gjson.Result
+	for _, c := range append(out, flushed...) {
+		if data := strings.TrimSpace(strings.TrimPrefix(string(c), "data: ")); data != "" && data != "[DONE]" {
+			chunks = append(chunks, gjson.Parse(data))
+		}
+	}
+	return chunks
+}
@@ -0,0 +1,73 @@
+package stream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+const geminiCodeExecutionResponse = `{"candidates":[{"content":{"role":"model","parts":[
+	{"text":"Computing."},
+	{"executableCode":{"language":"PYTHON","code":"print(1+1)"}},
+	{"codeExecutionResult":{"outcome":"OUTCOME_OK","output":"2\n"}}
+]},"finishReason":"STOP"}]}`
+
+func TestTranslateResponseNonStream_CodeExecutionAsMarkdown(t *testing.T) {
+	out, err := TranslateResponseNonStream(nil, provider.FormatGemini, provider.FormatOpenAI, []byte(geminiCodeExecutionResponse), "gemini-2.5-pro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := gjson.GetBytes(out, "choices.0.message.content").String()
+	for _, want := range []string{"Computing.", "```python\nprint(1+1)\n```", "Output:\n```\n2\n```"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("content %q missing %q", content, want)
+		}
+	}
+}
+
+func TestStreamTranslator_CodeExecutionEvents(t *testing.T) {
+	event := func() *ir.UnifiedEvent {
+		return &ir.UnifiedEvent{Type: ir.EventTypeCodeExecution, CodeExecution: &ir.CodeExecutionPart{Outcome: ir.OutcomeFailed, Output: "boom"}}
+	}
+
+	claude := NewStreamTranslator(nil, provider.FormatGemini, "claude", "gemini-2.5-pro", "msg-1", nil)
+	ev := event()
+	if _, err := claude.Translate([]*ir.UnifiedEvent{ev}); err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != ir.EventTypeToken || !strings.Contains(ev.Content, "Execution failed (failed):\n```\nboom\n```") {
+		t.Errorf("claude event = %+v", ev)
+	}
+
+	gemini := NewStreamTranslator(nil, provider.FormatGemini, "gemini", "gemini-2.5-pro", "", nil)
+	ev = event()
+	if _, err := gemini.Translate([]*ir.UnifiedEvent{ev}); err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != ir.EventTypeCodeExecution {
+		t.Errorf("gemini client lost code execution event: %+v", ev)
+	}
+}
+
+func TestParseRequest_ToolChoiceRequiredMapsToGeminiAny(t *testing.T) {
+	req, err := to_ir.ParseOpenAIRequest([]byte(`{"model":"gemini-2.5-pro","messages":[{"role":"user","content":"hi"}],
+		"tool_choice":"required","tools":[{"type":"code_execution"},{"type":"function","function":{"name":"lookup","parameters":{"type":"object"}}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := (&from_ir.GeminiProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := gjson.GetBytes(out, "toolConfig.functionCallingConfig.mode").String(); mode != "ANY" {
+		t.Errorf("mode = %q", mode)
+	}
+	if !gjson.GetBytes(out, "tools.0.codeExecution").Exists() {
+		t.Errorf("codeExecution tool missing: %s", out)
+	}
+}
@@ -0,0 +1,103 @@
+package stream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestTranslateResponseNonStream_FinishReason(t *testing.T) {
+	const (
+		gemini = `{"candidates":[{"content":{"role":"model","parts":[{"text":"Once upon"}]},"finishReason":"RECITATION"}]}`
+		claude = `{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"I can't help with that."}],"stop_reason":"refusal"}`
+		openai = `{"id":"c","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"Long"},"finish_reason":"length"}]}`
+	)
+	tests := []struct {
+		name     string
+		from, to provider.Format
+		response string
+		want     map[string]string // path -> value
+	}{
+		{"gemini to openai", provider.FormatGemini, provider.FormatOpenAI, gemini, map[string]string{
+			"choices.0.finish_reason": "content_filter", "choices.0.native_finish_reason": "RECITATION",
+		}},
+		{"gemini to claude", provider.FormatGemini, provider.FormatClaude, gemini, map[string]string{
+			"stop_reason": "refusal", "native_finish_reason": "RECITATION",
+		}},
+		{"claude to openai", provider.FormatClaude, provider.FormatOpenAI, claude, map[string]string{
+			"choices.0.finish_reason": "stop", "choices.0.native_finish_reason": "refusal",
+		}},
+		{"claude to gemini", provider.FormatClaude, provider.FormatGemini, claude, map[string]string{
+			"candidates.0.finishReason": "SAFETY", "candidates.0.nativeFinishReason": "refusal",
+		}},
+		{"openai to responses", provider.FormatOpenAI, provider.FormatCodex, openai, map[string]string{
+			"status": "incomplete", "incomplete_details.reason": "max_output_tokens", "native_finish_reason": "length",
+		}},
+		{"openai to claude", provider.FormatOpenAI, provider.FormatClaude, openai, map[string]string{
+			"stop_reason": "max_tokens", "native_finish_reason": "length",
+		}},
+		{"openai to ollama", provider.FormatOpenAI, provider.FormatOllama, openai, map[string]string{
+			"done_reason": "length", "native_finish_reason": "length",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := TranslateResponseNonStream(nil, tt.from, tt.to, []byte(tt.response), "m")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for path, want := range tt.want {
+				if got := gjson.GetBytes(out, path).String(); got != want {
+					t.Errorf("%s = %q, want %q in %s", path, got, want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamTranslator_FinishReason(t *testing.T) {
+	events, err := to_ir.ParseGeminiChunk([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hi"}]},"finishReason":"SAFETY"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := NewStreamTranslator(nil, provider.FormatGemini, "claude", "m", "msg-1", nil)
+	res, err := tr.Translate(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var delta gjson.Result
+	for _, c := range res.Chunks {
+		for _, line := range strings.Split(string(c), "\n") {
+			if d := gjson.Parse(strings.TrimPrefix(line, "data: ")); d.Get("type").String() == "message_delta" {
+				delta = d.Get("delta")
+			}
+		}
+	}
+	if delta.Get("stop_reason").String() != "refusal" || delta.Get("native_finish_reason").String() != "SAFETY" {
+		t.Errorf("message_delta = %s", delta.Raw)
+	}
+
+	// OpenAI finishes a streamed refusal with "stop".
+	tr = NewStreamTranslator(nil, provider.FormatOpenAI, "claude", "m", "msg-2", nil)
+	for _, line := range []string{
+		`data: {"id":"c","choices":[{"index":0,"delta":{"role":"assistant","refusal":"No."}}]}`,
+		`data: {"id":"c","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+	} {
+		events, err := to_ir.ParseOpenAIChunk([]byte(line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ev := range events {
+			if _, err := tr.Translate([]*ir.UnifiedEvent{ev}); err != nil {
+				t.Fatal(err)
+			}
+			if ev.Type == ir.EventTypeFinish && ev.FinishReason != ir.FinishReasonRefusal {
+				t.Errorf("finish reason = %q, want refusal", ev.FinishReason)
+			}
+		}
+	}
+}
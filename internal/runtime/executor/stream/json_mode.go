@@ -0,0 +1,145 @@
+package stream
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+)
+
+// IsJSONObjectRequest reports whether an OpenAI-format request asked for
+// response_format {"type":"json_object"}.
+func IsJSONObjectRequest(payload []byte) bool {
+	return gjson.GetBytes(payload, "response_format.type").String() == "json_object"
+}
+
+type jsonModePhase int
+
+const (
+	jsonModeLeading jsonModePhase = iota // before the first '{' or '['
+	jsonModeBody                         // inside the JSON document
+	jsonModeDone                         // document closed, drop the rest
+)
+
+// JSONModeEventBuffer keeps only the JSON document from streamed text tokens.
+// Gemini sometimes wraps JSON-mode output in markdown fences or adds commentary;
+// text before the first '{' or '[' and after the matching close is dropped while
+// the document itself streams through unchanged. If no document ever starts, the
+// held text is released before the finish event so nothing is silently lost.
+// Each choice of an n>1 stream holds its own document.
+type JSONModeEventBuffer struct {
+	choices map[int]*jsonModeChoice
+}
+
+type jsonModeChoice struct {
+	phase    jsonModePhase
+	lead     strings.Builder
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+func NewJSONModeEventBuffer() *JSONModeEventBuffer {
+	return &JSONModeEventBuffer{choices: make(map[int]*jsonModeChoice)}
+}
+
+func (b *JSONModeEventBuffer) choice(index int) *jsonModeChoice {
+	c := b.choices[index]
+	if c == nil {
+		c = &jsonModeChoice{}
+		b.choices[index] = c
+	}
+	return c
+}
+
+func (b *JSONModeEventBuffer) Process(event *ir.UnifiedEvent) []*ir.UnifiedEvent {
+	switch event.Type {
+	case ir.EventTypeToken:
+		text := b.choice(event.ChoiceIndex).filter(event.Content)
+		if text == "" {
+			return nil
+		}
+		event.Content = text
+		return []*ir.UnifiedEvent{event}
+	case ir.EventTypeFinish, ir.EventTypeChoiceFinish:
+		if pending := b.choice(event.ChoiceIndex).releaseLead(event.ChoiceIndex); pending != nil {
+			return []*ir.UnifiedEvent{pending, event}
+		}
+	}
+	return []*ir.UnifiedEvent{event}
+}
+
+func (b *JSONModeEventBuffer) Flush() []*ir.UnifiedEvent {
+	var out []*ir.UnifiedEvent
+	for _, index := range slices.Sorted(maps.Keys(b.choices)) {
+		if pending := b.choices[index].releaseLead(index); pending != nil {
+			out = append(out, pending)
+		}
+	}
+	return out
+}
+
+// releaseLead returns the held prefix as a token event when no JSON was found.
+func (b *jsonModeChoice) releaseLead(index int) *ir.UnifiedEvent {
+	if b.phase != jsonModeLeading || b.lead.Len() == 0 {
+		return nil
+	}
+	text := b.lead.String()
+	b.lead.Reset()
+	return &ir.UnifiedEvent{Type: ir.EventTypeToken, Content: text, ChoiceIndex: index}
+}
+
+// filter returns the part of text that belongs to the JSON document.
+// Structural characters are ASCII, so scanning bytes is safe for UTF-8 input.
+func (b *jsonModeChoice) filter(text string) string {
+	if b.phase == jsonModeDone {
+		return ""
+	}
+	start, end := 0, len(text)
+scan:
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch b.phase {
+		case jsonModeLeading:
+			if c != '{' && c != '[' {
+				b.lead.WriteByte(c)
+				continue
+			}
+			b.phase = jsonModeBody
+			b.lead.Reset()
+			b.depth = 1
+			start = i
+		case jsonModeBody:
+			if b.inString {
+				switch {
+				case b.escaped:
+					b.escaped = false
+				case c == '\\':
+					b.escaped = true
+				case c == '"':
+					b.inString = false
+				}
+				continue
+			}
+			switch c {
+			case '"':
+				b.inString = true
+			case '{', '[':
+				b.depth++
+			case '}', ']':
+				b.depth--
+				if b.depth == 0 {
+					b.phase = jsonModeDone
+					end = i + 1
+					break scan
+				}
+			}
+		}
+	}
+	if b.phase == jsonModeLeading {
+		return ""
+	}
+	return text[start:end]
+}
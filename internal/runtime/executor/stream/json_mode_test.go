@@ -0,0 +1,63 @@
+package stream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func collectJSONModeText(chunks []string) (string, int) {
+	buf := NewJSONModeEventBuffer()
+	var sb strings.Builder
+	tokens := 0
+	events := make([]*ir.UnifiedEvent, 0, len(chunks)+1)
+	for _, c := range chunks {
+		events = append(events, &ir.UnifiedEvent{Type: ir.EventTypeToken, Content: c})
+	}
+	events = append(events, &ir.UnifiedEvent{Type: ir.EventTypeFinish, FinishReason: ir.FinishReasonStop})
+
+	var emitted []*ir.UnifiedEvent
+	for _, ev := range events {
+		emitted = append(emitted, buf.Process(ev)...)
+	}
+	emitted = append(emitted, buf.Flush()...)
+	for _, ev := range emitted {
+		if ev.Type == ir.EventTypeToken {
+			sb.WriteString(ev.Content)
+			tokens++
+		}
+	}
+	return sb.String(), tokens
+}
+
+func TestJSONModeEventBuffer_StripsFencesAcrossChunks(t *testing.T) {
+	got, _ := collectJSONModeText([]string{"```js", "on\n{\"a\": ", "\"}\\\" {\"", ", \"b\": [1, 2]}\n`", "``\nHope this helps!"})
+	want := `{"a": "}\" {", "b": [1, 2]}`
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestJSONModeEventBuffer_PassesCleanJSON(t *testing.T) {
+	got, tokens := collectJSONModeText([]string{`{"x":`, `1}`})
+	if got != `{"x":1}` || tokens != 2 {
+		t.Errorf("got %q in %d tokens", got, tokens)
+	}
+}
+
+func TestJSONModeEventBuffer_ReleasesTextWithoutJSON(t *testing.T) {
+	got, _ := collectJSONModeText([]string{"I cannot ", "answer that."})
+	if got != "I cannot answer that." {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestIsJSONObjectRequest(t *testing.T) {
+	if !IsJSONObjectRequest([]byte(`{"response_format":{"type":"json_object"}}`)) {
+		t.Error("expected json_object request to be detected")
+	}
+	if IsJSONObjectRequest([]byte(`{"response_format":{"type":"json_schema"}}`)) {
+		t.Error("json_schema should not enable JSON repair")
+	}
+}
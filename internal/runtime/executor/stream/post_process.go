@@ -0,0 +1,171 @@
+package stream
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/sseutil"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// postProcessRule returns the first post-processing rule matching model.
+func postProcessRule(cfg *config.Config, model string) *config.PostProcessRule {
+	if cfg == nil {
+		return nil
+	}
+	for i := range cfg.PostProcessing {
+		for _, pattern := range cfg.PostProcessing[i].Models {
+			if sseutil.MatchModelPattern(pattern, model) {
+				return &cfg.PostProcessing[i]
+			}
+		}
+	}
+	return nil
+}
+
+// HasPostProcessing reports whether responses for model are post-processed.
+// Passthrough paths must then translate through IR so the rule is applied.
+func HasPostProcessing(cfg *config.Config, model string) bool {
+	return postProcessRule(cfg, model) != nil
+}
+
+// applyPostProcessing rewrites the text parts of assistant messages: strip
+// strings are removed everywhere, the prefix goes before the first text part
+// and the suffix after the last.
+func applyPostProcessing(rule *config.PostProcessRule, msgs []ir.Message) {
+	var first, last *ir.ContentPart
+	for i := range msgs {
+		if msgs[i].Role != ir.RoleAssistant {
+			continue
+		}
+		for j := range msgs[i].Content {
+			part := &msgs[i].Content[j]
+			if part.Type != ir.ContentTypeText {
+				continue
+			}
+			for _, s := range rule.Strip {
+				part.Text = strings.ReplaceAll(part.Text, s, "")
+			}
+			if first == nil {
+				first = part
+			}
+			last = part
+		}
+	}
+	if first == nil {
+		return
+	}
+	before, after := rule.Around()
+	first.Text = before + first.Text
+	last.Text += after
+}
+
+// PostProcessEventBuffer applies a post-processing rule to streamed text.
+// Text that could be the start of a strip string is held back until the next
+// token decides it. The prefix is emitted with the first text and the suffix
+// before the finish event; streams without text are left unchanged. Each
+// choice of an n>1 stream gets its own prefix and suffix.
+type PostProcessEventBuffer struct {
+	strip         []string
+	before, after string
+	choices       map[int]*postProcessChoice
+}
+
+type postProcessChoice struct {
+	pending string
+	started bool
+	ended   bool
+}
+
+func NewPostProcessEventBuffer(rule config.PostProcessRule) *PostProcessEventBuffer {
+	before, after := rule.Around()
+	return &PostProcessEventBuffer{strip: rule.Strip, before: before, after: after, choices: make(map[int]*postProcessChoice)}
+}
+
+func (b *PostProcessEventBuffer) choice(index int) *postProcessChoice {
+	c := b.choices[index]
+	if c == nil {
+		c = &postProcessChoice{}
+		b.choices[index] = c
+	}
+	return c
+}
+
+func (b *PostProcessEventBuffer) Process(event *ir.UnifiedEvent) []*ir.UnifiedEvent {
+	switch event.Type {
+	case ir.EventTypeToken:
+		c := b.choice(event.ChoiceIndex)
+		text := c.pending + event.Content
+		for _, s := range b.strip {
+			text = strings.ReplaceAll(text, s, "")
+		}
+		hold := holdbackLen(text, b.strip)
+		c.pending = text[len(text)-hold:]
+		text = text[:len(text)-hold]
+		if text == "" {
+			return nil
+		}
+		if !c.started {
+			c.started = true
+			text = b.before + text
+		}
+		event.Content = text
+		return []*ir.UnifiedEvent{event}
+	case ir.EventTypeFinish, ir.EventTypeChoiceFinish:
+		if end := b.end(event.ChoiceIndex); end != nil {
+			return []*ir.UnifiedEvent{end, event}
+		}
+		return []*ir.UnifiedEvent{event}
+	case ir.EventTypeStreamMeta, ir.EventTypeError, ir.EventTypeReasoning, ir.EventTypeReasoningSummary:
+		return []*ir.UnifiedEvent{event}
+	default:
+		if pending := b.releasePending(event.ChoiceIndex); pending != nil {
+			return []*ir.UnifiedEvent{pending, event}
+		}
+		return []*ir.UnifiedEvent{event}
+	}
+}
+
+func (b *PostProcessEventBuffer) Flush() []*ir.UnifiedEvent {
+	var out []*ir.UnifiedEvent
+	for _, index := range slices.Sorted(maps.Keys(b.choices)) {
+		if end := b.end(index); end != nil {
+			out = append(out, end)
+		}
+	}
+	return out
+}
+
+func (b *PostProcessEventBuffer) releasePending(index int) *ir.UnifiedEvent {
+	c := b.choice(index)
+	if c.pending == "" {
+		return nil
+	}
+	text := c.pending
+	c.pending = ""
+	if !c.started {
+		c.started = true
+		text = b.before + text
+	}
+	return &ir.UnifiedEvent{Type: ir.EventTypeToken, Content: text, ChoiceIndex: index}
+}
+
+// end releases held-back text followed by the suffix, once per choice.
+func (b *PostProcessEventBuffer) end(index int) *ir.UnifiedEvent {
+	ev := b.releasePending(index)
+	c := b.choice(index)
+	if !c.started || c.ended {
+		return ev
+	}
+	c.ended = true
+	if b.after == "" {
+		return ev
+	}
+	if ev == nil {
+		ev = &ir.UnifiedEvent{Type: ir.EventTypeToken, ChoiceIndex: index}
+	}
+	ev.Content += b.after
+	return ev
+}
@@ -0,0 +1,66 @@
+package stream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestPostProcessEventBuffer(t *testing.T) {
+	rule := config.PostProcessRule{
+		Models: []string{"*"},
+		Strip:  []string{"[AD]"},
+		Prefix: ">> ",
+		Wrap:   "<a>{{content}}</a>",
+		Suffix: " <<",
+	}
+	buf := NewPostProcessEventBuffer(rule)
+	var out []*ir.UnifiedEvent
+	for _, c := range []string{"hel", "lo [A", "D] world ["} {
+		out = append(out, buf.Process(&ir.UnifiedEvent{Type: ir.EventTypeToken, Content: c})...)
+	}
+	out = append(out, buf.Process(&ir.UnifiedEvent{Type: ir.EventTypeFinish, FinishReason: ir.FinishReasonStop})...)
+	out = append(out, buf.Flush()...)
+
+	var sb strings.Builder
+	for _, ev := range out {
+		if ev.Type == ir.EventTypeToken {
+			sb.WriteString(ev.Content)
+		}
+	}
+	if got, want := sb.String(), ">> <a>hello  world [</a> <<"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	if last := out[len(out)-1]; last.Type != ir.EventTypeFinish {
+		t.Errorf("last event = %v, want finish", last.Type)
+	}
+
+	// Streams without text, such as tool-call-only turns, get no prefix or suffix.
+	buf = NewPostProcessEventBuffer(rule)
+	if got := buf.Process(&ir.UnifiedEvent{Type: ir.EventTypeFinish}); len(got) != 1 {
+		t.Errorf("finish without text emitted %d events", len(got))
+	}
+}
+
+func TestTranslateResponseNonStreamPostProcessing(t *testing.T) {
+	cfg := &config.Config{PostProcessing: []config.PostProcessRule{
+		{Models: []string{"gpt-*"}, Strip: []string{" (generated)"}, Suffix: "\n--"},
+	}}
+	resp := []byte(`{"id":"x","choices":[{"index":0,"message":{"role":"assistant","content":"hi (generated)"},"finish_reason":"stop"}]}`)
+
+	out, err := TranslateResponseNonStream(cfg, "openai", "openai", resp, "gpt-4o")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gjson.GetBytes(out, "choices.0.message.content").String(); got != "hi\n--" {
+		t.Errorf("content = %q", got)
+	}
+
+	out, _ = TranslateResponseNonStream(cfg, "openai", "openai", resp, "claude-sonnet-4")
+	if string(out) != string(resp) {
+		t.Errorf("unmatched model was rewritten: %s", out)
+	}
+}
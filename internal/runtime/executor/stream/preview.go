@@ -0,0 +1,37 @@
+package stream
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// ErrPreviewUnsupported is returned by TranslateForProvider for providers
+// whose upstream format has no standalone translation.
+var ErrPreviewUnsupported = errors.New("request preview is not supported for this provider")
+
+// TranslateForProvider translates payload from the client format as the
+// executor of providerID does before sending it, and returns the upstream
+// format with the translated body. Executor-specific envelopes, headers and
+// model aliases are not applied.
+func TranslateForProvider(ctx context.Context, cfg *config.Config, providerID string, from provider.Format, model string, payload []byte, metadata map[string]any) (string, []byte, error) {
+	switch providerID {
+	case constant.Kiro:
+		return "", nil, ErrPreviewUnsupported
+	case constant.Claude:
+		out, err := TranslateToClaude(ctx, cfg, from, model, payload, false, metadata)
+		return constant.Claude, out, err
+	case constant.Codex:
+		out, err := TranslateToCodex(ctx, cfg, from, model, payload, false, metadata)
+		return constant.Codex, out, err
+	case constant.Gemini, constant.GeminiCLI, constant.Antigravity, "vertex", "aistudio":
+		out, err := TranslateToGemini(ctx, cfg, from, model, payload, false, metadata)
+		return constant.Gemini, out, err
+	default:
+		out, err := TranslateToOpenAI(ctx, cfg, from, model, payload, false, metadata)
+		return constant.OpenAI, out, err
+	}
+}
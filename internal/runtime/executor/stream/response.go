@@ -48,19 +48,39 @@ func (t *ResponseTranslator) Translate(candidates []ir.CandidateResult, usage *i
 		t.messageID = meta.ResponseID
 	}
 
+	// Only Gemini clients have code execution parts; others get them as Markdown.
+	if !provider.IsGeminiFormat(t.to) {
+		for i := range candidates {
+			ir.InlineCodeExecution(candidates[i].Messages)
+		}
+	}
+
+	if rule := postProcessRule(t.cfg, t.model); rule != nil {
+		for i := range candidates {
+			applyPostProcessing(rule, candidates[i].Messages)
+		}
+	}
+
 	// Extract messages from first candidate for formats that don't support multi-candidate
 	var messages []ir.Message
 	if len(candidates) > 0 {
 		messages = candidates[0].Messages
+		if meta == nil {
+			meta = &ir.OpenAIMeta{}
+		}
+		meta.FinishReason = candidates[0].FinishReason
+		if candidates[0].NativeFinishReason != "" {
+			meta.NativeFinishReason = candidates[0].NativeFinishReason
+		}
 	}
 
 	switch {
 	case t.to == "openai" || t.to == "cline":
 		return from_ir.ToOpenAIChatCompletionCandidates(candidates, usage, t.model, t.messageID, meta)
 	case t.to == "claude":
-		return from_ir.ToClaudeResponse(messages, usage, t.model, t.messageID)
+		return from_ir.ToClaudeResponseMeta(messages, usage, t.model, t.messageID, meta)
 	case t.to == "ollama":
-		return from_ir.ToOllamaChatResponse(messages, usage, t.model)
+		return from_ir.ToOllamaChatResponseMeta(messages, usage, t.model, meta)
 	case provider.IsGeminiFormat(t.to):
 		return from_ir.ToGeminiResponseMeta(messages, usage, t.model, meta)
 	case t.to == "codex" || t.to == "openai-response":
@@ -88,7 +108,8 @@ func parseOpenAIResponse(response []byte) (*ParsedResponse, error) {
 		return nil, err
 	}
 	// Wrap in single candidate
-	candidates := []ir.CandidateResult{{Index: 0, Messages: messages, FinishReason: ir.FinishReasonStop}}
+	fr, native := to_ir.ParseOpenAIFinishReason(response)
+	candidates := []ir.CandidateResult{{Index: 0, Messages: messages, FinishReason: fr, NativeFinishReason: native}}
 	return &ParsedResponse{Candidates: candidates, Usage: usage}, nil
 }
 
@@ -98,7 +119,8 @@ func parseClaudeResponse(response []byte) (*ParsedResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-	candidates := []ir.CandidateResult{{Index: 0, Messages: messages, FinishReason: ir.FinishReasonStop}}
+	fr, native := to_ir.ParseClaudeFinishReason(response)
+	candidates := []ir.CandidateResult{{Index: 0, Messages: messages, FinishReason: fr, NativeFinishReason: native}}
 	return &ParsedResponse{Candidates: candidates, Usage: usage}, nil
 }
 
@@ -134,9 +156,11 @@ func TranslateResponseNonStream(cfg *config.Config, from, to provider.Format, re
 	fromStr := from.String()
 	toStr := to.String()
 
-	// Handle passthrough cases
-	if passthrough := handlePassthrough(fromStr, toStr, response); passthrough != nil {
-		return passthrough, nil
+	// Handle passthrough cases; post-processed responses go through IR
+	if !HasPostProcessing(cfg, model) {
+		if passthrough := handlePassthrough(fromStr, toStr, response); passthrough != nil {
+			return passthrough, nil
+		}
 	}
 
 	// Parse source format to IR
@@ -26,6 +26,11 @@ type UsageReporter interface {
 	EnsurePublished(ctx context.Context)
 }
 
+// firstTokenMarker is implemented by reporters that measure time to first token.
+type firstTokenMarker interface {
+	MarkFirstToken()
+}
+
 const (
 	DefaultStreamBufferSize  = 2 * 1024 * 1024 // 2MB
 	DefaultScannerBufferSize = 1024 * 1024     // 1MB - single user, maximize for single stream
@@ -253,17 +258,9 @@ func RunSSEStream(
 				if reporter != nil {
 					reporter.PublishFailure(ctx)
 				}
-				if processor != nil {
-					if flushed, _ := processor.ProcessDone(); len(flushed) > 0 {
-						for _, chunk := range flushed {
-							if !pipeline.SendData(chunk) {
-								return nil
-							}
-						}
-					}
-				}
-				errorJSON := fmt.Sprintf("event: error\ndata: {\"type\": \"error\", \"error\": {\"type\": \"server_error\", \"message\": \"%s\"}}\n\n", escapeJSONString(err.Error()))
-				pipeline.SendData([]byte(errorJSON))
+				// The handler ends the stream with an error in the client's format;
+				// flushing the processor here would emit a normal finish first.
+				pipeline.SendError(err)
 				return nil
 			}
 
@@ -272,6 +269,9 @@ func RunSSEStream(
 			}
 
 			if len(chunks) > 0 {
+				if marker, ok := reporter.(firstTokenMarker); ok {
+					marker.MarkFirstToken()
+				}
 				for _, chunk := range chunks {
 					if !pipeline.SendData(chunk) {
 						return nil
@@ -284,6 +284,14 @@ func RunSSEStream(
 			}
 		}
 
+		if errScan := scanner.Err(); errScan != nil && !isExpectedEOF(errScan) {
+			if reporter != nil {
+				reporter.PublishFailure(ctx)
+			}
+			pipeline.SendError(fmt.Errorf("upstream stream interrupted: %w", errScan))
+			return nil
+		}
+
 		if processor != nil {
 			doneChunks, doneErr := processor.ProcessDone()
 			if doneErr != nil {
@@ -300,15 +308,6 @@ func RunSSEStream(
 			}
 		}
 
-		if errScan := scanner.Err(); errScan != nil && !isExpectedEOF(errScan) {
-			if reporter != nil {
-				reporter.PublishFailure(ctx)
-			}
-			errorJSON := fmt.Sprintf("event: error\ndata: {\"type\": \"error\", \"error\": {\"type\": \"server_error\", \"message\": \"%s\"}}\n\n", escapeJSONString(errScan.Error()))
-			pipeline.SendData([]byte(errorJSON))
-			return nil
-		}
-
 		if cfg.EnsurePublished && reporter != nil {
 			reporter.EnsurePublished(ctx)
 		}
@@ -453,14 +452,3 @@ func ConvertPipelineToStreamChunk(ctx context.Context, input <-chan streamutil.C
 	}()
 	return out
 }
-
-func escapeJSONString(s string) string {
-	r := strings.NewReplacer(
-		`\`, `\\`,
-		`"`, `\"`,
-		"\n", `\n`,
-		"\r", `\r`,
-		"\t", `\t`,
-	)
-	return r.Replace(s)
-}
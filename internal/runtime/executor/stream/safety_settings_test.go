@@ -0,0 +1,57 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestTranslateToGeminiSafetySettings(t *testing.T) {
+	cfg := &config.Config{Payload: config.PayloadConfig{Default: []config.PayloadRule{{
+		Models: []config.PayloadModelRule{{Name: "gemini-*"}},
+		Params: map[string]any{"safetySettings": []map[string]string{
+			{"category": "HARM_CATEGORY_HARASSMENT", "threshold": "BLOCK_NONE"},
+		}},
+	}}}}
+	from := provider.FromString("openai")
+	body := []byte(`{"model":"gemini-2.5-pro","messages":[{"role":"user","content":"hi"}]}`)
+
+	res, err := TranslateToGeminiWithTokens(context.Background(), cfg, from, "gemini-2.5-pro", body, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gjson.GetBytes(res.Payload, "safetySettings.#").Int(); got != 1 {
+		t.Errorf("default rule not applied: %s", res.Payload)
+	}
+
+	res, err = TranslateToGeminiWithTokens(context.Background(), cfg, from, "other-model", body, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gjson.GetBytes(res.Payload, "safetySettings.#").Int(); got != int64(len(ir.DefaultGeminiSafetySettings())) {
+		t.Errorf("built-in defaults not applied: %s", res.Payload)
+	}
+
+	client := []byte(`{"model":"gemini-2.5-pro","messages":[{"role":"user","content":"hi"}],
+		"metadata":{"safety_settings":[{"category":"harm_category_hate_speech","threshold":"block_only_high"}]}}`)
+	res, err = TranslateToGeminiWithTokens(context.Background(), cfg, from, "gemini-2.5-pro", client, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gjson.GetBytes(res.Payload, "safetySettings.0.threshold").String(); got != "BLOCK_ONLY_HIGH" {
+		t.Errorf("client settings not passed through: %s", res.Payload)
+	}
+
+	invalid := []byte(`{"model":"gemini-2.5-pro","messages":[{"role":"user","content":"hi"}],
+		"safety_settings":[{"category":"HARM_CATEGORY_HARASSMENT","threshold":"SOMETIMES"}]}`)
+	_, err = TranslateToGeminiWithTokens(context.Background(), cfg, from, "gemini-2.5-pro", invalid, false, nil)
+	var invalidErr *ir.InvalidRequestError
+	if !errors.As(err, &invalidErr) {
+		t.Errorf("invalid threshold error = %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package stream
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+)
+
+// EmulatedStopSequences returns the stop sequences in a client request that the
+// upstream will not enforce itself. nativeLimit is how many sequences the upstream
+// accepts; 0 means it ignores stop sequences entirely.
+func EmulatedStopSequences(payload []byte, nativeLimit int) []string {
+	root := gjson.ParseBytes(payload)
+	stops := ir.ExtractStopSequences(root)
+	if len(stops) == 0 {
+		stops = ir.ExtractStopSequences(root.Get("generationConfig"), "stopSequences")
+	}
+	if nativeLimit > 0 {
+		if len(stops) <= nativeLimit {
+			return nil
+		}
+		stops = stops[nativeLimit:]
+	}
+	out := stops[:0:0]
+	for _, s := range stops {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// TruncateAtStopSequence cuts text at the earliest stop sequence.
+// It reports whether a stop sequence was found.
+func TruncateAtStopSequence(text string, stops []string) (string, bool) {
+	cut := -1
+	for _, s := range stops {
+		if s == "" {
+			continue
+		}
+		if i := strings.Index(text, s); i >= 0 && (cut < 0 || i < cut) {
+			cut = i
+		}
+	}
+	if cut < 0 {
+		return text, false
+	}
+	return text[:cut], true
+}
+
+// StopSequenceEventBuffer emulates stop sequences for upstreams that ignore them.
+// Text that could be the start of a stop sequence is held back until the next
+// token decides it, so matches spanning chunk boundaries are caught. Once a stop
+// sequence is seen the text before it is emitted, later content is dropped, and
+// the upstream finish event is reported as a stop-sequence finish ("stop" for
+// OpenAI clients). Each choice of an n>1 stream is tracked on its own.
+type StopSequenceEventBuffer struct {
+	stops   []string
+	choices map[int]*stopSequenceChoice
+}
+
+type stopSequenceChoice struct {
+	pending string
+	stopped bool
+	finish  bool
+}
+
+func NewStopSequenceEventBuffer(stops []string) *StopSequenceEventBuffer {
+	return &StopSequenceEventBuffer{stops: stops, choices: make(map[int]*stopSequenceChoice)}
+}
+
+func (b *StopSequenceEventBuffer) choice(index int) *stopSequenceChoice {
+	c := b.choices[index]
+	if c == nil {
+		c = &stopSequenceChoice{}
+		b.choices[index] = c
+	}
+	return c
+}
+
+func (b *StopSequenceEventBuffer) Process(event *ir.UnifiedEvent) []*ir.UnifiedEvent {
+	switch event.Type {
+	case ir.EventTypeStreamMeta, ir.EventTypeError, ir.EventTypeReasoning, ir.EventTypeReasoningSummary:
+		return []*ir.UnifiedEvent{event}
+	}
+	c := b.choice(event.ChoiceIndex)
+	switch event.Type {
+	case ir.EventTypeToken:
+		if c.stopped {
+			return nil
+		}
+		text, found := TruncateAtStopSequence(c.pending+event.Content, b.stops)
+		if found {
+			c.stopped = true
+			c.pending = ""
+		} else {
+			hold := holdbackLen(text, b.stops)
+			c.pending = text[len(text)-hold:]
+			text = text[:len(text)-hold]
+		}
+		if text == "" {
+			return nil
+		}
+		event.Content = text
+		return []*ir.UnifiedEvent{event}
+	case ir.EventTypeFinish, ir.EventTypeChoiceFinish:
+		c.finish = true
+		if c.stopped {
+			event.FinishReason = ir.FinishReasonStopSequence
+			return []*ir.UnifiedEvent{event}
+		}
+		if pending := c.releasePending(event.ChoiceIndex); pending != nil {
+			return []*ir.UnifiedEvent{pending, event}
+		}
+		return []*ir.UnifiedEvent{event}
+	default:
+		if c.stopped {
+			return nil
+		}
+		if pending := c.releasePending(event.ChoiceIndex); pending != nil {
+			return []*ir.UnifiedEvent{pending, event}
+		}
+		return []*ir.UnifiedEvent{event}
+	}
+}
+
+func (b *StopSequenceEventBuffer) Flush() []*ir.UnifiedEvent {
+	var out []*ir.UnifiedEvent
+	for _, index := range slices.Sorted(maps.Keys(b.choices)) {
+		c := b.choices[index]
+		if c.stopped && !c.finish {
+			c.finish = true
+			out = append(out, &ir.UnifiedEvent{Type: ir.EventTypeFinish, FinishReason: ir.FinishReasonStopSequence, ChoiceIndex: index})
+			continue
+		}
+		if pending := c.releasePending(index); pending != nil {
+			out = append(out, pending)
+		}
+	}
+	return out
+}
+
+func (c *stopSequenceChoice) releasePending(index int) *ir.UnifiedEvent {
+	if c.pending == "" {
+		return nil
+	}
+	text := c.pending
+	c.pending = ""
+	return &ir.UnifiedEvent{Type: ir.EventTypeToken, Content: text, ChoiceIndex: index}
+}
+
+// holdbackLen returns the length of the longest suffix of text that is a
+// proper prefix of one of seqs.
+func holdbackLen(text string, seqs []string) int {
+	best := 0
+	for _, s := range seqs {
+		n := min(len(s)-1, len(text))
+		for ; n > best; n-- {
+			if strings.HasSuffix(text, s[:n]) {
+				best = n
+				break
+			}
+		}
+	}
+	return best
+}
+
+// chainedEventBuffer feeds the output of one event buffer into the next.
+type chainedEventBuffer struct {
+	first, second EventBufferStrategy
+}
+
+func (c *chainedEventBuffer) Process(event *ir.UnifiedEvent) []*ir.UnifiedEvent {
+	var out []*ir.UnifiedEvent
+	for _, ev := range c.first.Process(event) {
+		out = append(out, c.second.Process(ev)...)
+	}
+	return out
+}
+
+func (c *chainedEventBuffer) Flush() []*ir.UnifiedEvent {
+	var out []*ir.UnifiedEvent
+	for _, ev := range c.first.Flush() {
+		out = append(out, c.second.Process(ev)...)
+	}
+	return append(out, c.second.Flush()...)
+}
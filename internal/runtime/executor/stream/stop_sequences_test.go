@@ -0,0 +1,61 @@
+package stream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func runStopBuffer(stops []string, chunks []string) (string, []*ir.UnifiedEvent) {
+	buf := NewStopSequenceEventBuffer(stops)
+	var emitted []*ir.UnifiedEvent
+	for _, c := range chunks {
+		emitted = append(emitted, buf.Process(&ir.UnifiedEvent{Type: ir.EventTypeToken, Content: c})...)
+	}
+	emitted = append(emitted, buf.Process(&ir.UnifiedEvent{Type: ir.EventTypeFinish, FinishReason: ir.FinishReasonMaxTokens})...)
+	emitted = append(emitted, buf.Flush()...)
+
+	var sb strings.Builder
+	for _, ev := range emitted {
+		if ev.Type == ir.EventTypeToken {
+			sb.WriteString(ev.Content)
+		}
+	}
+	return sb.String(), emitted
+}
+
+func TestStopSequenceEventBuffer_MatchAcrossChunks(t *testing.T) {
+	text, emitted := runStopBuffer([]string{"END"}, []string{"hello E", "N", "D world"})
+	if text != "hello " {
+		t.Errorf("text = %q, want %q", text, "hello ")
+	}
+	last := emitted[len(emitted)-1]
+	if last.Type != ir.EventTypeFinish || last.FinishReason != ir.FinishReasonStopSequence {
+		t.Errorf("expected finish with stop reason, got %v %v", last.Type, last.FinishReason)
+	}
+}
+
+func TestStopSequenceEventBuffer_ReleasesPartialPrefix(t *testing.T) {
+	text, emitted := runStopBuffer([]string{"###"}, []string{"a #", "# b", " c #"})
+	if text != "a ## b c #" {
+		t.Errorf("text = %q", text)
+	}
+	last := emitted[len(emitted)-1]
+	if last.FinishReason != ir.FinishReasonMaxTokens {
+		t.Errorf("finish reason should be untouched without a match, got %v", last.FinishReason)
+	}
+}
+
+func TestEmulatedStopSequences(t *testing.T) {
+	payload := []byte(`{"stop":["a","b","c","d","e","f","g"]}`)
+	if got := EmulatedStopSequences(payload, 5); len(got) != 2 || got[0] != "f" {
+		t.Errorf("expected sequences beyond the native limit, got %v", got)
+	}
+	if got := EmulatedStopSequences(payload, 0); len(got) != 7 {
+		t.Errorf("expected all sequences when unsupported natively, got %v", got)
+	}
+	if got := EmulatedStopSequences([]byte(`{"stop":"x"}`), 5); got != nil {
+		t.Errorf("expected nil within native limit, got %v", got)
+	}
+}
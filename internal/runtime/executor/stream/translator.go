@@ -1,6 +1,8 @@
 package stream
 
 import (
+	"bytes"
+
 	"github.com/nghyane/llm-mux/internal/config"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/translator/from_ir"
@@ -35,10 +37,54 @@ type StreamContext struct {
 	GeminiState          *ir.GeminiStreamParserState
 	ToolCallIndex        int
 	HasToolCalls         bool
+	Refused              bool // the model streamed a refusal, which OpenAI finishes with "stop"
 	FinishSent           bool
 	ReasoningCharsAccum  int
 	ToolSchemaCtx        *ir.ToolSchemaContext
 	EstimatedInputTokens int64
+	JSONMode             bool     // client asked for json_object; strip fences and prose around the document
+	StopSequences        []string // stop sequences the upstream does not enforce; emulated on the stream
+	ResponsesState       *from_ir.ResponsesStreamState
+
+	// choices tracks each choice of an n>1 stream; nil while only choice 0
+	// was seen. Choice 0 always numbers its tool calls with ToolCallIndex.
+	choices map[int]*streamChoice
+}
+
+// streamChoice is the state of one choice of an n>1 stream.
+type streamChoice struct {
+	toolCallIndex int
+	hasToolCalls  bool
+	refused       bool
+	finished      bool
+}
+
+// choice returns the state of choice i, or nil for a single-choice stream.
+func (s *StreamContext) choice(i int) *streamChoice {
+	if s.choices == nil {
+		if i == 0 {
+			return nil
+		}
+		s.choices = map[int]*streamChoice{0: {hasToolCalls: s.HasToolCalls, refused: s.Refused}}
+	}
+	c := s.choices[i]
+	if c == nil {
+		c = &streamChoice{}
+		s.choices[i] = c
+	}
+	return c
+}
+
+// finishChoice marks choice c finished and reports whether other choices are
+// still streaming.
+func (s *StreamContext) finishChoice(c *streamChoice) bool {
+	c.finished = true
+	for _, other := range s.choices {
+		if !other.finished {
+			return true
+		}
+	}
+	return false
 }
 
 func NewStreamContext() *StreamContext {
@@ -110,6 +156,15 @@ func NewStreamTranslator(cfg *config.Config, from provider.Format, to, model, me
 		st.eventBuffer = NewPassthroughEventBuffer()
 		st.chunkBuffer = NewPassthroughBuffer()
 	}
+	if len(Ctx.StopSequences) > 0 {
+		st.eventBuffer = NewStopSequenceEventBuffer(Ctx.StopSequences)
+	}
+	if Ctx.JSONMode {
+		st.eventBuffer = &chainedEventBuffer{first: st.eventBuffer, second: NewJSONModeEventBuffer()}
+	}
+	if rule := postProcessRule(cfg, model); rule != nil {
+		st.eventBuffer = &chainedEventBuffer{first: st.eventBuffer, second: NewPostProcessEventBuffer(*rule)}
+	}
 
 	return st
 }
@@ -218,10 +273,37 @@ func (t *StreamTranslator) Flush() ([][]byte, error) {
 
 // preprocess handles state tracking (tool calls, reasoning, finish dedup)
 func (t *StreamTranslator) preprocess(event *ir.UnifiedEvent) bool {
+	// Hosted tool items (web_search_call, ...) only exist in the Responses API
+	if event.Type == ir.EventTypeHostedToolCall && !isResponsesFormat(t.to) {
+		return true
+	}
+
+	// Only Gemini clients have code execution parts; others get them as Markdown text
+	if event.Type == ir.EventTypeCodeExecution && !provider.IsGeminiFormat(t.to) {
+		event.Type = ir.EventTypeToken
+		event.Content = ir.CodeExecutionText(event.CodeExecution)
+	}
+
+	// Only OpenAI chat chunks can carry more than one choice
+	if event.ChoiceIndex > 0 && t.to != "openai" && t.to != "cline" {
+		return true
+	}
+	choice := t.Ctx.choice(event.ChoiceIndex)
+
 	// Track tool calls - mark HasToolCalls but don't increment index yet
 	// Index increment happens in convertEvent to maintain correct 0-based indexing
 	if event.Type == ir.EventTypeToolCall {
 		t.Ctx.HasToolCalls = true
+		if choice != nil {
+			choice.hasToolCalls = true
+		}
+	}
+
+	if event.Refusal != "" {
+		t.Ctx.Refused = true
+		if choice != nil {
+			choice.refused = true
+		}
 	}
 
 	// Track reasoning content for token estimation
@@ -234,14 +316,21 @@ func (t *StreamTranslator) preprocess(event *ir.UnifiedEvent) bool {
 
 	// Handle finish event with deduplication and token estimation
 	if event.Type == ir.EventTypeFinish {
+		hasToolCalls, refused := t.Ctx.HasToolCalls, t.Ctx.Refused
+		if choice != nil && !choice.finished {
+			hasToolCalls, refused = choice.hasToolCalls, choice.refused
+			// The stream ends with its last choice; the others only close
+			// their own choice.
+			if t.Ctx.finishChoice(choice) {
+				event.Type = ir.EventTypeChoiceFinish
+				event.FinishReason = streamFinishReason(event.FinishReason, hasToolCalls, refused)
+				return false
+			}
+		}
 		if !t.Ctx.MarkFinishSent() {
 			return true // skip duplicate finish
 		}
-
-		// Override finish_reason if tool calls were seen
-		if t.Ctx.HasToolCalls {
-			event.FinishReason = ir.FinishReasonToolCalls
-		}
+		event.FinishReason = streamFinishReason(event.FinishReason, hasToolCalls, refused)
 
 		// Estimate reasoning tokens if provider didn't provide them
 		if t.Ctx.ReasoningCharsAccum > 0 {
@@ -257,18 +346,37 @@ func (t *StreamTranslator) preprocess(event *ir.UnifiedEvent) bool {
 	return false // don't skip
 }
 
+// streamFinishReason refines the upstream's finish reason with what the
+// stream carried: tool calls turn a normal stop into tool_calls, a refusal
+// into refusal. Token limits and content filters are kept.
+func streamFinishReason(reason ir.FinishReason, hasToolCalls, refused bool) ir.FinishReason {
+	switch reason {
+	case "", ir.FinishReasonStop, ir.FinishReasonUnknown, ir.FinishReasonToolCalls:
+		if hasToolCalls {
+			return ir.FinishReasonToolCalls
+		}
+		return ir.MapOpenAIRefusal(reason, refused)
+	}
+	return reason
+}
+
 // convertEvent converts single event to target format
 func (t *StreamTranslator) convertEvent(event *ir.UnifiedEvent) ([]byte, error) {
 	switch {
 	case t.to == "openai" || t.to == "cline":
 		idx := 0
+		// Tool calls are numbered per choice
+		next := &t.Ctx.ToolCallIndex
+		if event.ChoiceIndex > 0 {
+			next = &t.Ctx.choice(event.ChoiceIndex).toolCallIndex
+		}
 		if event.Type == ir.EventTypeToolCall {
-			idx = t.Ctx.ToolCallIndex
-			t.Ctx.ToolCallIndex++ // Increment AFTER getting current index
+			idx = *next
+			*next++ // Increment AFTER getting current index
 		} else if event.Type == ir.EventTypeToolCallDelta {
 			// For deltas, use PREVIOUS index (the tool call we're continuing)
-			if t.Ctx.ToolCallIndex > 0 {
-				idx = t.Ctx.ToolCallIndex - 1
+			if *next > 0 {
+				idx = *next - 1
 			}
 		}
 		return from_ir.ToOpenAIChunk(*event, t.model, t.messageID, idx)
@@ -278,7 +386,20 @@ func (t *StreamTranslator) convertEvent(event *ir.UnifiedEvent) ([]byte, error)
 		return from_ir.ToGeminiChunk(*event, t.model)
 	case t.to == "ollama":
 		return from_ir.ToOllamaChatChunk(*event, t.model)
+	case isResponsesFormat(t.to):
+		if t.Ctx.ResponsesState == nil {
+			t.Ctx.ResponsesState = from_ir.NewResponsesStreamState()
+		}
+		chunks, err := from_ir.ToResponsesAPIChunk(*event, t.model, t.Ctx.ResponsesState)
+		if err != nil || len(chunks) == 0 {
+			return nil, err
+		}
+		return bytes.Join(chunks, nil), nil
 	default:
 		return nil, nil // unsupported format
 	}
 }
+
+func isResponsesFormat(to string) bool {
+	return to == "codex" || to == "openai-response"
+}
@@ -1,6 +1,8 @@
 package stream
 
 import (
+	"context"
+
 	"github.com/nghyane/llm-mux/internal/config"
 	"github.com/nghyane/llm-mux/internal/misc"
 	"github.com/nghyane/llm-mux/internal/provider"
@@ -10,6 +12,8 @@ import (
 	"github.com/nghyane/llm-mux/internal/translator/from_ir"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"github.com/nghyane/llm-mux/internal/translator/preprocess"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 )
 
 func ExtractUsageFromEvents(events []*ir.UnifiedEvent) *ir.Usage {
@@ -33,8 +37,8 @@ type StreamTranslationResult struct {
 	Usage  *ir.Usage // Usage extracted from IR events (nil if not present in this chunk)
 }
 
-func TranslateToGeminiWithTokens(cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) (*TranslationResult, error) {
-	irReq, err := ConvertRequestToIR(from, model, payload, metadata)
+func TranslateToGeminiWithTokens(ctx context.Context, cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) (*TranslationResult, error) {
+	irReq, err := ConvertRequestToIR(ctx, from, model, payload, metadata)
 	if err != nil {
 		return nil, err
 	}
@@ -44,15 +48,25 @@ func TranslateToGeminiWithTokens(cfg *config.Config, from provider.Format, model
 		return nil, err
 	}
 
+	// Without client safety settings, let payload default rules supply
+	// per-model settings before falling back to the built-in ones.
+	if len(irReq.SafetySettings) == 0 {
+		geminiJSON, _ = sjson.DeleteBytes(geminiJSON, "safetySettings")
+	}
+	geminiJSON = sseutil.ApplyPayloadConfig(cfg, model, provider.ClientAPIKey(ctx), geminiJSON)
+	if !gjson.GetBytes(geminiJSON, "safetySettings").Exists() {
+		geminiJSON, _ = sjson.SetBytes(geminiJSON, "safetySettings", ir.DefaultGeminiSafetySettings())
+	}
+
 	result := &TranslationResult{
-		Payload: sseutil.ApplyPayloadConfig(cfg, model, geminiJSON),
+		Payload: geminiJSON,
 		IR:      irReq,
 	}
 
 	return result, nil
 }
 
-func ConvertRequestToIR(from provider.Format, model string, payload []byte, metadata map[string]any) (*ir.UnifiedChatRequest, error) {
+func ConvertRequestToIR(ctx context.Context, from provider.Format, model string, payload []byte, metadata map[string]any) (*ir.UnifiedChatRequest, error) {
 	payload = sseutil.SanitizeUndefinedValues(payload)
 
 	formatStr := from.String()
@@ -92,7 +106,9 @@ func ConvertRequestToIR(from provider.Format, model string, payload []byte, meta
 
 	NormalizeIRLimits(irReq.Model, irReq)
 	ApplyThinkingToIR(irReq.Model, irReq)
-	preprocess.Apply(irReq)
+	if err := preprocess.Apply(ctx, irReq); err != nil {
+		return nil, err
+	}
 
 	return irReq, nil
 }
@@ -159,8 +175,8 @@ func ExtractThinkingFromMetadata(metadata map[string]any) (budget *int, include
 	return budget, include, hasOverride
 }
 
-func TranslateToCodex(cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
-	irReq, err := ConvertRequestToIR(from, model, payload, metadata)
+func TranslateToCodex(ctx context.Context, cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
+	irReq, err := ConvertRequestToIR(ctx, from, model, payload, metadata)
 	if err != nil {
 		return nil, err
 	}
@@ -182,21 +198,23 @@ func TranslateToCodex(cfg *config.Config, from provider.Format, model string, pa
 	return from_ir.ToOpenAIRequestFmt(irReq, from_ir.FormatResponsesAPI)
 }
 
-func TranslateToClaude(cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
-	irReq, err := ConvertRequestToIR(from, model, payload, metadata)
+func TranslateToClaude(ctx context.Context, cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
+	irReq, err := ConvertRequestToIR(ctx, from, model, payload, metadata)
 	if err != nil {
 		return nil, err
 	}
 	return translator.ConvertRequest("claude", irReq)
 }
 
-func TranslateToOpenAI(cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
+func TranslateToOpenAI(ctx context.Context, cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
+	// OpenAI requests are passed through unless preprocessing rewrites
+	// their messages.
 	fromStr := from.String()
-	if fromStr == "openai" || fromStr == "cline" {
-		return sseutil.ApplyPayloadConfig(cfg, model, payload), nil
+	if (fromStr == "openai" || fromStr == "cline") && !preprocess.RewritesMessages(model) {
+		return sseutil.ApplyPayloadConfig(cfg, model, provider.ClientAPIKey(ctx), payload), nil
 	}
 
-	irReq, err := ConvertRequestToIR(from, model, payload, metadata)
+	irReq, err := ConvertRequestToIR(ctx, from, model, payload, metadata)
 	if err != nil {
 		return nil, err
 	}
@@ -204,11 +222,11 @@ func TranslateToOpenAI(cfg *config.Config, from provider.Format, model string, p
 	if err != nil {
 		return nil, err
 	}
-	return sseutil.ApplyPayloadConfig(cfg, model, openaiJSON), nil
+	return sseutil.ApplyPayloadConfig(cfg, model, provider.ClientAPIKey(ctx), openaiJSON), nil
 }
 
-func TranslateToGemini(cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
-	result, err := TranslateToGeminiWithTokens(cfg, from, model, payload, streaming, metadata)
+func TranslateToGemini(ctx context.Context, cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
+	result, err := TranslateToGeminiWithTokens(ctx, cfg, from, model, payload, streaming, metadata)
 	if err != nil {
 		return nil, err
 	}
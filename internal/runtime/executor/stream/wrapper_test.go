@@ -0,0 +1,37 @@
+package stream
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/preprocess"
+	"github.com/tidwall/gjson"
+)
+
+func TestTranslateToOpenAIAppliesSystemPrompts(t *testing.T) {
+	payload := []byte(`{"model":"gpt-x","messages":[{"role":"user","content":"hi"}]}`)
+	translate := func(model string) string {
+		t.Helper()
+		out, err := TranslateToOpenAI(context.Background(), &config.Config{}, provider.FormatOpenAI, model, payload, false, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(out)
+	}
+
+	if got := translate("gpt-x"); got != string(payload) {
+		t.Errorf("passthrough changed the request: %s", got)
+	}
+
+	preprocess.SetSystemPrompts([]preprocess.SystemPrompt{{Name: "brief", Version: 1, Models: []string{"gpt-*"}, Template: "Be brief."}})
+	t.Cleanup(func() { preprocess.SetSystemPrompts(nil) })
+	msgs := gjson.Get(translate("gpt-x"), "messages").Array()
+	if len(msgs) != 2 || msgs[0].Get("role").String() != "system" || msgs[0].Get("content").String() != "Be brief." {
+		t.Errorf("system prompt not applied: %v", msgs)
+	}
+	if got := translate("claude-x"); got != string(payload) {
+		t.Errorf("unmatched model changed the request: %s", got)
+	}
+}
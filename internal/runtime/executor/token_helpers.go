@@ -260,7 +260,7 @@ func CountTokensForOpenAIProvider(
 	payload []byte,
 	metadata map[string]any,
 ) (provider.Response, error) {
-	body, err := stream.TranslateToOpenAI(cfg, from, model, payload, false, metadata)
+	body, err := stream.TranslateToOpenAI(ctx, cfg, from, model, payload, false, metadata)
 	if err != nil {
 		return provider.Response{}, err
 	}
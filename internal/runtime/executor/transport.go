@@ -51,6 +51,7 @@ func baseTransport() *http.Transport {
 		WriteBufferSize: 256 * 1024, // 256KB - increased from 64KB for better streaming throughput
 		ReadBufferSize:  256 * 1024, // 256KB - increased from 64KB for better streaming throughput
 	}
+	transport.ConfigureTLSSessions(t.TLSClientConfig, transport.DefaultTLSPool, 0)
 	configureHTTP2(t)
 	return t
 }
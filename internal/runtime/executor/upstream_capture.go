@@ -0,0 +1,111 @@
+package executor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxCapturedBody bounds the request bytes kept per captured exchange.
+const maxCapturedBody = 64 * 1024
+
+// UpstreamExchange is a single HTTP round trip recorded by an UpstreamCapture.
+type UpstreamExchange struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	Status      int    `json:"status,omitempty"`
+	LatencyMs   int64  `json:"latency_ms"`
+	RequestBody string `json:"request_body,omitempty"`
+	Truncated   bool   `json:"truncated,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// UpstreamCapture records the outbound requests executors make for a context.
+// It is used by diagnostics such as the provider smoke test; production requests
+// never carry one.
+type UpstreamCapture struct {
+	mu        sync.Mutex
+	exchanges []UpstreamExchange
+}
+
+type upstreamCaptureKey struct{}
+
+// WithUpstreamCapture returns a context whose executor HTTP clients record every
+// round trip into the returned capture.
+func WithUpstreamCapture(ctx context.Context) (context.Context, *UpstreamCapture) {
+	capture := &UpstreamCapture{}
+	return context.WithValue(ctx, upstreamCaptureKey{}, capture), capture
+}
+
+// Exchanges returns a copy of the recorded round trips in request order.
+func (c *UpstreamCapture) Exchanges() []UpstreamExchange {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]UpstreamExchange(nil), c.exchanges...)
+}
+
+func (c *UpstreamCapture) record(ex UpstreamExchange) {
+	c.mu.Lock()
+	c.exchanges = append(c.exchanges, ex)
+	c.mu.Unlock()
+}
+
+// withUpstreamCapture wraps the client transport when ctx carries a capture.
+func withUpstreamCapture(ctx context.Context, client *http.Client) *http.Client {
+	capture, ok := ctx.Value(upstreamCaptureKey{}).(*UpstreamCapture)
+	if !ok || capture == nil {
+		return client
+	}
+	return wrapTransport(client, func(next http.RoundTripper) http.RoundTripper {
+		return &captureTransport{next: next, capture: capture}
+	})
+}
+
+type captureTransport struct {
+	next    http.RoundTripper
+	capture *UpstreamCapture
+}
+
+func (t *captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := *req.URL
+	u.RawQuery = ""
+	ex := UpstreamExchange{Method: req.Method, URL: u.String()}
+
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			_ = body.Close()
+			if req.Header.Get("Content-Encoding") == "gzip" {
+				if zr, errGzip := gzip.NewReader(bytes.NewReader(data)); errGzip == nil {
+					if plain, errRead := io.ReadAll(zr); errRead == nil {
+						data = plain
+					}
+				}
+			}
+			ex.RequestBody, ex.Truncated = truncateCaptured(data)
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	ex.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		ex.Error = err.Error()
+		t.capture.record(ex)
+		return nil, err
+	}
+	ex.Status = resp.StatusCode
+	t.capture.record(ex)
+	return resp, nil
+}
+
+func truncateCaptured(data []byte) (string, bool) {
+	if len(data) > maxCapturedBody {
+		return string(data[:maxCapturedBody]), true
+	}
+	return string(data), false
+}
@@ -0,0 +1,54 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUpstreamCapture_RecordsDecompressedBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+
+	ctx, capture := WithUpstreamCapture(context.Background())
+	client := NewProxyAwareHTTPClient(ctx, nil, nil, 0)
+
+	payload := bytes.Repeat([]byte(`{"contents":"hello"}`), 200)
+	compressed := CompressRequestBody(payload)
+	if !compressed.IsCompressed {
+		t.Fatal("expected payload to be compressed")
+	}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"/v1/generate?key=secret", bytes.NewReader(compressed.Data))
+	req.Header.Set("Content-Encoding", "gzip")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	exchanges := capture.Exchanges()
+	if len(exchanges) != 1 {
+		t.Fatalf("expected 1 exchange, got %d", len(exchanges))
+	}
+	ex := exchanges[0]
+	if ex.URL != srv.URL+"/v1/generate" {
+		t.Errorf("expected query to be stripped, got %q", ex.URL)
+	}
+	if ex.Status != http.StatusTeapot {
+		t.Errorf("expected status 418, got %d", ex.Status)
+	}
+	if ex.RequestBody != string(payload) {
+		t.Errorf("expected decompressed request body, got %d bytes", len(ex.RequestBody))
+	}
+}
+
+func TestUpstreamCapture_NoCaptureLeavesClientShared(t *testing.T) {
+	client := NewProxyAwareHTTPClient(context.Background(), nil, nil, 0)
+	if client.Transport != SharedTransport {
+		t.Error("expected shared transport without a capture in context")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -23,6 +24,7 @@ type UsageReporter struct {
 	apiKey      string
 	source      string
 	requestedAt time.Time
+	firstToken  atomic.Int64 // unix nanoseconds of the first streamed chunk
 	once        sync.Once
 }
 
@@ -109,7 +111,7 @@ func (r *usageReporter) publishWithOutcome(ctx context.Context, u *ir.Usage, fai
 		return
 	}
 	r.once.Do(func() {
-		usage.PublishRecord(ctx, usage.Record{
+		record := usage.Record{
 			Provider:    r.provider,
 			Model:       r.model,
 			Source:      r.source,
@@ -119,7 +121,14 @@ func (r *usageReporter) publishWithOutcome(ctx context.Context, u *ir.Usage, fai
 			RequestedAt: r.requestedAt,
 			Failed:      failed,
 			Usage:       u,
-		})
+		}
+		record.Latency, record.TTFT = r.latencies()
+		record.RequestBytes, record.ResponseBytes = provider.PayloadSizesFrom(ctx).Sizes()
+		if decision := provider.RoutingDecisionFromContext(ctx); decision != nil {
+			record.RoutePolicy = decision.Policy
+			record.EstimatedSavings = decision.EstimatedSavings(r.provider, u)
+		}
+		usage.PublishRecord(ctx, record)
 	})
 }
 
@@ -128,20 +137,45 @@ func (r *usageReporter) ensurePublished(ctx context.Context) {
 		return
 	}
 	r.once.Do(func() {
+		latency, ttft := r.latencies()
+		requestBytes, responseBytes := provider.PayloadSizesFrom(ctx).Sizes()
 		usage.PublishRecord(ctx, usage.Record{
-			Provider:    r.provider,
-			Model:       r.model,
-			Source:      r.source,
-			APIKey:      r.apiKey,
-			AuthID:      r.authID,
-			AuthIndex:   r.authIndex,
-			RequestedAt: r.requestedAt,
-			Failed:      false,
-			Usage:       nil,
+			Provider:      r.provider,
+			Model:         r.model,
+			Source:        r.source,
+			APIKey:        r.apiKey,
+			AuthID:        r.authID,
+			AuthIndex:     r.authIndex,
+			RequestedAt:   r.requestedAt,
+			Failed:        false,
+			Usage:         nil,
+			Latency:       latency,
+			TTFT:          ttft,
+			RequestBytes:  requestBytes,
+			ResponseBytes: responseBytes,
 		})
 	})
 }
 
+// MarkFirstToken records when the first streamed chunk arrived, for TTFT.
+// Only the first call counts.
+func (r *usageReporter) MarkFirstToken() {
+	if r == nil {
+		return
+	}
+	r.firstToken.CompareAndSwap(0, time.Now().UnixNano())
+}
+
+// latencies returns the time since the request started and the time to the
+// first streamed chunk, if any.
+func (r *usageReporter) latencies() (total, ttft time.Duration) {
+	total = time.Since(r.requestedAt)
+	if first := r.firstToken.Load(); first != 0 {
+		ttft = time.Unix(0, first).Sub(r.requestedAt)
+	}
+	return total, ttft
+}
+
 // EnsurePublished implements stream.UsageReporter interface
 func (r *usageReporter) EnsurePublished(ctx context.Context) {
 	r.ensurePublished(ctx)
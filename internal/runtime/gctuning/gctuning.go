@@ -0,0 +1,164 @@
+// Package gctuning applies garbage collector settings at runtime so large
+// instances can trade memory for tail latency without rebuilding or setting
+// GOGC/GOMEMLIMIT in the environment.
+package gctuning
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Settings are the tunables applied by Apply. Zero values restore the
+// process defaults (GOGC/GOMEMLIMIT from the environment, or Go's defaults).
+type Settings struct {
+	// GCPercent overrides GOGC when non-nil. Negative disables the collector
+	// unless a memory limit is set.
+	GCPercent *int
+	// MemoryLimit is the soft heap limit in bytes. 0 keeps the default.
+	MemoryLimit int64
+	// Ballast is the size in bytes of a never-touched allocation that raises
+	// the heap size the GC paces against. 0 releases it.
+	Ballast int64
+}
+
+var (
+	mu sync.Mutex
+	// defaults captured before the first Apply so settings can be reverted.
+	defaultGCPercent   int
+	defaultMemoryLimit int64
+	captured           bool
+	ballast            []byte
+)
+
+// Apply installs s, replacing any previously applied settings.
+func Apply(s Settings) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !captured {
+		defaultGCPercent = debug.SetGCPercent(100)
+		debug.SetGCPercent(defaultGCPercent)
+		defaultMemoryLimit = debug.SetMemoryLimit(-1)
+		captured = true
+	}
+
+	if s.GCPercent != nil {
+		debug.SetGCPercent(*s.GCPercent)
+	} else {
+		debug.SetGCPercent(defaultGCPercent)
+	}
+	if s.MemoryLimit > 0 {
+		debug.SetMemoryLimit(s.MemoryLimit)
+	} else {
+		debug.SetMemoryLimit(defaultMemoryLimit)
+	}
+	if s.Ballast != int64(len(ballast)) {
+		ballast = nil
+		if s.Ballast > 0 {
+			// Never written, so the pages stay untouched and cost no RSS.
+			ballast = make([]byte, s.Ballast)
+		}
+	}
+}
+
+// ParseSize parses a byte size such as "512MiB", "4GiB", "1.5GB" or "1048576".
+func ParseSize(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	units := []struct {
+		suffix string
+		mult   float64
+	}{
+		{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+		{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+		{"B", 1},
+	}
+	mult := 1.0
+	number := value
+	for _, u := range units {
+		if strings.HasSuffix(strings.ToUpper(value), strings.ToUpper(u.suffix)) {
+			number = strings.TrimSpace(value[:len(value)-len(u.suffix)])
+			mult = u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 || n*mult > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return int64(n * mult), nil
+}
+
+// Stats is a snapshot of runtime memory and scheduler state.
+type Stats struct {
+	Goroutines    int       `json:"goroutines"`
+	GOMAXPROCS    int       `json:"gomaxprocs"`
+	GCPercent     int       `json:"gc_percent"`
+	MemoryLimit   int64     `json:"memory_limit"`
+	Ballast       int64     `json:"ballast"`
+	HeapAlloc     uint64    `json:"heap_alloc"`
+	HeapInuse     uint64    `json:"heap_inuse"`
+	HeapIdle      uint64    `json:"heap_idle"`
+	HeapReleased  uint64    `json:"heap_released"`
+	HeapObjects   uint64    `json:"heap_objects"`
+	StackInuse    uint64    `json:"stack_inuse"`
+	Sys           uint64    `json:"sys"`
+	TotalAlloc    uint64    `json:"total_alloc"`
+	Mallocs       uint64    `json:"mallocs"`
+	Frees         uint64    `json:"frees"`
+	NextGC        uint64    `json:"next_gc"`
+	NumGC         uint32    `json:"num_gc"`
+	NumForcedGC   uint32    `json:"num_forced_gc"`
+	GCCPUFraction float64   `json:"gc_cpu_fraction"`
+	PauseTotalNs  uint64    `json:"pause_total_ns"`
+	LastPauseNs   uint64    `json:"last_pause_ns"`
+	LastGC        time.Time `json:"last_gc"`
+}
+
+// ReadStats collects current memory statistics. It briefly stops the world.
+func ReadStats() Stats {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	mu.Lock()
+	// SetGCPercent is the only way to read the current value.
+	gcPercent := debug.SetGCPercent(-1)
+	debug.SetGCPercent(gcPercent)
+	ballastSize := int64(len(ballast))
+	mu.Unlock()
+
+	stats := Stats{
+		Goroutines:    runtime.NumGoroutine(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		GCPercent:     gcPercent,
+		MemoryLimit:   debug.SetMemoryLimit(-1),
+		Ballast:       ballastSize,
+		HeapAlloc:     ms.HeapAlloc,
+		HeapInuse:     ms.HeapInuse,
+		HeapIdle:      ms.HeapIdle,
+		HeapReleased:  ms.HeapReleased,
+		HeapObjects:   ms.HeapObjects,
+		StackInuse:    ms.StackInuse,
+		Sys:           ms.Sys,
+		TotalAlloc:    ms.TotalAlloc,
+		Mallocs:       ms.Mallocs,
+		Frees:         ms.Frees,
+		NextGC:        ms.NextGC,
+		NumGC:         ms.NumGC,
+		NumForcedGC:   ms.NumForcedGC,
+		GCCPUFraction: ms.GCCPUFraction,
+		PauseTotalNs:  ms.PauseTotalNs,
+	}
+	if ms.NumGC > 0 {
+		stats.LastPauseNs = ms.PauseNs[(ms.NumGC+255)%256]
+		stats.LastGC = time.Unix(0, int64(ms.LastGC))
+	}
+	return stats
+}
@@ -0,0 +1,47 @@
+package gctuning
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]int64{
+		"":        0,
+		"1048576": 1 << 20,
+		"512MiB":  512 << 20,
+		"4gib":    4 << 30,
+		"1.5GB":   1_500_000_000,
+		"64 KB":   64_000,
+	}
+	for in, want := range cases {
+		got, err := ParseSize(in)
+		if err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"lots", "-1MiB", "12XB"} {
+		if _, err := ParseSize(in); err == nil {
+			t.Errorf("ParseSize(%q) should fail", in)
+		}
+	}
+}
+
+func TestApplyRestoresDefaults(t *testing.T) {
+	before := ReadStats()
+	percent := 250
+	Apply(Settings{GCPercent: &percent, MemoryLimit: 1 << 30, Ballast: 1 << 20})
+	stats := ReadStats()
+	if stats.GCPercent != 250 || stats.MemoryLimit != 1<<30 || stats.Ballast != 1<<20 {
+		t.Fatalf("settings not applied: %+v", stats)
+	}
+
+	Apply(Settings{})
+	if got := debug.SetMemoryLimit(-1); got != before.MemoryLimit {
+		t.Fatalf("memory limit = %d, want %d", got, before.MemoryLimit)
+	}
+	stats = ReadStats()
+	if stats.GCPercent != before.GCPercent || stats.Ballast != 0 {
+		t.Fatalf("defaults not restored: %+v", stats)
+	}
+}
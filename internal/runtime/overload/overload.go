@@ -0,0 +1,250 @@
+// Package overload sheds traffic by priority when the process is overloaded,
+// so interactive requests keep being served while batch traffic backs off
+// instead of everything slowing down together.
+package overload
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+const (
+	// sampleInterval is how often load is sampled. Scheduler latency is the
+	// lateness of this tick.
+	sampleInterval = 100 * time.Millisecond
+
+	// latencySmoothing is the weight of a new latency sample in the average.
+	latencySmoothing = 0.2
+
+	// recoveryRatio is the fraction of a level's threshold load must fall
+	// below before shedding is relaxed, so the level does not flap.
+	recoveryRatio = 0.8
+
+	defaultCriticalFactor = 1.5
+	defaultRetryAfter     = 5 * time.Second
+)
+
+// Settings configure the controller. Zero thresholds are not checked.
+type Settings struct {
+	Enabled bool
+	// MaxGoroutines is the goroutine count at which low-priority requests
+	// are shed.
+	MaxGoroutines int
+	// MaxSchedulerLatency is the smoothed scheduling delay at which
+	// low-priority requests are shed.
+	MaxSchedulerLatency time.Duration
+	// CriticalFactor is the multiple of a threshold past which
+	// normal-priority requests are shed too. Default: 1.5.
+	CriticalFactor float64
+	// RetryAfter is returned to shed clients. Default: 5s.
+	RetryAfter time.Duration
+}
+
+// Level is the degree of shedding.
+type Level int32
+
+const (
+	// LevelNormal admits every request.
+	LevelNormal Level = iota
+	// LevelShedLow rejects low-priority requests.
+	LevelShedLow
+	// LevelShedNormal rejects all but high-priority requests.
+	LevelShedNormal
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelShedLow:
+		return "shed-low"
+	case LevelShedNormal:
+		return "shed-normal"
+	}
+	return "normal"
+}
+
+// Status is a snapshot of the controller.
+type Status struct {
+	Enabled            bool             `json:"enabled"`
+	Level              string           `json:"level"`
+	Load               float64          `json:"load"`
+	Goroutines         int              `json:"goroutines"`
+	SchedulerLatencyMs float64          `json:"scheduler_latency_ms"`
+	Shed               map[string]int64 `json:"shed"`
+}
+
+var (
+	mu       sync.Mutex
+	settings Settings
+	stop     chan struct{}
+
+	enabled    atomic.Bool
+	level      atomic.Int32
+	load       atomic.Uint64 // math.Float64bits of the last load
+	goroutines atomic.Int64
+	latency    atomic.Int64 // smoothed scheduler latency in nanoseconds
+	shedLow    atomic.Int64
+	shedNormal atomic.Int64
+)
+
+// Apply installs s, starting or stopping the sampler as needed.
+func Apply(s Settings) {
+	if s.CriticalFactor <= 1 {
+		s.CriticalFactor = defaultCriticalFactor
+	}
+	if s.RetryAfter <= 0 {
+		s.RetryAfter = defaultRetryAfter
+	}
+	if s.MaxGoroutines <= 0 && s.MaxSchedulerLatency <= 0 {
+		s.Enabled = false
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	settings = s
+	enabled.Store(s.Enabled)
+	switch {
+	case s.Enabled && stop == nil:
+		stop = make(chan struct{})
+		go sample(stop)
+	case !s.Enabled && stop != nil:
+		close(stop)
+		stop = nil
+		level.Store(int32(LevelNormal))
+	}
+}
+
+// Admit reports whether a request of priority p is served at the current
+// level, and otherwise how long the client should wait before retrying.
+func Admit(p provider.Priority) (bool, time.Duration) {
+	if !enabled.Load() {
+		return true, 0
+	}
+	switch Level(level.Load()) {
+	case LevelShedLow:
+		if p < provider.PriorityNormal {
+			shedLow.Add(1)
+			return false, retryAfter()
+		}
+	case LevelShedNormal:
+		if p < provider.PriorityHigh {
+			if p < provider.PriorityNormal {
+				shedLow.Add(1)
+			} else {
+				shedNormal.Add(1)
+			}
+			return false, retryAfter()
+		}
+	}
+	return true, 0
+}
+
+// CurrentStatus returns the current level, its inputs and how many requests
+// were shed per priority since startup.
+func CurrentStatus() Status {
+	return Status{
+		Enabled:            enabled.Load(),
+		Level:              Level(level.Load()).String(),
+		Load:               math.Float64frombits(load.Load()),
+		Goroutines:         int(goroutines.Load()),
+		SchedulerLatencyMs: float64(latency.Load()) / float64(time.Millisecond),
+		Shed: map[string]int64{
+			provider.PriorityLow.String():    shedLow.Load(),
+			provider.PriorityNormal.String(): shedNormal.Load(),
+		},
+	}
+}
+
+func retryAfter() time.Duration {
+	mu.Lock()
+	defer mu.Unlock()
+	return settings.RetryAfter
+}
+
+func sample(stop <-chan struct{}) {
+	ticker := time.NewTicker(sampleInterval)
+	defer ticker.Stop()
+	last := time.Now()
+	smoothed := 0.0
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			// The channel holds the scheduled tick time; the delay until this
+			// goroutine runs is the scheduling latency.
+			lag := time.Since(now)
+			if gap := now.Sub(last) - sampleInterval; gap > lag {
+				lag = gap
+			}
+			last = now
+			smoothed = (1-latencySmoothing)*smoothed + latencySmoothing*float64(lag)
+			observe(runtime.NumGoroutine(), time.Duration(smoothed))
+		}
+	}
+}
+
+// observe updates the level from a sample.
+func observe(numGoroutines int, schedLatency time.Duration) {
+	mu.Lock()
+	s := settings
+	mu.Unlock()
+
+	current := Level(level.Load())
+	l := loadOf(s, numGoroutines, schedLatency)
+	next := nextLevel(current, l, s.CriticalFactor)
+
+	goroutines.Store(int64(numGoroutines))
+	latency.Store(int64(schedLatency))
+	load.Store(math.Float64bits(l))
+	if next == current {
+		return
+	}
+	level.Store(int32(next))
+	if next > current {
+		log.Warnf("overload: %s (goroutines=%d, scheduler latency=%s)", next, numGoroutines, schedLatency.Round(time.Millisecond))
+	} else {
+		log.Infof("overload: relaxed to %s (goroutines=%d, scheduler latency=%s)", next, numGoroutines, schedLatency.Round(time.Millisecond))
+	}
+}
+
+// loadOf returns the highest ratio of a measurement to its threshold.
+func loadOf(s Settings, numGoroutines int, schedLatency time.Duration) float64 {
+	var l float64
+	if s.MaxGoroutines > 0 {
+		l = max(l, float64(numGoroutines)/float64(s.MaxGoroutines))
+	}
+	if s.MaxSchedulerLatency > 0 {
+		l = max(l, float64(schedLatency)/float64(s.MaxSchedulerLatency))
+	}
+	return l
+}
+
+// nextLevel returns the level for load l. Shedding starts as soon as a
+// threshold is reached and is relaxed once load falls below recoveryRatio of
+// it.
+func nextLevel(current Level, l, criticalFactor float64) Level {
+	target := LevelNormal
+	switch {
+	case l >= criticalFactor:
+		target = LevelShedNormal
+	case l >= 1:
+		target = LevelShedLow
+	}
+	if target >= current {
+		return target
+	}
+	threshold := 1.0
+	if current == LevelShedNormal {
+		threshold = criticalFactor
+	}
+	if l >= threshold*recoveryRatio {
+		return current
+	}
+	return current - 1
+}
@@ -0,0 +1,54 @@
+package overload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestNextLevel(t *testing.T) {
+	tests := []struct {
+		current Level
+		load    float64
+		want    Level
+	}{
+		{LevelNormal, 0.5, LevelNormal},
+		{LevelNormal, 1.0, LevelShedLow},
+		{LevelNormal, 2.0, LevelShedNormal},
+		{LevelShedLow, 0.9, LevelShedLow}, // within the recovery margin
+		{LevelShedLow, 0.7, LevelNormal},  // recovered
+		{LevelShedNormal, 1.3, LevelShedNormal},
+		{LevelShedNormal, 1.1, LevelShedLow}, // relaxed one level at a time
+		{LevelShedNormal, 0.1, LevelShedLow},
+	}
+	for _, tt := range tests {
+		if got := nextLevel(tt.current, tt.load, 1.5); got != tt.want {
+			t.Errorf("nextLevel(%s, %v) = %s, want %s", tt.current, tt.load, got, tt.want)
+		}
+	}
+}
+
+func TestAdmitShedsByPriority(t *testing.T) {
+	Apply(Settings{Enabled: true, MaxGoroutines: 100, RetryAfter: 2 * time.Second})
+	defer Apply(Settings{})
+
+	observe(120, 0)
+	if ok, _ := Admit(provider.PriorityNormal); !ok {
+		t.Fatal("normal priority shed at shed-low")
+	}
+	if ok, retry := Admit(provider.PriorityLow); ok || retry != 2*time.Second {
+		t.Fatalf("low priority: admitted=%v retry=%s", ok, retry)
+	}
+
+	observe(200, 0)
+	if ok, _ := Admit(provider.PriorityNormal); ok {
+		t.Fatal("normal priority admitted at shed-normal")
+	}
+	if ok, _ := Admit(provider.PriorityHigh); !ok {
+		t.Fatal("high priority shed")
+	}
+	if got := CurrentStatus().Level; got != "shed-normal" {
+		t.Fatalf("level = %s", got)
+	}
+}
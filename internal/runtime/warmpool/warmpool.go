@@ -0,0 +1,268 @@
+// Package warmpool keeps models of local Ollama upstreams loaded, so the
+// first request after an idle period does not wait for the model to load.
+package warmpool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+const (
+	// DefaultInterval is the time between keep-alive pings.
+	DefaultInterval = 4 * time.Minute
+	// DefaultKeepAlive is how long Ollama keeps a pinged model loaded.
+	DefaultKeepAlive = 10 * time.Minute
+
+	// pingTimeout allows for loading a large model from disk.
+	pingTimeout = 2 * time.Minute
+	psTimeout   = 5 * time.Second
+)
+
+// Target is an Ollama server whose models are kept loaded.
+type Target struct {
+	// Provider is the llm-mux provider name.
+	Provider string
+	// BaseURL is the configured provider URL; a trailing /v1 is removed to
+	// reach the native API.
+	BaseURL   string
+	APIKey    string
+	Models    []string
+	Interval  time.Duration
+	KeepAlive time.Duration
+}
+
+// ModelState is the outcome of the last keep-alive ping of a model.
+type ModelState struct {
+	Model    string    `json:"model"`
+	LastPing time.Time `json:"last_ping"`
+	// LoadMs is how long the last ping waited for the model to load; near
+	// zero when it was still loaded.
+	LoadMs int64  `json:"load_ms"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RunningModel is a model loaded on the server, as reported by /api/ps.
+type RunningModel struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	SizeVRAM  int64     `json:"size_vram"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TargetStatus combines ping state with the models the server reports loaded.
+type TargetStatus struct {
+	Provider string         `json:"provider"`
+	BaseURL  string         `json:"base_url"`
+	Models   []ModelState   `json:"models"`
+	Running  []RunningModel `json:"running"`
+	// Error is set when /api/ps could not be read.
+	Error string `json:"error,omitempty"`
+}
+
+// Pool pings the models of its targets in the background.
+type Pool struct {
+	client *http.Client
+
+	mu      sync.Mutex
+	targets []Target
+	state   map[string]map[string]*ModelState // provider -> model
+	cancel  context.CancelFunc
+}
+
+// New returns an idle pool.
+func New(client *http.Client) *Pool {
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &Pool{client: client, state: make(map[string]map[string]*ModelState)}
+}
+
+var defaultPool = New(nil)
+
+// Configure replaces the targets of the default pool.
+func Configure(targets []Target) { defaultPool.Configure(targets) }
+
+// Stop stops the default pool.
+func Stop() { defaultPool.Stop() }
+
+// Status returns the state of the default pool.
+func Status(ctx context.Context) []TargetStatus { return defaultPool.Status(ctx) }
+
+// Configure replaces the targets and restarts the background loops. Every
+// model is pinged immediately and then every interval.
+func (p *Pool) Configure(targets []Target) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+	p.targets = nil
+	state := make(map[string]map[string]*ModelState)
+	for _, t := range targets {
+		if len(t.Models) == 0 {
+			continue
+		}
+		if t.Interval <= 0 {
+			t.Interval = DefaultInterval
+		}
+		if t.KeepAlive <= 0 {
+			t.KeepAlive = DefaultKeepAlive
+		}
+		t.BaseURL = NativeBaseURL(t.BaseURL)
+		p.targets = append(p.targets, t)
+		models := make(map[string]*ModelState, len(t.Models))
+		for _, m := range t.Models {
+			if prev, ok := p.state[t.Provider][m]; ok {
+				models[m] = prev
+			} else {
+				models[m] = &ModelState{Model: m}
+			}
+		}
+		state[t.Provider] = models
+	}
+	p.state = state
+	if len(p.targets) == 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	for _, t := range p.targets {
+		go p.loop(ctx, t)
+	}
+}
+
+// Stop stops the background loops.
+func (p *Pool) Stop() { p.Configure(nil) }
+
+func (p *Pool) loop(ctx context.Context, t Target) {
+	ticker := time.NewTicker(t.Interval)
+	defer ticker.Stop()
+	for {
+		for _, model := range t.Models {
+			p.ping(ctx, t, model)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ping asks Ollama to load model, or extend its keep-alive, without
+// generating anything.
+func (p *Pool) ping(ctx context.Context, t Target, model string) {
+	body, _ := json.Marshal(map[string]any{"model": model, "keep_alive": t.KeepAlive.String()})
+	var resp struct {
+		LoadDuration int64 `json:"load_duration"`
+	}
+	err := p.do(ctx, pingTimeout, t, http.MethodPost, "/api/generate", body, &resp)
+	if ctx.Err() != nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.state[t.Provider][model]
+	if !ok {
+		return
+	}
+	s.LastPing = time.Now()
+	if err != nil {
+		// Log once per failure streak; the state keeps the latest error.
+		if s.Error == "" {
+			log.Warnf("warm pool: %s %s: %v", t.Provider, model, err)
+		}
+		s.Error = err.Error()
+		return
+	}
+	s.Error = ""
+	s.LoadMs = time.Duration(resp.LoadDuration).Milliseconds()
+}
+
+// Status returns the ping state of every target and the models each server
+// reports loaded.
+func (p *Pool) Status(ctx context.Context) []TargetStatus {
+	p.mu.Lock()
+	targets := slices.Clone(p.targets)
+	out := make([]TargetStatus, len(targets))
+	for i, t := range targets {
+		out[i] = TargetStatus{Provider: t.Provider, BaseURL: t.BaseURL, Models: []ModelState{}, Running: []RunningModel{}}
+		for _, m := range t.Models {
+			if s, ok := p.state[t.Provider][m]; ok {
+				out[i].Models = append(out[i].Models, *s)
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var ps struct {
+				Models []RunningModel `json:"models"`
+			}
+			if err := p.do(ctx, psTimeout, t, http.MethodGet, "/api/ps", nil, &ps); err != nil {
+				out[i].Error = err.Error()
+				return
+			}
+			if ps.Models != nil {
+				out[i].Running = ps.Models
+			}
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+func (p *Pool) do(ctx context.Context, timeout time.Duration, t Target, method, path string, body []byte, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, t.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if t.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.APIKey)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, out)
+}
+
+// NativeBaseURL returns the native API root of an Ollama OpenAI-compatible
+// base URL such as http://localhost:11434/v1.
+func NativeBaseURL(baseURL string) string {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	return strings.TrimSuffix(baseURL, "/v1")
+}
@@ -0,0 +1,62 @@
+package warmpool
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoolPingsModelsAndReportsStatus(t *testing.T) {
+	pinged := make(chan string, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/generate":
+			body, _ := io.ReadAll(r.Body)
+			pinged <- string(body)
+			_, _ = io.WriteString(w, `{"model":"llama3","done":true,"done_reason":"load","load_duration":1500000000}`)
+		case "/api/ps":
+			_, _ = io.WriteString(w, `{"models":[{"name":"llama3","size":5000,"size_vram":4000,"expires_at":"2030-01-01T00:00:00Z"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	p := New(srv.Client())
+	p.Configure([]Target{{Provider: "ollama", BaseURL: srv.URL + "/v1/", Models: []string{"llama3"}, KeepAlive: 30 * time.Minute}})
+	defer p.Stop()
+
+	select {
+	case body := <-pinged:
+		if !strings.Contains(body, `"keep_alive":"30m0s"`) || !strings.Contains(body, `"model":"llama3"`) {
+			t.Fatalf("ping body = %s", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("model was not pinged")
+	}
+
+	var status []TargetStatus
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		status = p.Status(context.Background())
+		if len(status) == 1 && !status[0].Models[0].LastPing.IsZero() {
+			break
+		}
+	}
+	if len(status) != 1 {
+		t.Fatalf("status = %+v", status)
+	}
+	s := status[0]
+	if s.BaseURL != srv.URL || s.Error != "" {
+		t.Fatalf("base url = %q, error = %q", s.BaseURL, s.Error)
+	}
+	if m := s.Models[0]; m.LoadMs != 1500 || m.Error != "" {
+		t.Fatalf("model state = %+v", m)
+	}
+	if len(s.Running) != 1 || s.Running[0].Name != "llama3" || s.Running[0].SizeVRAM != 4000 {
+		t.Fatalf("running = %+v", s.Running)
+	}
+}
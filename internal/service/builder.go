@@ -12,6 +12,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/config"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/usage"
+	"github.com/nghyane/llm-mux/internal/watcher"
 )
 
 // Builder constructs a Service instance with customizable providers.
@@ -26,6 +27,12 @@ type Builder struct {
 	accessManager  *access.Manager
 	coreManager    *provider.Manager
 	serverOptions  []api.ServerOption
+	refreshHooks   []providerRefreshHook
+}
+
+type providerRefreshHook struct {
+	provider string
+	hook     provider.RefreshHook
 }
 
 // Hooks allows callers to plug into service lifecycle stages.
@@ -39,6 +46,10 @@ type Hooks struct {
 	// OnAfterStart is called after the service has started successfully,
 	// providing access to the service instance for additional operations.
 	OnAfterStart func(*Service)
+
+	// OnConfigReloadFailed is called when a hot-reloaded config is rejected and
+	// the previous config keeps serving.
+	OnConfigReloadFailed func(watcher.ReloadStatus)
 }
 
 // NewBuilder creates a Builder with default dependencies left unset.
@@ -116,6 +127,14 @@ func (b *Builder) WithCoreAuthManager(mgr *provider.Manager) *Builder {
 	return b
 }
 
+// WithRefreshHook registers a custom credential refresher for the auths of a
+// provider, such as a corporate SSO token exchange. Hooks run in
+// registration order before the provider executor's built-in refresher.
+func (b *Builder) WithRefreshHook(providerName string, hook provider.RefreshHook) *Builder {
+	b.refreshHooks = append(b.refreshHooks, providerRefreshHook{provider: providerName, hook: hook})
+	return b
+}
+
 // WithServerOptions appends server configuration options used during construction.
 func (b *Builder) WithServerOptions(opts ...api.ServerOption) *Builder {
 	b.serverOptions = append(b.serverOptions, opts...)
@@ -181,6 +200,9 @@ func (b *Builder) Build() (*Service, error) {
 		}
 		coreManager = provider.NewManager(tokenStore, nil, serviceHook)
 	}
+	for _, h := range b.refreshHooks {
+		coreManager.RegisterRefreshHook(h.provider, h.hook)
+	}
 	// Attach a default RoundTripper provider so providers can opt-in per-auth transports.
 	coreManager.SetRoundTripperProvider(newDefaultRoundTripperProvider())
 
@@ -189,6 +211,9 @@ func (b *Builder) Build() (*Service, error) {
 		plugin := provider.NewQuotaSyncPlugin(qm)
 		usage.RegisterPlugin(plugin)
 	}
+	usage.RegisterPlugin(coreManager.RoutingScheduler())
+	usage.RegisterPlugin(coreManager.SpendLimiter())
+	usage.RegisterPlugin(usage.GetPayloadMonitor())
 
 	service := &Service{
 		cfg:            b.cfg,
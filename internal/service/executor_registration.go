@@ -65,6 +65,8 @@ func registerProviderExecutor(a *provider.Auth, cfg *config.Config, coreManager
 		coreManager.RegisterExecutor(providers.NewKiroExecutor(cfg))
 	case "github-copilot":
 		coreManager.RegisterExecutor(providers.NewCopilotExecutor(cfg))
+	case "mock":
+		coreManager.RegisterExecutor(providers.NewMockExecutor(cfg))
 	default:
 		providerKey := strings.ToLower(strings.TrimSpace(a.Provider))
 		if providerKey == "" {
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+const (
+	defaultModelRefreshInterval = 6 * time.Hour
+	defaultModelRefreshJitter   = 0.1
+)
+
+// dynamicModelProviders fetch their model lists from upstream; the other
+// providers use built-in or configured lists.
+var dynamicModelProviders = []string{"gemini", "vertex", "gemini-cli", "aistudio", "antigravity"}
+
+// registeredModels holds the models last registered per auth ID, to detect
+// upstream changes on refresh.
+var registeredModels sync.Map // map[authID]registeredModelSet
+
+type registeredModelSet struct {
+	models []*ModelInfo
+	// fetched is false when the upstream fetch failed and a built-in list
+	// was registered instead.
+	fetched bool
+}
+
+// modelRefresher re-fetches the model lists of dynamicModelProviders on a
+// jittered schedule.
+type modelRefresher struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	// refreshing serializes refreshes of a provider.
+	refreshing sync.Map // map[provider]*sync.Mutex
+}
+
+func (s *Service) applyModelRefreshConfig(cfg *config.Config) {
+	if s == nil || cfg == nil || s.coreManager == nil {
+		return
+	}
+	interval := defaultModelRefreshInterval
+	if raw := strings.TrimSpace(cfg.ModelRefresh.Interval); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			log.Warnf("model-refresh: invalid interval %q, using %s", raw, defaultModelRefreshInterval)
+		} else {
+			interval = d
+		}
+	}
+	jitter := cfg.ModelRefresh.Jitter
+	if jitter <= 0 || jitter >= 1 {
+		jitter = defaultModelRefreshJitter
+	}
+
+	r := &s.modelRefresh
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+	if interval == 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	go s.modelRefreshLoop(ctx, interval, jitter)
+}
+
+func (s *Service) stopModelRefresh() {
+	r := &s.modelRefresh
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+}
+
+func (s *Service) modelRefreshLoop(ctx context.Context, interval time.Duration, jitter float64) {
+	for {
+		// Spread instances and providers so upstreams are not hit at once.
+		wait := time.Duration(float64(interval) * (1 + jitter*(2*rand.Float64()-1)))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		for _, p := range dynamicModelProviders {
+			if ctx.Err() != nil {
+				return
+			}
+			if _, err := s.RefreshProviderModels(ctx, p); err != nil {
+				log.Debugf("model refresh: %s: %v", p, err)
+			}
+		}
+	}
+}
+
+// RefreshProviderModels re-fetches the model lists of every enabled auth of
+// providerName from upstream, registers them and returns how the provider's
+// models changed. Changes are logged.
+func (s *Service) RefreshProviderModels(ctx context.Context, providerName string) (registry.ModelChanges, error) {
+	providerName = strings.ToLower(strings.TrimSpace(providerName))
+	if !slices.Contains(dynamicModelProviders, providerName) {
+		return registry.ModelChanges{}, fmt.Errorf("provider %q does not publish its models; refreshable providers: %s",
+			providerName, strings.Join(dynamicModelProviders, ", "))
+	}
+	if s == nil || s.coreManager == nil {
+		return registry.ModelChanges{}, fmt.Errorf("service not running")
+	}
+	lock, _ := s.modelRefresh.refreshing.LoadOrStore(providerName, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	s.cfgMu.RLock()
+	cfg := s.cfg
+	s.cfgMu.RUnlock()
+
+	var auths []*provider.Auth
+	for _, a := range s.coreManager.List() {
+		if a != nil && !a.Disabled && strings.EqualFold(strings.TrimSpace(a.Provider), providerName) {
+			auths = append(auths, a)
+		}
+	}
+	if len(auths) == 0 {
+		return registry.ModelChanges{}, fmt.Errorf("no enabled %s auths", providerName)
+	}
+
+	var before, after []*ModelInfo
+	failed := 0
+	for _, a := range auths {
+		if ctx.Err() != nil {
+			return registry.ModelChanges{}, ctx.Err()
+		}
+		prev, _ := registeredModels.Load(a.ID)
+		lastRegisteredVersion.Delete(a.ID)
+		registerModelsForAuth(a, cfg, s.wsGateway)
+		cur, _ := registeredModels.Load(a.ID)
+		prevSet, _ := prev.(registeredModelSet)
+		curSet, _ := cur.(registeredModelSet)
+		switch {
+		case !curSet.fetched:
+			// A failed fetch says nothing about what upstream serves; keep
+			// the last fetched list rather than the built-in one.
+			failed++
+			if prevSet.fetched {
+				GlobalModelRegistry().RegisterClient(a.ID, providerName, prevSet.models)
+				registeredModels.Store(a.ID, prevSet)
+			}
+		case prevSet.fetched:
+			before = append(before, prevSet.models...)
+			after = append(after, curSet.models...)
+		}
+	}
+	if failed == len(auths) {
+		return registry.ModelChanges{}, fmt.Errorf("fetching %s models failed for every auth", providerName)
+	}
+	changes := registry.DiffModels(before, after)
+	if !changes.Empty() {
+		log.Infof("model refresh: %s models changed upstream: added %v, removed %v, changed %v",
+			providerName, changes.Added, changes.Removed, changes.Changed)
+	}
+	return changes, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -52,12 +53,14 @@ func registerModelsForAuth(a *provider.Auth, cfg *config.Config, wsGateway *wsre
 	}
 	excluded := oauthExcludedModels(providerName, authKind, cfg)
 	var models []*ModelInfo
+	fetched := false // models came from upstream rather than a built-in list
 	switch providerName {
 	case "gemini":
 		// Try dynamic fetch first, fallback to static
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		models = providers.FetchGeminiModels(ctx, a, cfg)
 		cancel()
+		fetched = len(models) > 0
 		if len(models) == 0 {
 			models = registry.GetGeminiModelsForProvider("gemini")
 		}
@@ -72,6 +75,7 @@ func registerModelsForAuth(a *provider.Auth, cfg *config.Config, wsGateway *wsre
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		models = providers.FetchVertexModels(ctx, a, cfg)
 		cancel()
+		fetched = len(models) > 0
 		if len(models) == 0 {
 			models = registry.GetGeminiModelsForProvider("vertex")
 		}
@@ -86,6 +90,7 @@ func registerModelsForAuth(a *provider.Auth, cfg *config.Config, wsGateway *wsre
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		models = providers.FetchGeminiCLIModels(ctx, a, cfg)
 		cancel()
+		fetched = len(models) > 0
 		if len(models) == 0 {
 			models = registry.GetGeminiModelsForProvider("gemini-cli")
 		}
@@ -96,6 +101,7 @@ func registerModelsForAuth(a *provider.Auth, cfg *config.Config, wsGateway *wsre
 			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 			models = providers.FetchAIStudioModels(ctx, a, wsGateway)
 			cancel()
+			fetched = len(models) > 0
 		}
 		if len(models) == 0 {
 			models = registry.GetGeminiModelsForProvider("aistudio")
@@ -105,6 +111,7 @@ func registerModelsForAuth(a *provider.Auth, cfg *config.Config, wsGateway *wsre
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		models = providers.FetchAntigravityModels(ctx, a, cfg)
 		cancel()
+		fetched = len(models) > 0
 		if len(models) == 0 {
 			// Use single source of truth: GetAntigravityFallbackModels
 			// This preserves "antigravity" type and applies hidden filter
@@ -145,6 +152,11 @@ func registerModelsForAuth(a *provider.Auth, cfg *config.Config, wsGateway *wsre
 	case "github-copilot":
 		models = registry.GetGitHubCopilotModels()
 		models = applyExcludedModels(models, excluded)
+	case "mock":
+		if entry := cfg.MockProvider(a.Label, a.Attributes["api_key"]); entry != nil {
+			models = buildMockConfigModels(entry)
+			models = applyExcludedModels(models, entry.ExcludedModels)
+		}
 	default:
 		handleOpenAICompatProvider(a, compatProviderKey, compatDisplayName, compatDetected, cfg)
 		return
@@ -158,10 +170,12 @@ func registerModelsForAuth(a *provider.Auth, cfg *config.Config, wsGateway *wsre
 		log.Debugf("registerModelsForAuth: registering %d models for client=%s, key=%s", len(models), a.ID, key)
 		GlobalModelRegistry().RegisterClient(a.ID, key, models)
 		lastRegisteredVersion.Store(a.ID, a.MaterialVersion)
+		registeredModels.Store(a.ID, registeredModelSet{models: models, fetched: fetched})
 		return
 	}
 
 	GlobalModelRegistry().UnregisterClient(a.ID)
+	registeredModels.Delete(a.ID)
 }
 
 // handleOpenAICompatProvider handles OpenAI-compatible provider registration.
@@ -208,23 +222,29 @@ func handleOpenAICompatProvider(a *provider.Auth, compatProviderKey, compatDispl
 	}
 	for i := range cfg.Providers {
 		p := &cfg.Providers[i]
-		if p.Type == config.ProviderTypeOpenAI && strings.EqualFold(p.Name, compatName) {
+		if p.Type.IsOpenAICompatible() && strings.EqualFold(p.Name, compatName) {
 			isCompatAuth = true
+			var probed []*ModelInfo
+			if p.Type.IsLocalServer() {
+				probed = probeLocalServer(a, p)
+			}
 			ms := make([]*ModelInfo, 0, len(p.Models))
 			for j := range p.Models {
 				m := p.Models[j]
-				modelID := m.Alias
-				if modelID == "" {
-					modelID = m.Name
+				for _, modelID := range cfg.OpenAIModelIDs(p.Name, m.ModelID()) {
+					ms = append(ms, &ModelInfo{
+						ID:            modelID,
+						Object:        "model",
+						Created:       time.Now().Unix(),
+						OwnedBy:       p.Name,
+						Type:          "openai-compatibility",
+						DisplayName:   m.Name,
+						ContextLength: probedContextLength(probed, m.Name),
+					})
 				}
-				ms = append(ms, &ModelInfo{
-					ID:          modelID,
-					Object:      "model",
-					Created:     time.Now().Unix(),
-					OwnedBy:     p.Name,
-					Type:        "openai-compatibility",
-					DisplayName: m.Name,
-				})
+			}
+			if len(p.Models) == 0 {
+				ms = applyExcludedModels(probed, p.ExcludedModels)
 			}
 			if len(ms) > 0 {
 				if providerKey == "" {
@@ -244,6 +264,35 @@ func handleOpenAICompatProvider(a *provider.Auth, compatProviderKey, compatDispl
 	}
 }
 
+// probeLocalServer checks a llama.cpp or vLLM server and returns the models
+// it serves. Configured models the server does not serve are logged.
+func probeLocalServer(a *provider.Auth, p *config.Provider) []*ModelInfo {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	models, err := providers.ProbeLocalServer(ctx, p, a.Attributes["api_key"])
+	if err != nil {
+		log.Warnf("%s %s: model discovery failed: %v", p.Type, p.Name, err)
+		return nil
+	}
+	for _, m := range p.Models {
+		if len(models) > 0 && !slices.ContainsFunc(models, func(info *ModelInfo) bool { return info.ID == m.Name }) {
+			log.Warnf("%s %s: configured model %s is not served", p.Type, p.Name, m.Name)
+		}
+	}
+	return models
+}
+
+// probedContextLength returns the context length the server reported for
+// model, or 0.
+func probedContextLength(probed []*ModelInfo, model string) int {
+	for _, info := range probed {
+		if info.ID == model {
+			return info.ContextLength
+		}
+	}
+	return 0
+}
+
 func resolveProvider(auth *provider.Auth, cfg *config.Config, providerType config.ProviderType) *config.Provider {
 	if auth == nil || cfg == nil {
 		return nil
@@ -4,10 +4,13 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"os"
+	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -16,14 +19,22 @@ import (
 	"github.com/nghyane/llm-mux/internal/api"
 	"github.com/nghyane/llm-mux/internal/auth/login"
 	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/conversation"
 	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/runtime/canary"
 	"github.com/nghyane/llm-mux/internal/runtime/executor"
+	"github.com/nghyane/llm-mux/internal/runtime/gctuning"
+	"github.com/nghyane/llm-mux/internal/runtime/overload"
+	"github.com/nghyane/llm-mux/internal/runtime/warmpool"
+	"github.com/nghyane/llm-mux/internal/translator/preprocess"
 	"github.com/nghyane/llm-mux/internal/transport"
 	"github.com/nghyane/llm-mux/internal/usage"
 	"github.com/nghyane/llm-mux/internal/util"
 	"github.com/nghyane/llm-mux/internal/watcher"
 	"github.com/nghyane/llm-mux/internal/wsrelay"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 )
 
 // Service wraps the proxy server lifecycle so external programs can embed the CLI proxy.
@@ -53,6 +64,10 @@ type Service struct {
 
 	shutdownOnce sync.Once
 	wsGateway    *wsrelay.Manager
+
+	reconciler        *usage.Reconciler
+	spendBootstrapped bool
+	modelRefresh      modelRefresher
 }
 
 // RegisterUsagePlugin registers a usage plugin on the global usage manager.
@@ -293,6 +308,658 @@ func (s *Service) applyRetryConfig(cfg *config.Config) {
 	}
 }
 
+func (s *Service) applyLatencySLOConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	rules := make([]provider.LatencySLO, 0, len(cfg.Routing.LatencySLOs))
+	for _, slo := range cfg.Routing.LatencySLOs {
+		model := strings.TrimSpace(slo.Model)
+		ttft, err := time.ParseDuration(strings.TrimSpace(slo.TTFT))
+		if model == "" || err != nil || ttft <= 0 {
+			log.Warnf("ignoring latency SLO for model %q: invalid ttft %q", slo.Model, slo.TTFT)
+			continue
+		}
+		rule := provider.LatencySLO{
+			ModelPattern: model,
+			TTFT:         ttft,
+			Percentile:   slo.Percentile,
+			MinSamples:   slo.MinSamples,
+		}
+		if d, errParse := time.ParseDuration(strings.TrimSpace(slo.Window)); errParse == nil {
+			rule.Window = d
+		}
+		if d, errParse := time.ParseDuration(strings.TrimSpace(slo.DemoteFor)); errParse == nil {
+			rule.DemoteFor = d
+		}
+		rules = append(rules, rule)
+	}
+	s.coreManager.SetLatencySLOs(rules)
+}
+
+func (s *Service) applyErrorRateConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	er := cfg.Routing.ErrorRateDisable
+	if !er.Enabled {
+		s.coreManager.SetErrorRatePolicy(nil)
+		return
+	}
+	policy := &provider.ErrorRatePolicy{
+		MinRequests:  er.MinRequests,
+		MaxErrorRate: er.MaxErrorRate,
+		Statuses:     er.Statuses,
+	}
+	if raw := strings.TrimSpace(er.Window); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			policy.Window = d
+		} else {
+			log.Warnf("error-rate-disable: invalid window %q, using the default", er.Window)
+		}
+	}
+	if raw := strings.TrimSpace(er.DisableFor); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			policy.DisableFor = d
+		} else {
+			log.Warnf("error-rate-disable: invalid disable-for %q, using the default", er.DisableFor)
+		}
+	}
+	s.coreManager.SetErrorRatePolicy(policy)
+}
+
+func (s *Service) applyConcurrencyLimitConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	rules := make([]provider.ConcurrencyLimit, 0, len(cfg.Routing.ConcurrencyLimits))
+	for _, cl := range cfg.Routing.ConcurrencyLimits {
+		model := strings.TrimSpace(cl.Model)
+		if model == "" || cl.MaxPerAuth <= 0 {
+			log.Warnf("ignoring concurrency limit for model %q: max-per-auth must be positive", cl.Model)
+			continue
+		}
+		rule := provider.ConcurrencyLimit{
+			Provider:     strings.ToLower(strings.TrimSpace(cl.Provider)),
+			ModelPattern: model,
+			MaxPerAuth:   cl.MaxPerAuth,
+		}
+		if qt := strings.TrimSpace(cl.QueueTimeout); qt != "" {
+			d, errParse := time.ParseDuration(qt)
+			switch {
+			case errParse != nil:
+				log.Warnf("concurrency limit for model %q: invalid queue-timeout %q, using default", cl.Model, cl.QueueTimeout)
+			case d <= 0:
+				rule.QueueTimeout = -1
+			default:
+				rule.QueueTimeout = d
+			}
+		}
+		rules = append(rules, rule)
+	}
+	s.coreManager.SetConcurrencyLimits(rules)
+}
+
+func (s *Service) applyAuthFilterConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	rules := make([]provider.AuthTagFilter, 0, len(cfg.Routing.AuthFilters))
+	for _, af := range cfg.Routing.AuthFilters {
+		if len(af.Tags) == 0 {
+			log.Warnf("ignoring auth filter for model %q: no tags", af.Model)
+			continue
+		}
+		rules = append(rules, provider.AuthTagFilter{
+			Provider:     strings.ToLower(strings.TrimSpace(af.Provider)),
+			ModelPattern: strings.TrimSpace(af.Model),
+			APIKeys:      af.APIKeys,
+			Tags:         af.Tags,
+		})
+	}
+	s.coreManager.SetAuthTagFilters(rules)
+}
+
+func (s *Service) applyRequestPriorityConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	rp := cfg.Routing.RequestPriority
+	minPriority := provider.PriorityHigh
+	if rp.PremiumMin != "" {
+		p, ok := provider.ParsePriority(rp.PremiumMin)
+		if !ok {
+			log.Warnf("request priority: invalid premium-min %q, using high", rp.PremiumMin)
+		} else {
+			minPriority = p
+		}
+	}
+	s.coreManager.SetPremiumAuths(rp.PremiumTags, minPriority)
+}
+
+// reportAliasConflicts logs model IDs exposed by several openai providers and
+// how routing.alias-conflicts resolves them.
+func (s *Service) reportAliasConflicts(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	strategy := strings.ToLower(strings.TrimSpace(cfg.Routing.AliasConflicts.Strategy))
+	switch strategy {
+	case "":
+		strategy = config.AliasConflictLoadBalance
+	case config.AliasConflictFirstWins, config.AliasConflictSuffixByProvider, config.AliasConflictLoadBalance:
+	default:
+		log.Warnf("unknown alias-conflicts strategy %q, using %s", cfg.Routing.AliasConflicts.Strategy, config.AliasConflictLoadBalance)
+		strategy = config.AliasConflictLoadBalance
+	}
+	conflicts := cfg.OpenAIAliasConflicts()
+	ids := make([]string, 0, len(conflicts))
+	for id := range conflicts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		log.Infof("model %q is exposed by openai providers %s (%s)", id, strings.Join(conflicts[id], ", "), strategy)
+	}
+}
+
+func (s *Service) applyCostRoutingConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	routeBy := strings.ToLower(strings.TrimSpace(cfg.Routing.RouteBy))
+	if routeBy != "" && routeBy != provider.RouteByCost {
+		log.Warnf("unknown routing route-by %q, using default ordering", cfg.Routing.RouteBy)
+	}
+	prices := make([]provider.ModelPrice, 0, len(cfg.Routing.Pricing))
+	for _, mp := range cfg.Routing.Pricing {
+		model := strings.TrimSpace(mp.Model)
+		if model == "" || mp.Input < 0 || mp.Output < 0 {
+			log.Warnf("ignoring pricing entry for model %q: model required and prices must not be negative", mp.Model)
+			continue
+		}
+		prices = append(prices, provider.ModelPrice{
+			Provider:     strings.ToLower(strings.TrimSpace(mp.Provider)),
+			ModelPattern: model,
+			Input:        mp.Input,
+			Output:       mp.Output,
+		})
+	}
+	s.coreManager.SetCostRouting(routeBy == provider.RouteByCost, prices)
+}
+
+func (s *Service) applyRoutingScheduleConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	loc := routingLocation(cfg)
+
+	schedules := make([]provider.RoutingSchedule, 0, len(cfg.Routing.Schedules))
+	for _, rs := range cfg.Routing.Schedules {
+		schedule := provider.RoutingSchedule{
+			ModelPattern: strings.TrimSpace(rs.Model),
+			Prefer:       lowerTrimmed(rs.Prefer),
+			Avoid:        lowerTrimmed(rs.Avoid),
+		}
+		var errParse error
+		if schedule.Days, errParse = parseWeekdays(rs.Days); errParse != nil {
+			log.Warnf("ignoring routing schedule for model %q: %v", rs.Model, errParse)
+			continue
+		}
+		if schedule.Start, errParse = parseClock(rs.From); errParse != nil {
+			log.Warnf("ignoring routing schedule for model %q: invalid from %q", rs.Model, rs.From)
+			continue
+		}
+		if schedule.End, errParse = parseClock(rs.To); errParse != nil {
+			log.Warnf("ignoring routing schedule for model %q: invalid to %q", rs.Model, rs.To)
+			continue
+		}
+		if len(schedule.Prefer) == 0 && len(schedule.Avoid) == 0 {
+			log.Warnf("ignoring routing schedule for model %q: prefer or avoid required", rs.Model)
+			continue
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	budgets := make([]provider.QuotaBudget, 0, len(cfg.Routing.QuotaBudgets))
+	for _, qb := range cfg.Routing.QuotaBudgets {
+		if qb.DailyTokens <= 0 && qb.DailyRequests <= 0 {
+			log.Warnf("ignoring quota budget for provider %q: daily-tokens or daily-requests required", qb.Provider)
+			continue
+		}
+		budgets = append(budgets, provider.QuotaBudget{
+			Provider:      strings.ToLower(strings.TrimSpace(qb.Provider)),
+			ModelPattern:  strings.TrimSpace(qb.Model),
+			DailyTokens:   qb.DailyTokens,
+			DailyRequests: qb.DailyRequests,
+		})
+	}
+	s.coreManager.SetRoutingSchedules(loc, schedules, budgets)
+}
+
+func (s *Service) applySpendLimitConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	limits := make([]provider.SpendLimit, 0, len(cfg.Routing.SpendLimits))
+	for _, sl := range cfg.Routing.SpendLimits {
+		if sl.MonthlyTokens <= 0 && sl.MonthlyCost <= 0 {
+			log.Warnf("ignoring spend limit for provider %q: monthly-tokens or monthly-cost required", sl.Provider)
+			continue
+		}
+		if sl.WarnAt < 0 || sl.WarnAt > 1 {
+			log.Warnf("spend limit for provider %q: warn-at must be between 0 and 1, using 0.8", sl.Provider)
+			sl.WarnAt = 0
+		}
+		limits = append(limits, provider.SpendLimit{
+			Provider:      strings.ToLower(strings.TrimSpace(sl.Provider)),
+			AuthID:        strings.TrimSpace(sl.Auth),
+			MonthlyTokens: sl.MonthlyTokens,
+			MonthlyCost:   sl.MonthlyCost,
+			WarnAt:        sl.WarnAt,
+		})
+	}
+	limiter := s.coreManager.SpendLimiter()
+	s.coreManager.SetSpendLimits(routingLocation(cfg), limits)
+	if len(limits) == 0 || s.spendBootstrapped {
+		return
+	}
+	// Seed month-to-date tokens from persisted usage so a restart does not
+	// reset the ceilings. Costs are only counted from startup.
+	s.spendBootstrapped = true
+	backend := usage.GetLoggerPlugin().GetBackend()
+	if backend == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stats, err := backend.QueryAuthStats(ctx, limiter.MonthStart())
+	if err != nil {
+		log.Warnf("spend limits: failed to load month-to-date usage: %v", err)
+		return
+	}
+	tokens := make(map[string]int64, len(stats))
+	for _, st := range stats {
+		tokens[st.AuthID] = st.TotalTokens
+	}
+	limiter.Bootstrap(tokens)
+}
+
+// routingLocation returns the time zone for routing schedules and budgets.
+func routingLocation(cfg *config.Config) *time.Location {
+	tz := strings.TrimSpace(cfg.Routing.Timezone)
+	if tz == "" {
+		return time.Local
+	}
+	loc, errLoad := time.LoadLocation(tz)
+	if errLoad != nil {
+		log.Warnf("invalid routing timezone %q, using local time: %v", cfg.Routing.Timezone, errLoad)
+		return time.Local
+	}
+	return loc
+}
+
+func lowerTrimmed(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// parseClock parses "HH:MM" into an offset from midnight. Empty is midnight.
+func parseClock(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func parseWeekdays(days []string) ([]time.Weekday, error) {
+	out := make([]time.Weekday, 0, len(days))
+	for _, d := range days {
+		name := strings.ToLower(strings.TrimSpace(d))
+		found := false
+		for wd := time.Sunday; wd <= time.Saturday; wd++ {
+			if full := strings.ToLower(wd.String()); name == full || name == full[:3] {
+				out = append(out, wd)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown day %q", d)
+		}
+	}
+	return out, nil
+}
+
+func (s *Service) applyRuntimeConfig(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	rc := cfg.Runtime
+	settings := gctuning.Settings{GCPercent: rc.GCPercent}
+	var errParse error
+	if settings.MemoryLimit, errParse = gctuning.ParseSize(rc.MemoryLimit); errParse != nil {
+		log.Warnf("runtime: ignoring memory-limit: %v", errParse)
+	}
+	if settings.Ballast, errParse = gctuning.ParseSize(rc.Ballast); errParse != nil {
+		log.Warnf("runtime: ignoring ballast: %v", errParse)
+	}
+	gctuning.Apply(settings)
+
+	oc := rc.Overload
+	overloadSettings := overload.Settings{
+		Enabled:        oc.Enabled,
+		MaxGoroutines:  oc.MaxGoroutines,
+		CriticalFactor: oc.CriticalFactor,
+	}
+	maxLatency := strings.TrimSpace(oc.MaxSchedulerLatency)
+	if maxLatency == "" {
+		maxLatency = "50ms"
+	}
+	if overloadSettings.MaxSchedulerLatency, errParse = time.ParseDuration(maxLatency); errParse != nil {
+		log.Warnf("runtime: ignoring overload.max-scheduler-latency: %v", errParse)
+	}
+	if oc.RetryAfter != "" {
+		if overloadSettings.RetryAfter, errParse = time.ParseDuration(oc.RetryAfter); errParse != nil {
+			log.Warnf("runtime: ignoring overload.retry-after: %v", errParse)
+		}
+	}
+	overload.Apply(overloadSettings)
+
+	pc := cfg.RemoteManagement.Pprof
+	if pc.Enable {
+		runtime.SetBlockProfileRate(pc.BlockProfileRate)
+		runtime.SetMutexProfileFraction(pc.MutexProfileFraction)
+	} else {
+		runtime.SetBlockProfileRate(0)
+		runtime.SetMutexProfileFraction(0)
+	}
+}
+
+func (s *Service) applyWarmPoolConfig(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	var targets []warmpool.Target
+	for i := range cfg.Providers {
+		p := &cfg.Providers[i]
+		kw := p.KeepWarm
+		if len(kw.Models) == 0 || !p.IsEnabled() {
+			continue
+		}
+		if p.Type != config.ProviderTypeOpenAI {
+			log.Warnf("keep-warm: provider %s is not of type openai, ignoring", p.GetDisplayName())
+			continue
+		}
+		target := warmpool.Target{Provider: p.GetDisplayName(), BaseURL: p.BaseURL, Models: kw.Models}
+		if keys := p.GetAPIKeys(); len(keys) > 0 {
+			target.APIKey = keys[0].Key
+		}
+		var err error
+		if kw.Interval != "" {
+			if target.Interval, err = time.ParseDuration(kw.Interval); err != nil {
+				log.Warnf("keep-warm: provider %s: invalid interval %q, using %s", target.Provider, kw.Interval, warmpool.DefaultInterval)
+			}
+		}
+		if kw.KeepAlive != "" {
+			if target.KeepAlive, err = time.ParseDuration(kw.KeepAlive); err != nil {
+				log.Warnf("keep-warm: provider %s: invalid keep-alive %q, using %s", target.Provider, kw.KeepAlive, warmpool.DefaultKeepAlive)
+			}
+		}
+		targets = append(targets, target)
+	}
+	warmpool.Configure(targets)
+}
+
+func (s *Service) applyCanaryConfig(cfg *config.Config) {
+	if s == nil || cfg == nil || s.coreManager == nil {
+		return
+	}
+	checks := make([]canary.Check, 0, len(cfg.Canaries))
+	for _, cc := range cfg.Canaries {
+		if strings.TrimSpace(cc.Model) == "" || strings.TrimSpace(cc.Prompt) == "" {
+			log.Warnf("canary %q: model and prompt are required, ignoring", cc.Name)
+			continue
+		}
+		check := canary.Check{
+			Name:      strings.TrimSpace(cc.Name),
+			Model:     strings.TrimSpace(cc.Model),
+			Prompt:    cc.Prompt,
+			Expect:    cc.Expect,
+			Schema:    cc.Schema,
+			MaxTokens: cc.MaxTokens,
+		}
+		for _, p := range cc.Providers {
+			if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+				check.Providers = append(check.Providers, p)
+			}
+		}
+		var err error
+		if cc.Interval != "" {
+			if check.Interval, err = time.ParseDuration(cc.Interval); err != nil {
+				log.Warnf("canary %q: invalid interval %q, using %s", cc.Name, cc.Interval, canary.DefaultInterval)
+			}
+		}
+		if cc.Timeout != "" {
+			if check.Timeout, err = time.ParseDuration(cc.Timeout); err != nil {
+				log.Warnf("canary %q: invalid timeout %q, using %s", cc.Name, cc.Timeout, canary.DefaultTimeout)
+			}
+		}
+		checks = append(checks, check)
+	}
+	canary.Configure(checks, canary.Env{
+		Execute:   s.executeCanary,
+		Providers: util.GetProviderName,
+		Report:    s.coreManager.MarkCanaryResult,
+	})
+}
+
+// executeCanary sends a canary payload in OpenAI format to a single provider.
+func (s *Service) executeCanary(ctx context.Context, providerName, model string, payload []byte) ([]byte, error) {
+	resp, err := s.coreManager.Execute(ctx, []string{providerName}, provider.Request{Model: model, Payload: payload}, provider.Options{
+		SourceFormat:    provider.FormatOpenAI,
+		OriginalRequest: payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Payload, nil
+}
+
+func (s *Service) applyPayloadAlertConfig(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	pa := cfg.Usage.PayloadAlerts
+	policy := usage.PayloadAlertPolicy{Limits: pa.Limits, Percentile: pa.Percentile, MinSamples: pa.MinSamples}
+	if pa.Window != "" {
+		d, err := time.ParseDuration(strings.TrimSpace(pa.Window))
+		if err != nil {
+			log.Warnf("payload alerts: invalid window %q, using the default", pa.Window)
+		}
+		policy.Window = d
+	}
+	usage.GetPayloadMonitor().SetPolicy(policy)
+}
+
+func (s *Service) applyUsageReconciliationConfig(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	rc := cfg.Usage.Reconciliation
+	var sources []usage.UsageReportSource
+	for _, src := range rc.Sources {
+		if strings.TrimSpace(src.AdminKey) == "" {
+			log.Warnf("usage reconciliation: %s source has no admin-key, skipping", src.Type)
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(src.Type)) {
+		case "anthropic":
+			sources = append(sources, &usage.AnthropicUsageSource{
+				ProviderName: cmp.Or(src.Provider, "claude"), AdminKey: src.AdminKey, BaseURL: src.BaseURL,
+			})
+		case "openai":
+			sources = append(sources, &usage.OpenAIUsageSource{
+				ProviderName: cmp.Or(src.Provider, "openai"), AdminKey: src.AdminKey, BaseURL: src.BaseURL,
+			})
+		default:
+			log.Warnf("usage reconciliation: unknown source type %q", src.Type)
+		}
+	}
+	if s.reconciler == nil {
+		if len(sources) == 0 {
+			return
+		}
+		plugin := usage.GetLoggerPlugin()
+		if plugin.GetBackend() == nil {
+			log.Warnf("usage reconciliation requires usage.dsn, disabled")
+			return
+		}
+		s.reconciler = usage.NewReconciler(plugin.GetBackend())
+	}
+	var interval time.Duration
+	if rc.Interval != "" {
+		d, err := time.ParseDuration(rc.Interval)
+		if err != nil {
+			log.Warnf("usage reconciliation: invalid interval %q, using 24h", rc.Interval)
+		}
+		interval = d
+	}
+	s.reconciler.Configure(sources, interval, cmp.Or(rc.LookbackDays, 3))
+}
+
+func (s *Service) applyResponseValidationConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	rv := cfg.ResponseValidation
+	s.coreManager.SetResponseValidation(provider.ResponseValidation{
+		Enabled:         rv.Enabled,
+		Retry:           rv.Retry,
+		RepeatThreshold: rv.RepeatThreshold,
+	})
+}
+
+func (s *Service) applyStreamEncodingConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	form := strings.ToLower(strings.TrimSpace(cfg.StreamEncoding.Normalize))
+	switch form {
+	case "", "nfc", "nfkc":
+	default:
+		log.Warnf("unknown stream-encoding normalize %q, not normalizing", cfg.StreamEncoding.Normalize)
+		form = ""
+	}
+	s.coreManager.SetStreamNormalization(form)
+}
+
+func (s *Service) applySystemPromptsConfig(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	prompts := make([]preprocess.SystemPrompt, 0, len(cfg.SystemPrompts))
+	for _, t := range cfg.SystemPrompts {
+		prompts = append(prompts, preprocess.SystemPrompt{
+			Name:      strings.TrimSpace(t.Name),
+			Version:   t.Version,
+			Models:    t.Models,
+			WithTools: t.WithTools,
+			Append:    strings.EqualFold(strings.TrimSpace(t.Position), "append"),
+			Template:  t.Template,
+		})
+	}
+	preprocess.SetSystemPrompts(prompts)
+}
+
+func (s *Service) applyToolLoopGuardConfig(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	gc := cfg.ToolLoopGuard
+	guard := preprocess.ToolLoopGuard{
+		Threshold: gc.Threshold,
+		Action:    strings.ToLower(strings.TrimSpace(gc.Action)),
+		Notice:    strings.TrimSpace(gc.Notice),
+	}
+	if guard.Action == "" {
+		guard.Action = preprocess.ToolLoopNotice
+	}
+	if guard.Action != preprocess.ToolLoopNotice && guard.Action != preprocess.ToolLoopError {
+		log.Warnf("unknown tool-loop-guard action %q, using %s", gc.Action, preprocess.ToolLoopNotice)
+		guard.Action = preprocess.ToolLoopNotice
+	}
+	preprocess.SetToolLoopGuard(guard)
+}
+
+func (s *Service) applyToolResultGuardConfig(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	gc := cfg.ToolResultGuard
+	guard := preprocess.ToolResultGuard{
+		MaxBytes:     gc.MaxBytes,
+		Strategy:     strings.ToLower(strings.TrimSpace(gc.Strategy)),
+		HeadBytes:    gc.HeadBytes,
+		TailBytes:    gc.TailBytes,
+		SummaryModel: strings.TrimSpace(gc.SummaryModel),
+	}
+	if guard.Strategy == "" {
+		guard.Strategy = preprocess.ToolResultTruncate
+	}
+	if guard.Strategy != preprocess.ToolResultTruncate && guard.Strategy != preprocess.ToolResultSummarize {
+		log.Warnf("unknown tool-result-guard strategy %q, using %s", gc.Strategy, preprocess.ToolResultTruncate)
+		guard.Strategy = preprocess.ToolResultTruncate
+	}
+	if guard.Strategy == preprocess.ToolResultSummarize && guard.SummaryModel == "" {
+		log.Warnf("tool-result-guard strategy summarize requires summary-model, using %s", preprocess.ToolResultTruncate)
+		guard.Strategy = preprocess.ToolResultTruncate
+	}
+	if d, errParse := time.ParseDuration(strings.TrimSpace(gc.SummaryTimeout)); errParse == nil {
+		guard.SummaryTimeout = d
+	}
+	preprocess.SetToolResultGuard(guard)
+	if s.coreManager != nil {
+		preprocess.SetToolResultSummarizer(s.summarizeToolResult)
+	}
+}
+
+// summarizeToolResult condenses a tool result through the provider manager using an
+// OpenAI-format request, so any configured provider can serve the summary model.
+func (s *Service) summarizeToolResult(ctx context.Context, model, text string) (string, error) {
+	providers := util.GetProviderName(model)
+	if len(providers) == 0 {
+		return "", fmt.Errorf("no provider for summary model %s", model)
+	}
+	payload := []byte(`{"model":"","stream":false,"messages":[{"role":"system","content":""},{"role":"user","content":""}]}`)
+	payload, _ = sjson.SetBytes(payload, "model", model)
+	payload, _ = sjson.SetBytes(payload, "messages.0.content", toolResultSummaryPrompt)
+	payload, _ = sjson.SetBytes(payload, "messages.1.content", text)
+
+	resp, err := s.coreManager.Execute(ctx, providers, provider.Request{Model: model, Payload: payload}, provider.Options{
+		SourceFormat:    provider.FormatOpenAI,
+		OriginalRequest: payload,
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(gjson.GetBytes(resp.Payload, "choices.0.message.content").String()), nil
+}
+
+const toolResultSummaryPrompt = "Summarize the following tool output for another model that must continue the task. " +
+	"Preserve identifiers, file paths, numbers, error messages and anything needed to act on the result. " +
+	"Reply with the summary only."
+
 func openAICompatInfoFromAuth(a *provider.Auth) (providerKey string, compatName string, ok bool) {
 	if a == nil {
 		return "", "", false
@@ -367,6 +1034,27 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 
 	s.applyRetryConfig(s.cfg)
+	s.applyLatencySLOConfig(s.cfg)
+	s.applyErrorRateConfig(s.cfg)
+	s.applyConcurrencyLimitConfig(s.cfg)
+	s.applyAuthFilterConfig(s.cfg)
+	s.applyRequestPriorityConfig(s.cfg)
+	s.reportAliasConflicts(s.cfg)
+	s.applyCostRoutingConfig(s.cfg)
+	s.applyRoutingScheduleConfig(s.cfg)
+	s.applySpendLimitConfig(s.cfg)
+	s.applyResponseValidationConfig(s.cfg)
+	s.applyStreamEncodingConfig(s.cfg)
+	log.SetRedactedSecrets(s.cfg.SecretValues())
+	s.applyToolResultGuardConfig(s.cfg)
+	s.applyToolLoopGuardConfig(s.cfg)
+	s.applySystemPromptsConfig(s.cfg)
+	s.applyRuntimeConfig(s.cfg)
+	s.applyUsageReconciliationConfig(s.cfg)
+	s.applyPayloadAlertConfig(s.cfg)
+	s.applyWarmPoolConfig(s.cfg)
+	s.applyCanaryConfig(s.cfg)
+	s.applyModelRefreshConfig(s.cfg)
 
 	if s.coreManager != nil {
 		if errLoad := s.coreManager.Load(ctx); errLoad != nil {
@@ -391,6 +1079,7 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 
 	s.server = api.NewServer(s.cfg, s.coreManager, s.accessManager, s.configPath, s.serverOptions...)
+	s.server.SetModelRefresher(s.RefreshProviderModels)
 
 	if s.authManager == nil {
 		s.authManager = newDefaultAuthManager()
@@ -456,6 +1145,27 @@ func (s *Service) Run(ctx context.Context) error {
 			return
 		}
 		s.applyRetryConfig(newCfg)
+		s.applyLatencySLOConfig(newCfg)
+		s.applyErrorRateConfig(newCfg)
+		s.applyConcurrencyLimitConfig(newCfg)
+		s.applyAuthFilterConfig(newCfg)
+		s.applyRequestPriorityConfig(newCfg)
+		s.reportAliasConflicts(newCfg)
+		s.applyCostRoutingConfig(newCfg)
+		s.applyRoutingScheduleConfig(newCfg)
+		s.applySpendLimitConfig(newCfg)
+		s.applyResponseValidationConfig(newCfg)
+		s.applyStreamEncodingConfig(newCfg)
+		log.SetRedactedSecrets(newCfg.SecretValues())
+		s.applyToolResultGuardConfig(newCfg)
+		s.applyToolLoopGuardConfig(newCfg)
+		s.applySystemPromptsConfig(newCfg)
+		s.applyRuntimeConfig(newCfg)
+		s.applyUsageReconciliationConfig(newCfg)
+		s.applyPayloadAlertConfig(newCfg)
+		s.applyWarmPoolConfig(newCfg)
+		s.applyCanaryConfig(newCfg)
+		s.applyModelRefreshConfig(newCfg)
 		if s.server != nil {
 			s.server.UpdateClients(newCfg)
 		}
@@ -481,6 +1191,14 @@ func (s *Service) Run(ctx context.Context) error {
 		watcherWrapper.SetAuthUpdateQueue(s.authUpdates)
 	}
 	watcherWrapper.SetConfig(s.cfg)
+	watcherWrapper.SetReloadFailureHandler(func(status watcher.ReloadStatus) {
+		if s.hooks.OnConfigReloadFailed != nil {
+			s.hooks.OnConfigReloadFailed(status)
+		}
+	})
+	if s.server != nil {
+		s.server.SetReloadStatusProvider(watcherWrapper.ReloadStatus)
+	}
 
 	watcherCtx, watcherCancel := context.WithCancel(context.Background())
 	s.watcherCancel = watcherCancel
@@ -563,7 +1281,12 @@ func (s *Service) Shutdown(ctx context.Context) error {
 			}
 		}
 
+		s.reconciler.Stop()
+		warmpool.Stop()
+		canary.Stop()
+		s.stopModelRefresh()
 		usage.StopDefault()
+		conversation.CloseDefault()
 	})
 	return shutdownErr
 }
@@ -753,3 +1476,23 @@ func buildClaudeConfigModels(entry *config.Provider) []*ModelInfo {
 	}
 	return out
 }
+
+func buildMockConfigModels(entry *config.Provider) []*ModelInfo {
+	models := entry.Models
+	if len(models) == 0 {
+		models = []config.ProviderModel{{Name: config.DefaultMockModel}}
+	}
+	now := time.Now().Unix()
+	out := make([]*ModelInfo, 0, len(models))
+	for _, m := range models {
+		out = append(out, &ModelInfo{
+			ID:          m.ModelID(),
+			Object:      "model",
+			Created:     now,
+			OwnedBy:     "llm-mux",
+			Type:        "mock",
+			DisplayName: m.Name,
+		})
+	}
+	return out
+}
@@ -80,6 +80,8 @@ type WatcherWrapper struct {
 	setUpdateQueue        func(queue chan<- watcher.AuthUpdate)
 	dispatchRuntimeUpdate func(update watcher.AuthUpdate) bool
 	markPendingWrite      func(path string)
+	reloadStatus          func() watcher.ReloadStatus
+	setReloadFailure      func(fn func(watcher.ReloadStatus))
 }
 
 // Start proxies to the underlying watcher Start implementation.
@@ -136,6 +138,22 @@ func (w *WatcherWrapper) SetAuthUpdateQueue(queue chan<- watcher.AuthUpdate) {
 	w.setUpdateQueue(queue)
 }
 
+// ReloadStatus returns the outcome of the most recent config reload.
+func (w *WatcherWrapper) ReloadStatus() watcher.ReloadStatus {
+	if w == nil || w.reloadStatus == nil {
+		return watcher.ReloadStatus{}
+	}
+	return w.reloadStatus()
+}
+
+// SetReloadFailureHandler registers a callback for rejected config reloads.
+func (w *WatcherWrapper) SetReloadFailureHandler(fn func(watcher.ReloadStatus)) {
+	if w == nil || w.setReloadFailure == nil {
+		return
+	}
+	w.setReloadFailure(fn)
+}
+
 func (w *WatcherWrapper) MarkPendingWrite(path string) {
 	if w == nil || w.markPendingWrite == nil {
 		return
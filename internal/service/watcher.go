@@ -34,5 +34,9 @@ func defaultWatcherFactory(configPath, authDir string, reload func(*config.Confi
 		markPendingWrite: func(path string) {
 			w.MarkPendingWrite(path)
 		},
+		reloadStatus: w.ReloadStatus,
+		setReloadFailure: func(fn func(watcher.ReloadStatus)) {
+			w.SetReloadFailureHandler(fn)
+		},
 	}, nil
 }
@@ -0,0 +1,98 @@
+package sseutil
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// replacementEscape replaces unpaired UTF-16 surrogate escapes.
+var replacementEscape = []byte(`\ufffd`)
+
+// SanitizeEncoding makes streamed output safe for strict JSON parsers: invalid
+// UTF-8 sequences and unpaired \uXXXX surrogate escapes are replaced with
+// U+FFFD. normalize ("nfc" or "nfkc") additionally applies that Unicode
+// normalization form. repaired reports whether invalid input was replaced;
+// normalization alone does not count as a repair.
+func SanitizeEncoding(b []byte, normalize string) (out []byte, repaired bool) {
+	out = b
+	if !utf8.Valid(out) {
+		out = bytes.ToValidUTF8(out, []byte(string(utf8.RuneError)))
+		repaired = true
+	}
+	if bytes.Contains(out, []byte(`\u`)) {
+		if fixed, ok := replaceLoneSurrogates(out); ok {
+			out = fixed
+			repaired = true
+		}
+	}
+	switch strings.ToLower(normalize) {
+	case "nfc":
+		if !norm.NFC.IsNormal(out) {
+			out = norm.NFC.Bytes(out)
+		}
+	case "nfkc":
+		if !norm.NFKC.IsNormal(out) {
+			out = norm.NFKC.Bytes(out)
+		}
+	}
+	return out, repaired
+}
+
+// replaceLoneSurrogates rewrites \uD800-\uDFFF escapes that are not part of a
+// high/low surrogate pair. It returns false when every escape is paired.
+func replaceLoneSurrogates(b []byte) ([]byte, bool) {
+	var out []byte
+	last := 0
+	for i := 0; i < len(b); i++ {
+		if b[i] != '\\' || i+1 >= len(b) {
+			continue
+		}
+		if b[i+1] != 'u' {
+			i++ // skip the escaped character, which may itself be a backslash
+			continue
+		}
+		r, ok := surrogateEscape(b, i)
+		if !ok {
+			i++
+			continue
+		}
+		if r < 0xDC00 {
+			if low, okLow := surrogateEscape(b, i+6); okLow && low >= 0xDC00 {
+				i += 11
+				continue
+			}
+		}
+		out = append(out, b[last:i]...)
+		out = append(out, replacementEscape...)
+		last = i + 6
+		i += 5
+	}
+	if out == nil {
+		return b, false
+	}
+	return append(out, b[last:]...), true
+}
+
+// surrogateEscape parses a \uXXXX escape at b[i:] holding a UTF-16 surrogate.
+func surrogateEscape(b []byte, i int) (rune, bool) {
+	if i+6 > len(b) || b[i] != '\\' || b[i+1] != 'u' {
+		return 0, false
+	}
+	var r rune
+	for _, c := range b[i+2 : i+6] {
+		switch {
+		case c >= '0' && c <= '9':
+			r = r<<4 | rune(c-'0')
+		case c >= 'a' && c <= 'f':
+			r = r<<4 | rune(c-'a'+10)
+		case c >= 'A' && c <= 'F':
+			r = r<<4 | rune(c-'A'+10)
+		default:
+			return 0, false
+		}
+	}
+	return r, r >= 0xD800 && r <= 0xDFFF
+}
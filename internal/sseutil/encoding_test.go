@@ -0,0 +1,39 @@
+package sseutil
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSanitizeEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		want     string
+		repaired bool
+	}{
+		{"valid", `data: {"text":"héllo 😀"}`, `data: {"text":"héllo 😀"}`, false},
+		{"invalid utf8", "data: {\"text\":\"a\xff\xfeb\"}", "data: {\"text\":\"a\uFFFDb\"}", true},
+		{"lone high surrogate", `{"text":"x\ud83dy"}`, `{"text":"x\ufffdy"}`, true},
+		{"lone low surrogate", `{"text":"\ude00"}`, `{"text":"\ufffd"}`, true},
+		{"truncated pair", `{"text":"\ud83dA"}`, `{"text":"\ufffdA"}`, true},
+		{"escaped backslash", `{"text":"\\ud83d"}`, `{"text":"\\ud83d"}`, false},
+	}
+	for _, tt := range tests {
+		out, repaired := SanitizeEncoding([]byte(tt.in), "")
+		if string(out) != tt.want || repaired != tt.repaired {
+			t.Errorf("%s: got %q (repaired %v), want %q (repaired %v)", tt.name, out, repaired, tt.want, tt.repaired)
+		}
+	}
+
+	out, _ := SanitizeEncoding([]byte(`{"text":"x\ud83dy"}`), "")
+	var v map[string]string
+	if err := json.Unmarshal(out, &v); err != nil || v["text"] != "x\uFFFDy" {
+		t.Errorf("repaired JSON = %q, %v", out, err)
+	}
+
+	// "e" followed by a combining acute accent composes to "é" under NFC.
+	if out, repaired := SanitizeEncoding([]byte("e\u0301"), "nfc"); string(out) != "\u00e9" || repaired {
+		t.Errorf("nfc: got %q (repaired %v)", out, repaired)
+	}
+}
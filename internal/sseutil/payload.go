@@ -1,6 +1,7 @@
 package sseutil
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/nghyane/llm-mux/internal/config"
@@ -8,13 +9,14 @@ import (
 	"github.com/tidwall/sjson"
 )
 
-// ApplyPayloadConfig applies configuration rules (defaults and overrides) to payload.
-func ApplyPayloadConfig(cfg *config.Config, model string, payload []byte) []byte {
-	return ApplyPayloadConfigWithRoot(cfg, model, "", "", payload)
+// ApplyPayloadConfig applies configuration rules (defaults and overrides) to
+// payload for a request of the client with apiKey.
+func ApplyPayloadConfig(cfg *config.Config, model, apiKey string, payload []byte) []byte {
+	return ApplyPayloadConfigWithRoot(cfg, model, apiKey, "", "", payload)
 }
 
 // ApplyPayloadConfigWithRoot applies configuration with custom root path and protocol.
-func ApplyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string, payload []byte) []byte {
+func ApplyPayloadConfigWithRoot(cfg *config.Config, model, apiKey, protocol, root string, payload []byte) []byte {
 	if cfg == nil || len(payload) == 0 {
 		return payload
 	}
@@ -29,11 +31,7 @@ func ApplyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string
 	out := payload
 
 	// Apply defaults (only if path doesn't exist)
-	for i := range rules.Default {
-		rule := &rules.Default[i]
-		if !payloadRuleMatchesModel(rule, model, protocol) {
-			continue
-		}
+	for _, rule := range matchingRules(rules.Default, model, apiKey, protocol, true) {
 		for path, value := range rule.Params {
 			fullPath := buildPayloadPath(root, path)
 			if fullPath == "" {
@@ -51,11 +49,7 @@ func ApplyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string
 	}
 
 	// Apply overrides (always set)
-	for i := range rules.Override {
-		rule := &rules.Override[i]
-		if !payloadRuleMatchesModel(rule, model, protocol) {
-			continue
-		}
+	for _, rule := range matchingRules(rules.Override, model, apiKey, protocol, false) {
 		for path, value := range rule.Params {
 			fullPath := buildPayloadPath(root, path)
 			if fullPath == "" {
@@ -71,6 +65,54 @@ func ApplyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string
 	return out
 }
 
+// PayloadRuleMatch is a payload rule that applies to a model.
+type PayloadRuleMatch struct {
+	// Kind is "default" or "override".
+	Kind   string
+	Params map[string]any
+}
+
+// MatchingPayloadRules returns the payload rules ApplyPayloadConfigWithRoot
+// would apply for model, client apiKey and protocol, defaults first, in the
+// order they are applied.
+func MatchingPayloadRules(cfg *config.Config, model, apiKey, protocol string) []PayloadRuleMatch {
+	model = strings.TrimSpace(model)
+	if cfg == nil || model == "" {
+		return nil
+	}
+	var out []PayloadRuleMatch
+	for _, rule := range matchingRules(cfg.Payload.Default, model, apiKey, protocol, true) {
+		out = append(out, PayloadRuleMatch{Kind: "default", Params: rule.Params})
+	}
+	for _, rule := range matchingRules(cfg.Payload.Override, model, apiKey, protocol, false) {
+		out = append(out, PayloadRuleMatch{Kind: "override", Params: rule.Params})
+	}
+	return out
+}
+
+// matchingRules returns the rules matching the request in configuration
+// order, with client rules first when clientFirst is set and last otherwise.
+// Defaults pass clientFirst, since the first default setting a path wins,
+// and overrides do not, since the last one wins.
+func matchingRules(rules []config.PayloadRule, model, apiKey, protocol string, clientFirst bool) []*config.PayloadRule {
+	var all, client []*config.PayloadRule
+	for i := range rules {
+		rule := &rules[i]
+		if !payloadRuleMatchesModel(rule, model, protocol) {
+			continue
+		}
+		if len(rule.APIKeys) == 0 {
+			all = append(all, rule)
+		} else if apiKey != "" && slices.Contains(rule.APIKeys, apiKey) {
+			client = append(client, rule)
+		}
+	}
+	if clientFirst {
+		return append(client, all...)
+	}
+	return append(all, client...)
+}
+
 func payloadRuleMatchesModel(rule *config.PayloadRule, model, protocol string) bool {
 	if rule == nil || len(rule.Models) == 0 {
 		return false
@@ -0,0 +1,49 @@
+package sseutil
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestApplyPayloadConfigClientRules(t *testing.T) {
+	models := []config.PayloadModelRule{{Name: "gpt-*"}}
+	cfg := &config.Config{Payload: config.PayloadConfig{
+		Default: []config.PayloadRule{
+			{Models: models, Params: map[string]any{"temperature": 0.7, "top_p": 0.9}},
+			{Models: models, Params: map[string]any{"temperature": 0}, APIKeys: []string{"team-a"}},
+		},
+		Override: []config.PayloadRule{
+			{Models: models, Params: map[string]any{"max_tokens": 4096}, APIKeys: []string{"team-b"}},
+			{Models: models, Params: map[string]any{"max_tokens": 1024}},
+		},
+	}}
+	payload := []byte(`{"model":"gpt-4o","messages":[]}`)
+
+	tests := []struct {
+		apiKey      string
+		temperature float64
+		maxTokens   int64
+	}{
+		{"", 0.7, 1024},
+		{"team-a", 0, 1024},
+		{"team-b", 0.7, 4096},
+	}
+	for _, tt := range tests {
+		out := ApplyPayloadConfig(cfg, "gpt-4o", tt.apiKey, payload)
+		if got := gjson.GetBytes(out, "temperature").Float(); got != tt.temperature {
+			t.Errorf("key %q: temperature = %v, want %v", tt.apiKey, got, tt.temperature)
+		}
+		if got := gjson.GetBytes(out, "max_tokens").Int(); got != tt.maxTokens {
+			t.Errorf("key %q: max_tokens = %v, want %v", tt.apiKey, got, tt.maxTokens)
+		}
+		if got := gjson.GetBytes(out, "top_p").Float(); got != 0.9 {
+			t.Errorf("key %q: top_p = %v, want 0.9", tt.apiKey, got)
+		}
+	}
+
+	if got := len(MatchingPayloadRules(cfg, "gpt-4o", "team-a", "")); got != 3 {
+		t.Errorf("MatchingPayloadRules for team-a = %d rules, want 3", got)
+	}
+}
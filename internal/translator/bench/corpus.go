@@ -0,0 +1,29 @@
+// Package bench replays recorded provider streams through the translator so
+// parse and render costs can be compared between revisions.
+//
+// Run "make bench" to record results and "make bench-gate" to compare them
+// against a baseline; see scripts/bench-gate.sh.
+package bench
+
+import (
+	"bytes"
+	"os"
+)
+
+// LoadCorpus reads a recorded SSE stream and returns its data lines in order.
+// Event name lines and blank separators are dropped; each returned line still
+// carries its "data:" prefix, as the stream parsers receive it upstream.
+func LoadCorpus(path string) ([][]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lines [][]byte
+	for _, line := range bytes.Split(raw, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if bytes.HasPrefix(line, []byte("data:")) {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
@@ -0,0 +1,131 @@
+package bench
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+)
+
+// longConversation returns an agent-style history of n turns, each a user
+// message, an assistant tool call and its result.
+func longConversation(n int) *ir.UnifiedChatRequest {
+	req := &ir.UnifiedChatRequest{
+		Model: "bench-model",
+		Messages: []ir.Message{{
+			Role:    ir.RoleSystem,
+			Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "You are a coding agent."}},
+		}},
+	}
+	text := strings.Repeat("Please look at the next file and explain what changed. ", 20)
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("call_%d", i)
+		req.Messages = append(req.Messages,
+			ir.Message{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: text}}},
+			ir.Message{
+				Role:      ir.RoleAssistant,
+				Content:   []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Reading the file."}},
+				ToolCalls: []ir.ToolCall{{ID: id, Name: "read_file", Args: fmt.Sprintf(`{"path":"src/file_%d.go"}`, i)}},
+			},
+			ir.Message{Role: ir.RoleTool, Content: []ir.ContentPart{{
+				Type:       ir.ContentTypeToolResult,
+				ToolResult: &ir.ToolResultPart{ToolCallID: id, Result: text},
+			}}},
+		)
+	}
+	return req
+}
+
+var requestFormats = []struct {
+	name    string
+	convert func(*ir.UnifiedChatRequest) ([]byte, error)
+	parse   func([]byte) (*ir.UnifiedChatRequest, error)
+}{
+	{"claude", (&from_ir.ClaudeProvider{}).ConvertRequest, to_ir.ParseClaudeRequest},
+	{"gemini", (&from_ir.GeminiProvider{}).ConvertRequest, to_ir.ParseGeminiRequest},
+	{"openai", from_ir.ToOpenAIRequest, to_ir.ParseOpenAIRequest},
+}
+
+// withThreshold runs fn with ir.ParallelMessagesThreshold set to threshold.
+func withThreshold(threshold int, fn func()) {
+	saved := ir.ParallelMessagesThreshold
+	ir.ParallelMessagesThreshold = threshold
+	defer func() { ir.ParallelMessagesThreshold = saved }()
+	fn()
+}
+
+// TestParallelRequestConversion checks that parallel conversion produces
+// the same result as sequential conversion.
+func TestParallelRequestConversion(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(4, runtime.NumCPU())))
+	req := longConversation(100)
+	for _, f := range requestFormats {
+		var seqBody, parBody []byte
+		var seqReq, parReq *ir.UnifiedChatRequest
+		withThreshold(0, func() {
+			seqBody, _ = f.convert(req)
+			seqReq, _ = f.parse(seqBody)
+		})
+		withThreshold(1, func() {
+			parBody, _ = f.convert(req)
+			parReq, _ = f.parse(seqBody)
+		})
+		var seq, par any
+		if err := json.Unmarshal(seqBody, &seq); err != nil {
+			t.Fatalf("%s: %v", f.name, err)
+		}
+		if err := json.Unmarshal(parBody, &par); err != nil {
+			t.Fatalf("%s: %v", f.name, err)
+		}
+		if !reflect.DeepEqual(seq, par) {
+			t.Errorf("%s: parallel ConvertRequest differs from sequential", f.name)
+		}
+		if seqReq == nil || !reflect.DeepEqual(seqReq, parReq) {
+			t.Errorf("%s: parallel request parsing differs from sequential", f.name)
+		}
+	}
+}
+
+// BenchmarkLongConversation converts and parses a history of several
+// hundred messages sequentially and in parallel. Parallel conversion only
+// differs with GOMAXPROCS > 1.
+func BenchmarkLongConversation(b *testing.B) {
+	req := longConversation(200)
+	modes := []struct {
+		name      string
+		threshold int
+	}{{"sequential", 0}, {"parallel", ir.ParallelMessagesThreshold}}
+	for _, f := range requestFormats {
+		body, err := f.convert(req)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, mode := range modes {
+			withThreshold(mode.threshold, func() {
+				b.Run("to_ir/"+f.name+"/"+mode.name, func(b *testing.B) {
+					b.ReportAllocs()
+					b.SetBytes(int64(len(body)))
+					for i := 0; i < b.N; i++ {
+						if _, err := f.parse(body); err != nil {
+							b.Fatal(err)
+						}
+					}
+				})
+				b.Run("from_ir/"+f.name+"/"+mode.name, func(b *testing.B) {
+					b.ReportAllocs()
+					for i := 0; i < b.N; i++ {
+						if _, err := f.convert(req); err != nil {
+							b.Fatal(err)
+						}
+					}
+				})
+			})
+		}
+	}
+}
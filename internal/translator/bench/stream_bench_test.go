@@ -0,0 +1,188 @@
+package bench
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+)
+
+// parser converts one upstream stream line into IR events. A new parser is
+// created per stream so stateful parsers start clean.
+type parser func(line []byte) ([]*ir.UnifiedEvent, error)
+
+var sources = []struct {
+	name   string
+	parser func() parser
+}{
+	{"claude", func() parser {
+		state := ir.NewClaudeStreamParserState()
+		return func(line []byte) ([]*ir.UnifiedEvent, error) { return to_ir.ParseClaudeChunkWithState(line, state) }
+	}},
+	{"gemini", func() parser {
+		state := ir.NewGeminiStreamParserState()
+		return func(line []byte) ([]*ir.UnifiedEvent, error) { return to_ir.ParseGeminiChunkWithState(line, state) }
+	}},
+	{"openai", func() parser { return to_ir.ParseOpenAIChunk }},
+}
+
+// renderer converts one IR event into client stream output.
+type renderer func(ev ir.UnifiedEvent) (int, error)
+
+var targets = []struct {
+	name     string
+	renderer func() renderer
+}{
+	{"openai", func() renderer {
+		return func(ev ir.UnifiedEvent) (int, error) {
+			out, err := from_ir.ToOpenAIChunk(ev, "bench-model", "chatcmpl-bench", 0)
+			return len(out), err
+		}
+	}},
+	{"responses", func() renderer {
+		state := from_ir.NewResponsesStreamState()
+		return func(ev ir.UnifiedEvent) (int, error) {
+			out, err := from_ir.ToResponsesAPIChunk(ev, "bench-model", state)
+			return len(out), err
+		}
+	}},
+	{"claude", func() renderer {
+		state := from_ir.NewClaudeStreamState()
+		return func(ev ir.UnifiedEvent) (int, error) {
+			out, err := from_ir.ToClaudeSSE(ev, state)
+			return len(out), err
+		}
+	}},
+	{"gemini", func() renderer {
+		return func(ev ir.UnifiedEvent) (int, error) {
+			out, err := from_ir.ToGeminiChunk(ev, "bench-model")
+			return len(out), err
+		}
+	}},
+	{"ollama", func() renderer {
+		return func(ev ir.UnifiedEvent) (int, error) {
+			out, err := from_ir.ToOllamaChatChunk(ev, "bench-model")
+			return len(out), err
+		}
+	}},
+}
+
+func loadSource(tb testing.TB, name string) [][]byte {
+	tb.Helper()
+	lines, err := LoadCorpus(filepath.Join("testdata", name+".sse"))
+	if err != nil {
+		tb.Fatalf("load corpus %s: %v", name, err)
+	}
+	return lines
+}
+
+func parseAll(tb testing.TB, lines [][]byte, parse parser) []ir.UnifiedEvent {
+	tb.Helper()
+	var events []ir.UnifiedEvent
+	for _, line := range lines {
+		evs, err := parse(line)
+		if err != nil {
+			tb.Fatalf("parse %s: %v", line, err)
+		}
+		for _, ev := range evs {
+			events = append(events, *ev)
+		}
+	}
+	return events
+}
+
+// TestCorporaTranslate keeps the corpora valid: every stream must parse and
+// render on every target without errors.
+func TestCorporaTranslate(t *testing.T) {
+	for _, src := range sources {
+		events := parseAll(t, loadSource(t, src.name), src.parser())
+		if len(events) == 0 {
+			t.Fatalf("%s: corpus produced no events", src.name)
+		}
+		for _, dst := range targets {
+			render := dst.renderer()
+			for _, ev := range events {
+				if _, err := render(ev); err != nil {
+					t.Fatalf("%s -> %s: render %s: %v", src.name, dst.name, ev.Type, err)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkToIR parses each recorded stream end to end.
+func BenchmarkToIR(b *testing.B) {
+	for _, src := range sources {
+		lines := loadSource(b, src.name)
+		b.Run(src.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				parse := src.parser()
+				for _, line := range lines {
+					if _, err := parse(line); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(lines)), "ns/line")
+		})
+	}
+}
+
+// BenchmarkFromIR renders events grouped by type, one event per op, so a
+// regression in a single fast path shows up on its own line.
+func BenchmarkFromIR(b *testing.B) {
+	byType := make(map[ir.EventType][]ir.UnifiedEvent)
+	var order []ir.EventType
+	for _, src := range sources {
+		for _, ev := range parseAll(b, loadSource(b, src.name), src.parser()) {
+			if _, seen := byType[ev.Type]; !seen {
+				order = append(order, ev.Type)
+			}
+			byType[ev.Type] = append(byType[ev.Type], ev)
+		}
+	}
+	for _, dst := range targets {
+		for _, et := range order {
+			events := byType[et]
+			b.Run(dst.name+"/"+string(et), func(b *testing.B) {
+				render := dst.renderer()
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := render(events[i%len(events)]); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkStream parses and renders each recorded stream for every target,
+// as the proxy does for a single streaming request.
+func BenchmarkStream(b *testing.B) {
+	for _, src := range sources {
+		lines := loadSource(b, src.name)
+		for _, dst := range targets {
+			b.Run(src.name+"_to_"+dst.name, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					parse, render := src.parser(), dst.renderer()
+					for _, line := range lines {
+						events, err := parse(line)
+						if err != nil {
+							b.Fatal(err)
+						}
+						for _, ev := range events {
+							if _, err := render(*ev); err != nil {
+								b.Fatal(err)
+							}
+						}
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Package export re-renders stored conversations into another API format
+// through the IR, for moving chat histories between tools.
+package export
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/nghyane/llm-mux/internal/translator"
+	_ "github.com/nghyane/llm-mux/internal/translator/from_ir"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	_ "github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+// Formats are the formats conversations can be exported to.
+var Formats = []string{constant.OpenAI, constant.Claude, constant.Gemini, constant.Ollama}
+
+// Conversation converts request, a request body in format from, into a
+// request body in format to carrying the same messages, system prompt and
+// tools. reply, a transcript response ({text, reasoning, tool_calls}), is
+// appended as the final assistant turn when set.
+func Conversation(from, to string, request, reply []byte) ([]byte, error) {
+	if !slices.Contains(Formats, to) {
+		return nil, fmt.Errorf("unsupported target format %q, want one of %v", to, Formats)
+	}
+	if from == constant.GeminiCLI {
+		from, request = constant.Gemini, []byte(gjson.GetBytes(request, "request").Raw)
+	}
+	if !gjson.ValidBytes(request) {
+		return nil, fmt.Errorf("request is not valid JSON")
+	}
+	req, err := translator.ParseRequest(from, request)
+	if err != nil {
+		return nil, err
+	}
+	if msg, ok := replyMessage(reply); ok {
+		req.Messages = append(req.Messages, msg)
+	}
+	return translator.ConvertRequest(to, req)
+}
+
+// replyMessage builds the assistant message of a transcript response.
+func replyMessage(reply []byte) (ir.Message, bool) {
+	msg := ir.Message{Role: ir.RoleAssistant}
+	if len(reply) == 0 || !gjson.ValidBytes(reply) {
+		return msg, false
+	}
+	r := gjson.ParseBytes(reply)
+	if reasoning := r.Get("reasoning").String(); reasoning != "" {
+		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeReasoning, Reasoning: reasoning})
+	}
+	if text := r.Get("text").String(); text != "" {
+		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeText, Text: text})
+	}
+	for _, tc := range r.Get("tool_calls").Array() {
+		msg.ToolCalls = append(msg.ToolCalls, ir.ToolCall{
+			ID:   tc.Get("id").String(),
+			Name: tc.Get("name").String(),
+			Args: tc.Get("arguments").String(),
+		})
+	}
+	return msg, len(msg.Content) > 0 || len(msg.ToolCalls) > 0
+}
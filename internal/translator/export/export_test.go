@@ -0,0 +1,36 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConversationOpenAIToClaude(t *testing.T) {
+	request := []byte(`{"model":"gpt-4o","messages":[{"role":"system","content":"Be brief."},{"role":"user","content":"What is 2+2?"}]}`)
+	reply := []byte(`{"text":"4","finish_reason":"stop"}`)
+
+	out, err := Conversation("openai", "claude", request, reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gjson.GetBytes(out, "system").String(); got != "Be brief." {
+		t.Errorf("system = %q in %s", got, out)
+	}
+	msgs := gjson.GetBytes(out, "messages").Array()
+	if len(msgs) != 2 || msgs[1].Get("role").String() != "assistant" || msgs[1].Get("content.0.text").String() != "4" {
+		t.Fatalf("messages = %s", gjson.GetBytes(out, "messages").Raw)
+	}
+
+	out, err = Conversation("claude", "gemini", out, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gjson.GetBytes(out, "contents.1.parts.0.text").String(); got != "4" || gjson.GetBytes(out, "contents.1.role").String() != "model" {
+		t.Fatalf("gemini contents = %s", gjson.GetBytes(out, "contents").Raw)
+	}
+
+	if _, err := Conversation("openai", "kiro", request, nil); err == nil {
+		t.Fatal("unsupported target accepted")
+	}
+}
@@ -3,6 +3,8 @@ package from_ir
 import (
 	"bytes"
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/nghyane/llm-mux/internal/json"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
@@ -21,33 +23,140 @@ type ClaudeStreamState struct {
 	HasTextContent   bool
 	FinishSent       bool
 	ParserState      *ir.ClaudeStreamParserState
+	// ToolBlocks maps an upstream tool call index to its tool_use block so
+	// argument fragments streamed after the first one land in the same block.
+	ToolBlocks map[int]ClaudeToolBlock
+}
+
+// ClaudeToolBlock is a tool_use content block opened for an upstream tool call.
+type ClaudeToolBlock struct {
+	Index int
+	ID    string
 }
 
 func NewClaudeStreamState() *ClaudeStreamState {
-	return &ClaudeStreamState{TextBlockIndex: 0, ParserState: ir.NewClaudeStreamParserState()}
+	return &ClaudeStreamState{TextBlockIndex: 0, ParserState: ir.NewClaudeStreamParserState(), ToolBlocks: make(map[int]ClaudeToolBlock)}
+}
+
+// claudeRequest is the Messages API request body. The caller-supplied parts
+// (metadata, schemas, server tool options) are maps, as their keys are not
+// known in advance.
+type claudeRequest struct {
+	Model         string            `json:"model"`
+	MaxTokens     int               `json:"max_tokens"`
+	Metadata      map[string]any    `json:"metadata"`
+	System        string            `json:"system,omitempty"`
+	Messages      []claudeMessage   `json:"messages"`
+	Temperature   *float64          `json:"temperature,omitempty"`
+	TopP          *float64          `json:"top_p,omitempty"`
+	TopK          *int              `json:"top_k,omitempty"`
+	StopSequences []string          `json:"stop_sequences,omitempty"`
+	Thinking      *claudeThinking   `json:"thinking,omitempty"`
+	Tools         []any             `json:"tools,omitempty"` // claudeTool or a server tool map
+	ToolChoice    *claudeToolChoice `json:"tool_choice,omitempty"`
+	MCPServers    []claudeMCPServer `json:"mcp_servers,omitempty"`
 }
 
+// claudeMessage is a message of a Claude request; an empty Role means the
+// message had nothing left to send.
+type claudeMessage struct {
+	Role         string              `json:"role"`
+	Content      any                 `json:"content"` // content parts or []claudeToolResult
+	CacheControl *claudeCacheControl `json:"cache_control,omitempty"`
+}
+
+type claudeCacheControl struct {
+	Type string `json:"type"`
+	TTL  *int64 `json:"ttl,omitempty"`
+}
+
+type claudeThinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int32  `json:"budget_tokens,omitempty"`
+}
+
+type claudeTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	InputSchema any    `json:"input_schema"`
+}
+
+type claudeToolChoice struct {
+	Type                   string `json:"type"`
+	Name                   string `json:"name,omitempty"`
+	DisableParallelToolUse bool   `json:"disable_parallel_tool_use,omitempty"`
+}
+
+type claudeMCPServer struct {
+	Type               string         `json:"type"`
+	URL                string         `json:"url"`
+	Name               string         `json:"name"`
+	AuthorizationToken string         `json:"authorization_token,omitempty"`
+	ToolConfiguration  map[string]any `json:"tool_configuration,omitempty"`
+}
+
+type claudeToolResult struct {
+	Type      string `json:"type"`
+	ToolUseID string `json:"tool_use_id"`
+	IsError   bool   `json:"is_error,omitempty"`
+	Content   any    `json:"content"` // string or []claudeResultBlock
+}
+
+// claudeResultBlock is a text, image or document block of a tool result.
+type claudeResultBlock struct {
+	Type   string        `json:"type"`
+	Text   string        `json:"text,omitempty"`
+	Title  string        `json:"title,omitempty"`
+	Source *claudeSource `json:"source,omitempty"`
+}
+
+type claudeSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+	FileID    string `json:"file_id,omitempty"`
+}
+
+var claudeRequestPool = sync.Pool{
+	New: func() any {
+		return &claudeRequest{}
+	},
+}
+
+func getClaudeRequest() *claudeRequest {
+	return claudeRequestPool.Get().(*claudeRequest)
+}
+
+// putClaudeRequest clears r but keeps its slices' capacity for the next
+// request.
+func putClaudeRequest(r *claudeRequest) {
+	clear(r.Messages)
+	clear(r.Tools)
+	*r = claudeRequest{Messages: r.Messages[:0], Tools: r.Tools[:0]}
+	claudeRequestPool.Put(r)
+}
+
+// claudeServerTools maps request metadata keys to Claude server tool names.
+var claudeServerTools = map[string]string{ir.MetaGoogleSearch: "web_search", ir.MetaClaudeComputer: "computer", ir.MetaClaudeBash: "bash", ir.MetaClaudeTextEditor: "str_replace_editor", ir.MetaCodeExecution: "code_execution"}
+
 func (p *ClaudeProvider) ConvertRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 	userID := "llm-mux-user"
 	if v, ok := req.Metadata[ir.MetaOpenAIUser].(string); ok && v != "" {
 		userID = v
 	}
 
-	root := map[string]any{"model": req.Model, "max_tokens": ir.ClaudeDefaultMaxTokens, "metadata": map[string]any{"user_id": userID}, "messages": []any{}}
+	root := getClaudeRequest()
+	defer putClaudeRequest(root)
+	root.Model = req.Model
+	root.MaxTokens = ir.ClaudeDefaultMaxTokens
+	root.Metadata = map[string]any{"user_id": userID}
 	if req.MaxTokens != nil {
-		root["max_tokens"] = *req.MaxTokens
-	}
-	if req.Temperature != nil {
-		root["temperature"] = *req.Temperature
-	}
-	if req.TopP != nil {
-		root["top_p"] = *req.TopP
-	}
-	if req.TopK != nil {
-		root["top_k"] = *req.TopK
+		root.MaxTokens = *req.MaxTokens
 	}
+	root.Temperature, root.TopP, root.TopK = req.Temperature, req.TopP, req.TopK
 	if len(req.StopSequences) > 0 {
-		root["stop_sequences"] = req.StopSequences
+		root.StopSequences = req.StopSequences
 	}
 
 	thinkingEnabled := false
@@ -57,179 +166,174 @@ func (p *ClaudeProvider) ConvertRequest(req *ir.UnifiedChatRequest) ([]byte, err
 			b = *req.Thinking.ThinkingBudget
 		}
 		if req.Thinking.IncludeThoughts && b != 0 {
-			root["thinking"] = map[string]any{"type": "enabled", "budget_tokens": b}
+			root.Thinking = &claudeThinking{Type: "enabled", BudgetTokens: b}
 			thinkingEnabled = true
 		} else if b == 0 && !req.Thinking.IncludeThoughts {
-			root["thinking"] = map[string]any{"type": "disabled"}
+			root.Thinking = &claudeThinking{Type: "disabled"}
 		}
 	}
 
-	var msgs []any
-	for _, m := range req.Messages {
-		switch m.Role {
-		case ir.RoleSystem:
-			if text := ir.CombineTextParts(m); text != "" {
-				root["system"] = text
-			}
-		case ir.RoleUser:
-			if ps := ir.BuildClaudeContentParts(m, false, false); len(ps) > 0 {
-				obj := map[string]any{"role": ir.ClaudeRoleUser, "content": ps}
-				if m.CacheControl != nil {
-					cc := map[string]any{"type": m.CacheControl.Type}
-					if m.CacheControl.TTL != nil {
-						cc["ttl"] = *m.CacheControl.TTL
-					}
-					obj["cache_control"] = cc
-				}
-				msgs = append(msgs, obj)
-			}
-		case ir.RoleAssistant:
-			if ps := ir.BuildClaudeContentParts(m, len(m.ToolCalls) > 0, thinkingEnabled); len(ps) > 0 {
-				obj := map[string]any{"role": ir.ClaudeRoleAssistant, "content": ps}
-				if m.CacheControl != nil && !ir.HasThinkingParts(m) {
-					cc := map[string]any{"type": m.CacheControl.Type}
-					if m.CacheControl.TTL != nil {
-						cc["ttl"] = *m.CacheControl.TTL
-					}
-					obj["cache_control"] = cc
-				}
-				msgs = append(msgs, obj)
-			}
-		case ir.RoleTool:
-			var toolResults []any
-			for _, p := range m.Content {
-				if p.Type == ir.ContentTypeToolResult && p.ToolResult != nil {
-					tr := map[string]any{"type": ir.ClaudeBlockToolResult, "tool_use_id": p.ToolResult.ToolCallID}
-					if p.ToolResult.IsError {
-						tr["is_error"] = true
-					}
-					if len(p.ToolResult.Images) > 0 || len(p.ToolResult.Files) > 0 {
-						var c []any
-						if p.ToolResult.Result != "" {
-							c = append(c, map[string]any{"type": "text", "text": p.ToolResult.Result})
-						}
-						for _, img := range p.ToolResult.Images {
-							s := map[string]any{}
-							if img.Data != "" {
-								s = map[string]any{"type": "base64", "media_type": img.MimeType, "data": img.Data}
-							} else if img.URL != "" {
-								s = map[string]any{"type": "url", "url": img.URL}
-							} else if img.FileID != "" {
-								s = map[string]any{"type": "file", "file_id": img.FileID}
-							}
-							if len(s) > 0 {
-								c = append(c, map[string]any{"type": ir.ClaudeBlockImage, "source": s})
-							}
-						}
-						for _, f := range p.ToolResult.Files {
-							s := map[string]any{}
-							if f.FileData != "" {
-								s = map[string]any{"type": "base64", "data": f.FileData, "media_type": f.MimeType}
-							} else if f.FileURL != "" {
-								s = map[string]any{"type": "url", "url": f.FileURL}
-							} else if f.FileID != "" {
-								s = map[string]any{"type": "file", "file_id": f.FileID}
-							}
-							if len(s) > 0 {
-								c = append(c, map[string]any{"type": ir.ClaudeBlockDocument, "title": f.Filename, "source": s})
-							}
-						}
-						tr["content"] = c
-					} else {
-						tr["content"] = p.ToolResult.Result
-					}
-					toolResults = append(toolResults, tr)
-				}
-			}
-			if len(toolResults) > 0 {
-				msgs = append(msgs, map[string]any{"role": ir.ClaudeRoleUser, "content": toolResults})
+	converted := ir.MapMessages(len(req.Messages), func(i int) claudeMessage {
+		return toClaudeMessage(&req.Messages[i], thinkingEnabled)
+	})
+	for i := range req.Messages {
+		if m := &req.Messages[i]; m.Role == ir.RoleSystem {
+			if text := ir.CombineTextParts(*m); text != "" {
+				root.System = text
 			}
+		} else if converted[i].Role != "" {
+			root.Messages = append(root.Messages, converted[i])
 		}
 	}
-	root["messages"] = msgs
 
-	var tools []any
-	for _, t := range req.Tools {
-		ps := ir.CleanJsonSchemaForClaude(ir.CopyMap(t.Parameters))
-		if ps == nil {
-			ps = map[string]any{"type": "object", "properties": map[string]any{}, "additionalProperties": false, "$schema": ir.JSONSchemaDraft202012}
+	if req.ToolChoice != "none" {
+		for _, t := range req.Tools {
+			ps := ir.CleanJsonSchemaForClaude(ir.CopyMap(t.Parameters))
+			if ps == nil {
+				ps = map[string]any{"type": "object", "properties": map[string]any{}, "additionalProperties": false, "$schema": ir.JSONSchemaDraft202012}
+			}
+			root.Tools = append(root.Tools, claudeTool{Name: t.Name, Description: t.Description, InputSchema: ps})
 		}
-		tools = append(tools, map[string]any{"name": t.Name, "description": t.Description, "input_schema": ps})
-	}
-
-	if req.Metadata != nil {
-		for k, mKey := range map[string]string{ir.MetaGoogleSearch: "web_search", ir.MetaClaudeComputer: "computer", ir.MetaClaudeBash: "bash", ir.MetaClaudeTextEditor: "str_replace_editor"} {
+		for k, mKey := range claudeServerTools {
 			if v, ok := req.Metadata[k]; ok {
-				t := map[string]any{"name": mKey}
-				if cfg, ok := v.(map[string]any); ok {
-					if ot, _ := cfg["_original_type"].(string); ot != "" {
-						t["type"] = ot
-					} else {
-						t["type"] = mKey + "_20241022"
-					}
-					for mk, mv := range cfg {
-						if mk != "_original_type" {
-							t[mk] = mv
-						}
-					}
-				} else {
-					t["type"] = mKey + "_20241022"
-				}
-				tools = append(tools, t)
+				root.Tools = append(root.Tools, claudeServerTool(k, mKey, v))
 			}
 		}
 	}
 
-	if req.ToolChoice == "none" {
-		tools = nil
-	}
-
-	if len(tools) > 0 {
-		root["tools"] = tools
-		tc := map[string]any{}
+	if len(root.Tools) > 0 {
 		switch req.ToolChoice {
 		case "function":
-			tc = map[string]any{"type": "tool", "name": req.ToolChoiceFunction}
+			root.ToolChoice = &claudeToolChoice{Type: "tool", Name: req.ToolChoiceFunction}
 		case "required", "any":
-			tc = map[string]any{"type": "any"}
+			root.ToolChoice = &claudeToolChoice{Type: "any"}
 		case "auto":
-			tc = map[string]any{"type": "auto"}
+			root.ToolChoice = &claudeToolChoice{Type: "auto"}
 		}
-		if len(tc) > 0 {
-			if req.ParallelToolCalls != nil && !*req.ParallelToolCalls {
-				tc["disable_parallel_tool_use"] = true
-			}
-			root["tool_choice"] = tc
+		if root.ToolChoice != nil && req.ParallelToolCalls != nil && !*req.ParallelToolCalls {
+			root.ToolChoice.DisableParallelToolUse = true
 		}
 	}
 
 	if len(req.MCPServers) > 0 {
-		var srvs []any
-		for _, s := range req.MCPServers {
-			srv := map[string]any{"type": s.Type, "url": s.URL, "name": s.Name}
-			if s.AuthorizationToken != "" {
-				srv["authorization_token"] = s.AuthorizationToken
-			}
-			if s.ToolConfiguration != nil {
-				srv["tool_configuration"] = s.ToolConfiguration
-			}
-			srvs = append(srvs, srv)
+		root.MCPServers = make([]claudeMCPServer, len(req.MCPServers))
+		for i, s := range req.MCPServers {
+			root.MCPServers[i] = claudeMCPServer{Type: s.Type, URL: s.URL, Name: s.Name, AuthorizationToken: s.AuthorizationToken, ToolConfiguration: s.ToolConfiguration}
 		}
-		root["mcp_servers"] = srvs
 	}
 
-	if len(req.Metadata) > 0 {
-		m := root["metadata"].(map[string]any)
-		for k, v := range req.Metadata {
-			if k != ir.MetaGoogleSearch && k != ir.MetaClaudeComputer && k != ir.MetaClaudeBash && k != ir.MetaClaudeTextEditor {
-				m[k] = v
-			}
+	for k, v := range req.Metadata {
+		if k != ir.MetaGoogleSearch && k != ir.MetaClaudeComputer && k != ir.MetaClaudeBash && k != ir.MetaClaudeTextEditor && k != ir.MetaOpenAIComputerUse && k != ir.MetaCodeExecution {
+			root.Metadata[k] = v
 		}
 	}
 
 	return json.Marshal(root)
 }
 
+// claudeServerTool builds a server tool such as web_search from its request
+// metadata. Its options are passed through, so it stays a map.
+func claudeServerTool(key, name string, v any) map[string]any {
+	t := map[string]any{"name": name}
+	defaultType := name + "_20241022"
+	if key == ir.MetaCodeExecution {
+		defaultType = "code_execution_20250522"
+	}
+	cfg, ok := v.(map[string]any)
+	if !ok {
+		t["type"] = defaultType
+		return t
+	}
+	if ot, _ := cfg["_original_type"].(string); ot != "" {
+		t["type"] = ot
+	} else {
+		t["type"] = defaultType
+	}
+	for mk, mv := range cfg {
+		// Skip internal keys and OpenAI-only web search and code interpreter options.
+		if !strings.HasPrefix(mk, "_") && mk != "search_context_size" && mk != "filters" && mk != "container" {
+			t[mk] = mv
+		}
+	}
+	return t
+}
+
+func toClaudeCacheControl(cc *ir.CacheControl) *claudeCacheControl {
+	if cc == nil {
+		return nil
+	}
+	return &claudeCacheControl{Type: cc.Type, TTL: cc.TTL}
+}
+
+// toClaudeMessage converts a non-system message to a Claude message, or
+// returns one without a role when nothing is left to send.
+func toClaudeMessage(m *ir.Message, thinkingEnabled bool) claudeMessage {
+	switch m.Role {
+	case ir.RoleUser:
+		if ps := ir.BuildClaudeContentParts(*m, false, false); len(ps) > 0 {
+			return claudeMessage{Role: ir.ClaudeRoleUser, Content: ps, CacheControl: toClaudeCacheControl(m.CacheControl)}
+		}
+	case ir.RoleAssistant:
+		if ps := ir.BuildClaudeContentParts(*m, len(m.ToolCalls) > 0, thinkingEnabled); len(ps) > 0 {
+			msg := claudeMessage{Role: ir.ClaudeRoleAssistant, Content: ps}
+			if !ir.HasThinkingParts(*m) {
+				msg.CacheControl = toClaudeCacheControl(m.CacheControl)
+			}
+			return msg
+		}
+	case ir.RoleTool:
+		var toolResults []claudeToolResult
+		for _, p := range m.Content {
+			if p.Type == ir.ContentTypeToolResult && p.ToolResult != nil {
+				toolResults = append(toolResults, toClaudeToolResult(p.ToolResult))
+			}
+		}
+		if len(toolResults) > 0 {
+			return claudeMessage{Role: ir.ClaudeRoleUser, Content: toolResults}
+		}
+	}
+	return claudeMessage{}
+}
+
+func toClaudeToolResult(r *ir.ToolResultPart) claudeToolResult {
+	tr := claudeToolResult{Type: ir.ClaudeBlockToolResult, ToolUseID: r.ToolCallID, IsError: r.IsError, Content: r.Result}
+	if len(r.Images) == 0 && len(r.Files) == 0 {
+		return tr
+	}
+	var c []claudeResultBlock
+	if r.Result != "" {
+		c = append(c, claudeResultBlock{Type: "text", Text: r.Result})
+	}
+	for _, img := range r.Images {
+		var s *claudeSource
+		if img.Data != "" {
+			s = &claudeSource{Type: "base64", MediaType: img.MimeType, Data: img.Data}
+		} else if img.URL != "" {
+			s = &claudeSource{Type: "url", URL: img.URL}
+		} else if img.FileID != "" {
+			s = &claudeSource{Type: "file", FileID: img.FileID}
+		}
+		if s != nil {
+			c = append(c, claudeResultBlock{Type: ir.ClaudeBlockImage, Source: s})
+		}
+	}
+	for _, f := range r.Files {
+		var s *claudeSource
+		if f.FileData != "" {
+			s = &claudeSource{Type: "base64", MediaType: f.MimeType, Data: f.FileData}
+		} else if f.FileURL != "" {
+			s = &claudeSource{Type: "url", URL: f.FileURL}
+		} else if f.FileID != "" {
+			s = &claudeSource{Type: "file", FileID: f.FileID}
+		}
+		if s != nil {
+			c = append(c, claudeResultBlock{Type: ir.ClaudeBlockDocument, Title: f.Filename, Source: s})
+		}
+	}
+	tr.Content = c
+	return tr
+}
+
 func (p *ClaudeProvider) ParseResponse(rj []byte) ([]ir.Message, *ir.Usage, error) {
 	root, err := ir.ParseAndValidateJSON(rj)
 	if err != nil {
@@ -293,9 +397,7 @@ func ToClaudeSSE(ev ir.UnifiedEvent, state *ClaudeStreamState) ([]byte, error) {
 				"input_tokens":                inputTokens,
 				"output_tokens":               int64(1),
 				"cache_creation_input_tokens": int64(0),
-			}
-			if cacheTokens > 0 {
-				usage["cache_read_input_tokens"] = cacheTokens
+				"cache_read_input_tokens":     cacheTokens,
 			}
 			writeSSE(buf, ir.ClaudeSSEMessageStart, map[string]any{
 				"type": ir.ClaudeSSEMessageStart,
@@ -319,17 +421,17 @@ func ToClaudeSSE(ev ir.UnifiedEvent, state *ClaudeStreamState) ([]byte, error) {
 		}
 	case ir.EventTypeToolCall:
 		if ev.ToolCall != nil {
-			emitToolCallTo(buf, ev.ToolCall, state)
+			emitToolCallTo(buf, ev.ToolCall, ev.ToolCallIndex, state)
 		}
 	case ir.EventTypeFinish:
 		if state != nil && !state.FinishSent {
 			state.FinishSent = true
-			emitFinishTo(buf, ev.Usage, state)
+			emitFinishTo(buf, &ev, state)
 		} else if state == nil {
-			emitFinishTo(buf, ev.Usage, nil)
+			emitFinishTo(buf, &ev, nil)
 		}
 	case ir.EventTypeError:
-		writeSSE(buf, ir.ClaudeSSEError, map[string]any{"type": ir.ClaudeSSEError, "error": map[string]any{"type": "api_error", "message": ev.ErrorMessage()}})
+		return nil, streamError(&ev)
 	}
 	if buf.Len() == 0 {
 		return nil, nil
@@ -338,34 +440,58 @@ func ToClaudeSSE(ev ir.UnifiedEvent, state *ClaudeStreamState) ([]byte, error) {
 }
 
 func ToClaudeResponse(ms []ir.Message, us *ir.Usage, model, mid string) ([]byte, error) {
+	return ToClaudeResponseMeta(ms, us, model, mid, nil)
+}
+
+// ToClaudeResponseMeta is ToClaudeResponse with the upstream's finish reason
+// taken from meta.
+func ToClaudeResponseMeta(ms []ir.Message, us *ir.Usage, model, mid string, meta *ir.OpenAIMeta) ([]byte, error) {
 	b := ir.NewResponseBuilder(ms, us, model, false)
 	res := map[string]any{"id": mid, "type": "message", "role": ir.ClaudeRoleAssistant, "content": b.BuildClaudeContentParts(), "model": model, "stop_reason": ir.ClaudeStopEndTurn}
-	if b.HasToolCalls() {
-		res["stop_reason"] = ir.ClaudeStopToolUse
+	var reason ir.FinishReason
+	if meta != nil {
+		reason = meta.FinishReason
+		if meta.NativeFinishReason != "" {
+			res["native_finish_reason"] = meta.NativeFinishReason
+		}
 	}
+	res["stop_reason"] = claudeStopReason(reason, b.HasToolCalls())
 	if us != nil {
-		inputTokens := us.PromptTokens
-		var cacheReadTokens int64
-		if us.CacheReadInputTokens > 0 {
-			cacheReadTokens = us.CacheReadInputTokens
-		} else if us.PromptTokensDetails != nil && us.PromptTokensDetails.CachedTokens > 0 {
-			cacheReadTokens = us.PromptTokensDetails.CachedTokens
-		}
-		if cacheReadTokens > 0 {
-			inputTokens -= cacheReadTokens
-		}
-		um := map[string]any{"input_tokens": inputTokens, "output_tokens": us.CompletionTokens}
-		if us.CacheCreationInputTokens > 0 {
-			um["cache_creation_input_tokens"] = us.CacheCreationInputTokens
-		}
-		if cacheReadTokens > 0 {
-			um["cache_read_input_tokens"] = cacheReadTokens
-		}
-		res["usage"] = um
+		res["usage"] = claudeUsage(us, us.CompletionTokens)
 	}
 	return json.Marshal(res)
 }
 
+// claudeUsage builds a Claude usage object. input_tokens excludes cache reads,
+// which Claude reports separately; both cache fields are always present since
+// cost tooling reads them unconditionally.
+func claudeUsage(us *ir.Usage, outputTokens int64) map[string]any {
+	var cacheReadTokens int64
+	if us.CacheReadInputTokens > 0 {
+		cacheReadTokens = us.CacheReadInputTokens
+	} else if us.PromptTokensDetails != nil && us.PromptTokensDetails.CachedTokens > 0 {
+		cacheReadTokens = us.PromptTokensDetails.CachedTokens
+	}
+	um := map[string]any{
+		"input_tokens":                us.PromptTokens - cacheReadTokens,
+		"output_tokens":               outputTokens,
+		"cache_creation_input_tokens": us.CacheCreationInputTokens,
+		"cache_read_input_tokens":     cacheReadTokens,
+	}
+	if stu := us.ServerToolUse; stu != nil {
+		um["server_tool_use"] = map[string]any{
+			"web_search_requests": stu.WebSearchRequests,
+			"web_fetch_requests":  stu.WebFetchRequests,
+		}
+	}
+	// Not part of Claude's usage; only set when a prediction was sent.
+	if accepted, rejected := us.PredictionTokens(); accepted > 0 || rejected > 0 {
+		um["accepted_prediction_tokens"] = accepted
+		um["rejected_prediction_tokens"] = rejected
+	}
+	return um
+}
+
 func writeSSE(buf *bytes.Buffer, et string, d any) {
 	jb, _ := json.Marshal(d)
 	buf.WriteString("event: ")
@@ -440,29 +566,62 @@ func emitRedactedThinkingDeltaTo(buf *bytes.Buffer, d string, s *ClaudeStreamSta
 	writeSSE(buf, ir.ClaudeSSEContentBlockDelta, map[string]any{"type": ir.ClaudeSSEContentBlockDelta, "index": idx, "delta": map[string]any{"type": ir.ClaudeDeltaRedactedThinking, "data": d}})
 }
 
-func emitToolCallTo(buf *bytes.Buffer, tc *ir.ToolCall, s *ClaudeStreamState) {
-	if s != nil && s.TextBlockStarted && s.CurrentBlockType == ir.ClaudeBlockThinking && len(tc.ThoughtSignature) > 0 {
+// emitToolCallTo streams a tool call as a tool_use block. With state, the block
+// stays open so later argument fragments of the same upstream tool call (no ID,
+// same index, as OpenAI-compatible providers send them) are forwarded as
+// input_json_delta events as they arrive; the block is closed when the next
+// block starts or the message finishes.
+func emitToolCallTo(buf *bytes.Buffer, tc *ir.ToolCall, toolIndex int, s *ClaudeStreamState) {
+	if s == nil {
+		buf.Write(ir.BuildClaudeToolCallBlockStartSSE(0, ir.ToClaudeToolID(tc.ID), tc.Name))
+		args := tc.Args
+		if args == "" {
+			args = "{}"
+		}
+		buf.Write(ir.BuildClaudeToolCallInputDeltaSSE(0, args))
+		buf.Write(ir.BuildClaudeContentBlockStopSSE(0))
+		return
+	}
+	if block, ok := s.ToolBlocks[toolIndex]; ok && (tc.ID == "" || tc.ID == block.ID) {
+		if tc.Args != "" {
+			buf.Write(ir.BuildClaudeToolCallInputDeltaSSE(block.Index, tc.Args))
+		}
+		return
+	}
+	if s.TextBlockStarted && s.CurrentBlockType == ir.ClaudeBlockThinking && len(tc.ThoughtSignature) > 0 {
 		writeSSE(buf, ir.ClaudeSSEContentBlockDelta, map[string]any{"type": ir.ClaudeSSEContentBlockDelta, "index": s.TextBlockIndex, "delta": map[string]any{"type": "signature_delta", "signature": string(tc.ThoughtSignature)}})
 	}
-	if s != nil && s.TextBlockStarted {
+	if s.TextBlockStarted {
 		buf.Write(ir.BuildClaudeContentBlockStopSSE(s.TextBlockIndex))
 		s.TextBlockStarted, s.TextBlockIndex, s.CurrentBlockType = false, s.TextBlockIndex+1, ""
 	}
-	idx := 0
-	if s != nil {
-		s.HasToolCalls, idx = true, s.TextBlockIndex
-		s.TextBlockIndex++
+	idx := s.TextBlockIndex
+	s.HasToolCalls = true
+	s.TextBlockStarted, s.CurrentBlockType = true, ir.ClaudeBlockToolUse
+	if s.ToolBlocks == nil {
+		s.ToolBlocks = make(map[int]ClaudeToolBlock)
 	}
+	s.ToolBlocks[toolIndex] = ClaudeToolBlock{Index: idx, ID: tc.ID}
 	buf.Write(ir.BuildClaudeToolCallBlockStartSSE(idx, ir.ToClaudeToolID(tc.ID), tc.Name))
-	args := tc.Args
-	if args == "" {
-		args = "{}"
+	if tc.Args != "" {
+		buf.Write(ir.BuildClaudeToolCallInputDeltaSSE(idx, tc.Args))
 	}
-	buf.Write(ir.BuildClaudeToolCallInputDeltaSSE(idx, args))
-	buf.Write(ir.BuildClaudeContentBlockStopSSE(idx))
 }
 
-func emitFinishTo(buf *bytes.Buffer, us *ir.Usage, s *ClaudeStreamState) {
+// claudeStopReason maps reason to a Claude stop_reason. tool_use follows the
+// tool_use blocks actually sent, since not every upstream reports tool calls
+// as the finish reason.
+func claudeStopReason(reason ir.FinishReason, hasToolCalls bool) string {
+	switch {
+	case hasToolCalls && (reason == "" || reason == ir.FinishReasonStop || reason == ir.FinishReasonUnknown):
+		return ir.ClaudeStopToolUse
+	case !hasToolCalls && reason == ir.FinishReasonToolCalls:
+		return ir.ClaudeStopEndTurn
+	}
+	return ir.MapFinishReasonToClaude(reason)
+}
+
+func emitFinishTo(buf *bytes.Buffer, ev *ir.UnifiedEvent, s *ClaudeStreamState) {
 	if s != nil && s.TextBlockStarted {
 		// Use pooled struct for content block stop
 		buf.Write(ir.BuildClaudeContentBlockStopSSE(s.TextBlockIndex))
@@ -475,29 +634,14 @@ func emitFinishTo(buf *bytes.Buffer, us *ir.Usage, s *ClaudeStreamState) {
 		// Use pooled struct for content block stop
 		buf.Write(ir.BuildClaudeContentBlockStopSSE(s.TextBlockIndex))
 	}
-	sr := ir.ClaudeStopEndTurn
-	if s != nil && s.HasToolCalls {
-		sr = ir.ClaudeStopToolUse
+	delta := map[string]any{"stop_reason": claudeStopReason(ev.FinishReason, s != nil && s.HasToolCalls)}
+	if ev.NativeFinishReason != "" {
+		delta["native_finish_reason"] = ev.NativeFinishReason
 	}
 	um := map[string]any{"output_tokens": int64(0)}
-	if us != nil {
-		inputTokens := us.PromptTokens
-		var cacheReadTokens int64
-		if us.CacheReadInputTokens > 0 {
-			cacheReadTokens = us.CacheReadInputTokens
-		} else if us.PromptTokensDetails != nil && us.PromptTokensDetails.CachedTokens > 0 {
-			cacheReadTokens = us.PromptTokensDetails.CachedTokens
-		}
-		if cacheReadTokens > 0 {
-			inputTokens -= cacheReadTokens
-			um["cache_read_input_tokens"] = cacheReadTokens
-		}
-		um["output_tokens"] = us.CompletionTokens + int64(us.ThoughtsTokenCount)
-		um["input_tokens"] = inputTokens
-		if us.CacheCreationInputTokens > 0 {
-			um["cache_creation_input_tokens"] = us.CacheCreationInputTokens
-		}
+	if us := ev.Usage; us != nil {
+		um = claudeUsage(us, us.CompletionTokens+int64(us.ThoughtsTokenCount))
 	}
-	writeSSE(buf, ir.ClaudeSSEMessageDelta, map[string]any{"type": ir.ClaudeSSEMessageDelta, "delta": map[string]any{"stop_reason": sr}, "usage": um})
+	writeSSE(buf, ir.ClaudeSSEMessageDelta, map[string]any{"type": ir.ClaudeSSEMessageDelta, "delta": delta, "usage": um})
 	writeSSE(buf, ir.ClaudeSSEMessageStop, map[string]any{"type": ir.ClaudeSSEMessageStop})
 }
@@ -1,6 +1,7 @@
 package from_ir
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/nghyane/llm-mux/internal/translator/ir"
@@ -116,3 +117,99 @@ func TestClaudeProvider_NormalMessagesKeepCacheControl(t *testing.T) {
 		t.Error("assistant message without thinking should have cache_control")
 	}
 }
+
+func TestClaudeUsage_CacheAndServerToolUse(t *testing.T) {
+	us := &ir.Usage{
+		PromptTokens:             1000,
+		CompletionTokens:         50,
+		CacheCreationInputTokens: 30,
+		CacheReadInputTokens:     800,
+		ServerToolUse:            &ir.ServerToolUse{WebSearchRequests: 2},
+	}
+
+	sse, err := ToClaudeSSE(ir.UnifiedEvent{Type: ir.EventTypeFinish, Usage: us}, NewClaudeStreamState())
+	if err != nil {
+		t.Fatalf("ToClaudeSSE failed: %v", err)
+	}
+	var delta gjson.Result
+	for _, line := range strings.Split(string(sse), "\n") {
+		if data, ok := strings.CutPrefix(line, "data: "); ok && gjson.Get(data, "type").String() == ir.ClaudeSSEMessageDelta {
+			delta = gjson.Get(data, "usage")
+		}
+	}
+	resp, err := ToClaudeResponse(nil, us, "claude-sonnet-4-5", "msg_1")
+	if err != nil {
+		t.Fatalf("ToClaudeResponse failed: %v", err)
+	}
+
+	for name, usage := range map[string]gjson.Result{"message_delta": delta, "response": gjson.GetBytes(resp, "usage")} {
+		if got := usage.Get("input_tokens").Int(); got != 200 {
+			t.Errorf("%s input_tokens = %d, want 200", name, got)
+		}
+		if got := usage.Get("cache_creation_input_tokens").Int(); got != 30 {
+			t.Errorf("%s cache_creation_input_tokens = %d, want 30", name, got)
+		}
+		if got := usage.Get("cache_read_input_tokens").Int(); got != 800 {
+			t.Errorf("%s cache_read_input_tokens = %d, want 800", name, got)
+		}
+		if got := usage.Get("server_tool_use.web_search_requests").Int(); got != 2 {
+			t.Errorf("%s server_tool_use.web_search_requests = %d, want 2", name, got)
+		}
+	}
+}
+
+func TestToClaudeSSE_StreamsOpenAIToolCallFragments(t *testing.T) {
+	state := NewClaudeStreamState()
+	events := []ir.UnifiedEvent{
+		{Type: ir.EventTypeToken, Content: "Checking."},
+		{Type: ir.EventTypeToolCall, ToolCall: &ir.ToolCall{ID: "call_a", Name: "get_weather"}, ToolCallIndex: 0},
+		{Type: ir.EventTypeToolCall, ToolCall: &ir.ToolCall{Args: `{"city":`}, ToolCallIndex: 0},
+		{Type: ir.EventTypeToolCall, ToolCall: &ir.ToolCall{Args: `"Paris"}`}, ToolCallIndex: 0},
+		{Type: ir.EventTypeToolCall, ToolCall: &ir.ToolCall{ID: "call_b", Name: "get_time", Args: `{}`}, ToolCallIndex: 1},
+		{Type: ir.EventTypeFinish},
+	}
+	var out strings.Builder
+	for _, ev := range events {
+		sse, err := ToClaudeSSE(ev, state)
+		if err != nil {
+			t.Fatalf("ToClaudeSSE failed: %v", err)
+		}
+		out.Write(sse)
+	}
+
+	var got []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		ev := gjson.Parse(data)
+		switch ev.Get("type").String() {
+		case ir.ClaudeSSEContentBlockStart:
+			got = append(got, "start "+ev.Get("index").String()+" "+ev.Get("content_block.type").String())
+		case ir.ClaudeSSEContentBlockDelta:
+			if ev.Get("delta.type").String() == "input_json_delta" {
+				got = append(got, "args "+ev.Get("index").String()+" "+ev.Get("delta.partial_json").String())
+			}
+		case ir.ClaudeSSEContentBlockStop:
+			got = append(got, "stop "+ev.Get("index").String())
+		case ir.ClaudeSSEMessageDelta:
+			got = append(got, "finish "+ev.Get("delta.stop_reason").String())
+		}
+	}
+	want := []string{
+		"start 0 text",
+		"stop 0",
+		"start 1 tool_use",
+		`args 1 {"city":`,
+		`args 1 "Paris"}`,
+		"stop 1",
+		"start 2 tool_use",
+		"args 2 {}",
+		"stop 2",
+		"finish tool_use",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("events:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
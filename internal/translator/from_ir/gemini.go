@@ -2,6 +2,7 @@ package from_ir
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/tidwall/gjson"
@@ -57,7 +58,11 @@ func (p *GeminiProvider) applyGenerationConfig(root map[string]any, req *ir.Unif
 		gc["maxOutputTokens"] = *req.MaxTokens
 	}
 	if len(req.StopSequences) > 0 {
-		gc["stopSequences"] = req.StopSequences
+		stops := req.StopSequences
+		if len(stops) > ir.GeminiMaxStopSequences {
+			stops = stops[:ir.GeminiMaxStopSequences]
+		}
+		gc["stopSequences"] = stops
 	}
 	if req.FrequencyPenalty != nil {
 		gc["frequencyPenalty"] = *req.FrequencyPenalty
@@ -65,6 +70,9 @@ func (p *GeminiProvider) applyGenerationConfig(root map[string]any, req *ir.Unif
 	if req.PresencePenalty != nil {
 		gc["presencePenalty"] = *req.PresencePenalty
 	}
+	if req.Seed != nil {
+		gc["seed"] = *req.Seed
+	}
 	if req.Logprobs != nil && *req.Logprobs {
 		gc["responseLogprobs"] = true
 		if req.TopLogprobs != nil {
@@ -86,6 +94,9 @@ func (p *GeminiProvider) applyGenerationConfig(root map[string]any, req *ir.Unif
 	if req.ResponseSchema != nil {
 		gc["responseMimeType"] = "application/json"
 		gc["responseJsonSchema"] = req.ResponseSchema
+	} else if format, _ := req.Metadata["ollama_format"].(string); format == "json" {
+		// OpenAI json_object mode: JSON output without a schema.
+		gc["responseMimeType"] = "application/json"
 	}
 
 	if req.FunctionCalling != nil {
@@ -130,6 +141,21 @@ func (p *GeminiProvider) applyMessages(root map[string]any, req *ir.UnifiedChatR
 	toolIDToName, toolResults := ir.BuildToolMaps(req.Messages)
 	coalescer := ir.GetContentCoalescer(len(req.Messages) * 2)
 
+	// Parts are built per message, possibly concurrently, and coalesced in
+	// order afterwards.
+	type messageParts struct{ user, model, response []any }
+	built := ir.MapMessages(len(req.Messages), func(i int) messageParts {
+		msg := &req.Messages[i]
+		switch msg.Role {
+		case ir.RoleUser:
+			return messageParts{user: parts.BuildUserParts(msg.Content)}
+		case ir.RoleAssistant:
+			modelParts, responseParts := p.buildAssistantAndToolParts(msg, toolIDToName, toolResults, req.Model)
+			return messageParts{model: modelParts, response: responseParts}
+		}
+		return messageParts{}
+	})
+
 	for i := range req.Messages {
 		msg := &req.Messages[i]
 		switch msg.Role {
@@ -138,11 +164,10 @@ func (p *GeminiProvider) applyMessages(root map[string]any, req *ir.UnifiedChatR
 				root["systemInstruction"] = map[string]any{"role": "user", "parts": []any{map[string]any{"text": text}}}
 			}
 		case ir.RoleUser:
-			coalescer.Emit("user", parts.BuildUserParts(msg.Content))
+			coalescer.Emit("user", built[i].user)
 		case ir.RoleAssistant:
-			modelParts, responseParts := p.buildAssistantAndToolParts(msg, toolIDToName, toolResults, req.Model)
-			coalescer.Emit("model", modelParts)
-			coalescer.Emit("user", responseParts)
+			coalescer.Emit("model", built[i].model)
+			coalescer.Emit("user", built[i].response)
 		}
 	}
 	contents := coalescer.Build()
@@ -203,6 +228,13 @@ func (p *GeminiProvider) buildAssistantAndToolParts(msg *ir.Message, toolIDToNam
 						responseParts = append(responseParts, map[string]any{"fileData": map[string]any{"fileUri": img.URL, "mimeType": img.MimeType}})
 					}
 				}
+				for _, f := range res.Files {
+					if f.FileData != "" && f.MimeType != "" {
+						responseParts = append(responseParts, map[string]any{"inlineData": map[string]any{"mimeType": f.MimeType, "data": f.FileData}})
+					} else if f.FileURL != "" {
+						responseParts = append(responseParts, map[string]any{"fileData": map[string]any{"fileUri": f.FileURL, "mimeType": f.MimeType}})
+					}
+				}
 			}
 		}
 	}
@@ -224,9 +256,15 @@ func (p *GeminiProvider) applyTools(root map[string]any, req *ir.UnifiedChatRequ
 				if m, ok := v.(map[string]any); ok {
 					cleaned := map[string]any{}
 					for mk, mv := range m {
-						if mk != "_original_type" && mk != "max_uses" && mk != "container" && mk != "vector_store" && mk != "max_num_results" && mk != "ranking_options" {
-							cleaned[mk] = mv
+						if strings.HasPrefix(mk, "_") {
+							continue
+						}
+						// Claude and OpenAI tool options have no Gemini equivalent.
+						switch mk {
+						case "max_uses", "container", "vector_store", "vector_store_ids", "max_num_results", "ranking_options", "filters", "search_context_size", "user_location", "allowed_domains", "blocked_domains":
+							continue
 						}
+						cleaned[mk] = mv
 					}
 					tn[meta] = cleaned
 				} else {
@@ -331,14 +369,34 @@ func ToGeminiResponse(messages []ir.Message, usage *ir.Usage, model string) ([]b
 	return ToGeminiResponseMeta(messages, usage, model, nil)
 }
 
+// addGeminiPredictionUsage adds the predicted output token counts, which
+// Gemini does not report itself, when a prediction was sent.
+func addGeminiPredictionUsage(um map[string]any, usage *ir.Usage) {
+	if accepted, rejected := usage.PredictionTokens(); accepted > 0 || rejected > 0 {
+		um["acceptedPredictionTokenCount"] = accepted
+		um["rejectedPredictionTokenCount"] = rejected
+	}
+}
+
 func ToGeminiResponseMeta(messages []ir.Message, usage *ir.Usage, model string, meta *ir.OpenAIMeta) ([]byte, error) {
 	builder := ir.NewResponseBuilder(messages, usage, model, false)
 	candidate := map[string]any{"content": map[string]any{"role": "model", "parts": builder.BuildGeminiContentParts()}, "finishReason": "STOP"}
-	if meta != nil && meta.GroundingMetadata != nil {
-		candidate["groundingMetadata"] = buildGroundingMetadataMap(meta.GroundingMetadata)
+	var filtered bool
+	if meta != nil {
+		if meta.FinishReason != "" {
+			candidate["finishReason"] = ir.MapFinishReasonToGemini(meta.FinishReason)
+			filtered = meta.FinishReason.IsContentFilter() || meta.FinishReason == ir.FinishReasonRefusal
+		}
+		if meta.NativeFinishReason != "" {
+			candidate["nativeFinishReason"] = meta.NativeFinishReason
+		}
+		if meta.GroundingMetadata != nil {
+			candidate["groundingMetadata"] = buildGroundingMetadataMap(meta.GroundingMetadata)
+		}
 	}
 	response := map[string]any{"candidates": []any{}, "modelVersion": model}
-	if builder.HasContent() {
+	// A blocked candidate is kept without content, as Gemini returns it.
+	if builder.HasContent() || filtered {
 		response["candidates"] = []any{candidate}
 	}
 	if usage != nil {
@@ -352,6 +410,7 @@ func ToGeminiResponseMeta(messages []ir.Message, usage *ir.Usage, model string,
 		if usage.ToolUsePromptTokens > 0 {
 			um["toolUsePromptTokenCount"] = usage.ToolUsePromptTokens
 		}
+		addGeminiPredictionUsage(um, usage)
 		response["usageMetadata"] = um
 	}
 	return json.Marshal(response)
@@ -405,7 +464,10 @@ func ToGeminiChunk(event ir.UnifiedEvent, model string) ([]byte, error) {
 			candidate["content"].(map[string]any)["parts"] = []any{p}
 		}
 	case ir.EventTypeFinish:
-		candidate["finishReason"] = "STOP"
+		candidate["finishReason"] = ir.MapFinishReasonToGemini(event.FinishReason)
+		if event.NativeFinishReason != "" {
+			candidate["nativeFinishReason"] = event.NativeFinishReason
+		}
 		if event.GroundingMetadata != nil {
 			candidate["groundingMetadata"] = buildGroundingMetadataMap(event.GroundingMetadata)
 		}
@@ -420,10 +482,11 @@ func ToGeminiChunk(event ir.UnifiedEvent, model string) ([]byte, error) {
 			if usage.ToolUsePromptTokens > 0 {
 				um["toolUsePromptTokenCount"] = usage.ToolUsePromptTokens
 			}
+			addGeminiPredictionUsage(um, usage)
 			chunk["usageMetadata"] = um
 		}
 	case ir.EventTypeError:
-		return nil, fmt.Errorf("stream error: %s", event.ErrorMessage())
+		return nil, streamError(&event)
 	}
 	chunk["candidates"] = []any{candidate}
 	jb, err := json.Marshal(chunk)
@@ -18,6 +18,7 @@ type ollamaChatChunk struct {
 	Done               bool              `json:"done"`
 	Message            ollamaChatMessage `json:"message"`
 	DoneReason         string            `json:"done_reason,omitempty"`
+	NativeFinishReason string            `json:"native_finish_reason,omitempty"`
 	PromptEvalCount    int64             `json:"prompt_eval_count,omitempty"`
 	EvalCount          int64             `json:"eval_count,omitempty"`
 	TotalDuration      int64             `json:"total_duration,omitempty"`
@@ -51,6 +52,7 @@ type ollamaGenerateChunk struct {
 	Response           string `json:"response"`
 	Thinking           string `json:"thinking,omitempty"`
 	DoneReason         string `json:"done_reason,omitempty"`
+	NativeFinishReason string `json:"native_finish_reason,omitempty"`
 	PromptEvalCount    int64  `json:"prompt_eval_count,omitempty"`
 	EvalCount          int64  `json:"eval_count,omitempty"`
 	TotalDuration      int64  `json:"total_duration,omitempty"`
@@ -76,7 +78,7 @@ func getOllamaChatChunk() *ollamaChatChunk {
 }
 
 func putOllamaChatChunk(c *ollamaChatChunk) {
-	c.Model, c.CreatedAt, c.Done, c.DoneReason = "", "", false, ""
+	c.Model, c.CreatedAt, c.Done, c.DoneReason, c.NativeFinishReason = "", "", false, "", ""
 	c.Message.Content, c.Message.Thinking, c.Message.ToolCalls = "", "", nil
 	c.PromptEvalCount, c.EvalCount = 0, 0
 	c.TotalDuration, c.LoadDuration, c.PromptEvalDuration, c.EvalDuration = 0, 0, 0, 0
@@ -88,7 +90,7 @@ func getOllamaGenerateChunk() *ollamaGenerateChunk {
 }
 
 func putOllamaGenerateChunk(c *ollamaGenerateChunk) {
-	c.Model, c.CreatedAt, c.Done, c.Response, c.Thinking, c.DoneReason = "", "", false, "", "", ""
+	c.Model, c.CreatedAt, c.Done, c.Response, c.Thinking, c.DoneReason, c.NativeFinishReason = "", "", false, "", "", "", ""
 	c.PromptEvalCount, c.EvalCount = 0, 0
 	c.TotalDuration, c.LoadDuration, c.PromptEvalDuration, c.EvalDuration = 0, 0, 0, 0
 	ollamaGenerateChunkPool.Put(c)
@@ -103,168 +105,215 @@ func ToOllamaRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 	return convertToOllamaChatRequest(req)
 }
 
+// ollamaChatRequest is the body of /api/chat.
+type ollamaChatRequest struct {
+	Model     string                 `json:"model"`
+	Messages  []ollamaRequestMessage `json:"messages"`
+	Stream    bool                   `json:"stream"`
+	Options   ollamaOptions          `json:"options"`
+	Tools     []ollamaTool           `json:"tools,omitempty"`
+	Format    any                    `json:"format,omitempty"` // "json" or a JSON schema
+	KeepAlive string                 `json:"keep_alive,omitempty"`
+}
+
+// ollamaGenerateRequest is the body of /api/generate.
+type ollamaGenerateRequest struct {
+	Model     string        `json:"model"`
+	Prompt    string        `json:"prompt"`
+	Stream    bool          `json:"stream"`
+	Options   ollamaOptions `json:"options"`
+	System    string        `json:"system,omitempty"`
+	Images    []string      `json:"images,omitempty"`
+	Format    any           `json:"format,omitempty"`
+	KeepAlive string        `json:"keep_alive,omitempty"`
+}
+
+// ollamaRequestMessage is a message of a chat request; an empty Role means
+// the message had nothing left to send.
+type ollamaRequestMessage struct {
+	Role       string           `json:"role"`
+	Content    string           `json:"content,omitempty"`
+	Thinking   string           `json:"thinking,omitempty"`
+	Images     []string         `json:"images,omitempty"`
+	ToolCalls  []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type ollamaTool struct {
+	Type     string                `json:"type"`
+	Function ollamaToolDeclaration `json:"function"`
+}
+
+type ollamaToolDeclaration struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Parameters  any    `json:"parameters"`
+}
+
+type ollamaOptions struct {
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"top_p,omitempty"`
+	TopK             *int     `json:"top_k,omitempty"`
+	NumPredict       *int     `json:"num_predict,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	Seed             *int64   `json:"seed,omitempty"`
+	NumCtx           *int64   `json:"num_ctx,omitempty"`
+}
+
+var ollamaChatRequestPool = sync.Pool{
+	New: func() any {
+		return &ollamaChatRequest{}
+	},
+}
+
+func getOllamaChatRequest() *ollamaChatRequest {
+	return ollamaChatRequestPool.Get().(*ollamaChatRequest)
+}
+
+// putOllamaChatRequest clears r but keeps its slices' capacity for the next
+// request.
+func putOllamaChatRequest(r *ollamaChatRequest) {
+	clear(r.Messages)
+	clear(r.Tools)
+	*r = ollamaChatRequest{Messages: r.Messages[:0], Tools: r.Tools[:0]}
+	ollamaChatRequestPool.Put(r)
+}
+
 func convertToOllamaChatRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
-	m := map[string]any{"model": req.Model, "messages": []any{}, "stream": req.Metadata["stream"] == true, "options": buildOllamaOptions(req)}
+	m := getOllamaChatRequest()
+	defer putOllamaChatRequest(m)
+	m.Model, m.Stream, m.Options = req.Model, req.Metadata["stream"] == true, buildOllamaOptions(req)
 	for _, msg := range req.Messages {
 		if msg.Role == ir.RoleTool {
 			for _, p := range msg.Content {
 				if p.Type == ir.ContentTypeToolResult && p.ToolResult != nil {
-					m["messages"] = append(m["messages"].([]any), map[string]any{"role": "tool", "tool_call_id": p.ToolResult.ToolCallID, "content": p.ToolResult.Result})
+					m.Messages = append(m.Messages, ollamaRequestMessage{Role: "tool", ToolCallID: p.ToolResult.ToolCallID, Content: p.ToolResult.Result})
 				}
 			}
 			continue
 		}
-		if mo := convertMessageToOllama(msg); mo != nil {
-			m["messages"] = append(m["messages"].([]any), mo)
+		if mo := convertMessageToOllama(msg); mo.Role != "" {
+			m.Messages = append(m.Messages, mo)
 		}
 	}
-	if len(req.Tools) > 0 {
-		var tools []any
-		for _, t := range req.Tools {
-			ps := t.Parameters
-			if ps == nil {
-				ps = map[string]any{"type": "object", "properties": map[string]any{}}
-			}
-			tools = append(tools, map[string]any{"type": "function", "function": map[string]any{"name": t.Name, "description": t.Description, "parameters": ps}})
+	for _, t := range req.Tools {
+		var ps any = t.Parameters
+		if t.Parameters == nil {
+			ps = map[string]any{"type": "object", "properties": map[string]any{}}
 		}
-		m["tools"] = tools
-	}
-	if req.ResponseSchema != nil {
-		m["format"] = req.ResponseSchema
-	} else if fmt, ok := req.Metadata["ollama_format"].(string); ok && fmt != "" {
-		m["format"] = fmt
-	}
-	if ka, ok := req.Metadata["ollama_keep_alive"].(string); ok && ka != "" {
-		m["keep_alive"] = ka
+		m.Tools = append(m.Tools, ollamaTool{Type: "function", Function: ollamaToolDeclaration{Name: t.Name, Description: t.Description, Parameters: ps}})
 	}
+	m.Format, m.KeepAlive = ollamaFormat(req), ollamaKeepAlive(req)
 	return json.Marshal(m)
 }
 
 func convertToOllamaGenerateRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
-	m := map[string]any{"model": req.Model, "prompt": "", "stream": req.Metadata["stream"] == true, "options": buildOllamaOptions(req)}
-	var sp, up string
-	var imgs []string
+	m := ollamaGenerateRequest{Model: req.Model, Stream: req.Metadata["stream"] == true, Options: buildOllamaOptions(req)}
 	for _, msg := range req.Messages {
 		switch msg.Role {
 		case ir.RoleSystem:
-			sp = ir.CombineTextParts(msg)
+			m.System = ir.CombineTextParts(msg)
 		case ir.RoleUser:
-			up = ir.CombineTextParts(msg)
+			m.Prompt = ir.CombineTextParts(msg)
 			for _, p := range msg.Content {
 				if p.Type == ir.ContentTypeImage && p.Image != nil {
-					imgs = append(imgs, p.Image.Data)
+					m.Images = append(m.Images, p.Image.Data)
 				}
 			}
 		}
 	}
-	if sp != "" {
-		m["system"] = sp
-	}
-	if up != "" {
-		m["prompt"] = up
-	}
-	if len(imgs) > 0 {
-		m["images"] = imgs
-	}
+	m.Format, m.KeepAlive = ollamaFormat(req), ollamaKeepAlive(req)
+	return json.Marshal(&m)
+}
+
+// ollamaFormat returns the structured output format of req, or nil.
+func ollamaFormat(req *ir.UnifiedChatRequest) any {
 	if req.ResponseSchema != nil {
-		m["format"] = req.ResponseSchema
-	} else if fmt, ok := req.Metadata["ollama_format"].(string); ok && fmt != "" {
-		m["format"] = fmt
+		return req.ResponseSchema
 	}
-	if ka, ok := req.Metadata["ollama_keep_alive"].(string); ok && ka != "" {
-		m["keep_alive"] = ka
+	if format, ok := req.Metadata["ollama_format"].(string); ok && format != "" {
+		return format
 	}
-	return json.Marshal(m)
+	return nil
 }
 
-func buildOllamaOptions(req *ir.UnifiedChatRequest) map[string]any {
-	o := make(map[string]any)
-	if req.Temperature != nil {
-		o["temperature"] = *req.Temperature
-	}
-	if req.TopP != nil {
-		o["top_p"] = *req.TopP
-	}
-	if req.TopK != nil {
-		o["top_k"] = *req.TopK
-	}
-	if req.MaxTokens != nil {
-		o["num_predict"] = *req.MaxTokens
+func ollamaKeepAlive(req *ir.UnifiedChatRequest) string {
+	ka, _ := req.Metadata["ollama_keep_alive"].(string)
+	return ka
+}
+
+func buildOllamaOptions(req *ir.UnifiedChatRequest) ollamaOptions {
+	o := ollamaOptions{
+		Temperature:      req.Temperature,
+		TopP:             req.TopP,
+		TopK:             req.TopK,
+		NumPredict:       req.MaxTokens,
+		FrequencyPenalty: req.FrequencyPenalty,
+		PresencePenalty:  req.PresencePenalty,
+		Seed:             req.Seed,
 	}
 	if len(req.StopSequences) > 0 {
-		o["stop"] = req.StopSequences
+		o.Stop = req.StopSequences
 	}
-	if req.Metadata != nil {
-		if v, ok := req.Metadata["ollama_seed"].(int64); ok {
-			o["seed"] = v
-		}
-		if v, ok := req.Metadata["ollama_num_ctx"].(int64); ok {
-			o["num_ctx"] = v
-		}
+	if v, ok := req.Metadata["ollama_num_ctx"].(int64); ok {
+		o.NumCtx = &v
 	}
 	return o
 }
 
-func convertMessageToOllama(m ir.Message) map[string]any {
+func convertMessageToOllama(m ir.Message) ollamaRequestMessage {
 	switch m.Role {
 	case ir.RoleSystem:
 		if t := ir.CombineTextParts(m); t != "" {
-			return map[string]any{"role": "system", "content": t}
+			return ollamaRequestMessage{Role: "system", Content: t}
 		}
 	case ir.RoleUser:
 		return buildOllamaUserMessage(m)
 	case ir.RoleAssistant:
 		return buildOllamaAssistantMessage(m)
 	}
-	return nil
+	return ollamaRequestMessage{}
 }
 
-func buildOllamaUserMessage(m ir.Message) map[string]any {
-	var t string
-	var imgs []string
+func buildOllamaUserMessage(m ir.Message) ollamaRequestMessage {
+	res := ollamaRequestMessage{Role: "user"}
 	for _, p := range m.Content {
 		switch p.Type {
 		case ir.ContentTypeText:
-			t += p.Text
+			res.Content += p.Text
 		case ir.ContentTypeImage:
 			if p.Image != nil {
-				imgs = append(imgs, p.Image.Data)
+				res.Images = append(res.Images, p.Image.Data)
 			}
 		}
 	}
-	res := map[string]any{"role": "user"}
-	if t != "" {
-		res["content"] = t
-	}
-	if len(imgs) > 0 {
-		res["images"] = imgs
-	}
-	if t == "" && len(imgs) == 0 {
-		return nil
+	if res.Content == "" && len(res.Images) == 0 {
+		return ollamaRequestMessage{}
 	}
 	return res
 }
 
-func buildOllamaAssistantMessage(m ir.Message) map[string]any {
-	res := map[string]any{"role": "assistant"}
-	t, r := ir.CombineTextAndReasoning(m)
-	if t != "" {
-		res["content"] = t
-	}
-	if r != "" {
-		res["thinking"] = r
-	}
+func buildOllamaAssistantMessage(m ir.Message) ollamaRequestMessage {
+	res := ollamaRequestMessage{Role: "assistant"}
+	res.Content, res.Thinking = ir.CombineTextAndReasoning(m)
 	if len(m.ToolCalls) > 0 {
-		tcs := make([]any, len(m.ToolCalls))
+		res.ToolCalls = make([]ollamaToolCall, len(m.ToolCalls))
 		for i, tc := range m.ToolCalls {
-			tcs[i] = map[string]any{"id": tc.ID, "type": "function", "function": map[string]any{"name": tc.Name, "arguments": tc.Args}}
+			res.ToolCalls[i] = ollamaToolCall{ID: tc.ID, Type: "function", Function: ollamaToolFunction{Name: tc.Name, Arguments: tc.Args}}
 		}
-		res["tool_calls"] = tcs
 	}
 	return res
 }
 
 func ToOllamaChatResponse(ms []ir.Message, us *ir.Usage, model string) ([]byte, error) {
+	return ToOllamaChatResponseMeta(ms, us, model, nil)
+}
+
+// ToOllamaChatResponseMeta is ToOllamaChatResponse with the upstream's
+// finish reason taken from meta.
+func ToOllamaChatResponseMeta(ms []ir.Message, us *ir.Usage, model string, meta *ir.OpenAIMeta) ([]byte, error) {
 	b := ir.NewResponseBuilder(ms, us, model, false)
 	res := map[string]any{"model": model, "created_at": time.Now().UTC().Format(time.RFC3339), "done": true, "message": map[string]any{"role": "assistant", "content": ""}}
 	if m := b.GetLastMessage(); m != nil {
@@ -278,10 +327,15 @@ func ToOllamaChatResponse(ms []ir.Message, us *ir.Usage, model string) ([]byte,
 		}
 		if tcs := b.BuildOpenAIToolCalls(); tcs != nil {
 			mc["tool_calls"], res["done_reason"] = tcs, "tool_calls"
+		} else if meta != nil {
+			res["done_reason"] = mapFinishReasonToOllama(meta.FinishReason)
 		} else {
 			res["done_reason"] = "stop"
 		}
 	}
+	if meta != nil && meta.NativeFinishReason != "" {
+		res["native_finish_reason"] = meta.NativeFinishReason
+	}
 	if us != nil {
 		res["prompt_eval_count"], res["eval_count"] = us.PromptTokens, us.CompletionTokens
 		res["total_duration"], res["load_duration"], res["prompt_eval_duration"], res["eval_duration"] = 0, 0, 0, 0
@@ -338,6 +392,7 @@ func ToOllamaChatChunk(ev ir.UnifiedEvent, model string) ([]byte, error) {
 	case ir.EventTypeFinish:
 		c.Done = true
 		c.DoneReason = mapFinishReasonToOllama(ev.FinishReason)
+		c.NativeFinishReason = ev.NativeFinishReason
 		if ev.Usage != nil {
 			c.PromptEvalCount = ev.Usage.PromptTokens
 			c.EvalCount = ev.Usage.CompletionTokens
@@ -370,6 +425,7 @@ func ToOllamaGenerateChunk(ev ir.UnifiedEvent, model string) ([]byte, error) {
 	case ir.EventTypeFinish:
 		c.Done = true
 		c.DoneReason = mapFinishReasonToOllama(ev.FinishReason)
+		c.NativeFinishReason = ev.NativeFinishReason
 		if ev.Usage != nil {
 			c.PromptEvalCount = ev.Usage.PromptTokens
 			c.EvalCount = ev.Usage.CompletionTokens
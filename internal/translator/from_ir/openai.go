@@ -41,6 +41,15 @@ func convertToChatCompletionsRequest(req *ir.UnifiedChatRequest) ([]byte, error)
 	if len(req.StopSequences) > 0 {
 		m["stop"] = req.StopSequences
 	}
+	if req.FrequencyPenalty != nil {
+		m["frequency_penalty"] = *req.FrequencyPenalty
+	}
+	if req.PresencePenalty != nil {
+		m["presence_penalty"] = *req.PresencePenalty
+	}
+	if req.Seed != nil {
+		m["seed"] = *req.Seed
+	}
 	if req.Prediction != nil && req.Prediction.Content != "" {
 		m["prediction"] = map[string]any{"type": req.Prediction.Type, "content": req.Prediction.Content}
 	}
@@ -52,21 +61,36 @@ func convertToChatCompletionsRequest(req *ir.UnifiedChatRequest) ([]byte, error)
 		m["reasoning_effort"] = ir.BudgetToEffort(b, "auto")
 	}
 
-	var msgs []any
-	for _, msg := range req.Messages {
+	// Images from tool results follow the run of tool messages as a user message:
+	// tool messages must directly follow the assistant turn that called them.
+	converted := ir.MapMessages(len(req.Messages), func(i int) map[string]any {
+		if msg := req.Messages[i]; msg.Role != ir.RoleTool {
+			return convertMessageToOpenAI(msg)
+		}
+		return nil
+	})
+	var msgs, toolImages []any
+	for i, msg := range req.Messages {
 		if msg.Role == ir.RoleTool {
 			for _, p := range msg.Content {
 				if p.Type == ir.ContentTypeToolResult && p.ToolResult != nil {
 					msgs = append(msgs, map[string]any{"role": "tool", "tool_call_id": p.ToolResult.ToolCallID, "content": p.ToolResult.Result})
+					if im := buildOpenAIToolImagesMessage(p.ToolResult); im != nil {
+						toolImages = append(toolImages, im)
+					}
 				}
 			}
 			continue
 		}
-		if obj := convertMessageToOpenAI(msg); obj != nil {
+		if len(msg.HostedToolCalls) > 0 && len(msg.Content) == 0 && len(msg.ToolCalls) == 0 {
+			continue // hosted tool items only exist in the Responses API
+		}
+		msgs, toolImages = append(msgs, toolImages...), nil
+		if obj := converted[i]; obj != nil {
 			msgs = append(msgs, obj)
 		}
 	}
-	m["messages"] = msgs
+	m["messages"] = append(msgs, toolImages...)
 
 	if req.ResponseSchema != nil {
 		rf := map[string]any{"type": "json_schema", "json_schema": map[string]any{"schema": req.ResponseSchema}}
@@ -88,19 +112,7 @@ func convertToChatCompletionsRequest(req *ir.UnifiedChatRequest) ([]byte, error)
 		tools = append(tools, map[string]any{"type": "function", "function": map[string]any{"name": t.Name, "description": t.Description, "parameters": ps}})
 	}
 
-	if req.Metadata != nil {
-		for k, mk := range map[string]string{ir.MetaGoogleSearch: "web_search_preview", ir.MetaCodeExecution: "code_interpreter", ir.MetaFileSearch: "file_search"} {
-			if cfg, ok := req.Metadata[k]; ok {
-				t := map[string]any{"type": mk}
-				if m, ok := cfg.(map[string]any); ok {
-					for ck, cv := range m {
-						t[ck] = cv
-					}
-				}
-				tools = append(tools, t)
-			}
-		}
-	}
+	tools = append(tools, buildOpenAIHostedTools(req.Metadata, false)...)
 
 	if len(tools) > 0 {
 		m["tools"] = tools
@@ -135,7 +147,7 @@ func convertToChatCompletionsRequest(req *ir.UnifiedChatRequest) ([]byte, error)
 	}
 
 	if req.Metadata != nil {
-		for _, k := range []string{ir.MetaOpenAILogprobs, ir.MetaOpenAITopLogprobs, ir.MetaOpenAILogitBias, ir.MetaOpenAISeed, ir.MetaOpenAIUser, ir.MetaOpenAIFrequencyPenalty, ir.MetaOpenAIPresencePenalty} {
+		for _, k := range []string{ir.MetaOpenAILogprobs, ir.MetaOpenAITopLogprobs, ir.MetaOpenAILogitBias, ir.MetaOpenAIUser, ir.MetaOpenAIFrequencyPenalty, ir.MetaOpenAIPresencePenalty} {
 			if v, ok := req.Metadata[k]; ok {
 				m[strings.TrimPrefix(k, "openai:")] = v
 			}
@@ -181,12 +193,15 @@ func convertToResponsesAPIRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 		m["modalities"] = req.ResponseModality
 	}
 
-	var input []any
-	for _, msg := range req.Messages {
-		if msg.Role == ir.RoleSystem && req.Instructions != "" {
-			continue
+	items := ir.MapMessages(len(req.Messages), func(i int) any {
+		if msg := req.Messages[i]; msg.Role != ir.RoleSystem || req.Instructions == "" {
+			return convertMessageToResponsesInput(msg)
 		}
-		if item := convertMessageToResponsesInput(msg); item != nil {
+		return nil
+	})
+	var input []any
+	for _, item := range items {
+		if item != nil {
 			input = append(input, item)
 		}
 	}
@@ -228,19 +243,7 @@ func convertToResponsesAPIRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 	for _, t := range req.Tools {
 		tools = append(tools, map[string]any{"type": "function", "name": t.Name, "description": t.Description, "parameters": t.Parameters})
 	}
-	if req.Metadata != nil {
-		for k, mk := range map[string]string{ir.MetaGoogleSearch: "web_search_preview", ir.MetaCodeExecution: "code_interpreter", ir.MetaFileSearch: "file_search"} {
-			if cfg, ok := req.Metadata[k]; ok {
-				t := map[string]any{"type": mk}
-				if m, ok := cfg.(map[string]any); ok {
-					for ck, cv := range m {
-						t[ck] = cv
-					}
-				}
-				tools = append(tools, t)
-			}
-		}
-	}
+	tools = append(tools, buildOpenAIHostedTools(req.Metadata, true)...)
 	if len(tools) > 0 {
 		m["tools"] = tools
 	}
@@ -276,7 +279,43 @@ func convertToResponsesAPIRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 	return json.Marshal(m)
 }
 
+// hostedToolTypes maps IR metadata keys to OpenAI hosted tool types, in output order.
+var hostedToolTypes = []struct{ key, openAIType string }{
+	{ir.MetaGoogleSearch, "web_search_preview"},
+	{ir.MetaCodeExecution, "code_interpreter"},
+	{ir.MetaFileSearch, "file_search"},
+	{ir.MetaOpenAIComputerUse, "computer_use_preview"},
+}
+
+// buildOpenAIHostedTools returns hosted tool entries for the built-in tools in meta.
+// The client's original tool type is restored when known; internal "_" keys are dropped.
+// Computer use only exists in the Responses API.
+func buildOpenAIHostedTools(meta map[string]any, responses bool) []any {
+	var tools []any
+	for _, ht := range hostedToolTypes {
+		cfg, ok := meta[ht.key]
+		if !ok || (ht.key == ir.MetaOpenAIComputerUse && !responses) {
+			continue
+		}
+		t := map[string]any{"type": ht.openAIType}
+		if m, ok := cfg.(map[string]any); ok {
+			for ck, cv := range m {
+				if ck == "_openai_type" {
+					t["type"] = cv
+				} else if !strings.HasPrefix(ck, "_") {
+					t[ck] = cv
+				}
+			}
+		}
+		tools = append(tools, t)
+	}
+	return tools
+}
+
 func convertMessageToResponsesInput(msg ir.Message) any {
+	if len(msg.HostedToolCalls) > 0 {
+		return json.RawMessage(msg.HostedToolCalls[0].Item)
+	}
 	switch msg.Role {
 	case ir.RoleSystem:
 		if t := ir.CombineTextParts(msg); t != "" {
@@ -295,7 +334,7 @@ func convertMessageToResponsesInput(msg ir.Message) any {
 	case ir.RoleTool:
 		for _, p := range msg.Content {
 			if p.Type == ir.ContentTypeToolResult && p.ToolResult != nil {
-				res := map[string]any{"type": "function_call_output", "call_id": p.ToolResult.ToolCallID, "output": p.ToolResult.Result}
+				res := map[string]any{"type": "function_call_output", "call_id": p.ToolResult.ToolCallID, "output": buildResponsesToolOutput(p.ToolResult)}
 				if p.ToolResult.IsError {
 					res["is_error"] = true
 				}
@@ -306,6 +345,42 @@ func convertMessageToResponsesInput(msg ir.Message) any {
 	return nil
 }
 
+// buildResponsesToolOutput returns the tool result text, or a list of input parts
+// when the result also carries images or files.
+func buildResponsesToolOutput(res *ir.ToolResultPart) any {
+	if len(res.Images) == 0 && len(res.Files) == 0 {
+		return res.Result
+	}
+	var out []any
+	if res.Result != "" {
+		out = append(out, map[string]any{"type": "input_text", "text": res.Result})
+	}
+	for _, img := range res.Images {
+		out = append(out, map[string]any{"type": "input_image", "image_url": imageURLOrDataURI(img)})
+	}
+	for _, f := range res.Files {
+		i := map[string]any{"type": "input_file"}
+		if f.FileID != "" {
+			i["file_id"] = f.FileID
+		}
+		if f.FileURL != "" {
+			i["file_url"] = f.FileURL
+		}
+		if f.Filename != "" {
+			i["filename"] = f.Filename
+		}
+		if f.FileData != "" {
+			if f.MimeType != "" && !strings.HasPrefix(f.FileData, "data:") {
+				i["file_data"] = fmt.Sprintf("data:%s;base64,%s", f.MimeType, f.FileData)
+			} else {
+				i["file_data"] = f.FileData
+			}
+		}
+		out = append(out, i)
+	}
+	return out
+}
+
 func buildResponsesUserMessage(msg ir.Message) any {
 	var c []any
 	for _, p := range msg.Content {
@@ -396,7 +471,14 @@ func ToOpenAIChatCompletionCandidates(cs []ir.CandidateResult, us *ir.Usage, mod
 		if tcs != nil {
 			mc["tool_calls"] = tcs
 		}
-		co := map[string]any{"index": c.Index, "finish_reason": ir.MapFinishReasonToOpenAI(c.FinishReason), "message": mc}
+		fr := c.FinishReason
+		if fr == ir.FinishReasonStop && tcs != nil {
+			fr = ir.FinishReasonToolCalls
+		}
+		co := map[string]any{"index": c.Index, "finish_reason": ir.MapFinishReasonToOpenAI(fr), "message": mc}
+		if c.NativeFinishReason != "" {
+			co["native_finish_reason"] = c.NativeFinishReason
+		}
 		if c.Logprobs != nil {
 			co["logprobs"] = c.Logprobs
 		}
@@ -555,11 +637,11 @@ func ToOpenAIChunkMeta(ev ir.UnifiedEvent, model, mid string, ci int, meta *ir.O
 	}
 	// HOT PATH: Simple text delta - use pooled struct for zero-allocation
 	if ev.Type == ir.EventTypeToken && ev.Content != "" && ev.Refusal == "" && ev.Logprobs == nil && ev.SystemFingerprint == "" {
-		return ir.BuildOpenAITextDeltaSSE(rid, model, cr, ev.Content), nil
+		return ir.BuildOpenAITextDeltaSSE(rid, model, cr, ev.ChoiceIndex, ev.Content), nil
 	}
 	// HOT PATH: Reasoning delta - use pooled struct
 	if ev.Type == ir.EventTypeReasoning && ev.Reasoning != "" {
-		return ir.BuildOpenAIReasoningDeltaSSE(rid, model, cr, ev.Reasoning, string(ev.ThoughtSignature)), nil
+		return ir.BuildOpenAIReasoningDeltaSSE(rid, model, cr, ev.ChoiceIndex, ev.Reasoning, string(ev.ThoughtSignature)), nil
 	}
 	// HOT PATH: Tool call delta - use pooled struct for zero-allocation
 	if ev.Type == ir.EventTypeToolCall && ev.ToolCall != nil {
@@ -567,17 +649,17 @@ func ToOpenAIChunkMeta(ev ir.UnifiedEvent, model, mid string, ci int, meta *ir.O
 		if len(ts) == 0 {
 			ts = ev.ToolCall.ThoughtSignature
 		}
-		return ir.BuildOpenAIToolCallDeltaSSE(rid, model, cr, ci, ev.ToolCall.ID, ev.ToolCall.Name, ev.ToolCall.Args, ts), nil
+		return ir.BuildOpenAIToolCallDeltaSSE(rid, model, cr, ev.ChoiceIndex, ci, ev.ToolCall.ID, ev.ToolCall.Name, ev.ToolCall.Args, ts), nil
 	}
 	// HOT PATH: Tool call args delta (streaming args) - use pooled struct
 	if ev.Type == ir.EventTypeToolCallDelta && ev.ToolCall != nil {
-		return ir.BuildOpenAIToolCallArgsDeltaSSE(rid, model, cr, ci, ev.ToolCall.Args), nil
+		return ir.BuildOpenAIToolCallArgsDeltaSSE(rid, model, cr, ev.ChoiceIndex, ci, ev.ToolCall.Args), nil
 	}
 	ch := map[string]any{"id": rid, "object": "chat.completion.chunk", "created": cr, "model": model, "choices": []any{}}
 	if ev.SystemFingerprint != "" {
 		ch["system_fingerprint"] = ev.SystemFingerprint
 	}
-	c := map[string]any{"index": 0, "delta": map[string]any{}}
+	c := map[string]any{"index": ev.ChoiceIndex, "delta": map[string]any{}}
 	switch ev.Type {
 	case ir.EventTypeToken:
 		d := map[string]any{"role": "assistant"}
@@ -628,9 +710,11 @@ func ToOpenAIChunkMeta(ev ir.UnifiedEvent, model, mid string, ci int, meta *ir.O
 			}
 			c["delta"] = map[string]any{"role": "assistant", "audio": ao}
 		}
-	case ir.EventTypeFinish:
+	case ir.EventTypeFinish, ir.EventTypeChoiceFinish:
 		c["finish_reason"] = ir.MapFinishReasonToOpenAI(ev.FinishReason)
-		if meta != nil && meta.NativeFinishReason != "" {
+		if ev.NativeFinishReason != "" {
+			c["native_finish_reason"] = ev.NativeFinishReason
+		} else if meta != nil && meta.NativeFinishReason != "" {
 			c["native_finish_reason"] = meta.NativeFinishReason
 		}
 		if ev.Logprobs != nil {
@@ -646,9 +730,9 @@ func ToOpenAIChunkMeta(ev ir.UnifiedEvent, model, mid string, ci int, meta *ir.O
 			ch["grounding_metadata"] = buildOpenAIGroundingMetadata(ev.GroundingMetadata)
 		}
 	case ir.EventTypeError:
-		return nil, fmt.Errorf("stream error: %s", ev.ErrorMessage())
+		return nil, streamError(&ev)
 	}
-	if ev.Logprobs != nil && ev.Type != ir.EventTypeFinish {
+	if ev.Logprobs != nil && ev.Type != ir.EventTypeFinish && ev.Type != ir.EventTypeChoiceFinish {
 		c["logprobs"] = ev.Logprobs
 	}
 	ch["choices"] = []any{c}
@@ -656,6 +740,15 @@ func ToOpenAIChunkMeta(ev ir.UnifiedEvent, model, mid string, ci int, meta *ir.O
 	return ir.BuildSSEChunk(jb), nil
 }
 
+// streamError ends a stream on an upstream error event. The upstream error is
+// wrapped so its status reaches the handler that reports it to the client.
+func streamError(ev *ir.UnifiedEvent) error {
+	if ev.Error != nil {
+		return fmt.Errorf("stream error: %w", ev.Error)
+	}
+	return fmt.Errorf("stream error: %s", ev.ErrorMessage())
+}
+
 func convertMessageToOpenAI(msg ir.Message) map[string]any {
 	var res map[string]any
 	switch msg.Role {
@@ -713,6 +806,26 @@ func buildOpenAIUserMessage(msg ir.Message) map[string]any {
 	return map[string]any{"role": "user", "content": ps}
 }
 
+// buildOpenAIToolImagesMessage carries images returned by a tool as a user message.
+// Chat Completions tool messages only accept text content.
+func buildOpenAIToolImagesMessage(res *ir.ToolResultPart) map[string]any {
+	if len(res.Images) == 0 {
+		return nil
+	}
+	ps := []any{map[string]any{"type": "text", "text": fmt.Sprintf("Images returned by tool call %s:", res.ToolCallID)}}
+	for _, img := range res.Images {
+		ps = append(ps, map[string]any{"type": "image_url", "image_url": map[string]string{"url": imageURLOrDataURI(img)}})
+	}
+	return map[string]any{"role": "user", "content": ps}
+}
+
+func imageURLOrDataURI(img *ir.ImagePart) string {
+	if img.URL != "" {
+		return img.URL
+	}
+	return fmt.Sprintf("data:%s;base64,%s", img.MimeType, img.Data)
+}
+
 func buildOpenAIAssistantMessage(msg ir.Message) map[string]any {
 	res := map[string]any{"role": "assistant"}
 	t, r := ir.CombineTextAndReasoning(msg)
@@ -751,6 +864,16 @@ func ToResponsesAPIResponse(ms []ir.Message, us *ir.Usage, model string, meta *i
 		}
 	}
 	res := map[string]any{"id": rid, "object": "response", "created_at": cr, "status": "completed", "model": model}
+	if meta != nil {
+		status, reason := ir.MapFinishReasonToResponses(meta.FinishReason)
+		res["status"] = status
+		if reason != "" {
+			res["incomplete_details"] = map[string]any{"reason": reason}
+		}
+		if meta.NativeFinishReason != "" {
+			res["native_finish_reason"] = meta.NativeFinishReason
+		}
+	}
 	var out []any
 	var ot string
 	b := ir.NewResponseBuilder(ms, us, model, false)
@@ -769,6 +892,14 @@ func ToResponsesAPIResponse(ms []ir.Message, us *ir.Usage, model string, meta *i
 		for _, tc := range m.ToolCalls {
 			out = append(out, map[string]any{"id": fmt.Sprintf("fc_%s", tc.ID), "type": "function_call", "status": "completed", "call_id": tc.ID, "name": tc.Name, "arguments": tc.Args})
 		}
+		for _, h := range m.HostedToolCalls {
+			out = append(out, json.RawMessage(h.Item))
+		}
+	}
+	if meta != nil && meta.GroundingMetadata != nil {
+		if ws := buildWebSearchCallItem(rid, meta.GroundingMetadata); ws != nil {
+			out = append([]any{ws}, out...)
+		}
 	}
 	if len(out) > 0 {
 		res["output"] = out
@@ -813,11 +944,21 @@ func ToResponsesAPIResponse(ms []ir.Message, us *ir.Usage, model string, meta *i
 	return json.Marshal(res)
 }
 
+// buildWebSearchCallItem reports Gemini search grounding as a Responses
+// web_search_call item, the output OpenAI clients expect from the web_search tool.
+func buildWebSearchCallItem(rid string, gm *ir.GroundingMetadata) map[string]any {
+	if len(gm.WebSearchQueries) == 0 {
+		return nil
+	}
+	return map[string]any{"id": fmt.Sprintf("ws_%s", rid), "type": "web_search_call", "status": "completed", "action": map[string]any{"type": "search", "query": gm.WebSearchQueries[0]}}
+}
+
 type ResponsesStreamState struct {
 	Seq             int
 	ResponseID      string
 	Created         int64
 	Started         bool
+	WebSearchSent   bool
 	ReasoningID     string
 	MsgID           string
 	TextBuffer      strings.Builder
@@ -848,6 +989,9 @@ func ToResponsesAPIChunk(ev ir.UnifiedEvent, model string, s *ResponsesStreamSta
 	if ev.Type == ir.EventTypeStreamMeta {
 		return nil, nil
 	}
+	if ev.Type == ir.EventTypeError {
+		return nil, streamError(&ev)
+	}
 	if s.ResponseID == "" {
 		s.ResponseID, s.Created = fmt.Sprintf("resp_%d", time.Now().UnixNano()), time.Now().Unix()
 	}
@@ -858,7 +1002,20 @@ func ToResponsesAPIChunk(ev ir.UnifiedEvent, model string, s *ResponsesStreamSta
 		out = append(out, ir.BuildResponsesResponseEventSSE("response.in_progress", ns(), s.ResponseID, s.Created, "in_progress"))
 		s.Started = true
 	}
+	if ev.GroundingMetadata != nil && !s.WebSearchSent {
+		if ws := buildWebSearchCallItem(s.ResponseID, ev.GroundingMetadata); ws != nil {
+			s.WebSearchSent = true
+			jb, _ := json.Marshal(ws)
+			out = append(out, ir.BuildResponsesOutputItemRawSSE("response.output_item.added", ns(), 0, jb))
+			out = append(out, ir.BuildResponsesOutputItemRawSSE("response.output_item.done", ns(), 0, jb))
+		}
+	}
 	switch ev.Type {
+	case ir.EventTypeHostedToolCall:
+		if h := ev.HostedToolCall; h != nil {
+			out = append(out, ir.BuildResponsesOutputItemRawSSE("response.output_item.added", ns(), ev.ToolCallIndex, []byte(h.Item)))
+			out = append(out, ir.BuildResponsesOutputItemRawSSE("response.output_item.done", ns(), ev.ToolCallIndex, []byte(h.Item)))
+		}
 	case ir.EventTypeToken:
 		if s.MsgID == "" {
 			s.MsgID = fmt.Sprintf("msg_%s", s.ResponseID)
@@ -936,7 +1093,7 @@ func ToResponsesAPIChunk(ev ir.UnifiedEvent, model string, s *ResponsesStreamSta
 				usage.OutputTokensDetails = &ir.ResponsesOutputTokensDetails{ReasoningTokens: rt}
 			}
 		}
-		out = append(out, ir.BuildResponsesDoneSSE(ns(), s.ResponseID, s.Created, usage))
+		out = append(out, ir.BuildResponsesDoneSSE(ns(), s.ResponseID, s.Created, ev.FinishReason, ev.NativeFinishReason, usage))
 	}
 	return out, nil
 }
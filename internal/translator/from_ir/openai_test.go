@@ -0,0 +1,68 @@
+package from_ir
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestResponsesAPIRequest_HostedToolsPassThrough(t *testing.T) {
+	item := `{"type":"computer_call","id":"cu_1","call_id":"call_1","action":{"type":"click","x":1,"y":2}}`
+	req := &ir.UnifiedChatRequest{
+		Model: "gpt-5",
+		Messages: []ir.Message{
+			{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Open the page"}}},
+			{Role: ir.RoleAssistant, HostedToolCalls: []ir.HostedToolCall{{ID: "cu_1", Type: "computer_call", Item: item}}},
+		},
+		Metadata: map[string]any{
+			ir.MetaGoogleSearch:      map[string]any{"_openai_type": "web_search", "search_context_size": "low"},
+			ir.MetaOpenAIComputerUse: map[string]any{"_openai_type": "computer_use_preview", "display_width": 1024, "environment": "browser"},
+		},
+	}
+
+	out, err := ToOpenAIRequestFmt(req, FormatResponsesAPI)
+	if err != nil {
+		t.Fatalf("ToOpenAIRequestFmt failed: %v", err)
+	}
+	root := gjson.ParseBytes(out)
+
+	if got := root.Get("input.1").Raw; got != item {
+		t.Errorf("hosted input item = %s, want %s", got, item)
+	}
+	tools := root.Get("tools").Array()
+	if len(tools) != 2 {
+		t.Fatalf("expected 2 tools, got %s", root.Get("tools").Raw)
+	}
+	if tools[0].Get("type").String() != "web_search" || tools[0].Get("search_context_size").String() != "low" || tools[0].Get("_openai_type").Exists() {
+		t.Errorf("web search tool = %s", tools[0].Raw)
+	}
+	if tools[1].Get("type").String() != "computer_use_preview" || tools[1].Get("display_width").Int() != 1024 {
+		t.Errorf("computer use tool = %s", tools[1].Raw)
+	}
+
+	chat, err := ToOpenAIRequest(req)
+	if err != nil {
+		t.Fatalf("ToOpenAIRequest failed: %v", err)
+	}
+	if n := len(gjson.GetBytes(chat, "messages").Array()); n != 1 {
+		t.Errorf("chat request should drop hosted items, got %d messages", n)
+	}
+	if gjson.GetBytes(chat, `tools.#(type=="computer_use_preview")`).Exists() {
+		t.Error("chat request should not carry computer use")
+	}
+}
+
+func TestResponsesAPIResponse_GroundingAsWebSearchCall(t *testing.T) {
+	msgs := []ir.Message{{Role: ir.RoleAssistant, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Sunny"}}}}
+	meta := &ir.OpenAIMeta{ResponseID: "resp_1", GroundingMetadata: &ir.GroundingMetadata{WebSearchQueries: []string{"weather hanoi"}}}
+
+	out, err := ToResponsesAPIResponse(msgs, nil, "gemini-2.5-flash", meta)
+	if err != nil {
+		t.Fatalf("ToResponsesAPIResponse failed: %v", err)
+	}
+	first := gjson.GetBytes(out, "output.0")
+	if first.Get("type").String() != "web_search_call" || first.Get("action.query").String() != "weather hanoi" {
+		t.Errorf("expected web_search_call item first, got %s", first.Raw)
+	}
+}
@@ -0,0 +1,70 @@
+package from_ir
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// benchRequest is a mid-sized agent conversation: a system prompt, tools,
+// and alternating turns with tool calls and results.
+func benchRequest() *ir.UnifiedChatRequest {
+	req := &ir.UnifiedChatRequest{
+		Model:       "bench-model",
+		MaxTokens:   ir.Ptr(4096),
+		Temperature: ir.Ptr(0.2),
+		Messages: []ir.Message{{
+			Role:    ir.RoleSystem,
+			Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "You are a coding assistant."}},
+		}},
+	}
+	for i := range 8 {
+		req.Tools = append(req.Tools, ir.ToolDefinition{
+			Name:        "tool_" + strconv.Itoa(i),
+			Description: "Runs tool number " + strconv.Itoa(i),
+			Parameters: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"path": map[string]any{"type": "string"}},
+				"required":   []any{"path"},
+			},
+		})
+	}
+	for i := range 20 {
+		id := "call_" + strconv.Itoa(i)
+		req.Messages = append(req.Messages,
+			ir.Message{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Please look at file " + strconv.Itoa(i)}}},
+			ir.Message{
+				Role:      ir.RoleAssistant,
+				Content:   []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Reading it now."}},
+				ToolCalls: []ir.ToolCall{{ID: id, Name: "tool_1", Args: `{"path":"main.go"}`}},
+			},
+			ir.Message{Role: ir.RoleTool, Content: []ir.ContentPart{{
+				Type:       ir.ContentTypeToolResult,
+				ToolResult: &ir.ToolResultPart{ToolCallID: id, Result: "package main\n\nfunc main() {}\n"},
+			}}},
+		)
+	}
+	return req
+}
+
+func BenchmarkClaudeConvertRequest(b *testing.B) {
+	req := benchRequest()
+	p := &ClaudeProvider{}
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := p.ConvertRequest(req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkToOllamaRequest(b *testing.B) {
+	req := benchRequest()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := ToOllamaRequest(req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
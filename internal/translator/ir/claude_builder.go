@@ -43,6 +43,7 @@ type ClaudeStreamParserState struct {
 	CurrentThinkingSignature string
 	BlockTypes               map[int]string
 	PendingThinkingEvent     *UnifiedEvent
+	StartUsage               *Usage // usage from message_start, merged into message_delta usage
 }
 
 // NewClaudeStreamParserState creates a new parser state with pre-allocated maps.
@@ -82,6 +83,33 @@ func (s *ClaudeStreamParserState) FlushPending() *UnifiedEvent {
 	return event
 }
 
+// MergeStartUsage fills fields missing from a message_delta usage with the
+// counts reported in message_start. Claude only guarantees output_tokens in
+// message_delta; input and cache counts may appear in message_start alone.
+func (s *ClaudeStreamParserState) MergeStartUsage(u *Usage) {
+	if s == nil || s.StartUsage == nil || u == nil {
+		return
+	}
+	start := s.StartUsage
+	if u.PromptTokens == 0 {
+		u.PromptTokens = start.PromptTokens
+	}
+	if u.CacheCreationInputTokens == 0 {
+		u.CacheCreationInputTokens = start.CacheCreationInputTokens
+	}
+	if u.CacheReadInputTokens == 0 && start.CacheReadInputTokens > 0 {
+		u.CacheReadInputTokens = start.CacheReadInputTokens
+		if u.PromptTokensDetails == nil {
+			u.PromptTokensDetails = &PromptTokensDetails{}
+		}
+		u.PromptTokensDetails.CachedTokens = start.CacheReadInputTokens
+	}
+	if u.ServerToolUse == nil {
+		u.ServerToolUse = start.ServerToolUse
+	}
+	u.TotalTokens = u.PromptTokens + u.CompletionTokens
+}
+
 func (s *ClaudeStreamParserState) Finalize() *UnifiedEvent {
 	return s.FlushPending()
 }
@@ -101,6 +129,12 @@ func ParseClaudeUsage(usage gjson.Result) *Usage {
 	if v := usage.Get("cache_read_input_tokens"); v.Exists() {
 		u.CacheReadInputTokens = v.Int()
 	}
+	if stu := usage.Get("server_tool_use"); stu.Exists() {
+		u.ServerToolUse = &ServerToolUse{
+			WebSearchRequests: stu.Get("web_search_requests").Int(),
+			WebFetchRequests:  stu.Get("web_fetch_requests").Int(),
+		}
+	}
 
 	// Map cache tokens to PromptTokensDetails for OpenAI compatibility
 	// Use cache_read_input_tokens as the primary "cached" count (tokens read from cache)
@@ -412,15 +446,16 @@ func ParseClaudeContentBlockStop(parsed gjson.Result, state *ClaudeStreamParserS
 
 // ParseClaudeMessageDelta parses Claude message_delta into IR events.
 func ParseClaudeMessageDelta(parsed gjson.Result) []*UnifiedEvent {
-	finishReason := FinishReasonUnknown
+	finishReason, native := FinishReasonUnknown, ""
 	if delta := parsed.Get("delta"); delta.Exists() {
 		if sr := delta.Get("stop_reason"); sr.Exists() {
-			finishReason = MapClaudeFinishReason(sr.String())
+			native = sr.String()
+			finishReason = MapClaudeFinishReason(native)
 		}
 	}
 	var usage *Usage
 	if u := parsed.Get("usage"); u.Exists() {
 		usage = ParseClaudeUsage(u)
 	}
-	return []*UnifiedEvent{{Type: EventTypeFinish, Usage: usage, FinishReason: finishReason}}
+	return []*UnifiedEvent{{Type: EventTypeFinish, Usage: usage, FinishReason: finishReason, NativeFinishReason: native}}
 }
@@ -77,17 +77,40 @@ func ExtractStopSequences(root gjson.Result, keys ...string) []string {
 }
 
 // ExtractFrequencyPenalty extracts frequency_penalty from gjson.Result.
-func ExtractFrequencyPenalty(root gjson.Result) *float64 {
-	if v := root.Get("frequency_penalty"); v.Exists() {
-		return Ptr(v.Float())
+func ExtractFrequencyPenalty(root gjson.Result, keys ...string) *float64 {
+	if len(keys) == 0 {
+		keys = []string{"frequency_penalty", "frequencyPenalty"}
+	}
+	for _, k := range keys {
+		if v := root.Get(k); v.Exists() && v.Type == gjson.Number {
+			return Ptr(v.Float())
+		}
 	}
 	return nil
 }
 
 // ExtractPresencePenalty extracts presence_penalty from gjson.Result.
-func ExtractPresencePenalty(root gjson.Result) *float64 {
-	if v := root.Get("presence_penalty"); v.Exists() {
-		return Ptr(v.Float())
+func ExtractPresencePenalty(root gjson.Result, keys ...string) *float64 {
+	if len(keys) == 0 {
+		keys = []string{"presence_penalty", "presencePenalty"}
+	}
+	for _, k := range keys {
+		if v := root.Get(k); v.Exists() && v.Type == gjson.Number {
+			return Ptr(v.Float())
+		}
+	}
+	return nil
+}
+
+// ExtractSeed extracts the sampling seed from gjson.Result.
+func ExtractSeed(root gjson.Result, keys ...string) *int64 {
+	if len(keys) == 0 {
+		keys = []string{"seed"}
+	}
+	for _, k := range keys {
+		if v := root.Get(k); v.Exists() && v.Type == gjson.Number {
+			return Ptr(v.Int())
+		}
 	}
 	return nil
 }
@@ -126,10 +149,32 @@ func ApplyCommonParams(req *UnifiedChatRequest, root gjson.Result) {
 	req.StopSequences = ExtractStopSequences(root)
 }
 
+// ApplyLocalSamplingParams stores the min_p and repetition penalty sampling
+// parameters of local inference servers in Metadata. repetitionKeys defaults
+// to "repetition_penalty" (vLLM) and "repeat_penalty" (llama.cpp, Ollama).
+func ApplyLocalSamplingParams(req *UnifiedChatRequest, root gjson.Result, repetitionKeys ...string) {
+	if len(repetitionKeys) == 0 {
+		repetitionKeys = []string{"repetition_penalty", "repeat_penalty"}
+	}
+	if req.Metadata == nil {
+		req.Metadata = make(map[string]any)
+	}
+	if v := root.Get("min_p"); v.Exists() {
+		req.Metadata[MetaMinP] = v.Float()
+	}
+	for _, k := range repetitionKeys {
+		if v := root.Get(k); v.Exists() {
+			req.Metadata[MetaRepetitionPenalty] = v.Float()
+			break
+		}
+	}
+}
+
 // ApplyOpenAIExtendedParams applies OpenAI-specific extended parameters.
 func ApplyOpenAIExtendedParams(req *UnifiedChatRequest, root gjson.Result) {
 	req.FrequencyPenalty = ExtractFrequencyPenalty(root)
 	req.PresencePenalty = ExtractPresencePenalty(root)
+	req.Seed = ExtractSeed(root)
 	req.Logprobs = ExtractLogprobs(root)
 	req.TopLogprobs = ExtractTopLogprobs(root)
 	req.CandidateCount = ExtractCandidateCount(root)
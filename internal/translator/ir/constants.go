@@ -121,3 +121,9 @@ const (
 	ResponseModalityImage = "IMAGE"
 	ResponseModalityAudio = "AUDIO"
 )
+
+// Stop Sequence Limits
+// Maximum stop sequences accepted natively; extra ones are emulated on the stream
+const (
+	GeminiMaxStopSequences = 5
+)
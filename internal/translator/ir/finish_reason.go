@@ -0,0 +1,192 @@
+package ir
+
+import "strings"
+
+// Finish reason mapping between providers and the IR.
+//
+//	IR                  OpenAI          Claude          Gemini               Responses
+//	stop                stop            end_turn        STOP                 completed
+//	stop_sequence       stop            stop_sequence   STOP                 completed
+//	max_tokens          length          max_tokens      MAX_TOKENS           incomplete: max_output_tokens
+//	tool_calls          tool_calls      tool_use        STOP                 completed
+//	content_filter      content_filter  refusal         SAFETY               incomplete: content_filter
+//	recitation          content_filter  refusal         RECITATION           incomplete: content_filter
+//	blocklist           content_filter  refusal         BLOCKLIST            incomplete: content_filter
+//	prohibited_content  content_filter  refusal         PROHIBITED_CONTENT   incomplete: content_filter
+//	spii                content_filter  refusal         SPII                 incomplete: content_filter
+//	image_safety        content_filter  refusal         IMAGE_SAFETY         incomplete: content_filter
+//	refusal             stop            refusal         SAFETY               completed
+//
+// A client format has fewer reasons than the IR, so the upstream's own value
+// is also passed through as native_finish_reason (see NativeFinishReason on
+// UnifiedEvent, CandidateResult and OpenAIMeta).
+
+func MapGeminiFinishReason(geminiReason string) FinishReason {
+	switch strings.ToUpper(geminiReason) {
+	case "STOP", "FINISH_REASON_UNSPECIFIED", "UNKNOWN":
+		return FinishReasonStop
+	case "MAX_TOKENS", "LENGTH":
+		return FinishReasonMaxTokens
+	case "TOOL_CALLS", "FUNCTION_CALL":
+		return FinishReasonToolCalls
+	case "SAFETY", "LANGUAGE", "IMAGE_OTHER":
+		return FinishReasonContentFilter
+	case "RECITATION", "IMAGE_RECITATION":
+		return FinishReasonRecitation
+	case "BLOCKLIST":
+		return FinishReasonBlocklist
+	case "PROHIBITED_CONTENT", "IMAGE_PROHIBITED_CONTENT":
+		return FinishReasonProhibitedContent
+	case "SPII":
+		return FinishReasonSPII
+	case "IMAGE_SAFETY":
+		return FinishReasonImageSafety
+	case "OTHER":
+		return FinishReasonContentFilter // Map OTHER to content_filter
+	case "MALFORMED_FUNCTION_CALL":
+		return FinishReasonToolCalls // Still try to parse the tool call
+	case "UNEXPECTED_TOOL_CALL", "TOO_MANY_TOOL_CALLS":
+		return FinishReasonError
+	default:
+		return FinishReasonUnknown
+	}
+}
+
+func MapClaudeFinishReason(claudeReason string) FinishReason {
+	switch claudeReason {
+	case "end_turn", "pause_turn":
+		return FinishReasonStop // Claude "end_turn" = normal completion = IR "stop"
+	case "stop_sequence":
+		return FinishReasonStopSequence
+	case "max_tokens", "model_context_window_exceeded":
+		return FinishReasonMaxTokens
+	case "tool_use":
+		return FinishReasonToolCalls
+	case "refusal":
+		return FinishReasonRefusal
+	default:
+		return FinishReasonUnknown
+	}
+}
+
+func MapOpenAIFinishReason(openaiReason string) FinishReason {
+	switch openaiReason {
+	case "stop":
+		return FinishReasonStop
+	case "length":
+		return FinishReasonMaxTokens // OpenAI "length" = IR "max_tokens"
+	case "tool_calls", "function_call":
+		return FinishReasonToolCalls
+	case "content_filter":
+		return FinishReasonContentFilter
+	default:
+		return FinishReasonUnknown
+	}
+}
+
+// MapOpenAIRefusal returns FinishReasonRefusal for a normal stop whose
+// message was a refusal, which OpenAI reports as "stop".
+func MapOpenAIRefusal(reason FinishReason, refused bool) FinishReason {
+	if refused && reason == FinishReasonStop {
+		return FinishReasonRefusal
+	}
+	return reason
+}
+
+// MapResponsesFinishReason maps a Responses API status and
+// incomplete_details.reason to the IR.
+func MapResponsesFinishReason(status, incompleteReason string) FinishReason {
+	if status != "incomplete" {
+		return FinishReasonStop
+	}
+	switch incompleteReason {
+	case "max_output_tokens":
+		return FinishReasonMaxTokens
+	case "content_filter":
+		return FinishReasonContentFilter
+	default:
+		return FinishReasonUnknown
+	}
+}
+
+// IsContentFilter reports whether reason means the output was blocked by a
+// safety or policy filter.
+func (reason FinishReason) IsContentFilter() bool {
+	switch reason {
+	case FinishReasonContentFilter, FinishReasonBlocklist,
+		FinishReasonProhibitedContent, FinishReasonSPII,
+		FinishReasonImageSafety, FinishReasonRecitation:
+		return true
+	}
+	return false
+}
+
+func MapFinishReasonToOpenAI(reason FinishReason) string {
+	switch {
+	case reason == FinishReasonStop, reason == FinishReasonStopSequence, reason == FinishReasonRefusal:
+		return "stop"
+	case reason == FinishReasonMaxTokens:
+		return "length"
+	case reason == FinishReasonToolCalls:
+		return "tool_calls"
+	case reason.IsContentFilter():
+		return "content_filter"
+	case reason == FinishReasonError:
+		return "error"
+	default:
+		return "stop"
+	}
+}
+
+func MapFinishReasonToClaude(reason FinishReason) string {
+	switch {
+	case reason == FinishReasonStop:
+		return "end_turn"
+	case reason == FinishReasonMaxTokens:
+		return "max_tokens"
+	case reason == FinishReasonToolCalls:
+		return "tool_use"
+	case reason == FinishReasonStopSequence:
+		return "stop_sequence"
+	case reason == FinishReasonRefusal, reason.IsContentFilter():
+		return "refusal"
+	default:
+		return "end_turn"
+	}
+}
+
+func MapFinishReasonToGemini(reason FinishReason) string {
+	switch reason {
+	case FinishReasonMaxTokens:
+		return "MAX_TOKENS"
+	case FinishReasonContentFilter, FinishReasonRefusal:
+		return "SAFETY"
+	case FinishReasonRecitation:
+		return "RECITATION"
+	case FinishReasonBlocklist:
+		return "BLOCKLIST"
+	case FinishReasonProhibitedContent:
+		return "PROHIBITED_CONTENT"
+	case FinishReasonSPII:
+		return "SPII"
+	case FinishReasonImageSafety:
+		return "IMAGE_SAFETY"
+	case FinishReasonError:
+		return "OTHER"
+	default:
+		return "STOP"
+	}
+}
+
+// MapFinishReasonToResponses returns the Responses API status for reason and,
+// when incomplete, the incomplete_details.reason.
+func MapFinishReasonToResponses(reason FinishReason) (status, incompleteReason string) {
+	switch {
+	case reason == FinishReasonMaxTokens:
+		return "incomplete", "max_output_tokens"
+	case reason.IsContentFilter():
+		return "incomplete", "content_filter"
+	default:
+		return "completed", ""
+	}
+}
@@ -0,0 +1,58 @@
+package ir
+
+import "testing"
+
+func TestFinishReasonRoundTrip_Gemini(t *testing.T) {
+	tests := []string{"STOP", "MAX_TOKENS", "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY"}
+
+	for _, geminiReason := range tests {
+		t.Run(geminiReason, func(t *testing.T) {
+			ir := MapGeminiFinishReason(geminiReason)
+			if back := MapFinishReasonToGemini(ir); back != geminiReason {
+				t.Errorf("Round-trip failed: %q -> %q -> %q", geminiReason, ir, back)
+			}
+		})
+	}
+}
+
+func TestFinishReasonAcrossProviders(t *testing.T) {
+	// Each provider's terminal reasons as seen by the other client formats.
+	tests := []struct {
+		name      string
+		reason    FinishReason
+		openai    string
+		claude    string
+		gemini    string
+		responses string
+	}{
+		{"gemini SAFETY", MapGeminiFinishReason("SAFETY"), "content_filter", "refusal", "SAFETY", "content_filter"},
+		{"gemini RECITATION", MapGeminiFinishReason("RECITATION"), "content_filter", "refusal", "RECITATION", "content_filter"},
+		{"gemini MAX_TOKENS", MapGeminiFinishReason("MAX_TOKENS"), "length", "max_tokens", "MAX_TOKENS", "max_output_tokens"},
+		{"claude refusal", MapClaudeFinishReason("refusal"), "stop", "refusal", "SAFETY", ""},
+		{"claude tool_use", MapClaudeFinishReason("tool_use"), "tool_calls", "tool_use", "STOP", ""},
+		{"claude stop_sequence", MapClaudeFinishReason("stop_sequence"), "stop", "stop_sequence", "STOP", ""},
+		{"openai refusal", MapOpenAIRefusal(MapOpenAIFinishReason("stop"), true), "stop", "refusal", "SAFETY", ""},
+		{"openai content_filter", MapOpenAIFinishReason("content_filter"), "content_filter", "refusal", "SAFETY", "content_filter"},
+		{"openai length", MapOpenAIFinishReason("length"), "length", "max_tokens", "MAX_TOKENS", "max_output_tokens"},
+		{"responses incomplete", MapResponsesFinishReason("incomplete", "max_output_tokens"), "length", "max_tokens", "MAX_TOKENS", "max_output_tokens"},
+		{"responses completed", MapResponsesFinishReason("completed", ""), "stop", "end_turn", "STOP", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MapFinishReasonToOpenAI(tt.reason); got != tt.openai {
+				t.Errorf("OpenAI = %q, want %q", got, tt.openai)
+			}
+			if got := MapFinishReasonToClaude(tt.reason); got != tt.claude {
+				t.Errorf("Claude = %q, want %q", got, tt.claude)
+			}
+			if got := MapFinishReasonToGemini(tt.reason); got != tt.gemini {
+				t.Errorf("Gemini = %q, want %q", got, tt.gemini)
+			}
+			status, got := MapFinishReasonToResponses(tt.reason)
+			if got != tt.responses || (got == "") != (status == "completed") {
+				t.Errorf("Responses = %q %q, want incomplete reason %q", status, got, tt.responses)
+			}
+		})
+	}
+}
@@ -366,3 +366,36 @@ func GetFirstReasoningSignature(msg Message) string {
 	}
 	return ""
 }
+
+// CodeExecutionText renders Gemini executable code or its result as Markdown,
+// for clients whose format has no code execution block.
+func CodeExecutionText(ce *CodeExecutionPart) string {
+	if ce == nil {
+		return ""
+	}
+	if ce.Code != "" {
+		lang := ""
+		if ce.Language != "" && ce.Language != LanguageUnspecified {
+			lang = strings.ToLower(string(ce.Language))
+		}
+		return "\n```" + lang + "\n" + strings.TrimRight(ce.Code, "\n") + "\n```\n"
+	}
+	label := "Output:"
+	if ce.Outcome != "" && ce.Outcome != OutcomeOK && ce.Outcome != OutcomeUnspecified {
+		label = "Execution failed (" + strings.ToLower(strings.TrimPrefix(string(ce.Outcome), "OUTCOME_")) + "):"
+	}
+	return "\n" + label + "\n```\n" + strings.TrimRight(ce.Output, "\n") + "\n```\n"
+}
+
+// InlineCodeExecution replaces executable code and code result parts in msgs
+// with text parts holding their CodeExecutionText.
+func InlineCodeExecution(msgs []Message) {
+	for i := range msgs {
+		for j := range msgs[i].Content {
+			part := &msgs[i].Content[j]
+			if part.Type == ContentTypeExecutableCode || part.Type == ContentTypeCodeResult {
+				*part = ContentPart{Type: ContentTypeText, Text: CodeExecutionText(part.CodeExecution)}
+			}
+		}
+	}
+}
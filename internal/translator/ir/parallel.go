@@ -0,0 +1,56 @@
+package ir
+
+import (
+	"runtime"
+	"sync"
+)
+
+// ParallelMessagesThreshold is the number of messages from which message
+// conversion is spread over several goroutines. Below it the goroutine and
+// scheduling cost outweighs the gain. Zero or less disables parallel
+// conversion.
+var ParallelMessagesThreshold = 128
+
+// MapMessages returns fn(0), ..., fn(n-1) in order. Once n reaches
+// ParallelMessagesThreshold the calls are split into contiguous chunks run
+// concurrently, so fn must only depend on its own message and on state
+// that is not modified during the call.
+func MapMessages[T any](n int, fn func(i int) T) []T {
+	out := make([]T, n)
+	workers := min(runtime.GOMAXPROCS(0), n)
+	if threshold := ParallelMessagesThreshold; threshold <= 0 || n < threshold || workers < 2 {
+		for i := range out {
+			out[i] = fn(i)
+		}
+		return out
+	}
+
+	chunk := (n + workers - 1) / workers
+	var (
+		wg        sync.WaitGroup
+		panicOnce sync.Once
+		panicked  any
+	)
+	for start := 0; start < n; start += chunk {
+		end := min(start+chunk, n)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Re-raised below so the caller's recovery handles it as it
+			// would in sequential conversion.
+			defer func() {
+				if r := recover(); r != nil {
+					panicOnce.Do(func() { panicked = r })
+				}
+			}()
+			for i := start; i < end; i++ {
+				out[i] = fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+	if panicked != nil {
+		panic(panicked)
+	}
+	return out
+}
@@ -0,0 +1,38 @@
+package ir
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestMapMessages(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	defer func(saved int) { ParallelMessagesThreshold = saved }(ParallelMessagesThreshold)
+
+	for _, threshold := range []int{0, 1, 1000} {
+		ParallelMessagesThreshold = threshold
+		got := MapMessages(103, func(i int) int { return i * i })
+		if len(got) != 103 {
+			t.Fatalf("threshold %d: len = %d", threshold, len(got))
+		}
+		for i, v := range got {
+			if v != i*i {
+				t.Fatalf("threshold %d: got[%d] = %d", threshold, i, v)
+			}
+		}
+	}
+
+	ParallelMessagesThreshold = 1
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("recovered %v, want boom", r)
+		}
+	}()
+	MapMessages(10, func(i int) int {
+		if i == 7 {
+			panic("boom")
+		}
+		return i
+	})
+	t.Error("MapMessages did not panic")
+}
@@ -0,0 +1,58 @@
+package ir
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// Gemini harm categories and block thresholds accepted in safety settings.
+var (
+	safetyCategories = map[string]bool{
+		"HARM_CATEGORY_HARASSMENT":              true,
+		"HARM_CATEGORY_HATE_SPEECH":             true,
+		"HARM_CATEGORY_SEXUALLY_EXPLICIT":       true,
+		"HARM_CATEGORY_DANGEROUS_CONTENT":       true,
+		"HARM_CATEGORY_CIVIC_INTEGRITY":         true,
+		"HARM_CATEGORY_IMAGE_HATE":              true,
+		"HARM_CATEGORY_IMAGE_DANGEROUS_CONTENT": true,
+		"HARM_CATEGORY_IMAGE_HARASSMENT":        true,
+		"HARM_CATEGORY_IMAGE_SEXUALLY_EXPLICIT": true,
+		"HARM_CATEGORY_JAILBREAK":               true,
+	}
+	safetyThresholds = map[string]bool{
+		"BLOCK_LOW_AND_ABOVE":    true,
+		"BLOCK_MEDIUM_AND_ABOVE": true,
+		"BLOCK_ONLY_HIGH":        true,
+		"BLOCK_NONE":             true,
+		"OFF":                    true,
+	}
+)
+
+// ParseSafetySettings parses a Gemini safetySettings array. Enum values are
+// matched case-insensitively; unknown categories or thresholds are rejected
+// with an InvalidRequestError.
+func ParseSafetySettings(v gjson.Result) ([]SafetySetting, error) {
+	if !v.Exists() || v.Type == gjson.Null {
+		return nil, nil
+	}
+	if !v.IsArray() {
+		return nil, &InvalidRequestError{Message: "safety settings must be an array"}
+	}
+	var out []SafetySetting
+	for i, s := range v.Array() {
+		setting := SafetySetting{
+			Category:  strings.ToUpper(strings.TrimSpace(s.Get("category").String())),
+			Threshold: strings.ToUpper(strings.TrimSpace(s.Get("threshold").String())),
+		}
+		if !safetyCategories[setting.Category] {
+			return nil, &InvalidRequestError{Message: fmt.Sprintf("safety settings[%d]: unknown category %q", i, setting.Category)}
+		}
+		if !safetyThresholds[setting.Threshold] {
+			return nil, &InvalidRequestError{Message: fmt.Sprintf("safety settings[%d]: unknown threshold %q", i, setting.Threshold)}
+		}
+		out = append(out, setting)
+	}
+	return out, nil
+}
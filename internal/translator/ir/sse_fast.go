@@ -65,12 +65,12 @@ func PutOpenAITextDelta(d *OpenAITextDelta) {
 // BuildOpenAITextDeltaSSE builds an SSE chunk for a simple text delta.
 // This is the HOT PATH - called for every token in streaming.
 // Uses template fast path for simple ASCII content (~5x faster).
-func BuildOpenAITextDeltaSSE(id, model string, created int64, content string) []byte {
+func BuildOpenAITextDeltaSSE(id, model string, created int64, choice int, content string) []byte {
 	// Fast path: check if content needs JSON escaping
 	if isSimpleASCII(content) {
 		// Simple ASCII - use pre-escaped content (just wrap in quotes)
 		contentJSON := escapeSimpleASCII(content)
-		return BuildOpenAITextDeltaSSETemplate(id, model, created, contentJSON)
+		return BuildOpenAITextDeltaSSETemplate(id, model, created, choice, contentJSON)
 	}
 
 	// Fallback: content needs escaping, use json.Marshal
@@ -80,6 +80,7 @@ func BuildOpenAITextDeltaSSE(id, model string, created int64, content string) []
 	delta.ID = id
 	delta.Model = model
 	delta.Created = created
+	delta.Choices[0].Index = choice
 	delta.Choices[0].Delta.Role = "assistant"
 	delta.Choices[0].Delta.Content = content
 
@@ -146,13 +147,14 @@ func PutOpenAIReasoningDelta(d *OpenAIReasoningDelta) {
 	openaiReasoningDeltaPool.Put(d)
 }
 
-func BuildOpenAIReasoningDeltaSSE(id, model string, created int64, reasoning, signature string) []byte {
+func BuildOpenAIReasoningDeltaSSE(id, model string, created int64, choice int, reasoning, signature string) []byte {
 	delta := GetOpenAIReasoningDelta()
 	defer PutOpenAIReasoningDelta(delta)
 
 	delta.ID = id
 	delta.Model = model
 	delta.Created = created
+	delta.Choices[0].Index = choice
 	delta.Choices[0].Delta.Role = "assistant"
 	delta.Choices[0].Delta.Reasoning.Content = reasoning
 	delta.Choices[0].Delta.Reasoning.Signature = signature
@@ -397,7 +399,7 @@ func BuildClaudeContentBlockStopSSE(index int) []byte {
 // BuildOpenAITextDeltaSSETemplate builds an SSE chunk using string concatenation
 // instead of JSON marshaling. ~5x faster for simple text deltas.
 // Note: content must be pre-escaped JSON string.
-func BuildOpenAITextDeltaSSETemplate(id, model string, created int64, contentJSON []byte) []byte {
+func BuildOpenAITextDeltaSSETemplate(id, model string, created int64, choice int, contentJSON []byte) []byte {
 	buf := GetBuffer()
 	defer PutBuffer(buf)
 
@@ -410,7 +412,9 @@ func BuildOpenAITextDeltaSSETemplate(id, model string, created int64, contentJSO
 	buf.WriteString(strconv.FormatInt(created, 10))
 	buf.WriteString(`,"model":"`)
 	buf.WriteString(model)
-	buf.WriteString(`","choices":[{"index":0,"delta":{"role":"assistant","content":`)
+	buf.WriteString(`","choices":[{"index":`)
+	buf.WriteString(strconv.Itoa(choice))
+	buf.WriteString(`,"delta":{"role":"assistant","content":`)
 	buf.Write(contentJSON)
 	buf.WriteString(`}}]}`)
 	buf.WriteString("\n\n")
@@ -565,13 +569,14 @@ func PutOpenAIToolCallDelta(d *OpenAIToolCallDelta) {
 
 // BuildOpenAIToolCallDeltaSSE builds an SSE chunk for a tool call delta.
 // This is a HOT PATH for agentic workflows - called for every tool call in streaming.
-func BuildOpenAIToolCallDeltaSSE(id, model string, created int64, toolCallIndex int, toolCallID, funcName, funcArgs string, thoughtSig []byte) []byte {
+func BuildOpenAIToolCallDeltaSSE(id, model string, created int64, choice, toolCallIndex int, toolCallID, funcName, funcArgs string, thoughtSig []byte) []byte {
 	delta := GetOpenAIToolCallDelta()
 	defer PutOpenAIToolCallDelta(delta)
 
 	delta.ID = id
 	delta.Model = model
 	delta.Created = created
+	delta.Choices[0].Index = choice
 	delta.Choices[0].Delta.Role = "assistant"
 
 	// Build tool call entry
@@ -602,13 +607,14 @@ func BuildOpenAIToolCallDeltaSSE(id, model string, created int64, toolCallIndex
 
 // BuildOpenAIToolCallArgsDeltaSSE builds an SSE chunk for tool call arguments delta (streaming args).
 // Used when only arguments are being streamed (no ID/name).
-func BuildOpenAIToolCallArgsDeltaSSE(id, model string, created int64, toolCallIndex int, funcArgs string) []byte {
+func BuildOpenAIToolCallArgsDeltaSSE(id, model string, created int64, choice, toolCallIndex int, funcArgs string) []byte {
 	delta := GetOpenAIToolCallDelta()
 	defer PutOpenAIToolCallDelta(delta)
 
 	delta.ID = id
 	delta.Model = model
 	delta.Created = created
+	delta.Choices[0].Index = choice
 
 	entry := OpenAIToolCallEntry{
 		Index: toolCallIndex,
@@ -887,6 +893,13 @@ func BuildResponsesOutputItemAddedFunctionCallSSE(seqNum, outputIndex int, itemI
 	return formatResponsesSSEBytes("response.output_item.added", jb)
 }
 
+// BuildResponsesOutputItemRawSSE builds SSE for response.output_item.added/done with
+// an already encoded item, such as a hosted tool call passed through from upstream.
+func BuildResponsesOutputItemRawSSE(eventType string, seqNum, outputIndex int, item []byte) []byte {
+	jb, _ := json.Marshal(map[string]any{"type": eventType, "sequence_number": seqNum, "output_index": outputIndex, "item": json.RawMessage(item)})
+	return formatResponsesSSEBytes(eventType, jb)
+}
+
 // ResponsesContentPartAddedEvent is used for response.content_part.added event.
 type ResponsesContentPartAddedEvent struct {
 	Type           string                 `json:"type"`
@@ -1155,11 +1168,17 @@ type ResponsesDoneEvent struct {
 }
 
 type ResponsesDoneEventInner struct {
-	ID        string              `json:"id"`
-	Object    string              `json:"object"`
-	CreatedAt int64               `json:"created_at"`
-	Status    string              `json:"status"`
-	Usage     *ResponsesDoneUsage `json:"usage,omitempty"`
+	ID                 string                      `json:"id"`
+	Object             string                      `json:"object"`
+	CreatedAt          int64                       `json:"created_at"`
+	Status             string                      `json:"status"`
+	IncompleteDetails  *ResponsesIncompleteDetails `json:"incomplete_details,omitempty"`
+	NativeFinishReason string                      `json:"native_finish_reason,omitempty"`
+	Usage              *ResponsesDoneUsage         `json:"usage,omitempty"`
+}
+
+type ResponsesIncompleteDetails struct {
+	Reason string `json:"reason"`
 }
 
 type ResponsesDoneUsage struct {
@@ -1198,18 +1217,28 @@ func PutResponsesDoneEvent(d *ResponsesDoneEvent) {
 	d.SequenceNumber = 0
 	d.Response.ID = ""
 	d.Response.CreatedAt = 0
+	d.Response.Status = "completed"
+	d.Response.IncompleteDetails = nil
+	d.Response.NativeFinishReason = ""
 	d.Response.Usage = nil
 	responsesDoneEventPool.Put(d)
 }
 
-// BuildResponsesDoneSSE builds SSE for response.done.
-func BuildResponsesDoneSSE(seqNum int, respID string, createdAt int64, usage *ResponsesDoneUsage) []byte {
+// BuildResponsesDoneSSE builds SSE for response.done. A token limit or
+// content filter marks the response incomplete.
+func BuildResponsesDoneSSE(seqNum int, respID string, createdAt int64, reason FinishReason, nativeReason string, usage *ResponsesDoneUsage) []byte {
 	d := GetResponsesDoneEvent()
 	defer PutResponsesDoneEvent(d)
 
 	d.SequenceNumber = seqNum
 	d.Response.ID = respID
 	d.Response.CreatedAt = createdAt
+	status, incomplete := MapFinishReasonToResponses(reason)
+	d.Response.Status = status
+	if incomplete != "" {
+		d.Response.IncompleteDetails = &ResponsesIncompleteDetails{Reason: incomplete}
+	}
+	d.Response.NativeFinishReason = nativeReason
 	d.Response.Usage = usage
 
 	jb, _ := json.Marshal(d)
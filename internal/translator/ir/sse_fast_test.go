@@ -11,7 +11,7 @@ import (
 // -----------------------------------------------------------------------------
 
 func TestBuildOpenAITextDeltaSSE(t *testing.T) {
-	result := BuildOpenAITextDeltaSSE("chatcmpl-123", "gpt-4", 1234567890, "Hello")
+	result := BuildOpenAITextDeltaSSE("chatcmpl-123", "gpt-4", 1234567890, 0, "Hello")
 
 	expected := `data: {"id":"chatcmpl-123","object":"chat.completion.chunk","created":1234567890,"model":"gpt-4","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}`
 
@@ -36,7 +36,7 @@ func TestBuildOpenAITextDeltaSSE(t *testing.T) {
 }
 
 func TestBuildOpenAIReasoningDeltaSSE(t *testing.T) {
-	result := BuildOpenAIReasoningDeltaSSE("chatcmpl-123", "gpt-4", 1234567890, "thinking...", "sig123")
+	result := BuildOpenAIReasoningDeltaSSE("chatcmpl-123", "gpt-4", 1234567890, 0, "thinking...", "sig123")
 
 	if len(result) < 10 {
 		t.Fatalf("Result too short: %s", string(result))
@@ -143,7 +143,7 @@ func TestOpenAITextDeltaPool(t *testing.T) {
 func BenchmarkBuildOpenAITextDeltaSSE_Pooled(b *testing.B) {
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		result := BuildOpenAITextDeltaSSE("chatcmpl-xyz", "gpt-4", 1234567890, "Hello world")
+		result := BuildOpenAITextDeltaSSE("chatcmpl-xyz", "gpt-4", 1234567890, 0, "Hello world")
 		_ = result
 	}
 }
@@ -244,7 +244,7 @@ func BenchmarkStreamSimulation_10kTokens_Pooled(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		for _, tok := range tokens {
-			result := BuildOpenAITextDeltaSSE("chatcmpl-xyz", "gpt-4", 1234567890, tok)
+			result := BuildOpenAITextDeltaSSE("chatcmpl-xyz", "gpt-4", 1234567890, 0, tok)
 			_ = result
 		}
 	}
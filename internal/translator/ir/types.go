@@ -14,13 +14,15 @@ const (
 	MetaClaudeTextEditor = "claude:text_editor"
 	MetaClaudeMCP        = "claude:mcp" // MCP (Model Context Protocol) servers
 
+	// OpenAI Responses API hosted tools (stored for passthrough)
+	MetaOpenAIComputerUse = "openai:computer_use"
+
 	// MCP tool metadata keys
 	MetaMCPServers = "mcp_servers" // MCP server configurations in request
 
 	MetaOpenAILogprobs         = "openai:logprobs"
 	MetaOpenAITopLogprobs      = "openai:top_logprobs"
 	MetaOpenAILogitBias        = "openai:logit_bias"
-	MetaOpenAISeed             = "openai:seed"
 	MetaOpenAIUser             = "openai:user"
 	MetaOpenAIFrequencyPenalty = "openai:frequency_penalty"
 	MetaOpenAIPresencePenalty  = "openai:presence_penalty"
@@ -30,6 +32,10 @@ const (
 
 	MetaClaudeMetadata = "claude:metadata"
 
+	// Sampling parameters of local inference servers (llama.cpp, vLLM)
+	MetaMinP              = "min_p"
+	MetaRepetitionPenalty = "repetition_penalty"
+
 	// Internal flags (prefixed with _ to indicate internal use)
 	MetaForceDisableThinking = "_force_disable_thinking" // Set by translator_wrapper for non-streaming Claude via Antigravity
 )
@@ -46,8 +52,12 @@ const (
 	EventTypeImage            EventType = "image"
 	EventTypeAudio            EventType = "audio"
 	EventTypeCodeExecution    EventType = "code_execution"
+	EventTypeHostedToolCall   EventType = "hosted_tool_call"
 	EventTypeError            EventType = "error"
 	EventTypeFinish           EventType = "finish"
+	// EventTypeChoiceFinish ends one choice of an n>1 stream while other
+	// choices are still streaming.
+	EventTypeChoiceFinish EventType = "choice_finish"
 )
 
 type FinishReason string
@@ -73,6 +83,7 @@ const (
 	FinishReasonSPII              FinishReason = "spii"               // Sensitive PII detected
 	FinishReasonImageSafety       FinishReason = "image_safety"       // Image safety issue
 	FinishReasonRecitation        FinishReason = "recitation"         // Recitation/copyright issue
+	FinishReasonRefusal           FinishReason = "refusal"            // Model declined to answer (Claude "refusal", OpenAI message.refusal)
 )
 
 // ThinkingLevel represents the level of thinking tokens for thinking models.
@@ -149,6 +160,7 @@ type UnifiedEvent struct {
 	Image             *ImagePart
 	Audio             *AudioPart
 	CodeExecution     *CodeExecutionPart
+	HostedToolCall    *HostedToolCall
 	GroundingMetadata *GroundingMetadata
 	StreamMeta        *StreamMeta
 	Error             error
@@ -159,6 +171,11 @@ type UnifiedEvent struct {
 	ContentFilter     any
 	SystemFingerprint string
 	RedactedData      string
+	// ChoiceIndex is the choice the event belongs to in n>1 streams.
+	ChoiceIndex int
+	// NativeFinishReason is the upstream's own finish reason on finish
+	// events, such as Gemini "SAFETY" or Claude "refusal".
+	NativeFinishReason string
 }
 
 // ErrorMessage returns the error message safely, handling nil Error.
@@ -181,11 +198,27 @@ type Usage struct {
 	RejectedPredictionTokens int64
 	CacheCreationInputTokens int64
 	CacheReadInputTokens     int64
-	ToolUsePromptTokens      int64 // Gemini: tokens used for tool/function call context
+	ToolUsePromptTokens      int64          // Gemini: tokens used for tool/function call context
+	ServerToolUse            *ServerToolUse // Claude: server-side tool invocations billed per request
 	PromptTokensDetails      *PromptTokensDetails
 	CompletionTokensDetails  *CompletionTokensDetails
 }
 
+// PredictionTokens returns the tokens of a predicted output the upstream
+// accepted and rejected.
+func (u *Usage) PredictionTokens() (accepted, rejected int64) {
+	if d := u.CompletionTokensDetails; d != nil && (d.AcceptedPredictionTokens > 0 || d.RejectedPredictionTokens > 0) {
+		return d.AcceptedPredictionTokens, d.RejectedPredictionTokens
+	}
+	return u.AcceptedPredictionTokens, u.RejectedPredictionTokens
+}
+
+// ServerToolUse counts server-side tool requests (Claude usage.server_tool_use).
+type ServerToolUse struct {
+	WebSearchRequests int64
+	WebFetchRequests  int64
+}
+
 type PromptTokensDetails struct {
 	CachedTokens int64
 	AudioTokens  int64
@@ -205,7 +238,8 @@ type OpenAIMeta struct {
 	ResponseID         string
 	CreateTime         int64
 	NativeFinishReason string
-	ThoughtsTokenCount int32 // Matches SDK int32
+	FinishReason       FinishReason // Why the first candidate stopped, for formats built from messages
+	ThoughtsTokenCount int32        // Matches SDK int32
 	Logprobs           any
 	GroundingMetadata  *GroundingMetadata // Google Search grounding metadata
 	PromptFeedback     *PromptFeedback    // Prompt-level safety feedback
@@ -229,12 +263,13 @@ type PromptFeedback struct {
 // CandidateResult holds the result of a single candidate/choice from the model.
 // Used when candidateCount/n > 1 to return multiple alternatives.
 type CandidateResult struct {
-	Index             int                // Candidate index (0-based)
-	Messages          []Message          // Messages from this candidate
-	FinishReason      FinishReason       // Why this candidate stopped
-	Logprobs          any                // Log probabilities for this candidate (OpenAI format)
-	GroundingMetadata *GroundingMetadata // Google Search grounding metadata for this candidate
-	SafetyRatings     []*SafetyRating    // Safety evaluation results
+	Index              int                // Candidate index (0-based)
+	Messages           []Message          // Messages from this candidate
+	FinishReason       FinishReason       // Why this candidate stopped
+	NativeFinishReason string             // Upstream's own finish reason, passed through as native_finish_reason
+	Logprobs           any                // Log probabilities for this candidate (OpenAI format)
+	GroundingMetadata  *GroundingMetadata // Google Search grounding metadata for this candidate
+	SafetyRatings      []*SafetyRating    // Safety evaluation results
 }
 
 // ToolCall represents a request from the model to execute a tool.
@@ -246,6 +281,15 @@ type ToolCall struct {
 	ThoughtSignature []byte // Opaque signature for thought reuse (matches SDK []byte)
 }
 
+// HostedToolCall is a Responses API output item for a tool the upstream runs
+// itself (web_search_call, file_search_call, computer_call) or the client's reply
+// to one (computer_call_output). Item is the raw JSON so it round-trips unchanged.
+type HostedToolCall struct {
+	ID   string
+	Type string
+	Item string
+}
+
 type Role string
 
 const (
@@ -462,11 +506,12 @@ type CacheControl struct {
 }
 
 type Message struct {
-	Role         Role
-	Content      []ContentPart
-	ToolCalls    []ToolCall
-	CacheControl *CacheControl
-	Refusal      string
+	Role            Role
+	Content         []ContentPart
+	ToolCalls       []ToolCall
+	HostedToolCalls []HostedToolCall // Responses API hosted tool items, passed through to Responses upstreams
+	CacheControl    *CacheControl
+	Refusal         string
 }
 
 // ToolDefinition represents a tool capability exposed to the model.
@@ -497,6 +542,7 @@ type UnifiedChatRequest struct {
 	StopSequences    []string
 	FrequencyPenalty *float64
 	PresencePenalty  *float64
+	Seed             *int64
 	Logprobs         *bool
 	TopLogprobs      *int
 	CandidateCount   *int
@@ -162,6 +162,30 @@ func ParseOpenAIUsage(u gjson.Result) *Usage {
 	return usage
 }
 
+// ParsePrediction parses an OpenAI style predicted output,
+// {"type":"content","content":...}, whose content is a string or an array
+// of text parts. The type may be omitted. It returns nil when v is not a
+// content prediction.
+func ParsePrediction(v gjson.Result) *PredictionConfig {
+	if !v.IsObject() {
+		return nil
+	}
+	if t := v.Get("type").String(); t != "" && t != "content" {
+		return nil
+	}
+	var text string
+	if content := v.Get("content"); content.IsArray() {
+		var sb strings.Builder
+		for _, p := range content.Array() {
+			sb.WriteString(p.Get("text").String())
+		}
+		text = sb.String()
+	} else {
+		text = content.String()
+	}
+	return &PredictionConfig{Type: "content", Content: text}
+}
+
 func DefaultGeminiSafetySettings() []map[string]string {
 	return []map[string]string{
 		{"category": "HARM_CATEGORY_HARASSMENT", "threshold": "OFF"},
@@ -305,35 +329,6 @@ func hasProblematicChars(s string) bool {
 	return false
 }
 
-func MapGeminiFinishReason(geminiReason string) FinishReason {
-	switch strings.ToUpper(geminiReason) {
-	case "STOP", "FINISH_REASON_UNSPECIFIED", "UNKNOWN":
-		return FinishReasonStop
-	case "MAX_TOKENS", "LENGTH":
-		return FinishReasonMaxTokens
-	case "TOOL_CALLS", "FUNCTION_CALL":
-		return FinishReasonToolCalls
-	case "SAFETY":
-		return FinishReasonContentFilter
-	case "RECITATION":
-		return FinishReasonRecitation
-	case "BLOCKLIST":
-		return FinishReasonBlocklist
-	case "PROHIBITED_CONTENT":
-		return FinishReasonProhibitedContent
-	case "SPII":
-		return FinishReasonSPII
-	case "IMAGE_SAFETY":
-		return FinishReasonImageSafety
-	case "OTHER":
-		return FinishReasonContentFilter // Map OTHER to content_filter
-	case "MALFORMED_FUNCTION_CALL":
-		return FinishReasonToolCalls // Still try to parse the tool call
-	default:
-		return FinishReasonUnknown
-	}
-}
-
 func ParseMalformedFunctionCall(finishMessage string) (string, string, bool) {
 	idx := strings.Index(finishMessage, ": call:")
 	if idx != -1 {
@@ -466,74 +461,6 @@ func convertMalformedArgsToJSONFallback(argsRaw string) string {
 	return result.String()
 }
 
-func MapClaudeFinishReason(claudeReason string) FinishReason {
-	switch claudeReason {
-	case "end_turn":
-		return FinishReasonStop // Claude "end_turn" = normal completion = IR "stop"
-	case "stop_sequence":
-		return FinishReasonStopSequence
-	case "max_tokens":
-		return FinishReasonMaxTokens
-	case "tool_use":
-		return FinishReasonToolCalls
-	default:
-		return FinishReasonUnknown
-	}
-}
-
-func MapOpenAIFinishReason(openaiReason string) FinishReason {
-	switch openaiReason {
-	case "stop":
-		return FinishReasonStop
-	case "length":
-		return FinishReasonMaxTokens // OpenAI "length" = IR "max_tokens"
-	case "tool_calls", "function_call":
-		return FinishReasonToolCalls
-	case "content_filter":
-		return FinishReasonContentFilter
-	default:
-		return FinishReasonUnknown
-	}
-}
-
-func MapFinishReasonToOpenAI(reason FinishReason) string {
-	switch reason {
-	case FinishReasonStop, FinishReasonStopSequence:
-		return "stop"
-	case FinishReasonMaxTokens:
-		return "length"
-	case FinishReasonToolCalls:
-		return "tool_calls"
-	case FinishReasonContentFilter, FinishReasonBlocklist,
-		FinishReasonProhibitedContent, FinishReasonSPII,
-		FinishReasonImageSafety, FinishReasonRecitation:
-		return "content_filter"
-	case FinishReasonError:
-		return "error"
-	default:
-		return "stop"
-	}
-}
-
-func MapFinishReasonToClaude(reason FinishReason) string {
-	switch reason {
-	case FinishReasonStop:
-		return "end_turn"
-	case FinishReasonMaxTokens:
-		return "max_tokens"
-	case FinishReasonToolCalls:
-		return "tool_use"
-	case FinishReasonStopSequence:
-		return "stop_sequence"
-	case FinishReasonContentFilter, FinishReasonBlocklist,
-		FinishReasonProhibitedContent, FinishReasonSPII,
-		FinishReasonImageSafety, FinishReasonRecitation:
-		return "end_turn" // Claude doesn't have content_filter equivalent
-	default:
-		return "end_turn"
-	}
-}
-
 func MapStandardRole(role string) Role {
 	switch role {
 	case "system", "developer":
@@ -2,6 +2,8 @@ package ir
 
 import (
 	"testing"
+
+	"github.com/tidwall/gjson"
 )
 
 func TestCleanJsonSchemaForGemini_RemovesExclusiveMinMax(t *testing.T) {
@@ -273,6 +275,10 @@ func TestMapGeminiFinishReason(t *testing.T) {
 		{"SPII", FinishReasonSPII},
 		{"IMAGE_SAFETY", FinishReasonImageSafety},
 		{"RECITATION", FinishReasonRecitation},
+		{"LANGUAGE", FinishReasonContentFilter},
+		{"IMAGE_PROHIBITED_CONTENT", FinishReasonProhibitedContent},
+		{"IMAGE_RECITATION", FinishReasonRecitation},
+		{"UNEXPECTED_TOOL_CALL", FinishReasonError},
 
 		// Unknown values
 		{"SOME_FUTURE_REASON", FinishReasonUnknown},
@@ -298,6 +304,9 @@ func TestMapClaudeFinishReason(t *testing.T) {
 		{"stop_sequence", FinishReasonStopSequence},
 		{"max_tokens", FinishReasonMaxTokens},
 		{"tool_use", FinishReasonToolCalls},
+		{"refusal", FinishReasonRefusal},
+		{"pause_turn", FinishReasonStop},
+		{"model_context_window_exceeded", FinishReasonMaxTokens},
 		{"unknown_value", FinishReasonUnknown},
 		{"", FinishReasonUnknown},
 	}
@@ -374,12 +383,13 @@ func TestMapFinishReasonToClaude(t *testing.T) {
 		{FinishReasonMaxTokens, "max_tokens"},
 		{FinishReasonToolCalls, "tool_use"},
 		{FinishReasonStopSequence, "stop_sequence"},
-		{FinishReasonContentFilter, "end_turn"},
-		{FinishReasonBlocklist, "end_turn"},
-		{FinishReasonProhibitedContent, "end_turn"},
-		{FinishReasonSPII, "end_turn"},
-		{FinishReasonImageSafety, "end_turn"},
-		{FinishReasonRecitation, "end_turn"},
+		{FinishReasonContentFilter, "refusal"},
+		{FinishReasonBlocklist, "refusal"},
+		{FinishReasonProhibitedContent, "refusal"},
+		{FinishReasonSPII, "refusal"},
+		{FinishReasonImageSafety, "refusal"},
+		{FinishReasonRecitation, "refusal"},
+		{FinishReasonRefusal, "refusal"},
 		{FinishReasonUnknown, "end_turn"},
 	}
 
@@ -412,7 +422,7 @@ func TestFinishReasonRoundTrip_OpenAI(t *testing.T) {
 
 func TestFinishReasonRoundTrip_Claude(t *testing.T) {
 	// Test that common Claude reasons round-trip correctly
-	tests := []string{"end_turn", "max_tokens", "tool_use", "stop_sequence"}
+	tests := []string{"end_turn", "max_tokens", "tool_use", "stop_sequence", "refusal"}
 
 	for _, claudeReason := range tests {
 		t.Run(claudeReason, func(t *testing.T) {
@@ -540,3 +550,28 @@ func TestSanitizeText(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePrediction(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{`{"type":"content","content":"abc"}`, "abc"},
+		{`{"content":[{"type":"text","text":"a"},{"type":"text","text":"bc"}]}`, "abc"},
+		{`{"type":"content","content":""}`, ""},
+		{`{"type":"other","content":"abc"}`, "-"},
+		{`"abc"`, "-"},
+	}
+	for _, tt := range tests {
+		got := ParsePrediction(gjson.Parse(tt.raw))
+		if tt.want == "-" {
+			if got != nil {
+				t.Errorf("ParsePrediction(%s) = %+v, want nil", tt.raw, got)
+			}
+			continue
+		}
+		if got == nil || got.Type != "content" || got.Content != tt.want {
+			t.Errorf("ParsePrediction(%s) = %+v, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
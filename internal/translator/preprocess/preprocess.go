@@ -3,13 +3,41 @@
 package preprocess
 
 import (
+	"context"
+
 	"github.com/nghyane/llm-mux/internal/registry"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 )
 
+type previewContextKey struct{}
+
+// WithPreview marks ctx as a preview of the translation, such as
+// /v1/validate or explain mode, which must not call upstream. Previews
+// truncate oversized tool results instead of summarizing them.
+func WithPreview(ctx context.Context) context.Context {
+	return context.WithValue(ctx, previewContextKey{}, true)
+}
+
+func isPreview(ctx context.Context) bool {
+	preview, _ := ctx.Value(previewContextKey{}).(bool)
+	return preview
+}
+
+// RewritesMessages reports whether Apply may add to or change the messages of
+// a request for model: a system prompt matches the model, or the tool result
+// or tool loop guard is installed. Translations that otherwise pass requests
+// through unchanged must go through the IR when it does.
+func RewritesMessages(model string) bool {
+	if toolResultGuard.Load() != nil || toolLoopGuard.Load() != nil {
+		return true
+	}
+	prompts := systemPrompts.Load()
+	return prompts != nil && matchSystemPrompt(*prompts, model) != nil
+}
+
 // Apply normalizes the IR request before translation.
 // This is the single entry point for all preprocessing.
-func Apply(req *ir.UnifiedChatRequest) error {
+func Apply(ctx context.Context, req *ir.UnifiedChatRequest) error {
 	if req == nil {
 		return nil
 	}
@@ -19,6 +47,8 @@ func Apply(req *ir.UnifiedChatRequest) error {
 	applyThinkingNormalization(req, info)
 	applyLimits(req, info)
 	applyProviderDefaults(req, info)
+	applySystemPrompts(req)
+	applyToolResultGuard(ctx, req)
 
-	return nil
+	return applyToolLoopGuard(req)
 }
@@ -0,0 +1,72 @@
+package preprocess
+
+import (
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// FoldLateSystemMessages moves system messages that follow the conversation's
+// first non-system message into the next user message, for upstreams that
+// reject the system role mid-conversation. The folded text is wrapped in
+// [system] ... [/system] ahead of the user's text. Late system messages with
+// no user message after them become a user message of their own.
+func FoldLateSystemMessages(req *ir.UnifiedChatRequest) {
+	if req == nil {
+		return
+	}
+	var (
+		out     = make([]ir.Message, 0, len(req.Messages))
+		pending []string
+		started bool
+	)
+	for _, msg := range req.Messages {
+		if msg.Role == ir.RoleSystem {
+			if !started {
+				out = append(out, msg)
+			} else if text := ir.CombineTextParts(msg); text != "" {
+				pending = append(pending, text)
+			}
+			continue
+		}
+		started = true
+		if msg.Role == ir.RoleUser && len(pending) > 0 && !hasToolResult(msg) {
+			msg.Content = prependText(msg.Content, foldSystemText(pending)+"\n\n")
+			pending = nil
+		}
+		out = append(out, msg)
+	}
+	if len(pending) > 0 {
+		out = append(out, ir.Message{
+			Role:    ir.RoleUser,
+			Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: foldSystemText(pending)}},
+		})
+	}
+	req.Messages = out
+}
+
+func foldSystemText(texts []string) string {
+	return "[system]\n" + strings.Join(texts, "\n\n") + "\n[/system]"
+}
+
+// prependText adds text to the first text part, keeping single-text messages
+// a plain string upstream.
+func prependText(parts []ir.ContentPart, text string) []ir.ContentPart {
+	out := make([]ir.ContentPart, 0, len(parts)+1)
+	if len(parts) > 0 && parts[0].Type == ir.ContentTypeText {
+		first := parts[0]
+		first.Text = text + first.Text
+		return append(append(out, first), parts[1:]...)
+	}
+	out = append(out, ir.ContentPart{Type: ir.ContentTypeText, Text: strings.TrimSuffix(text, "\n\n")})
+	return append(out, parts...)
+}
+
+func hasToolResult(msg ir.Message) bool {
+	for _, part := range msg.Content {
+		if part.Type == ir.ContentTypeToolResult {
+			return true
+		}
+	}
+	return false
+}
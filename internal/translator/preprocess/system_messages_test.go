@@ -0,0 +1,36 @@
+package preprocess
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func textMessage(role ir.Role, text string) ir.Message {
+	return ir.Message{Role: role, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: text}}}
+}
+
+func TestFoldLateSystemMessages(t *testing.T) {
+	req := &ir.UnifiedChatRequest{Messages: []ir.Message{
+		textMessage(ir.RoleSystem, "be brief"),
+		textMessage(ir.RoleUser, "hi"),
+		textMessage(ir.RoleAssistant, "hello"),
+		textMessage(ir.RoleSystem, "wrap up"),
+		textMessage(ir.RoleUser, "bye"),
+		textMessage(ir.RoleSystem, "done"),
+	}}
+	FoldLateSystemMessages(req)
+
+	if len(req.Messages) != 5 {
+		t.Fatalf("messages = %+v", req.Messages)
+	}
+	if req.Messages[0].Role != ir.RoleSystem {
+		t.Errorf("leading system message moved: %+v", req.Messages[0])
+	}
+	if got := req.Messages[3]; got.Role != ir.RoleUser || got.Content[0].Text != "[system]\nwrap up\n[/system]\n\nbye" {
+		t.Errorf("folded message = %+v", got)
+	}
+	if got := req.Messages[4]; got.Role != ir.RoleUser || got.Content[0].Text != "[system]\ndone\n[/system]" {
+		t.Errorf("trailing system message = %+v", got)
+	}
+}
@@ -0,0 +1,111 @@
+package preprocess
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/sseutil"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// SystemPrompt is a templated system prompt added to requests for matching
+// models.
+type SystemPrompt struct {
+	Name    string
+	Version int
+	// Models are glob-style model patterns; the first matching prompt applies.
+	Models []string
+	// WithTools applies the prompt only to requests carrying tools.
+	WithTools bool
+	// Append places the prompt after the client's system messages instead of
+	// before them.
+	Append bool
+	// Template may reference {{tool_names}}, {{date}} and {{model}}.
+	Template string
+}
+
+var (
+	systemPrompts atomic.Pointer[[]SystemPrompt]
+	// systemPromptNow is replaced in tests.
+	systemPromptNow = time.Now
+)
+
+// SetSystemPrompts installs the system prompt templates; none disables them.
+// Each new prompt version is logged, so the logs show which prompt requests
+// were sent with at any time.
+func SetSystemPrompts(prompts []SystemPrompt) {
+	previous := make(map[string]SystemPrompt)
+	if old := systemPrompts.Load(); old != nil {
+		for _, p := range *old {
+			previous[p.Name] = p
+		}
+	}
+	for _, p := range prompts {
+		old, ok := previous[p.Name]
+		switch {
+		case !ok || old.Version != p.Version:
+			log.Infof("system prompt %s in use for models %s", p.ID(), strings.Join(p.Models, ", "))
+		case old.Template != p.Template:
+			log.Warnf("system prompt %s changed without a version bump", p.ID())
+		}
+	}
+	if len(prompts) == 0 {
+		systemPrompts.Store(nil)
+		return
+	}
+	systemPrompts.Store(&prompts)
+}
+
+func applySystemPrompts(req *ir.UnifiedChatRequest) {
+	prompts := systemPrompts.Load()
+	if prompts == nil {
+		return
+	}
+	p := matchSystemPrompt(*prompts, req.Model)
+	if p == nil || (p.WithTools && len(req.Tools) == 0) {
+		return
+	}
+	msg := ir.Message{
+		Role:    ir.RoleSystem,
+		Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: p.render(req)}},
+	}
+	at := 0
+	if p.Append {
+		for at < len(req.Messages) && req.Messages[at].Role == ir.RoleSystem {
+			at++
+		}
+	}
+	req.Messages = append(req.Messages[:at:at], append([]ir.Message{msg}, req.Messages[at:]...)...)
+	log.Debugf("system prompt %s added for model %s", p.ID(), req.Model)
+}
+
+func matchSystemPrompt(prompts []SystemPrompt, model string) *SystemPrompt {
+	for i := range prompts {
+		for _, pattern := range prompts[i].Models {
+			if sseutil.MatchModelPattern(pattern, model) {
+				return &prompts[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (p SystemPrompt) render(req *ir.UnifiedChatRequest) string {
+	names := make([]string, len(req.Tools))
+	for i, t := range req.Tools {
+		names[i] = t.Name
+	}
+	return strings.NewReplacer(
+		"{{tool_names}}", strings.Join(names, ", "),
+		"{{date}}", systemPromptNow().UTC().Format(time.DateOnly),
+		"{{model}}", req.Model,
+	).Replace(p.Template)
+}
+
+// ID identifies the prompt and its version, such as "tool-nudge@v3".
+func (p SystemPrompt) ID() string {
+	return p.Name + "@v" + strconv.Itoa(p.Version)
+}
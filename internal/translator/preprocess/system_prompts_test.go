@@ -0,0 +1,52 @@
+package preprocess
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func TestSystemPrompts(t *testing.T) {
+	t.Cleanup(func() {
+		SetSystemPrompts(nil)
+		systemPromptNow = time.Now
+	})
+	systemPromptNow = func() time.Time { return time.Date(2026, 3, 9, 23, 0, 0, 0, time.UTC) }
+	SetSystemPrompts([]SystemPrompt{
+		{Name: "nudge", Version: 2, Models: []string{"qwen*"}, WithTools: true, Append: true,
+			Template: "Today is {{date}}. {{model}} must call one of: {{tool_names}}."},
+		{Name: "all", Version: 1, Models: []string{"*"}, Template: "Be brief."},
+	})
+	text := func(m ir.Message) string { return ir.CombineTextParts(m) }
+
+	req := &ir.UnifiedChatRequest{
+		Model: "qwen3-coder",
+		Tools: []ir.ToolDefinition{{Name: "read"}, {Name: "bash"}},
+		Messages: []ir.Message{
+			{Role: ir.RoleSystem, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "You are an agent."}}},
+			{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Fix the build."}}},
+		},
+	}
+	applySystemPrompts(req)
+	if len(req.Messages) != 3 || req.Messages[1].Role != ir.RoleSystem {
+		t.Fatalf("prompt not appended after the client's system prompt: %+v", req.Messages)
+	}
+	if got, want := text(req.Messages[1]), "Today is 2026-03-09. qwen3-coder must call one of: read, bash."; got != want {
+		t.Errorf("rendered %q, want %q", got, want)
+	}
+
+	// Without tools the first matching template does not apply, and no other
+	// template is tried.
+	req = &ir.UnifiedChatRequest{Model: "qwen3-coder", Messages: []ir.Message{{Role: ir.RoleUser}}}
+	applySystemPrompts(req)
+	if len(req.Messages) != 1 {
+		t.Errorf("prompt added to a request without tools: %+v", req.Messages)
+	}
+
+	req = &ir.UnifiedChatRequest{Model: "gpt-5", Messages: []ir.Message{{Role: ir.RoleUser}}}
+	applySystemPrompts(req)
+	if len(req.Messages) != 2 || text(req.Messages[0]) != "Be brief." {
+		t.Errorf("prompt not prepended: %+v", req.Messages)
+	}
+}
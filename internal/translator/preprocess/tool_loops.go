@@ -0,0 +1,178 @@
+package preprocess
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// Tool loop guard actions.
+const (
+	ToolLoopNotice = "notice"
+	ToolLoopError  = "error"
+)
+
+const defaultToolLoopNotice = "The last %d tool calls were identical (%s) and kept producing the same kind of result. " +
+	"Do not call it again with the same arguments. Change the approach or explain to the user what is blocking progress."
+
+// ToolLoopGuard detects agents calling the same tool with the same arguments
+// over and over.
+type ToolLoopGuard struct {
+	// Threshold is the number of consecutive identical tool-call turns that
+	// counts as a loop. 0 disables the guard.
+	Threshold int
+	// Action is ToolLoopNotice (add a system message) or ToolLoopError.
+	Action string
+	// Notice overrides the system message; %d and %s are not expanded.
+	Notice string
+}
+
+// ToolLoopDetectedError rejects a request whose conversation is stuck in a
+// tool-call loop.
+type ToolLoopDetectedError struct {
+	Tool        string
+	Count       int
+	Fingerprint string
+}
+
+func (e *ToolLoopDetectedError) Error() string {
+	return fmt.Sprintf("tool_loop_detected: %s was called %d times in a row with identical arguments (conversation %s)", e.Tool, e.Count, e.Fingerprint)
+}
+
+func (e *ToolLoopDetectedError) StatusCode() int { return http.StatusUnprocessableEntity }
+
+var toolLoopGuard atomic.Pointer[ToolLoopGuard]
+
+// SetToolLoopGuard installs the global tool loop guard. A zero Threshold disables it.
+func SetToolLoopGuard(g ToolLoopGuard) {
+	if g.Threshold <= 0 {
+		toolLoopGuard.Store(nil)
+		return
+	}
+	toolLoopGuard.Store(&g)
+}
+
+func applyToolLoopGuard(req *ir.UnifiedChatRequest) error {
+	g := toolLoopGuard.Load()
+	if g == nil {
+		return nil
+	}
+	tool, count := trailingToolLoop(req.Messages)
+	if count < g.Threshold {
+		return nil
+	}
+	fp := conversationFingerprint(req.Messages)
+	log.Warnf("tool loop detected: model=%s tool=%s repeats=%d conversation=%s action=%s", req.Model, tool, count, fp, g.Action)
+	if g.Action == ToolLoopError {
+		return &ToolLoopDetectedError{Tool: tool, Count: count, Fingerprint: fp}
+	}
+	notice := g.Notice
+	if notice == "" {
+		notice = fmt.Sprintf(defaultToolLoopNotice, count, tool)
+	}
+	req.Messages = append(req.Messages, ir.Message{
+		Role:    ir.RoleSystem,
+		Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: notice}},
+	})
+	return nil
+}
+
+// trailingToolLoop counts how many of the most recent assistant turns made
+// exactly the same tool calls, returning the tool names and the count.
+func trailingToolLoop(msgs []ir.Message) (string, int) {
+	var sig, names string
+	count := 0
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role != ir.RoleAssistant {
+			continue
+		}
+		if len(msgs[i].ToolCalls) == 0 {
+			break
+		}
+		s, n := toolCallSignature(msgs[i].ToolCalls)
+		if count > 0 && s != sig {
+			break
+		}
+		sig, names = s, n
+		count++
+	}
+	return names, count
+}
+
+// toolCallSignature identifies a turn's tool calls by name and canonical
+// arguments, ignoring call IDs and key order.
+func toolCallSignature(calls []ir.ToolCall) (sig, names string) {
+	parts := make([]string, len(calls))
+	tools := make([]string, len(calls))
+	for i, tc := range calls {
+		args := strings.TrimSpace(tc.Args)
+		var v any
+		if json.Unmarshal([]byte(args), &v) == nil {
+			args = string(appendCanonicalJSON(nil, v))
+		}
+		parts[i] = tc.Name + "\x00" + args
+		tools[i] = tc.Name
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "\x01"), strings.Join(tools, ",")
+}
+
+// appendCanonicalJSON appends v, as decoded from JSON, with object keys
+// sorted so that equal arguments encode the same.
+func appendCanonicalJSON(b []byte, v any) []byte {
+	switch v := v.(type) {
+	case map[string]any:
+		b = append(b, '{')
+		for i, k := range slices.Sorted(maps.Keys(v)) {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			key, _ := json.Marshal(k)
+			b = append(append(b, key...), ':')
+			b = appendCanonicalJSON(b, v[k])
+		}
+		return append(b, '}')
+	case []any:
+		b = append(b, '[')
+		for i, e := range v {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendCanonicalJSON(b, e)
+		}
+		return append(b, ']')
+	default:
+		leaf, _ := json.Marshal(v)
+		return append(b, leaf...)
+	}
+}
+
+// conversationFingerprint identifies a conversation by its system prompt and
+// first user message, which stay the same across an agent's turns.
+func conversationFingerprint(msgs []ir.Message) string {
+	h := sha256.New()
+	for _, m := range msgs {
+		if m.Role != ir.RoleSystem && m.Role != ir.RoleUser {
+			continue
+		}
+		for _, p := range m.Content {
+			if p.Type == ir.ContentTypeText {
+				h.Write([]byte(p.Text))
+			}
+		}
+		if m.Role == ir.RoleUser {
+			break
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,51 @@
+package preprocess
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func loopConversation(turns int) []ir.Message {
+	msgs := []ir.Message{
+		{Role: ir.RoleSystem, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "You are an agent."}}},
+		{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Fix the build."}}},
+		{Role: ir.RoleAssistant, ToolCalls: []ir.ToolCall{{ID: "a", Name: "ls", Args: `{}`}}},
+	}
+	args := []string{`{"cmd":"make","dir":"."}`, `{"dir":".", "cmd":"make"}`}
+	for i := 0; i < turns; i++ {
+		msgs = append(msgs,
+			ir.Message{Role: ir.RoleAssistant, ToolCalls: []ir.ToolCall{{ID: string(rune('b' + i)), Name: "bash", Args: args[i%2]}}},
+			ir.Message{Role: ir.RoleTool, Content: []ir.ContentPart{{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{Result: "error"}}}},
+		)
+	}
+	return msgs
+}
+
+func TestToolLoopGuard(t *testing.T) {
+	t.Cleanup(func() { SetToolLoopGuard(ToolLoopGuard{}) })
+
+	SetToolLoopGuard(ToolLoopGuard{Threshold: 3, Action: ToolLoopNotice})
+	req := &ir.UnifiedChatRequest{Messages: loopConversation(2)}
+	if err := applyToolLoopGuard(req); err != nil || len(req.Messages) != 7 {
+		t.Fatalf("below threshold: err=%v, %d messages", err, len(req.Messages))
+	}
+	req = &ir.UnifiedChatRequest{Messages: loopConversation(3)}
+	if err := applyToolLoopGuard(req); err != nil {
+		t.Fatal(err)
+	}
+	if last := req.Messages[len(req.Messages)-1]; last.Role != ir.RoleSystem {
+		t.Errorf("notice not appended, last role = %v", last.Role)
+	}
+
+	SetToolLoopGuard(ToolLoopGuard{Threshold: 3, Action: ToolLoopError})
+	err := applyToolLoopGuard(&ir.UnifiedChatRequest{Messages: loopConversation(4)})
+	var loopErr *ToolLoopDetectedError
+	if !errors.As(err, &loopErr) || loopErr.Tool != "bash" || loopErr.Count != 4 || loopErr.Fingerprint == "" {
+		t.Fatalf("err = %#v", err)
+	}
+	if ir.ErrorStatus(err) != 422 {
+		t.Errorf("status = %d", ir.ErrorStatus(err))
+	}
+}
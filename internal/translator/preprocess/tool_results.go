@@ -0,0 +1,217 @@
+package preprocess
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+)
+
+// Tool result guard strategies.
+const (
+	ToolResultTruncate  = "truncate"
+	ToolResultSummarize = "summarize"
+)
+
+const (
+	defaultSummaryTimeout = 30 * time.Second
+	// maxConcurrentSummaries bounds the summarization calls of one request.
+	maxConcurrentSummaries = 4
+	maxCachedSummaries     = 1024
+)
+
+// ToolResultGuard limits the size of tool results forwarded to providers.
+type ToolResultGuard struct {
+	// MaxBytes is the largest tool result passed through unchanged. 0 disables the guard.
+	MaxBytes int
+	// Strategy is ToolResultTruncate (head/tail) or ToolResultSummarize.
+	Strategy string
+	// HeadBytes and TailBytes are kept around the truncation marker.
+	// Default: two thirds head, one third tail of MaxBytes.
+	HeadBytes int
+	TailBytes int
+	// SummaryModel is the model used by ToolResultSummarize.
+	SummaryModel string
+	// SummaryTimeout bounds each summarization call (default 30s).
+	SummaryTimeout time.Duration
+}
+
+// ToolResultSummarizer condenses an oversized tool result using model.
+type ToolResultSummarizer func(ctx context.Context, model, text string) (string, error)
+
+var (
+	toolResultGuard        atomic.Pointer[ToolResultGuard]
+	toolResultSummarizer   atomic.Pointer[ToolResultSummarizer]
+	toolResultSummaries    summaryCache
+	toolResultSummaryGroup singleflight.Group
+)
+
+// SetToolResultGuard installs the global tool result guard. A zero MaxBytes disables it.
+func SetToolResultGuard(g ToolResultGuard) {
+	if g.MaxBytes <= 0 {
+		toolResultGuard.Store(nil)
+		return
+	}
+	toolResultGuard.Store(&g)
+}
+
+// SetToolResultSummarizer registers the callback used by the summarize strategy
+// and drops cached summaries. Without one, oversized results fall back to
+// head/tail truncation.
+func SetToolResultSummarizer(fn ToolResultSummarizer) {
+	toolResultSummaries.clear()
+	if fn == nil {
+		toolResultSummarizer.Store(nil)
+		return
+	}
+	toolResultSummarizer.Store(&fn)
+}
+
+func applyToolResultGuard(ctx context.Context, req *ir.UnifiedChatRequest) {
+	g := toolResultGuard.Load()
+	if g == nil {
+		return
+	}
+	var oversized []*ir.ToolResultPart
+	for i := range req.Messages {
+		for j := range req.Messages[i].Content {
+			part := &req.Messages[i].Content[j]
+			if part.Type != ir.ContentTypeToolResult || part.ToolResult == nil {
+				continue
+			}
+			if len(part.ToolResult.Result) > g.MaxBytes {
+				oversized = append(oversized, part.ToolResult)
+			}
+		}
+	}
+	if len(oversized) == 0 {
+		return
+	}
+
+	fn := toolResultSummarizer.Load()
+	if g.Strategy != ToolResultSummarize || g.SummaryModel == "" || fn == nil || isPreview(ctx) {
+		for _, r := range oversized {
+			r.Result = g.truncate(r.Result)
+		}
+		return
+	}
+	var eg errgroup.Group
+	eg.SetLimit(maxConcurrentSummaries)
+	for _, r := range oversized {
+		eg.Go(func() error {
+			r.Result = g.summarize(ctx, *fn, r.Result)
+			return nil
+		})
+	}
+	_ = eg.Wait()
+}
+
+// summarize condenses text with fn, reusing the summary of an identical
+// result from an earlier turn. It truncates text when summarization fails.
+func (g *ToolResultGuard) summarize(ctx context.Context, fn ToolResultSummarizer, text string) string {
+	sum := sha256.Sum256([]byte(g.SummaryModel + "\x00" + text))
+	key := hex.EncodeToString(sum[:])
+	v, err, _ := toolResultSummaryGroup.Do(key, func() (any, error) {
+		if summary, ok := toolResultSummaries.get(key); ok {
+			return summary, nil
+		}
+		timeout := g.SummaryTimeout
+		if timeout <= 0 {
+			timeout = defaultSummaryTimeout
+		}
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		summary, err := fn(callCtx, g.SummaryModel, text)
+		if err != nil {
+			return "", err
+		}
+		if summary == "" {
+			return "", errors.New("empty summary")
+		}
+		toolResultSummaries.put(key, summary)
+		return summary, nil
+	})
+	if err != nil {
+		log.Warnf("tool result summarization failed, truncating instead: %v", err)
+		return g.truncate(text)
+	}
+	return fmt.Sprintf("[tool output summarized by llm-mux: original was %d bytes]\n%s", len(text), v.(string))
+}
+
+// summaryCache keeps tool result summaries by content hash, so resending a
+// conversation does not summarize its earlier tool results again.
+type summaryCache struct {
+	mu      sync.Mutex
+	entries map[string]string
+}
+
+func (c *summaryCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.entries[key]
+	return s, ok
+}
+
+func (c *summaryCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+func (c *summaryCache) put(key, summary string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]string)
+	}
+	if len(c.entries) >= maxCachedSummaries {
+		for k := range c.entries {
+			delete(c.entries, k) // map order is random, so this evicts a random entry
+			break
+		}
+	}
+	c.entries[key] = summary
+}
+
+func (g *ToolResultGuard) truncate(text string) string {
+	head, tail := g.HeadBytes, g.TailBytes
+	if head <= 0 && tail <= 0 {
+		head = g.MaxBytes * 2 / 3
+		tail = g.MaxBytes - head
+	}
+	if head+tail >= len(text) {
+		return text
+	}
+	headText := trimToRuneStart(text[:head], false)
+	tailText := trimToRuneStart(text[len(text)-tail:], true)
+	omitted := len(text) - len(headText) - len(tailText)
+	return fmt.Sprintf("%s\n\n[... %d bytes of tool output truncated by llm-mux ...]\n\n%s", headText, omitted, tailText)
+}
+
+// trimToRuneStart drops partial UTF-8 sequences left at a cut boundary.
+func trimToRuneStart(s string, leading bool) string {
+	if leading {
+		for len(s) > 0 && !utf8.RuneStart(s[0]) {
+			s = s[1:]
+		}
+		return s
+	}
+	for len(s) > 0 {
+		r, size := utf8.DecodeLastRuneInString(s)
+		if r != utf8.RuneError || size != 1 {
+			break
+		}
+		s = s[:len(s)-1]
+	}
+	return s
+}
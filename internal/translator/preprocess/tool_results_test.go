@@ -0,0 +1,131 @@
+package preprocess
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func toolResultRequest(result string) *ir.UnifiedChatRequest {
+	return &ir.UnifiedChatRequest{
+		Messages: []ir.Message{{
+			Role: ir.RoleTool,
+			Content: []ir.ContentPart{{
+				Type:       ir.ContentTypeToolResult,
+				ToolResult: &ir.ToolResultPart{ToolCallID: "call_1", Result: result},
+			}},
+		}},
+	}
+}
+
+func TestToolResultGuardTruncatesHeadAndTail(t *testing.T) {
+	SetToolResultGuard(ToolResultGuard{MaxBytes: 100, HeadBytes: 10, TailBytes: 5})
+	defer SetToolResultGuard(ToolResultGuard{})
+
+	req := toolResultRequest("HEAD-START" + strings.Repeat("x", 500) + "TAIL!")
+	applyToolResultGuard(context.Background(), req)
+
+	got := req.Messages[0].Content[0].ToolResult.Result
+	if !strings.HasPrefix(got, "HEAD-START") || !strings.HasSuffix(got, "TAIL!") {
+		t.Fatalf("expected head and tail to be kept, got %q", got)
+	}
+	if !strings.Contains(got, "[... 500 bytes of tool output truncated by llm-mux ...]") {
+		t.Fatalf("expected truncation marker, got %q", got)
+	}
+}
+
+func TestToolResultGuardKeepsSmallResults(t *testing.T) {
+	SetToolResultGuard(ToolResultGuard{MaxBytes: 100})
+	defer SetToolResultGuard(ToolResultGuard{})
+
+	req := toolResultRequest("ok")
+	applyToolResultGuard(context.Background(), req)
+	if got := req.Messages[0].Content[0].ToolResult.Result; got != "ok" {
+		t.Fatalf("small result modified: %q", got)
+	}
+}
+
+func TestToolResultGuardSummarizeFallsBackToTruncate(t *testing.T) {
+	SetToolResultGuard(ToolResultGuard{MaxBytes: 20, Strategy: ToolResultSummarize, SummaryModel: "m"})
+	SetToolResultSummarizer(func(context.Context, string, string) (string, error) {
+		return "", errors.New("unavailable")
+	})
+	defer func() {
+		SetToolResultGuard(ToolResultGuard{})
+		SetToolResultSummarizer(nil)
+	}()
+
+	req := toolResultRequest(strings.Repeat("y", 200))
+	applyToolResultGuard(context.Background(), req)
+	if got := req.Messages[0].Content[0].ToolResult.Result; !strings.Contains(got, "truncated by llm-mux") {
+		t.Fatalf("expected truncation fallback, got %q", got)
+	}
+
+	SetToolResultSummarizer(func(_ context.Context, model, text string) (string, error) {
+		return "short summary", nil
+	})
+	req = toolResultRequest(strings.Repeat("y", 200))
+	applyToolResultGuard(context.Background(), req)
+	if got := req.Messages[0].Content[0].ToolResult.Result; !strings.HasSuffix(got, "short summary") {
+		t.Fatalf("expected summary, got %q", got)
+	}
+}
+
+func TestToolResultGuardSummarizeCachesAndRunsConcurrently(t *testing.T) {
+	SetToolResultGuard(ToolResultGuard{MaxBytes: 20, Strategy: ToolResultSummarize, SummaryModel: "m"})
+	var calls, inFlight, maxInFlight atomic.Int32
+	SetToolResultSummarizer(func(ctx context.Context, _, text string) (string, error) {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		calls.Add(1)
+		if n := inFlight.Add(1); n > maxInFlight.Load() {
+			maxInFlight.Store(n)
+		}
+		time.Sleep(50 * time.Millisecond)
+		inFlight.Add(-1)
+		return "summary of " + text[:1], nil
+	})
+	defer func() {
+		SetToolResultGuard(ToolResultGuard{})
+		SetToolResultSummarizer(nil)
+	}()
+
+	newRequest := func() *ir.UnifiedChatRequest {
+		req := toolResultRequest(strings.Repeat("a", 200))
+		req.Messages = append(req.Messages, toolResultRequest(strings.Repeat("b", 200)).Messages...)
+		return req
+	}
+	for turn := range 2 {
+		req := newRequest()
+		applyToolResultGuard(context.Background(), req)
+		if got := req.Messages[1].Content[0].ToolResult.Result; !strings.HasSuffix(got, "summary of b") {
+			t.Fatalf("turn %d: expected summary, got %q", turn, got)
+		}
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("summarizer called %d times, want 2 (cached on the second turn)", n)
+	}
+	if n := maxInFlight.Load(); n != 2 {
+		t.Errorf("%d summaries ran at once, want 2", n)
+	}
+
+	req := toolResultRequest(strings.Repeat("d", 200))
+	applyToolResultGuard(WithPreview(context.Background()), req)
+	if got := req.Messages[0].Content[0].ToolResult.Result; !strings.Contains(got, "truncated by llm-mux") || calls.Load() != 2 {
+		t.Fatalf("preview summarized (%d calls): %q", calls.Load(), got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req = toolResultRequest(strings.Repeat("c", 200))
+	applyToolResultGuard(ctx, req)
+	if got := req.Messages[0].Content[0].ToolResult.Result; !strings.Contains(got, "truncated by llm-mux") {
+		t.Fatalf("expected truncation after cancellation, got %q", got)
+	}
+}
@@ -48,9 +48,10 @@ func ParseClaudeRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 		}
 	}
 
-	for _, m := range parsed.Get("messages").Array() {
-		req.Messages = append(req.Messages, parseClaudeMessage(m))
-	}
+	messages := parsed.Get("messages").Array()
+	req.Messages = append(req.Messages, ir.MapMessages(len(messages), func(i int) ir.Message {
+		return parseClaudeMessage(messages[i])
+	})...)
 
 	req.Metadata = make(map[string]any)
 	for _, t := range parsed.Get("tools").Array() {
@@ -80,6 +81,10 @@ func ParseClaudeRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 				req.Metadata[ir.MetaClaudeComputer] = conf
 				continue
 			}
+			if strings.HasPrefix(toolType, "code_execution_") {
+				req.Metadata[ir.MetaCodeExecution] = map[string]any{"_original_type": toolType}
+				continue
+			}
 			if strings.HasPrefix(toolType, "bash_") {
 				req.Metadata[ir.MetaClaudeBash] = map[string]any{"_original_type": toolType}
 				continue
@@ -119,6 +124,10 @@ func ParseClaudeRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 		req.MCPServers = append(req.MCPServers, mcp)
 	}
 
+	// Claude has no predicted outputs; clients may send OpenAI's field for
+	// upstreams that support it.
+	req.Prediction = ir.ParsePrediction(parsed.Get("prediction"))
+
 	if thinking := parsed.Get("thinking"); thinking.IsObject() {
 		if thinking.Get("type").String() == "enabled" {
 			budget := int32(thinking.Get("budget_tokens").Int())
@@ -167,6 +176,11 @@ func ParseClaudeRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 				req.Metadata[k] = v
 			}
 		}
+		delete(req.Metadata, "safety_settings")
+	}
+
+	if req.SafetySettings, err = parseClientSafetySettings(parsed); err != nil {
+		return nil, err
 	}
 
 	return req, nil
@@ -308,6 +322,16 @@ func ParseClaudeResponse(rawJSON []byte) ([]ir.Message, *ir.Usage, error) {
 	return nil, usage, nil
 }
 
+// ParseClaudeFinishReason returns the stop reason of a Messages API response
+// and the upstream's own value.
+func ParseClaudeFinishReason(rawJSON []byte) (ir.FinishReason, string) {
+	native := gjson.GetBytes(rawJSON, "stop_reason").String()
+	if native == "" {
+		return ir.FinishReasonStop, ""
+	}
+	return ir.MapClaudeFinishReason(native), native
+}
+
 func ParseClaudeChunk(rawJSON []byte) ([]*ir.UnifiedEvent, error) {
 	return ParseClaudeChunkWithState(rawJSON, nil)
 }
@@ -325,16 +349,32 @@ func ParseClaudeChunkWithState(rawJSON []byte, state *ir.ClaudeStreamParserState
 		return ir.ParseClaudeStreamDeltaWithState(parsed, state), nil
 	case "content_block_stop":
 		return ir.ParseClaudeContentBlockStop(parsed, state), nil
+	case "message_start":
+		if state != nil {
+			state.StartUsage = ir.ParseClaudeUsage(parsed.Get("message.usage"))
+		}
 	case "message_delta":
-		return ir.ParseClaudeMessageDelta(parsed), nil
+		events := ir.ParseClaudeMessageDelta(parsed)
+		for _, ev := range events {
+			state.MergeStartUsage(ev.Usage)
+		}
+		return events, nil
 	case "message_stop":
 		return []*ir.UnifiedEvent{{Type: ir.EventTypeFinish, FinishReason: ir.FinishReasonStop}}, nil
 	case "error":
-		return []*ir.UnifiedEvent{{Type: ir.EventTypeError, Error: &ClaudeAPIError{Message: parsed.Get("error.message").String()}}}, nil
+		return []*ir.UnifiedEvent{{Type: ir.EventTypeError, Error: &ClaudeAPIError{Type: parsed.Get("error.type").String(), Message: parsed.Get("error.message").String()}}}, nil
 	}
 	return nil, nil
 }
 
-type ClaudeAPIError struct{ Message string }
+// ClaudeAPIError is an error event received in a Claude stream.
+type ClaudeAPIError struct {
+	Type    string
+	Message string
+}
 
 func (e *ClaudeAPIError) Error() string { return e.Message }
+
+// StatusCode returns the HTTP status Anthropic uses for the error type, so an
+// overloaded_error mid-stream is reported like a 529 response.
+func (e *ClaudeAPIError) StatusCode() int { return ir.ClaudeErrorStatus(e.Type) }
@@ -527,6 +527,27 @@ func TestParseClaudeChunk_Ping(t *testing.T) {
 	}
 }
 
+func TestParseClaudeChunk_MessageDeltaUsage(t *testing.T) {
+	state := ir.NewClaudeStreamParserState()
+	start := `data: {"type":"message_start","message":{"usage":{"input_tokens":120,"cache_creation_input_tokens":30,"cache_read_input_tokens":800,"output_tokens":1}}}`
+	if _, err := ParseClaudeChunkWithState([]byte(start), state); err != nil {
+		t.Fatalf("message_start: %v", err)
+	}
+
+	delta := `data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":42,"server_tool_use":{"web_search_requests":2}}}`
+	events, err := ParseClaudeChunkWithState([]byte(delta), state)
+	if err != nil || len(events) != 1 || events[0].Usage == nil {
+		t.Fatalf("message_delta: events=%v err=%v", events, err)
+	}
+	u := events[0].Usage
+	if u.PromptTokens != 120 || u.CompletionTokens != 42 || u.CacheCreationInputTokens != 30 || u.CacheReadInputTokens != 800 {
+		t.Errorf("usage = %+v", u)
+	}
+	if u.ServerToolUse == nil || u.ServerToolUse.WebSearchRequests != 2 {
+		t.Errorf("server_tool_use = %+v", u.ServerToolUse)
+	}
+}
+
 // ==================== round-trip Tests ====================
 
 func TestClaudeRedactedThinking_RoundTrip(t *testing.T) {
@@ -636,3 +657,14 @@ func TestParseClaudeRequest_CacheControl(t *testing.T) {
 		t.Errorf("CacheControl.Type = %q, want %q", msg.CacheControl.Type, "ephemeral")
 	}
 }
+
+func TestParseClaudeRequest_Prediction(t *testing.T) {
+	input := `{"model":"m","max_tokens":10,"messages":[{"role":"user","content":"Rename x"}],"prediction":{"type":"content","content":"let y = 1;"}}`
+	req, err := ParseClaudeRequest([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseClaudeRequest failed: %v", err)
+	}
+	if req.Prediction == nil || req.Prediction.Content != "let y = 1;" {
+		t.Fatalf("Prediction = %+v", req.Prediction)
+	}
+}
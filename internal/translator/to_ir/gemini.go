@@ -39,6 +39,9 @@ func ParseGeminiRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 		req.TopP = ir.ExtractTopP(gc, "topP")
 		req.TopK = ir.ExtractTopK(gc, "topK")
 		req.StopSequences = ir.ExtractStopSequences(gc, "stopSequences")
+		req.FrequencyPenalty = ir.ExtractFrequencyPenalty(gc, "frequencyPenalty")
+		req.PresencePenalty = ir.ExtractPresencePenalty(gc, "presencePenalty")
+		req.Seed = ir.ExtractSeed(gc)
 
 		if tc := gc.Get("thinkingConfig"); tc.Exists() {
 			req.Thinking = &ir.ThinkingConfig{
@@ -73,8 +76,11 @@ func ParseGeminiRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 		}
 	}
 
-	for _, c := range parsed.Get("contents").Array() {
-		if msg := parseGeminiContent(c); msg.Role != "" {
+	contents := parsed.Get("contents").Array()
+	for _, msg := range ir.MapMessages(len(contents), func(i int) ir.Message {
+		return parseGeminiContent(contents[i])
+	}) {
+		if msg.Role != "" {
 			req.Messages = append(req.Messages, msg)
 		}
 	}
@@ -122,6 +128,10 @@ func ParseGeminiRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 		}
 	}
 
+	// Gemini has no predicted outputs; clients may send OpenAI's field for
+	// upstreams that support it.
+	req.Prediction = ir.ParsePrediction(parsed.Get("prediction"))
+
 	if v := parsed.Get("cachedContent").String(); v != "" {
 		req.Metadata[ir.MetaGeminiCachedContent] = v
 	}
@@ -152,6 +162,10 @@ func ParseGeminiRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 		}
 	}
 
+	if req.SafetySettings, err = ir.ParseSafetySettings(parsed.Get("safetySettings")); err != nil {
+		return nil, err
+	}
+
 	return req, nil
 }
 
@@ -329,9 +343,9 @@ func ParseGeminiResponseCandidates(rawJSON []byte, schemaCtx *ir.ToolSchemaConte
 			continue
 		}
 
-		finishReason := ir.FinishReasonStop
-		if fr := candidate.Get("finishReason"); fr.Exists() {
-			finishReason = ir.MapGeminiFinishReason(fr.String())
+		finishReason, native := ir.FinishReasonStop, candidate.Get("finishReason").String()
+		if native != "" {
+			finishReason = ir.MapGeminiFinishReason(native)
 		}
 
 		var groundingMeta *ir.GroundingMetadata
@@ -346,12 +360,13 @@ func ParseGeminiResponseCandidates(rawJSON []byte, schemaCtx *ir.ToolSchemaConte
 		}
 
 		results = append(results, ir.CandidateResult{
-			Index:             i,
-			Messages:          []ir.Message{*msg},
-			FinishReason:      finishReason,
-			Logprobs:          parseGeminiLogprobs(candidate),
-			GroundingMetadata: groundingMeta,
-			SafetyRatings:     parseGeminiSafetyRatings(candidate),
+			Index:              i,
+			Messages:           []ir.Message{*msg},
+			FinishReason:       finishReason,
+			NativeFinishReason: native,
+			Logprobs:           parseGeminiLogprobs(candidate),
+			GroundingMetadata:  groundingMeta,
+			SafetyRatings:      parseGeminiSafetyRatings(candidate),
 		})
 	}
 
@@ -487,6 +502,7 @@ func ParseGeminiChunkWithStateContext(rawJSON []byte, state *ir.GeminiStreamPars
 
 	var events []*ir.UnifiedEvent
 	var finishReason ir.FinishReason
+	var nativeFinishReason string
 	var toolCallIndex int
 
 	usage := parseGeminiUsage(parsed)
@@ -601,6 +617,7 @@ func ParseGeminiChunkWithStateContext(rawJSON []byte, state *ir.GeminiStreamPars
 		if fr := candidate.Get("finishReason"); fr.Exists() {
 			frStr := fr.String()
 			finishReason = ir.MapGeminiFinishReason(frStr)
+			nativeFinishReason = frStr
 
 			if frStr == "MALFORMED_FUNCTION_CALL" {
 				if fm := candidate.Get("finishMessage"); fm.Exists() {
@@ -652,11 +669,12 @@ func ParseGeminiChunkWithStateContext(rawJSON []byte, state *ir.GeminiStreamPars
 		}
 
 		events = append(events, &ir.UnifiedEvent{
-			Type:              ir.EventTypeFinish,
-			Usage:             usage,
-			FinishReason:      finishReason,
-			GroundingMetadata: groundingMeta,
-			Logprobs:          logprobs,
+			Type:               ir.EventTypeFinish,
+			Usage:              usage,
+			FinishReason:       finishReason,
+			NativeFinishReason: nativeFinishReason,
+			GroundingMetadata:  groundingMeta,
+			Logprobs:           logprobs,
 		})
 	}
 
@@ -795,6 +813,9 @@ func parseGeminiMeta(parsed gjson.Result) *ir.OpenAIMeta {
 	meta.ServiceTier = parsed.Get("service_tier").String()
 	if candidates := parsed.Get("candidates").Array(); len(candidates) > 0 {
 		meta.NativeFinishReason = candidates[0].Get("finishReason").String()
+		if meta.NativeFinishReason != "" {
+			meta.FinishReason = ir.MapGeminiFinishReason(meta.NativeFinishReason)
+		}
 		meta.Logprobs = parseGeminiLogprobs(candidates[0])
 	}
 	return meta
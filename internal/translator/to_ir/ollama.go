@@ -24,9 +24,10 @@ func ParseOllamaRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 		req.TopK = ir.ExtractTopK(opts)
 		req.MaxTokens = ir.ExtractMaxTokens(opts, "num_predict")
 		req.StopSequences = ir.ExtractStopSequences(opts, "stop")
-		if v := opts.Get("seed"); v.Exists() {
-			req.Metadata["ollama_seed"] = v.Int()
-		}
+		req.FrequencyPenalty = ir.ExtractFrequencyPenalty(opts, "frequency_penalty")
+		req.PresencePenalty = ir.ExtractPresencePenalty(opts, "presence_penalty")
+		req.Seed = ir.ExtractSeed(opts)
+		ir.ApplyLocalSamplingParams(req, opts, "repeat_penalty")
 		if v := opts.Get("num_ctx"); v.Exists() {
 			req.Metadata["ollama_num_ctx"] = v.Int()
 		}
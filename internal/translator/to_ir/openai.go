@@ -25,62 +25,42 @@ func ParseOpenAIRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 
 	ir.ApplyCommonParams(req, root)
 	ir.ApplyOpenAIExtendedParams(req, root)
+	ir.ApplyLocalSamplingParams(req, root)
 
 	if input := root.Get("input"); input.Exists() && !root.Get("messages").Exists() {
 		parseResponsesAPIFields(root, req)
 	} else {
-		for _, m := range root.Get("messages").Array() {
-			req.Messages = append(req.Messages, parseOpenAIMessage(m))
-		}
+		messages := root.Get("messages").Array()
+		req.Messages = append(req.Messages, ir.MapMessages(len(messages), func(i int) ir.Message {
+			return parseOpenAIMessage(messages[i])
+		})...)
 	}
 
 	for _, t := range root.Get("tools").Array() {
 		toolType := t.Get("type").String()
 		if !t.Get("function").Exists() {
-			if strings.HasPrefix(toolType, "web_search") {
-				conf := map[string]any{}
-				if v := t.Get("search_context_size"); v.Exists() {
-					conf["search_context_size"] = v.String()
-				}
-				if v := t.Get("user_location"); v.IsObject() {
-					var val any
-					if json.Unmarshal([]byte(v.Raw), &val) == nil {
-						conf["user_location"] = val
-					}
-				}
+			// Hosted tools keep their OpenAI type so Responses upstreams get them back unchanged.
+			switch {
+			case strings.HasPrefix(toolType, "web_search"):
+				conf := map[string]any{"_openai_type": toolType}
+				copyToolFields(conf, t, "search_context_size", "user_location", "filters")
 				req.Metadata[ir.MetaGoogleSearch] = conf
 				continue
-			}
-			if toolType == "code_interpreter" {
+			case toolType == "code_interpreter" || toolType == "code_execution":
 				conf := map[string]any{}
-				if v := t.Get("container"); v.IsObject() {
-					var val any
-					if json.Unmarshal([]byte(v.Raw), &val) == nil {
-						conf["container"] = val
-					}
-				}
+				copyToolFields(conf, t, "container")
 				req.Metadata[ir.MetaCodeExecution] = conf
 				continue
-			}
-			if toolType == "file_search" {
+			case toolType == "file_search":
 				conf := map[string]any{}
-				if v := t.Get("vector_store"); v.IsObject() {
-					var val any
-					if json.Unmarshal([]byte(v.Raw), &val) == nil {
-						conf["vector_store"] = val
-					}
-				}
-				if v := t.Get("max_num_results"); v.Exists() {
-					conf["max_num_results"] = int(v.Int())
-				}
-				if v := t.Get("ranking_options"); v.IsObject() {
-					var val any
-					if json.Unmarshal([]byte(v.Raw), &val) == nil {
-						conf["ranking_options"] = val
-					}
-				}
+				copyToolFields(conf, t, "vector_store_ids", "vector_store", "max_num_results", "ranking_options", "filters")
 				req.Metadata[ir.MetaFileSearch] = conf
 				continue
+			case strings.HasPrefix(toolType, "computer_use"):
+				conf := map[string]any{"_openai_type": toolType}
+				copyToolFields(conf, t, "display_width", "display_height", "environment")
+				req.Metadata[ir.MetaOpenAIComputerUse] = conf
+				continue
 			}
 		}
 		if tool := parseOpenAITool(t); tool != nil {
@@ -106,9 +86,7 @@ func ParseOpenAIRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 			Format: v.Get("format").String(),
 		}
 	}
-	if v := root.Get("prediction"); v.IsObject() && v.Get("type").String() == "content" {
-		req.Prediction = &ir.PredictionConfig{Type: "content", Content: v.Get("content").String()}
-	}
+	req.Prediction = ir.ParsePrediction(root.Get("prediction"))
 	if v := root.Get("stream_options"); v.IsObject() {
 		req.StreamOptions = &ir.StreamOptionsConfig{IncludeUsage: v.Get("include_usage").Bool()}
 	}
@@ -136,9 +114,6 @@ func ParseOpenAIRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 			req.Metadata[ir.MetaOpenAILogitBias] = lb
 		}
 	}
-	if v := root.Get("seed"); v.Exists() {
-		req.Metadata[ir.MetaOpenAISeed] = int(v.Int())
-	}
 	if v := root.Get("user").String(); v != "" {
 		req.Metadata[ir.MetaOpenAIUser] = v
 	}
@@ -163,6 +138,10 @@ func ParseOpenAIRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 		}
 	}
 
+	if req.SafetySettings, err = parseClientSafetySettings(root); err != nil {
+		return nil, err
+	}
+
 	return req, nil
 }
 
@@ -219,8 +198,14 @@ func parseResponsesInputItem(item gjson.Result) *ir.Message {
 		return msg
 	case "function_call":
 		return &ir.Message{Role: ir.RoleAssistant, ToolCalls: []ir.ToolCall{{ID: item.Get("call_id").String(), Name: item.Get("name").String(), Args: item.Get("arguments").String()}}}
+	case "web_search_call", "file_search_call", "computer_call":
+		return &ir.Message{Role: ir.RoleAssistant, HostedToolCalls: []ir.HostedToolCall{{ID: item.Get("id").String(), Type: t, Item: item.Raw}}}
+	case "computer_call_output":
+		return &ir.Message{Role: ir.RoleTool, HostedToolCalls: []ir.HostedToolCall{{ID: item.Get("call_id").String(), Type: t, Item: item.Raw}}}
 	case "function_call_output":
-		return &ir.Message{Role: ir.RoleTool, Content: []ir.ContentPart{{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{ToolCallID: item.Get("call_id").String(), Result: item.Get("output").String()}}}}
+		res := &ir.ToolResultPart{ToolCallID: item.Get("call_id").String()}
+		parseToolResultContent(item.Get("output"), res)
+		return &ir.Message{Role: ir.RoleTool, Content: []ir.ContentPart{{Type: ir.ContentTypeToolResult, ToolResult: res}}}
 	}
 	return nil
 }
@@ -291,6 +276,27 @@ func ParseOpenAIResponse(rawJSON []byte) ([]ir.Message, *ir.Usage, error) {
 	return []ir.Message{msg}, usage, nil
 }
 
+// ParseOpenAIFinishReason returns the finish reason of the first choice of a
+// chat completion or of a Responses API response, and the upstream's own
+// value. A refusal with a normal stop is reported as FinishReasonRefusal.
+func ParseOpenAIFinishReason(rawJSON []byte) (ir.FinishReason, string) {
+	root := gjson.ParseBytes(rawJSON)
+	if root.Get("output").IsArray() {
+		status, reason := root.Get("status").String(), root.Get("incomplete_details.reason").String()
+		refused := root.Get(`output.#(type=="message").content.#(type=="refusal")`).Exists()
+		if status != "incomplete" {
+			reason = ""
+		}
+		return ir.MapOpenAIRefusal(ir.MapResponsesFinishReason(status, reason), refused), reason
+	}
+	native := root.Get("choices.0.finish_reason").String()
+	if native == "" {
+		return ir.FinishReasonStop, ""
+	}
+	refused := root.Get("choices.0.message.refusal").String() != ""
+	return ir.MapOpenAIRefusal(ir.MapOpenAIFinishReason(native), refused), native
+}
+
 func parseResponsesAPIOutput(output gjson.Result, usage *ir.Usage) ([]ir.Message, *ir.Usage, error) {
 	var res []ir.Message
 	for _, item := range output.Array() {
@@ -317,6 +323,8 @@ func parseResponsesAPIOutput(output gjson.Result, usage *ir.Usage) ([]ir.Message
 			}
 		case "function_call":
 			res = append(res, ir.Message{Role: ir.RoleAssistant, ToolCalls: []ir.ToolCall{{ID: item.Get("call_id").String(), Name: item.Get("name").String(), Args: item.Get("arguments").String()}}})
+		case "web_search_call", "file_search_call", "computer_call":
+			res = append(res, ir.Message{Role: ir.RoleAssistant, HostedToolCalls: []ir.HostedToolCall{{ID: item.Get("id").String(), Type: item.Get("type").String(), Item: item.Raw}}})
 		}
 	}
 	return res, usage, nil
@@ -351,15 +359,24 @@ func ParseOpenAIChunk(rawJSON []byte) ([]*ir.UnifiedEvent, error) {
 		return parseResponsesStreamEvent(et, root)
 	}
 
-	choice := root.Get("choices.0")
-	if !choice.Exists() {
+	choices := root.Get("choices").Array()
+	if len(choices) == 0 {
 		if u := root.Get("usage"); u.Exists() {
 			usage := ir.ParseOpenAIUsage(u)
 			return []*ir.UnifiedEvent{{Type: ir.EventTypeFinish, Usage: usage, SystemFingerprint: root.Get("system_fingerprint").String()}}, nil
 		}
 		return nil, nil
 	}
+	var evs []*ir.UnifiedEvent
+	for _, choice := range choices {
+		evs = append(evs, parseOpenAIChoiceDelta(root, choice)...)
+	}
+	return evs, nil
+}
 
+// parseOpenAIChoiceDelta parses the delta of one choice of a chunk; n>1
+// streams carry one choice per index.
+func parseOpenAIChoiceDelta(root, choice gjson.Result) []*ir.UnifiedEvent {
 	var evs []*ir.UnifiedEvent
 	d := choice.Get("delta")
 	if v := d.Get("content").String(); v != "" {
@@ -379,7 +396,7 @@ func ParseOpenAIChunk(rawJSON []byte) ([]*ir.UnifiedEvent, error) {
 	}
 
 	if fr := choice.Get("finish_reason").String(); fr != "" {
-		ev := &ir.UnifiedEvent{Type: ir.EventTypeFinish, FinishReason: ir.MapOpenAIFinishReason(fr), SystemFingerprint: root.Get("system_fingerprint").String()}
+		ev := &ir.UnifiedEvent{Type: ir.EventTypeFinish, FinishReason: ir.MapOpenAIFinishReason(fr), NativeFinishReason: fr, SystemFingerprint: root.Get("system_fingerprint").String()}
 		if v := choice.Get("logprobs"); v.Exists() {
 			ev.Logprobs = v.Value()
 		}
@@ -393,7 +410,12 @@ func ParseOpenAIChunk(rawJSON []byte) ([]*ir.UnifiedEvent, error) {
 			evs[0].Logprobs = v.Value()
 		}
 	}
-	return evs, nil
+	if ci := int(choice.Get("index").Int()); ci > 0 {
+		for _, ev := range evs {
+			ev.ChoiceIndex = ci
+		}
+	}
+	return evs
 }
 
 func parseResponsesStreamEvent(et string, root gjson.Result) ([]*ir.UnifiedEvent, error) {
@@ -410,8 +432,12 @@ func parseResponsesStreamEvent(et string, root gjson.Result) ([]*ir.UnifiedEvent
 		return []*ir.UnifiedEvent{{Type: ir.EventTypeToolCallDelta, ToolCall: &ir.ToolCall{ID: root.Get("item_id").String(), Args: root.Get("delta").String()}, ToolCallIndex: int(root.Get("output_index").Int())}}, nil
 	case "response.function_call_arguments.done":
 		return []*ir.UnifiedEvent{{Type: ir.EventTypeToolCall, ToolCall: &ir.ToolCall{ID: root.Get("item_id").String(), Name: root.Get("name").String(), Args: root.Get("arguments").String()}, ToolCallIndex: int(root.Get("output_index").Int())}}, nil
-	case "response.web_search_call.in_progress":
-		return []*ir.UnifiedEvent{{Type: ir.EventTypeToolCall, ToolCall: &ir.ToolCall{ID: root.Get("item_id").String(), Name: "web_search", Args: "{}"}}}, nil
+	case "response.output_item.done":
+		switch t := root.Get("item.type").String(); t {
+		case "web_search_call", "file_search_call", "computer_call":
+			item := root.Get("item")
+			return []*ir.UnifiedEvent{{Type: ir.EventTypeHostedToolCall, HostedToolCall: &ir.HostedToolCall{ID: item.Get("id").String(), Type: t, Item: item.Raw}, ToolCallIndex: int(root.Get("output_index").Int())}}, nil
+		}
 	case "response.refusal.delta":
 		if v := root.Get("delta").String(); v != "" {
 			return []*ir.UnifiedEvent{{Type: ir.EventTypeToken, Refusal: v}}, nil
@@ -420,8 +446,11 @@ func parseResponsesStreamEvent(et string, root gjson.Result) ([]*ir.UnifiedEvent
 		if v := root.Get("delta").String(); v != "" {
 			return []*ir.UnifiedEvent{{Type: ir.EventTypeAudio, Audio: &ir.AudioPart{Transcript: v}}}, nil
 		}
-	case "response.completed":
-		ev := &ir.UnifiedEvent{Type: ir.EventTypeFinish, FinishReason: ir.FinishReasonStop}
+	case "response.completed", "response.incomplete":
+		ev := &ir.UnifiedEvent{Type: ir.EventTypeFinish, FinishReason: ir.MapResponsesFinishReason(root.Get("response.status").String(), root.Get("response.incomplete_details.reason").String())}
+		if ev.FinishReason != ir.FinishReasonStop {
+			ev.NativeFinishReason = root.Get("response.incomplete_details.reason").String()
+		}
 		if u := root.Get("response.usage"); u.Exists() {
 			ev.Usage = ir.ParseOpenAIUsage(u)
 		}
@@ -451,9 +480,10 @@ func parseOpenAIMessage(m gjson.Result) ir.Message {
 		}
 	}
 	c := m.Get("content")
+	// Tool message content is folded into the tool result below.
 	if c.Type == gjson.String && role != "tool" {
 		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeText, Text: c.String()})
-	} else {
+	} else if role != "tool" {
 		for _, item := range c.Array() {
 			if p := parseOpenAIContentPart(item, &msg); p != nil {
 				msg.Content = append(msg.Content, *p)
@@ -476,7 +506,9 @@ func parseOpenAIMessage(m gjson.Result) ir.Message {
 		if id == "" {
 			id = m.Get("tool_use_id").String()
 		}
-		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{ToolCallID: id, Result: ir.SanitizeText(extractContentString(c))}})
+		res := &ir.ToolResultPart{ToolCallID: id}
+		parseToolResultContent(c, res)
+		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeToolResult, ToolResult: res})
 	}
 	return msg
 }
@@ -539,11 +571,22 @@ func parseOpenAIContentPart(item gjson.Result, msg *ir.Message) *ir.ContentPart
 		msg.ToolCalls = append(msg.ToolCalls, ir.ToolCall{ID: item.Get("id").String(), Name: item.Get("name").String(), Args: args})
 	case "tool_result":
 		msg.Role = ir.RoleTool
-		return &ir.ContentPart{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{ToolCallID: item.Get("tool_use_id").String(), Result: ir.SanitizeText(extractContentString(item.Get("content")))}}
+		res := &ir.ToolResultPart{ToolCallID: item.Get("tool_use_id").String(), IsError: item.Get("is_error").Bool()}
+		parseToolResultContent(item.Get("content"), res)
+		return &ir.ContentPart{Type: ir.ContentTypeToolResult, ToolResult: res}
 	}
 	return nil
 }
 
+// copyToolFields copies the given fields of a hosted tool definition into conf.
+func copyToolFields(conf map[string]any, t gjson.Result, keys ...string) {
+	for _, k := range keys {
+		if v := t.Get(k); v.Exists() && v.Type != gjson.Null {
+			conf[k] = v.Value()
+		}
+	}
+}
+
 func parseOpenAITool(t gjson.Result) *ir.ToolDefinition {
 	var n, d string
 	var pr gjson.Result
@@ -622,6 +665,39 @@ func parseDataURI(url string) *ir.ImagePart {
 	return &ir.ImagePart{MimeType: m, Data: p[1]}
 }
 
+// parseToolResultContent fills res from tool output given either as a string or as
+// an array of content parts. Text parts are joined; images and files are kept so
+// targets that accept media in tool results still receive them.
+func parseToolResultContent(c gjson.Result, res *ir.ToolResultPart) {
+	if !c.IsArray() {
+		res.Result = ir.SanitizeText(extractContentString(c))
+		return
+	}
+	var texts []string
+	for _, item := range c.Array() {
+		p := parseResponsesContentPart(item)
+		if p == nil {
+			p = parseOpenAIContentPart(item, &ir.Message{})
+		}
+		if p == nil {
+			continue
+		}
+		switch p.Type {
+		case ir.ContentTypeText:
+			texts = append(texts, p.Text)
+		case ir.ContentTypeImage:
+			res.Images = append(res.Images, p.Image)
+		case ir.ContentTypeFile:
+			res.Files = append(res.Files, p.File)
+		}
+	}
+	if len(texts) == 0 && len(res.Images) == 0 && len(res.Files) == 0 {
+		res.Result = c.Raw
+		return
+	}
+	res.Result = ir.SanitizeText(strings.Join(texts, "\n"))
+}
+
 func extractContentString(c gjson.Result) string {
 	if c.Type == gjson.String {
 		return c.String()
@@ -633,3 +709,13 @@ func extractContentString(c gjson.Result) string {
 	}
 	return c.Raw
 }
+
+// parseClientSafetySettings reads Gemini safety settings sent by a non-Gemini
+// client, either top-level (e.g. via extra_body) or in metadata.
+func parseClientSafetySettings(root gjson.Result) ([]ir.SafetySetting, error) {
+	v := root.Get("safety_settings")
+	if !v.Exists() {
+		v = root.Get("metadata.safety_settings")
+	}
+	return ir.ParseSafetySettings(v)
+}
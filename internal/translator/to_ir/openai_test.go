@@ -614,3 +614,118 @@ func TestParseOpenAIRequest_MaxCompletionTokens(t *testing.T) {
 		t.Errorf("MaxTokens = %v, want 300", req.MaxTokens)
 	}
 }
+
+// ==================== Sampling Parameter Tests ====================
+
+func TestParseOpenAIRequest_PenaltiesAndSeed(t *testing.T) {
+	input := `{
+		"model": "gpt-4o",
+		"messages": [{"role": "user", "content": "Hello"}],
+		"frequency_penalty": 0.5,
+		"presence_penalty": -0.25,
+		"seed": 42
+	}`
+
+	req, err := ParseOpenAIRequest([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest failed: %v", err)
+	}
+
+	if req.FrequencyPenalty == nil || *req.FrequencyPenalty != 0.5 {
+		t.Errorf("FrequencyPenalty = %v, want 0.5", req.FrequencyPenalty)
+	}
+	if req.PresencePenalty == nil || *req.PresencePenalty != -0.25 {
+		t.Errorf("PresencePenalty = %v, want -0.25", req.PresencePenalty)
+	}
+	if req.Seed == nil || *req.Seed != 42 {
+		t.Errorf("Seed = %v, want 42", req.Seed)
+	}
+}
+
+// ==================== Tool Result Content Tests ====================
+
+func TestParseOpenAIRequest_ToolResultWithImage(t *testing.T) {
+	input := `{
+		"model": "gpt-4o",
+		"messages": [
+			{"role": "assistant", "tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "screenshot", "arguments": "{}"}}]},
+			{"role": "tool", "tool_call_id": "call_1", "content": [
+				{"type": "text", "text": "Captured"},
+				{"type": "image_url", "image_url": {"url": "data:image/png;base64,iVBORw0KGgo="}},
+				{"type": "text", "text": "1 window"}
+			]}
+		]
+	}`
+
+	req, err := ParseOpenAIRequest([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest failed: %v", err)
+	}
+
+	msg := req.Messages[1]
+	if len(msg.Content) != 1 || msg.Content[0].Type != ir.ContentTypeToolResult {
+		t.Fatalf("expected a single tool result part, got %+v", msg.Content)
+	}
+	res := msg.Content[0].ToolResult
+	if res.Result != "Captured\n1 window" {
+		t.Errorf("Result = %q", res.Result)
+	}
+	if len(res.Images) != 1 || res.Images[0].MimeType != "image/png" || res.Images[0].Data != "iVBORw0KGgo=" {
+		t.Errorf("Images = %+v", res.Images)
+	}
+}
+
+// ==================== Hosted Tool Tests ====================
+
+func TestParseOpenAIRequest_ResponsesHostedTools(t *testing.T) {
+	input := `{
+		"model": "computer-use-preview",
+		"input": [
+			{"role": "user", "content": "Open the page"},
+			{"type": "computer_call", "id": "cu_1", "call_id": "call_1", "action": {"type": "screenshot"}},
+			{"type": "computer_call_output", "call_id": "call_1", "output": {"type": "input_image", "image_url": "data:image/png;base64,AAA="}}
+		],
+		"tools": [
+			{"type": "web_search", "search_context_size": "high"},
+			{"type": "computer_use_preview", "display_width": 1280, "display_height": 800, "environment": "browser"}
+		]
+	}`
+
+	req, err := ParseOpenAIRequest([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest failed: %v", err)
+	}
+
+	ws, _ := req.Metadata[ir.MetaGoogleSearch].(map[string]any)
+	if ws["_openai_type"] != "web_search" || ws["search_context_size"] != "high" {
+		t.Errorf("web search config = %v", ws)
+	}
+	cu, _ := req.Metadata[ir.MetaOpenAIComputerUse].(map[string]any)
+	if cu["environment"] != "browser" || cu["display_width"] != float64(1280) {
+		t.Errorf("computer use config = %v", cu)
+	}
+	if len(req.Messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(req.Messages))
+	}
+	if h := req.Messages[1].HostedToolCalls; len(h) != 1 || h[0].Type != "computer_call" || h[0].ID != "cu_1" {
+		t.Errorf("computer_call item = %+v", h)
+	}
+	if h := req.Messages[2].HostedToolCalls; len(h) != 1 || req.Messages[2].Role != ir.RoleTool || h[0].Type != "computer_call_output" {
+		t.Errorf("computer_call_output item = %+v", req.Messages[2])
+	}
+}
+
+func TestParseOpenAIChunk_HostedToolOutputItem(t *testing.T) {
+	chunk := `data: {"type":"response.output_item.done","output_index":1,"item":{"type":"web_search_call","id":"ws_1","status":"completed"}}`
+
+	events, err := ParseOpenAIChunk([]byte(chunk))
+	if err != nil {
+		t.Fatalf("ParseOpenAIChunk failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != ir.EventTypeHostedToolCall {
+		t.Fatalf("expected a hosted tool event, got %+v", events)
+	}
+	if h := events[0].HostedToolCall; h.ID != "ws_1" || h.Type != "web_search_call" || events[0].ToolCallIndex != 1 {
+		t.Errorf("hosted tool call = %+v", h)
+	}
+}
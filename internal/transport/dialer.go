@@ -0,0 +1,216 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// IP family preferences for DialerOptions.IPPreference.
+const (
+	PreferIPv4 = "prefer-ipv4"
+	PreferIPv6 = "prefer-ipv6"
+	IPv4Only   = "ipv4-only"
+	IPv6Only   = "ipv6-only"
+)
+
+// DialerOptions controls how upstream connections are established.
+// The zero value dials like the shared transport.
+type DialerOptions struct {
+	// IPPreference orders or restricts address families: PreferIPv4, PreferIPv6,
+	// IPv4Only or IPv6Only. Empty keeps the resolver's order.
+	IPPreference string
+	// FallbackDelay is how long the preferred family gets before the other family
+	// is raced against it (happy eyeballs). Zero uses 300ms; negative dials the
+	// families one after the other.
+	FallbackDelay time.Duration
+	// Hosts maps hostnames to fixed IP addresses, bypassing DNS.
+	Hosts map[string]string
+	// DNSCache, when set, serves resolver answers from an in-process cache.
+	DNSCache *DNSCache
+}
+
+// IsZero reports whether o leaves dialing unchanged.
+func (o DialerOptions) IsZero() bool {
+	return o.IPPreference == "" && o.FallbackDelay == 0 && len(o.Hosts) == 0 && o.DNSCache == nil
+}
+
+// Validate checks the preference and host overrides.
+func (o DialerOptions) Validate() error {
+	switch o.IPPreference {
+	case "", PreferIPv4, PreferIPv6, IPv4Only, IPv6Only:
+	default:
+		return fmt.Errorf("unknown ip-preference %q", o.IPPreference)
+	}
+	for host, ip := range o.Hosts {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("hosts: %q is not an IP address for %s", ip, host)
+		}
+	}
+	return nil
+}
+
+const defaultFallbackDelay = 300 * time.Millisecond
+
+// DialContext returns a dial function applying o on top of base.
+func (o DialerOptions) DialContext(base *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if o.IsZero() {
+		return base.DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		ips, err := o.resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		primary, fallback := o.partition(ips)
+		if len(primary) == 0 {
+			return nil, &net.DNSError{Err: "no addresses for " + o.IPPreference, Name: host, IsNotFound: true}
+		}
+		return o.dialParallel(ctx, base, network, port, primary, fallback)
+	}
+}
+
+func (o DialerOptions) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if ip, ok := o.Hosts[strings.ToLower(host)]; ok {
+		return []net.IP{net.ParseIP(ip)}, nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	if o.DNSCache != nil {
+		return o.DNSCache.LookupIP(ctx, host)
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, len(addrs))
+	for i, a := range addrs {
+		ips[i] = a.IP
+	}
+	return ips, nil
+}
+
+// partition splits ips into the family tried first and the one raced after
+// FallbackDelay. With no preference the first address decides the primary family.
+func (o DialerOptions) partition(ips []net.IP) (primary, fallback []net.IP) {
+	var v4, v6 []net.IP
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			v4 = append(v4, ip)
+		} else {
+			v6 = append(v6, ip)
+		}
+	}
+	switch o.IPPreference {
+	case IPv4Only:
+		return v4, nil
+	case IPv6Only:
+		return v6, nil
+	case PreferIPv4:
+		if len(v4) == 0 {
+			return v6, nil
+		}
+		return v4, v6
+	case PreferIPv6:
+		if len(v6) == 0 {
+			return v4, nil
+		}
+		return v6, v4
+	}
+	if len(ips) > 0 && ips[0].To4() == nil {
+		return v6, v4
+	}
+	if len(v4) == 0 {
+		return v6, nil
+	}
+	return v4, v6
+}
+
+func dialSerial(ctx context.Context, base *net.Dialer, network, port string, ips []net.IP) (net.Conn, error) {
+	var errs []error
+	for _, ip := range ips {
+		conn, err := base.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// dialParallel races the fallback family against the primary one once
+// FallbackDelay has passed or the primary family has failed (RFC 8305).
+func (o DialerOptions) dialParallel(ctx context.Context, base *net.Dialer, network, port string, primary, fallback []net.IP) (net.Conn, error) {
+	if len(fallback) == 0 || o.FallbackDelay < 0 {
+		conn, err := dialSerial(ctx, base, network, port, primary)
+		if err == nil || len(fallback) == 0 {
+			return conn, err
+		}
+		return dialSerial(ctx, base, network, port, fallback)
+	}
+	delay := o.FallbackDelay
+	if delay == 0 {
+		delay = defaultFallbackDelay
+	}
+
+	type result struct {
+		conn    net.Conn
+		err     error
+		primary bool
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan result, 2)
+	race := func(ips []net.IP, isPrimary bool) {
+		conn, err := dialSerial(ctx, base, network, port, ips)
+		results <- result{conn, err, isPrimary}
+	}
+
+	go race(primary, true)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var errs []error
+	pending, fallbackStarted := 1, false
+	for pending > 0 || !fallbackStarted {
+		select {
+		case <-timer.C:
+			if !fallbackStarted {
+				fallbackStarted = true
+				pending++
+				go race(fallback, false)
+			}
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				if pending > 0 {
+					// Close the loser once it finishes.
+					go func() {
+						if late := <-results; late.conn != nil {
+							late.conn.Close()
+						}
+					}()
+				}
+				return r.conn, nil
+			}
+			errs = append(errs, r.err)
+			if r.primary && !fallbackStarted {
+				fallbackStarted = true
+				pending++
+				go race(fallback, false)
+			}
+		}
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,90 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDialerOptionsPartition(t *testing.T) {
+	v4, v6 := net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1")
+	ips := []net.IP{v6, v4}
+
+	tests := []struct {
+		pref              string
+		primary, fallback int
+		primaryIsV4       bool
+	}{
+		{"", 1, 1, false},
+		{PreferIPv4, 1, 1, true},
+		{PreferIPv6, 1, 1, false},
+		{IPv4Only, 1, 0, true},
+		{IPv6Only, 1, 0, false},
+	}
+	for _, tt := range tests {
+		primary, fallback := DialerOptions{IPPreference: tt.pref}.partition(ips)
+		if len(primary) != tt.primary || len(fallback) != tt.fallback {
+			t.Fatalf("%q: got %v / %v", tt.pref, primary, fallback)
+		}
+		if (primary[0].To4() != nil) != tt.primaryIsV4 {
+			t.Fatalf("%q: primary = %v", tt.pref, primary)
+		}
+	}
+}
+
+func TestDialerOptionsHostsOverride(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	opts := DialerOptions{IPPreference: PreferIPv4, Hosts: map[string]string{"upstream.invalid": "127.0.0.1"}}
+	if err := opts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := opts.DialContext(&net.Dialer{})(context.Background(), "tcp", "Upstream.invalid:"+port)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	conn.Close()
+}
+
+func TestDialerOptionsValidate(t *testing.T) {
+	if err := (DialerOptions{IPPreference: "ipv5"}).Validate(); err == nil {
+		t.Fatal("expected error for unknown preference")
+	}
+	if err := (DialerOptions{Hosts: map[string]string{"a.example": "not-an-ip"}}).Validate(); err == nil {
+		t.Fatal("expected error for invalid host IP")
+	}
+}
+
+func TestDNSCacheServesFreshAndStaleEntries(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := NewDNSCache(time.Minute)
+	c.now = func() time.Time { return now }
+	c.resolver = &net.Resolver{PreferGo: true, Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return nil, errors.New("resolver down")
+	}}
+	cached := []net.IP{net.ParseIP("192.0.2.1")}
+	c.entries["upstream.example"] = dnsCacheEntry{ips: cached, expires: now.Add(time.Minute)}
+
+	if ips, err := c.LookupIP(context.Background(), "Upstream.example"); err != nil || !ips[0].Equal(cached[0]) {
+		t.Fatalf("fresh lookup = %v, %v", ips, err)
+	}
+	now = now.Add(2 * time.Minute)
+	if ips, err := c.LookupIP(context.Background(), "upstream.example"); err != nil || !ips[0].Equal(cached[0]) {
+		t.Fatalf("stale lookup = %v, %v", ips, err)
+	}
+	if _, err := c.LookupIP(context.Background(), "other.example"); err == nil {
+		t.Fatal("expected resolver error for uncached host")
+	}
+}
@@ -0,0 +1,72 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DNSCache caches resolver answers for a fixed TTL. Concurrent lookups of the
+// same host share one query, and a stale answer is served when a refresh fails
+// so resolver hiccups do not fail upstream requests.
+type DNSCache struct {
+	ttl      time.Duration
+	resolver *net.Resolver
+	group    singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]dnsCacheEntry
+	now     func() time.Time
+}
+
+type dnsCacheEntry struct {
+	ips     []net.IP
+	expires time.Time
+}
+
+// NewDNSCache creates a cache holding answers from net.DefaultResolver for ttl.
+func NewDNSCache(ttl time.Duration) *DNSCache {
+	return &DNSCache{
+		ttl:      ttl,
+		resolver: net.DefaultResolver,
+		entries:  make(map[string]dnsCacheEntry),
+		now:      time.Now,
+	}
+}
+
+// LookupIP returns the addresses of host, from cache while they are fresh.
+func (c *DNSCache) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	host = strings.ToLower(host)
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		return entry.ips, nil
+	}
+
+	v, err, _ := c.group.Do(host, func() (any, error) {
+		addrs, err := c.resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		ips := make([]net.IP, len(addrs))
+		for i, a := range addrs {
+			ips[i] = a.IP
+		}
+		c.mu.Lock()
+		c.entries[host] = dnsCacheEntry{ips: ips, expires: c.now().Add(c.ttl)}
+		c.mu.Unlock()
+		return ips, nil
+	})
+	if err != nil {
+		if ok {
+			return entry.ips, nil
+		}
+		return nil, err
+	}
+	return v.([]net.IP), nil
+}
@@ -0,0 +1,93 @@
+package transport
+
+import (
+	"cmp"
+	"crypto/tls"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultTLSSessionCacheSize is how many TLS sessions an upstream transport
+// keeps for resumption when its dialer entry sets no size.
+const DefaultTLSSessionCacheSize = 256
+
+// DefaultTLSPool names the handshake counters of transports without a dialer entry.
+const DefaultTLSPool = "default"
+
+// TLSSessionStats counts the TLS handshakes with one upstream host since
+// startup, and how many of them resumed a cached session.
+type TLSSessionStats struct {
+	Pool       string  `json:"pool"` // dialer entry ("gemini-cli", "*") or "default"
+	Host       string  `json:"host"`
+	Handshakes int64   `json:"handshakes"`
+	Resumed    int64   `json:"resumed"`
+	ReuseRate  float64 `json:"reuse_rate"`
+}
+
+type tlsSessionKey struct {
+	pool, host string
+}
+
+type tlsSessionCounter struct {
+	handshakes, resumed atomic.Int64
+}
+
+var tlsSessions sync.Map // tlsSessionKey -> *tlsSessionCounter
+
+// ConfigureTLSSessions sets the session cache of cfg and counts its
+// handshakes under pool. A positive cacheSize sets the number of cached
+// sessions, zero uses DefaultTLSSessionCacheSize and a negative value
+// disables resumption.
+//
+// Go's TLS client does not send 0-RTT early data, so resumption is the only
+// handshake saving available.
+func ConfigureTLSSessions(cfg *tls.Config, pool string, cacheSize int) {
+	switch {
+	case cacheSize < 0:
+		cfg.ClientSessionCache = nil
+	case cacheSize == 0:
+		cfg.ClientSessionCache = tls.NewLRUClientSessionCache(DefaultTLSSessionCacheSize)
+	default:
+		cfg.ClientSessionCache = tls.NewLRUClientSessionCache(cacheSize)
+	}
+	// VerifyConnection also runs for resumed handshakes, unlike
+	// VerifyPeerCertificate.
+	cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+		recordTLSHandshake(pool, cs.ServerName, cs.DidResume)
+		return nil
+	}
+}
+
+func recordTLSHandshake(pool, host string, resumed bool) {
+	key := tlsSessionKey{pool: pool, host: strings.ToLower(host)}
+	v, ok := tlsSessions.Load(key)
+	if !ok {
+		v, _ = tlsSessions.LoadOrStore(key, new(tlsSessionCounter))
+	}
+	c := v.(*tlsSessionCounter)
+	c.handshakes.Add(1)
+	if resumed {
+		c.resumed.Add(1)
+	}
+}
+
+// TLSSessions returns the handshake counters per pool and host, busiest
+// first.
+func TLSSessions() []TLSSessionStats {
+	out := []TLSSessionStats{}
+	tlsSessions.Range(func(k, v any) bool {
+		key, c := k.(tlsSessionKey), v.(*tlsSessionCounter)
+		s := TLSSessionStats{Pool: key.pool, Host: key.host, Handshakes: c.handshakes.Load(), Resumed: c.resumed.Load()}
+		if s.Handshakes > 0 {
+			s.ReuseRate = float64(s.Resumed) / float64(s.Handshakes)
+		}
+		out = append(out, s)
+		return true
+	})
+	slices.SortFunc(out, func(x, y TLSSessionStats) int {
+		return cmp.Or(cmp.Compare(y.Handshakes, x.Handshakes), strings.Compare(x.Pool, y.Pool), strings.Compare(x.Host, y.Host))
+	})
+	return out
+}
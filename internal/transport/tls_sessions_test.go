@@ -0,0 +1,44 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConfigureTLSSessions(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	stats := func(pool string) TLSSessionStats {
+		for _, s := range TLSSessions() {
+			if s.Pool == pool {
+				return s
+			}
+		}
+		return TLSSessionStats{}
+	}
+	for _, tt := range []struct {
+		pool        string
+		cacheSize   int
+		wantResumed int64
+	}{
+		{"resume", 0, 2},
+		{"no-resume", -1, 0},
+	} {
+		tr := srv.Client().Transport.(*http.Transport).Clone()
+		tr.TLSClientConfig.ServerName = "example.com" // in the httptest certificate
+		ConfigureTLSSessions(tr.TLSClientConfig, tt.pool, tt.cacheSize)
+		for range 3 {
+			resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			tr.CloseIdleConnections()
+		}
+		if s := stats(tt.pool); s.Handshakes != 3 || s.Resumed != tt.wantResumed || s.Host != "example.com" {
+			t.Errorf("%s: stats = %+v, want 3 handshakes, %d resumed", tt.pool, s, tt.wantResumed)
+		}
+	}
+}
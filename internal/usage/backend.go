@@ -37,9 +37,41 @@ type Backend interface {
 	// QueryModelStats returns per-model statistics since the given time.
 	QueryModelStats(ctx context.Context, since time.Time) ([]ModelStats, error)
 
+	// QueryDailyProviderStats returns successful usage per UTC day and provider since the given time.
+	QueryDailyProviderStats(ctx context.Context, since time.Time) ([]DailyProviderStats, error)
+
+	// SaveProviderReports upserts provider-reported daily usage, keyed by day and provider.
+	SaveProviderReports(ctx context.Context, reports []ProviderUsageReport) error
+
+	// QueryProviderReports returns provider-reported usage for days on or after since.
+	QueryProviderReports(ctx context.Context, since time.Time) ([]ProviderUsageReport, error)
+
+	// QueryUsage aggregates records over arbitrary time buckets, filters and metrics.
+	QueryUsage(ctx context.Context, q UsageQuery) ([]UsageQueryRow, error)
+
+	// QueryLatencyHistograms returns latency and TTFT histograms per provider
+	// and model for the hours overlapping [from, to).
+	QueryLatencyHistograms(ctx context.Context, from, to time.Time) ([]LatencyHistogram, error)
+
+	// QuerySizeHistograms returns request and response size histograms per
+	// provider for the hours overlapping [from, to).
+	QuerySizeHistograms(ctx context.Context, from, to time.Time) ([]SizeHistogram, error)
+
 	// Cleanup removes records older than the given time.
 	Cleanup(ctx context.Context, before time.Time) (int64, error)
 
+	// SaveTranscript stores a transcript, replacing one with the same ID.
+	SaveTranscript(ctx context.Context, t Transcript) error
+
+	// QueryTranscripts returns matching transcripts, newest first.
+	QueryTranscripts(ctx context.Context, q TranscriptQuery) ([]Transcript, error)
+
+	// GetTranscript returns the transcript with the given ID, or nil.
+	GetTranscript(ctx context.Context, id string) (*Transcript, error)
+
+	// CleanupTranscripts removes transcripts older than the given time.
+	CleanupTranscripts(ctx context.Context, before time.Time) (int64, error)
+
 	// Start begins background workers (write loop, cleanup loop).
 	Start() error
 
@@ -60,6 +92,33 @@ type BackendConfig struct {
 
 	// RetentionDays is how many days of records to keep.
 	RetentionDays int
+
+	// TranscriptRetentionDays is how many days of transcripts to keep.
+	// Default: RetentionDays.
+	TranscriptRetentionDays int
+
+	// VacuumInterval, AnalyzeInterval and CheckpointInterval schedule SQLite
+	// maintenance. Zero disables each task.
+	VacuumInterval     time.Duration
+	AnalyzeInterval    time.Duration
+	CheckpointInterval time.Duration
+
+	// WALAutocheckpoint and JournalSizeLimit tune the SQLite WAL. Zero keeps
+	// the SQLite defaults.
+	WALAutocheckpoint int
+	JournalSizeLimit  int64
+
+	// Partition splits SQLite usage records into per-period database files.
+	// Empty keeps them in the main database; SQLitePartitionMonth writes one
+	// file per month.
+	Partition string
+}
+
+// Backuper is implemented by backends that can copy their database while
+// it is in use.
+type Backuper interface {
+	// Backup writes a consistent copy of the database to path.
+	Backup(ctx context.Context, path string) error
 }
 
 // NewBackend creates the appropriate backend based on DSN configuration.
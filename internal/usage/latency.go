@@ -0,0 +1,159 @@
+package usage
+
+import (
+	"sort"
+	"time"
+)
+
+// Latency histogram kinds.
+const (
+	LatencyKindTotal = "latency" // request start to last byte
+	LatencyKindTTFT  = "ttft"    // request start to first streamed chunk
+)
+
+// LatencyBoundsMs are the upper bounds of the latency histogram buckets in
+// milliseconds. A final bucket holds everything above the last bound.
+var LatencyBoundsMs = []int64{
+	50, 100, 250, 500, 750, 1000, 1500, 2000, 3000, 5000,
+	7500, 10000, 15000, 20000, 30000, 60000, 120000, 300000,
+}
+
+// latencyBucket returns the index of the bucket holding ms.
+func latencyBucket(ms int64) int {
+	return sort.Search(len(LatencyBoundsMs), func(i int) bool { return ms <= LatencyBoundsMs[i] })
+}
+
+// LatencyHistogram is the latency distribution of one provider, model and kind.
+type LatencyHistogram struct {
+	Provider string
+	Model    string
+	Kind     string
+	// Counts has one entry per bucket in LatencyBoundsMs plus the overflow bucket.
+	Counts []int64
+	SumMs  int64
+}
+
+// Total returns the number of samples.
+func (h *LatencyHistogram) Total() int64 {
+	var n int64
+	for _, c := range h.Counts {
+		n += c
+	}
+	return n
+}
+
+// Quantile estimates the q-quantile (0..1) in milliseconds by interpolating
+// within the bucket it falls in. Samples in the overflow bucket are reported
+// as the last bound.
+func (h *LatencyHistogram) Quantile(q float64) float64 {
+	return bucketQuantile(h.Counts, LatencyBoundsMs, q)
+}
+
+// bucketQuantile estimates the q-quantile of a histogram with one count per
+// bound plus an overflow bucket, as described for LatencyHistogram.Quantile.
+func bucketQuantile(counts, bounds []int64, q float64) float64 {
+	var total int64
+	for _, c := range counts {
+		total += c
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := q * float64(total)
+	var seen float64
+	for i, c := range counts {
+		if c == 0 {
+			continue
+		}
+		if seen+float64(c) >= rank {
+			if i == len(bounds) {
+				return float64(bounds[i-1])
+			}
+			lower := 0.0
+			if i > 0 {
+				lower = float64(bounds[i-1])
+			}
+			upper := float64(bounds[i])
+			return lower + (upper-lower)*(rank-seen)/float64(c)
+		}
+		seen += float64(c)
+	}
+	return float64(bounds[len(bounds)-1])
+}
+
+// latencyKey identifies a persisted histogram bucket. Histograms are kept
+// per hour, so windows are resolved to whole hours.
+type latencyKey struct {
+	hour     int64
+	provider string
+	model    string
+	kind     string
+	bucket   int
+}
+
+type latencyDelta struct {
+	samples int64
+	sumMs   int64
+}
+
+// latencyDeltas aggregates the latencies of successful records by hour,
+// provider, model, kind and bucket.
+func latencyDeltas(records []UsageRecord) map[latencyKey]latencyDelta {
+	out := make(map[latencyKey]latencyDelta)
+	add := func(r UsageRecord, kind string, ms int64) {
+		if ms <= 0 {
+			return
+		}
+		k := latencyKey{
+			hour:     r.RequestedAt.Truncate(time.Hour).Unix(),
+			provider: r.Provider,
+			model:    r.Model,
+			kind:     kind,
+			bucket:   latencyBucket(ms),
+		}
+		d := out[k]
+		d.samples++
+		d.sumMs += ms
+		out[k] = d
+	}
+	for _, r := range records {
+		if r.Failed {
+			continue
+		}
+		add(r, LatencyKindTotal, r.LatencyMs)
+		add(r, LatencyKindTTFT, r.TTFTMs)
+	}
+	return out
+}
+
+// latencyHistogramSet merges bucket rows into histograms.
+type latencyHistogramSet struct {
+	order []*LatencyHistogram
+	index map[[3]string]*LatencyHistogram
+}
+
+func (s *latencyHistogramSet) add(provider, model, kind string, bucket int, samples, sumMs int64) {
+	if bucket < 0 || bucket > len(LatencyBoundsMs) {
+		return
+	}
+	if s.index == nil {
+		s.index = make(map[[3]string]*LatencyHistogram)
+	}
+	key := [3]string{provider, model, kind}
+	h := s.index[key]
+	if h == nil {
+		h = &LatencyHistogram{Provider: provider, Model: model, Kind: kind, Counts: make([]int64, len(LatencyBoundsMs)+1)}
+		s.index[key] = h
+		s.order = append(s.order, h)
+	}
+	h.Counts[bucket] += samples
+	h.SumMs += sumMs
+}
+
+func (s *latencyHistogramSet) histograms() []LatencyHistogram {
+	out := make([]LatencyHistogram, len(s.order))
+	for i, h := range s.order {
+		out[i] = *h
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package usage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteLatencyHistograms(t *testing.T) {
+	b, err := NewSQLiteBackend(filepath.Join(t.TempDir(), "usage.db"), BackendConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = b.Stop() })
+	ctx := context.Background()
+
+	now := time.Now()
+	var records []UsageRecord
+	for i := int64(1); i <= 100; i++ {
+		records = append(records, UsageRecord{Provider: "claude", Model: "sonnet", RequestedAt: now, LatencyMs: i * 100, TTFTMs: 200})
+	}
+	records = append(records, UsageRecord{Provider: "claude", Model: "sonnet", RequestedAt: now, LatencyMs: 999999, Failed: true})
+	// Split across two batches to exercise the upsert.
+	if err := b.writeBatch(ctx, records[:40]); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.writeBatch(ctx, records[40:]); err != nil {
+		t.Fatal(err)
+	}
+
+	hists, err := b.QueryLatencyHistograms(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hists) != 2 {
+		t.Fatalf("got %d histograms, want 2", len(hists))
+	}
+	for _, h := range hists {
+		switch h.Kind {
+		case LatencyKindTotal:
+			if h.Total() != 100 || h.SumMs != 505000 {
+				t.Errorf("latency total=%d sum=%d", h.Total(), h.SumMs)
+			}
+			// 95 of 100 samples are at or below 9500ms; the bucket is 7500-10000.
+			if p95 := h.Quantile(0.95); p95 < 7500 || p95 > 10000 {
+				t.Errorf("p95 = %v", p95)
+			}
+		case LatencyKindTTFT:
+			if p99 := h.Quantile(0.99); p99 <= 100 || p99 > 250 {
+				t.Errorf("ttft p99 = %v", p99)
+			}
+		}
+	}
+
+	if _, err := b.Cleanup(ctx, now.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if hists, _ := b.QueryLatencyHistograms(ctx, now.Add(-time.Hour), now.Add(time.Hour)); len(hists) != 0 {
+		t.Errorf("histograms survived cleanup: %d", len(hists))
+	}
+}
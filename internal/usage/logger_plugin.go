@@ -91,6 +91,12 @@ func (p *LoggerPlugin) HandleUsage(ctx context.Context, record Record) {
 			CacheCreationInputTokens: tokens.CacheCreationInputTokens,
 			CacheReadInputTokens:     tokens.CacheReadInputTokens,
 			ToolUsePromptTokens:      tokens.ToolUsePromptTokens,
+			RoutePolicy:              record.RoutePolicy,
+			EstimatedSavings:         record.EstimatedSavings,
+			LatencyMs:                record.Latency.Milliseconds(),
+			TTFTMs:                   record.TTFT.Milliseconds(),
+			RequestBytes:             record.RequestBytes,
+			ResponseBytes:            record.ResponseBytes,
 		})
 	}
 }
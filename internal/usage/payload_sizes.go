@@ -0,0 +1,315 @@
+package usage
+
+import (
+	"context"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// Payload size histogram kinds.
+const (
+	SizeKindRequest  = "request"  // translated request body sent upstream
+	SizeKindResponse = "response" // upstream response body
+)
+
+// SizeBoundsBytes are the upper bounds of the payload size histogram
+// buckets in bytes. A final bucket holds everything above the last bound.
+var SizeBoundsBytes = []int64{
+	1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 512 << 10,
+	1 << 20, 2 << 20, 4 << 20, 8 << 20, 16 << 20, 20 << 20, 32 << 20, 64 << 20, 100 << 20,
+}
+
+// SizeHistogram is the payload size distribution of one provider and kind.
+type SizeHistogram struct {
+	Provider string
+	Kind     string
+	// Counts has one entry per bucket in SizeBoundsBytes plus the overflow bucket.
+	Counts   []int64
+	SumBytes int64
+}
+
+// Total returns the number of samples.
+func (h *SizeHistogram) Total() int64 {
+	var n int64
+	for _, c := range h.Counts {
+		n += c
+	}
+	return n
+}
+
+// Quantile estimates the q-quantile (0..1) in bytes.
+func (h *SizeHistogram) Quantile(q float64) float64 {
+	return bucketQuantile(h.Counts, SizeBoundsBytes, q)
+}
+
+func sizeBucket(n int64) int {
+	return sort.Search(len(SizeBoundsBytes), func(i int) bool { return n <= SizeBoundsBytes[i] })
+}
+
+// sizeKey identifies a persisted size histogram bucket, per hour like
+// latencyKey.
+type sizeKey struct {
+	hour     int64
+	provider string
+	kind     string
+	bucket   int
+}
+
+type sizeDelta struct {
+	samples  int64
+	sumBytes int64
+}
+
+// sizeDeltas aggregates the payload sizes of records by hour, provider,
+// kind and bucket. Failed requests are included: requests rejected for
+// their size are the ones worth seeing.
+func sizeDeltas(records []UsageRecord) map[sizeKey]sizeDelta {
+	out := make(map[sizeKey]sizeDelta)
+	add := func(r UsageRecord, kind string, n int64) {
+		if n <= 0 {
+			return
+		}
+		k := sizeKey{
+			hour:     r.RequestedAt.Truncate(time.Hour).Unix(),
+			provider: r.Provider,
+			kind:     kind,
+			bucket:   sizeBucket(n),
+		}
+		d := out[k]
+		d.samples++
+		d.sumBytes += n
+		out[k] = d
+	}
+	for _, r := range records {
+		add(r, SizeKindRequest, r.RequestBytes)
+		add(r, SizeKindResponse, r.ResponseBytes)
+	}
+	return out
+}
+
+// sizeHistogramSet merges bucket rows into histograms.
+type sizeHistogramSet struct {
+	order []*SizeHistogram
+	index map[[2]string]*SizeHistogram
+}
+
+func (s *sizeHistogramSet) add(provider, kind string, bucket int, samples, sumBytes int64) {
+	if bucket < 0 || bucket > len(SizeBoundsBytes) {
+		return
+	}
+	if s.index == nil {
+		s.index = make(map[[2]string]*SizeHistogram)
+	}
+	key := [2]string{provider, kind}
+	h := s.index[key]
+	if h == nil {
+		h = &SizeHistogram{Provider: provider, Kind: kind, Counts: make([]int64, len(SizeBoundsBytes)+1)}
+		s.index[key] = h
+		s.order = append(s.order, h)
+	}
+	h.Counts[bucket] += samples
+	h.SumBytes += sumBytes
+}
+
+func (s *sizeHistogramSet) histograms() []SizeHistogram {
+	out := make([]SizeHistogram, len(s.order))
+	for i, h := range s.order {
+		out[i] = *h
+	}
+	return out
+}
+
+const (
+	defaultPayloadAlertPercentile = 95.0
+	defaultPayloadAlertWindow     = 15 * time.Minute
+	defaultPayloadAlertMinSamples = 20
+	maxPayloadSamples             = 512
+)
+
+// DefaultProviderRequestLimits are the documented request size limits of
+// provider APIs in bytes, keyed by provider name.
+var DefaultProviderRequestLimits = map[string]int64{
+	"claude": 32 << 20, // Messages API
+	"gemini": 20 << 20, // generateContent
+}
+
+// PayloadAlertPolicy configures the PayloadMonitor.
+type PayloadAlertPolicy struct {
+	// Limits maps provider names to their request size limit in bytes.
+	Limits map[string]int64
+	// Percentile of the request sizes compared against the limit (default 95).
+	Percentile float64
+	// Window is the sliding window of samples considered (default 15m). A
+	// provider is alerted on at most once per window.
+	Window time.Duration
+	// MinSamples is the minimum number of samples before alerting (default 20).
+	MinSamples int
+}
+
+// PayloadAlertStatus is the windowed request size of a provider with a limit.
+type PayloadAlertStatus struct {
+	Provider   string  `json:"provider"`
+	LimitBytes int64   `json:"limit_bytes"`
+	Percentile float64 `json:"percentile"`
+	// ObservedBytes is the request size at Percentile over the window.
+	ObservedBytes int64 `json:"observed_bytes"`
+	Samples       int   `json:"samples"`
+	// Exceeded reports whether ObservedBytes is above the limit.
+	Exceeded bool `json:"exceeded"`
+}
+
+type payloadSample struct {
+	at    time.Time
+	bytes int64
+}
+
+type payloadSeries struct {
+	samples   []payloadSample
+	alertedAt time.Time
+}
+
+// PayloadMonitor is a usage plugin that tracks the translated request sizes
+// of providers with a size limit and logs a warning when their windowed
+// percentile exceeds it, before clients start reporting 413 responses.
+type PayloadMonitor struct {
+	mu     sync.Mutex
+	policy PayloadAlertPolicy
+	series map[string]*payloadSeries
+	now    func() time.Time
+}
+
+var defaultPayloadMonitor = NewPayloadMonitor()
+
+// GetPayloadMonitor returns the shared payload monitor.
+func GetPayloadMonitor() *PayloadMonitor { return defaultPayloadMonitor }
+
+// NewPayloadMonitor creates a monitor with the default limits.
+func NewPayloadMonitor() *PayloadMonitor {
+	m := &PayloadMonitor{series: make(map[string]*payloadSeries), now: time.Now}
+	m.SetPolicy(PayloadAlertPolicy{})
+	return m
+}
+
+// SetPolicy replaces the alert policy. Limits add to or override
+// DefaultProviderRequestLimits; a limit of 0 or less removes one.
+func (m *PayloadMonitor) SetPolicy(p PayloadAlertPolicy) {
+	if m == nil {
+		return
+	}
+	limits := maps.Clone(DefaultProviderRequestLimits)
+	for provider, limit := range p.Limits {
+		if limit <= 0 {
+			delete(limits, provider)
+			continue
+		}
+		limits[provider] = limit
+	}
+	p.Limits = limits
+	if p.Percentile <= 0 || p.Percentile > 100 {
+		p.Percentile = defaultPayloadAlertPercentile
+	}
+	if p.Window <= 0 {
+		p.Window = defaultPayloadAlertWindow
+	}
+	if p.MinSamples <= 0 {
+		p.MinSamples = defaultPayloadAlertMinSamples
+	}
+	m.mu.Lock()
+	m.policy = p
+	m.mu.Unlock()
+}
+
+// HandleUsage implements Plugin.
+func (m *PayloadMonitor) HandleUsage(_ context.Context, record Record) {
+	if m == nil || record.RequestBytes <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	limit, ok := m.policy.Limits[record.Provider]
+	if !ok {
+		return
+	}
+	now := m.now()
+	s := m.series[record.Provider]
+	if s == nil {
+		s = &payloadSeries{}
+		m.series[record.Provider] = s
+	}
+	s.samples = append(s.samples, payloadSample{at: now, bytes: record.RequestBytes})
+	m.prune(s, now)
+
+	st := m.statusLocked(record.Provider, limit, s)
+	if !st.Exceeded || (!s.alertedAt.IsZero() && now.Sub(s.alertedAt) < m.policy.Window) {
+		return
+	}
+	s.alertedAt = now
+	log.Warnf("payload size: p%g of %s requests over the last %s is %d bytes, above the provider limit of %d bytes (%d samples)",
+		st.Percentile, record.Provider, m.policy.Window, st.ObservedBytes, limit, st.Samples)
+}
+
+// Status returns the windowed request sizes of the providers with a limit
+// and samples, ordered by provider.
+func (m *PayloadMonitor) Status() []PayloadAlertStatus {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	var out []PayloadAlertStatus
+	for _, provider := range slices.Sorted(maps.Keys(m.series)) {
+		limit, ok := m.policy.Limits[provider]
+		if !ok {
+			continue
+		}
+		s := m.series[provider]
+		m.prune(s, now)
+		if len(s.samples) > 0 {
+			out = append(out, m.statusLocked(provider, limit, s))
+		}
+	}
+	return out
+}
+
+// prune drops samples outside the window and beyond maxPayloadSamples.
+// Caller must hold m.mu.
+func (m *PayloadMonitor) prune(s *payloadSeries, now time.Time) {
+	cutoff := now.Add(-m.policy.Window)
+	drop := 0
+	for drop < len(s.samples) && s.samples[drop].at.Before(cutoff) {
+		drop++
+	}
+	drop = max(drop, len(s.samples)-maxPayloadSamples)
+	if drop > 0 {
+		s.samples = append(s.samples[:0], s.samples[drop:]...)
+	}
+}
+
+// statusLocked evaluates s against limit. Caller must hold m.mu.
+func (m *PayloadMonitor) statusLocked(provider string, limit int64, s *payloadSeries) PayloadAlertStatus {
+	st := PayloadAlertStatus{
+		Provider:   provider,
+		LimitBytes: limit,
+		Percentile: m.policy.Percentile,
+		Samples:    len(s.samples),
+	}
+	if st.Samples == 0 {
+		return st
+	}
+	sizes := make([]int64, st.Samples)
+	for i, sample := range s.samples {
+		sizes[i] = sample.bytes
+	}
+	slices.Sort(sizes)
+	rank := int(math.Ceil(st.Percentile/100*float64(len(sizes)))) - 1
+	st.ObservedBytes = sizes[max(rank, 0)]
+	st.Exceeded = st.Samples >= m.policy.MinSamples && st.ObservedBytes > limit
+	return st
+}
@@ -0,0 +1,109 @@
+package usage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteSizeHistograms(t *testing.T) {
+	b, err := NewSQLiteBackend(filepath.Join(t.TempDir(), "usage.db"), BackendConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = b.Stop() })
+	ctx := context.Background()
+
+	now := time.Now()
+	var records []UsageRecord
+	for i := int64(1); i <= 100; i++ {
+		records = append(records, UsageRecord{Provider: "claude", Model: "sonnet", RequestedAt: now, RequestBytes: i << 10, ResponseBytes: 2 << 10})
+	}
+	// Rejected for its size: counted as a request, no response.
+	records = append(records, UsageRecord{Provider: "claude", Model: "sonnet", RequestedAt: now, RequestBytes: 40 << 20, Failed: true})
+	if err := b.writeBatch(ctx, records[:30]); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.writeBatch(ctx, records[30:]); err != nil {
+		t.Fatal(err)
+	}
+
+	hists, err := b.QuerySizeHistograms(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hists) != 2 {
+		t.Fatalf("got %d histograms, want 2", len(hists))
+	}
+	for _, h := range hists {
+		switch h.Kind {
+		case SizeKindRequest:
+			if h.Total() != 101 || h.Counts[sizeBucket(40<<20)] != 1 {
+				t.Errorf("request total=%d counts=%v", h.Total(), h.Counts)
+			}
+			// 95 of 101 samples are at or below 95 KiB; the bucket is 64-256 KiB.
+			if p95 := h.Quantile(0.95); p95 < 64<<10 || p95 > 256<<10 {
+				t.Errorf("request p95 = %v", p95)
+			}
+		case SizeKindResponse:
+			if h.Total() != 100 || h.SumBytes != 200<<10 {
+				t.Errorf("response total=%d sum=%d", h.Total(), h.SumBytes)
+			}
+		}
+	}
+
+	if _, err := b.Cleanup(ctx, now.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if hists, _ := b.QuerySizeHistograms(ctx, now.Add(-time.Hour), now.Add(time.Hour)); len(hists) != 0 {
+		t.Errorf("histograms survived cleanup: %d", len(hists))
+	}
+}
+
+func TestPayloadMonitor(t *testing.T) {
+	m := NewPayloadMonitor()
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	m.SetPolicy(PayloadAlertPolicy{Limits: map[string]int64{"openai": 1000, "gemini": 0}, MinSamples: 10, Window: time.Minute})
+
+	record := func(provider string, n int64) {
+		m.HandleUsage(context.Background(), Record{Provider: provider, RequestBytes: n})
+	}
+	openai := func() PayloadAlertStatus {
+		for _, st := range m.Status() {
+			if st.Provider == "openai" {
+				return st
+			}
+		}
+		return PayloadAlertStatus{}
+	}
+	for i := 0; i < 9; i++ {
+		record("openai", 2000)
+		record("gemini", 50<<20)
+		record("claude", 100)
+	}
+	status := m.Status()
+	if len(status) != 2 || status[0].Provider != "claude" || status[1].Provider != "openai" {
+		t.Fatalf("status = %+v", status)
+	}
+	if status[1].Exceeded {
+		t.Error("exceeded before min-samples")
+	}
+	record("openai", 2000)
+	if st := openai(); !st.Exceeded || st.ObservedBytes != 2000 || st.Samples != 10 {
+		t.Errorf("openai status = %+v", st)
+	}
+
+	now = now.Add(2 * time.Minute)
+	for i := 0; i < 20; i++ {
+		record("openai", 500)
+	}
+	record("openai", 5000)
+	if st := openai(); st.Exceeded || st.Samples != 21 || st.ObservedBytes != 500 {
+		t.Errorf("after window: %+v", st)
+	}
+	if len(m.Status()) != 1 {
+		t.Errorf("expired providers reported: %+v", m.Status())
+	}
+}
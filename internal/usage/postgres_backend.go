@@ -2,7 +2,9 @@ package usage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -23,6 +25,8 @@ type PostgresBackend struct {
 	batchSize     int
 	flushInterval time.Duration
 	retentionDays int
+
+	transcriptRetentionDays int
 }
 
 // Postgres backend constants
@@ -74,6 +78,10 @@ func NewPostgresBackend(dsn string, cfg BackendConfig) (*PostgresBackend, error)
 	if retentionDays <= 0 {
 		retentionDays = pgDefaultRetentionDays
 	}
+	transcriptRetentionDays := cfg.TranscriptRetentionDays
+	if transcriptRetentionDays <= 0 {
+		transcriptRetentionDays = retentionDays
+	}
 
 	return &PostgresBackend{
 		pool:          pool,
@@ -84,6 +92,8 @@ func NewPostgresBackend(dsn string, cfg BackendConfig) (*PostgresBackend, error)
 		flushInterval: flushInterval,
 		retentionDays: retentionDays,
 		cleanupTicker: time.NewTicker(24 * time.Hour),
+
+		transcriptRetentionDays: transcriptRetentionDays,
 	}, nil
 }
 
@@ -109,12 +119,64 @@ func ensurePostgresSchema(ctx context.Context, pool *pgxpool.Pool) error {
 		cache_creation_input_tokens BIGINT NOT NULL DEFAULT 0,
 		cache_read_input_tokens BIGINT NOT NULL DEFAULT 0,
 		tool_use_prompt_tokens BIGINT NOT NULL DEFAULT 0,
+		route_policy TEXT NOT NULL DEFAULT '',
+		estimated_savings DOUBLE PRECISION NOT NULL DEFAULT 0,
 		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 	);
 
+	ALTER TABLE usage_records ADD COLUMN IF NOT EXISTS route_policy TEXT NOT NULL DEFAULT '';
+	ALTER TABLE usage_records ADD COLUMN IF NOT EXISTS estimated_savings DOUBLE PRECISION NOT NULL DEFAULT 0;
+
 	CREATE INDEX IF NOT EXISTS idx_usage_requested_at ON usage_records(requested_at);
 	CREATE INDEX IF NOT EXISTS idx_usage_api_key ON usage_records(api_key);
 	CREATE INDEX IF NOT EXISTS idx_usage_provider_model ON usage_records(provider, model);
+
+	CREATE TABLE IF NOT EXISTS provider_usage_reports (
+		day DATE NOT NULL,
+		provider TEXT NOT NULL,
+		requests BIGINT NOT NULL DEFAULT 0,
+		input_tokens BIGINT NOT NULL DEFAULT 0,
+		output_tokens BIGINT NOT NULL DEFAULT 0,
+		cached_tokens BIGINT NOT NULL DEFAULT 0,
+		fetched_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (day, provider)
+	);
+
+	CREATE TABLE IF NOT EXISTS latency_histograms (
+		hour_start BIGINT NOT NULL,
+		provider TEXT NOT NULL,
+		model TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		bucket INTEGER NOT NULL,
+		samples BIGINT NOT NULL DEFAULT 0,
+		sum_ms BIGINT NOT NULL DEFAULT 0,
+		PRIMARY KEY (hour_start, provider, model, kind, bucket)
+	);
+
+	CREATE TABLE IF NOT EXISTS size_histograms (
+		hour_start BIGINT NOT NULL,
+		provider TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		bucket INTEGER NOT NULL,
+		samples BIGINT NOT NULL DEFAULT 0,
+		sum_bytes BIGINT NOT NULL DEFAULT 0,
+		PRIMARY KEY (hour_start, provider, kind, bucket)
+	);
+
+	CREATE TABLE IF NOT EXISTS transcripts (
+		id TEXT PRIMARY KEY,
+		requested_at TIMESTAMPTZ NOT NULL,
+		api_key TEXT NOT NULL DEFAULT '',
+		model TEXT NOT NULL DEFAULT '',
+		format TEXT NOT NULL DEFAULT '',
+		label TEXT NOT NULL DEFAULT '',
+		stream BOOLEAN NOT NULL DEFAULT FALSE,
+		request TEXT NOT NULL,
+		response TEXT NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_transcripts_requested_at ON transcripts(requested_at);
+	CREATE INDEX IF NOT EXISTS idx_transcripts_label ON transcripts(label);
 	`
 
 	_, err := pool.Exec(ctx, schema)
@@ -205,13 +267,14 @@ func (b *PostgresBackend) QueryGlobalStats(ctx context.Context, since time.Time)
 			COUNT(*),
 			SUM(CASE WHEN failed = false THEN 1 ELSE 0 END),
 			SUM(CASE WHEN failed = true THEN 1 ELSE 0 END),
-			COALESCE(SUM(total_tokens), 0)
+			COALESCE(SUM(total_tokens), 0),
+			COALESCE(SUM(estimated_savings), 0)
 		FROM usage_records
 		WHERE requested_at >= $1
 	`, since)
 
 	var stats AggregatedStats
-	if err := row.Scan(&stats.TotalRequests, &stats.SuccessCount, &stats.FailureCount, &stats.TotalTokens); err != nil {
+	if err := row.Scan(&stats.TotalRequests, &stats.SuccessCount, &stats.FailureCount, &stats.TotalTokens, &stats.EstimatedSavings); err != nil {
 		return nil, fmt.Errorf("failed to query global stats: %w", err)
 	}
 	return &stats, nil
@@ -386,6 +449,160 @@ func (b *PostgresBackend) QueryModelStats(ctx context.Context, since time.Time)
 	return results, rows.Err()
 }
 
+// QueryDailyProviderStats returns successful usage per UTC day and provider since the given time.
+func (b *PostgresBackend) QueryDailyProviderStats(ctx context.Context, since time.Time) ([]DailyProviderStats, error) {
+	rows, err := b.pool.Query(ctx, `
+		SELECT
+			TO_CHAR(requested_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') as day,
+			provider,
+			COUNT(*) as requests,
+			COALESCE(SUM(input_tokens), 0) as input_tokens,
+			COALESCE(SUM(output_tokens), 0) as output_tokens,
+			COALESCE(SUM(GREATEST(cached_tokens, cache_read_input_tokens)), 0) as cached_tokens
+		FROM usage_records
+		WHERE requested_at >= $1 AND failed = FALSE
+		GROUP BY day, provider
+		ORDER BY day, provider
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily provider stats: %w", err)
+	}
+	defer rows.Close()
+
+	var results []DailyProviderStats
+	for rows.Next() {
+		var d DailyProviderStats
+		if err := rows.Scan(&d.Day, &d.Provider, &d.Requests, &d.InputTokens, &d.OutputTokens, &d.CachedTokens); err != nil {
+			return nil, err
+		}
+		results = append(results, d)
+	}
+	return results, rows.Err()
+}
+
+// QueryUsage aggregates records over arbitrary time buckets. Buckets are
+// aligned to UTC.
+func (b *PostgresBackend) QueryUsage(ctx context.Context, q UsageQuery) ([]UsageQueryRow, error) {
+	query, args := postgresUsageDialect.build(q, q.From, q.To)
+	rows, err := b.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	defer rows.Close()
+
+	var results []UsageQueryRow
+	for rows.Next() {
+		row, err := scanUsageQueryRow(q, rows.Scan, func(sec int64) time.Time { return time.Unix(sec, 0).UTC() })
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, row)
+	}
+	return results, rows.Err()
+}
+
+// QueryLatencyHistograms returns latency histograms for the hours overlapping [from, to).
+func (b *PostgresBackend) QueryLatencyHistograms(ctx context.Context, from, to time.Time) ([]LatencyHistogram, error) {
+	rows, err := b.pool.Query(ctx, `
+		SELECT provider, model, kind, bucket, SUM(samples)::BIGINT, SUM(sum_ms)::BIGINT
+		FROM latency_histograms
+		WHERE hour_start >= $1 AND hour_start < $2
+		GROUP BY provider, model, kind, bucket
+		ORDER BY provider, model, kind, bucket
+	`, from.Truncate(time.Hour).Unix(), to.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latency histograms: %w", err)
+	}
+	defer rows.Close()
+
+	var set latencyHistogramSet
+	for rows.Next() {
+		var provider, model, kind string
+		var bucket int32
+		var samples, sumMs int64
+		if err := rows.Scan(&provider, &model, &kind, &bucket, &samples, &sumMs); err != nil {
+			return nil, err
+		}
+		set.add(provider, model, kind, int(bucket), samples, sumMs)
+	}
+	return set.histograms(), rows.Err()
+}
+
+// QuerySizeHistograms returns payload size histograms for the hours overlapping [from, to).
+func (b *PostgresBackend) QuerySizeHistograms(ctx context.Context, from, to time.Time) ([]SizeHistogram, error) {
+	rows, err := b.pool.Query(ctx, `
+		SELECT provider, kind, bucket, SUM(samples)::BIGINT, SUM(sum_bytes)::BIGINT
+		FROM size_histograms
+		WHERE hour_start >= $1 AND hour_start < $2
+		GROUP BY provider, kind, bucket
+		ORDER BY provider, kind, bucket
+	`, from.Truncate(time.Hour).Unix(), to.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query size histograms: %w", err)
+	}
+	defer rows.Close()
+
+	var set sizeHistogramSet
+	for rows.Next() {
+		var provider, kind string
+		var bucket int32
+		var samples, sumBytes int64
+		if err := rows.Scan(&provider, &kind, &bucket, &samples, &sumBytes); err != nil {
+			return nil, err
+		}
+		set.add(provider, kind, int(bucket), samples, sumBytes)
+	}
+	return set.histograms(), rows.Err()
+}
+
+// SaveProviderReports upserts provider-reported daily usage.
+func (b *PostgresBackend) SaveProviderReports(ctx context.Context, reports []ProviderUsageReport) error {
+	batch := &pgx.Batch{}
+	for _, r := range reports {
+		batch.Queue(`
+			INSERT INTO provider_usage_reports (day, provider, requests, input_tokens, output_tokens, cached_tokens, fetched_at)
+			VALUES ($1::DATE, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (day, provider) DO UPDATE SET
+				requests = EXCLUDED.requests,
+				input_tokens = EXCLUDED.input_tokens,
+				output_tokens = EXCLUDED.output_tokens,
+				cached_tokens = EXCLUDED.cached_tokens,
+				fetched_at = EXCLUDED.fetched_at
+		`, r.Day, r.Provider, r.Requests, r.InputTokens, r.OutputTokens, r.CachedTokens, r.FetchedAt)
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	if err := b.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to save provider reports: %w", err)
+	}
+	return nil
+}
+
+// QueryProviderReports returns provider-reported usage for days on or after since.
+func (b *PostgresBackend) QueryProviderReports(ctx context.Context, since time.Time) ([]ProviderUsageReport, error) {
+	rows, err := b.pool.Query(ctx, `
+		SELECT day::TEXT, provider, requests, input_tokens, output_tokens, cached_tokens, fetched_at
+		FROM provider_usage_reports
+		WHERE day >= $1::DATE
+		ORDER BY day, provider
+	`, since.UTC().Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query provider reports: %w", err)
+	}
+	defer rows.Close()
+
+	var results []ProviderUsageReport
+	for rows.Next() {
+		var r ProviderUsageReport
+		if err := rows.Scan(&r.Day, &r.Provider, &r.Requests, &r.InputTokens, &r.OutputTokens, &r.CachedTokens, &r.FetchedAt); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
 // Cleanup removes records older than the given time.
 func (b *PostgresBackend) Cleanup(ctx context.Context, before time.Time) (int64, error) {
 	result, err := b.pool.Exec(ctx, `
@@ -394,6 +611,79 @@ func (b *PostgresBackend) Cleanup(ctx context.Context, before time.Time) (int64,
 	if err != nil {
 		return 0, err
 	}
+	for _, table := range []string{"latency_histograms", "size_histograms"} {
+		if _, err := b.pool.Exec(ctx, `DELETE FROM `+table+` WHERE hour_start < $1`, before.Truncate(time.Hour).Unix()); err != nil {
+			return 0, err
+		}
+	}
+	return result.RowsAffected(), nil
+}
+
+// SaveTranscript stores a transcript, replacing one with the same ID.
+func (b *PostgresBackend) SaveTranscript(ctx context.Context, t Transcript) error {
+	_, err := b.pool.Exec(ctx, `
+		INSERT INTO transcripts (`+transcriptColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET
+			requested_at = excluded.requested_at,
+			api_key = excluded.api_key,
+			model = excluded.model,
+			format = excluded.format,
+			label = excluded.label,
+			stream = excluded.stream,
+			request = excluded.request,
+			response = excluded.response
+	`, t.ID, t.RequestedAt, t.APIKey, t.Model, t.Format, t.Label, t.Stream, string(t.Request), string(t.Response))
+	if err != nil {
+		return fmt.Errorf("failed to save transcript: %w", err)
+	}
+	return nil
+}
+
+// QueryTranscripts returns matching transcripts, newest first.
+func (b *PostgresBackend) QueryTranscripts(ctx context.Context, q TranscriptQuery) ([]Transcript, error) {
+	where, args := q.build(postgresUsageDialect)
+	rows, err := b.pool.Query(ctx, `
+		SELECT `+transcriptColumns+`
+		FROM transcripts
+		WHERE `+where+`
+		ORDER BY requested_at DESC
+		LIMIT `+strconv.Itoa(q.limit()), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transcripts: %w", err)
+	}
+	defer rows.Close()
+
+	var results []Transcript
+	for rows.Next() {
+		t, err := scanTranscript(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, t)
+	}
+	return results, rows.Err()
+}
+
+// GetTranscript returns the transcript with the given ID, or nil.
+func (b *PostgresBackend) GetTranscript(ctx context.Context, id string) (*Transcript, error) {
+	row := b.pool.QueryRow(ctx, `SELECT `+transcriptColumns+` FROM transcripts WHERE id = $1`, id)
+	t, err := scanTranscript(row.Scan)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transcript: %w", err)
+	}
+	return &t, nil
+}
+
+// CleanupTranscripts removes transcripts older than the given time.
+func (b *PostgresBackend) CleanupTranscripts(ctx context.Context, before time.Time) (int64, error) {
+	result, err := b.pool.Exec(ctx, `DELETE FROM transcripts WHERE requested_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
 	return result.RowsAffected(), nil
 }
 
@@ -453,7 +743,7 @@ func (b *PostgresBackend) writeBatch(ctx context.Context, records []UsageRecord)
 		"requested_at", "failed", "input_tokens", "output_tokens",
 		"reasoning_tokens", "cached_tokens", "total_tokens",
 		"audio_tokens", "cache_creation_input_tokens", "cache_read_input_tokens",
-		"tool_use_prompt_tokens",
+		"tool_use_prompt_tokens", "route_policy", "estimated_savings",
 	}
 
 	_, err := b.pool.CopyFrom(
@@ -480,6 +770,8 @@ func (b *PostgresBackend) writeBatch(ctx context.Context, records []UsageRecord)
 				r.CacheCreationInputTokens,
 				r.CacheReadInputTokens,
 				r.ToolUsePromptTokens,
+				r.RoutePolicy,
+				r.EstimatedSavings,
 			}, nil
 		}),
 	)
@@ -488,6 +780,31 @@ func (b *PostgresBackend) writeBatch(ctx context.Context, records []UsageRecord)
 		return fmt.Errorf("failed to copy records: %w", err)
 	}
 
+	batch := &pgx.Batch{}
+	for k, d := range latencyDeltas(records) {
+		batch.Queue(`
+			INSERT INTO latency_histograms (hour_start, provider, model, kind, bucket, samples, sum_ms)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (hour_start, provider, model, kind, bucket) DO UPDATE SET
+				samples = latency_histograms.samples + EXCLUDED.samples,
+				sum_ms = latency_histograms.sum_ms + EXCLUDED.sum_ms
+		`, k.hour, k.provider, k.model, k.kind, k.bucket, d.samples, d.sumMs)
+	}
+	for k, d := range sizeDeltas(records) {
+		batch.Queue(`
+			INSERT INTO size_histograms (hour_start, provider, kind, bucket, samples, sum_bytes)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (hour_start, provider, kind, bucket) DO UPDATE SET
+				samples = size_histograms.samples + EXCLUDED.samples,
+				sum_bytes = size_histograms.sum_bytes + EXCLUDED.sum_bytes
+		`, k.hour, k.provider, k.kind, k.bucket, d.samples, d.sumBytes)
+	}
+	if batch.Len() > 0 {
+		if err := b.pool.SendBatch(ctx, batch).Close(); err != nil {
+			return fmt.Errorf("failed to update histograms: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -507,6 +824,14 @@ func (b *PostgresBackend) cleanupLoop() {
 			} else if rowsDeleted > 0 {
 				log.Infof("Cleaned up %d usage records older than %d days", rowsDeleted, b.retentionDays)
 			}
+			ctx, cancel = context.WithTimeout(context.Background(), 1*time.Minute)
+			transcriptsDeleted, err := b.CleanupTranscripts(ctx, time.Now().AddDate(0, 0, -b.transcriptRetentionDays))
+			cancel()
+			if err != nil {
+				log.Errorf("Failed to cleanup old transcripts: %v", err)
+			} else if transcriptsDeleted > 0 {
+				log.Infof("Cleaned up %d transcripts older than %d days", transcriptsDeleted, b.transcriptRetentionDays)
+			}
 		case <-b.stopChan:
 			return
 		}
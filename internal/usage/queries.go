@@ -8,6 +8,8 @@ type AggregatedStats struct {
 	SuccessCount  int64 `json:"success_count"`
 	FailureCount  int64 `json:"failure_count"`
 	TotalTokens   int64 `json:"total_tokens"`
+	// EstimatedSavings is the USD saved by cost-based routing.
+	EstimatedSavings float64 `json:"estimated_savings"`
 }
 
 // DailyStats represents aggregated metrics for a single day.
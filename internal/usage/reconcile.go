@@ -0,0 +1,200 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// UsageTotals are the token and request counts compared during reconciliation.
+type UsageTotals struct {
+	Requests     int64 `json:"requests"`
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+	CachedTokens int64 `json:"cached_tokens"`
+}
+
+// DailyProviderStats is llm-mux's own successful usage of a provider on one day.
+type DailyProviderStats struct {
+	Day      string `json:"day"` // Format: "2006-01-02"
+	Provider string `json:"provider"`
+	UsageTotals
+}
+
+// ProviderUsageReport is the usage a provider's own usage API reports for one UTC day.
+type ProviderUsageReport struct {
+	Day      string `json:"day"` // Format: "2006-01-02"
+	Provider string `json:"provider"`
+	UsageTotals
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// UsageDrift compares local and provider-reported usage for one provider and day.
+type UsageDrift struct {
+	Day      string       `json:"day"`
+	Provider string       `json:"provider"`
+	Local    UsageTotals  `json:"local"`
+	Reported *UsageTotals `json:"reported,omitempty"`
+	// TokenDriftPercent is (local - reported) / reported over input plus output
+	// tokens. Negative values mean llm-mux recorded less than the provider billed.
+	TokenDriftPercent float64   `json:"token_drift_percent"`
+	FetchedAt         time.Time `json:"fetched_at,omitzero"`
+}
+
+// BuildDriftReport pairs local daily stats with provider reports. Only providers
+// that have at least one report are included, ordered by day then provider.
+func BuildDriftReport(local []DailyProviderStats, reported []ProviderUsageReport) []UsageDrift {
+	type key struct{ day, provider string }
+	rows := make(map[key]*UsageDrift)
+	reportedProviders := make(map[string]bool)
+	for _, r := range reported {
+		reportedProviders[r.Provider] = true
+		totals := r.UsageTotals
+		rows[key{r.Day, r.Provider}] = &UsageDrift{Day: r.Day, Provider: r.Provider, Reported: &totals, FetchedAt: r.FetchedAt}
+	}
+	for _, l := range local {
+		if !reportedProviders[l.Provider] {
+			continue
+		}
+		k := key{l.Day, l.Provider}
+		if rows[k] == nil {
+			rows[k] = &UsageDrift{Day: l.Day, Provider: l.Provider}
+		}
+		rows[k].Local = l.UsageTotals
+	}
+
+	report := make([]UsageDrift, 0, len(rows))
+	for _, d := range rows {
+		if d.Reported != nil {
+			if billed := d.Reported.InputTokens + d.Reported.OutputTokens; billed > 0 {
+				recorded := d.Local.InputTokens + d.Local.OutputTokens
+				d.TokenDriftPercent = float64(recorded-billed) / float64(billed) * 100
+			}
+		}
+		report = append(report, *d)
+	}
+	slices.SortFunc(report, func(a, b UsageDrift) int {
+		if a.Day != b.Day {
+			if a.Day < b.Day {
+				return -1
+			}
+			return 1
+		}
+		if a.Provider < b.Provider {
+			return -1
+		}
+		if a.Provider > b.Provider {
+			return 1
+		}
+		return 0
+	})
+	return report
+}
+
+// UsageReportSource fetches provider-reported usage from a provider's usage API.
+type UsageReportSource interface {
+	// Provider is the llm-mux provider name the reported usage is compared against.
+	Provider() string
+	// FetchDaily returns per-UTC-day totals for days in [from, to).
+	FetchDaily(ctx context.Context, from, to time.Time) ([]ProviderUsageReport, error)
+}
+
+// Reconciler periodically stores provider-reported usage next to the local
+// aggregates so drift can be reported.
+type Reconciler struct {
+	backend Backend
+
+	mu       sync.Mutex
+	sources  []UsageReportSource
+	lookback int
+	cancel   context.CancelFunc
+	now      func() time.Time
+}
+
+// NewReconciler creates an idle reconciler writing to backend.
+func NewReconciler(backend Backend) *Reconciler {
+	return &Reconciler{backend: backend, now: time.Now}
+}
+
+// Configure replaces the sources and restarts the background loop. The loop
+// runs immediately and then every interval, fetching the last lookbackDays
+// days including today. No sources stops the loop.
+func (r *Reconciler) Configure(sources []UsageReportSource, interval time.Duration, lookbackDays int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+	r.sources = slices.Clone(sources)
+	r.lookback = max(lookbackDays, 1)
+	if len(sources) == 0 || r.backend == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	go r.loop(ctx, interval)
+}
+
+// Stop stops the background loop.
+func (r *Reconciler) Stop() {
+	if r == nil {
+		return
+	}
+	r.Configure(nil, 0, 0)
+}
+
+func (r *Reconciler) loop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := r.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Warnf("usage reconciliation: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce fetches every source and stores the reports. A failing source does
+// not prevent the others from being stored.
+func (r *Reconciler) RunOnce(ctx context.Context) error {
+	r.mu.Lock()
+	sources, lookback := r.sources, r.lookback
+	r.mu.Unlock()
+
+	now := r.now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -lookback)
+
+	var errs []error
+	for _, src := range sources {
+		reports, err := src.FetchDaily(ctx, from, to)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", src.Provider(), err))
+			continue
+		}
+		for i := range reports {
+			reports[i].Provider = src.Provider()
+			reports[i].FetchedAt = now
+		}
+		if err := r.backend.SaveProviderReports(ctx, reports); err != nil {
+			errs = append(errs, fmt.Errorf("%s: store reports: %w", src.Provider(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
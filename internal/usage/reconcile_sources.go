@@ -0,0 +1,138 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	anthropicUsageReportURL = "https://api.anthropic.com/v1/organizations/usage_report/messages"
+	openAIUsageReportURL    = "https://api.openai.com/v1/organization/usage/completions"
+	usageReportPageLimit    = 31
+)
+
+var usageReportClient = &http.Client{Timeout: 60 * time.Second}
+
+// AnthropicUsageSource reads the Anthropic Admin API messages usage report.
+// Input tokens are uncached input, matching Claude's input_tokens.
+type AnthropicUsageSource struct {
+	ProviderName string
+	AdminKey     string
+	// BaseURL overrides the usage report endpoint.
+	BaseURL string
+}
+
+// Provider implements UsageReportSource.
+func (s *AnthropicUsageSource) Provider() string { return s.ProviderName }
+
+// FetchDaily implements UsageReportSource.
+func (s *AnthropicUsageSource) FetchDaily(ctx context.Context, from, to time.Time) ([]ProviderUsageReport, error) {
+	endpoint := s.BaseURL
+	if endpoint == "" {
+		endpoint = anthropicUsageReportURL
+	}
+	query := url.Values{
+		"starting_at":  {from.UTC().Format(time.RFC3339)},
+		"ending_at":    {to.UTC().Format(time.RFC3339)},
+		"bucket_width": {"1d"},
+		"limit":        {strconv.Itoa(usageReportPageLimit)},
+	}
+	header := http.Header{"X-Api-Key": {s.AdminKey}, "Anthropic-Version": {"2023-06-01"}}
+
+	var reports []ProviderUsageReport
+	err := fetchUsagePages(ctx, endpoint, query, header, func(bucket gjson.Result) {
+		start, err := time.Parse(time.RFC3339, bucket.Get("starting_at").String())
+		if err != nil {
+			return
+		}
+		r := ProviderUsageReport{Day: start.UTC().Format(time.DateOnly)}
+		for _, res := range bucket.Get("results").Array() {
+			r.InputTokens += res.Get("uncached_input_tokens").Int()
+			r.OutputTokens += res.Get("output_tokens").Int()
+			r.CachedTokens += res.Get("cache_read_input_tokens").Int()
+		}
+		reports = append(reports, r)
+	})
+	return reports, err
+}
+
+// OpenAIUsageSource reads the OpenAI organization completions usage API.
+// Input tokens include cached input, matching OpenAI's prompt_tokens.
+type OpenAIUsageSource struct {
+	ProviderName string
+	AdminKey     string
+	// BaseURL overrides the usage endpoint.
+	BaseURL string
+}
+
+// Provider implements UsageReportSource.
+func (s *OpenAIUsageSource) Provider() string { return s.ProviderName }
+
+// FetchDaily implements UsageReportSource.
+func (s *OpenAIUsageSource) FetchDaily(ctx context.Context, from, to time.Time) ([]ProviderUsageReport, error) {
+	endpoint := s.BaseURL
+	if endpoint == "" {
+		endpoint = openAIUsageReportURL
+	}
+	query := url.Values{
+		"start_time":   {strconv.FormatInt(from.Unix(), 10)},
+		"end_time":     {strconv.FormatInt(to.Unix(), 10)},
+		"bucket_width": {"1d"},
+		"limit":        {strconv.Itoa(usageReportPageLimit)},
+	}
+	header := http.Header{"Authorization": {"Bearer " + s.AdminKey}}
+
+	var reports []ProviderUsageReport
+	err := fetchUsagePages(ctx, endpoint, query, header, func(bucket gjson.Result) {
+		r := ProviderUsageReport{Day: time.Unix(bucket.Get("start_time").Int(), 0).UTC().Format(time.DateOnly)}
+		for _, res := range bucket.Get("results").Array() {
+			r.Requests += res.Get("num_model_requests").Int()
+			r.InputTokens += res.Get("input_tokens").Int()
+			r.OutputTokens += res.Get("output_tokens").Int()
+			r.CachedTokens += res.Get("input_cached_tokens").Int()
+		}
+		reports = append(reports, r)
+	})
+	return reports, err
+}
+
+// fetchUsagePages walks a paginated usage API ({data, has_more, next_page})
+// and calls visit for every bucket in data.
+func fetchUsagePages(ctx context.Context, endpoint string, query url.Values, header http.Header, visit func(bucket gjson.Result)) error {
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+		if err != nil {
+			return err
+		}
+		req.Header = header.Clone()
+		resp, err := usageReportClient.Do(req)
+		if err != nil {
+			return err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("usage API returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+		page := gjson.ParseBytes(body)
+		for _, bucket := range page.Get("data").Array() {
+			visit(bucket)
+		}
+		next := page.Get("next_page").String()
+		if !page.Get("has_more").Bool() || next == "" {
+			return nil
+		}
+		query.Set("page", next)
+	}
+}
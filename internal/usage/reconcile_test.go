@@ -0,0 +1,98 @@
+package usage
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReconcilerStoresProviderReports(t *testing.T) {
+	anthropic := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "admin-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("page") == "" {
+			w.Write([]byte(`{"data":[{"starting_at":"2026-10-14T00:00:00Z","results":[
+				{"uncached_input_tokens":900,"cache_read_input_tokens":50,"output_tokens":100}]}],
+				"has_more":true,"next_page":"p2"}`))
+			return
+		}
+		w.Write([]byte(`{"data":[{"starting_at":"2026-10-15T00:00:00Z","results":[
+			{"uncached_input_tokens":10,"output_tokens":5}]}],"has_more":false}`))
+	}))
+	defer anthropic.Close()
+
+	backend, err := NewSQLiteBackend(t.TempDir()+"/usage.db", BackendConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.db.Close()
+
+	r := NewReconciler(backend)
+	r.now = func() time.Time { return time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC) }
+	r.sources = []UsageReportSource{&AnthropicUsageSource{ProviderName: "claude", AdminKey: "admin-key", BaseURL: anthropic.URL}}
+	r.lookback = 2
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	since := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	reported, err := backend.QueryProviderReports(context.Background(), since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reported) != 2 || reported[0].Day != "2026-10-14" || reported[0].InputTokens != 900 || reported[0].CachedTokens != 50 {
+		t.Fatalf("reported = %+v", reported)
+	}
+
+	requestedAt := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	if err := backend.writeBatch(context.Background(), []UsageRecord{
+		{Provider: "claude", Model: "claude-sonnet-4-5", RequestedAt: requestedAt, InputTokens: 880, OutputTokens: 100, CacheReadInputTokens: 50},
+		{Provider: "claude", Model: "claude-sonnet-4-5", RequestedAt: requestedAt, InputTokens: 70, Failed: true},
+		{Provider: "gemini", Model: "gemini-2.5-pro", RequestedAt: requestedAt, InputTokens: 5},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	local, err := backend.QueryDailyProviderStats(context.Background(), since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(local) != 2 || local[0].Day != "2026-10-14" || local[0].Requests != 1 || local[0].CachedTokens != 50 {
+		t.Fatalf("local = %+v", local)
+	}
+
+	report := BuildDriftReport(local, reported)
+	if len(report) != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	if d := report[0]; d.Provider != "claude" || math.Abs(d.TokenDriftPercent+2) > 1e-9 {
+		t.Fatalf("drift = %+v, want -2%%", d)
+	}
+}
+
+func TestOpenAIUsageSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer admin-key" || r.URL.Query().Get("bucket_width") != "1d" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"data":[{"start_time":1791936000,"results":[
+			{"input_tokens":100,"output_tokens":20,"input_cached_tokens":40,"num_model_requests":3},
+			{"input_tokens":1,"output_tokens":1,"num_model_requests":1}]}],"has_more":false}`))
+	}))
+	defer srv.Close()
+
+	src := &OpenAIUsageSource{ProviderName: "openai", AdminKey: "admin-key", BaseURL: srv.URL}
+	reports, err := src.FetchDaily(context.Background(), time.Unix(1791936000, 0), time.Unix(1792022400, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := UsageTotals{Requests: 4, InputTokens: 101, OutputTokens: 21, CachedTokens: 40}
+	if len(reports) != 1 || reports[0].UsageTotals != want || reports[0].Day != "2026-10-14" {
+		t.Fatalf("reports = %+v", reports)
+	}
+}
@@ -3,9 +3,11 @@ package usage
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -27,6 +29,16 @@ type SQLiteBackend struct {
 	flushInterval time.Duration
 	retentionDays int
 	dbPath        string
+
+	// partitions holds usage records when partitioning is enabled; nil
+	// keeps them in the main database.
+	partitions *sqlitePartitions
+
+	transcriptRetentionDays int
+
+	vacuumInterval     time.Duration
+	analyzeInterval    time.Duration
+	checkpointInterval time.Duration
 }
 
 // SQLite backend constants
@@ -37,8 +49,9 @@ const (
 	sqliteDefaultChannelBufferSize = 1000
 )
 
-func initSchema(db *sql.DB) error {
-	schema := `
+// usageRecordsSchema creates the usage_records table, in the main database
+// and in each partition.
+const usageRecordsSchema = `
 	CREATE TABLE IF NOT EXISTS usage_records (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		provider TEXT NOT NULL,
@@ -58,6 +71,8 @@ func initSchema(db *sql.DB) error {
 		cache_creation_input_tokens INTEGER NOT NULL DEFAULT 0,
 		cache_read_input_tokens INTEGER NOT NULL DEFAULT 0,
 		tool_use_prompt_tokens INTEGER NOT NULL DEFAULT 0,
+		route_policy TEXT NOT NULL DEFAULT '',
+		estimated_savings REAL NOT NULL DEFAULT 0,
 		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);
 
@@ -66,10 +81,67 @@ func initSchema(db *sql.DB) error {
 	CREATE INDEX IF NOT EXISTS idx_usage_provider_model ON usage_records(provider, model);
 	`
 
+func initSchema(db *sql.DB) error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS provider_usage_reports (
+		day TEXT NOT NULL,
+		provider TEXT NOT NULL,
+		requests INTEGER NOT NULL DEFAULT 0,
+		input_tokens INTEGER NOT NULL DEFAULT 0,
+		output_tokens INTEGER NOT NULL DEFAULT 0,
+		cached_tokens INTEGER NOT NULL DEFAULT 0,
+		fetched_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (day, provider)
+	);
+
+	CREATE TABLE IF NOT EXISTS latency_histograms (
+		hour_start INTEGER NOT NULL,
+		provider TEXT NOT NULL,
+		model TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		bucket INTEGER NOT NULL,
+		samples INTEGER NOT NULL DEFAULT 0,
+		sum_ms INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (hour_start, provider, model, kind, bucket)
+	);
+
+	CREATE TABLE IF NOT EXISTS size_histograms (
+		hour_start INTEGER NOT NULL,
+		provider TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		bucket INTEGER NOT NULL,
+		samples INTEGER NOT NULL DEFAULT 0,
+		sum_bytes INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (hour_start, provider, kind, bucket)
+	);
+
+	CREATE TABLE IF NOT EXISTS transcripts (
+		id TEXT PRIMARY KEY,
+		requested_at TIMESTAMP NOT NULL,
+		api_key TEXT NOT NULL DEFAULT '',
+		model TEXT NOT NULL DEFAULT '',
+		format TEXT NOT NULL DEFAULT '',
+		label TEXT NOT NULL DEFAULT '',
+		stream BOOLEAN NOT NULL DEFAULT 0,
+		request TEXT NOT NULL,
+		response TEXT NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_transcripts_requested_at ON transcripts(requested_at);
+	CREATE INDEX IF NOT EXISTS idx_transcripts_label ON transcripts(label);
+	`
+
 	if _, err := db.Exec(schema); err != nil {
 		return err
 	}
 
+	return initUsageRecordsSchema(db)
+}
+
+func initUsageRecordsSchema(db *sql.DB) error {
+	if _, err := db.Exec(usageRecordsSchema); err != nil {
+		return err
+	}
 	return migrateSchema(db)
 }
 
@@ -79,6 +151,8 @@ func migrateSchema(db *sql.DB) error {
 		"cache_creation_input_tokens INTEGER NOT NULL DEFAULT 0",
 		"cache_read_input_tokens INTEGER NOT NULL DEFAULT 0",
 		"tool_use_prompt_tokens INTEGER NOT NULL DEFAULT 0",
+		"route_policy TEXT NOT NULL DEFAULT ''",
+		"estimated_savings REAL NOT NULL DEFAULT 0",
 	}
 
 	for _, colDef := range migrations {
@@ -119,7 +193,7 @@ func NewSQLiteBackend(dbPath string, cfg BackendConfig) (*SQLiteBackend, error)
 	}
 
 	// Open database with WAL mode
-	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_synchronous=NORMAL&_cache_size=-64000")
+	db, err := sql.Open("sqlite", dbPath+sqlitePragmas(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -149,6 +223,27 @@ func NewSQLiteBackend(dbPath string, cfg BackendConfig) (*SQLiteBackend, error)
 	if retentionDays <= 0 {
 		retentionDays = sqliteDefaultRetentionDays
 	}
+	transcriptRetentionDays := cfg.TranscriptRetentionDays
+	if transcriptRetentionDays <= 0 {
+		transcriptRetentionDays = retentionDays
+	}
+
+	var partitions *sqlitePartitions
+	switch cfg.Partition {
+	case "":
+	case SQLitePartitionMonth:
+		if retentionDays > sqlitePartitionMaxRetentionDays {
+			_ = db.Close()
+			return nil, fmt.Errorf("monthly partitions support at most %d retention days, got %d", sqlitePartitionMaxRetentionDays, retentionDays)
+		}
+		if partitions, err = openSQLitePartitions(dbPath, sqlitePragmas(cfg)); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	default:
+		_ = db.Close()
+		return nil, fmt.Errorf("unknown SQLite partition %q", cfg.Partition)
+	}
 
 	return &SQLiteBackend{
 		db:            db,
@@ -160,14 +255,22 @@ func NewSQLiteBackend(dbPath string, cfg BackendConfig) (*SQLiteBackend, error)
 		retentionDays: retentionDays,
 		cleanupTicker: time.NewTicker(24 * time.Hour), // Cleanup daily
 		dbPath:        dbPath,
+		partitions:    partitions,
+
+		transcriptRetentionDays: transcriptRetentionDays,
+
+		vacuumInterval:     cfg.VacuumInterval,
+		analyzeInterval:    cfg.AnalyzeInterval,
+		checkpointInterval: cfg.CheckpointInterval,
 	}, nil
 }
 
 // Start begins background workers (write loop, cleanup loop).
 func (b *SQLiteBackend) Start() error {
-	b.wg.Add(2)
+	b.wg.Add(3)
 	go b.writeLoop()
 	go b.cleanupLoop()
+	go b.maintenanceLoop()
 	return nil
 }
 
@@ -193,6 +296,9 @@ func (b *SQLiteBackend) Stop() error {
 		if b.db != nil {
 			err = b.db.Close()
 		}
+		if perr := b.partitions.close(); perr != nil {
+			err = errors.Join(err, perr)
+		}
 	})
 
 	return err
@@ -243,18 +349,25 @@ func (b *SQLiteBackend) Flush(ctx context.Context) error {
 
 // QueryGlobalStats returns aggregate statistics since the given time.
 func (b *SQLiteBackend) QueryGlobalStats(ctx context.Context, since time.Time) (*AggregatedStats, error) {
+	release, err := b.partitions.acquire(ctx, b.db)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	row := b.db.QueryRowContext(ctx, `
 		SELECT 
 			COUNT(*),
 			SUM(CASE WHEN failed = 0 THEN 1 ELSE 0 END),
 			SUM(CASE WHEN failed = 1 THEN 1 ELSE 0 END),
-			COALESCE(SUM(total_tokens), 0)
+			COALESCE(SUM(total_tokens), 0),
+			COALESCE(SUM(estimated_savings), 0)
 		FROM usage_records
 		WHERE requested_at >= ?
 	`, since)
 
 	var stats AggregatedStats
-	if err := row.Scan(&stats.TotalRequests, &stats.SuccessCount, &stats.FailureCount, &stats.TotalTokens); err != nil {
+	if err := row.Scan(&stats.TotalRequests, &stats.SuccessCount, &stats.FailureCount, &stats.TotalTokens, &stats.EstimatedSavings); err != nil {
 		return nil, fmt.Errorf("failed to query global stats: %w", err)
 	}
 	return &stats, nil
@@ -262,6 +375,12 @@ func (b *SQLiteBackend) QueryGlobalStats(ctx context.Context, since time.Time) (
 
 // QueryDailyStats returns per-day statistics since the given time.
 func (b *SQLiteBackend) QueryDailyStats(ctx context.Context, since time.Time) ([]DailyStats, error) {
+	release, err := b.partitions.acquire(ctx, b.db)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	rows, err := b.db.QueryContext(ctx, `
 		SELECT 
 			COALESCE(DATE(requested_at), DATE('now')) as day,
@@ -295,6 +414,12 @@ func (b *SQLiteBackend) QueryDailyStats(ctx context.Context, since time.Time) ([
 
 // QueryHourlyStats returns per-hour-of-day statistics since the given time.
 func (b *SQLiteBackend) QueryHourlyStats(ctx context.Context, since time.Time) ([]HourlyStats, error) {
+	release, err := b.partitions.acquire(ctx, b.db)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	rows, err := b.db.QueryContext(ctx, `
 		SELECT 
 			CAST(strftime('%H', requested_at) AS INTEGER) as hour,
@@ -322,6 +447,12 @@ func (b *SQLiteBackend) QueryHourlyStats(ctx context.Context, since time.Time) (
 }
 
 func (b *SQLiteBackend) QueryProviderStats(ctx context.Context, since time.Time) ([]ProviderStats, error) {
+	release, err := b.partitions.acquire(ctx, b.db)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	rows, err := b.db.QueryContext(ctx, `
 		SELECT 
 			COALESCE(NULLIF(provider, ''), 'unknown') as provider,
@@ -364,6 +495,12 @@ func (b *SQLiteBackend) QueryProviderStats(ctx context.Context, since time.Time)
 }
 
 func (b *SQLiteBackend) QueryAuthStats(ctx context.Context, since time.Time) ([]AuthStats, error) {
+	release, err := b.partitions.acquire(ctx, b.db)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	rows, err := b.db.QueryContext(ctx, `
 		SELECT 
 			COALESCE(NULLIF(provider, ''), 'unknown') as provider,
@@ -400,6 +537,12 @@ func (b *SQLiteBackend) QueryAuthStats(ctx context.Context, since time.Time) ([]
 }
 
 func (b *SQLiteBackend) QueryModelStats(ctx context.Context, since time.Time) ([]ModelStats, error) {
+	release, err := b.partitions.acquire(ctx, b.db)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	rows, err := b.db.QueryContext(ctx, `
 		SELECT 
 			COALESCE(NULLIF(model, ''), 'unknown') as model,
@@ -435,14 +578,263 @@ func (b *SQLiteBackend) QueryModelStats(ctx context.Context, since time.Time) ([
 	return results, rows.Err()
 }
 
+// QueryDailyProviderStats returns successful usage per day and provider since the given time.
+// Days come from the stored timestamp, which is in the server's time zone.
+func (b *SQLiteBackend) QueryDailyProviderStats(ctx context.Context, since time.Time) ([]DailyProviderStats, error) {
+	release, err := b.partitions.acquire(ctx, b.db)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	rows, err := b.db.QueryContext(ctx, `
+		SELECT
+			SUBSTR(requested_at, 1, 10) as day,
+			provider,
+			COUNT(*) as requests,
+			COALESCE(SUM(input_tokens), 0) as input_tokens,
+			COALESCE(SUM(output_tokens), 0) as output_tokens,
+			COALESCE(SUM(MAX(cached_tokens, cache_read_input_tokens)), 0) as cached_tokens
+		FROM usage_records
+		WHERE requested_at >= ? AND failed = 0
+		GROUP BY day, provider
+		ORDER BY day, provider
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily provider stats: %w", err)
+	}
+	defer rows.Close()
+
+	var results []DailyProviderStats
+	for rows.Next() {
+		var d DailyProviderStats
+		if err := rows.Scan(&d.Day, &d.Provider, &d.Requests, &d.InputTokens, &d.OutputTokens, &d.CachedTokens); err != nil {
+			return nil, err
+		}
+		results = append(results, d)
+	}
+	return results, rows.Err()
+}
+
+// QueryUsage aggregates records over arbitrary time buckets. Buckets follow
+// the server's local wall clock.
+func (b *SQLiteBackend) QueryUsage(ctx context.Context, q UsageQuery) ([]UsageQueryRow, error) {
+	release, err := b.partitions.acquire(ctx, b.db)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	query, args := sqliteUsageDialect.build(q, q.From.Local(), q.To.Local())
+	rows, err := b.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	defer rows.Close()
+
+	localBucket := func(wall int64) time.Time {
+		t := time.Unix(wall, 0).UTC()
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.Local)
+	}
+	var results []UsageQueryRow
+	for rows.Next() {
+		row, err := scanUsageQueryRow(q, rows.Scan, localBucket)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, row)
+	}
+	return results, rows.Err()
+}
+
+// QueryLatencyHistograms returns latency histograms for the hours overlapping [from, to).
+func (b *SQLiteBackend) QueryLatencyHistograms(ctx context.Context, from, to time.Time) ([]LatencyHistogram, error) {
+	rows, err := b.db.QueryContext(ctx, `
+		SELECT provider, model, kind, bucket, SUM(samples), SUM(sum_ms)
+		FROM latency_histograms
+		WHERE hour_start >= ? AND hour_start < ?
+		GROUP BY provider, model, kind, bucket
+		ORDER BY provider, model, kind, bucket
+	`, from.Truncate(time.Hour).Unix(), to.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latency histograms: %w", err)
+	}
+	defer rows.Close()
+
+	var set latencyHistogramSet
+	for rows.Next() {
+		var provider, model, kind string
+		var bucket int
+		var samples, sumMs int64
+		if err := rows.Scan(&provider, &model, &kind, &bucket, &samples, &sumMs); err != nil {
+			return nil, err
+		}
+		set.add(provider, model, kind, bucket, samples, sumMs)
+	}
+	return set.histograms(), rows.Err()
+}
+
+// QuerySizeHistograms returns payload size histograms for the hours overlapping [from, to).
+func (b *SQLiteBackend) QuerySizeHistograms(ctx context.Context, from, to time.Time) ([]SizeHistogram, error) {
+	rows, err := b.db.QueryContext(ctx, `
+		SELECT provider, kind, bucket, SUM(samples), SUM(sum_bytes)
+		FROM size_histograms
+		WHERE hour_start >= ? AND hour_start < ?
+		GROUP BY provider, kind, bucket
+		ORDER BY provider, kind, bucket
+	`, from.Truncate(time.Hour).Unix(), to.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query size histograms: %w", err)
+	}
+	defer rows.Close()
+
+	var set sizeHistogramSet
+	for rows.Next() {
+		var provider, kind string
+		var bucket int
+		var samples, sumBytes int64
+		if err := rows.Scan(&provider, &kind, &bucket, &samples, &sumBytes); err != nil {
+			return nil, err
+		}
+		set.add(provider, kind, bucket, samples, sumBytes)
+	}
+	return set.histograms(), rows.Err()
+}
+
+// SaveProviderReports upserts provider-reported daily usage.
+func (b *SQLiteBackend) SaveProviderReports(ctx context.Context, reports []ProviderUsageReport) error {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	for _, r := range reports {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO provider_usage_reports (day, provider, requests, input_tokens, output_tokens, cached_tokens, fetched_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (day, provider) DO UPDATE SET
+				requests = excluded.requests,
+				input_tokens = excluded.input_tokens,
+				output_tokens = excluded.output_tokens,
+				cached_tokens = excluded.cached_tokens,
+				fetched_at = excluded.fetched_at
+		`, r.Day, r.Provider, r.Requests, r.InputTokens, r.OutputTokens, r.CachedTokens, r.FetchedAt); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to save provider report: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// QueryProviderReports returns provider-reported usage for days on or after since.
+func (b *SQLiteBackend) QueryProviderReports(ctx context.Context, since time.Time) ([]ProviderUsageReport, error) {
+	rows, err := b.db.QueryContext(ctx, `
+		SELECT day, provider, requests, input_tokens, output_tokens, cached_tokens, fetched_at
+		FROM provider_usage_reports
+		WHERE day >= ?
+		ORDER BY day, provider
+	`, since.UTC().Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query provider reports: %w", err)
+	}
+	defer rows.Close()
+
+	var results []ProviderUsageReport
+	for rows.Next() {
+		var r ProviderUsageReport
+		if err := rows.Scan(&r.Day, &r.Provider, &r.Requests, &r.InputTokens, &r.OutputTokens, &r.CachedTokens, &r.FetchedAt); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
 // Cleanup removes records older than the given time.
 func (b *SQLiteBackend) Cleanup(ctx context.Context, before time.Time) (int64, error) {
 	result, err := b.db.ExecContext(ctx, `
-		DELETE FROM usage_records WHERE requested_at < ?
+		DELETE FROM main.usage_records WHERE requested_at < ?
 	`, before)
 	if err != nil {
 		return 0, err
 	}
+	for _, table := range []string{"latency_histograms", "size_histograms"} {
+		if _, err := b.db.ExecContext(ctx, `DELETE FROM `+table+` WHERE hour_start < ?`, before.Truncate(time.Hour).Unix()); err != nil {
+			return 0, err
+		}
+	}
+	removed, _ := result.RowsAffected()
+	if b.partitions != nil {
+		n, err := b.partitions.cleanup(ctx, b.db, before)
+		removed += n
+		if err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// SaveTranscript stores a transcript, replacing one with the same ID.
+func (b *SQLiteBackend) SaveTranscript(ctx context.Context, t Transcript) error {
+	_, err := b.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO transcripts (`+transcriptColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, t.ID, t.RequestedAt.Local(), t.APIKey, t.Model, t.Format, t.Label, t.Stream, string(t.Request), string(t.Response))
+	if err != nil {
+		return fmt.Errorf("failed to save transcript: %w", err)
+	}
+	return nil
+}
+
+// QueryTranscripts returns matching transcripts, newest first.
+func (b *SQLiteBackend) QueryTranscripts(ctx context.Context, q TranscriptQuery) ([]Transcript, error) {
+	if !q.From.IsZero() {
+		q.From = q.From.Local()
+	}
+	if !q.To.IsZero() {
+		q.To = q.To.Local()
+	}
+	where, args := q.build(sqliteUsageDialect)
+	rows, err := b.db.QueryContext(ctx, `
+		SELECT `+transcriptColumns+`
+		FROM transcripts
+		WHERE `+where+`
+		ORDER BY requested_at DESC
+		LIMIT `+strconv.Itoa(q.limit()), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transcripts: %w", err)
+	}
+	defer rows.Close()
+
+	var results []Transcript
+	for rows.Next() {
+		t, err := scanTranscript(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, t)
+	}
+	return results, rows.Err()
+}
+
+// GetTranscript returns the transcript with the given ID, or nil.
+func (b *SQLiteBackend) GetTranscript(ctx context.Context, id string) (*Transcript, error) {
+	row := b.db.QueryRowContext(ctx, `SELECT `+transcriptColumns+` FROM transcripts WHERE id = ?`, id)
+	t, err := scanTranscript(row.Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transcript: %w", err)
+	}
+	return &t, nil
+}
+
+// CleanupTranscripts removes transcripts older than the given time.
+func (b *SQLiteBackend) CleanupTranscripts(ctx context.Context, before time.Time) (int64, error) {
+	result, err := b.db.ExecContext(ctx, `DELETE FROM transcripts WHERE requested_at < ?`, before.Local())
+	if err != nil {
+		return 0, err
+	}
 	return result.RowsAffected()
 }
 
@@ -505,21 +897,116 @@ func (b *SQLiteBackend) writeBatch(ctx context.Context, records []UsageRecord) e
 		return nil
 	}
 
+	// Partitions are written before the main transaction begins, so a
+	// partition write never waits on a query holding the main connection.
+	if b.partitions != nil {
+		if err := b.partitions.write(ctx, records); err != nil {
+			return err
+		}
+	}
+
 	tx, err := b.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	if b.partitions == nil {
+		if err := insertUsageRecords(ctx, tx, records); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	if deltas := latencyDeltas(records); len(deltas) > 0 {
+		histStmt, err := tx.PrepareContext(ctx, `
+			INSERT INTO latency_histograms (hour_start, provider, model, kind, bucket, samples, sum_ms)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (hour_start, provider, model, kind, bucket) DO UPDATE SET
+				samples = latency_histograms.samples + excluded.samples,
+				sum_ms = latency_histograms.sum_ms + excluded.sum_ms
+		`)
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to prepare histogram statement: %w", err)
+		}
+		defer histStmt.Close()
+		for k, d := range deltas {
+			if _, err := histStmt.ExecContext(ctx, k.hour, k.provider, k.model, k.kind, k.bucket, d.samples, d.sumMs); err != nil {
+				_ = tx.Rollback()
+				return fmt.Errorf("failed to update latency histogram: %w", err)
+			}
+		}
+	}
+
+	if deltas := sizeDeltas(records); len(deltas) > 0 {
+		sizeStmt, err := tx.PrepareContext(ctx, `
+			INSERT INTO size_histograms (hour_start, provider, kind, bucket, samples, sum_bytes)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (hour_start, provider, kind, bucket) DO UPDATE SET
+				samples = size_histograms.samples + excluded.samples,
+				sum_bytes = size_histograms.sum_bytes + excluded.sum_bytes
+		`)
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to prepare size histogram statement: %w", err)
+		}
+		defer sizeStmt.Close()
+		for k, d := range deltas {
+			if _, err := sizeStmt.ExecContext(ctx, k.hour, k.provider, k.kind, k.bucket, d.samples, d.sumBytes); err != nil {
+				_ = tx.Rollback()
+				return fmt.Errorf("failed to update size histogram: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// cleanupLoop periodically removes old records based on retention policy.
+func (b *SQLiteBackend) cleanupLoop() {
+	defer b.wg.Done()
 
+	for {
+		select {
+		case <-b.cleanupTicker.C:
+			cutoffTime := time.Now().AddDate(0, 0, -b.retentionDays)
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+			rowsDeleted, err := b.Cleanup(ctx, cutoffTime)
+			cancel()
+			if err != nil {
+				log.Errorf("Failed to cleanup old usage records: %v", err)
+			} else if rowsDeleted > 0 {
+				log.Infof("Cleaned up %d usage records older than %d days", rowsDeleted, b.retentionDays)
+			}
+			ctx, cancel = context.WithTimeout(context.Background(), 1*time.Minute)
+			transcriptsDeleted, err := b.CleanupTranscripts(ctx, time.Now().AddDate(0, 0, -b.transcriptRetentionDays))
+			cancel()
+			if err != nil {
+				log.Errorf("Failed to cleanup old transcripts: %v", err)
+			} else if transcriptsDeleted > 0 {
+				log.Infof("Cleaned up %d transcripts older than %d days", transcriptsDeleted, b.transcriptRetentionDays)
+			}
+		case <-b.stopChan:
+			return
+		}
+	}
+}
+
+// insertUsageRecords inserts records into the usage_records table of tx.
+func insertUsageRecords(ctx context.Context, tx *sql.Tx, records []UsageRecord) error {
 	stmt, err := tx.PrepareContext(ctx, `
 		INSERT INTO usage_records (
 			provider, model, api_key, auth_id, auth_index, source,
 			requested_at, failed, input_tokens, output_tokens,
 			reasoning_tokens, cached_tokens, total_tokens,
-			audio_tokens, cache_creation_input_tokens, cache_read_input_tokens, tool_use_prompt_tokens
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			audio_tokens, cache_creation_input_tokens, cache_read_input_tokens, tool_use_prompt_tokens,
+			route_policy, estimated_savings
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
-		_ = tx.Rollback()
 		return fmt.Errorf("failed to prepare statement: %w", err)
 	}
 	defer stmt.Close()
@@ -543,38 +1030,12 @@ func (b *SQLiteBackend) writeBatch(ctx context.Context, records []UsageRecord) e
 			record.CacheCreationInputTokens,
 			record.CacheReadInputTokens,
 			record.ToolUsePromptTokens,
+			record.RoutePolicy,
+			record.EstimatedSavings,
 		)
 		if err != nil {
-			_ = tx.Rollback()
 			return fmt.Errorf("failed to insert record: %w", err)
 		}
 	}
-
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
-
-// cleanupLoop periodically removes old records based on retention policy.
-func (b *SQLiteBackend) cleanupLoop() {
-	defer b.wg.Done()
-
-	for {
-		select {
-		case <-b.cleanupTicker.C:
-			cutoffTime := time.Now().AddDate(0, 0, -b.retentionDays)
-			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
-			rowsDeleted, err := b.Cleanup(ctx, cutoffTime)
-			cancel()
-			if err != nil {
-				log.Errorf("Failed to cleanup old usage records: %v", err)
-			} else if rowsDeleted > 0 {
-				log.Infof("Cleaned up %d usage records older than %d days", rowsDeleted, b.retentionDays)
-			}
-		case <-b.stopChan:
-			return
-		}
-	}
-}
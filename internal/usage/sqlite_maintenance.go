@@ -0,0 +1,190 @@
+package usage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"modernc.org/sqlite"
+)
+
+// sqliteMaintenanceTimeout bounds a scheduled VACUUM, ANALYZE or checkpoint.
+const sqliteMaintenanceTimeout = 10 * time.Minute
+
+// sqlitePragmas returns the connection parameters applied to every
+// connection of the usage database.
+func sqlitePragmas(cfg BackendConfig) string {
+	pragmas := []string{"journal_mode(WAL)", "synchronous(NORMAL)", "cache_size(-64000)"}
+	if cfg.WALAutocheckpoint > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("wal_autocheckpoint(%d)", cfg.WALAutocheckpoint))
+	}
+	if cfg.JournalSizeLimit > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("journal_size_limit(%d)", cfg.JournalSizeLimit))
+	}
+	return "?_pragma=" + strings.Join(pragmas, "&_pragma=")
+}
+
+// Backup writes a consistent copy of the database to path using the SQLite
+// online backup API. Writers wait while pages are copied. The copy is made
+// next to path and renamed into place, so path never holds a partial backup.
+func (b *SQLiteBackend) Backup(ctx context.Context, path string) error {
+	if strings.HasPrefix(path, "~") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	path = filepath.Clean(path)
+	if same, _ := sameFile(path, b.dbPath); same {
+		return fmt.Errorf("backup path is the live database")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	if err := backupSQLite(ctx, b.db, path); err != nil {
+		return err
+	}
+	// Partitions are copied next to path under the same naming scheme as
+	// the live files.
+	ext := filepath.Ext(path)
+	return b.partitions.each(func(month string, db *sql.DB) error {
+		if err := backupSQLite(ctx, db, strings.TrimSuffix(path, ext)+"-"+month+ext); err != nil {
+			return fmt.Errorf("usage partition %s: %w", month, err)
+		}
+		return nil
+	})
+}
+
+// backupSQLite copies db to path with the online backup API.
+func backupSQLite(ctx context.Context, db *sql.DB, path string) error {
+	tmp := path + ".tmp"
+	_ = os.Remove(tmp)
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+	err = conn.Raw(func(driverConn any) error {
+		src, ok := driverConn.(interface {
+			NewBackup(string) (*sqlite.Backup, error)
+		})
+		if !ok {
+			return fmt.Errorf("sqlite driver does not support online backup")
+		}
+		bck, err := src.NewBackup(tmp)
+		if err != nil {
+			return err
+		}
+		for more := true; more; {
+			if more, err = bck.Step(-1); err != nil {
+				_ = bck.Finish()
+				return err
+			}
+		}
+		return bck.Finish()
+	})
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to move backup into place: %w", err)
+	}
+	return nil
+}
+
+func sameFile(a, b string) (bool, error) {
+	fa, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	fb, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	return os.SameFile(fa, fb), nil
+}
+
+// Vacuum rebuilds the database to release free pages, then truncates the WAL
+// the rebuild grew.
+func (b *SQLiteBackend) Vacuum(ctx context.Context) error {
+	if _, err := b.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("vacuum failed: %w", err)
+	}
+	return b.Checkpoint(ctx)
+}
+
+// Analyze refreshes the query planner statistics.
+func (b *SQLiteBackend) Analyze(ctx context.Context) error {
+	if _, err := b.db.ExecContext(ctx, "ANALYZE"); err != nil {
+		return fmt.Errorf("analyze failed: %w", err)
+	}
+	return nil
+}
+
+// Checkpoint copies the WAL into the database and truncates it.
+func (b *SQLiteBackend) Checkpoint(ctx context.Context) error {
+	var busy, logFrames, checkpointed int
+	row := b.db.QueryRowContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)")
+	if err := row.Scan(&busy, &logFrames, &checkpointed); err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("wal checkpoint failed: %w", err)
+	}
+	if busy != 0 {
+		return fmt.Errorf("wal checkpoint incomplete: database busy")
+	}
+	return nil
+}
+
+// maintenanceLoop runs the configured VACUUM, ANALYZE and checkpoint schedules.
+func (b *SQLiteBackend) maintenanceLoop() {
+	defer b.wg.Done()
+
+	vacuum, stopVacuum := maintenanceTicker(b.vacuumInterval)
+	defer stopVacuum()
+	analyze, stopAnalyze := maintenanceTicker(b.analyzeInterval)
+	defer stopAnalyze()
+	checkpoint, stopCheckpoint := maintenanceTicker(b.checkpointInterval)
+	defer stopCheckpoint()
+
+	run := func(name string, task func(context.Context) error) {
+		ctx, cancel := context.WithTimeout(context.Background(), sqliteMaintenanceTimeout)
+		defer cancel()
+		start := time.Now()
+		if err := task(ctx); err != nil {
+			log.Errorf("Usage database %s failed: %v", name, err)
+			return
+		}
+		log.Debugf("Usage database %s finished in %s", name, time.Since(start).Round(time.Millisecond))
+	}
+
+	for {
+		select {
+		case <-vacuum:
+			run("vacuum", b.Vacuum)
+		case <-analyze:
+			run("analyze", b.Analyze)
+		case <-checkpoint:
+			run("checkpoint", b.Checkpoint)
+		case <-b.stopChan:
+			return
+		}
+	}
+}
+
+// maintenanceTicker returns a ticker channel for interval, or a nil channel
+// that never fires when interval is zero.
+func maintenanceTicker(interval time.Duration) (<-chan time.Time, func()) {
+	if interval <= 0 {
+		return nil, func() {}
+	}
+	t := time.NewTicker(interval)
+	return t.C, t.Stop
+}
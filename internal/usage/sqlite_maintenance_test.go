@@ -0,0 +1,49 @@
+package usage
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteBackendMaintenance(t *testing.T) {
+	dir := t.TempDir()
+	b, err := NewSQLiteBackend(filepath.Join(dir, "usage.db"), BackendConfig{WALAutocheckpoint: 100, JournalSizeLimit: 1 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = b.Stop() })
+	ctx := context.Background()
+
+	var mode string
+	if err := b.db.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&mode); err != nil || mode != "wal" {
+		t.Fatalf("journal_mode = %q, %v", mode, err)
+	}
+	if err := b.writeBatch(ctx, []UsageRecord{{Provider: "claude", Model: "m", RequestedAt: time.Now(), TotalTokens: 7}}); err != nil {
+		t.Fatal(err)
+	}
+	for name, task := range map[string]func(context.Context) error{"vacuum": b.Vacuum, "analyze": b.Analyze, "checkpoint": b.Checkpoint} {
+		if err := task(ctx); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	dest := filepath.Join(dir, "backups", "copy.db")
+	if err := b.Backup(ctx, dest); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Backup(ctx, filepath.Join(dir, "usage.db")); err == nil {
+		t.Error("backup over the live database succeeded")
+	}
+	copyDB, err := sql.Open("sqlite", dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer copyDB.Close()
+	var tokens int64
+	if err := copyDB.QueryRow("SELECT SUM(total_tokens) FROM usage_records").Scan(&tokens); err != nil || tokens != 7 {
+		t.Errorf("backup tokens = %d, %v", tokens, err)
+	}
+}
@@ -0,0 +1,361 @@
+package usage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+const (
+	// SQLitePartitionMonth stores usage records in one database file per
+	// UTC month next to the main database, such as usage-2026-10.db.
+	SQLitePartitionMonth = "month"
+
+	// sqlitePartitionMaxRetentionDays keeps the partitions covering the
+	// retention window within SQLite's limit of 10 attached databases.
+	sqlitePartitionMaxRetentionDays = 180
+
+	sqlitePartitionLayout = "2006-01"
+)
+
+// usageRecordColumns are the columns of usage_records, listed so the view
+// over the partitions does not depend on the column order of each file.
+const usageRecordColumns = `id, provider, model, api_key, auth_id, auth_index, source,
+	requested_at, failed, input_tokens, output_tokens, reasoning_tokens, cached_tokens, total_tokens,
+	audio_tokens, cache_creation_input_tokens, cache_read_input_tokens, tool_use_prompt_tokens,
+	route_policy, estimated_savings, created_at`
+
+// sqlitePartitions stores usage records in monthly database files, each
+// with its own connection so months are written in parallel. The files are
+// attached to the main connection and read through a temporary
+// usage_records view, which shadows the main database's table, so queries
+// span every partition unchanged. Rows written to the main table before
+// partitioning was enabled stay part of the view until retention removes
+// them.
+type sqlitePartitions struct {
+	// mu is held for reading while a partition is written or queried, and
+	// for writing while one is dropped.
+	mu      sync.RWMutex
+	prefix  string // path of a partition without its month and extension
+	ext     string
+	pragmas string
+	dbs     map[string]*sql.DB // "2006-01" -> partition
+
+	// viewMu serializes rebuilding the view; stale is set when partitions
+	// were added or dropped since.
+	viewMu sync.Mutex
+	stale  bool
+}
+
+// openSQLitePartitions opens the existing partitions of the database at
+// dbPath.
+func openSQLitePartitions(dbPath, pragmas string) (*sqlitePartitions, error) {
+	ext := filepath.Ext(dbPath)
+	p := &sqlitePartitions{
+		prefix:  strings.TrimSuffix(dbPath, ext) + "-",
+		ext:     ext,
+		pragmas: pragmas,
+		dbs:     make(map[string]*sql.DB),
+		stale:   true,
+	}
+	files, err := filepath.Glob(p.prefix + "[0-9][0-9][0-9][0-9]-[0-9][0-9]" + ext)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		month := strings.TrimSuffix(strings.TrimPrefix(file, p.prefix), ext)
+		if _, err := time.Parse(sqlitePartitionLayout, month); err != nil {
+			continue
+		}
+		if _, err := p.open(month); err != nil {
+			p.close()
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *sqlitePartitions) path(month string) string {
+	return p.prefix + month + p.ext
+}
+
+// open opens or creates the partition of month. Caller must hold p.mu or
+// be the only user of p.
+func (p *sqlitePartitions) open(month string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", p.path(month)+p.pragmas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage partition %s: %w", month, err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	if err := initUsageRecordsSchema(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize usage partition %s: %w", month, err)
+	}
+	p.dbs[month] = db
+	p.stale = true
+	return db, nil
+}
+
+// partition returns the partition of month, creating it when missing.
+func (p *sqlitePartitions) partition(month string) (*sql.DB, error) {
+	p.mu.RLock()
+	db := p.dbs[month]
+	p.mu.RUnlock()
+	if db != nil {
+		return db, nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if db := p.dbs[month]; db != nil {
+		return db, nil
+	}
+	log.Infof("Creating usage partition %s", p.path(month))
+	return p.open(month)
+}
+
+// write inserts records into the partitions of their months, writing each
+// month in parallel.
+func (p *sqlitePartitions) write(ctx context.Context, records []UsageRecord) error {
+	byMonth := make(map[string][]UsageRecord)
+	for _, r := range records {
+		month := r.RequestedAt.UTC().Format(sqlitePartitionLayout)
+		byMonth[month] = append(byMonth[month], r)
+	}
+	var (
+		wg    sync.WaitGroup
+		errMu sync.Mutex
+		errs  []error
+	)
+	for month, group := range byMonth {
+		db, err := p.partition(month)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.mu.RLock()
+			defer p.mu.RUnlock()
+			if err := insertUsageRecordsTx(ctx, db, group); err != nil {
+				errMu.Lock()
+				errs = append(errs, fmt.Errorf("usage partition %s: %w", month, err))
+				errMu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func insertUsageRecordsTx(ctx context.Context, db *sql.DB, records []UsageRecord) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := insertUsageRecords(ctx, tx, records); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// acquire attaches the partitions to the main connection and points the
+// usage_records view at them. The returned func must be called once the
+// query is done.
+func (p *sqlitePartitions) acquire(ctx context.Context, main *sql.DB) (func(), error) {
+	if p == nil {
+		return func() {}, nil
+	}
+	p.mu.RLock()
+	p.viewMu.Lock()
+	err := p.syncView(ctx, main)
+	p.viewMu.Unlock()
+	if err != nil {
+		p.mu.RUnlock()
+		return nil, err
+	}
+	return p.mu.RUnlock, nil
+}
+
+// syncView rebuilds the view when partitions changed or the connection
+// lost it. Caller must hold p.mu for reading and p.viewMu.
+func (p *sqlitePartitions) syncView(ctx context.Context, main *sql.DB) error {
+	var views int
+	if err := main.QueryRowContext(ctx, `SELECT COUNT(*) FROM temp.sqlite_master WHERE type = 'view' AND name = 'usage_records'`).Scan(&views); err != nil {
+		return err
+	}
+	if views == 1 && !p.stale {
+		return nil
+	}
+
+	if _, err := main.ExecContext(ctx, `DROP VIEW IF EXISTS temp.usage_records`); err != nil {
+		return err
+	}
+	attached, err := attachedPartitions(ctx, main)
+	if err != nil {
+		return err
+	}
+	for _, schema := range attached {
+		if _, err := main.ExecContext(ctx, `DETACH DATABASE `+schema); err != nil {
+			return fmt.Errorf("failed to detach usage partition: %w", err)
+		}
+	}
+	months := make([]string, 0, len(p.dbs))
+	for month := range p.dbs {
+		months = append(months, month)
+	}
+	slices.Sort(months)
+	selects := []string{`SELECT ` + usageRecordColumns + ` FROM main.usage_records`}
+	for _, month := range months {
+		schema := partitionSchema(month)
+		if _, err := main.ExecContext(ctx, `ATTACH DATABASE ? AS `+schema, p.path(month)); err != nil {
+			return fmt.Errorf("failed to attach usage partition %s: %w", month, err)
+		}
+		selects = append(selects, `SELECT `+usageRecordColumns+` FROM `+schema+`.usage_records`)
+	}
+	if _, err := main.ExecContext(ctx, `CREATE TEMP VIEW usage_records AS `+strings.Join(selects, ` UNION ALL `)); err != nil {
+		return fmt.Errorf("failed to create usage view: %w", err)
+	}
+	p.stale = false
+	return nil
+}
+
+// partitionSchema is the schema name a partition is attached as.
+func partitionSchema(month string) string {
+	return "usage_" + strings.ReplaceAll(month, "-", "_")
+}
+
+func attachedPartitions(ctx context.Context, main *sql.DB) ([]string, error) {
+	rows, err := main.QueryContext(ctx, `PRAGMA database_list`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var (
+			seq        int
+			name, file string
+		)
+		if err := rows.Scan(&seq, &name, &file); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(name, "usage_") {
+			out = append(out, name)
+		}
+	}
+	return out, rows.Err()
+}
+
+// cleanup removes records older than before: partitions whose month ended
+// by then are deleted, and older rows of the others. It returns the number
+// of records removed.
+func (p *sqlitePartitions) cleanup(ctx context.Context, main *sql.DB, before time.Time) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var removed int64
+	for month, db := range p.dbs {
+		start, _ := time.Parse(sqlitePartitionLayout, month)
+		if start.AddDate(0, 1, 0).After(before) {
+			result, err := db.ExecContext(ctx, `DELETE FROM usage_records WHERE requested_at < ?`, before)
+			if err != nil {
+				return removed, fmt.Errorf("usage partition %s: %w", month, err)
+			}
+			n, _ := result.RowsAffected()
+			removed += n
+			continue
+		}
+		var n int64
+		if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM usage_records`).Scan(&n); err != nil {
+			return removed, fmt.Errorf("usage partition %s: %w", month, err)
+		}
+		if err := p.drop(ctx, main, month, db); err != nil {
+			return removed, err
+		}
+		removed += n
+	}
+	return removed, nil
+}
+
+// drop detaches, closes and deletes the partition of month. Caller must
+// hold p.mu.
+func (p *sqlitePartitions) drop(ctx context.Context, main *sql.DB, month string, db *sql.DB) error {
+	p.viewMu.Lock()
+	defer p.viewMu.Unlock()
+	if _, err := main.ExecContext(ctx, `DROP VIEW IF EXISTS temp.usage_records`); err != nil {
+		return err
+	}
+	p.stale = true
+	attached, err := attachedPartitions(ctx, main)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(attached, partitionSchema(month)) {
+		if _, err := main.ExecContext(ctx, `DETACH DATABASE `+partitionSchema(month)); err != nil {
+			return fmt.Errorf("failed to detach usage partition %s: %w", month, err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		return fmt.Errorf("failed to close usage partition %s: %w", month, err)
+	}
+	delete(p.dbs, month)
+	path := p.path(month)
+	for _, file := range []string{path, path + "-wal", path + "-shm"} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove usage partition %s: %w", month, err)
+		}
+	}
+	log.Infof("Removed usage partition %s", path)
+	return nil
+}
+
+// each calls fn for every partition, oldest first.
+func (p *sqlitePartitions) each(fn func(month string, db *sql.DB) error) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	months := make([]string, 0, len(p.dbs))
+	for month := range p.dbs {
+		months = append(months, month)
+	}
+	slices.Sort(months)
+	for _, month := range months {
+		if err := fn(month, p.dbs[month]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *sqlitePartitions) close() error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var errs []error
+	for month, db := range p.dbs {
+		if err := db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("usage partition %s: %w", month, err))
+		}
+		delete(p.dbs, month)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,72 @@
+package usage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteBackendMonthlyPartitions(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "usage.db")
+	cfg := BackendConfig{Partition: SQLitePartitionMonth, RetentionDays: 90}
+	b, err := NewSQLiteBackend(path, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// A record written before partitioning stays readable.
+	if err := insertUsageRecordsTx(ctx, b.db, []UsageRecord{{Provider: "claude", Model: "m", RequestedAt: time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC), TotalTokens: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.writeBatch(ctx, []UsageRecord{
+		{Provider: "claude", Model: "m", RequestedAt: time.Date(2026, 8, 10, 0, 0, 0, 0, time.UTC), TotalTokens: 10},
+		{Provider: "claude", Model: "m", RequestedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), TotalTokens: 100},
+		{Provider: "claude", Model: "m", RequestedAt: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC), TotalTokens: 1000},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, month := range []string{"2026-08", "2026-10"} {
+		if _, err := os.Stat(filepath.Join(dir, "usage-"+month+".db")); err != nil {
+			t.Errorf("partition %s: %v", month, err)
+		}
+	}
+	stats, err := b.QueryGlobalStats(ctx, time.Time{})
+	if err != nil || stats.TotalRequests != 4 || stats.TotalTokens != 1111 {
+		t.Fatalf("stats = %+v, %v", stats, err)
+	}
+
+	removed, err := b.Cleanup(ctx, time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC))
+	if err != nil || removed != 3 {
+		t.Fatalf("removed = %d, %v", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "usage-2026-08.db")); !os.IsNotExist(err) {
+		t.Errorf("expired partition still exists: %v", err)
+	}
+	if err := b.Backup(ctx, filepath.Join(dir, "backups", "copy.db")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "backups", "copy-2026-10.db")); err != nil {
+		t.Errorf("partition backup: %v", err)
+	}
+	if err := b.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = NewSQLiteBackend(path, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = b.Stop() })
+	stats, err = b.QueryGlobalStats(ctx, time.Time{})
+	if err != nil || stats.TotalRequests != 1 || stats.TotalTokens != 1000 {
+		t.Fatalf("reopened stats = %+v, %v", stats, err)
+	}
+
+	if _, err := NewSQLiteBackend(filepath.Join(dir, "long.db"), BackendConfig{Partition: SQLitePartitionMonth, RetentionDays: 365}); err == nil {
+		t.Error("retention beyond the partition limit accepted")
+	}
+}
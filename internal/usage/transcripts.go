@@ -0,0 +1,106 @@
+package usage
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+)
+
+const (
+	transcriptDefaultLimit = 50
+	transcriptMaxLimit     = 500
+)
+
+// Transcript is a persisted conversation turn: the client request as sent
+// and the assembled response.
+type Transcript struct {
+	ID          string    `json:"id"`
+	RequestedAt time.Time `json:"requested_at"`
+	APIKey      string    `json:"api_key,omitempty"`
+	Model       string    `json:"model"`
+	// Format is the client API format, e.g. "openai" or "claude".
+	Format string `json:"format"`
+	// Label is the value of the marking header, if it named one.
+	Label  string `json:"label,omitempty"`
+	Stream bool   `json:"stream"`
+	// Request is the client request body.
+	Request json.RawMessage `json:"request"`
+	// Response holds the assembled text, reasoning, tool calls and finish reason.
+	Response json.RawMessage `json:"response"`
+}
+
+// TranscriptQuery filters stored transcripts. Zero fields match everything.
+type TranscriptQuery struct {
+	From   time.Time
+	To     time.Time
+	APIKey string
+	Model  string
+	Label  string
+	// Search matches a substring of the request or response.
+	Search string
+	Limit  int
+}
+
+// build returns the WHERE clause, without the keyword, and its arguments.
+func (q TranscriptQuery) build(d usageQueryDialect) (string, []any) {
+	var where []string
+	var args []any
+	add := func(expr string, vals ...any) {
+		for _, v := range vals {
+			args = append(args, v)
+			expr = strings.Replace(expr, "?", d.placeholder(len(args)), 1)
+		}
+		where = append(where, expr)
+	}
+	if !q.From.IsZero() {
+		add("requested_at >= ?", q.From)
+	}
+	if !q.To.IsZero() {
+		add("requested_at < ?", q.To)
+	}
+	if q.APIKey != "" {
+		add("api_key = ?", q.APIKey)
+	}
+	if q.Model != "" {
+		add("model = ?", q.Model)
+	}
+	if q.Label != "" {
+		add("label = ?", q.Label)
+	}
+	if q.Search != "" {
+		pattern := "%" + likeEscaper.Replace(q.Search) + "%"
+		add(`(request LIKE ? ESCAPE '\' OR response LIKE ? ESCAPE '\')`, pattern, pattern)
+	}
+	if len(where) == 0 {
+		return "1 = 1", args
+	}
+	return strings.Join(where, " AND "), args
+}
+
+// limit returns the row limit, defaulting to 50 and capped at 500.
+func (q TranscriptQuery) limit() int {
+	switch {
+	case q.Limit <= 0:
+		return transcriptDefaultLimit
+	case q.Limit > transcriptMaxLimit:
+		return transcriptMaxLimit
+	}
+	return q.Limit
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+const transcriptColumns = "id, requested_at, api_key, model, format, label, stream, request, response"
+
+// scanTranscript reads a row selected with transcriptColumns.
+func scanTranscript(scan func(dest ...any) error) (Transcript, error) {
+	var t Transcript
+	var request, response string
+	if err := scan(&t.ID, &t.RequestedAt, &t.APIKey, &t.Model, &t.Format, &t.Label, &t.Stream, &request, &response); err != nil {
+		return t, err
+	}
+	t.Request = json.RawMessage(request)
+	t.Response = json.RawMessage(response)
+	return t, nil
+}
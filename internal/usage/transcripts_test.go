@@ -0,0 +1,68 @@
+package usage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteBackendTranscripts(t *testing.T) {
+	b, err := NewSQLiteBackend(filepath.Join(t.TempDir(), "usage.db"), BackendConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = b.Stop() })
+	ctx := context.Background()
+
+	now := time.Now()
+	for _, tr := range []Transcript{
+		{ID: "a", RequestedAt: now.Add(-48 * time.Hour), Model: "m1", Format: "openai", Request: []byte(`{"q":"old"}`), Response: []byte(`{"text":"x"}`)},
+		{ID: "b", RequestedAt: now.Add(-time.Hour), Model: "m1", Format: "claude", Label: "eval", Stream: true, Request: []byte(`{"q":"100% sure"}`), Response: []byte(`{"text":"y"}`)},
+		{ID: "c", RequestedAt: now, Model: "m2", Format: "openai", Request: []byte(`{"q":"new"}`), Response: []byte(`{"text":"needle"}`)},
+	} {
+		if err := b.SaveTranscript(ctx, tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := func(q TranscriptQuery) string {
+		t.Helper()
+		got, err := b.QueryTranscripts(ctx, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := ""
+		for _, tr := range got {
+			s += tr.ID
+		}
+		return s
+	}
+	if got := ids(TranscriptQuery{}); got != "cba" {
+		t.Errorf("all = %q, want newest first", got)
+	}
+	if got := ids(TranscriptQuery{Search: "needle"}); got != "c" {
+		t.Errorf("search response = %q", got)
+	}
+	if got := ids(TranscriptQuery{Search: "0% s"}); got != "b" {
+		t.Errorf("search with %% = %q", got)
+	}
+	if got := ids(TranscriptQuery{Label: "eval", Model: "m1"}); got != "b" {
+		t.Errorf("label filter = %q", got)
+	}
+	if got := ids(TranscriptQuery{From: now.Add(-2 * time.Hour), Limit: 1}); got != "c" {
+		t.Errorf("from + limit = %q", got)
+	}
+
+	tr, err := b.GetTranscript(ctx, "b")
+	if err != nil || tr == nil || !tr.Stream || tr.Label != "eval" || string(tr.Request) != `{"q":"100% sure"}` {
+		t.Fatalf("get = %+v, %v", tr, err)
+	}
+	if tr, err := b.GetTranscript(ctx, "missing"); tr != nil || err != nil {
+		t.Errorf("missing = %+v, %v", tr, err)
+	}
+
+	if n, err := b.CleanupTranscripts(ctx, now.Add(-24*time.Hour)); err != nil || n != 1 {
+		t.Errorf("cleanup = %d, %v", n, err)
+	}
+}
@@ -20,6 +20,18 @@ type Record struct {
 	RequestedAt time.Time
 	Failed      bool
 	Usage       *ir.Usage
+	// RoutePolicy is the routing policy that chose the provider ("cost"), if any.
+	RoutePolicy string
+	// EstimatedSavings is the USD saved against the provider the default order would have used.
+	EstimatedSavings float64
+	// Latency is the time from sending the request to the last byte.
+	Latency time.Duration
+	// TTFT is the time to the first streamed chunk; zero for non-streaming requests.
+	TTFT time.Duration
+	// RequestBytes is the size of the translated request body sent upstream.
+	RequestBytes int64
+	// ResponseBytes is the size of the upstream response body read so far.
+	ResponseBytes int64
 }
 
 // UsageRecord represents a single usage record for persistence.
@@ -41,6 +53,12 @@ type UsageRecord struct {
 	CacheCreationInputTokens int64
 	CacheReadInputTokens     int64
 	ToolUsePromptTokens      int64
+	RoutePolicy              string
+	EstimatedSavings         float64
+	LatencyMs                int64
+	TTFTMs                   int64
+	RequestBytes             int64
+	ResponseBytes            int64
 }
 
 // Plugin consumes usage records emitted by the proxy runtime.
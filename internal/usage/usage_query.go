@@ -0,0 +1,205 @@
+package usage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UsageQueryDimensions are the record columns a usage query can filter and
+// group by.
+var UsageQueryDimensions = []string{"provider", "model", "auth_id", "api_key", "source"}
+
+// UsageQueryMetrics are the aggregates a usage query can select.
+var UsageQueryMetrics = []string{
+	"requests", "success", "failures",
+	"input_tokens", "output_tokens", "reasoning_tokens", "cached_tokens", "total_tokens",
+	"estimated_savings",
+}
+
+// UsageQuery selects aggregated usage over arbitrary time buckets.
+type UsageQuery struct {
+	From, To time.Time
+	// Bucket is the time bucket width. Zero aggregates the whole range.
+	Bucket time.Duration
+	// GroupBy lists dimensions each bucket is split by.
+	GroupBy []string
+	// Filters restricts dimensions to any of the listed values.
+	Filters map[string][]string
+	// Failed restricts records to failed (true) or successful (false) requests.
+	Failed *bool
+	// Metrics lists the aggregates returned for each row.
+	Metrics []string
+}
+
+// UsageQueryRow is one bucket and group of a usage query.
+type UsageQueryRow struct {
+	Time   time.Time
+	Group  map[string]string
+	Values map[string]float64
+}
+
+// Validate checks dimensions and metrics against the allowed names, so they
+// can be used in SQL unquoted.
+func (q *UsageQuery) Validate() error {
+	if !q.To.After(q.From) {
+		return fmt.Errorf("to must be after from")
+	}
+	if q.Bucket < 0 || q.Bucket%time.Second != 0 {
+		return fmt.Errorf("bucket must be a whole number of seconds")
+	}
+	for _, d := range q.GroupBy {
+		if !containsString(UsageQueryDimensions, d) {
+			return fmt.Errorf("unknown group_by dimension %q", d)
+		}
+	}
+	for d := range q.Filters {
+		if !containsString(UsageQueryDimensions, d) {
+			return fmt.Errorf("unknown filter %q", d)
+		}
+	}
+	if len(q.Metrics) == 0 {
+		return fmt.Errorf("at least one metric is required")
+	}
+	for _, m := range q.Metrics {
+		if !containsString(UsageQueryMetrics, m) {
+			return fmt.Errorf("unknown metric %q", m)
+		}
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// usageQueryDialect holds the SQL differences between the backends.
+type usageQueryDialect struct {
+	// placeholder returns the n-th (1-based) bind parameter.
+	placeholder func(n int) string
+	// bucket returns an expression for the bucket start in Unix seconds.
+	bucket     func(seconds int64) string
+	falseValue string
+	floatType  string
+}
+
+var sqliteUsageDialect = usageQueryDialect{
+	placeholder: func(int) string { return "?" },
+	// Timestamps are stored as text in the server's time zone; buckets follow
+	// the local wall clock, like the other SQLite statistics.
+	bucket: func(seconds int64) string {
+		return fmt.Sprintf("CAST(strftime('%%s', SUBSTR(requested_at, 1, 19)) AS INTEGER) / %d * %d", seconds, seconds)
+	},
+	falseValue: "0",
+	floatType:  "REAL",
+}
+
+var postgresUsageDialect = usageQueryDialect{
+	placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+	bucket: func(seconds int64) string {
+		return fmt.Sprintf("FLOOR(EXTRACT(EPOCH FROM requested_at) / %d)::BIGINT * %d", seconds, seconds)
+	},
+	falseValue: "false",
+	floatType:  "DOUBLE PRECISION",
+}
+
+func (d usageQueryDialect) metric(name string) string {
+	var expr string
+	switch name {
+	case "requests":
+		expr = "COUNT(*)"
+	case "success":
+		expr = "SUM(CASE WHEN failed = " + d.falseValue + " THEN 1 ELSE 0 END)"
+	case "failures":
+		expr = "SUM(CASE WHEN failed = " + d.falseValue + " THEN 0 ELSE 1 END)"
+	default:
+		expr = "SUM(" + name + ")"
+	}
+	return "CAST(COALESCE(" + expr + ", 0) AS " + d.floatType + ")"
+}
+
+// build returns the SQL and arguments for a validated query. Selected
+// columns are the bucket (when set), the group-by dimensions, then the metrics.
+func (d usageQueryDialect) build(q UsageQuery, from, to any) (string, []any) {
+	var sel, groups []string
+	if q.Bucket > 0 {
+		sel = append(sel, d.bucket(int64(q.Bucket/time.Second))+" AS bucket")
+		groups = append(groups, "bucket")
+	}
+	for _, dim := range q.GroupBy {
+		sel = append(sel, dim)
+		groups = append(groups, dim)
+	}
+	for _, m := range q.Metrics {
+		sel = append(sel, d.metric(m)+" AS "+m)
+	}
+
+	args := []any{from, to}
+	where := []string{"requested_at >= " + d.placeholder(1), "requested_at < " + d.placeholder(2)}
+	for _, dim := range UsageQueryDimensions {
+		values := q.Filters[dim]
+		if len(values) == 0 {
+			continue
+		}
+		marks := make([]string, len(values))
+		for i, v := range values {
+			args = append(args, v)
+			marks[i] = d.placeholder(len(args))
+		}
+		where = append(where, dim+" IN ("+strings.Join(marks, ", ")+")")
+	}
+	if q.Failed != nil {
+		op := "="
+		if *q.Failed {
+			op = "<>"
+		}
+		where = append(where, "failed "+op+" "+d.falseValue)
+	}
+
+	query := "SELECT " + strings.Join(sel, ", ") + " FROM usage_records WHERE " + strings.Join(where, " AND ")
+	if len(groups) > 0 {
+		query += " GROUP BY " + strings.Join(groups, ", ") + " ORDER BY " + strings.Join(groups, ", ")
+	}
+	return query, args
+}
+
+// scanUsageQueryRow reads one row selected by build.
+func scanUsageQueryRow(q UsageQuery, scan func(dest ...any) error, bucketTime func(int64) time.Time) (UsageQueryRow, error) {
+	var bucket int64
+	group := make([]string, len(q.GroupBy))
+	values := make([]float64, len(q.Metrics))
+	var dest []any
+	if q.Bucket > 0 {
+		dest = append(dest, &bucket)
+	}
+	for i := range group {
+		dest = append(dest, &group[i])
+	}
+	for i := range values {
+		dest = append(dest, &values[i])
+	}
+	if err := scan(dest...); err != nil {
+		return UsageQueryRow{}, err
+	}
+
+	row := UsageQueryRow{Time: q.From, Values: make(map[string]float64, len(values))}
+	if q.Bucket > 0 {
+		row.Time = bucketTime(bucket)
+	}
+	if len(group) > 0 {
+		row.Group = make(map[string]string, len(group))
+		for i, dim := range q.GroupBy {
+			row.Group[dim] = group[i]
+		}
+	}
+	for i, m := range q.Metrics {
+		row.Values[m] = values[i]
+	}
+	return row, nil
+}
@@ -0,0 +1,76 @@
+package usage
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSQLiteQueryUsage(t *testing.T) {
+	b, err := NewSQLiteBackend(filepath.Join(t.TempDir(), "usage.db"), BackendConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = b.Stop() })
+	ctx := context.Background()
+
+	base := time.Date(2026, 10, 15, 10, 0, 0, 0, time.Local)
+	records := []UsageRecord{
+		{Provider: "claude", Model: "sonnet", RequestedAt: base.Add(1 * time.Minute), TotalTokens: 10},
+		{Provider: "claude", Model: "sonnet", RequestedAt: base.Add(7 * time.Minute), TotalTokens: 20, Failed: true},
+		{Provider: "openai", Model: "gpt", RequestedAt: base.Add(8 * time.Minute), TotalTokens: 40},
+		{Provider: "claude", Model: "opus", RequestedAt: base.Add(2 * time.Hour), TotalTokens: 80},
+	}
+	if err := b.writeBatch(ctx, records); err != nil {
+		t.Fatal(err)
+	}
+
+	q := UsageQuery{
+		From:    base,
+		To:      base.Add(time.Hour),
+		Bucket:  5 * time.Minute,
+		GroupBy: []string{"provider"},
+		Metrics: []string{"requests", "failures", "total_tokens"},
+	}
+	if err := q.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := b.QueryUsage(ctx, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range rows {
+		got = append(got, r.Time.Format("15:04")+" "+r.Group["provider"]+" "+
+			strings.Join([]string{ftoa(r.Values["requests"]), ftoa(r.Values["failures"]), ftoa(r.Values["total_tokens"])}, "/"))
+	}
+	want := []string{"10:00 claude 1/0/10", "10:05 claude 1/1/20", "10:05 openai 1/0/40"}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("rows = %v, want %v", got, want)
+	}
+
+	failed := false
+	q = UsageQuery{
+		From:    base,
+		To:      base.Add(3 * time.Hour),
+		Filters: map[string][]string{"provider": {"claude"}},
+		Failed:  &failed,
+		Metrics: []string{"total_tokens"},
+	}
+	rows, err = b.QueryUsage(ctx, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Values["total_tokens"] != 90 {
+		t.Errorf("filtered rows = %+v", rows)
+	}
+
+	if err := (&UsageQuery{From: base, To: base.Add(time.Hour), Metrics: []string{"1; DROP TABLE usage_records"}}).Validate(); err == nil {
+		t.Error("unknown metric accepted")
+	}
+}
+
+func ftoa(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
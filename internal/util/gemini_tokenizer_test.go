@@ -1,6 +1,8 @@
 package util
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/nghyane/llm-mux/internal/translator/ir"
@@ -380,6 +382,52 @@ func TestCountTokensFromIR_ToolResultWithFiles(t *testing.T) {
 	t.Logf("IR with tool result files token count: %d", count)
 }
 
+func TestCountTokensFromIR_PrefixCache(t *testing.T) {
+	turn := func(i int) []ir.Message {
+		return []ir.Message{
+			{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: fmt.Sprintf("Step %d: read the file and fix the bug", i)}}},
+			{Role: ir.RoleAssistant, ToolCalls: []ir.ToolCall{{ID: fmt.Sprintf("call_%d", i), Name: "read_file", Args: `{"path":"main.go"}`}}},
+		}
+	}
+	var history []ir.Message
+	for i := range 3 {
+		history = append(history, turn(i)...)
+	}
+	count := func(msgs []ir.Message) int64 {
+		return CountTokensFromIR("claude-sonnet-4-5", &ir.UnifiedChatRequest{Messages: msgs})
+	}
+	uncached := func(msgs []ir.Message) int64 {
+		enc, _ := getTiktokenCodec(getTiktokenEncodingName("claude-sonnet-4-5"))
+		var sb strings.Builder
+		var n int64
+		for i := range msgs {
+			n += countMessageTokens(enc, &msgs[i], &sb)
+		}
+		return n + 3
+	}
+
+	first := count(history)
+	if want := uncached(history); first != want {
+		t.Fatalf("first count = %d, want %d", first, want)
+	}
+	hashes := messagePrefixHashes(string(getTiktokenEncodingName("claude-sonnet-4-5")), history)
+	if _, ok := PrefixTokenCache.getHash(hashes[len(hashes)-1]); !ok {
+		t.Fatal("conversation prefix not cached")
+	}
+
+	grown := append(append([]ir.Message(nil), history...), turn(3)...)
+	if got, want := count(grown), uncached(grown); got != want {
+		t.Errorf("grown count = %d, want %d", got, want)
+	}
+
+	// Editing an earlier message must not reuse the old prefix count.
+	edited := append([]ir.Message(nil), grown...)
+	edited[0] = ir.Message{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: strings.Repeat("much longer first message ", 20)}}}
+	if got, want := count(edited), uncached(edited); got != want {
+		t.Errorf("edited count = %d, want %d", got, want)
+	}
+}
+
 // =============================================================================
 // Model Normalization Tests
 // =============================================================================
@@ -3,15 +3,25 @@ package util
 import (
 	"net/http"
 	"strings"
+	"time"
 )
 
 // ApplyCustomHeadersFromAttrs applies user-defined headers stored in the provided attributes map.
-// Custom headers override built-in defaults when conflicts occur.
+// Custom headers override built-in defaults when conflicts occur. Header values
+// may contain {{...}} placeholders rendered per request, e.g. a timestamp or an
+// HMAC of the body keyed with the provider's signing-secret.
 func ApplyCustomHeadersFromAttrs(r *http.Request, attrs map[string]string) {
 	if r == nil {
 		return
 	}
-	applyCustomHeaders(r, extractCustomHeaders(attrs))
+	headers := extractCustomHeaders(attrs)
+	if len(headers) > 0 {
+		tmpl := &headerTemplate{req: r, secret: attrs[signingSecretAttr], now: time.Now()}
+		for k, v := range headers {
+			headers[k] = tmpl.render(v)
+		}
+	}
+	applyCustomHeaders(r, headers)
 }
 
 func extractCustomHeaders(attrs map[string]string) map[string]string {
@@ -0,0 +1,112 @@
+package util
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// signingSecretAttr is the auth attribute holding the provider's signing-secret.
+const signingSecretAttr = "signing_secret"
+
+// headerTemplate holds the values interpolated into custom header templates.
+// One is built per request so every header sees the same timestamp and ID.
+type headerTemplate struct {
+	req    *http.Request
+	secret string
+	now    time.Time
+	id     string
+	body   []byte
+	read   bool
+}
+
+// render replaces {{name}} placeholders in value. Unknown placeholders are
+// left as-is.
+//
+//	{{timestamp}}     Unix time in seconds
+//	{{timestamp_ms}}  Unix time in milliseconds
+//	{{request_id}}    random UUID, shared by all headers of the request
+//	{{method}}        HTTP method
+//	{{path}}          URL path
+//	{{body_sha256}}   hex SHA-256 of the request body
+//	{{hmac_sha256}}   hex HMAC-SHA256 of the request body keyed with signing-secret
+func (t *headerTemplate) render(value string) string {
+	if !strings.Contains(value, "{{") {
+		return value
+	}
+	var b strings.Builder
+	for {
+		start := strings.Index(value, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(value[start+2:], "}}")
+		if end < 0 {
+			break
+		}
+		name := value[start+2 : start+2+end]
+		b.WriteString(value[:start])
+		if v, ok := t.lookup(strings.TrimSpace(name)); ok {
+			b.WriteString(v)
+		} else {
+			b.WriteString(value[start : start+4+end])
+		}
+		value = value[start+4+end:]
+	}
+	b.WriteString(value)
+	return b.String()
+}
+
+func (t *headerTemplate) lookup(name string) (string, bool) {
+	switch name {
+	case "timestamp":
+		return strconv.FormatInt(t.now.Unix(), 10), true
+	case "timestamp_ms":
+		return strconv.FormatInt(t.now.UnixMilli(), 10), true
+	case "request_id":
+		if t.id == "" {
+			t.id = uuid.New().String()
+		}
+		return t.id, true
+	case "method":
+		return t.req.Method, true
+	case "path":
+		return t.req.URL.Path, true
+	case "body_sha256":
+		sum := sha256.Sum256(t.requestBody())
+		return hex.EncodeToString(sum[:]), true
+	case "hmac_sha256":
+		mac := hmac.New(sha256.New, []byte(t.secret))
+		mac.Write(t.requestBody())
+		return hex.EncodeToString(mac.Sum(nil)), true
+	}
+	return "", false
+}
+
+// requestBody returns the request body without consuming it.
+func (t *headerTemplate) requestBody() []byte {
+	if t.read {
+		return t.body
+	}
+	t.read = true
+	switch {
+	case t.req.GetBody != nil:
+		if rc, err := t.req.GetBody(); err == nil {
+			t.body, _ = io.ReadAll(rc)
+			_ = rc.Close()
+		}
+	case t.req.Body != nil && t.req.Body != http.NoBody:
+		t.body, _ = io.ReadAll(t.req.Body)
+		_ = t.req.Body.Close()
+		t.req.Body = io.NopCloser(bytes.NewReader(t.body))
+	}
+	return t.body
+}
@@ -0,0 +1,48 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestApplyCustomHeadersTemplates(t *testing.T) {
+	body := `{"model":"gpt-4o"}`
+	req, _ := http.NewRequest(http.MethodPost, "https://gateway.example/v1/chat/completions", strings.NewReader(body))
+	attrs := map[string]string{
+		"header:X-Tenant":    "acme",
+		"header:X-Signature": "t={{timestamp}},v1={{hmac_sha256}}",
+		"header:X-Trace":     "{{request_id}}",
+		"header:X-Echo":      "{{request_id}}",
+		"header:X-Path":      "{{method}} {{path}}",
+		"header:X-Unknown":   "{{nope}}",
+		"signing_secret":     "s3cret",
+	}
+	ApplyCustomHeadersFromAttrs(req, attrs)
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(body))
+	sig := req.Header.Get("X-Signature")
+	if !strings.HasPrefix(sig, "t=") || !strings.HasSuffix(sig, ",v1="+hex.EncodeToString(mac.Sum(nil))) {
+		t.Errorf("X-Signature = %q", sig)
+	}
+	if id := req.Header.Get("X-Trace"); len(id) != 36 || id != req.Header.Get("X-Echo") {
+		t.Errorf("request_id not shared across headers: %q vs %q", id, req.Header.Get("X-Echo"))
+	}
+	if got := req.Header.Get("X-Path"); got != "POST /v1/chat/completions" {
+		t.Errorf("X-Path = %q", got)
+	}
+	if got := req.Header.Get("X-Unknown"); got != "{{nope}}" {
+		t.Errorf("X-Unknown = %q", got)
+	}
+	if got := req.Header.Get("X-Tenant"); got != "acme" {
+		t.Errorf("X-Tenant = %q", got)
+	}
+	if sent, _ := io.ReadAll(req.Body); string(sent) != body {
+		t.Errorf("body consumed: %q", sent)
+	}
+}
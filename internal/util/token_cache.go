@@ -1,9 +1,12 @@
 package util
 
 import (
+	"encoding/binary"
 	"hash"
 	"hash/fnv"
 	"sync"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
 )
 
 const (
@@ -32,6 +35,10 @@ var (
 	ToolTokenCache        = NewTokenCache()
 	InstructionTokenCache = NewTokenCache()
 	ContentTokenCache     = NewTokenCache()
+
+	// PrefixTokenCache maps hashes of conversation prefixes, as returned by
+	// messagePrefixHashes, to their message token counts.
+	PrefixTokenCache = NewTokenCache()
 )
 
 func NewTokenCache() *TokenCache {
@@ -54,7 +61,14 @@ func hashContent(s string) uint64 {
 }
 
 func (tc *TokenCache) Get(content string) (int, bool) {
-	hash := hashContent(content)
+	return tc.getHash(hashContent(content))
+}
+
+func (tc *TokenCache) Set(content string, tokens int) {
+	tc.setHash(hashContent(content), tokens)
+}
+
+func (tc *TokenCache) getHash(hash uint64) (int, bool) {
 	shard := tc.shards[hash%numShards]
 
 	shard.mu.RLock()
@@ -68,8 +82,7 @@ func (tc *TokenCache) Get(content string) (int, bool) {
 	return 0, false
 }
 
-func (tc *TokenCache) Set(content string, tokens int) {
-	hash := hashContent(content)
+func (tc *TokenCache) setHash(hash uint64, tokens int) {
 	shard := tc.shards[hash%numShards]
 
 	shard.mu.Lock()
@@ -88,3 +101,88 @@ func (tc *TokenCache) Set(content string, tokens int) {
 
 	shard.entries = append(shard.entries, tokenCacheEntry{hash: hash, tokens: tokens})
 }
+
+// messagePrefixHashes returns, for each i, a hash of msgs[:i+1] and of
+// everything token counting depends on in them, seeded with the encoding.
+func messagePrefixHashes(encoding string, msgs []ir.Message) []uint64 {
+	h := hasherPool.Get().(hash.Hash64)
+	defer hasherPool.Put(h)
+	h.Reset()
+	w := prefixHashWriter{h: h}
+	w.str(encoding)
+
+	hashes := make([]uint64, len(msgs))
+	for i := range msgs {
+		msg := &msgs[i]
+		w.str(string(msg.Role))
+		w.num(len(msg.Content))
+		for j := range msg.Content {
+			part := &msg.Content[j]
+			w.str(string(part.Type))
+			w.str(part.Text)
+			w.str(part.Reasoning)
+			w.bytes(part.ThoughtSignature)
+			w.str(part.RedactedData)
+			w.flag(part.Image != nil)
+			w.flag(part.Video != nil)
+			if part.File != nil {
+				w.str(part.File.FileData)
+				w.str(part.File.FileURL)
+				w.str(part.File.FileID)
+			}
+			w.flag(part.Audio != nil)
+			if part.Audio != nil {
+				w.str(part.Audio.Transcript)
+			}
+			if part.CodeExecution != nil {
+				w.str(part.CodeExecution.Code)
+				w.str(part.CodeExecution.Output)
+			}
+			if part.ToolResult != nil {
+				w.str(part.ToolResult.ToolCallID)
+				w.str(part.ToolResult.Result)
+				w.num(len(part.ToolResult.Images))
+				w.num(len(part.ToolResult.Files))
+			}
+		}
+		w.num(len(msg.ToolCalls))
+		for j := range msg.ToolCalls {
+			tc := &msg.ToolCalls[j]
+			w.str(tc.Name)
+			w.str(tc.Args)
+			w.bytes(tc.ThoughtSignature)
+		}
+		hashes[i] = h.Sum64()
+	}
+	return hashes
+}
+
+// prefixHashWriter writes length-prefixed fields so that adjacent fields
+// cannot run into each other.
+type prefixHashWriter struct {
+	h   hash.Hash64
+	buf [8]byte
+}
+
+func (w *prefixHashWriter) num(n int) {
+	binary.LittleEndian.PutUint64(w.buf[:], uint64(n))
+	w.h.Write(w.buf[:])
+}
+
+func (w *prefixHashWriter) str(s string) {
+	w.num(len(s))
+	w.h.Write([]byte(s))
+}
+
+func (w *prefixHashWriter) bytes(b []byte) {
+	w.num(len(b))
+	w.h.Write(b)
+}
+
+func (w *prefixHashWriter) flag(v bool) {
+	if v {
+		w.num(1)
+	} else {
+		w.num(0)
+	}
+}
@@ -75,247 +75,277 @@ func CountTiktokenTokens(model string, req *ir.UnifiedChatRequest) int64 {
 		}
 	}
 
+	totalTokens += countMessagesTokens(enc, encodingName, req.Messages)
+
+	totalTokens += countToolDefinitionsTokens(enc, req.Tools)
+
+	if req.ResponseSchema != nil {
+		if data, err := json.Marshal(req.ResponseSchema); err == nil {
+			totalTokens += countJSONDataTokens(enc, data, ToolTokenCache)
+		}
+	}
+
+	if len(req.MCPServers) > 0 {
+		if data, err := json.Marshal(req.MCPServers); err == nil {
+			totalTokens += countJSONDataTokens(enc, data, ToolTokenCache)
+		}
+	}
+
+	if req.Prediction != nil && req.Prediction.Content != "" {
+		if len(req.Prediction.Content) > TokenEstimationThreshold {
+			totalTokens += estimateTokens(req.Prediction.Content)
+		} else {
+			totalTokens += countTokens(enc, req.Prediction.Content)
+		}
+	}
+
+	totalTokens += 3
+
+	return totalTokens
+}
+
+// countMessagesTokens counts the tokens of msgs, reusing the count of the
+// longest prefix of them counted before, so each turn of a growing
+// conversation only tokenizes the messages added since the previous turn.
+func countMessagesTokens(enc tokenizer.Codec, encoding tokenizer.Encoding, msgs []ir.Message) int64 {
+	if len(msgs) == 0 {
+		return 0
+	}
+	hashes := messagePrefixHashes(string(encoding), msgs)
+	start, totalTokens := 0, int64(0)
+	for i := len(hashes); i > 0; i-- {
+		if cached, ok := PrefixTokenCache.getHash(hashes[i-1]); ok {
+			start, totalTokens = i, int64(cached)
+			break
+		}
+	}
+	if start == len(msgs) {
+		return totalTokens
+	}
+
 	sb := acquireBuilder()
 	defer releaseBuilder(sb)
+	for i := start; i < len(msgs); i++ {
+		totalTokens += countMessageTokens(enc, &msgs[i], sb)
+	}
+	PrefixTokenCache.setHash(hashes[len(hashes)-1], int(totalTokens))
+	return totalTokens
+}
+
+// countMessageTokens counts the tokens of one message, including its role
+// and per-message overhead. sb is scratch space.
+func countMessageTokens(enc tokenizer.Codec, msg *ir.Message, sb *strings.Builder) int64 {
+	const tokensPerMessage int64 = 3
 
-	for i := range req.Messages {
-		msg := &req.Messages[i]
-		totalTokens += tokensPerMessage
-		totalTokens += countRoleTokens(enc, string(msg.Role))
-
-		sb.Reset()
-		hasContentToCount := false
-
-		// Process content parts
-		// We stream parts into the tokenizer buffer, but switch to direct estimation
-		// for large chunks to avoid memory spikes and tokenizer overhead.
-		for j := range msg.Content {
-			part := &msg.Content[j]
-			switch part.Type {
-			case ir.ContentTypeText:
-				if len(part.Text) > TokenEstimationThreshold {
+	totalTokens := tokensPerMessage
+	totalTokens += countRoleTokens(enc, string(msg.Role))
+
+	sb.Reset()
+	hasContentToCount := false
+
+	// Process content parts
+	// We stream parts into the tokenizer buffer, but switch to direct estimation
+	// for large chunks to avoid memory spikes and tokenizer overhead.
+	for j := range msg.Content {
+		part := &msg.Content[j]
+		switch part.Type {
+		case ir.ContentTypeText:
+			if len(part.Text) > TokenEstimationThreshold {
+				if sb.Len() > 0 {
+					totalTokens += countTokensWithCache(enc, sb.String(), ContentTokenCache)
+					sb.Reset()
+				}
+				// Estimate large text directly
+				totalTokens += estimateTokens(part.Text)
+				hasContentToCount = false
+			} else if part.Text != "" {
+				sb.WriteString(part.Text)
+				hasContentToCount = true
+			}
+
+		case ir.ContentTypeReasoning:
+			if len(part.Reasoning) > TokenEstimationThreshold {
+				if sb.Len() > 0 {
+					totalTokens += countTokensWithCache(enc, sb.String(), ContentTokenCache)
+					sb.Reset()
+				}
+				totalTokens += estimateTokens(part.Reasoning)
+				hasContentToCount = false
+			} else if part.Reasoning != "" {
+				sb.WriteString(part.Reasoning)
+				hasContentToCount = true
+			}
+			if len(part.ThoughtSignature) > 0 {
+				sb.Write(part.ThoughtSignature)
+				hasContentToCount = true
+			}
+
+		case ir.ContentTypeCodeResult:
+			if part.CodeExecution != nil && part.CodeExecution.Output != "" {
+				if len(part.CodeExecution.Output) > TokenEstimationThreshold {
 					if sb.Len() > 0 {
 						totalTokens += countTokensWithCache(enc, sb.String(), ContentTokenCache)
 						sb.Reset()
 					}
-					// Estimate large text directly
-					totalTokens += estimateTokens(part.Text)
+					totalTokens += estimateTokens(part.CodeExecution.Output)
 					hasContentToCount = false
-				} else if part.Text != "" {
-					sb.WriteString(part.Text)
+				} else {
+					sb.WriteString(part.CodeExecution.Output)
 					hasContentToCount = true
 				}
+			}
 
-			case ir.ContentTypeReasoning:
-				if len(part.Reasoning) > TokenEstimationThreshold {
+		case ir.ContentTypeExecutableCode:
+			if part.CodeExecution != nil && part.CodeExecution.Code != "" {
+				if len(part.CodeExecution.Code) > TokenEstimationThreshold {
 					if sb.Len() > 0 {
 						totalTokens += countTokensWithCache(enc, sb.String(), ContentTokenCache)
 						sb.Reset()
 					}
-					totalTokens += estimateTokens(part.Reasoning)
+					totalTokens += estimateTokens(part.CodeExecution.Code)
 					hasContentToCount = false
-				} else if part.Reasoning != "" {
-					sb.WriteString(part.Reasoning)
-					hasContentToCount = true
-				}
-				if len(part.ThoughtSignature) > 0 {
-					sb.Write(part.ThoughtSignature)
+				} else {
+					sb.WriteString(part.CodeExecution.Code)
 					hasContentToCount = true
 				}
+			}
 
-			case ir.ContentTypeCodeResult:
-				if part.CodeExecution != nil && part.CodeExecution.Output != "" {
-					if len(part.CodeExecution.Output) > TokenEstimationThreshold {
-						if sb.Len() > 0 {
-							totalTokens += countTokensWithCache(enc, sb.String(), ContentTokenCache)
-							sb.Reset()
-						}
-						totalTokens += estimateTokens(part.CodeExecution.Output)
-						hasContentToCount = false
-					} else {
-						sb.WriteString(part.CodeExecution.Output)
-						hasContentToCount = true
-					}
-				}
+		case ir.ContentTypeImage:
+			if part.Image != nil {
+				totalTokens += ImageTokenCostTiktoken
+			}
 
-			case ir.ContentTypeExecutableCode:
-				if part.CodeExecution != nil && part.CodeExecution.Code != "" {
-					if len(part.CodeExecution.Code) > TokenEstimationThreshold {
+		case ir.ContentTypeFile:
+			if part.File != nil {
+				if part.File.FileData != "" {
+					if len(part.File.FileData) > TokenEstimationThreshold {
 						if sb.Len() > 0 {
 							totalTokens += countTokensWithCache(enc, sb.String(), ContentTokenCache)
 							sb.Reset()
 						}
-						totalTokens += estimateTokens(part.CodeExecution.Code)
+						totalTokens += estimateTokens(part.File.FileData)
 						hasContentToCount = false
 					} else {
-						sb.WriteString(part.CodeExecution.Code)
+						sb.WriteString(part.File.FileData)
 						hasContentToCount = true
 					}
+				} else if part.File.FileURL != "" || part.File.FileID != "" {
+					totalTokens += DocTokenCostTiktoken
 				}
+			}
 
-			case ir.ContentTypeImage:
-				if part.Image != nil {
-					totalTokens += ImageTokenCostTiktoken
-				}
-
-			case ir.ContentTypeFile:
-				if part.File != nil {
-					if part.File.FileData != "" {
-						if len(part.File.FileData) > TokenEstimationThreshold {
-							if sb.Len() > 0 {
-								totalTokens += countTokensWithCache(enc, sb.String(), ContentTokenCache)
-								sb.Reset()
-							}
-							totalTokens += estimateTokens(part.File.FileData)
-							hasContentToCount = false
-						} else {
-							sb.WriteString(part.File.FileData)
-							hasContentToCount = true
-						}
-					} else if part.File.FileURL != "" || part.File.FileID != "" {
-						totalTokens += DocTokenCostTiktoken
-					}
-				}
-
-			case ir.ContentTypeAudio:
-				if part.Audio != nil {
-					if part.Audio.Transcript != "" {
-						sb.WriteString(part.Audio.Transcript)
-						hasContentToCount = true
-					}
-					totalTokens += AudioTokenCostTiktoken
+		case ir.ContentTypeAudio:
+			if part.Audio != nil {
+				if part.Audio.Transcript != "" {
+					sb.WriteString(part.Audio.Transcript)
+					hasContentToCount = true
 				}
+				totalTokens += AudioTokenCostTiktoken
+			}
 
-			case ir.ContentTypeVideo:
-				if part.Video != nil {
-					totalTokens += VideoTokenCostTiktoken
-				}
+		case ir.ContentTypeVideo:
+			if part.Video != nil {
+				totalTokens += VideoTokenCostTiktoken
+			}
 
-			case ir.ContentTypeToolResult:
-				if part.ToolResult != nil {
-					// Tool result formatting involves mixed content, handle carefully
-					// Simplified: just estimate if result is huge
-					if len(part.ToolResult.Result) > TokenEstimationThreshold {
-						if sb.Len() > 0 {
-							totalTokens += countTokensWithCache(enc, sb.String(), ContentTokenCache)
-							sb.Reset()
-						}
-						sb.WriteString("\nTool ")
-						sb.WriteString(part.ToolResult.ToolCallID)
-						sb.WriteString(" result: ")
-						// Flush header
-						headerStr := sb.String()
-						if cached, ok := ContentTokenCache.Get(headerStr); ok {
-							totalTokens += int64(cached)
-						} else {
-							tokens := countTokens(enc, headerStr)
-							ContentTokenCache.Set(headerStr, int(tokens))
-							totalTokens += tokens
-						}
+		case ir.ContentTypeToolResult:
+			if part.ToolResult != nil {
+				// Tool result formatting involves mixed content, handle carefully
+				// Simplified: just estimate if result is huge
+				if len(part.ToolResult.Result) > TokenEstimationThreshold {
+					if sb.Len() > 0 {
+						totalTokens += countTokensWithCache(enc, sb.String(), ContentTokenCache)
 						sb.Reset()
-
-						totalTokens += estimateTokens(part.ToolResult.Result)
-						hasContentToCount = false
+					}
+					sb.WriteString("\nTool ")
+					sb.WriteString(part.ToolResult.ToolCallID)
+					sb.WriteString(" result: ")
+					// Flush header
+					headerStr := sb.String()
+					if cached, ok := ContentTokenCache.Get(headerStr); ok {
+						totalTokens += int64(cached)
 					} else {
-						sb.WriteString("\nTool ")
-						sb.WriteString(part.ToolResult.ToolCallID)
-						sb.WriteString(" result: ")
-						sb.WriteString(part.ToolResult.Result)
-						hasContentToCount = true
+						tokens := countTokens(enc, headerStr)
+						ContentTokenCache.Set(headerStr, int(tokens))
+						totalTokens += tokens
 					}
-					totalTokens += int64(len(part.ToolResult.Images) * ImageTokenCostTiktoken)
-					totalTokens += int64(len(part.ToolResult.Files) * DocTokenCostTiktoken)
-				}
-
-			case ir.ContentTypeRedactedThinking:
-				// Estimate tokens for encrypted binary data
-				if len(part.RedactedData) > 0 {
-					totalTokens += int64(len(part.RedactedData) / 4)
-				}
-			}
-		}
+					sb.Reset()
 
-		// Process tool calls
-		for j := range msg.ToolCalls {
-			tc := &msg.ToolCalls[j]
-			sb.WriteString("\nCall tool ")
-			sb.WriteString(tc.Name)
-			sb.WriteByte('(')
-			if len(tc.Args) > TokenEstimationThreshold {
-				// Flush prefix
-				prefixStr := sb.String()
-				if cached, ok := ContentTokenCache.Get(prefixStr); ok {
-					totalTokens += int64(cached)
-				} else {
-					tokens := countTokens(enc, prefixStr)
-					ContentTokenCache.Set(prefixStr, int(tokens))
-					totalTokens += tokens
-				}
-				sb.Reset()
-
-				totalTokens += estimateTokens(tc.Args)
-				sb.WriteByte(')')
-				// Flush suffix
-				suffixStr := sb.String()
-				if cached, ok := ContentTokenCache.Get(suffixStr); ok {
-					totalTokens += int64(cached)
+					totalTokens += estimateTokens(part.ToolResult.Result)
+					hasContentToCount = false
 				} else {
-					tokens := countTokens(enc, suffixStr)
-					ContentTokenCache.Set(suffixStr, int(tokens))
-					totalTokens += tokens
+					sb.WriteString("\nTool ")
+					sb.WriteString(part.ToolResult.ToolCallID)
+					sb.WriteString(" result: ")
+					sb.WriteString(part.ToolResult.Result)
+					hasContentToCount = true
 				}
-				sb.Reset()
-				hasContentToCount = false
-			} else {
-				sb.WriteString(tc.Args)
-				sb.WriteByte(')')
-				hasContentToCount = true
+				totalTokens += int64(len(part.ToolResult.Images) * ImageTokenCostTiktoken)
+				totalTokens += int64(len(part.ToolResult.Files) * DocTokenCostTiktoken)
 			}
-			// ThoughtSignature in tool calls
-			if len(tc.ThoughtSignature) > 0 {
-				sb.Write(tc.ThoughtSignature)
-				hasContentToCount = true
+
+		case ir.ContentTypeRedactedThinking:
+			// Estimate tokens for encrypted binary data
+			if len(part.RedactedData) > 0 {
+				totalTokens += int64(len(part.RedactedData) / 4)
 			}
 		}
+	}
 
-		// Final flush for this message
-		if hasContentToCount && sb.Len() > 0 {
-			contentStr := sb.String()
-			if cached, ok := ContentTokenCache.Get(contentStr); ok {
+	// Process tool calls
+	for j := range msg.ToolCalls {
+		tc := &msg.ToolCalls[j]
+		sb.WriteString("\nCall tool ")
+		sb.WriteString(tc.Name)
+		sb.WriteByte('(')
+		if len(tc.Args) > TokenEstimationThreshold {
+			// Flush prefix
+			prefixStr := sb.String()
+			if cached, ok := ContentTokenCache.Get(prefixStr); ok {
 				totalTokens += int64(cached)
 			} else {
-				tokens := countTokens(enc, contentStr)
-				ContentTokenCache.Set(contentStr, int(tokens))
+				tokens := countTokens(enc, prefixStr)
+				ContentTokenCache.Set(prefixStr, int(tokens))
 				totalTokens += tokens
 			}
-		}
+			sb.Reset()
 
-	}
-
-	totalTokens += countToolDefinitionsTokens(enc, req.Tools)
-
-	if req.ResponseSchema != nil {
-		if data, err := json.Marshal(req.ResponseSchema); err == nil {
-			totalTokens += countJSONDataTokens(enc, data, ToolTokenCache)
+			totalTokens += estimateTokens(tc.Args)
+			sb.WriteByte(')')
+			// Flush suffix
+			suffixStr := sb.String()
+			if cached, ok := ContentTokenCache.Get(suffixStr); ok {
+				totalTokens += int64(cached)
+			} else {
+				tokens := countTokens(enc, suffixStr)
+				ContentTokenCache.Set(suffixStr, int(tokens))
+				totalTokens += tokens
+			}
+			sb.Reset()
+			hasContentToCount = false
+		} else {
+			sb.WriteString(tc.Args)
+			sb.WriteByte(')')
+			hasContentToCount = true
 		}
-	}
-
-	if len(req.MCPServers) > 0 {
-		if data, err := json.Marshal(req.MCPServers); err == nil {
-			totalTokens += countJSONDataTokens(enc, data, ToolTokenCache)
+		// ThoughtSignature in tool calls
+		if len(tc.ThoughtSignature) > 0 {
+			sb.Write(tc.ThoughtSignature)
+			hasContentToCount = true
 		}
 	}
 
-	if req.Prediction != nil && req.Prediction.Content != "" {
-		if len(req.Prediction.Content) > TokenEstimationThreshold {
-			totalTokens += estimateTokens(req.Prediction.Content)
+	// Final flush for this message
+	if hasContentToCount && sb.Len() > 0 {
+		contentStr := sb.String()
+		if cached, ok := ContentTokenCache.Get(contentStr); ok {
+			totalTokens += int64(cached)
 		} else {
-			totalTokens += countTokens(enc, req.Prediction.Content)
+			tokens := countTokens(enc, contentStr)
+			ContentTokenCache.Set(contentStr, int(tokens))
+			totalTokens += tokens
 		}
 	}
-
-	totalTokens += 3
-
 	return totalTokens
 }
 
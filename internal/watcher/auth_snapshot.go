@@ -27,7 +27,7 @@ func computeProviderModelsHash(models []config.ProviderModel) string {
 	return hex.EncodeToString(sum[:])
 }
 
-func createProviderAuth(idGen *stableIDGenerator, providerName, label, key, baseURL, proxyURL string, headers map[string]string, models []config.ProviderModel, excludedModels []string, cfg *config.Config, now time.Time) *provider.Auth {
+func createProviderAuth(idGen *stableIDGenerator, providerName, label, key, baseURL, proxyURL string, headers map[string]string, signingSecret string, models []config.ProviderModel, excludedModels []string, cfg *config.Config, now time.Time) *provider.Auth {
 	idKind := fmt.Sprintf("%s:apikey", providerName)
 	id, token := idGen.next(idKind, key, baseURL, proxyURL)
 	attrs := map[string]string{
@@ -41,6 +41,9 @@ func createProviderAuth(idGen *stableIDGenerator, providerName, label, key, base
 		attrs["models_hash"] = hash
 	}
 	addConfigHeadersToAttrs(headers, attrs)
+	if signingSecret != "" {
+		attrs["signing_secret"] = signingSecret
+	}
 	a := &provider.Auth{
 		ID:         id,
 		Provider:   providerName,
@@ -74,13 +77,16 @@ func (w *Watcher) SnapshotCoreAuths() []*provider.Auth {
 			case config.ProviderTypeAnthropic:
 				pName = "claude"
 				lbl = "claude-apikey"
-			case config.ProviderTypeOpenAI:
+			case config.ProviderTypeOpenAI, config.ProviderTypeLlamaCpp, config.ProviderTypeVLLM:
 				displayName := prov.GetDisplayName()
 				pName = strings.ToLower(displayName)
 				lbl = displayName
 			case config.ProviderTypeVertexCompat:
 				pName = "vertex"
 				lbl = "vertex-apikey"
+			case config.ProviderTypeMock:
+				pName = "mock"
+				lbl = prov.GetDisplayName()
 			default:
 				continue
 			}
@@ -93,7 +99,7 @@ func (w *Watcher) SnapshotCoreAuths() []*provider.Auth {
 				if proxy == "" {
 					proxy = strings.TrimSpace(prov.ProxyURL)
 				}
-				auth := createProviderAuth(idGen, pName, lbl, key, strings.TrimSpace(prov.BaseURL), proxy, prov.Headers, prov.Models, prov.ExcludedModels, cfg, now)
+				auth := createProviderAuth(idGen, pName, lbl, key, strings.TrimSpace(prov.BaseURL), proxy, prov.Headers, prov.SigningSecret, prov.Models, prov.ExcludedModels, cfg, now)
 				out = append(out, auth)
 			}
 		}
@@ -94,6 +94,9 @@ func (w *Watcher) reloadClients(rescanAuth bool, affectedOAuthProviders []string
 	}
 
 	totalNewClients := authFileCount + geminiAPIKeyCount + vertexCompatAPIKeyCount + claudeAPIKeyCount + codexAPIKeyCount + openAICompatCount
+	w.clientsMutex.Lock()
+	w.lastClientCount = totalNewClients
+	w.clientsMutex.Unlock()
 
 	// Ensure consumers observe the new configuration before auth updates dispatch.
 	if w.reloadCallback != nil {
@@ -237,7 +240,7 @@ func BuildAPIKeyClients(cfg *config.Config) (int, int, int, int, int) {
 			} else {
 				claudeAPIKeyCount += keyCount
 			}
-		case config.ProviderTypeOpenAI:
+		case config.ProviderTypeOpenAI, config.ProviderTypeLlamaCpp, config.ProviderTypeVLLM:
 			openAICompatCount += keyCount
 		case config.ProviderTypeVertexCompat:
 			vertexCompatAPIKeyCount += keyCount
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"os"
 	"strings"
 	"time"
@@ -77,7 +78,7 @@ func (w *Watcher) reloadConfig() bool {
 
 	newConfig, errLoadConfig := config.LoadConfig(w.configPath)
 	if errLoadConfig != nil {
-		log.Errorf("failed to reload config: %v", errLoadConfig)
+		w.recordReloadFailure(fmt.Errorf("failed to load config: %w", errLoadConfig))
 		return false
 	}
 
@@ -91,6 +92,11 @@ func (w *Watcher) reloadConfig() bool {
 		}
 	}
 
+	if errSafety := w.checkReloadSafety(newConfig); errSafety != nil {
+		w.recordReloadFailure(errSafety)
+		return false
+	}
+
 	w.clientsMutex.Lock()
 	var oldConfig *config.Config
 	_ = yaml.Unmarshal(w.oldConfigYaml, &oldConfig)
@@ -128,6 +134,7 @@ func (w *Watcher) reloadConfig() bool {
 	log.Infof("config successfully reloaded, triggering client reload")
 	// Reload clients with new config
 	w.reloadClients(authDirChanged, affectedOAuthProviders)
+	w.recordReloadSuccess()
 	return true
 }
 
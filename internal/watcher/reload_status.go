@@ -0,0 +1,81 @@
+package watcher
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// ReloadStatus reports the outcome of the most recent config hot reload.
+type ReloadStatus struct {
+	LastAttempt         time.Time `json:"last_attempt,omitempty"`
+	LastSuccess         time.Time `json:"last_success,omitempty"`
+	Failed              bool      `json:"failed"`
+	RolledBack          bool      `json:"rolled_back"`
+	Error               string    `json:"error,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+}
+
+// ReloadStatus returns the outcome of the most recent config reload.
+func (w *Watcher) ReloadStatus() ReloadStatus {
+	w.reloadStatusMu.RLock()
+	defer w.reloadStatusMu.RUnlock()
+	return w.reloadStatus
+}
+
+// SetReloadFailureHandler registers a callback invoked when a config reload is
+// rejected and the previous config is kept.
+func (w *Watcher) SetReloadFailureHandler(fn func(ReloadStatus)) {
+	w.reloadStatusMu.Lock()
+	w.onReloadFailure = fn
+	w.reloadStatusMu.Unlock()
+}
+
+func (w *Watcher) recordReloadSuccess() {
+	w.reloadStatusMu.Lock()
+	now := time.Now()
+	w.reloadStatus = ReloadStatus{LastAttempt: now, LastSuccess: now}
+	w.reloadStatusMu.Unlock()
+}
+
+// recordReloadFailure records a rejected reload. The previous config stays active.
+func (w *Watcher) recordReloadFailure(err error) {
+	w.reloadStatusMu.Lock()
+	w.reloadStatus.LastAttempt = time.Now()
+	w.reloadStatus.Failed = true
+	w.reloadStatus.RolledBack = true
+	w.reloadStatus.Error = err.Error()
+	w.reloadStatus.ConsecutiveFailures++
+	status := w.reloadStatus
+	handler := w.onReloadFailure
+	w.reloadStatusMu.Unlock()
+
+	log.Errorf("config reload rejected, still serving previous config: %v", err)
+	if handler != nil {
+		handler(status)
+	}
+}
+
+// checkReloadSafety rejects configs that would leave the proxy without any clients
+// when the running config has some, which almost always indicates a broken edit.
+func (w *Watcher) checkReloadSafety(newCfg *config.Config) error {
+	w.clientsMutex.RLock()
+	previous := w.lastClientCount
+	oldCfg := w.config
+	authFiles := len(w.lastAuthHashes)
+	w.clientsMutex.RUnlock()
+
+	if previous == 0 {
+		return nil
+	}
+	if oldCfg == nil || oldCfg.AuthDir != newCfg.AuthDir {
+		authFiles = w.loadFileClients(newCfg)
+	}
+	gemini, vertex, claude, codex, openAICompat := BuildAPIKeyClients(newCfg)
+	if total := authFiles + gemini + vertex + claude + codex + openAICompat; total == 0 {
+		return fmt.Errorf("new config would leave 0 clients (currently %d)", previous)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+func TestReloadConfigRollback(t *testing.T) {
+	dir := t.TempDir()
+	authDir := filepath.Join(dir, "auths")
+	if err := os.Mkdir(authDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	writeConfig := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(configPath, []byte("auth-dir: "+authDir+"\n"+content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var applied []*config.Config
+	w, err := NewWatcher(configPath, authDir, func(cfg *config.Config) { applied = append(applied, cfg) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Stop() })
+	var failures []ReloadStatus
+	w.SetReloadFailureHandler(func(s ReloadStatus) { failures = append(failures, s) })
+
+	writeConfig("providers:\n  - type: anthropic\n    api-key: sk-one\n")
+	if !w.reloadConfig() || len(applied) != 1 {
+		t.Fatalf("initial reload rejected: %+v", w.ReloadStatus())
+	}
+	good := w.config
+
+	// A config without any client is rejected while the running one has some.
+	writeConfig("providers: []\n")
+	if w.reloadConfig() {
+		t.Fatal("reload to zero clients was applied")
+	}
+	if w.config != good || len(applied) != 1 {
+		t.Fatal("previous config was replaced")
+	}
+	if s := w.ReloadStatus(); !s.Failed || !s.RolledBack || s.ConsecutiveFailures != 1 || s.Error == "" || !s.LastAttempt.After(s.LastSuccess) {
+		t.Errorf("status after rejected reload = %+v", s)
+	}
+
+	writeConfig("providers: [\n")
+	if w.reloadConfig() {
+		t.Fatal("unparsable config was applied")
+	}
+	if s := w.ReloadStatus(); !s.Failed || !s.RolledBack || s.ConsecutiveFailures != 2 {
+		t.Errorf("status after load error = %+v", s)
+	}
+	if len(failures) != 2 || failures[1].ConsecutiveFailures != 2 {
+		t.Errorf("failure handler calls = %+v", failures)
+	}
+
+	writeConfig("providers:\n  - type: anthropic\n    api-key: sk-two\n")
+	if !w.reloadConfig() || len(applied) != 2 {
+		t.Fatal("valid reload rejected")
+	}
+	if s := w.ReloadStatus(); s.Failed || s.RolledBack || s.ConsecutiveFailures != 0 || s.Error != "" || s.LastSuccess.IsZero() {
+		t.Errorf("status after successful reload = %+v", s)
+	}
+	if len(failures) != 2 {
+		t.Errorf("failure handler called on success: %+v", failures)
+	}
+}
@@ -74,6 +74,11 @@ type Watcher struct {
 	pendingWrites   map[string]time.Time
 	// persistGroup deduplicates concurrent config persistence calls using singleflight
 	persistGroup singleflight.Group
+	// lastClientCount is the number of clients loaded by the last successful reload.
+	lastClientCount int
+	reloadStatusMu  sync.RWMutex
+	reloadStatus    ReloadStatus
+	onReloadFailure func(ReloadStatus)
 }
 
 type stableIDGenerator struct {
@@ -30,6 +30,14 @@ type Auth = provider.Auth
 // Manager orchestrates auth lifecycle, selection, execution, and persistence.
 type Manager = provider.Manager
 
+// RefreshHook refreshes the credentials of an auth with custom logic ahead
+// of the built-in refresher of its provider. Register hooks with
+// Builder.WithRefreshHook or Manager.RegisterRefreshHook.
+type RefreshHook = provider.RefreshHook
+
+// RefreshHookFunc adapts a function to RefreshHook.
+type RefreshHookFunc = provider.RefreshHookFunc
+
 // Authenticator manages login and optional refresh flows for a provider.
 type Authenticator = login.Authenticator
 
@@ -0,0 +1,220 @@
+// Package mgmtclient is a typed Go client for the llm-mux management API,
+// generated from docs/management-api.yaml, for tooling that manages
+// llm-mux declaratively.
+//
+//	c := mgmtclient.New("http://localhost:8317", os.Getenv("MANAGEMENT_KEY"))
+//	files, err := c.ListAuthFiles(ctx, nil)
+//
+// Each operation of the spec is a method named after its operationId.
+// Methods return the data of the response envelope; errors reported by the
+// server are *APIError.
+package mgmtclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+//go:generate go run ./gen
+
+// basePath is where the server mounts the management API.
+const basePath = "/v1/management"
+
+// Client calls the management API of one llm-mux server. It is safe for
+// concurrent use.
+type Client struct {
+	baseURL    string
+	key        string
+	httpClient *http.Client
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests; the default is
+// http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			c.httpClient = hc
+		}
+	}
+}
+
+// New returns a client for the server at baseURL, such as
+// http://localhost:8317, authenticating with the management key.
+func New(baseURL, managementKey string, opts ...Option) *Client {
+	baseURL = strings.TrimRight(baseURL, "/")
+	if !strings.HasSuffix(baseURL, basePath) {
+		baseURL += basePath
+	}
+	c := &Client{baseURL: baseURL, key: managementKey, httpClient: http.DefaultClient}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// File is a file sent in a multipart request.
+type File struct {
+	Name    string
+	Content io.Reader
+}
+
+// APIError is an error response of the management API.
+type APIError struct {
+	StatusCode int
+	// Code is the error code, such as NOT_FOUND; empty when the server did
+	// not send one.
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("management api: status %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("management api: status %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Ptr returns a pointer to v, for optional fields and parameters.
+func Ptr[T any](v T) *T { return &v }
+
+// call describes one request of a generated method.
+type call struct {
+	method string
+	path   string
+	query  url.Values
+
+	// json is sent as a JSON body.
+	json any
+	// raw is sent as the body with contentType.
+	raw         []byte
+	contentType string
+	// file is sent as the fileField of a multipart body.
+	file      *File
+	fileField string
+
+	// data decodes the data of the response envelope.
+	data any
+	// result decodes the whole JSON response.
+	result any
+	// body receives the raw response body.
+	body *[]byte
+}
+
+func (c *Client) call(ctx context.Context, r call) error {
+	body, contentType, err := r.encode()
+	if err != nil {
+		return err
+	}
+	target := c.baseURL + r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, target, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.key != "" {
+		req.Header.Set("X-Management-Key", c.key)
+	}
+	req.Header.Set("User-Agent", "llm-mux-mgmtclient/"+SpecVersion)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return decodeError(resp.StatusCode, payload)
+	}
+
+	switch {
+	case r.body != nil:
+		*r.body = payload
+	case r.result != nil:
+		return json.Unmarshal(payload, r.result)
+	case r.data != nil:
+		var envelope struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(payload, &envelope); err != nil {
+			return fmt.Errorf("management api: decode response: %w", err)
+		}
+		if len(envelope.Data) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(envelope.Data, r.data); err != nil {
+			return fmt.Errorf("management api: decode response data: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r call) encode() (io.Reader, string, error) {
+	switch {
+	case r.json != nil:
+		b, err := json.Marshal(r.json)
+		if err != nil {
+			return nil, "", err
+		}
+		if string(b) == "null" {
+			// A nil optional body.
+			return nil, "", nil
+		}
+		return bytes.NewReader(b), "application/json", nil
+	case r.raw != nil:
+		return bytes.NewReader(r.raw), r.contentType, nil
+	case r.file != nil:
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		part, err := w.CreateFormFile(r.fileField, r.file.Name)
+		if err != nil {
+			return nil, "", err
+		}
+		if r.file.Content != nil {
+			if _, err := io.Copy(part, r.file.Content); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, "", err
+		}
+		return &buf, w.FormDataContentType(), nil
+	}
+	return nil, "", nil
+}
+
+func decodeError(status int, payload []byte) error {
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	e := &APIError{StatusCode: status}
+	if json.Unmarshal(payload, &body) == nil && body.Error.Message != "" {
+		e.Code, e.Message = body.Error.Code, body.Error.Message
+		return e
+	}
+	e.Message = strings.TrimSpace(string(payload))
+	if e.Message == "" {
+		e.Message = http.StatusText(status)
+	}
+	return e
+}
@@ -0,0 +1,97 @@
+package mgmtclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientCalls(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Management-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":"UNAUTHORIZED","message":"invalid management key"}}`))
+			return
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/management/auth-files":
+			if r.URL.Query().Get("runtime-only") != "true" {
+				t.Errorf("query = %q", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"data":{"files":[{"id":"a","status":"cooling","disabled":false}]},"meta":{}}`))
+		case "DELETE /v1/management/api-keys":
+			if r.URL.RawQuery != "index=0" {
+				t.Errorf("query = %q", r.URL.RawQuery)
+			}
+		case "PUT /v1/management/debug":
+			var body BooleanValue
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Value {
+				t.Errorf("body = %+v, err = %v", body, err)
+			}
+			_, _ = w.Write([]byte(`{"data":{"debug":false},"meta":{}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"NOT_FOUND","message":"no route"}}`))
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	c := New(srv.URL, "secret")
+
+	files, err := c.ListAuthFiles(ctx, &ListAuthFilesParams{RuntimeOnly: true})
+	if err != nil || len(files.Files) != 1 || files.Files[0].Status != "cooling" || files.Files[0].Disabled == nil {
+		t.Fatalf("files = %+v, err = %v", files, err)
+	}
+	if err := c.DeleteAPIKeys(ctx, &DeleteAPIKeysParams{Index: Ptr(0)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.PutDebug(ctx, &BooleanValue{Value: false}); err != nil {
+		t.Fatal(err)
+	}
+
+	var apiErr *APIError
+	_, err = New(srv.URL+"/v1/management/", "wrong").GetDebug(ctx)
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Code != "UNAUTHORIZED" {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestWatchSendsChanges(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls atomic.Int32
+	values := []int{1, 1, 2, 0, 2}
+	events := Watch(ctx, time.Millisecond, func(context.Context) (int, error) {
+		n := int(calls.Add(1)) - 1
+		if n == 3 {
+			return 0, errors.New("unavailable")
+		}
+		return values[min(n, len(values)-1)], nil
+	})
+	var got []any
+	for e := range events {
+		if e.Err != nil {
+			got = append(got, e.Err.Error())
+		} else {
+			got = append(got, e.Value)
+		}
+		if len(got) == 4 {
+			cancel()
+		}
+	}
+	// The unchanged value after the error is sent to report recovery.
+	want := []any{1, 2, "unavailable", 2}
+	if len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,495 @@
+// Command gen writes operations.gen.go from the management API OpenAPI
+// spec in docs/management-api.yaml. Run it with go generate in
+// pkg/mgmtclient.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+const specPath = "../../docs/management-api.yaml"
+
+func main() {
+	spec, err := os.ReadFile(specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("operations.gen.go", src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// envelopeSchemas describe the response envelope and errors, which the
+// hand-written client handles.
+var envelopeSchemas = map[string]bool{"APIResponse": true, "APIMeta": true, "APIError": true, "APIErrorDetail": true}
+
+// initialisms are the words written in capitals in Go names.
+var initialisms = map[string]string{
+	"api": "API", "cpu": "CPU", "gc": "GC", "http": "HTTP", "id": "ID", "ip": "IP", "json": "JSON",
+	"mb": "MB", "oauth": "OAuth", "ttft": "TTFT", "ttl": "TTL", "url": "URL", "uuid": "UUID", "vram": "VRAM", "ws": "WS",
+}
+
+var httpMethods = map[string]string{
+	"get": "http.MethodGet", "post": "http.MethodPost", "put": "http.MethodPut",
+	"patch": "http.MethodPatch", "delete": "http.MethodDelete",
+}
+
+type document struct {
+	Info struct {
+		Version string `yaml:"version"`
+	} `yaml:"info"`
+	Paths      ordered[ordered[operation]] `yaml:"paths"`
+	Components struct {
+		Schemas ordered[*schema] `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+type operation struct {
+	OperationID string      `yaml:"operationId"`
+	Summary     string      `yaml:"summary"`
+	Parameters  []parameter `yaml:"parameters"`
+	RequestBody *struct {
+		Content ordered[mediaType] `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses ordered[struct {
+		Content ordered[mediaType] `yaml:"content"`
+	}] `yaml:"responses"`
+}
+
+type parameter struct {
+	Name        string  `yaml:"name"`
+	In          string  `yaml:"in"`
+	Description string  `yaml:"description"`
+	Schema      *schema `yaml:"schema"`
+}
+
+type mediaType struct {
+	Schema *schema `yaml:"schema"`
+}
+
+type schema struct {
+	Ref                  string           `yaml:"$ref"`
+	Type                 string           `yaml:"type"`
+	Format               string           `yaml:"format"`
+	Description          string           `yaml:"description"`
+	Required             []string         `yaml:"required"`
+	Properties           ordered[*schema] `yaml:"properties"`
+	Items                *schema          `yaml:"items"`
+	AdditionalProperties yaml.Node        `yaml:"additionalProperties"`
+	OneOf                []*schema        `yaml:"oneOf"`
+	Minimum              *float64         `yaml:"minimum"`
+}
+
+// ordered decodes a YAML mapping keeping the order of its keys.
+type ordered[T any] []entry[T]
+
+type entry[T any] struct {
+	Key   string
+	Value T
+}
+
+func (o *ordered[T]) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", n.Line)
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		var v T
+		if err := n.Content[i+1].Decode(&v); err != nil {
+			return err
+		}
+		*o = append(*o, entry[T]{Key: n.Content[i].Value, Value: v})
+	}
+	return nil
+}
+
+func (o ordered[T]) get(key string) (T, bool) {
+	for _, e := range o {
+		if e.Key == key {
+			return e.Value, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+type generator struct {
+	types   bytes.Buffer
+	methods bytes.Buffer
+	// structs holds the names of the declared struct types.
+	structs map[string]bool
+}
+
+func generate(spec []byte) ([]byte, error) {
+	var doc document
+	if err := yaml.Unmarshal(spec, &doc); err != nil {
+		return nil, err
+	}
+	g := &generator{structs: make(map[string]bool)}
+	for _, e := range doc.Components.Schemas {
+		if !envelopeSchemas[e.Key] && len(e.Value.Properties) > 0 {
+			g.structs[e.Key] = true
+		}
+	}
+	for _, e := range doc.Components.Schemas {
+		if envelopeSchemas[e.Key] {
+			continue
+		}
+		if err := g.declare(e.Key, e.Value, "the "+e.Key+" schema of the management API"); err != nil {
+			return nil, err
+		}
+	}
+	ops := 0
+	for _, p := range doc.Paths {
+		for _, m := range p.Value {
+			if err := g.operation(p.Key, m.Key, m.Value); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(m.Key), p.Key, err)
+			}
+			ops++
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by go run ./gen; DO NOT EDIT.\n\n")
+	out.WriteString("package mgmtclient\n\n")
+	out.WriteString("import (\n\"context\"\n\"encoding/json\"\n\"net/http\"\n\"net/url\"\n\"strconv\"\n)\n\n")
+	fmt.Fprintf(&out, "// SpecVersion is the version of the management API spec the client was\n// generated from.\nconst SpecVersion = %q\n\n", doc.Info.Version)
+	fmt.Fprintf(&out, "// operationCount is the number of operations in the spec.\nconst operationCount = %d\n\n", ops)
+	out.Write(g.types.Bytes())
+	out.Write(g.methods.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format: %w\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+// goType returns the Go type of s, declaring a struct named name when s is
+// an inline object with properties; what describes s for its doc comment.
+func (g *generator) goType(s *schema, name, what string) (string, error) {
+	if s == nil {
+		return "json.RawMessage", nil
+	}
+	if s.Ref != "" {
+		return strings.TrimPrefix(s.Ref, "#/components/schemas/"), nil
+	}
+	if len(s.OneOf) > 0 {
+		return "json.RawMessage", nil
+	}
+	switch s.Type {
+	case "string":
+		return "string", nil
+	case "integer":
+		return "int64", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		item, err := g.goType(s.Items, name+"Item", "an item of "+what)
+		return "[]" + item, err
+	}
+	if len(s.Properties) > 0 {
+		g.structs[name] = true
+		return name, g.declare(name, s, what)
+	}
+	if s.AdditionalProperties.Kind == yaml.MappingNode {
+		var value schema
+		if err := s.AdditionalProperties.Decode(&value); err != nil {
+			return "", err
+		}
+		elem, err := g.goType(&value, name+"Value", "a value of "+what)
+		return "map[string]" + elem, err
+	}
+	return "json.RawMessage", nil
+}
+
+// fieldType returns the Go type of a struct field of type typ. Optional
+// scalars and structs are pointers so that their zero values can be sent.
+func (g *generator) fieldType(typ string, required bool) (string, bool) {
+	if required {
+		return typ, false
+	}
+	if typ == "bool" || typ == "int64" || typ == "float64" || g.structs[typ] {
+		return "*" + typ, true
+	}
+	return typ, true
+}
+
+func (g *generator) declare(name string, s *schema, what string) error {
+	doc := name + " is " + what + "."
+	if len(s.Properties) == 0 {
+		typ, err := g.goType(s, name, what)
+		if err != nil {
+			return err
+		}
+		writeComment(&g.types, "", doc, s.Description)
+		fmt.Fprintf(&g.types, "type %s %s\n\n", name, typ)
+		return nil
+	}
+	var fields bytes.Buffer
+	seen := make(map[string]bool)
+	for _, p := range s.Properties {
+		field := goName(p.Key)
+		if seen[field] {
+			return fmt.Errorf("%s: properties map to the same field %s", name, field)
+		}
+		seen[field] = true
+		typ, err := g.goType(p.Value, name+field, fmt.Sprintf("the %s field of %s", p.Key, name))
+		if err != nil {
+			return err
+		}
+		typ, omit := g.fieldType(typ, slices.Contains(s.Required, p.Key))
+		tag := p.Key
+		if omit {
+			tag += ",omitempty"
+		}
+		writeComment(&fields, "\t", "", p.Value.Description)
+		fmt.Fprintf(&fields, "\t%s %s `json:%q`\n", field, typ, tag)
+	}
+	writeComment(&g.types, "", doc, s.Description)
+	fmt.Fprintf(&g.types, "type %s struct {\n%s}\n\n", name, fields.Bytes())
+	return nil
+}
+
+func (g *generator) operation(path, method string, op operation) error {
+	httpMethod, ok := httpMethods[method]
+	if !ok || op.OperationID == "" {
+		return fmt.Errorf("unsupported method or missing operationId")
+	}
+	name := goName(op.OperationID)
+	args := []string{"ctx context.Context"}
+	var fields []string
+
+	pathExpr, pathArgs := pathExpression(path)
+	for _, a := range pathArgs {
+		args = append(args, a+" string")
+	}
+	fields = append(fields, "method: "+httpMethod, "path: "+pathExpr)
+
+	var query []parameter
+	for _, p := range op.Parameters {
+		if p.In == "query" {
+			query = append(query, p)
+		}
+	}
+	if len(query) > 0 {
+		if err := g.params(name+"Params", name, query); err != nil {
+			return err
+		}
+		args = append(args, "params *"+name+"Params")
+		fields = append(fields, "query: params.values()")
+	}
+
+	if op.RequestBody != nil {
+		arg, field, err := g.requestBody(name, op.RequestBody.Content)
+		if err != nil {
+			return err
+		}
+		args = append(args, arg)
+		fields = append(fields, field...)
+	}
+
+	var content ordered[mediaType]
+	for _, status := range []string{"200", "201"} {
+		if r, ok := op.Responses.get(status); ok {
+			content = r.Content
+			break
+		}
+	}
+	result, err := g.result(name, content)
+	if err != nil {
+		return err
+	}
+
+	w := &g.methods
+	fmt.Fprintf(w, "// %s calls %s %s.\n", name, strings.ToUpper(method), path)
+	if op.Summary != "" {
+		fmt.Fprintf(w, "//\n// %s.\n", strings.TrimSuffix(strings.TrimSpace(op.Summary), "."))
+	}
+	call := "call{\n" + strings.Join(fields, ",\n") + ","
+	switch {
+	case result.typ == "":
+		fmt.Fprintf(w, "func (c *Client) %s(%s) error {\nreturn c.call(%s\n})\n}\n\n", name, strings.Join(args, ", "), "ctx, "+call)
+	default:
+		ret := result.typ
+		decl, target, zero := "var out "+ret, "&out", "nil"
+		if g.structs[ret] {
+			ret = "*" + ret
+			decl, target = "out := new("+result.typ+")", "out"
+		}
+		fmt.Fprintf(w, "func (c *Client) %s(%s) (%s, error) {\n%s\nif err := c.call(ctx, %s\n%s: %s,\n}); err != nil {\nreturn %s, err\n}\nreturn out, nil\n}\n\n",
+			name, strings.Join(args, ", "), ret, decl, call, result.field, target, zero)
+	}
+	return nil
+}
+
+// pathExpression returns a Go expression building path with its
+// parameters escaped, and the parameter names.
+func pathExpression(path string) (string, []string) {
+	var parts, names []string
+	for {
+		start := strings.Index(path, "{")
+		end := strings.Index(path, "}")
+		if start < 0 || end < start {
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%q", path[:start]))
+		name := path[start+1 : end]
+		names = append(names, name)
+		parts = append(parts, "url.PathEscape("+name+")")
+		path = path[end+1:]
+	}
+	if path != "" || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%q", path))
+	}
+	return strings.Join(parts, " + "), names
+}
+
+func (g *generator) params(typeName, opName string, query []parameter) error {
+	var fields, values bytes.Buffer
+	for _, p := range query {
+		field := goName(p.Name)
+		typ := "string"
+		if p.Schema != nil {
+			typ = p.Schema.Type
+		}
+		writeComment(&fields, "\t", "", p.Description)
+		switch typ {
+		case "integer":
+			// Zero is a value to send unless the parameter must be positive.
+			if p.Schema.Minimum != nil && *p.Schema.Minimum >= 1 {
+				fmt.Fprintf(&fields, "\t%s int\n", field)
+				fmt.Fprintf(&values, "if p.%s != 0 {\nq.Set(%q, strconv.Itoa(p.%s))\n}\n", field, p.Name, field)
+			} else {
+				fmt.Fprintf(&fields, "\t%s *int\n", field)
+				fmt.Fprintf(&values, "if p.%s != nil {\nq.Set(%q, strconv.Itoa(*p.%s))\n}\n", field, p.Name, field)
+			}
+		case "boolean":
+			fmt.Fprintf(&fields, "\t%s bool\n", field)
+			fmt.Fprintf(&values, "if p.%s {\nq.Set(%q, \"true\")\n}\n", field, p.Name)
+		case "string":
+			fmt.Fprintf(&fields, "\t%s string\n", field)
+			fmt.Fprintf(&values, "if p.%s != \"\" {\nq.Set(%q, p.%s)\n}\n", field, p.Name, field)
+		default:
+			return fmt.Errorf("query parameter %s: unsupported type %q", p.Name, typ)
+		}
+	}
+	fmt.Fprintf(&g.types, "// %s holds the query parameters of %s. Unset\n// fields are not sent.\ntype %s struct {\n%s}\n\n", typeName, opName, typeName, fields.Bytes())
+	fmt.Fprintf(&g.types, "func (p *%s) values() url.Values {\nif p == nil {\nreturn nil\n}\nq := url.Values{}\n%sreturn q\n}\n\n", typeName, values.Bytes())
+	return nil
+}
+
+// requestBody returns the method argument and call fields sending a
+// request body. JSON is preferred when an operation accepts several
+// content types.
+func (g *generator) requestBody(opName string, content ordered[mediaType]) (string, []string, error) {
+	if m, ok := content.get("application/json"); ok {
+		typ, err := g.goType(m.Schema, opName+"Request", "the body of a "+opName+" request")
+		if err != nil {
+			return "", nil, err
+		}
+		if typ == "json.RawMessage" {
+			typ = "any"
+		} else if g.structs[typ] {
+			typ = "*" + typ
+		}
+		return "body " + typ, []string{"json: body"}, nil
+	}
+	if m, ok := content.get("multipart/form-data"); ok && m.Schema != nil {
+		for _, p := range m.Schema.Properties {
+			if p.Value.Format == "binary" {
+				return "file File", []string{"file: &file", fmt.Sprintf("fileField: %q", p.Key)}, nil
+			}
+		}
+		return "", nil, fmt.Errorf("multipart body without a file field")
+	}
+	if len(content) == 0 {
+		return "", nil, fmt.Errorf("request body without content")
+	}
+	return "body []byte", []string{"raw: body", fmt.Sprintf("contentType: %q", content[0].Key)}, nil
+}
+
+type resultKind struct {
+	// typ is the Go type of the result; empty when there is none.
+	typ string
+	// field is the call field that decodes the response.
+	field string
+}
+
+func (g *generator) result(opName string, content ordered[mediaType]) (resultKind, error) {
+	if len(content) == 0 {
+		return resultKind{}, nil
+	}
+	m, ok := content.get("application/json")
+	if !ok {
+		return resultKind{typ: "[]byte", field: "body"}, nil
+	}
+	s, field := m.Schema, "result"
+	if s != nil {
+		if data, ok := s.Properties.get("data"); ok {
+			s, field = data, "data"
+		}
+	}
+	typ, err := g.goType(s, opName+"Result", "the data of a "+opName+" response")
+	if err != nil {
+		return resultKind{}, err
+	}
+	return resultKind{typ: typ, field: field}, nil
+}
+
+// goName converts an operation ID or a kebab-case or snake_case name to an
+// exported Go name.
+func goName(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+		if up, ok := initialisms[word]; ok {
+			b.WriteString(up)
+			continue
+		}
+		// Operation IDs are camelCase; capitalize their leading initialism.
+		for prefix, up := range initialisms {
+			if len(word) > len(prefix) && strings.HasPrefix(word, prefix) && unicode.IsUpper(rune(word[len(prefix)])) {
+				word = up + word[len(prefix):]
+				break
+			}
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// writeComment writes doc and the lines of description as a comment.
+func writeComment(w *bytes.Buffer, indent, doc, description string) {
+	var lines []string
+	if doc != "" {
+		lines = append(lines, doc)
+	}
+	if d := strings.TrimSpace(description); d != "" {
+		if doc != "" {
+			lines = append(lines, "")
+		}
+		for _, l := range strings.Split(d, "\n") {
+			lines = append(lines, strings.TrimRight(l, " "))
+		}
+	}
+	for _, l := range lines {
+		if l == "" {
+			fmt.Fprintf(w, "%s//\n", indent)
+		} else {
+			fmt.Fprintf(w, "%s// %s\n", indent, l)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestGeneratedUpToDate(t *testing.T) {
+	spec, err := os.ReadFile("../" + specPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := generate(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("../operations.gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("operations.gen.go is stale; run go generate ./pkg/mgmtclient")
+	}
+}
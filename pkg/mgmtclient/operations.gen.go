@@ -0,0 +1,2583 @@
+// Code generated by go run ./gen; DO NOT EDIT.
+
+package mgmtclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// SpecVersion is the version of the management API spec the client was
+// generated from.
+const SpecVersion = "1.0.0"
+
+// operationCount is the number of operations in the spec.
+const operationCount = 83
+
+// TranscriptResponseToolCallsItem is an item of the tool_calls field of TranscriptResponse.
+type TranscriptResponseToolCallsItem struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// TranscriptResponse is the response field of Transcript.
+type TranscriptResponse struct {
+	Text         string                            `json:"text,omitempty"`
+	Reasoning    string                            `json:"reasoning,omitempty"`
+	ToolCalls    []TranscriptResponseToolCallsItem `json:"tool_calls,omitempty"`
+	FinishReason string                            `json:"finish_reason,omitempty"`
+}
+
+// Transcript is the Transcript schema of the management API.
+type Transcript struct {
+	ID          string `json:"id,omitempty"`
+	RequestedAt string `json:"requested_at,omitempty"`
+	APIKey      string `json:"api_key,omitempty"`
+	Model       string `json:"model,omitempty"`
+	// Client API format
+	Format string `json:"format,omitempty"`
+	Label  string `json:"label,omitempty"`
+	Stream *bool  `json:"stream,omitempty"`
+	// Client request body
+	Request  json.RawMessage     `json:"request,omitempty"`
+	Response *TranscriptResponse `json:"response,omitempty"`
+}
+
+// QuotaWindow is the QuotaWindow schema of the management API.
+type QuotaWindow struct {
+	AuthID              string   `json:"auth_id,omitempty"`
+	Label               string   `json:"label,omitempty"`
+	WindowStart         string   `json:"window_start,omitempty"`
+	WindowResetAt       string   `json:"window_reset_at,omitempty"`
+	Messages            *int64   `json:"messages,omitempty"`
+	Tokens              *int64   `json:"tokens,omitempty"`
+	ActiveRequests      *int64   `json:"active_requests,omitempty"`
+	LearnedMessageLimit *int64   `json:"learned_message_limit,omitempty"`
+	LearnedTokenLimit   *int64   `json:"learned_token_limit,omitempty"`
+	RemainingFraction   *float64 `json:"remaining_fraction,omitempty"`
+	RemainingMessages   *int64   `json:"remaining_messages,omitempty"`
+	RemainingTokens     *int64   `json:"remaining_tokens,omitempty"`
+	Source              string   `json:"source,omitempty"`
+	// anthropic-ratelimit-unified-status of the last response
+	UpstreamStatus      string   `json:"upstream_status,omitempty"`
+	FiveHourUtilization *float64 `json:"five_hour_utilization,omitempty"`
+	SevenDayUtilization *float64 `json:"seven_day_utilization,omitempty"`
+	SevenDayResetAt     string   `json:"seven_day_reset_at,omitempty"`
+	CooldownUntil       string   `json:"cooldown_until,omitempty"`
+	LastExhaustedAt     string   `json:"last_exhausted_at,omitempty"`
+}
+
+// LatencySummary is the LatencySummary schema of the management API.
+type LatencySummary struct {
+	Count *int64   `json:"count,omitempty"`
+	AvgMs *float64 `json:"avg_ms,omitempty"`
+	P50Ms *float64 `json:"p50_ms,omitempty"`
+	P95Ms *float64 `json:"p95_ms,omitempty"`
+	P99Ms *float64 `json:"p99_ms,omitempty"`
+}
+
+// SizeSummary is the SizeSummary schema of the management API.
+type SizeSummary struct {
+	Count    *int64   `json:"count,omitempty"`
+	AvgBytes *float64 `json:"avg_bytes,omitempty"`
+	P50Bytes *float64 `json:"p50_bytes,omitempty"`
+	P95Bytes *float64 `json:"p95_bytes,omitempty"`
+	P99Bytes *float64 `json:"p99_bytes,omitempty"`
+}
+
+// UsageTotals is the UsageTotals schema of the management API.
+type UsageTotals struct {
+	// Zero when the provider does not report request counts
+	Requests     *int64 `json:"requests,omitempty"`
+	InputTokens  *int64 `json:"input_tokens,omitempty"`
+	OutputTokens *int64 `json:"output_tokens,omitempty"`
+	CachedTokens *int64 `json:"cached_tokens,omitempty"`
+}
+
+// LogLevels is the LogLevels schema of the management API.
+type LogLevels struct {
+	Level string `json:"level,omitempty"`
+	// Levels by module, a package path with internal/ dropped such as translator/claude
+	Modules map[string]string `json:"modules,omitempty"`
+}
+
+// SuccessResponse is the SuccessResponse schema of the management API.
+type SuccessResponse struct {
+	Ok      *bool    `json:"ok,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+}
+
+// AccessLogConfig is the AccessLogConfig schema of the management API.
+type AccessLogConfig struct {
+	Enabled    *bool    `json:"enabled,omitempty"`
+	SampleRate *float64 `json:"sample-rate,omitempty"`
+	File       string   `json:"file,omitempty"`
+	MaxSizeMB  *int64   `json:"max-size-mb,omitempty"`
+	MaxBackups *int64   `json:"max-backups,omitempty"`
+	MaxAgeDays *int64   `json:"max-age-days,omitempty"`
+	Compress   *bool    `json:"compress,omitempty"`
+}
+
+// BooleanValue is the BooleanValue schema of the management API.
+type BooleanValue struct {
+	Value bool `json:"value"`
+}
+
+// IntegerValue is the IntegerValue schema of the management API.
+type IntegerValue struct {
+	Value int64 `json:"value"`
+}
+
+// StringValue is the StringValue schema of the management API.
+type StringValue struct {
+	Value string `json:"value"`
+}
+
+// BooleanSetting is the BooleanSetting schema of the management API.
+type BooleanSetting struct {
+	Debug *bool `json:"debug,omitempty"`
+}
+
+// Provider is the Provider schema of the management API.
+//
+// Unified API provider configuration
+type Provider struct {
+	// Provider type (gemini, anthropic, openai, vertex-compat)
+	Type string `json:"type,omitempty"`
+	// Display name for this provider instance
+	Name string `json:"name,omitempty"`
+	// Whether the provider is enabled (default true)
+	Enabled *bool `json:"enabled,omitempty"`
+	// Primary API key for this provider
+	APIKey string `json:"api-key,omitempty"`
+	// Multiple API keys with per-key proxy settings for load balancing
+	APIKeys []ProviderAPIKey `json:"api-keys,omitempty"`
+	// API endpoint URL. Required for openai and vertex-compat types
+	BaseURL string `json:"base-url,omitempty"`
+	// Proxy URL for this provider's requests
+	ProxyURL string `json:"proxy-url,omitempty"`
+	// Custom HTTP headers to add to requests
+	Headers map[string]string `json:"headers,omitempty"`
+	// Available models for this provider. Required for openai and vertex-compat types
+	Models []ProviderModel `json:"models,omitempty"`
+	// Model names to exclude from this provider
+	ExcludedModels []string `json:"excluded-models,omitempty"`
+}
+
+// ProviderAPIKey is the ProviderAPIKey schema of the management API.
+//
+// API key with optional per-key settings
+type ProviderAPIKey struct {
+	// The API key value
+	Key string `json:"key"`
+	// Override proxy URL for this specific key
+	ProxyURL string `json:"proxy-url,omitempty"`
+}
+
+// ProviderModel is the ProviderModel schema of the management API.
+//
+// Model available from a provider
+type ProviderModel struct {
+	// Actual model name used in API requests
+	Name string `json:"name"`
+	// Optional alternative name for this model
+	Alias string `json:"alias,omitempty"`
+}
+
+// ChatSummary is the ChatSummary schema of the management API.
+//
+// Summary of a chat completion returned to clients
+type ChatSummary struct {
+	Text string `json:"text,omitempty"`
+	// OpenAI-format tool calls, when the model made any
+	ToolCalls    []json.RawMessage `json:"tool_calls,omitempty"`
+	FinishReason string            `json:"finish_reason,omitempty"`
+	Usage        json.RawMessage   `json:"usage,omitempty"`
+}
+
+// AuthFileSpend is the spend field of AuthFile.
+//
+// Month-to-date usage against the auth's spend limit (only when `routing.spend-limits` applies)
+type AuthFileSpend struct {
+	Month  string `json:"month,omitempty"`
+	Tokens *int64 `json:"tokens,omitempty"`
+	// USD, priced with `routing.pricing`
+	Cost        *float64 `json:"cost,omitempty"`
+	TokenLimit  *int64   `json:"token_limit,omitempty"`
+	CostLimit   *float64 `json:"cost_limit,omitempty"`
+	UsedPercent *float64 `json:"used_percent,omitempty"`
+	Status      string   `json:"status,omitempty"`
+}
+
+// AuthFile is the AuthFile schema of the management API.
+//
+// Authentication credential file information
+type AuthFile struct {
+	// Unique identifier for the auth file
+	ID string `json:"id,omitempty"`
+	// Index of this auth in the provider list
+	AuthIndex *int64 `json:"auth_index,omitempty"`
+	// File name
+	Name string `json:"name,omitempty"`
+	// Provider type (e.g., gemini, claude, codex)
+	Type string `json:"type,omitempty"`
+	// Provider type (same as type, for compatibility)
+	Provider string `json:"provider,omitempty"`
+	// Human-readable label (often the email)
+	Label string `json:"label,omitempty"`
+	// Account email if available
+	Email string `json:"email,omitempty"`
+	// Account type (e.g., pro, max, free)
+	AccountType string `json:"account_type,omitempty"`
+	// Account identifier
+	Account string `json:"account,omitempty"`
+	// Current status of the auth
+	Status string `json:"status,omitempty"`
+	// Status details or error message
+	StatusMessage string `json:"status_message,omitempty"`
+	// Whether the auth is disabled
+	Disabled *bool `json:"disabled,omitempty"`
+	// Whether the auth is temporarily unavailable
+	Unavailable *bool `json:"unavailable,omitempty"`
+	// Whether this auth exists only in memory (not on disk)
+	RuntimeOnly *bool `json:"runtime_only,omitempty"`
+	// Where the auth data comes from
+	Source string `json:"source,omitempty"`
+	// File path on disk (if source is file)
+	Path string `json:"path,omitempty"`
+	// File size in bytes
+	Size *int64 `json:"size,omitempty"`
+	// File modification time
+	Modtime string `json:"modtime,omitempty"`
+	// When the auth was created
+	CreatedAt string `json:"created_at,omitempty"`
+	// When the auth was last updated
+	UpdatedAt string `json:"updated_at,omitempty"`
+	// When the auth token was last refreshed
+	LastRefresh string `json:"last_refresh,omitempty"`
+	// Month-to-date usage against the auth's spend limit (only when `routing.spend-limits` applies)
+	Spend *AuthFileSpend `json:"spend,omitempty"`
+}
+
+// OAuthStartResponse is the OAuthStartResponse schema of the management API.
+//
+// Response for starting an OAuth or device flow
+type OAuthStartResponse struct {
+	Status string `json:"status,omitempty"`
+	// Type of authentication flow
+	FlowType string `json:"flow_type,omitempty"`
+	// URL to open in browser for authentication
+	AuthURL string `json:"auth_url,omitempty"`
+	// State token for status polling
+	State string `json:"state,omitempty"`
+	// Flow identifier (same as state)
+	ID string `json:"id,omitempty"`
+	// Error message if status is error
+	Error string `json:"error,omitempty"`
+	// PKCE code verifier (for PKCE-enabled providers like Claude, Codex)
+	CodeVerifier string `json:"code_verifier,omitempty"`
+	// PKCE code challenge (for PKCE-enabled providers)
+	CodeChallenge string `json:"code_challenge,omitempty"`
+	// Device flow user code to enter on verification page
+	UserCode string `json:"user_code,omitempty"`
+	// Device flow verification URL
+	VerificationURL string `json:"verification_url,omitempty"`
+	// Device code expiry in seconds
+	ExpiresIn *int64 `json:"expires_in,omitempty"`
+	// Recommended polling interval in seconds
+	Interval *int64 `json:"interval,omitempty"`
+}
+
+// UsageStats is the UsageStats schema of the management API.
+type UsageStats struct {
+	Summary *UsageSummary `json:"summary,omitempty"`
+	// Usage statistics grouped by provider
+	ByProvider map[string]UsageProviderStats `json:"by_provider,omitempty"`
+	// Usage statistics grouped by auth account (key format provider:auth_id)
+	ByAccount map[string]UsageAccountStats `json:"by_account,omitempty"`
+	// Usage statistics grouped by model
+	ByModel  map[string]UsageModelStats `json:"by_model,omitempty"`
+	Timeline *UsageTimeline             `json:"timeline,omitempty"`
+	Period   *UsagePeriod               `json:"period,omitempty"`
+}
+
+// UsageSummary is the UsageSummary schema of the management API.
+type UsageSummary struct {
+	TotalRequests *int64        `json:"total_requests,omitempty"`
+	SuccessCount  *int64        `json:"success_count,omitempty"`
+	FailureCount  *int64        `json:"failure_count,omitempty"`
+	Tokens        *TokenSummary `json:"tokens,omitempty"`
+}
+
+// TokenSummary is the TokenSummary schema of the management API.
+type TokenSummary struct {
+	Total     *int64 `json:"total,omitempty"`
+	Input     *int64 `json:"input,omitempty"`
+	Output    *int64 `json:"output,omitempty"`
+	Reasoning *int64 `json:"reasoning,omitempty"`
+}
+
+// UsageProviderStats is the UsageProviderStats schema of the management API.
+type UsageProviderStats struct {
+	Requests *int64        `json:"requests,omitempty"`
+	Success  *int64        `json:"success,omitempty"`
+	Failure  *int64        `json:"failure,omitempty"`
+	Tokens   *TokenSummary `json:"tokens,omitempty"`
+	// Number of unique auth accounts used
+	Accounts *int64 `json:"accounts,omitempty"`
+	// List of models used with this provider
+	Models []string `json:"models,omitempty"`
+}
+
+// UsageAccountStats is the UsageAccountStats schema of the management API.
+type UsageAccountStats struct {
+	Provider string        `json:"provider,omitempty"`
+	AuthID   string        `json:"auth_id,omitempty"`
+	Requests *int64        `json:"requests,omitempty"`
+	Success  *int64        `json:"success,omitempty"`
+	Failure  *int64        `json:"failure,omitempty"`
+	Tokens   *TokenSummary `json:"tokens,omitempty"`
+}
+
+// UsageModelStats is the UsageModelStats schema of the management API.
+type UsageModelStats struct {
+	Provider string        `json:"provider,omitempty"`
+	Requests *int64        `json:"requests,omitempty"`
+	Success  *int64        `json:"success,omitempty"`
+	Failure  *int64        `json:"failure,omitempty"`
+	Tokens   *TokenSummary `json:"tokens,omitempty"`
+}
+
+// UsageTimeline is the UsageTimeline schema of the management API.
+type UsageTimeline struct {
+	ByDay  []UsageDayStats  `json:"by_day,omitempty"`
+	ByHour []UsageHourStats `json:"by_hour,omitempty"`
+}
+
+// UsageDayStats is the UsageDayStats schema of the management API.
+type UsageDayStats struct {
+	Day      string `json:"day,omitempty"`
+	Requests *int64 `json:"requests,omitempty"`
+	Tokens   *int64 `json:"tokens,omitempty"`
+}
+
+// UsageHourStats is the UsageHourStats schema of the management API.
+type UsageHourStats struct {
+	// Hour of day (0-23)
+	Hour     *int64 `json:"hour,omitempty"`
+	Requests *int64 `json:"requests,omitempty"`
+	Tokens   *int64 `json:"tokens,omitempty"`
+}
+
+// UsagePeriod is the UsagePeriod schema of the management API.
+type UsagePeriod struct {
+	From          string `json:"from,omitempty"`
+	To            string `json:"to,omitempty"`
+	RetentionDays *int64 `json:"retention_days,omitempty"`
+}
+
+// PutConfigYAMLResult is the data of a PutConfigYAML response.
+type PutConfigYAMLResult struct {
+	Ok      *bool    `json:"ok,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+}
+
+// GetConfigReloadStatusResult is the data of a GetConfigReloadStatus response.
+type GetConfigReloadStatusResult struct {
+	LastAttempt         string `json:"last_attempt,omitempty"`
+	LastSuccess         string `json:"last_success,omitempty"`
+	Failed              *bool  `json:"failed,omitempty"`
+	RolledBack          *bool  `json:"rolled_back,omitempty"`
+	Error               string `json:"error,omitempty"`
+	ConsecutiveFailures *int64 `json:"consecutive_failures,omitempty"`
+}
+
+// GetLatestVersionResult is the data of a GetLatestVersion response.
+type GetLatestVersionResult struct {
+	LatestVersion string `json:"latest-version,omitempty"`
+	Cached        *bool  `json:"cached,omitempty"`
+	Stale         *bool  `json:"stale,omitempty"`
+}
+
+// GetPprofProfileParams holds the query parameters of GetPprofProfile. Unset
+// fields are not sent.
+type GetPprofProfileParams struct {
+	// Collection time for `profile` and `trace`, or delta duration for other profiles.
+	Seconds *int
+}
+
+func (p *GetPprofProfileParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Seconds != nil {
+		q.Set("seconds", strconv.Itoa(*p.Seconds))
+	}
+	return q
+}
+
+// GetRuntimeStatsResult is the data of a GetRuntimeStats response.
+type GetRuntimeStatsResult struct {
+	Goroutines    *int64   `json:"goroutines,omitempty"`
+	Gomaxprocs    *int64   `json:"gomaxprocs,omitempty"`
+	GCPercent     *int64   `json:"gc_percent,omitempty"`
+	MemoryLimit   *int64   `json:"memory_limit,omitempty"`
+	Ballast       *int64   `json:"ballast,omitempty"`
+	HeapAlloc     *int64   `json:"heap_alloc,omitempty"`
+	HeapInuse     *int64   `json:"heap_inuse,omitempty"`
+	NextGC        *int64   `json:"next_gc,omitempty"`
+	NumGC         *int64   `json:"num_gc,omitempty"`
+	GCCPUFraction *float64 `json:"gc_cpu_fraction,omitempty"`
+	LastPauseNs   *int64   `json:"last_pause_ns,omitempty"`
+}
+
+// GetOverloadResult is the data of a GetOverload response.
+type GetOverloadResult struct {
+	Enabled *bool  `json:"enabled,omitempty"`
+	Level   string `json:"level,omitempty"`
+	// Highest ratio of a measurement to its threshold
+	Load               *float64         `json:"load,omitempty"`
+	Goroutines         *int64           `json:"goroutines,omitempty"`
+	SchedulerLatencyMs *float64         `json:"scheduler_latency_ms,omitempty"`
+	Shed               map[string]int64 `json:"shed,omitempty"`
+}
+
+// GetStreamRepairsResult is the data of a GetStreamRepairs response.
+type GetStreamRepairsResult struct {
+	Repairs map[string]int64 `json:"repairs,omitempty"`
+}
+
+// GetUpstreamOverloadsResult is the data of a GetUpstreamOverloads response.
+type GetUpstreamOverloadsResult struct {
+	Overloads map[string]int64 `json:"overloads,omitempty"`
+}
+
+// GetAbandonedStreamsResult is the data of a GetAbandonedStreams response.
+type GetAbandonedStreamsResult struct {
+	Abandoned map[string]int64 `json:"abandoned,omitempty"`
+}
+
+// GetEndpointAliasCallersResultCallersItem is an item of the callers field of GetEndpointAliasCallersResult.
+type GetEndpointAliasCallersResultCallersItem struct {
+	Path      string `json:"path,omitempty"`
+	Target    string `json:"target,omitempty"`
+	Client    string `json:"client,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	Requests  *int64 `json:"requests,omitempty"`
+	FirstSeen string `json:"first_seen,omitempty"`
+	LastSeen  string `json:"last_seen,omitempty"`
+}
+
+// GetEndpointAliasCallersResult is the data of a GetEndpointAliasCallers response.
+type GetEndpointAliasCallersResult struct {
+	Callers []GetEndpointAliasCallersResultCallersItem `json:"callers,omitempty"`
+}
+
+// GetTLSSessionsResultSessionsItem is an item of the sessions field of GetTLSSessionsResult.
+type GetTLSSessionsResultSessionsItem struct {
+	Pool       string   `json:"pool,omitempty"`
+	Host       string   `json:"host,omitempty"`
+	Handshakes *int64   `json:"handshakes,omitempty"`
+	Resumed    *int64   `json:"resumed,omitempty"`
+	ReuseRate  *float64 `json:"reuse_rate,omitempty"`
+}
+
+// GetTLSSessionsResult is the data of a GetTLSSessions response.
+type GetTLSSessionsResult struct {
+	Sessions []GetTLSSessionsResultSessionsItem `json:"sessions,omitempty"`
+}
+
+// GetWarmPoolResultProvidersItemModelsItem is an item of the models field of GetWarmPoolResultProvidersItem.
+type GetWarmPoolResultProvidersItemModelsItem struct {
+	Model    string `json:"model,omitempty"`
+	LastPing string `json:"last_ping,omitempty"`
+	// Time the last ping waited for the model to load
+	LoadMs *int64 `json:"load_ms,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// GetWarmPoolResultProvidersItemRunningItem is an item of the running field of GetWarmPoolResultProvidersItem.
+type GetWarmPoolResultProvidersItemRunningItem struct {
+	Name      string `json:"name,omitempty"`
+	Size      *int64 `json:"size,omitempty"`
+	SizeVRAM  *int64 `json:"size_vram,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+// GetWarmPoolResultProvidersItem is an item of the providers field of GetWarmPoolResult.
+type GetWarmPoolResultProvidersItem struct {
+	Provider string `json:"provider,omitempty"`
+	// Native API root
+	BaseURL string                                      `json:"base_url,omitempty"`
+	Models  []GetWarmPoolResultProvidersItemModelsItem  `json:"models,omitempty"`
+	Running []GetWarmPoolResultProvidersItemRunningItem `json:"running,omitempty"`
+	// Set when /api/ps could not be read
+	Error string `json:"error,omitempty"`
+}
+
+// GetWarmPoolResult is the data of a GetWarmPool response.
+type GetWarmPoolResult struct {
+	Providers []GetWarmPoolResultProvidersItem `json:"providers,omitempty"`
+}
+
+// GetCanariesResultCanariesItem is an item of the canaries field of GetCanariesResult.
+type GetCanariesResultCanariesItem struct {
+	Canary    string `json:"canary,omitempty"`
+	Provider  string `json:"provider,omitempty"`
+	Model     string `json:"model,omitempty"`
+	Ok        *bool  `json:"ok,omitempty"`
+	LastRun   string `json:"last_run,omitempty"`
+	LatencyMs *int64 `json:"latency_ms,omitempty"`
+	// Start of the current failure streak
+	FailingSince string `json:"failing_since,omitempty"`
+	// Answer text, truncated to 512 bytes
+	Answer string `json:"answer,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// GetCanariesResult is the data of a GetCanaries response.
+type GetCanariesResult struct {
+	Canaries []GetCanariesResultCanariesItem `json:"canaries,omitempty"`
+}
+
+// GetConformanceResultFormatsItemChecksItem is an item of the checks field of GetConformanceResultFormatsItem.
+type GetConformanceResultFormatsItemChecksItem struct {
+	Name  string `json:"name,omitempty"`
+	Ok    *bool  `json:"ok,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// GetConformanceResultFormatsItem is an item of the formats field of GetConformanceResult.
+type GetConformanceResultFormatsItem struct {
+	Format string                                      `json:"format,omitempty"`
+	Ok     *bool                                       `json:"ok,omitempty"`
+	Checks []GetConformanceResultFormatsItemChecksItem `json:"checks,omitempty"`
+}
+
+// GetConformanceResult is the data of a GetConformance response.
+type GetConformanceResult struct {
+	// True when every check of every format passed
+	Ok      *bool                             `json:"ok,omitempty"`
+	Formats []GetConformanceResultFormatsItem `json:"formats,omitempty"`
+}
+
+// GetLoggingToFileResult is the data of a GetLoggingToFile response.
+type GetLoggingToFileResult struct {
+	LoggingToFile *bool `json:"logging-to-file,omitempty"`
+}
+
+// GetUsageStatisticsEnabledResult is the data of a GetUsageStatisticsEnabled response.
+type GetUsageStatisticsEnabledResult struct {
+	UsageStatisticsEnabled *bool `json:"usage-statistics-enabled,omitempty"`
+}
+
+// GetRequestLogResult is the data of a GetRequestLog response.
+type GetRequestLogResult struct {
+	RequestLog *bool `json:"request-log,omitempty"`
+}
+
+// GetAccessLogResult is the data of a GetAccessLog response.
+type GetAccessLogResult struct {
+	AccessLog *AccessLogConfig `json:"access-log,omitempty"`
+}
+
+// GetWebsocketAuthResult is the data of a GetWebsocketAuth response.
+type GetWebsocketAuthResult struct {
+	WSAuth *bool `json:"ws-auth,omitempty"`
+}
+
+// GetRequestRetryResult is the data of a GetRequestRetry response.
+type GetRequestRetryResult struct {
+	RequestRetry *int64 `json:"request-retry,omitempty"`
+}
+
+// GetMaxRetryIntervalResult is the data of a GetMaxRetryInterval response.
+type GetMaxRetryIntervalResult struct {
+	MaxRetryInterval *int64 `json:"max-retry-interval,omitempty"`
+}
+
+// GetProxyURLResult is the data of a GetProxyURL response.
+type GetProxyURLResult struct {
+	ProxyURL string `json:"proxy-url,omitempty"`
+}
+
+// GetSwitchProjectResult is the data of a GetSwitchProject response.
+type GetSwitchProjectResult struct {
+	SwitchProject *bool `json:"switch-project,omitempty"`
+}
+
+// GetSwitchPreviewModelResult is the data of a GetSwitchPreviewModel response.
+type GetSwitchPreviewModelResult struct {
+	SwitchPreviewModel *bool `json:"switch-preview-model,omitempty"`
+}
+
+// GetQuotaWindowsParams holds the query parameters of GetQuotaWindows. Unset
+// fields are not sent.
+type GetQuotaWindowsParams struct {
+	Provider string
+}
+
+func (p *GetQuotaWindowsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Provider != "" {
+		q.Set("provider", p.Provider)
+	}
+	return q
+}
+
+// GetQuotaWindowsResult is the data of a GetQuotaWindows response.
+type GetQuotaWindowsResult struct {
+	Provider string        `json:"provider,omitempty"`
+	Window   string        `json:"window,omitempty"`
+	Accounts []QuotaWindow `json:"accounts,omitempty"`
+}
+
+// GetAPIKeysResult is the data of a GetAPIKeys response.
+type GetAPIKeysResult struct {
+	APIKeys []string `json:"api-keys,omitempty"`
+}
+
+// PatchAPIKeysRequest is the body of a PatchAPIKeys request.
+type PatchAPIKeysRequest struct {
+	// Existing key to replace
+	Old string `json:"old,omitempty"`
+	// New key value
+	New string `json:"new,omitempty"`
+	// Index to update
+	Index *int64 `json:"index,omitempty"`
+	// New value for index
+	Value string `json:"value,omitempty"`
+}
+
+// DeleteAPIKeysParams holds the query parameters of DeleteAPIKeys. Unset
+// fields are not sent.
+type DeleteAPIKeysParams struct {
+	// Index of key to delete
+	Index *int
+	// Value of key to delete
+	Value string
+}
+
+func (p *DeleteAPIKeysParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Index != nil {
+		q.Set("index", strconv.Itoa(*p.Index))
+	}
+	if p.Value != "" {
+		q.Set("value", p.Value)
+	}
+	return q
+}
+
+// GetProvidersResult is the data of a GetProviders response.
+type GetProvidersResult struct {
+	Providers []Provider `json:"providers,omitempty"`
+}
+
+// DeleteProviderParams holds the query parameters of DeleteProvider. Unset
+// fields are not sent.
+type DeleteProviderParams struct {
+	// Index of provider to delete
+	Index *int
+}
+
+func (p *DeleteProviderParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Index != nil {
+		q.Set("index", strconv.Itoa(*p.Index))
+	}
+	return q
+}
+
+// TestProviderRequest is the body of a TestProvider request.
+type TestProviderRequest struct {
+	// Model to test. Defaults to the first model registered for the provider.
+	Model string `json:"model,omitempty"`
+	// Prompt text. Defaults to a one-word ping.
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// TestProviderResultUpstreamItem is an item of the upstream field of TestProviderResult.
+type TestProviderResultUpstreamItem struct {
+	Method    string `json:"method,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Status    *int64 `json:"status,omitempty"`
+	LatencyMs *int64 `json:"latency_ms,omitempty"`
+	// Translated upstream payload (first 64 KiB)
+	RequestBody string `json:"request_body,omitempty"`
+	Truncated   *bool  `json:"truncated,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// TestProviderResult is the data of a TestProvider response.
+type TestProviderResult struct {
+	Provider  string `json:"provider,omitempty"`
+	Model     string `json:"model,omitempty"`
+	Ok        *bool  `json:"ok,omitempty"`
+	LatencyMs *int64 `json:"latency_ms,omitempty"`
+	// OpenAI-format request sent into the translator
+	Request json.RawMessage `json:"request,omitempty"`
+	// Outbound HTTP requests made by the executor, in order
+	Upstream []TestProviderResultUpstreamItem `json:"upstream,omitempty"`
+	Response *ChatSummary                     `json:"response,omitempty"`
+	Error    string                           `json:"error,omitempty"`
+}
+
+// CompareModelsRequestTargetsItem is an item of the targets field of CompareModelsRequest.
+type CompareModelsRequestTargetsItem struct {
+	Model string `json:"model"`
+	// Provider that must serve the model. Defaults to every provider serving it.
+	Provider string `json:"provider,omitempty"`
+}
+
+// CompareModelsRequest is the body of a CompareModels request.
+type CompareModelsRequest struct {
+	// OpenAI chat completion request; its model is replaced by each target's
+	Request json.RawMessage                   `json:"request"`
+	Targets []CompareModelsRequestTargetsItem `json:"targets"`
+}
+
+// CompareModelsResultResultsItem is an item of the results field of CompareModelsResult.
+type CompareModelsResultResultsItem struct {
+	Model     string       `json:"model,omitempty"`
+	Providers []string     `json:"providers,omitempty"`
+	Ok        *bool        `json:"ok,omitempty"`
+	LatencyMs *int64       `json:"latency_ms,omitempty"`
+	Response  *ChatSummary `json:"response,omitempty"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// CompareModelsResult is the data of a CompareModels response.
+type CompareModelsResult struct {
+	Results []CompareModelsResultResultsItem `json:"results,omitempty"`
+}
+
+// RefreshProviderModelsResultChanges is the changes field of RefreshProviderModelsResult.
+type RefreshProviderModelsResultChanges struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	// Models with changed token limits, e.g. "gemini-2.5-pro (output_token_limit 65536 -> 32768)"
+	Changed []string `json:"changed,omitempty"`
+}
+
+// RefreshProviderModelsResult is the data of a RefreshProviderModels response.
+type RefreshProviderModelsResult struct {
+	Provider string                              `json:"provider,omitempty"`
+	Changes  *RefreshProviderModelsResultChanges `json:"changes,omitempty"`
+}
+
+// GetOAuthExcludedModelsResult is the data of a GetOAuthExcludedModels response.
+type GetOAuthExcludedModelsResult struct {
+	OAuthExcludedModels map[string][]string `json:"oauth-excluded-models,omitempty"`
+}
+
+// PatchOAuthExcludedModelsRequest is the body of a PatchOAuthExcludedModels request.
+type PatchOAuthExcludedModelsRequest struct {
+	Provider string   `json:"provider"`
+	Models   []string `json:"models,omitempty"`
+}
+
+// DeleteOAuthExcludedModelsParams holds the query parameters of DeleteOAuthExcludedModels. Unset
+// fields are not sent.
+type DeleteOAuthExcludedModelsParams struct {
+	// Provider name
+	Provider string
+}
+
+func (p *DeleteOAuthExcludedModelsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Provider != "" {
+		q.Set("provider", p.Provider)
+	}
+	return q
+}
+
+// ListAuthFilesParams holds the query parameters of ListAuthFiles. Unset
+// fields are not sent.
+type ListAuthFilesParams struct {
+	// Show only runtime-loaded auth
+	RuntimeOnly bool
+}
+
+func (p *ListAuthFilesParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.RuntimeOnly {
+		q.Set("runtime-only", "true")
+	}
+	return q
+}
+
+// ListAuthFilesResult is the data of a ListAuthFiles response.
+type ListAuthFilesResult struct {
+	Files []AuthFile `json:"files,omitempty"`
+}
+
+// UploadAuthFileParams holds the query parameters of UploadAuthFile. Unset
+// fields are not sent.
+type UploadAuthFileParams struct {
+	// File name for single file upload (must end with .json)
+	Name string
+}
+
+func (p *UploadAuthFileParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Name != "" {
+		q.Set("name", p.Name)
+	}
+	return q
+}
+
+// UploadAuthFileResultResultsItem is an item of the results field of UploadAuthFileResult.
+type UploadAuthFileResultResultsItem struct {
+	Name    string `json:"name,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// UploadAuthFileResult is the data of a UploadAuthFile response.
+type UploadAuthFileResult struct {
+	Status string `json:"status,omitempty"`
+	// Number of files processed (batch only)
+	Count *int64 `json:"count,omitempty"`
+	// Per-file results (batch only)
+	Results []UploadAuthFileResultResultsItem `json:"results,omitempty"`
+}
+
+// DeleteAuthFileParams holds the query parameters of DeleteAuthFile. Unset
+// fields are not sent.
+type DeleteAuthFileParams struct {
+	// Auth file name to delete
+	Name string
+	// Delete all auth files if set to true/1/*
+	All string
+}
+
+func (p *DeleteAuthFileParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Name != "" {
+		q.Set("name", p.Name)
+	}
+	if p.All != "" {
+		q.Set("all", p.All)
+	}
+	return q
+}
+
+// DeleteAuthFileResult is the data of a DeleteAuthFile response.
+type DeleteAuthFileResult struct {
+	Status string `json:"status,omitempty"`
+	// Number of files deleted (only present when using all=true)
+	Deleted *int64 `json:"deleted,omitempty"`
+}
+
+// PatchAuthFileRequest is the body of a PatchAuthFile request.
+type PatchAuthFileRequest struct {
+	// Auth file name
+	Name  string            `json:"name"`
+	Label string            `json:"label,omitempty"`
+	Tags  map[string]string `json:"tags,omitempty"`
+}
+
+// PatchAuthFileResult is the data of a PatchAuthFile response.
+type PatchAuthFileResult struct {
+	Status string            `json:"status,omitempty"`
+	Label  string            `json:"label,omitempty"`
+	Tags   map[string]string `json:"tags,omitempty"`
+}
+
+// DownloadAuthFileParams holds the query parameters of DownloadAuthFile. Unset
+// fields are not sent.
+type DownloadAuthFileParams struct {
+	// Auth file name (must end with .json)
+	Name string
+}
+
+func (p *DownloadAuthFileParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Name != "" {
+		q.Set("name", p.Name)
+	}
+	return q
+}
+
+// ImportCLICredentialsRequest is the body of a ImportCLICredentials request.
+type ImportCLICredentialsRequest struct {
+	// Tools to import; empty imports all
+	Tools []string `json:"tools,omitempty"`
+}
+
+// ImportCLICredentialsResultFilesItem is an item of the files field of ImportCLICredentialsResult.
+type ImportCLICredentialsResultFilesItem struct {
+	Tool string `json:"tool,omitempty"`
+	// Credentials file read
+	Path     string `json:"path,omitempty"`
+	AuthFile string `json:"auth_file,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ImportCLICredentialsResult is the data of a ImportCLICredentials response.
+type ImportCLICredentialsResult struct {
+	Status   string                                `json:"status,omitempty"`
+	Imported *int64                                `json:"imported,omitempty"`
+	Files    []ImportCLICredentialsResultFilesItem `json:"files,omitempty"`
+}
+
+// ImportVertexCredentialParams holds the query parameters of ImportVertexCredential. Unset
+// fields are not sent.
+type ImportVertexCredentialParams struct {
+	// Vertex AI location/region
+	Location string
+}
+
+func (p *ImportVertexCredentialParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Location != "" {
+		q.Set("location", p.Location)
+	}
+	return q
+}
+
+// ImportVertexCredentialResult is the data of a ImportVertexCredential response.
+type ImportVertexCredentialResult struct {
+	Status string `json:"status,omitempty"`
+	// Path to the saved auth file
+	AuthFile  string `json:"auth_file,omitempty"`
+	ProjectID string `json:"project_id,omitempty"`
+	// Service account email
+	Email    string `json:"email,omitempty"`
+	Location string `json:"location,omitempty"`
+}
+
+// OAuthStartRequest is the body of a OAuthStart request.
+type OAuthStartRequest struct {
+	// Provider to authenticate with. Aliases are supported:
+	// - anthropic → claude
+	// - gemini-cli → gemini
+	// - github-copilot → copilot
+	Provider string `json:"provider"`
+	// Optional project ID for some providers. For gemini, the
+	// Google Cloud project to onboard to Code Assist; without it
+	// the assigned or first active project is used.
+	ProjectID string `json:"project_id,omitempty"`
+}
+
+// OAuthStatusResult is the data of a OAuthStatus response.
+type OAuthStatusResult struct {
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// Step after authorization in progress, e.g. Gemini Code Assist onboarding
+	Progress string `json:"progress,omitempty"`
+}
+
+// OAuthCancelResult is the data of a OAuthCancel response.
+type OAuthCancelResult struct {
+	Status string `json:"status,omitempty"`
+}
+
+// GetLogsParams holds the query parameters of GetLogs. Unset
+// fields are not sent.
+type GetLogsParams struct {
+	// Maximum number of lines to return
+	Limit *int
+	// Unix timestamp - return logs after this time
+	After *int
+}
+
+func (p *GetLogsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Limit != nil {
+		q.Set("limit", strconv.Itoa(*p.Limit))
+	}
+	if p.After != nil {
+		q.Set("after", strconv.Itoa(*p.After))
+	}
+	return q
+}
+
+// GetLogsResult is the data of a GetLogs response.
+type GetLogsResult struct {
+	Lines []string `json:"lines,omitempty"`
+	// Total number of lines in log files
+	LineCount *int64 `json:"line_count,omitempty"`
+	// Unix timestamp of most recent log entry
+	LatestTimestamp *int64 `json:"latest_timestamp,omitempty"`
+}
+
+// DeleteLogsResult is the data of a DeleteLogs response.
+type DeleteLogsResult struct {
+	Success *bool  `json:"success,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Number of rotated log files removed
+	Removed *int64 `json:"removed,omitempty"`
+}
+
+// GetRecentLogsParams holds the query parameters of GetRecentLogs. Unset
+// fields are not sent.
+type GetRecentLogsParams struct {
+	// Number of lines to return (at most 2000 are kept)
+	Lines *int
+}
+
+func (p *GetRecentLogsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Lines != nil {
+		q.Set("lines", strconv.Itoa(*p.Lines))
+	}
+	return q
+}
+
+// GetRecentLogsResult is the data of a GetRecentLogs response.
+type GetRecentLogsResult struct {
+	Lines     []string `json:"lines,omitempty"`
+	LineCount *int64   `json:"line_count,omitempty"`
+}
+
+// GetRequestErrorLogsResultFilesItem is an item of the files field of GetRequestErrorLogsResult.
+type GetRequestErrorLogsResultFilesItem struct {
+	Name     string `json:"name,omitempty"`
+	Size     *int64 `json:"size,omitempty"`
+	Modified *int64 `json:"modified,omitempty"`
+}
+
+// GetRequestErrorLogsResult is the data of a GetRequestErrorLogs response.
+type GetRequestErrorLogsResult struct {
+	Files []GetRequestErrorLogsResultFilesItem `json:"files,omitempty"`
+}
+
+// GetUsageStatisticsParams holds the query parameters of GetUsageStatistics. Unset
+// fields are not sent.
+type GetUsageStatisticsParams struct {
+	// Number of days to include (default: retention_days from config)
+	Days int
+	// Start date (YYYY-MM-DD or RFC3339)
+	From string
+	// End date (YYYY-MM-DD or RFC3339)
+	To string
+}
+
+func (p *GetUsageStatisticsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Days != 0 {
+		q.Set("days", strconv.Itoa(p.Days))
+	}
+	if p.From != "" {
+		q.Set("from", p.From)
+	}
+	if p.To != "" {
+		q.Set("to", p.To)
+	}
+	return q
+}
+
+// GetUsageDriftParams holds the query parameters of GetUsageDrift. Unset
+// fields are not sent.
+type GetUsageDriftParams struct {
+	// Number of days to include (default: 7)
+	Days int
+	// Start date (YYYY-MM-DD or RFC3339)
+	From string
+}
+
+func (p *GetUsageDriftParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Days != 0 {
+		q.Set("days", strconv.Itoa(p.Days))
+	}
+	if p.From != "" {
+		q.Set("from", p.From)
+	}
+	return q
+}
+
+// GetUsageDriftResultDaysItem is an item of the days field of GetUsageDriftResult.
+type GetUsageDriftResultDaysItem struct {
+	Day      string       `json:"day,omitempty"`
+	Provider string       `json:"provider,omitempty"`
+	Local    *UsageTotals `json:"local,omitempty"`
+	Reported *UsageTotals `json:"reported,omitempty"`
+	// (local - reported) / reported over input plus output tokens
+	TokenDriftPercent *float64 `json:"token_drift_percent,omitempty"`
+	FetchedAt         string   `json:"fetched_at,omitempty"`
+}
+
+// GetUsageDriftResult is the data of a GetUsageDrift response.
+type GetUsageDriftResult struct {
+	From string                        `json:"from,omitempty"`
+	Days []GetUsageDriftResultDaysItem `json:"days,omitempty"`
+}
+
+// GetUsageQueryParams holds the query parameters of GetUsageQuery. Unset
+// fields are not sent.
+type GetUsageQueryParams struct {
+	// Start (YYYY-MM-DD or RFC3339, default: 1 day ago)
+	From string
+	// End (YYYY-MM-DD or RFC3339, default: now)
+	To string
+	// Bucket width, e.g. 5m, 1h, 1d. Omit for one row per group.
+	Bucket string
+	// Comma-separated: provider, model, auth_id, api_key, source
+	GroupBy string
+	// Comma-separated: requests, success, failures, input_tokens, output_tokens, reasoning_tokens, cached_tokens, total_tokens, estimated_savings (default: requests,total_tokens)
+	Metrics string
+	// Filter; comma-separated values match any. model, auth_id, api_key and source work the same way.
+	Provider string
+	Failed   bool
+}
+
+func (p *GetUsageQueryParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.From != "" {
+		q.Set("from", p.From)
+	}
+	if p.To != "" {
+		q.Set("to", p.To)
+	}
+	if p.Bucket != "" {
+		q.Set("bucket", p.Bucket)
+	}
+	if p.GroupBy != "" {
+		q.Set("group_by", p.GroupBy)
+	}
+	if p.Metrics != "" {
+		q.Set("metrics", p.Metrics)
+	}
+	if p.Provider != "" {
+		q.Set("provider", p.Provider)
+	}
+	if p.Failed {
+		q.Set("failed", "true")
+	}
+	return q
+}
+
+// GetUsageQueryResult is the data of a GetUsageQuery response.
+type GetUsageQueryResult struct {
+	From   string            `json:"from,omitempty"`
+	To     string            `json:"to,omitempty"`
+	Bucket string            `json:"bucket,omitempty"`
+	Rows   []json.RawMessage `json:"rows,omitempty"`
+}
+
+// GetUsageLatencyParams holds the query parameters of GetUsageLatency. Unset
+// fields are not sent.
+type GetUsageLatencyParams struct {
+	// Number of days to include (default: 1)
+	Days int
+	From string
+	To   string
+}
+
+func (p *GetUsageLatencyParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Days != 0 {
+		q.Set("days", strconv.Itoa(p.Days))
+	}
+	if p.From != "" {
+		q.Set("from", p.From)
+	}
+	if p.To != "" {
+		q.Set("to", p.To)
+	}
+	return q
+}
+
+// GetUsageLatencyResultModelsItem is an item of the models field of GetUsageLatencyResult.
+type GetUsageLatencyResultModelsItem struct {
+	Provider string          `json:"provider,omitempty"`
+	Model    string          `json:"model,omitempty"`
+	Latency  *LatencySummary `json:"latency,omitempty"`
+	TTFT     *LatencySummary `json:"ttft,omitempty"`
+}
+
+// GetUsageLatencyResult is the data of a GetUsageLatency response.
+type GetUsageLatencyResult struct {
+	From   string                            `json:"from,omitempty"`
+	To     string                            `json:"to,omitempty"`
+	Models []GetUsageLatencyResultModelsItem `json:"models,omitempty"`
+}
+
+// GetUsageSizesParams holds the query parameters of GetUsageSizes. Unset
+// fields are not sent.
+type GetUsageSizesParams struct {
+	// Number of days to include (default: 1)
+	Days int
+	From string
+	To   string
+}
+
+func (p *GetUsageSizesParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Days != 0 {
+		q.Set("days", strconv.Itoa(p.Days))
+	}
+	if p.From != "" {
+		q.Set("from", p.From)
+	}
+	if p.To != "" {
+		q.Set("to", p.To)
+	}
+	return q
+}
+
+// GetUsageSizesResultProvidersItem is an item of the providers field of GetUsageSizesResult.
+type GetUsageSizesResultProvidersItem struct {
+	Provider string       `json:"provider,omitempty"`
+	Request  *SizeSummary `json:"request,omitempty"`
+	Response *SizeSummary `json:"response,omitempty"`
+}
+
+// GetUsageSizesResultLimitsItem is an item of the limits field of GetUsageSizesResult.
+type GetUsageSizesResultLimitsItem struct {
+	Provider   string   `json:"provider,omitempty"`
+	LimitBytes *int64   `json:"limit_bytes,omitempty"`
+	Percentile *float64 `json:"percentile,omitempty"`
+	// Request size at `percentile` over the alert window
+	ObservedBytes *int64 `json:"observed_bytes,omitempty"`
+	Samples       *int64 `json:"samples,omitempty"`
+	Exceeded      *bool  `json:"exceeded,omitempty"`
+}
+
+// GetUsageSizesResult is the data of a GetUsageSizes response.
+type GetUsageSizesResult struct {
+	From      string                             `json:"from,omitempty"`
+	To        string                             `json:"to,omitempty"`
+	Providers []GetUsageSizesResultProvidersItem `json:"providers,omitempty"`
+	Limits    []GetUsageSizesResultLimitsItem    `json:"limits,omitempty"`
+}
+
+// PostUsageBackupRequest is the body of a PostUsageBackup request.
+type PostUsageBackupRequest struct {
+	Path string `json:"path"`
+}
+
+// PostUsageBackupResult is the data of a PostUsageBackup response.
+type PostUsageBackupResult struct {
+	Path     string `json:"path,omitempty"`
+	Bytes    *int64 `json:"bytes,omitempty"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// GetTranscriptsParams holds the query parameters of GetTranscripts. Unset
+// fields are not sent.
+type GetTranscriptsParams struct {
+	Days *int
+	// RFC3339 or YYYY-MM-DD
+	From string
+	// RFC3339 or YYYY-MM-DD
+	To     string
+	APIKey string
+	Model  string
+	Label  string
+	// Substring of the request or response
+	Q     string
+	Limit *int
+}
+
+func (p *GetTranscriptsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := url.Values{}
+	if p.Days != nil {
+		q.Set("days", strconv.Itoa(*p.Days))
+	}
+	if p.From != "" {
+		q.Set("from", p.From)
+	}
+	if p.To != "" {
+		q.Set("to", p.To)
+	}
+	if p.APIKey != "" {
+		q.Set("api_key", p.APIKey)
+	}
+	if p.Model != "" {
+		q.Set("model", p.Model)
+	}
+	if p.Label != "" {
+		q.Set("label", p.Label)
+	}
+	if p.Q != "" {
+		q.Set("q", p.Q)
+	}
+	if p.Limit != nil {
+		q.Set("limit", strconv.Itoa(*p.Limit))
+	}
+	return q
+}
+
+// GetTranscriptsResult is the data of a GetTranscripts response.
+type GetTranscriptsResult struct {
+	Transcripts []Transcript `json:"transcripts,omitempty"`
+}
+
+// ExportConversationRequest is the body of a ExportConversation request.
+type ExportConversationRequest struct {
+	To string `json:"to"`
+	// Stored transcript to convert; overrides from, request and response
+	TranscriptID string `json:"transcript_id,omitempty"`
+	// Format of request, e.g. openai, claude, gemini or openai-response
+	From string `json:"from,omitempty"`
+	// Request body in the from format
+	Request json.RawMessage `json:"request,omitempty"`
+	// Assistant reply with text, reasoning and tool_calls, as stored in transcripts
+	Response json.RawMessage `json:"response,omitempty"`
+}
+
+// ExportConversationResult is the data of a ExportConversation response.
+type ExportConversationResult struct {
+	Format string `json:"format,omitempty"`
+	// Request body in the target format
+	Conversation json.RawMessage `json:"conversation,omitempty"`
+}
+
+// GetConfig calls GET /config.
+//
+// Get runtime configuration.
+func (c *Client) GetConfig(ctx context.Context) (json.RawMessage, error) {
+	var out json.RawMessage
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/config",
+		data:   &out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetConfigSchema calls GET /config/schema.
+//
+// Get the config JSON Schema.
+func (c *Client) GetConfigSchema(ctx context.Context) ([]byte, error) {
+	var out []byte
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/config/schema",
+		body:   &out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetConfigYAML calls GET /config.yaml.
+//
+// Get raw config file.
+func (c *Client) GetConfigYAML(ctx context.Context) ([]byte, error) {
+	var out []byte
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/config.yaml",
+		body:   &out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutConfigYAML calls PUT /config.yaml.
+//
+// Update config file.
+func (c *Client) PutConfigYAML(ctx context.Context, body []byte) (*PutConfigYAMLResult, error) {
+	out := new(PutConfigYAMLResult)
+	if err := c.call(ctx, call{
+		method:      http.MethodPut,
+		path:        "/config.yaml",
+		raw:         body,
+		contentType: "application/yaml",
+		data:        out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetConfigReloadStatus calls GET /config/reload-status.
+//
+// Get hot reload status.
+func (c *Client) GetConfigReloadStatus(ctx context.Context) (*GetConfigReloadStatusResult, error) {
+	out := new(GetConfigReloadStatusResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/config/reload-status",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetLatestVersion calls GET /latest-version.
+//
+// Check latest version.
+func (c *Client) GetLatestVersion(ctx context.Context) (*GetLatestVersionResult, error) {
+	out := new(GetLatestVersionResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/latest-version",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetPprofProfile calls GET /debug/pprof/{profile}.
+//
+// Runtime profiling.
+func (c *Client) GetPprofProfile(ctx context.Context, profile string, params *GetPprofProfileParams) ([]byte, error) {
+	var out []byte
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/debug/pprof/" + url.PathEscape(profile),
+		query:  params.values(),
+		body:   &out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PostPprofProfile calls POST /debug/pprof/{profile}.
+//
+// Runtime profiling symbol lookup.
+func (c *Client) PostPprofProfile(ctx context.Context, profile string, body []byte) ([]byte, error) {
+	var out []byte
+	if err := c.call(ctx, call{
+		method:      http.MethodPost,
+		path:        "/debug/pprof/" + url.PathEscape(profile),
+		raw:         body,
+		contentType: "text/plain",
+		body:        &out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetRuntimeStats calls GET /runtime.
+//
+// Get runtime memory statistics.
+func (c *Client) GetRuntimeStats(ctx context.Context) (*GetRuntimeStatsResult, error) {
+	out := new(GetRuntimeStatsResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/runtime",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetOverload calls GET /runtime/overload.
+//
+// Get overload shedding status.
+func (c *Client) GetOverload(ctx context.Context) (*GetOverloadResult, error) {
+	out := new(GetOverloadResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/runtime/overload",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetStreamRepairs calls GET /stream-repairs.
+//
+// Get stream encoding repairs.
+func (c *Client) GetStreamRepairs(ctx context.Context) (*GetStreamRepairsResult, error) {
+	out := new(GetStreamRepairsResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/stream-repairs",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetUpstreamOverloads calls GET /upstream-overloads.
+//
+// Get upstream overload counts.
+func (c *Client) GetUpstreamOverloads(ctx context.Context) (*GetUpstreamOverloadsResult, error) {
+	out := new(GetUpstreamOverloadsResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/upstream-overloads",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetAbandonedStreams calls GET /abandoned-streams.
+//
+// Get abandoned stream counts.
+func (c *Client) GetAbandonedStreams(ctx context.Context) (*GetAbandonedStreamsResult, error) {
+	out := new(GetAbandonedStreamsResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/abandoned-streams",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetEndpointAliasCallers calls GET /endpoint-aliases/callers.
+//
+// Get callers of deprecated endpoint aliases.
+func (c *Client) GetEndpointAliasCallers(ctx context.Context) (*GetEndpointAliasCallersResult, error) {
+	out := new(GetEndpointAliasCallersResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/endpoint-aliases/callers",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetTLSSessions calls GET /tls-sessions.
+//
+// Get upstream TLS handshake reuse.
+func (c *Client) GetTLSSessions(ctx context.Context) (*GetTLSSessionsResult, error) {
+	out := new(GetTLSSessionsResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/tls-sessions",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetWarmPool calls GET /warm-pool.
+//
+// Get Ollama warm pool state.
+func (c *Client) GetWarmPool(ctx context.Context) (*GetWarmPoolResult, error) {
+	out := new(GetWarmPoolResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/warm-pool",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetCanaries calls GET /canaries.
+//
+// Get canary results.
+func (c *Client) GetCanaries(ctx context.Context) (*GetCanariesResult, error) {
+	out := new(GetCanariesResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/canaries",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetConformance calls GET /conformance.
+//
+// Run client format conformance checks.
+func (c *Client) GetConformance(ctx context.Context) (*GetConformanceResult, error) {
+	out := new(GetConformanceResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/conformance",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetDebug calls GET /debug.
+//
+// Get debug mode.
+func (c *Client) GetDebug(ctx context.Context) (*BooleanSetting, error) {
+	out := new(BooleanSetting)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/debug",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutDebug calls PUT /debug.
+//
+// Set debug mode.
+func (c *Client) PutDebug(ctx context.Context, body *BooleanValue) (*BooleanSetting, error) {
+	out := new(BooleanSetting)
+	if err := c.call(ctx, call{
+		method: http.MethodPut,
+		path:   "/debug",
+		json:   body,
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetLoggingToFile calls GET /logging-to-file.
+//
+// Get file logging status.
+func (c *Client) GetLoggingToFile(ctx context.Context) (*GetLoggingToFileResult, error) {
+	out := new(GetLoggingToFileResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/logging-to-file",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutLoggingToFile calls PUT /logging-to-file.
+//
+// Set file logging.
+func (c *Client) PutLoggingToFile(ctx context.Context, body *BooleanValue) error {
+	return c.call(ctx, call{
+		method: http.MethodPut,
+		path:   "/logging-to-file",
+		json:   body,
+	})
+}
+
+// GetUsageStatisticsEnabled calls GET /usage-statistics-enabled.
+//
+// Get usage statistics status.
+func (c *Client) GetUsageStatisticsEnabled(ctx context.Context) (*GetUsageStatisticsEnabledResult, error) {
+	out := new(GetUsageStatisticsEnabledResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/usage-statistics-enabled",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutUsageStatisticsEnabled calls PUT /usage-statistics-enabled.
+//
+// Enable/disable usage statistics.
+func (c *Client) PutUsageStatisticsEnabled(ctx context.Context, body *BooleanValue) error {
+	return c.call(ctx, call{
+		method: http.MethodPut,
+		path:   "/usage-statistics-enabled",
+		json:   body,
+	})
+}
+
+// GetRequestLog calls GET /request-log.
+//
+// Get request logging status.
+func (c *Client) GetRequestLog(ctx context.Context) (*GetRequestLogResult, error) {
+	out := new(GetRequestLogResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/request-log",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutRequestLog calls PUT /request-log.
+//
+// Set request logging.
+func (c *Client) PutRequestLog(ctx context.Context, body *BooleanValue) error {
+	return c.call(ctx, call{
+		method: http.MethodPut,
+		path:   "/request-log",
+		json:   body,
+	})
+}
+
+// GetAccessLog calls GET /access-log.
+//
+// Get access log settings.
+func (c *Client) GetAccessLog(ctx context.Context) (*GetAccessLogResult, error) {
+	out := new(GetAccessLogResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/access-log",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutAccessLog calls PUT /access-log.
+//
+// Replace access log settings.
+func (c *Client) PutAccessLog(ctx context.Context, body *AccessLogConfig) error {
+	return c.call(ctx, call{
+		method: http.MethodPut,
+		path:   "/access-log",
+		json:   body,
+	})
+}
+
+// GetWebsocketAuth calls GET /ws-auth.
+//
+// Get WebSocket authentication status.
+func (c *Client) GetWebsocketAuth(ctx context.Context) (*GetWebsocketAuthResult, error) {
+	out := new(GetWebsocketAuthResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/ws-auth",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutWebsocketAuth calls PUT /ws-auth.
+//
+// Set WebSocket authentication.
+func (c *Client) PutWebsocketAuth(ctx context.Context, body *BooleanValue) error {
+	return c.call(ctx, call{
+		method: http.MethodPut,
+		path:   "/ws-auth",
+		json:   body,
+	})
+}
+
+// GetRequestRetry calls GET /request-retry.
+//
+// Get request retry count.
+func (c *Client) GetRequestRetry(ctx context.Context) (*GetRequestRetryResult, error) {
+	out := new(GetRequestRetryResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/request-retry",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutRequestRetry calls PUT /request-retry.
+//
+// Set request retry count.
+func (c *Client) PutRequestRetry(ctx context.Context, body *IntegerValue) error {
+	return c.call(ctx, call{
+		method: http.MethodPut,
+		path:   "/request-retry",
+		json:   body,
+	})
+}
+
+// GetMaxRetryInterval calls GET /max-retry-interval.
+//
+// Get max retry interval.
+func (c *Client) GetMaxRetryInterval(ctx context.Context) (*GetMaxRetryIntervalResult, error) {
+	out := new(GetMaxRetryIntervalResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/max-retry-interval",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutMaxRetryInterval calls PUT /max-retry-interval.
+//
+// Set max retry interval.
+func (c *Client) PutMaxRetryInterval(ctx context.Context, body *IntegerValue) error {
+	return c.call(ctx, call{
+		method: http.MethodPut,
+		path:   "/max-retry-interval",
+		json:   body,
+	})
+}
+
+// GetProxyURL calls GET /proxy-url.
+//
+// Get proxy URL.
+func (c *Client) GetProxyURL(ctx context.Context) (*GetProxyURLResult, error) {
+	out := new(GetProxyURLResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/proxy-url",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutProxyURL calls PUT /proxy-url.
+//
+// Set proxy URL.
+func (c *Client) PutProxyURL(ctx context.Context, body *StringValue) error {
+	return c.call(ctx, call{
+		method: http.MethodPut,
+		path:   "/proxy-url",
+		json:   body,
+	})
+}
+
+// DeleteProxyURL calls DELETE /proxy-url.
+//
+// Remove proxy URL.
+func (c *Client) DeleteProxyURL(ctx context.Context) error {
+	return c.call(ctx, call{
+		method: http.MethodDelete,
+		path:   "/proxy-url",
+	})
+}
+
+// GetSwitchProject calls GET /quota-exceeded/switch-project.
+//
+// Get switch-project setting.
+func (c *Client) GetSwitchProject(ctx context.Context) (*GetSwitchProjectResult, error) {
+	out := new(GetSwitchProjectResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/quota-exceeded/switch-project",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutSwitchProject calls PUT /quota-exceeded/switch-project.
+//
+// Set switch-project behavior.
+func (c *Client) PutSwitchProject(ctx context.Context, body *BooleanValue) error {
+	return c.call(ctx, call{
+		method: http.MethodPut,
+		path:   "/quota-exceeded/switch-project",
+		json:   body,
+	})
+}
+
+// GetSwitchPreviewModel calls GET /quota-exceeded/switch-preview-model.
+//
+// Get switch-preview-model setting.
+func (c *Client) GetSwitchPreviewModel(ctx context.Context) (*GetSwitchPreviewModelResult, error) {
+	out := new(GetSwitchPreviewModelResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/quota-exceeded/switch-preview-model",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutSwitchPreviewModel calls PUT /quota-exceeded/switch-preview-model.
+//
+// Set switch-preview-model behavior.
+func (c *Client) PutSwitchPreviewModel(ctx context.Context, body *BooleanValue) error {
+	return c.call(ctx, call{
+		method: http.MethodPut,
+		path:   "/quota-exceeded/switch-preview-model",
+		json:   body,
+	})
+}
+
+// GetQuotaWindows calls GET /quota/windows.
+//
+// Rolling quota window usage per account.
+func (c *Client) GetQuotaWindows(ctx context.Context, params *GetQuotaWindowsParams) (*GetQuotaWindowsResult, error) {
+	out := new(GetQuotaWindowsResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/quota/windows",
+		query:  params.values(),
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetAPIKeys calls GET /api-keys.
+//
+// List API keys.
+func (c *Client) GetAPIKeys(ctx context.Context) (*GetAPIKeysResult, error) {
+	out := new(GetAPIKeysResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/api-keys",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutAPIKeys calls PUT /api-keys.
+//
+// Replace API keys.
+func (c *Client) PutAPIKeys(ctx context.Context, body any) error {
+	return c.call(ctx, call{
+		method: http.MethodPut,
+		path:   "/api-keys",
+		json:   body,
+	})
+}
+
+// PatchAPIKeys calls PATCH /api-keys.
+//
+// Update single API key.
+func (c *Client) PatchAPIKeys(ctx context.Context, body *PatchAPIKeysRequest) error {
+	return c.call(ctx, call{
+		method: http.MethodPatch,
+		path:   "/api-keys",
+		json:   body,
+	})
+}
+
+// DeleteAPIKeys calls DELETE /api-keys.
+//
+// Delete API key.
+func (c *Client) DeleteAPIKeys(ctx context.Context, params *DeleteAPIKeysParams) error {
+	return c.call(ctx, call{
+		method: http.MethodDelete,
+		path:   "/api-keys",
+		query:  params.values(),
+	})
+}
+
+// GetProviders calls GET /providers.
+//
+// List providers.
+func (c *Client) GetProviders(ctx context.Context) (*GetProvidersResult, error) {
+	out := new(GetProvidersResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/providers",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutProviders calls PUT /providers.
+//
+// Replace providers.
+func (c *Client) PutProviders(ctx context.Context, body any) error {
+	return c.call(ctx, call{
+		method: http.MethodPut,
+		path:   "/providers",
+		json:   body,
+	})
+}
+
+// DeleteProvider calls DELETE /providers.
+//
+// Delete provider.
+func (c *Client) DeleteProvider(ctx context.Context, params *DeleteProviderParams) error {
+	return c.call(ctx, call{
+		method: http.MethodDelete,
+		path:   "/providers",
+		query:  params.values(),
+	})
+}
+
+// TestProvider calls POST /providers/{name}/test.
+//
+// Smoke test a provider.
+func (c *Client) TestProvider(ctx context.Context, name string, body *TestProviderRequest) (*TestProviderResult, error) {
+	out := new(TestProviderResult)
+	if err := c.call(ctx, call{
+		method: http.MethodPost,
+		path:   "/providers/" + url.PathEscape(name) + "/test",
+		json:   body,
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CompareModels calls POST /compare.
+//
+// Compare two models on one request.
+func (c *Client) CompareModels(ctx context.Context, body *CompareModelsRequest) (*CompareModelsResult, error) {
+	out := new(CompareModelsResult)
+	if err := c.call(ctx, call{
+		method: http.MethodPost,
+		path:   "/compare",
+		json:   body,
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RefreshProviderModels calls POST /providers/{name}/models/refresh.
+//
+// Refresh a provider's model list.
+func (c *Client) RefreshProviderModels(ctx context.Context, name string) (*RefreshProviderModelsResult, error) {
+	out := new(RefreshProviderModelsResult)
+	if err := c.call(ctx, call{
+		method: http.MethodPost,
+		path:   "/providers/" + url.PathEscape(name) + "/models/refresh",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetOAuthExcludedModels calls GET /oauth-excluded-models.
+//
+// List excluded models.
+func (c *Client) GetOAuthExcludedModels(ctx context.Context) (*GetOAuthExcludedModelsResult, error) {
+	out := new(GetOAuthExcludedModelsResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/oauth-excluded-models",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutOAuthExcludedModels calls PUT /oauth-excluded-models.
+//
+// Replace excluded models.
+func (c *Client) PutOAuthExcludedModels(ctx context.Context, body map[string][]string) error {
+	return c.call(ctx, call{
+		method: http.MethodPut,
+		path:   "/oauth-excluded-models",
+		json:   body,
+	})
+}
+
+// PatchOAuthExcludedModels calls PATCH /oauth-excluded-models.
+//
+// Update provider's excluded models.
+func (c *Client) PatchOAuthExcludedModels(ctx context.Context, body *PatchOAuthExcludedModelsRequest) error {
+	return c.call(ctx, call{
+		method: http.MethodPatch,
+		path:   "/oauth-excluded-models",
+		json:   body,
+	})
+}
+
+// DeleteOAuthExcludedModels calls DELETE /oauth-excluded-models.
+//
+// Delete provider's excluded models.
+func (c *Client) DeleteOAuthExcludedModels(ctx context.Context, params *DeleteOAuthExcludedModelsParams) error {
+	return c.call(ctx, call{
+		method: http.MethodDelete,
+		path:   "/oauth-excluded-models",
+		query:  params.values(),
+	})
+}
+
+// ListAuthFiles calls GET /auth-files.
+//
+// List authentication files.
+func (c *Client) ListAuthFiles(ctx context.Context, params *ListAuthFilesParams) (*ListAuthFilesResult, error) {
+	out := new(ListAuthFilesResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/auth-files",
+		query:  params.values(),
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UploadAuthFile calls POST /auth-files.
+//
+// Upload authentication file.
+func (c *Client) UploadAuthFile(ctx context.Context, params *UploadAuthFileParams, body any) (*UploadAuthFileResult, error) {
+	out := new(UploadAuthFileResult)
+	if err := c.call(ctx, call{
+		method: http.MethodPost,
+		path:   "/auth-files",
+		query:  params.values(),
+		json:   body,
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteAuthFile calls DELETE /auth-files.
+//
+// Delete authentication file.
+func (c *Client) DeleteAuthFile(ctx context.Context, params *DeleteAuthFileParams) (*DeleteAuthFileResult, error) {
+	out := new(DeleteAuthFileResult)
+	if err := c.call(ctx, call{
+		method: http.MethodDelete,
+		path:   "/auth-files",
+		query:  params.values(),
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PatchAuthFile calls PATCH /auth-files.
+//
+// Update auth label and tags.
+func (c *Client) PatchAuthFile(ctx context.Context, body *PatchAuthFileRequest) (*PatchAuthFileResult, error) {
+	out := new(PatchAuthFileResult)
+	if err := c.call(ctx, call{
+		method: http.MethodPatch,
+		path:   "/auth-files",
+		json:   body,
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DownloadAuthFile calls GET /auth-files/download.
+//
+// Download authentication file.
+func (c *Client) DownloadAuthFile(ctx context.Context, params *DownloadAuthFileParams) (json.RawMessage, error) {
+	var out json.RawMessage
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/auth-files/download",
+		query:  params.values(),
+		result: &out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ImportCLICredentials calls POST /cli/import.
+//
+// Import official CLI logins.
+func (c *Client) ImportCLICredentials(ctx context.Context, body *ImportCLICredentialsRequest) (*ImportCLICredentialsResult, error) {
+	out := new(ImportCLICredentialsResult)
+	if err := c.call(ctx, call{
+		method: http.MethodPost,
+		path:   "/cli/import",
+		json:   body,
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ImportVertexCredential calls POST /vertex/import.
+//
+// Import Vertex AI credentials.
+func (c *Client) ImportVertexCredential(ctx context.Context, params *ImportVertexCredentialParams, file File) (*ImportVertexCredentialResult, error) {
+	out := new(ImportVertexCredentialResult)
+	if err := c.call(ctx, call{
+		method:    http.MethodPost,
+		path:      "/vertex/import",
+		query:     params.values(),
+		file:      &file,
+		fileField: "file",
+		data:      out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OAuthStart calls POST /oauth/start.
+//
+// Start OAuth flow.
+func (c *Client) OAuthStart(ctx context.Context, body *OAuthStartRequest) (*OAuthStartResponse, error) {
+	out := new(OAuthStartResponse)
+	if err := c.call(ctx, call{
+		method: http.MethodPost,
+		path:   "/oauth/start",
+		json:   body,
+		result: out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OAuthStatus calls GET /oauth/status/{state}.
+//
+// Check OAuth flow status.
+func (c *Client) OAuthStatus(ctx context.Context, state string) (*OAuthStatusResult, error) {
+	out := new(OAuthStatusResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/oauth/status/" + url.PathEscape(state),
+		result: out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OAuthCancel calls POST /oauth/cancel/{state}.
+//
+// Cancel OAuth flow.
+func (c *Client) OAuthCancel(ctx context.Context, state string) (*OAuthCancelResult, error) {
+	out := new(OAuthCancelResult)
+	if err := c.call(ctx, call{
+		method: http.MethodPost,
+		path:   "/oauth/cancel/" + url.PathEscape(state),
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetLogs calls GET /logs.
+//
+// Get log lines.
+func (c *Client) GetLogs(ctx context.Context, params *GetLogsParams) (*GetLogsResult, error) {
+	out := new(GetLogsResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/logs",
+		query:  params.values(),
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteLogs calls DELETE /logs.
+//
+// Clear logs.
+func (c *Client) DeleteLogs(ctx context.Context) (*DeleteLogsResult, error) {
+	out := new(DeleteLogsResult)
+	if err := c.call(ctx, call{
+		method: http.MethodDelete,
+		path:   "/logs",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetRecentLogs calls GET /logs/recent.
+//
+// Get recent log lines from memory.
+func (c *Client) GetRecentLogs(ctx context.Context, params *GetRecentLogsParams) (*GetRecentLogsResult, error) {
+	out := new(GetRecentLogsResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/logs/recent",
+		query:  params.values(),
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetLogLevel calls GET /log-level.
+//
+// Get log levels.
+func (c *Client) GetLogLevel(ctx context.Context) (*LogLevels, error) {
+	out := new(LogLevels)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/log-level",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutLogLevel calls PUT /log-level.
+//
+// Set log levels.
+func (c *Client) PutLogLevel(ctx context.Context, body *LogLevels) (*LogLevels, error) {
+	out := new(LogLevels)
+	if err := c.call(ctx, call{
+		method: http.MethodPut,
+		path:   "/log-level",
+		json:   body,
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetRequestErrorLogs calls GET /request-error-logs.
+//
+// List error log files.
+func (c *Client) GetRequestErrorLogs(ctx context.Context) (*GetRequestErrorLogsResult, error) {
+	out := new(GetRequestErrorLogsResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/request-error-logs",
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DownloadRequestErrorLog calls GET /request-error-logs/{name}.
+//
+// Download error log file.
+func (c *Client) DownloadRequestErrorLog(ctx context.Context, name string) ([]byte, error) {
+	var out []byte
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/request-error-logs/" + url.PathEscape(name),
+		body:   &out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetUsageStatistics calls GET /usage.
+//
+// Get usage statistics.
+func (c *Client) GetUsageStatistics(ctx context.Context, params *GetUsageStatisticsParams) (*UsageStats, error) {
+	out := new(UsageStats)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/usage",
+		query:  params.values(),
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetUsageDrift calls GET /usage/drift.
+//
+// Compare recorded usage with provider-reported usage.
+func (c *Client) GetUsageDrift(ctx context.Context, params *GetUsageDriftParams) (*GetUsageDriftResult, error) {
+	out := new(GetUsageDriftResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/usage/drift",
+		query:  params.values(),
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetUsageQuery calls GET /usage/query.
+//
+// Query usage over custom time buckets.
+func (c *Client) GetUsageQuery(ctx context.Context, params *GetUsageQueryParams) (*GetUsageQueryResult, error) {
+	out := new(GetUsageQueryResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/usage/query",
+		query:  params.values(),
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetUsageLatency calls GET /usage/latency.
+//
+// Latency and TTFT percentiles per provider and model.
+func (c *Client) GetUsageLatency(ctx context.Context, params *GetUsageLatencyParams) (*GetUsageLatencyResult, error) {
+	out := new(GetUsageLatencyResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/usage/latency",
+		query:  params.values(),
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetUsageLatencyMetrics calls GET /usage/latency/metrics.
+//
+// Latency histograms in Prometheus text format.
+func (c *Client) GetUsageLatencyMetrics(ctx context.Context) ([]byte, error) {
+	var out []byte
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/usage/latency/metrics",
+		body:   &out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetUsageSizes calls GET /usage/sizes.
+//
+// Upstream request and response sizes per provider.
+func (c *Client) GetUsageSizes(ctx context.Context, params *GetUsageSizesParams) (*GetUsageSizesResult, error) {
+	out := new(GetUsageSizesResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/usage/sizes",
+		query:  params.values(),
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PostUsageBackup calls POST /usage/backup.
+//
+// Back up the SQLite usage database.
+func (c *Client) PostUsageBackup(ctx context.Context, body *PostUsageBackupRequest) (*PostUsageBackupResult, error) {
+	out := new(PostUsageBackupResult)
+	if err := c.call(ctx, call{
+		method: http.MethodPost,
+		path:   "/usage/backup",
+		json:   body,
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetTranscripts calls GET /transcripts.
+//
+// List stored transcripts.
+func (c *Client) GetTranscripts(ctx context.Context, params *GetTranscriptsParams) (*GetTranscriptsResult, error) {
+	out := new(GetTranscriptsResult)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/transcripts",
+		query:  params.values(),
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetTranscript calls GET /transcripts/{id}.
+//
+// Get a stored transcript.
+func (c *Client) GetTranscript(ctx context.Context, id string) (*Transcript, error) {
+	out := new(Transcript)
+	if err := c.call(ctx, call{
+		method: http.MethodGet,
+		path:   "/transcripts/" + url.PathEscape(id),
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExportConversation calls POST /conversations/export.
+//
+// Convert a conversation to another API format.
+func (c *Client) ExportConversation(ctx context.Context, body *ExportConversationRequest) (*ExportConversationResult, error) {
+	out := new(ExportConversationResult)
+	if err := c.call(ctx, call{
+		method: http.MethodPost,
+		path:   "/conversations/export",
+		json:   body,
+		data:   out,
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,67 @@
+package mgmtclient
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// Event is a value or an error delivered by a watch.
+type Event[T any] struct {
+	Value T
+	Err   error
+}
+
+// Watch calls fetch right away and then every interval, and sends each
+// result that differs from the last one sent. Errors are always sent, and
+// the first result after an error is sent even when unchanged. The channel
+// is closed when ctx is done.
+func Watch[T any](ctx context.Context, interval time.Duration, fetch func(context.Context) (T, error)) <-chan Event[T] {
+	ch := make(chan Event[T], 1)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var last T
+		sent := false
+		for {
+			v, err := fetch(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil || !sent || !reflect.DeepEqual(v, last) {
+				select {
+				case ch <- Event[T]{Value: v, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+				last, sent = v, err == nil
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// WatchUsage watches the usage statistics selected by params.
+func (c *Client) WatchUsage(ctx context.Context, interval time.Duration, params *GetUsageStatisticsParams) <-chan Event[*UsageStats] {
+	return Watch(ctx, interval, func(ctx context.Context) (*UsageStats, error) {
+		return c.GetUsageStatistics(ctx, params)
+	})
+}
+
+// WatchAuthFiles watches the auth files and their status, such as an auth
+// cooling down or becoming unavailable.
+func (c *Client) WatchAuthFiles(ctx context.Context, interval time.Duration) <-chan Event[[]AuthFile] {
+	return Watch(ctx, interval, func(ctx context.Context) ([]AuthFile, error) {
+		res, err := c.ListAuthFiles(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return res.Files, nil
+	})
+}
@@ -0,0 +1,79 @@
+#!/bin/bash
+# ============================================================
+# llm-mux benchmark regression gate
+# Compares two `go test -bench` outputs and fails when any
+# benchmark present in both got slower or allocates more.
+#
+# Usage:
+#   ./scripts/bench-gate.sh <baseline.txt> <current.txt> [max_regression_pct]
+#
+# Runs with -count > 1 are reduced to the fastest sample per
+# benchmark. Default threshold: 10 (%).
+# ============================================================
+
+set -euo pipefail
+
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+NC='\033[0m'
+
+error() { echo -e "${RED}error:${NC} $*" >&2; exit 1; }
+
+BASELINE="${1:-}"
+CURRENT="${2:-}"
+THRESHOLD="${3:-10}"
+
+[[ -f "$BASELINE" ]] || error "baseline file not found: ${BASELINE:-<none>} (run 'make bench-baseline' first)"
+[[ -f "$CURRENT" ]] || error "current results not found: ${CURRENT:-<none>}"
+
+# Emit "name ns allocs" with the lowest ns/op and allocs/op seen per benchmark.
+reduce() {
+    awk '
+        /^Benchmark/ {
+            name = $1; sub(/-[0-9]+$/, "", name)
+            ns = ""; allocs = ""
+            for (i = 2; i < NF; i++) {
+                if ($(i+1) == "ns/op") ns = $i
+                if ($(i+1) == "allocs/op") allocs = $i
+            }
+            if (ns == "") next
+            if (!(name in best_ns) || ns + 0 < best_ns[name] + 0) best_ns[name] = ns
+            if (allocs != "" && (!(name in best_allocs) || allocs + 0 < best_allocs[name] + 0)) best_allocs[name] = allocs
+        }
+        END {
+            for (n in best_ns) print n, best_ns[n], (n in best_allocs ? best_allocs[n] : -1)
+        }
+    ' "$1" | sort
+}
+
+join <(reduce "$BASELINE") <(reduce "$CURRENT") | awk -v max="$THRESHOLD" -v red="$RED" -v green="$GREEN" -v nc="$NC" '
+    function pct(old, new) { return old > 0 ? (new - old) * 100 / old : (new > 0 ? 100 : 0) }
+    {
+        compared++
+        dns = pct($2, $4)
+        line = sprintf("%-60s %12.1f -> %12.1f ns/op (%+6.1f%%)", $1, $2, $4, dns)
+        bad = dns > max
+        if ($3 >= 0 && $5 >= 0) {
+            dallocs = pct($3, $5)
+            line = line sprintf("  %6d -> %6d allocs/op (%+6.1f%%)", $3, $5, dallocs)
+            if (dallocs > max) bad = 1
+        }
+        if (bad) {
+            failed++
+            print red "REGRESSION" nc " " line
+        } else {
+            print "           " line
+        }
+    }
+    END {
+        if (compared == 0) {
+            print red "no benchmarks in common" nc
+            exit 1
+        }
+        if (failed > 0) {
+            printf "%s%d of %d benchmarks regressed by more than %s%%%s\n", red, failed, compared, max, nc
+            exit 1
+        }
+        printf "%s%d benchmarks within %s%%%s\n", green, compared, max, nc
+    }
+'